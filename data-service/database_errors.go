@@ -0,0 +1,23 @@
+package main
+
+import (
+	"net/http"
+
+	"data-service/pkg/database"
+	"shared/httpx"
+)
+
+// writeDatabaseError answers with the envelope for a failed database operation, carrying the
+// stable code of errors classified by the database package so clients can react to them
+func writeDatabaseError(w http.ResponseWriter, r *http.Request, err error) {
+	status := http.StatusInternalServerError
+	code := database.CodeDatabaseError
+	message := "database operation failed"
+	if dbErr, ok := database.AsDBError(err); ok {
+		status = dbErr.HTTPStatus()
+		code = dbErr.Code
+		message = dbErr.Message
+	}
+
+	httpx.WriteRouteErrorDetail(w, status, database.CodeDatabaseError, code, message, r)
+}
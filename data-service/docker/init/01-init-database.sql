@@ -7,7 +7,6 @@ CREATE EXTENSION IF NOT EXISTS "pgcrypto";
 
 -- Create sequences
 CREATE SEQUENCE IF NOT EXISTS existence_reference_seq START 1;
-CREATE SEQUENCE IF NOT EXISTS order_number_seq START 1;
 CREATE SEQUENCE IF NOT EXISTS invoice_number_seq START 1;
 
 -- =============================================================================
@@ -23,7 +22,8 @@ CREATE TABLE suppliers (
     address TEXT,
     notes TEXT,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP -- set when the supplier is merged into another one
 );
 
 -- Ingredient Categories Table
@@ -43,6 +43,9 @@ CREATE TABLE ingredients (
     description TEXT,
     ingredient_category_id UUID REFERENCES ingredient_categories(id) ON DELETE SET NULL,
     supplier_id UUID REFERENCES suppliers(id) ON DELETE SET NULL,
+    reorder_point DECIMAL(10,2) CHECK (reorder_point >= 0), -- restock when available units fall below this; NULL disables
+    reorder_target DECIMAL(10,2) CHECK (reorder_target >= 0), -- level to restock up to; defaults to the reorder point
+    tags TEXT[] NOT NULL DEFAULT '{}', -- allergen labels (dairy, nuts, ...); allowed values are validated by inventory-service
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
@@ -80,6 +83,19 @@ CREATE TABLE recipe_ingredients (
     UNIQUE(recipe_id, ingredient_id)
 );
 
+-- Unit Conversions Table (ingredient-specific factors such as 1 Bag of sugar = 2000 g;
+-- standard conversions like Liters -> ml are built into inventory-service)
+CREATE TABLE unit_conversions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    ingredient_id UUID NOT NULL REFERENCES ingredients(id) ON DELETE CASCADE,
+    from_unit VARCHAR(50) NOT NULL,
+    to_unit VARCHAR(50) NOT NULL,
+    factor DECIMAL(14,6) NOT NULL CHECK (factor > 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(ingredient_id, from_unit, to_unit)
+);
+
 -- =============================================================================
 -- INVOICES MANAGEMENT ENTITIES
 -- =============================================================================
@@ -116,8 +132,11 @@ CREATE TABLE invoice (
     total_amount DECIMAL(12,2),
     image_url VARCHAR(500) NOT NULL,
     notes TEXT,
+    currency CHAR(3) NOT NULL DEFAULT 'CRC', -- ISO 4217, shared by all of the invoice's details
+    tax_exempt BOOLEAN NOT NULL DEFAULT FALSE, -- existences from exempt invoices carry no IVA or service tax
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP -- soft-delete marker; deleted invoices are hidden from listings but keep their existences
 );
 
 -- Invoice Details Table (line items for invoices)
@@ -130,7 +149,10 @@ CREATE TABLE invoice_details (
     unit_type VARCHAR(20) NOT NULL CHECK (unit_type IN ('Liters', 'Gallons', 'Units', 'Bag')),
     price DECIMAL(10,2) NOT NULL CHECK (price > 0),
     total DECIMAL(12,2) GENERATED ALWAYS AS (count * price) STORED,
+    received_count DECIMAL(10,2) NOT NULL DEFAULT 0 CHECK (received_count >= 0 AND received_count <= count), -- units delivered so far; existences exist only for these
+    outstanding_count DECIMAL(10,2) GENERATED ALWAYS AS (count - received_count) STORED,
     expiration_date DATE,
+    currency CHAR(3) NOT NULL DEFAULT 'CRC', -- ISO 4217, must match the invoice currency
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
@@ -168,6 +190,19 @@ CREATE TABLE existences (
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
+-- Reference codes only come from the sequence; nextval is atomic, so concurrent inserts never share a code
+ALTER SEQUENCE existence_reference_seq OWNED BY existences.existence_reference_code;
+
+-- Existence Movements Table (every change to an existence's units_available, oldest first)
+CREATE TABLE existence_movements (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    existence_id UUID NOT NULL REFERENCES existences(id) ON DELETE CASCADE,
+    movement_type VARCHAR(20) NOT NULL CHECK (movement_type IN ('purchase', 'consumption', 'write_off', 'adjustment', 'reassignment')),
+    quantity_change DECIMAL(10,2) NOT NULL, -- positive adds units, negative removes them
+    notes TEXT,
+    created_at TIMESTAMP DEFAULT clock_timestamp() -- wall clock so movements in one transaction stay ordered
+);
+
 -- Runout Ingredient Report Table
 CREATE TABLE runout_ingredient_report (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
@@ -198,15 +233,26 @@ CREATE TABLE customers (
 -- INCOME MANAGEMENT (ORDERS) ENTITIES
 -- =============================================================================
 
+-- Order Number Counters Table
+-- One row per numbering scope (a business date, or "all" when numbers never reset);
+-- orders take the next number with an atomic upsert of their scope's row
+CREATE TABLE order_number_counters (
+    scope VARCHAR(8) PRIMARY KEY,
+    last_number INTEGER NOT NULL
+);
+
 -- Orders Table
 CREATE TABLE orders (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-    order_number INTEGER DEFAULT nextval('order_number_seq'),
+    order_number VARCHAR(20) NOT NULL,
     customer_id UUID REFERENCES customers(id) ON DELETE SET NULL,
     total_amount DECIMAL(10,2) NOT NULL CHECK (total_amount >= 0),
+    service_amount DECIMAL(10,2) DEFAULT 0 CHECK (service_amount >= 0),
     discount_amount DECIMAL(10,2) DEFAULT 0 CHECK (discount_amount >= 0),
     final_amount DECIMAL(10,2) GENERATED ALWAYS AS (total_amount - discount_amount) STORED,
-    order_status VARCHAR(50) DEFAULT 'pending' CHECK (order_status IN ('pending', 'confirmed', 'completed', 'cancelled')),
+    order_status VARCHAR(50) DEFAULT 'pending' CHECK (order_status IN ('pending', 'confirmed', 'preparing', 'completed', 'cancelled')),
+    cancelled_at TIMESTAMP,
+    cancellation_reason VARCHAR(50),
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     UNIQUE(order_number)
@@ -221,6 +267,39 @@ CREATE TABLE ordered_receipes (
     receipe_price DECIMAL(10,2) NOT NULL CHECK (receipe_price >= 0)
 );
 
+-- Order Payments Table (one row per tender; split orders have several)
+CREATE TABLE order_payments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    payment_method VARCHAR(50) NOT NULL CHECK (payment_method IN ('cash', 'card', 'sinpe')),
+    amount DECIMAL(10,2) NOT NULL CHECK (amount >= 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Order Events Table (lifecycle history behind the order timeline)
+CREATE TABLE order_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    event_type VARCHAR(30) NOT NULL CHECK (event_type IN ('created', 'status_changed', 'cancelled', 'reopened', 'payment_recorded', 'payments_updated', 'updated')),
+    order_status VARCHAR(20) NOT NULL, -- status of the order after the event
+    actor VARCHAR(100), -- X-User-ID of the staff member, when the request came through the gateway
+    notes TEXT,
+    created_at TIMESTAMP DEFAULT clock_timestamp() -- wall clock so events in one transaction stay ordered
+);
+
+-- Outbox Table (notifications written with the change that caused them, relayed after commit)
+CREATE TABLE outbox (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    aggregate_id UUID NOT NULL,
+    event_type VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    next_attempt_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    sent_at TIMESTAMP
+);
+
 -- =============================================================================
 -- PROMOTIONS & LOYALTY SYSTEM ENTITIES
 -- =============================================================================
@@ -396,20 +475,26 @@ CREATE INDEX idx_ingredient_categories_active ON ingredient_categories(is_active
 CREATE INDEX idx_ingredients_name ON ingredients(name);
 CREATE INDEX idx_ingredients_category ON ingredients(ingredient_category_id);
 CREATE INDEX idx_ingredients_supplier ON ingredients(supplier_id);
+CREATE INDEX idx_ingredients_tags ON ingredients USING GIN (tags);
 CREATE INDEX idx_existences_ingredient ON existences(ingredient_id);
 CREATE INDEX idx_existences_reference_code ON existences(existence_reference_code);
 CREATE INDEX idx_existences_invoice_detail ON existences(invoice_detail_id);
 CREATE INDEX idx_existences_available ON existences(units_available);
 CREATE INDEX idx_existences_cost_per_item ON existences(cost_per_item);
 CREATE INDEX idx_existences_expiration_date ON existences(expiration_date);
+CREATE INDEX idx_existence_movements_existence ON existence_movements(existence_id, created_at);
 CREATE INDEX idx_recipe_ingredients_recipe_id ON recipe_ingredients(recipe_id);
 CREATE INDEX idx_recipe_ingredients_ingredient_id ON recipe_ingredients(ingredient_id);
+CREATE INDEX idx_unit_conversions_ingredient ON unit_conversions(ingredient_id);
 
 -- Orders indexes
 CREATE INDEX idx_orders_customer_id ON orders(customer_id);
 CREATE INDEX idx_orders_created_at ON orders(created_at);
 CREATE INDEX idx_ordered_receipes_order_id ON ordered_receipes(order_id);
 CREATE INDEX idx_ordered_receipes_recipe_id ON ordered_receipes(recipe_id);
+CREATE INDEX idx_order_payments_order_id ON order_payments(order_id);
+CREATE INDEX idx_order_events_order_id ON order_events(order_id, created_at);
+CREATE INDEX idx_outbox_pending ON outbox(next_attempt_at) WHERE sent_at IS NULL;
 
 -- Expenses indexes
 CREATE INDEX idx_expenses_category_id ON expenses(expense_category_id);
@@ -438,6 +523,7 @@ CREATE INDEX idx_invoice_supplier ON invoice(supplier_id);
 CREATE INDEX idx_invoice_category ON invoice(expense_category_id);
 CREATE INDEX idx_invoice_transaction_date ON invoice(transaction_date);
 CREATE INDEX idx_invoice_transaction_type ON invoice(transaction_type);
+CREATE INDEX idx_invoice_deleted_at ON invoice(deleted_at);
 CREATE INDEX idx_invoice_details_invoice ON invoice_details(invoice_id);
 CREATE INDEX idx_invoice_details_ingredient ON invoice_details(ingredient_id);
 CREATE INDEX idx_invoice_details_total ON invoice_details(total);
@@ -470,7 +556,10 @@ INSERT INTO system_configuration (config_key, config_value, description) VALUES
 ('currency', 'USD', 'Default currency for transactions'),
 ('tax_rate', '0.08', 'Default tax rate as decimal (8%)'),
 ('loyalty_points_rate', '0.01', 'Points earned per dollar spent'),
-('max_order_items', '50', 'Maximum items allowed per order');
+('max_order_items', '50', 'Maximum items allowed per order'),
+('income_margin_percentage', '30', 'Default income margin (%) for existences created from invoices'),
+('iva_percentage', '13', 'Default IVA (%) for existences created from invoices'),
+('service_tax_percentage', '10', 'Default service tax (%) for existences created from invoices');
 
 -- Insert default roles
 INSERT INTO roles (role_name, description) VALUES
@@ -573,6 +662,19 @@ BEGIN
 END;
 $$ language 'plpgsql';
 
+-- Record the opening stock of every new existence, whichever service inserts it
+CREATE OR REPLACE FUNCTION record_existence_purchase()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO existence_movements (existence_id, movement_type, quantity_change)
+    VALUES (NEW.id, 'purchase', NEW.units_available);
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER record_existences_purchase AFTER INSERT ON existences
+    FOR EACH ROW EXECUTE FUNCTION record_existence_purchase();
+
 -- Apply update triggers to all tables with updated_at
 CREATE TRIGGER update_suppliers_updated_at BEFORE UPDATE ON suppliers 
     FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -595,6 +697,9 @@ CREATE TRIGGER update_recipes_updated_at BEFORE UPDATE ON recipes
 CREATE TRIGGER update_recipe_ingredients_updated_at BEFORE UPDATE ON recipe_ingredients 
     FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 
+CREATE TRIGGER update_unit_conversions_updated_at BEFORE UPDATE ON unit_conversions 
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
 CREATE TRIGGER update_runout_ingredient_report_updated_at BEFORE UPDATE ON runout_ingredient_report 
     FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 
@@ -8,10 +8,13 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"data-service/pkg/database"
+	"shared/httpx"
+	"shared/version"
 
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
@@ -33,7 +36,13 @@ func main() {
 		User:     "postgres",
 		Password: "postgres123",
 		DBName:   "icecream_store",
-		SSLMode:  "disable",
+		SSLMode:  getEnv("DB_SSLMODE", "disable"),
+
+		// Set DB_REQUIRE_SSL=true in production to refuse unencrypted connections
+		RequireSSL: getEnv("DB_REQUIRE_SSL", "false") == "true",
+
+		// Comma-separated read replicas, e.g. DB_REPLICA_HOSTS=replica-1,replica-2:6432
+		ReplicaHosts: splitList(getEnv("DB_REPLICA_HOSTS", "")),
 
 		// Connection pool settings
 		MaxOpenConns:    25,
@@ -41,28 +50,40 @@ func main() {
 		ConnMaxLifetime: 5 * time.Minute,
 		ConnMaxIdleTime: 5 * time.Minute,
 
+		// Prepared statements kept for ExecCached/QueryCached (0 disables the cache)
+		StatementCacheSize: 100,
+
 		// Timeout settings
-		ConnectTimeout: 10 * time.Second,
-		QueryTimeout:   30 * time.Second,
+		ConnectTimeout:  10 * time.Second,
+		QueryTimeout:    30 * time.Second,
+		ShutdownTimeout: 15 * time.Second,
+
+		// Background ping that detects a restarted database and reconnects
+		KeepAliveInterval: 30 * time.Second,
 
 		// Retry settings
 		MaxRetries:    3,
 		RetryInterval: 1 * time.Second,
 	}
 
+	if err := config.Validate(); err != nil {
+		log.Fatalf("Invalid database configuration: %v", err)
+	}
+
 	// Create database handler
 	db := database.New(config, logger)
 
 	// Connect to database
 	fmt.Println("🍦 Connecting to Ice Cream Store Data Service...")
 	if err := db.Connect(); err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		log.Fatalf("Failed to connect to database (%s): %s", config.RedactedString(), config.Redact(err.Error()))
 	}
+	// Runs after the HTTP server has shut down; waits for in-flight transactions before closing
 	defer db.Close()
 
 	// Perform initial health check
 	if err := db.HealthCheck(); err != nil {
-		log.Fatalf("Initial database health check failed: %v", err)
+		log.Fatalf("Initial database health check failed: %s", config.Redact(err.Error()))
 	}
 
 	fmt.Println("✅ Database connection established successfully")
@@ -121,6 +142,9 @@ func setupRouter(db database.DatabaseHandler, logger *logrus.Logger) *mux.Router
 		statsEndpoint(w, r, db, logger)
 	}).Methods("GET")
 
+	// Unmatched routes get JSON 404/405 responses instead of mux's plain text
+	httpx.SetRouteErrorHandlers(router)
+
 	return router
 }
 
@@ -129,6 +153,8 @@ func healthCheck(w http.ResponseWriter, r *http.Request, db database.DatabaseHan
 	response := map[string]interface{}{
 		"service":   "data-service",
 		"timestamp": time.Now(),
+		"version":   version.Version,
+		"build":     version.Info(),
 	}
 
 	// Perform database health check
@@ -167,11 +193,12 @@ func statsEndpoint(w http.ResponseWriter, r *http.Request, db database.DatabaseH
 		"service":   "data-service",
 		"timestamp": time.Now(),
 		"database_stats": map[string]interface{}{
-			"open_connections": stats.OpenConnections,
-			"in_use":           stats.InUse,
-			"idle":             stats.Idle,
-			"wait_count":       stats.WaitCount,
-			"wait_duration":    stats.WaitDuration.String(),
+			"open_connections":    stats.OpenConnections,
+			"in_use":              stats.InUse,
+			"idle":                stats.Idle,
+			"wait_count":          stats.WaitCount,
+			"wait_duration":       stats.WaitDuration.String(),
+			"active_transactions": db.ActiveTransactions(),
 		},
 	}
 
@@ -179,3 +206,22 @@ func statsEndpoint(w http.ResponseWriter, r *http.Request, db database.DatabaseH
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
 }
+
+// getEnv returns the value of an environment variable or a default when it is unset
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// splitList splits a comma-separated value, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -3,7 +3,12 @@ package database
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/lib/pq"
@@ -29,6 +34,12 @@ type DatabaseHandler interface {
 	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
 	QueryRow(query string, args ...interface{}) *sql.Row
 	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	QueryRowScan(ctx context.Context, query string, args []interface{}, dest ...interface{}) error
+	QueryLimited(ctx context.Context, maxRows int, query string, args ...interface{}) (*LimitedRows, error)
+
+	// Read-only queries routed to a replica when one is configured (writes always use the primary)
+	QueryReplica(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowReplica(ctx context.Context, query string, args []interface{}, dest ...interface{}) error
 
 	// Execute operations
 	Exec(query string, args ...interface{}) (sql.Result, error)
@@ -38,10 +49,15 @@ type DatabaseHandler interface {
 	Prepare(query string) (*sql.Stmt, error)
 	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
 
+	// Cached prepared statements for hot queries
+	ExecCached(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryCached(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+
 	// Utility methods
 	GetDB() *sql.DB
 	GetStats() sql.DBStats
 	IsConnected() bool
+	ActiveTransactions() int
 }
 
 // Config holds database configuration
@@ -53,21 +69,51 @@ type Config struct {
 	DBName   string
 	SSLMode  string
 
+	// RequireSSL rejects SSLMode "disable", e.g. in production
+	RequireSSL bool
+
+	// ReplicaHosts lists read replicas as "host" or "host:port"; they share the primary's credentials
+	ReplicaHosts []string
+
 	// Connection pool settings
 	MaxOpenConns    int
 	MaxIdleConns    int
 	ConnMaxLifetime time.Duration
 	ConnMaxIdleTime time.Duration
 
+	// StatementCacheSize is how many prepared statements ExecCached and QueryCached keep (zero disables caching)
+	StatementCacheSize int
+
 	// Timeout settings
 	ConnectTimeout time.Duration
 	QueryTimeout   time.Duration
 
+	// KeepAliveInterval is how often the pool is pinged in the background to detect a restarted
+	// database (zero disables the keep-alive)
+	KeepAliveInterval time.Duration
+
+	// ShutdownTimeout bounds how long Close waits for in-flight transactions (zero disables waiting)
+	ShutdownTimeout time.Duration
+
 	// Retry settings
 	MaxRetries    int
 	RetryInterval time.Duration
 }
 
+// validSSLModes are the sslmode values lib/pq accepts; it does not implement allow or prefer
+var validSSLModes = []string{"disable", "require", "verify-ca", "verify-full"}
+
+// Validate checks the configuration so a typo fails fast instead of producing a bad DSN
+func (c *Config) Validate() error {
+	if !slices.Contains(validSSLModes, c.SSLMode) {
+		return fmt.Errorf("invalid SSL mode %q: must be one of %s", c.SSLMode, strings.Join(validSSLModes, ", "))
+	}
+	if c.RequireSSL && c.SSLMode == "disable" {
+		return fmt.Errorf("SSL mode %q is not allowed when SSL is required", c.SSLMode)
+	}
+	return nil
+}
+
 // DefaultConfig returns a default configuration
 func DefaultConfig() *Config {
 	return &Config{
@@ -81,9 +127,14 @@ func DefaultConfig() *Config {
 		ConnMaxLifetime: 5 * time.Minute,
 		ConnMaxIdleTime: 5 * time.Minute,
 
+		StatementCacheSize: 100,
+
 		// Timeout defaults
-		ConnectTimeout: 10 * time.Second,
-		QueryTimeout:   30 * time.Second,
+		ConnectTimeout:  10 * time.Second,
+		QueryTimeout:    30 * time.Second,
+		ShutdownTimeout: 15 * time.Second,
+
+		KeepAliveInterval: 30 * time.Second,
 
 		// Retry defaults
 		MaxRetries:    3,
@@ -91,12 +142,31 @@ func DefaultConfig() *Config {
 	}
 }
 
+// txDrainPollInterval is how often Close re-checks for in-flight transactions
+const txDrainPollInterval = 10 * time.Millisecond
+
 // dbHandler implements the DatabaseHandler interface
 type dbHandler struct {
 	db        *sql.DB
 	config    *Config
 	logger    *logrus.Logger
-	connected bool
+	connected atomic.Bool
+
+	// Transactions begun through BeginTx that have not been committed or rolled back yet
+	txMu      sync.Mutex
+	activeTxs map[*sql.Tx]struct{}
+
+	// Prepared statements reused by ExecCached and QueryCached; nil when caching is disabled
+	stmtCache *statementCache
+
+	// Read replicas used by QueryReplica and QueryRowReplica, picked round-robin
+	replicas    []*sql.DB
+	nextReplica atomic.Uint64
+
+	// Background keep-alive started by Connect when KeepAliveInterval is set
+	keepAliveStop chan struct{}
+	keepAliveDone chan struct{}
+	ping          func() error // replaceable in tests; defaults to Ping
 }
 
 // New creates a new database handler instance
@@ -113,11 +183,14 @@ func New(config *Config, logger *logrus.Logger) DatabaseHandler {
 		})
 	}
 
-	return &dbHandler{
-		config:    config,
-		logger:    logger,
-		connected: false,
+	handler := &dbHandler{
+		config: config,
+		logger: logger,
+	}
+	if config.StatementCacheSize > 0 {
+		handler.stmtCache = newStatementCache(config.StatementCacheSize)
 	}
+	return handler
 }
 
 // Connect establishes a connection to the database
@@ -127,8 +200,13 @@ func (h *dbHandler) Connect() error {
 		"port":   h.config.Port,
 		"dbname": h.config.DBName,
 		"user":   h.config.User,
+		"dsn":    h.config.RedactedString(),
 	}).Info("Attempting to connect to database")
 
+	if err := h.config.Validate(); err != nil {
+		return fmt.Errorf("invalid database configuration: %w", err)
+	}
+
 	// Build connection string
 	connStr := h.buildConnectionString()
 
@@ -141,7 +219,8 @@ func (h *dbHandler) Connect() error {
 		if err != nil {
 			h.logger.WithFields(logrus.Fields{
 				"attempt": attempt,
-				"error":   err.Error(),
+				"dsn":     h.config.RedactedString(),
+				"error":   h.config.Redact(err.Error()),
 			}).Warn("Failed to open database connection")
 
 			if attempt < h.config.MaxRetries {
@@ -159,7 +238,8 @@ func (h *dbHandler) Connect() error {
 		if err != nil {
 			h.logger.WithFields(logrus.Fields{
 				"attempt": attempt,
-				"error":   err.Error(),
+				"dsn":     h.config.RedactedString(),
+				"error":   h.config.Redact(err.Error()),
 			}).Warn("Failed to ping database")
 
 			db.Close()
@@ -177,7 +257,7 @@ func (h *dbHandler) Connect() error {
 	h.configureConnectionPool(db)
 
 	h.db = db
-	h.connected = true
+	h.connected.Store(true)
 
 	h.logger.WithFields(logrus.Fields{
 		"host":   h.config.Host,
@@ -185,6 +265,9 @@ func (h *dbHandler) Connect() error {
 		"dbname": h.config.DBName,
 	}).Info("Successfully connected to database")
 
+	h.connectReplicas()
+	h.startKeepAlive()
+
 	return nil
 }
 
@@ -194,6 +277,28 @@ func (h *dbHandler) Close() error {
 		return nil
 	}
 
+	h.stopKeepAlive()
+
+	if active := h.ActiveTransactions(); active > 0 && h.config.ShutdownTimeout > 0 {
+		h.logger.WithFields(logrus.Fields{
+			"active_transactions": active,
+			"timeout":             h.config.ShutdownTimeout,
+		}).Info("Waiting for in-flight transactions before closing database connection")
+
+		if !h.waitForTransactions(h.config.ShutdownTimeout) {
+			h.logger.WithField("active_transactions", h.ActiveTransactions()).
+				Warn("Shutdown timeout reached with transactions still in flight")
+		}
+	}
+
+	if h.stmtCache != nil {
+		if err := h.stmtCache.closeAll(); err != nil {
+			h.logger.WithError(err).Warn("Failed to close cached prepared statements")
+		}
+	}
+
+	h.closeReplicas()
+
 	h.logger.Info("Closing database connection")
 
 	err := h.db.Close()
@@ -202,7 +307,7 @@ func (h *dbHandler) Close() error {
 		return err
 	}
 
-	h.connected = false
+	h.connected.Store(false)
 	h.logger.Info("Database connection closed successfully")
 	return nil
 }
@@ -219,11 +324,11 @@ func (h *dbHandler) Ping() error {
 	err := h.db.PingContext(ctx)
 	if err != nil {
 		h.logger.WithError(err).Error("Database ping failed")
-		h.connected = false
+		h.connected.Store(false)
 		return err
 	}
 
-	h.connected = true
+	h.connected.Store(true)
 	return nil
 }
 
@@ -271,6 +376,7 @@ func (h *dbHandler) BeginTx(ctx context.Context) (*sql.Tx, error) {
 		return nil, err
 	}
 
+	h.trackTx(tx)
 	h.logger.Debug("Transaction started")
 	return tx, nil
 }
@@ -281,6 +387,9 @@ func (h *dbHandler) CommitTx(tx *sql.Tx) error {
 		return fmt.Errorf("transaction is nil")
 	}
 
+	// A failed commit still ends the transaction, so it is no longer in flight
+	defer h.untrackTx(tx)
+
 	err := tx.Commit()
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to commit transaction")
@@ -297,6 +406,8 @@ func (h *dbHandler) RollbackTx(tx *sql.Tx) error {
 		return fmt.Errorf("transaction is nil")
 	}
 
+	defer h.untrackTx(tx)
+
 	err := tx.Rollback()
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to rollback transaction")
@@ -362,6 +473,52 @@ func (h *dbHandler) QueryRowContext(ctx context.Context, query string, args ...i
 	return row
 }
 
+// QueryError is returned by QueryRowScan when the query itself failed (lost connection,
+// constraint violation, bad SQL) as opposed to matching no rows
+type QueryError struct {
+	Query string
+	Err   error
+}
+
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("query failed: %v", e.Err)
+}
+
+func (e *QueryError) Unwrap() error {
+	return e.Err
+}
+
+// QueryRowScan runs a single-row query and scans it into dest. Unlike QueryRow, failures are
+// classified up front: a query matching nothing returns sql.ErrNoRows unchanged, and every other
+// failure is run through handlePostgreSQLError and returned as a *QueryError.
+func (h *dbHandler) QueryRowScan(ctx context.Context, query string, args []interface{}, dest ...interface{}) error {
+	if h.db == nil {
+		return &QueryError{Query: h.sanitizeQuery(query), Err: fmt.Errorf("database connection is nil")}
+	}
+
+	start := time.Now()
+	err := h.db.QueryRowContext(ctx, query, args...).Scan(dest...)
+	duration := time.Since(start)
+
+	logEntry := h.logger.WithFields(logrus.Fields{
+		"query":      h.sanitizeQuery(query),
+		"duration":   duration,
+		"args_count": len(args),
+	})
+
+	if errors.Is(err, sql.ErrNoRows) {
+		logEntry.Debug("QueryRowScan matched no rows")
+		return sql.ErrNoRows
+	}
+	if err != nil {
+		logEntry.WithError(err).Error("QueryRowScan execution failed")
+		return &QueryError{Query: h.sanitizeQuery(query), Err: h.handlePostgreSQLError(err)}
+	}
+
+	logEntry.Debug("QueryRowScan executed successfully")
+	return nil
+}
+
 // Exec executes a query without returning rows
 func (h *dbHandler) Exec(query string, args ...interface{}) (sql.Result, error) {
 	return h.ExecContext(context.Background(), query, args...)
@@ -418,6 +575,65 @@ func (h *dbHandler) PrepareContext(ctx context.Context, query string) (*sql.Stmt
 	return stmt, nil
 }
 
+// ExecCached executes a query through a cached prepared statement, preparing it on first use
+func (h *dbHandler) ExecCached(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if h.stmtCache == nil {
+		return h.ExecContext(ctx, query, args...)
+	}
+
+	entry, err := h.cachedStatement(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer h.stmtCache.release(entry)
+
+	result, err := entry.stmt.ExecContext(ctx, args...)
+	if err != nil {
+		h.logger.WithFields(logrus.Fields{
+			"query": h.sanitizeQuery(query),
+		}).WithError(err).Error("Cached exec execution failed")
+		return nil, h.handlePostgreSQLError(err)
+	}
+	return result, nil
+}
+
+// QueryCached runs a query through a cached prepared statement, preparing it on first use
+func (h *dbHandler) QueryCached(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if h.stmtCache == nil {
+		return h.QueryContext(ctx, query, args...)
+	}
+
+	entry, err := h.cachedStatement(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	// Rows keep the statement alive on their own, so it can be released before they are read
+	defer h.stmtCache.release(entry)
+
+	rows, err := entry.stmt.QueryContext(ctx, args...)
+	if err != nil {
+		h.logger.WithFields(logrus.Fields{
+			"query": h.sanitizeQuery(query),
+		}).WithError(err).Error("Cached query execution failed")
+		return nil, h.handlePostgreSQLError(err)
+	}
+	return rows, nil
+}
+
+// cachedStatement returns the cached statement for query, preparing and caching it on a miss.
+// Callers must release the returned entry.
+func (h *dbHandler) cachedStatement(ctx context.Context, query string) (*cachedStatement, error) {
+	if entry := h.stmtCache.acquire(query); entry != nil {
+		return entry, nil
+	}
+
+	stmt, err := h.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return h.stmtCache.add(query, stmt), nil
+}
+
 // GetDB returns the underlying sql.DB instance
 func (h *dbHandler) GetDB() *sql.DB {
 	return h.db
@@ -433,23 +649,84 @@ func (h *dbHandler) GetStats() sql.DBStats {
 
 // IsConnected returns the connection status
 func (h *dbHandler) IsConnected() bool {
-	return h.connected && h.db != nil
+	return h.connected.Load() && h.db != nil
+}
+
+// ActiveTransactions returns the number of transactions begun through BeginTx that are still open
+func (h *dbHandler) ActiveTransactions() int {
+	h.txMu.Lock()
+	defer h.txMu.Unlock()
+	return len(h.activeTxs)
+}
+
+// trackTx marks a transaction as in flight
+func (h *dbHandler) trackTx(tx *sql.Tx) {
+	h.txMu.Lock()
+	defer h.txMu.Unlock()
+	if h.activeTxs == nil {
+		h.activeTxs = make(map[*sql.Tx]struct{})
+	}
+	h.activeTxs[tx] = struct{}{}
+}
+
+// untrackTx marks a transaction as finished; it is safe to call more than once
+func (h *dbHandler) untrackTx(tx *sql.Tx) {
+	h.txMu.Lock()
+	defer h.txMu.Unlock()
+	delete(h.activeTxs, tx)
+}
+
+// waitForTransactions blocks until no transactions are in flight or the timeout elapses,
+// reporting whether all transactions finished in time
+func (h *dbHandler) waitForTransactions(timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	ticker := time.NewTicker(txDrainPollInterval)
+	defer ticker.Stop()
+
+	for h.ActiveTransactions() > 0 {
+		if !time.Now().Before(deadline) {
+			return false
+		}
+		<-ticker.C
+	}
+	return true
 }
 
 // buildConnectionString creates the PostgreSQL connection string
 func (h *dbHandler) buildConnectionString() string {
+	return h.config.connectionString(h.config.Password)
+}
+
+// redactedPassword replaces the password in connection strings and messages that are logged
+const redactedPassword = "***"
+
+// RedactedString returns the connection string with the password masked, safe to log
+func (c *Config) RedactedString() string {
+	return c.connectionString(redactedPassword)
+}
+
+// connectionString formats the PostgreSQL connection string with the given password
+func (c *Config) connectionString(password string) string {
 	return fmt.Sprintf(
 		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s connect_timeout=%d",
-		h.config.Host,
-		h.config.Port,
-		h.config.User,
-		h.config.Password,
-		h.config.DBName,
-		h.config.SSLMode,
-		int(h.config.ConnectTimeout.Seconds()),
+		c.Host,
+		c.Port,
+		c.User,
+		password,
+		c.DBName,
+		c.SSLMode,
+		int(c.ConnectTimeout.Seconds()),
 	)
 }
 
+// Redact masks any occurrence of the password in a message, e.g. a driver error that echoes the DSN
+func (c *Config) Redact(message string) string {
+	if c.Password == "" {
+		return message
+	}
+	return strings.ReplaceAll(message, c.Password, redactedPassword)
+}
+
 // configureConnectionPool sets up the connection pool
 func (h *dbHandler) configureConnectionPool(db *sql.DB) {
 	db.SetMaxOpenConns(h.config.MaxOpenConns)
@@ -488,17 +765,23 @@ func (h *dbHandler) handlePostgreSQLError(err error) error {
 			"column":     pqErr.Column,
 		}).Error("PostgreSQL error occurred")
 
-		// Map PostgreSQL errors to custom errors if needed
-		switch pqErr.Code {
-		case "23505": // unique_violation
-			return fmt.Errorf("duplicate entry: %s", pqErr.Detail)
-		case "23503": // foreign_key_violation
-			return fmt.Errorf("foreign key constraint violation: %s", pqErr.Detail)
-		case "23502": // not_null_violation
-			return fmt.Errorf("required field missing: %s", pqErr.Column)
+		// Map PostgreSQL errors to a DBError with a stable code clients can switch on
+		dbErr := &DBError{
+			Code:     codeForSQLState(string(pqErr.Code)),
+			SQLState: string(pqErr.Code),
+			Err:      pqErr,
+		}
+		switch dbErr.Code {
+		case CodeDuplicateEntry:
+			dbErr.Message = fmt.Sprintf("duplicate entry: %s", pqErr.Detail)
+		case CodeFKViolation:
+			dbErr.Message = fmt.Sprintf("foreign key constraint violation: %s", pqErr.Detail)
+		case CodeRequiredFieldMissing:
+			dbErr.Message = fmt.Sprintf("required field missing: %s", pqErr.Column)
 		default:
-			return fmt.Errorf("database error [%s]: %s", pqErr.Code, pqErr.Message)
+			dbErr.Message = fmt.Sprintf("database error [%s]: %s", pqErr.Code, pqErr.Message)
 		}
+		return dbErr
 	}
 
 	return err
@@ -10,6 +10,7 @@ import (
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/lib/pq"
 	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -30,11 +31,11 @@ func setupTestDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock, DatabaseHandler) {
 	config.DBName = "test-db"
 
 	handler := &dbHandler{
-		db:        db,
-		config:    config,
-		logger:    logger,
-		connected: true,
+		db:     db,
+		config: config,
+		logger: logger,
 	}
+	handler.connected.Store(true)
 
 	return db, mock, handler
 }
@@ -56,8 +57,10 @@ func TestDefaultConfig(t *testing.T) {
 	assert.Equal(t, 5, config.MaxIdleConns)
 	assert.Equal(t, 5*time.Minute, config.ConnMaxLifetime)
 	assert.Equal(t, 5*time.Minute, config.ConnMaxIdleTime)
+	assert.Equal(t, 100, config.StatementCacheSize)
 	assert.Equal(t, 10*time.Second, config.ConnectTimeout)
 	assert.Equal(t, 30*time.Second, config.QueryTimeout)
+	assert.Equal(t, 30*time.Second, config.KeepAliveInterval)
 	assert.Equal(t, 3, config.MaxRetries)
 	assert.Equal(t, 1*time.Second, config.RetryInterval)
 }
@@ -151,6 +154,110 @@ func TestClose(t *testing.T) {
 	})
 }
 
+// TestCloseWaitsForInFlightTransactions tests that Close blocks until open transactions finish or the timeout passes
+func TestCloseWaitsForInFlightTransactions(t *testing.T) {
+	tests := []struct {
+		name            string
+		shutdownTimeout time.Duration
+		commitAfter     time.Duration
+		expectDrained   bool
+		minWait         time.Duration
+		maxWait         time.Duration
+	}{
+		{
+			name:            "close waits for transaction to commit",
+			shutdownTimeout: 2 * time.Second,
+			commitAfter:     100 * time.Millisecond,
+			expectDrained:   true,
+			minWait:         100 * time.Millisecond,
+			maxWait:         time.Second,
+		},
+		{
+			name:            "close gives up when timeout passes",
+			shutdownTimeout: 100 * time.Millisecond,
+			expectDrained:   false,
+			minWait:         100 * time.Millisecond,
+			maxWait:         time.Second,
+		},
+		{
+			name:            "zero timeout closes immediately",
+			shutdownTimeout: 0,
+			expectDrained:   false,
+			minWait:         0,
+			maxWait:         50 * time.Millisecond,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, mock, handler := setupTestDB(t)
+			handler.(*dbHandler).config.ShutdownTimeout = tt.shutdownTimeout
+
+			mock.ExpectBegin()
+			tx, err := handler.BeginTx(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, 1, handler.ActiveTransactions())
+
+			// Register every expectation before the committing goroutine starts
+			if tt.commitAfter > 0 {
+				mock.ExpectCommit()
+			}
+			mock.ExpectClose()
+
+			committed := make(chan struct{})
+			if tt.commitAfter > 0 {
+				go func() {
+					defer close(committed)
+					time.Sleep(tt.commitAfter)
+					assert.NoError(t, handler.CommitTx(tx))
+				}()
+			}
+
+			start := time.Now()
+			err = handler.Close()
+			elapsed := time.Since(start)
+
+			assert.NoError(t, err)
+			assert.GreaterOrEqual(t, elapsed, tt.minWait)
+			assert.Less(t, elapsed, tt.maxWait)
+
+			if tt.expectDrained {
+				<-committed
+				assert.Equal(t, 0, handler.ActiveTransactions())
+				assert.NoError(t, mock.ExpectationsWereMet())
+			} else {
+				assert.Equal(t, 1, handler.ActiveTransactions())
+			}
+		})
+	}
+}
+
+// TestActiveTransactions tests that commit and rollback stop tracking a transaction exactly once
+func TestActiveTransactions(t *testing.T) {
+	db, mock, handler := setupTestDB(t)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectBegin()
+	first, err := handler.BeginTx(context.Background())
+	require.NoError(t, err)
+	second, err := handler.BeginTx(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, handler.ActiveTransactions())
+
+	mock.ExpectCommit()
+	require.NoError(t, handler.CommitTx(first))
+	assert.Equal(t, 1, handler.ActiveTransactions())
+
+	// Rolling back an already committed transaction fails but must not affect the count
+	assert.Error(t, handler.RollbackTx(first))
+	assert.Equal(t, 1, handler.ActiveTransactions())
+
+	mock.ExpectRollback()
+	require.NoError(t, handler.RollbackTx(second))
+	assert.Equal(t, 0, handler.ActiveTransactions())
+}
+
 // TestPing tests database ping functionality
 func TestPing(t *testing.T) {
 	t.Run("successful ping", func(t *testing.T) {
@@ -539,6 +646,93 @@ func TestQueryRowContextWithNilDB(t *testing.T) {
 	assert.Nil(t, row)
 }
 
+// TestQueryRowScan tests that the wrapper tells no rows apart from query failures
+func TestQueryRowScan(t *testing.T) {
+	tests := []struct {
+		name             string
+		setupMock        func(sqlmock.Sqlmock)
+		expectNoRows     bool
+		expectQueryError string
+		expectedName     string
+	}{
+		{
+			name: "row found",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT name FROM users WHERE id = \\$1").
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("John"))
+			},
+			expectedName: "John",
+		},
+		{
+			name: "no rows",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT name FROM users WHERE id = \\$1").
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"name"}))
+			},
+			expectNoRows: true,
+		},
+		{
+			name: "connection failure",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT name FROM users WHERE id = \\$1").
+					WithArgs(1).
+					WillReturnError(errors.New("dial tcp 127.0.0.1:5432: connect: connection refused"))
+			},
+			expectQueryError: "query failed: dial tcp 127.0.0.1:5432: connect: connection refused",
+		},
+		{
+			name: "postgres error is classified",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT name FROM users WHERE id = \\$1").
+					WithArgs(1).
+					WillReturnError(&pq.Error{Code: "23503", Detail: "Key (id)=(1) is not present"})
+			},
+			expectQueryError: "query failed: foreign key constraint violation: Key (id)=(1) is not present",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, handler := setupTestDB(t)
+			defer db.Close()
+
+			tt.setupMock(mock)
+
+			var name string
+			err := handler.QueryRowScan(context.Background(), "SELECT name FROM users WHERE id = $1", []interface{}{1}, &name)
+
+			var queryErr *QueryError
+			switch {
+			case tt.expectNoRows:
+				assert.ErrorIs(t, err, sql.ErrNoRows)
+				assert.False(t, errors.As(err, &queryErr))
+			case tt.expectQueryError != "":
+				require.True(t, errors.As(err, &queryErr))
+				assert.EqualError(t, err, tt.expectQueryError)
+				assert.NotErrorIs(t, err, sql.ErrNoRows)
+			default:
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectedName, name)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// TestQueryRowScanWithNilDB tests that a missing connection surfaces as a query error
+func TestQueryRowScanWithNilDB(t *testing.T) {
+	handler := New(DefaultConfig(), setupTestLogger())
+
+	var result int
+	err := handler.QueryRowScan(context.Background(), "SELECT 1", nil, &result)
+
+	var queryErr *QueryError
+	assert.True(t, errors.As(err, &queryErr))
+}
+
 // TestExec tests query execution without returning rows
 func TestExec(t *testing.T) {
 	tests := []struct {
@@ -698,6 +892,97 @@ func TestPrepareContextWithNilDB(t *testing.T) {
 	assert.Contains(t, err.Error(), "database connection is nil")
 }
 
+// setupCachedTestDB creates a handler over sqlmock with a prepared statement cache of the given size
+func setupCachedTestDB(t *testing.T, cacheSize int) (*sql.DB, sqlmock.Sqlmock, *dbHandler) {
+	db, mock, handler := setupTestDB(t)
+	h := handler.(*dbHandler)
+	if cacheSize > 0 {
+		h.stmtCache = newStatementCache(cacheSize)
+	}
+	return db, mock, h
+}
+
+// TestExecCachedReusesStatement tests that a repeated query is prepared only once
+func TestExecCachedReusesStatement(t *testing.T) {
+	db, mock, handler := setupCachedTestDB(t, 10)
+	defer db.Close()
+
+	prep := mock.ExpectPrepare("UPDATE users SET name = \\$1 WHERE id = \\$2")
+	prep.ExpectExec().WithArgs("First", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	prep.ExpectExec().WithArgs("Second", 2).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	_, err := handler.ExecCached(context.Background(), "UPDATE users SET name = $1 WHERE id = $2", "First", 1)
+	require.NoError(t, err)
+	_, err = handler.ExecCached(context.Background(), "UPDATE users SET name = $1 WHERE id = $2", "Second", 2)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, handler.stmtCache.len())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestQueryCachedReusesStatement tests that cached queries share one prepared statement
+func TestQueryCachedReusesStatement(t *testing.T) {
+	db, mock, handler := setupCachedTestDB(t, 10)
+	defer db.Close()
+
+	prep := mock.ExpectPrepare("SELECT name FROM users WHERE id = \\$1")
+	prep.ExpectQuery().WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("John"))
+	prep.ExpectQuery().WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("Jane"))
+
+	for _, id := range []int{1, 2} {
+		rows, err := handler.QueryCached(context.Background(), "SELECT name FROM users WHERE id = $1", id)
+		require.NoError(t, err)
+		require.True(t, rows.Next())
+		rows.Close()
+	}
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestExecCachedDisabled tests that a zero cache size executes queries directly without preparing them
+func TestExecCachedDisabled(t *testing.T) {
+	db, mock, handler := setupCachedTestDB(t, 0)
+	defer db.Close()
+
+	mock.ExpectExec("DELETE FROM users").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM users").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	for i := 0; i < 2; i++ {
+		_, err := handler.ExecCached(context.Background(), "DELETE FROM users")
+		require.NoError(t, err)
+	}
+
+	assert.Nil(t, handler.stmtCache)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestNewStatementCacheSize tests that the cache follows the configured size
+func TestNewStatementCacheSize(t *testing.T) {
+	config := DefaultConfig()
+	assert.NotNil(t, New(config, setupTestLogger()).(*dbHandler).stmtCache)
+
+	config.StatementCacheSize = 0
+	assert.Nil(t, New(config, setupTestLogger()).(*dbHandler).stmtCache)
+}
+
+// TestCloseClosesCachedStatements tests that Close releases every cached prepared statement
+func TestCloseClosesCachedStatements(t *testing.T) {
+	_, mock, handler := setupCachedTestDB(t, 10)
+	handler.config.ShutdownTimeout = 0
+
+	prep := mock.ExpectPrepare("DELETE FROM users WHERE id = \\$1")
+	prep.ExpectExec().WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+	prep.WillBeClosed()
+	mock.ExpectClose()
+
+	_, err := handler.ExecCached(context.Background(), "DELETE FROM users WHERE id = $1", 1)
+	require.NoError(t, err)
+
+	require.NoError(t, handler.Close())
+	assert.Equal(t, 0, handler.stmtCache.len())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 // TestGetDB tests getting the underlying database instance
 func TestGetDB(t *testing.T) {
 	db, _, handler := setupTestDB(t)
@@ -804,6 +1089,129 @@ func TestBuildConnectionString(t *testing.T) {
 	assert.Equal(t, expected, connStr)
 }
 
+// TestConfigValidate tests SSL mode validation and the require-SSL option
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name          string
+		sslMode       string
+		requireSSL    bool
+		expectedError string
+	}{
+		{
+			name:    "valid mode",
+			sslMode: "verify-full",
+		},
+		{
+			name:    "disable allowed when SSL is not required",
+			sslMode: "disable",
+		},
+		{
+			name:          "invalid mode",
+			sslMode:       "requre",
+			expectedError: `invalid SSL mode "requre": must be one of disable, require, verify-ca, verify-full`,
+		},
+		{
+			name:          "empty mode",
+			sslMode:       "",
+			expectedError: `invalid SSL mode ""`,
+		},
+		{
+			name:          "disable forbidden when SSL is required",
+			sslMode:       "disable",
+			requireSSL:    true,
+			expectedError: `SSL mode "disable" is not allowed when SSL is required`,
+		},
+		{
+			name:       "require accepted when SSL is required",
+			sslMode:    "require",
+			requireSSL: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.SSLMode = tc.sslMode
+			config.RequireSSL = tc.requireSSL
+
+			err := config.Validate()
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.expectedError)
+		})
+	}
+}
+
+// TestConnectRejectsInvalidConfig tests that Connect validates the configuration before dialing
+func TestConnectRejectsInvalidConfig(t *testing.T) {
+	config := DefaultConfig()
+	config.SSLMode = "disabled"
+
+	handler := New(config, setupTestLogger())
+	err := handler.Connect()
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid database configuration")
+	assert.False(t, handler.IsConnected())
+}
+
+// TestRedactedString tests that the loggable connection string masks the password
+func TestRedactedString(t *testing.T) {
+	config := &Config{
+		Host:           "testhost",
+		Port:           5432,
+		User:           "testuser",
+		Password:       "s3cr3t-pass",
+		DBName:         "testdb",
+		SSLMode:        "require",
+		ConnectTimeout: 15 * time.Second,
+	}
+
+	redacted := config.RedactedString()
+
+	assert.Equal(t, "host=testhost port=5432 user=testuser password=*** dbname=testdb sslmode=require connect_timeout=15", redacted)
+	assert.Contains(t, redacted, "password=***")
+	assert.NotContains(t, redacted, config.Password)
+}
+
+// TestRedact tests that the password is masked wherever it appears in a message
+func TestRedact(t *testing.T) {
+	config := &Config{Password: "s3cr3t-pass"}
+
+	message := config.Redact(`pq: invalid DSN "user=postgres password=s3cr3t-pass"`)
+	assert.Equal(t, `pq: invalid DSN "user=postgres password=***"`, message)
+	assert.NotContains(t, message, config.Password)
+
+	empty := &Config{}
+	assert.Equal(t, "connection refused", empty.Redact("connection refused"))
+}
+
+// TestConnectLogsNeverContainPassword tests that connect attempts and failures only log the redacted DSN
+func TestConnectLogsNeverContainPassword(t *testing.T) {
+	config := DefaultConfig()
+	config.Host = "invalid-host"
+	config.Password = "s3cr3t-pass"
+	config.ConnectTimeout = 1 * time.Second
+	config.MaxRetries = 1
+
+	logger, hook := test.NewNullLogger()
+	handler := New(config, logger)
+
+	require.Error(t, handler.Connect())
+	require.NotEmpty(t, hook.AllEntries())
+
+	for _, entry := range hook.AllEntries() {
+		line, err := entry.String()
+		require.NoError(t, err)
+		assert.NotContains(t, line, config.Password)
+	}
+	assert.Equal(t, config.RedactedString(), hook.AllEntries()[0].Data["dsn"])
+}
+
 // TestSanitizeQuery tests query sanitization for logging
 func TestSanitizeQuery(t *testing.T) {
 	tests := []struct {
@@ -962,11 +1370,11 @@ func setupTestDBBench(b *testing.B) (*sql.DB, sqlmock.Sqlmock, DatabaseHandler)
 	config.DBName = "test-db"
 
 	handler := &dbHandler{
-		db:        db,
-		config:    config,
-		logger:    logger,
-		connected: true,
+		db:     db,
+		config: config,
+		logger: logger,
 	}
+	handler.connected.Store(true)
 
 	return db, mock, handler
 }
@@ -0,0 +1,76 @@
+package database
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Stable error codes exposed to clients; unlike SQLSTATE values they never change between
+// PostgreSQL versions and are safe to switch on
+const (
+	CodeDuplicateEntry       = "duplicate_entry"
+	CodeFKViolation          = "fk_violation"
+	CodeRequiredFieldMissing = "required_field_missing"
+	CodeDatabaseError        = "database_error"
+)
+
+// sqlStateCodes maps the PostgreSQL SQLSTATE classes handlePostgreSQLError recognises to their code
+var sqlStateCodes = map[string]string{
+	"23505": CodeDuplicateEntry,       // unique_violation
+	"23503": CodeFKViolation,          // foreign_key_violation
+	"23502": CodeRequiredFieldMissing, // not_null_violation
+}
+
+// DBError is a PostgreSQL error classified by handlePostgreSQLError, carrying a stable Code
+// alongside the human-readable message
+type DBError struct {
+	Code     string
+	SQLState string
+	Message  string
+	Err      error
+}
+
+func (e *DBError) Error() string {
+	return e.Message
+}
+
+func (e *DBError) Unwrap() error {
+	return e.Err
+}
+
+// HTTPStatus returns the response status matching the error's code
+func (e *DBError) HTTPStatus() int {
+	switch e.Code {
+	case CodeDuplicateEntry:
+		return http.StatusConflict
+	case CodeFKViolation, CodeRequiredFieldMissing:
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// codeForSQLState returns the stable code for a SQLSTATE, falling back to CodeDatabaseError
+func codeForSQLState(state string) string {
+	if code, ok := sqlStateCodes[state]; ok {
+		return code
+	}
+	return CodeDatabaseError
+}
+
+// AsDBError returns the *DBError wrapped anywhere in err's chain, if any
+func AsDBError(err error) (*DBError, bool) {
+	var dbErr *DBError
+	if errors.As(err, &dbErr) {
+		return dbErr, true
+	}
+	return nil, false
+}
+
+// ErrorCode returns the stable code for err, or CodeDatabaseError when it was not classified
+func ErrorCode(err error) string {
+	if dbErr, ok := AsDBError(err); ok {
+		return dbErr.Code
+	}
+	return CodeDatabaseError
+}
@@ -0,0 +1,109 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHandlePostgreSQLErrorCodes tests that each pq.Error class maps to its stable response code
+func TestHandlePostgreSQLErrorCodes(t *testing.T) {
+	tests := []struct {
+		name           string
+		inputError     *pq.Error
+		expectedCode   string
+		expectedStatus int
+	}{
+		{
+			name:           "unique violation",
+			inputError:     &pq.Error{Code: "23505", Detail: "Key (email)=(test@example.com) already exists."},
+			expectedCode:   CodeDuplicateEntry,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "foreign key violation",
+			inputError:     &pq.Error{Code: "23503", Detail: "Key (user_id)=(999) is not present in table users."},
+			expectedCode:   CodeFKViolation,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "not null violation",
+			inputError:     &pq.Error{Code: "23502", Column: "email"},
+			expectedCode:   CodeRequiredFieldMissing,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "undefined table",
+			inputError:     &pq.Error{Code: "42P01", Message: "relation does not exist"},
+			expectedCode:   CodeDatabaseError,
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:           "check violation",
+			inputError:     &pq.Error{Code: "23514", Message: "new row violates check constraint"},
+			expectedCode:   CodeDatabaseError,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &dbHandler{
+				logger: setupTestLogger(),
+			}
+
+			result := handler.handlePostgreSQLError(tt.inputError)
+
+			dbErr, ok := AsDBError(result)
+			require.True(t, ok)
+			assert.Equal(t, tt.expectedCode, dbErr.Code)
+			assert.Equal(t, string(tt.inputError.Code), dbErr.SQLState)
+			assert.Equal(t, tt.expectedStatus, dbErr.HTTPStatus())
+			assert.Equal(t, tt.expectedCode, ErrorCode(result))
+			assert.ErrorIs(t, result, tt.inputError)
+		})
+	}
+}
+
+// TestErrorCode tests code extraction through wrapping and for unclassified errors
+func TestErrorCode(t *testing.T) {
+	dbErr := &DBError{Code: CodeDuplicateEntry, Message: "duplicate entry: x"}
+
+	tests := []struct {
+		name         string
+		err          error
+		expectedCode string
+	}{
+		{
+			name:         "direct",
+			err:          dbErr,
+			expectedCode: CodeDuplicateEntry,
+		},
+		{
+			name:         "wrapped in QueryError",
+			err:          &QueryError{Query: "INSERT", Err: dbErr},
+			expectedCode: CodeDuplicateEntry,
+		},
+		{
+			name:         "wrapped with fmt.Errorf",
+			err:          fmt.Errorf("create user: %w", dbErr),
+			expectedCode: CodeDuplicateEntry,
+		},
+		{
+			name:         "unclassified",
+			err:          errors.New("connection reset"),
+			expectedCode: CodeDatabaseError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedCode, ErrorCode(tt.err))
+		})
+	}
+}
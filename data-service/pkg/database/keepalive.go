@@ -0,0 +1,93 @@
+package database
+
+import (
+	"time"
+)
+
+// maxReconnectBackoff caps the delay between reconnection attempts
+const maxReconnectBackoff = 30 * time.Second
+
+// startKeepAlive launches the background keep-alive when it is enabled and not already running
+func (h *dbHandler) startKeepAlive() {
+	if h.config.KeepAliveInterval <= 0 || h.keepAliveStop != nil {
+		return
+	}
+
+	h.keepAliveStop = make(chan struct{})
+	h.keepAliveDone = make(chan struct{})
+	go h.keepAlive(h.config.KeepAliveInterval, h.keepAliveStop, h.keepAliveDone)
+}
+
+// stopKeepAlive stops the background keep-alive and waits for it to exit
+func (h *dbHandler) stopKeepAlive() {
+	if h.keepAliveStop == nil {
+		return
+	}
+
+	close(h.keepAliveStop)
+	<-h.keepAliveDone
+	h.keepAliveStop = nil
+	h.keepAliveDone = nil
+}
+
+// keepAlive pings the pool every interval. When a ping fails the handler is marked disconnected
+// and reconnect keeps pinging with backoff; database/sql discards the dead connections and dials
+// new ones, so a successful ping means the database is reachable again.
+func (h *dbHandler) keepAlive(interval time.Duration, stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if err := h.keepAlivePing(); err != nil {
+				h.connected.Store(false)
+				h.logger.WithError(err).Warn("Database keep-alive ping failed, reconnecting")
+				if !h.reconnect(stop) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// reconnect pings with exponential backoff until the database answers, returning false if stopped first
+func (h *dbHandler) reconnect(stop <-chan struct{}) bool {
+	delay := h.config.RetryInterval
+	if delay <= 0 {
+		delay = time.Second
+	}
+
+	for attempt := 1; ; attempt++ {
+		select {
+		case <-stop:
+			return false
+		case <-time.After(delay):
+		}
+
+		err := h.keepAlivePing()
+		if err == nil {
+			h.connected.Store(true)
+			h.logger.WithField("attempts", attempt).Info("Reconnected to database")
+			return true
+		}
+
+		h.logger.WithError(err).WithField("attempt", attempt).Warn("Database reconnection attempt failed")
+		delay *= 2
+		if delay > maxReconnectBackoff {
+			delay = maxReconnectBackoff
+		}
+	}
+}
+
+// keepAlivePing pings the pool through the injected ping function, or Ping by default
+func (h *dbHandler) keepAlivePing() error {
+	if h.ping != nil {
+		return h.ping()
+	}
+	return h.Ping()
+}
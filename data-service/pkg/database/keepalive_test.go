@@ -0,0 +1,97 @@
+package database
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupKeepAliveHandler creates a connected handler with fast keep-alive timings and a scripted ping
+func setupKeepAliveHandler(t *testing.T, ping func() error) *dbHandler {
+	db, _, handler := setupTestDB(t)
+	t.Cleanup(func() { db.Close() })
+
+	h := handler.(*dbHandler)
+	h.config.KeepAliveInterval = 10 * time.Millisecond
+	h.config.RetryInterval = 5 * time.Millisecond
+	h.ping = ping
+	return h
+}
+
+// TestKeepAliveReconnectsAfterPingFailure tests that a failed ping marks the handler disconnected until a reconnect succeeds
+func TestKeepAliveReconnectsAfterPingFailure(t *testing.T) {
+	var calls atomic.Int32
+	reconnecting := make(chan struct{})
+	release := make(chan struct{})
+
+	handler := setupKeepAliveHandler(t, func() error {
+		switch calls.Add(1) {
+		case 1:
+			return errors.New("connection reset by peer")
+		case 2:
+			// First reconnection attempt: hold it so the disconnected state can be observed
+			close(reconnecting)
+			<-release
+			return errors.New("connection refused")
+		default:
+			return nil
+		}
+	})
+
+	handler.startKeepAlive()
+	defer handler.stopKeepAlive()
+
+	select {
+	case <-reconnecting:
+	case <-time.After(2 * time.Second):
+		t.Fatal("keep-alive never attempted to reconnect")
+	}
+	assert.False(t, handler.IsConnected())
+
+	close(release)
+	assert.Eventually(t, handler.IsConnected, 2*time.Second, 5*time.Millisecond)
+	assert.GreaterOrEqual(t, calls.Load(), int32(3))
+}
+
+// TestKeepAliveStopsWhileReconnecting tests that Close-time shutdown interrupts an endless reconnection loop
+func TestKeepAliveStopsWhileReconnecting(t *testing.T) {
+	var calls atomic.Int32
+	handler := setupKeepAliveHandler(t, func() error {
+		calls.Add(1)
+		return errors.New("connection refused")
+	})
+
+	handler.startKeepAlive()
+	require.Eventually(t, func() bool { return calls.Load() >= 2 }, 2*time.Second, 5*time.Millisecond)
+
+	stopped := make(chan struct{})
+	go func() {
+		handler.stopKeepAlive()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(2 * time.Second):
+		t.Fatal("keep-alive did not stop")
+	}
+	assert.False(t, handler.IsConnected())
+}
+
+// TestKeepAliveDisabled tests that a zero interval never starts the background goroutine
+func TestKeepAliveDisabled(t *testing.T) {
+	handler := setupKeepAliveHandler(t, func() error {
+		t.Error("ping should not be called when the keep-alive is disabled")
+		return nil
+	})
+	handler.config.KeepAliveInterval = 0
+
+	handler.startKeepAlive()
+
+	assert.Nil(t, handler.keepAliveStop)
+	handler.stopKeepAlive()
+}
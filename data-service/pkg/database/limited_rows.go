@@ -0,0 +1,57 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ErrTooManyRows is reported by LimitedRows.Err when a query returns more rows than its limit
+var ErrTooManyRows = errors.New("query returned more rows than allowed")
+
+// LimitedRows wraps sql.Rows and stops iteration once more than MaxRows rows are read, so a query
+// missing its LIMIT cannot pull a whole table into memory
+type LimitedRows struct {
+	*sql.Rows
+	maxRows int
+	read    int
+	err     error
+}
+
+// NewLimitedRows wraps rows so iteration fails after maxRows rows (maxRows <= 0 means no limit)
+func NewLimitedRows(rows *sql.Rows, maxRows int) *LimitedRows {
+	return &LimitedRows{Rows: rows, maxRows: maxRows}
+}
+
+// Next advances to the next row, returning false with ErrTooManyRows once the limit is exceeded
+func (r *LimitedRows) Next() bool {
+	if r.err != nil || !r.Rows.Next() {
+		return false
+	}
+
+	r.read++
+	if r.maxRows > 0 && r.read > r.maxRows {
+		r.err = fmt.Errorf("%w: limit is %d", ErrTooManyRows, r.maxRows)
+		r.Rows.Close()
+		return false
+	}
+	return true
+}
+
+// Err returns ErrTooManyRows if the limit tripped, otherwise the underlying iteration error
+func (r *LimitedRows) Err() error {
+	if r.err != nil {
+		return r.err
+	}
+	return r.Rows.Err()
+}
+
+// QueryLimited runs a query whose iteration fails after maxRows rows (maxRows <= 0 means no limit)
+func (h *dbHandler) QueryLimited(ctx context.Context, maxRows int, query string, args ...interface{}) (*LimitedRows, error) {
+	rows, err := h.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return NewLimitedRows(rows, maxRows), nil
+}
@@ -0,0 +1,59 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestQueryLimited tests that iteration fails past the row limit and succeeds at or below it
+func TestQueryLimited(t *testing.T) {
+	tests := []struct {
+		name          string
+		rowCount      int
+		maxRows       int
+		expectedRead  int
+		expectTooMany bool
+	}{
+		{name: "below limit", rowCount: 2, maxRows: 3, expectedRead: 2},
+		{name: "exactly at limit", rowCount: 3, maxRows: 3, expectedRead: 3},
+		{name: "past limit", rowCount: 5, maxRows: 3, expectedRead: 3, expectTooMany: true},
+		{name: "no limit", rowCount: 5, maxRows: 0, expectedRead: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, handler := setupTestDB(t)
+			defer db.Close()
+
+			rows := sqlmock.NewRows([]string{"id"})
+			for i := 1; i <= tt.rowCount; i++ {
+				rows.AddRow(i)
+			}
+			mock.ExpectQuery("SELECT id FROM orders").WillReturnRows(rows)
+
+			result, err := handler.QueryLimited(context.Background(), tt.maxRows, "SELECT id FROM orders")
+			require.NoError(t, err)
+			defer result.Close()
+
+			read := 0
+			for result.Next() {
+				var id int
+				require.NoError(t, result.Scan(&id))
+				read++
+			}
+
+			assert.Equal(t, tt.expectedRead, read)
+			if tt.expectTooMany {
+				assert.ErrorIs(t, result.Err(), ErrTooManyRows)
+				assert.False(t, result.Next(), "iteration must stay stopped after the guard trips")
+			} else {
+				assert.NoError(t, result.Err())
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
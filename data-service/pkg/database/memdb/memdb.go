@@ -0,0 +1,180 @@
+// Package memdb provides an in-memory database.DatabaseHandler for the data-service tests. It is
+// backed by sqlmock, so tests script the expected statements exactly as they would against *sql.DB.
+// The other services hold a *sql.DB directly and use sqlmock without this adapter.
+package memdb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"data-service/pkg/database"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// Handler implements database.DatabaseHandler on top of a sqlmock connection
+type Handler struct {
+	db        *sql.DB
+	connected atomic.Bool
+
+	txMu      sync.Mutex
+	activeTxs map[*sql.Tx]struct{}
+}
+
+var _ database.DatabaseHandler = (*Handler)(nil)
+
+// New returns a connected Handler and the sqlmock used to script it. Closing the handler and
+// asserting ExpectationsWereMet are left to the test.
+func New() (*Handler, sqlmock.Sqlmock, error) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		return nil, nil, fmt.Errorf("memdb: failed to create sqlmock: %w", err)
+	}
+
+	h := &Handler{db: db, activeTxs: make(map[*sql.Tx]struct{})}
+	h.connected.Store(true)
+	return h, mock, nil
+}
+
+// Connect marks the handler connected again after Close
+func (h *Handler) Connect() error {
+	h.connected.Store(true)
+	return nil
+}
+
+// Close marks the handler disconnected and closes the underlying connection
+func (h *Handler) Close() error {
+	h.connected.Store(false)
+	return h.db.Close()
+}
+
+func (h *Handler) Ping() error {
+	return h.db.Ping()
+}
+
+func (h *Handler) HealthCheck() error {
+	if !h.IsConnected() {
+		return errors.New("database not connected")
+	}
+	return h.Ping()
+}
+
+func (h *Handler) BeginTx(ctx context.Context) (*sql.Tx, error) {
+	tx, err := h.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	h.txMu.Lock()
+	h.activeTxs[tx] = struct{}{}
+	h.txMu.Unlock()
+	return tx, nil
+}
+
+func (h *Handler) CommitTx(tx *sql.Tx) error {
+	defer h.untrackTx(tx)
+	return tx.Commit()
+}
+
+func (h *Handler) RollbackTx(tx *sql.Tx) error {
+	defer h.untrackTx(tx)
+	return tx.Rollback()
+}
+
+func (h *Handler) untrackTx(tx *sql.Tx) {
+	h.txMu.Lock()
+	defer h.txMu.Unlock()
+	delete(h.activeTxs, tx)
+}
+
+func (h *Handler) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return h.db.Query(query, args...)
+}
+
+func (h *Handler) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return h.db.QueryContext(ctx, query, args...)
+}
+
+func (h *Handler) QueryRow(query string, args ...interface{}) *sql.Row {
+	return h.db.QueryRow(query, args...)
+}
+
+func (h *Handler) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return h.db.QueryRowContext(ctx, query, args...)
+}
+
+// QueryRowScan mirrors the real handler: sql.ErrNoRows is returned as is, anything else as a
+// *database.QueryError
+func (h *Handler) QueryRowScan(ctx context.Context, query string, args []interface{}, dest ...interface{}) error {
+	err := h.db.QueryRowContext(ctx, query, args...).Scan(dest...)
+	if err == nil || errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	return &database.QueryError{Query: query, Err: err}
+}
+
+func (h *Handler) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return h.db.Exec(query, args...)
+}
+
+func (h *Handler) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return h.db.ExecContext(ctx, query, args...)
+}
+
+func (h *Handler) Prepare(query string) (*sql.Stmt, error) {
+	return h.db.Prepare(query)
+}
+
+func (h *Handler) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return h.db.PrepareContext(ctx, query)
+}
+
+func (h *Handler) GetDB() *sql.DB {
+	return h.db
+}
+
+func (h *Handler) GetStats() sql.DBStats {
+	return h.db.Stats()
+}
+
+func (h *Handler) IsConnected() bool {
+	return h.connected.Load()
+}
+
+func (h *Handler) ActiveTransactions() int {
+	h.txMu.Lock()
+	defer h.txMu.Unlock()
+	return len(h.activeTxs)
+}
+
+func (h *Handler) QueryLimited(ctx context.Context, maxRows int, query string, args ...interface{}) (*database.LimitedRows, error) {
+	rows, err := h.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return database.NewLimitedRows(rows, maxRows), nil
+}
+
+// QueryReplica always uses the primary; memdb has no replicas
+func (h *Handler) QueryReplica(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return h.QueryContext(ctx, query, args...)
+}
+
+// QueryRowReplica always uses the primary; memdb has no replicas
+func (h *Handler) QueryRowReplica(ctx context.Context, query string, args []interface{}, dest ...interface{}) error {
+	return h.QueryRowScan(ctx, query, args, dest...)
+}
+
+// ExecCached runs the statement directly; memdb does not cache prepared statements
+func (h *Handler) ExecCached(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return h.ExecContext(ctx, query, args...)
+}
+
+// QueryCached runs the query directly; memdb does not cache prepared statements
+func (h *Handler) QueryCached(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return h.QueryContext(ctx, query, args...)
+}
@@ -0,0 +1,235 @@
+package memdb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"data-service/pkg/database"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newHandler returns a connected Handler that is closed when the test finishes
+func newHandler(t *testing.T) (*Handler, sqlmock.Sqlmock) {
+	t.Helper()
+
+	handler, mock, err := New()
+	require.NoError(t, err)
+	t.Cleanup(func() { handler.GetDB().Close() })
+	return handler, mock
+}
+
+// TestHandlerQuery tests that queries return the scripted rows and errors
+func TestHandlerQuery(t *testing.T) {
+	tests := []struct {
+		name          string
+		setupMock     func(sqlmock.Sqlmock)
+		expectedNames []string
+		expectedError bool
+	}{
+		{
+			name: "returns rows",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT name FROM roles").
+					WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("admin").AddRow("waiter"))
+			},
+			expectedNames: []string{"admin", "waiter"},
+		},
+		{
+			name: "query error",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT name FROM roles").WillReturnError(errors.New("boom"))
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, mock := newHandler(t)
+			tt.setupMock(mock)
+
+			rows, err := handler.QueryContext(context.Background(), "SELECT name FROM roles")
+			if tt.expectedError {
+				assert.Error(t, err)
+				assert.NoError(t, mock.ExpectationsWereMet())
+				return
+			}
+			require.NoError(t, err)
+			defer rows.Close()
+
+			var names []string
+			for rows.Next() {
+				var name string
+				require.NoError(t, rows.Scan(&name))
+				names = append(names, name)
+			}
+			assert.Equal(t, tt.expectedNames, names)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// TestHandlerQueryRowScan tests that the row is scanned and no rows and query failures are reported
+// like the real handler
+func TestHandlerQueryRowScan(t *testing.T) {
+	t.Run("found", func(t *testing.T) {
+		handler, mock := newHandler(t)
+		mock.ExpectQuery("SELECT value FROM config").
+			WithArgs("currency").
+			WillReturnRows(sqlmock.NewRows([]string{"value"}).AddRow("CRC"))
+
+		var value string
+		err := handler.QueryRowScan(context.Background(), "SELECT value FROM config WHERE key = $1", []interface{}{"currency"}, &value)
+
+		require.NoError(t, err)
+		assert.Equal(t, "CRC", value)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("no rows", func(t *testing.T) {
+		handler, mock := newHandler(t)
+		mock.ExpectQuery("SELECT value FROM config").WillReturnRows(sqlmock.NewRows([]string{"value"}))
+
+		var value string
+		err := handler.QueryRowScan(context.Background(), "SELECT value FROM config", nil, &value)
+
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	t.Run("query error", func(t *testing.T) {
+		handler, mock := newHandler(t)
+		mock.ExpectQuery("SELECT value FROM config").WillReturnError(errors.New("connection reset"))
+
+		var value string
+		err := handler.QueryRowScan(context.Background(), "SELECT value FROM config", nil, &value)
+
+		var queryErr *database.QueryError
+		assert.ErrorAs(t, err, &queryErr)
+	})
+}
+
+// TestHandlerExec tests that exec results are passed through
+func TestHandlerExec(t *testing.T) {
+	handler, mock := newHandler(t)
+	mock.ExpectExec("UPDATE roles").WithArgs("admin").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare("DELETE FROM roles").ExpectExec().WillReturnError(errors.New("not allowed"))
+
+	result, err := handler.Exec("UPDATE roles SET active = true WHERE name = $1", "admin")
+	require.NoError(t, err)
+	affected, err := result.RowsAffected()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), affected)
+
+	stmt, err := handler.Prepare("DELETE FROM roles")
+	require.NoError(t, err)
+	defer stmt.Close()
+	_, err = stmt.Exec()
+	assert.Error(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestHandlerTransactions tests commit and rollback along with active transaction tracking
+func TestHandlerTransactions(t *testing.T) {
+	tests := []struct {
+		name     string
+		commit   bool
+		setupTxn func(sqlmock.Sqlmock)
+	}{
+		{
+			name:   "commit",
+			commit: true,
+			setupTxn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO roles").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "rollback",
+			setupTxn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO roles").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectRollback()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, mock := newHandler(t)
+			tt.setupTxn(mock)
+
+			tx, err := handler.BeginTx(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, 1, handler.ActiveTransactions())
+
+			_, err = tx.Exec("INSERT INTO roles (name) VALUES ('admin')")
+			require.NoError(t, err)
+
+			if tt.commit {
+				require.NoError(t, handler.CommitTx(tx))
+			} else {
+				require.NoError(t, handler.RollbackTx(tx))
+			}
+			assert.Equal(t, 0, handler.ActiveTransactions())
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// TestHandlerConnectionState tests that Close disconnects the handler and fails health checks
+func TestHandlerConnectionState(t *testing.T) {
+	handler, mock := newHandler(t)
+	mock.ExpectClose()
+
+	assert.True(t, handler.IsConnected())
+	assert.NoError(t, handler.HealthCheck())
+
+	require.NoError(t, handler.Close())
+	assert.False(t, handler.IsConnected())
+	assert.Error(t, handler.HealthCheck())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestHandlerQueryLimited tests that the row limit is enforced
+func TestHandlerQueryLimited(t *testing.T) {
+	handler, mock := newHandler(t)
+	mock.ExpectQuery("SELECT id FROM items").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2).AddRow(3))
+
+	rows, err := handler.QueryLimited(context.Background(), 2, "SELECT id FROM items")
+	require.NoError(t, err)
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		count++
+	}
+	assert.Equal(t, 2, count)
+	assert.ErrorIs(t, rows.Err(), database.ErrTooManyRows)
+}
+
+// TestHandlerCachedStatements tests that cached execs and queries are passed through
+func TestHandlerCachedStatements(t *testing.T) {
+	handler, mock := newHandler(t)
+	mock.ExpectExec("UPDATE roles").WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectQuery("SELECT name FROM roles").WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("admin"))
+
+	result, err := handler.ExecCached(context.Background(), "UPDATE roles SET active = true")
+	require.NoError(t, err)
+	affected, err := result.RowsAffected()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), affected)
+
+	rows, err := handler.QueryCached(context.Background(), "SELECT name FROM roles")
+	require.NoError(t, err)
+	rows.Close()
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
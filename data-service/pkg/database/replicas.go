@@ -0,0 +1,118 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+)
+
+// replicaConfig returns a copy of the primary configuration pointing at a replica host
+func (c *Config) replicaConfig(replicaHost string) *Config {
+	replica := *c
+	replica.ReplicaHosts = nil
+
+	host, portStr, err := net.SplitHostPort(replicaHost)
+	if err != nil {
+		// No port given: the replica listens on the primary's port
+		replica.Host = replicaHost
+		return &replica
+	}
+
+	replica.Host = host
+	if port, err := strconv.Atoi(portStr); err == nil {
+		replica.Port = port
+	}
+	return &replica
+}
+
+// connectReplicas opens every configured replica. A replica that cannot be reached is skipped
+// with a warning so reads simply stay on the primary.
+func (h *dbHandler) connectReplicas() {
+	for _, replicaHost := range h.config.ReplicaHosts {
+		replicaConfig := h.config.replicaConfig(replicaHost)
+
+		db, err := sql.Open("postgres", replicaConfig.connectionString(replicaConfig.Password))
+		if err == nil {
+			ctx, cancel := context.WithTimeout(context.Background(), h.config.ConnectTimeout)
+			err = db.PingContext(ctx)
+			cancel()
+			if err != nil {
+				db.Close()
+			}
+		}
+
+		if err != nil {
+			h.logger.WithFields(logrus.Fields{
+				"replica": replicaHost,
+				"dsn":     replicaConfig.RedactedString(),
+				"error":   h.config.Redact(err.Error()),
+			}).Warn("Failed to connect to read replica, skipping it")
+			continue
+		}
+
+		h.configureConnectionPool(db)
+		h.replicas = append(h.replicas, db)
+		h.logger.WithField("replica", replicaHost).Info("Connected to read replica")
+	}
+}
+
+// closeReplicas closes every replica connection
+func (h *dbHandler) closeReplicas() {
+	for _, replica := range h.replicas {
+		if err := replica.Close(); err != nil {
+			h.logger.WithError(err).Warn("Failed to close read replica connection")
+		}
+	}
+	h.replicas = nil
+}
+
+// pickReplica returns the next replica in round-robin order, or nil when none are configured
+func (h *dbHandler) pickReplica() *sql.DB {
+	if len(h.replicas) == 0 {
+		return nil
+	}
+	index := (h.nextReplica.Add(1) - 1) % uint64(len(h.replicas))
+	return h.replicas[index]
+}
+
+// QueryReplica runs a read-only query on a replica, falling back to the primary if the replica fails.
+// Never use it for writes or for reads that must see a write made just before.
+func (h *dbHandler) QueryReplica(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	replica := h.pickReplica()
+	if replica == nil {
+		return h.QueryContext(ctx, query, args...)
+	}
+
+	rows, err := replica.QueryContext(ctx, query, args...)
+	if err == nil {
+		return rows, nil
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"query": h.sanitizeQuery(query),
+	}).WithError(err).Warn("Replica query failed, falling back to primary")
+	return h.QueryContext(ctx, query, args...)
+}
+
+// QueryRowReplica scans a single row read from a replica, falling back to the primary if the replica
+// fails. Like QueryRowScan it returns sql.ErrNoRows when nothing matched; that is not a replica failure.
+func (h *dbHandler) QueryRowReplica(ctx context.Context, query string, args []interface{}, dest ...interface{}) error {
+	replica := h.pickReplica()
+	if replica == nil {
+		return h.QueryRowScan(ctx, query, args, dest...)
+	}
+
+	err := replica.QueryRowContext(ctx, query, args...).Scan(dest...)
+	if err == nil || errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"query": h.sanitizeQuery(query),
+	}).WithError(err).Warn("Replica query failed, falling back to primary")
+	return h.QueryRowScan(ctx, query, args, dest...)
+}
@@ -0,0 +1,189 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupReplicaTestDB creates a handler whose primary and replicas are all sqlmock databases
+func setupReplicaTestDB(t *testing.T, replicaCount int) (*dbHandler, sqlmock.Sqlmock, []sqlmock.Sqlmock) {
+	db, primary, handler := setupTestDB(t)
+	t.Cleanup(func() { db.Close() })
+
+	h := handler.(*dbHandler)
+	replicaMocks := make([]sqlmock.Sqlmock, 0, replicaCount)
+	for i := 0; i < replicaCount; i++ {
+		replicaDB, replicaMock, err := sqlmock.New()
+		require.NoError(t, err)
+		t.Cleanup(func() { replicaDB.Close() })
+
+		h.replicas = append(h.replicas, replicaDB)
+		replicaMocks = append(replicaMocks, replicaMock)
+	}
+
+	return h, primary, replicaMocks
+}
+
+// TestReplicaConfig tests that replica hosts inherit the primary settings and may override the port
+func TestReplicaConfig(t *testing.T) {
+	tests := []struct {
+		name         string
+		replicaHost  string
+		expectedHost string
+		expectedPort int
+	}{
+		{name: "host only", replicaHost: "replica-1", expectedHost: "replica-1", expectedPort: 5432},
+		{name: "host and port", replicaHost: "replica-2:6432", expectedHost: "replica-2", expectedPort: 6432},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.User = "postgres"
+			config.DBName = "icecream_store"
+			config.ReplicaHosts = []string{tt.replicaHost}
+
+			replica := config.replicaConfig(tt.replicaHost)
+
+			assert.Equal(t, tt.expectedHost, replica.Host)
+			assert.Equal(t, tt.expectedPort, replica.Port)
+			assert.Equal(t, config.User, replica.User)
+			assert.Equal(t, config.DBName, replica.DBName)
+			assert.Empty(t, replica.ReplicaHosts)
+		})
+	}
+}
+
+// TestQueryReplicaRoundRobin tests that reads alternate between replicas and never hit the primary
+func TestQueryReplicaRoundRobin(t *testing.T) {
+	handler, primary, replicas := setupReplicaTestDB(t, 2)
+
+	replicas[0].ExpectQuery("SELECT name FROM flavors").WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("vanilla"))
+	replicas[1].ExpectQuery("SELECT name FROM flavors").WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("vanilla"))
+	replicas[0].ExpectQuery("SELECT name FROM flavors").WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("vanilla"))
+
+	for i := 0; i < 3; i++ {
+		rows, err := handler.QueryReplica(context.Background(), "SELECT name FROM flavors")
+		require.NoError(t, err)
+		rows.Close()
+	}
+
+	for _, replica := range replicas {
+		assert.NoError(t, replica.ExpectationsWereMet())
+	}
+	assert.NoError(t, primary.ExpectationsWereMet())
+}
+
+// TestQueryReplicaFallsBackToPrimary tests that a failing replica read is retried on the primary
+func TestQueryReplicaFallsBackToPrimary(t *testing.T) {
+	handler, primary, replicas := setupReplicaTestDB(t, 1)
+
+	replicas[0].ExpectQuery("SELECT name FROM flavors").WillReturnError(errors.New("replica unavailable"))
+	primary.ExpectQuery("SELECT name FROM flavors").WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("chocolate"))
+
+	rows, err := handler.QueryReplica(context.Background(), "SELECT name FROM flavors")
+	require.NoError(t, err)
+	defer rows.Close()
+
+	require.True(t, rows.Next())
+	var name string
+	require.NoError(t, rows.Scan(&name))
+	assert.Equal(t, "chocolate", name)
+
+	assert.NoError(t, replicas[0].ExpectationsWereMet())
+	assert.NoError(t, primary.ExpectationsWereMet())
+}
+
+// TestQueryReplicaWithoutReplicasUsesPrimary tests that reads go to the primary when no replica is configured
+func TestQueryReplicaWithoutReplicasUsesPrimary(t *testing.T) {
+	handler, primary, _ := setupReplicaTestDB(t, 0)
+
+	primary.ExpectQuery("SELECT name FROM flavors").WillReturnRows(sqlmock.NewRows([]string{"name"}))
+
+	rows, err := handler.QueryReplica(context.Background(), "SELECT name FROM flavors")
+	require.NoError(t, err)
+	rows.Close()
+
+	assert.NoError(t, primary.ExpectationsWereMet())
+}
+
+// TestQueryRowReplica tests single-row replica reads, their fallback and that no rows is not a failure
+func TestQueryRowReplica(t *testing.T) {
+	tests := []struct {
+		name          string
+		setupMocks    func(primary, replica sqlmock.Sqlmock)
+		expectedName  string
+		expectedError error
+	}{
+		{
+			name: "read from replica",
+			setupMocks: func(primary, replica sqlmock.Sqlmock) {
+				replica.ExpectQuery("SELECT name FROM flavors WHERE id = \\$1").
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("vanilla"))
+			},
+			expectedName: "vanilla",
+		},
+		{
+			name: "replica failure falls back to primary",
+			setupMocks: func(primary, replica sqlmock.Sqlmock) {
+				replica.ExpectQuery("SELECT name FROM flavors WHERE id = \\$1").
+					WithArgs(1).
+					WillReturnError(errors.New("replica unavailable"))
+				primary.ExpectQuery("SELECT name FROM flavors WHERE id = \\$1").
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("chocolate"))
+			},
+			expectedName: "chocolate",
+		},
+		{
+			name: "no rows on replica does not fall back",
+			setupMocks: func(primary, replica sqlmock.Sqlmock) {
+				replica.ExpectQuery("SELECT name FROM flavors WHERE id = \\$1").
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"name"}))
+			},
+			expectedError: sql.ErrNoRows,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, primary, replicas := setupReplicaTestDB(t, 1)
+			tt.setupMocks(primary, replicas[0])
+
+			var name string
+			err := handler.QueryRowReplica(context.Background(), "SELECT name FROM flavors WHERE id = $1", []interface{}{1}, &name)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectedName, name)
+			}
+			assert.NoError(t, replicas[0].ExpectationsWereMet())
+			assert.NoError(t, primary.ExpectationsWereMet())
+		})
+	}
+}
+
+// TestWritesIgnoreReplicas tests that writes always go to the primary
+func TestWritesIgnoreReplicas(t *testing.T) {
+	handler, primary, replicas := setupReplicaTestDB(t, 2)
+
+	primary.ExpectExec("UPDATE flavors SET name = \\$1").WithArgs("mint").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	_, err := handler.ExecContext(context.Background(), "UPDATE flavors SET name = $1", "mint")
+	require.NoError(t, err)
+
+	assert.NoError(t, primary.ExpectationsWereMet())
+	for _, replica := range replicas {
+		assert.NoError(t, replica.ExpectationsWereMet())
+	}
+}
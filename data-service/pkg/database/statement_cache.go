@@ -0,0 +1,116 @@
+package database
+
+import (
+	"container/list"
+	"database/sql"
+	"sync"
+)
+
+// cachedStatement is a prepared statement held by the cache. A statement evicted while a caller is
+// still using it is closed once that caller releases it.
+type cachedStatement struct {
+	query   string
+	stmt    *sql.Stmt
+	inUse   int
+	evicted bool
+}
+
+// statementCache is a least-recently-used cache of prepared statements keyed by query text
+type statementCache struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List // front is most recently used
+}
+
+// newStatementCache creates a cache holding at most capacity statements
+func newStatementCache(capacity int) *statementCache {
+	return &statementCache{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// acquire returns the cached statement for query and marks it in use, or nil on a miss
+func (c *statementCache) acquire(query string) *cachedStatement {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[query]
+	if !ok {
+		return nil
+	}
+	c.order.MoveToFront(element)
+	entry := element.Value.(*cachedStatement)
+	entry.inUse++
+	return entry
+}
+
+// add caches a freshly prepared statement and returns it marked in use. If another caller cached
+// the same query first, the duplicate is closed and the cached one is returned instead.
+func (c *statementCache) add(query string, stmt *sql.Stmt) *cachedStatement {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[query]; ok {
+		stmt.Close()
+		c.order.MoveToFront(element)
+		entry := element.Value.(*cachedStatement)
+		entry.inUse++
+		return entry
+	}
+
+	entry := &cachedStatement{query: query, stmt: stmt, inUse: 1}
+	c.entries[query] = c.order.PushFront(entry)
+
+	for c.order.Len() > c.capacity {
+		c.evictLocked(c.order.Back())
+	}
+	return entry
+}
+
+// release marks a statement as no longer in use, closing it if it was evicted meanwhile
+func (c *statementCache) release(entry *cachedStatement) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry.inUse--
+	if entry.evicted && entry.inUse == 0 {
+		entry.stmt.Close()
+	}
+}
+
+// closeAll evicts every statement, returning the first error from closing one
+func (c *statementCache) closeAll() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var firstErr error
+	for c.order.Len() > 0 {
+		if err := c.evictLocked(c.order.Back()); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// len returns the number of cached statements
+func (c *statementCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// evictLocked removes an entry and closes its statement unless it is still in use; callers must hold c.mu
+func (c *statementCache) evictLocked(element *list.Element) error {
+	entry := element.Value.(*cachedStatement)
+	c.order.Remove(element)
+	delete(c.entries, entry.query)
+
+	entry.evicted = true
+	if entry.inUse == 0 {
+		return entry.stmt.Close()
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestStatementCacheEvictsLeastRecentlyUsed tests that the oldest statement is closed once the cache is full
+func TestStatementCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectPrepare("SELECT 1").WillBeClosed()
+	mock.ExpectPrepare("SELECT 2")
+
+	cache := newStatementCache(1)
+
+	first, err := db.PrepareContext(context.Background(), "SELECT 1")
+	require.NoError(t, err)
+	cache.release(cache.add("SELECT 1", first))
+
+	second, err := db.PrepareContext(context.Background(), "SELECT 2")
+	require.NoError(t, err)
+	cache.release(cache.add("SELECT 2", second))
+
+	assert.Equal(t, 1, cache.len())
+	assert.Nil(t, cache.acquire("SELECT 1"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestStatementCacheDefersCloseWhileInUse tests that an evicted statement stays open until its user releases it
+func TestStatementCacheDefersCloseWhileInUse(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectPrepare("SELECT 1").WillBeClosed()
+	mock.ExpectPrepare("SELECT 2")
+
+	cache := newStatementCache(1)
+
+	first, err := db.PrepareContext(context.Background(), "SELECT 1")
+	require.NoError(t, err)
+	inUse := cache.add("SELECT 1", first)
+
+	second, err := db.PrepareContext(context.Background(), "SELECT 2")
+	require.NoError(t, err)
+	cache.release(cache.add("SELECT 2", second))
+
+	assert.True(t, inUse.evicted)
+	assert.Error(t, mock.ExpectationsWereMet(), "evicted statement must not be closed while in use")
+
+	cache.release(inUse)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
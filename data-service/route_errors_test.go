@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"shared/httpx"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRouteErrorHandlers tests that unmatched paths and methods get JSON 404 and 405 responses
+func TestRouteErrorHandlers(t *testing.T) {
+	handler, _ := setupTestHandler(t)
+	router := setupRouter(handler, logrus.New())
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		expectedStatus int
+		expectedError  string
+		expectedAllow  string
+	}{
+		{
+			name:           "unknown path",
+			method:         http.MethodGet,
+			path:           "/missing",
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "not_found",
+		},
+		{
+			name:           "unsupported method",
+			method:         http.MethodPost,
+			path:           "/health",
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedError:  "method_not_allowed",
+			expectedAllow:  "GET",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, httptest.NewRequest(tt.method, tt.path, nil))
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+			assert.Equal(t, tt.expectedAllow, rr.Header().Get("Allow"))
+
+			var body httpx.RouteError
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+			assert.Equal(t, tt.expectedError, body.Error)
+			assert.Equal(t, tt.path, body.Path)
+			assert.Equal(t, tt.method, body.Method)
+		})
+	}
+}
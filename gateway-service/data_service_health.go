@@ -0,0 +1,62 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+// data-service health states reported by the gateway
+const (
+	dataServiceHealthy      = "healthy"
+	dataServiceDatabaseOnly = "database-only"
+	dataServiceUnhealthy    = "unhealthy"
+)
+
+// dataServiceDatabaseAddr is the PostgreSQL address probed when the data-service HTTP API is not running
+var dataServiceDatabaseAddr = "localhost:5432"
+
+// checkDataServiceHealth reports the state of data-service. Unlike the business services it has no
+// /api/v1 routes, only /health and /stats, and in docker mode only its PostgreSQL containers run.
+// So a refused connection to its API is not a failure by itself: the database port is probed instead.
+//
+//   - API answers 200: healthy
+//   - API answers anything else (it could not reach the database): unhealthy
+//   - API unreachable but the database accepts connections: database-only
+//   - neither reachable: unhealthy
+func checkDataServiceHealth(healthURL, databaseAddr string) string {
+	client := &http.Client{
+		Timeout: 5 * time.Second,
+	}
+
+	req, err := http.NewRequest("GET", healthURL, nil)
+	if err != nil {
+		return dataServiceUnhealthy
+	}
+	req.Header.Set("X-Gateway-Service", "ice-cream-gateway")
+	req.Header.Set("X-Gateway-Session-Managed", "true")
+
+	resp, err := client.Do(req)
+	if err == nil {
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			return dataServiceHealthy
+		}
+		return dataServiceUnhealthy
+	}
+
+	conn, err := net.DialTimeout("tcp", databaseAddr, 2*time.Second)
+	if err != nil {
+		return dataServiceUnhealthy
+	}
+	conn.Close()
+	return dataServiceDatabaseOnly
+}
+
+// checkManagedServiceHealth checks any managed service, applying the data-service contract where needed
+func checkManagedServiceHealth(serviceName, healthURL string) bool {
+	if serviceName == "data-service" {
+		return checkDataServiceHealth(healthURL, dataServiceDatabaseAddr) != dataServiceUnhealthy
+	}
+	return checkServiceHealth(healthURL)
+}
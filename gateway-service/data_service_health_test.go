@@ -0,0 +1,98 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// closedAddress returns an address that refuses connections
+func closedAddress(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	listener.Close()
+	return addr
+}
+
+// openDatabaseAddress returns an address that accepts connections, standing in for PostgreSQL
+func openDatabaseAddress(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+	return listener.Addr().String()
+}
+
+// TestCheckDataServiceHealth tests the data-service health contract with and without its HTTP API
+func TestCheckDataServiceHealth(t *testing.T) {
+	testCases := map[string]struct {
+		apiStatus      int // 0 means the HTTP API is not running
+		databaseUp     bool
+		expectedStatus string
+	}{
+		"api reports healthy": {
+			apiStatus:      http.StatusOK,
+			databaseUp:     true,
+			expectedStatus: dataServiceHealthy,
+		},
+		"api reports database failure": {
+			apiStatus:      http.StatusServiceUnavailable,
+			databaseUp:     true,
+			expectedStatus: dataServiceUnhealthy,
+		},
+		"api not running but database reachable": {
+			databaseUp:     true,
+			expectedStatus: dataServiceDatabaseOnly,
+		},
+		"nothing reachable": {
+			expectedStatus: dataServiceUnhealthy,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			healthURL := "http://" + closedAddress(t) + "/health"
+			if tc.apiStatus != 0 {
+				api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					assert.Equal(t, "/health", r.URL.Path)
+					w.WriteHeader(tc.apiStatus)
+				}))
+				t.Cleanup(api.Close)
+				healthURL = api.URL + "/health"
+			}
+
+			databaseAddr := closedAddress(t)
+			if tc.databaseUp {
+				databaseAddr = openDatabaseAddress(t)
+			}
+
+			assert.Equal(t, tc.expectedStatus, checkDataServiceHealth(healthURL, databaseAddr))
+		})
+	}
+}
+
+// TestCheckManagedServiceHealthDataService tests that the watchdog treats a database-only data-service as healthy
+func TestCheckManagedServiceHealthDataService(t *testing.T) {
+	original := dataServiceDatabaseAddr
+	t.Cleanup(func() { dataServiceDatabaseAddr = original })
+
+	healthURL := "http://" + closedAddress(t) + "/health"
+
+	dataServiceDatabaseAddr = openDatabaseAddress(t)
+	assert.True(t, checkManagedServiceHealth("data-service", healthURL))
+
+	dataServiceDatabaseAddr = closedAddress(t)
+	assert.False(t, checkManagedServiceHealth("data-service", healthURL))
+
+	// Other services keep the plain HTTP contract and ignore the database fallback
+	dataServiceDatabaseAddr = openDatabaseAddress(t)
+	assert.False(t, checkManagedServiceHealth("orders-service", healthURL))
+}
@@ -0,0 +1,10 @@
+// Package integration holds end-to-end tests that exercise the gateway → service → database path.
+//
+// The tests are behind the "integration" build tag. They build every service, start a throwaway
+// PostgreSQL container seeded with data-service's init scripts, run the services on ephemeral
+// ports and drive requests through the gateway:
+//
+//	go test -tags integration -count=1 -v ./integration/...
+//
+// Docker must be available; without it the tests are skipped.
+package integration
@@ -0,0 +1,152 @@
+//go:build integration
+
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestEndToEnd runs the gateway → service → database flows against one running system
+func TestEndToEnd(t *testing.T) {
+	h := startHarness(t)
+
+	t.Run("services are reachable through the gateway", func(t *testing.T) {
+		for _, path := range []string{
+			"/api/v1/sessions/p/health",
+			"/api/v1/orders/p/health",
+			"/api/v1/inventory/p/health",
+			"/api/v1/invoices/p/health",
+		} {
+			resp := h.do(t, http.MethodGet, path, "", nil)
+			resp.Body.Close()
+			assert.Equal(t, http.StatusOK, resp.StatusCode, path)
+		}
+	})
+
+	t.Run("login, create order and fetch it", func(t *testing.T) {
+		token := h.login(t, "admin", "admin123")
+
+		notes := "integration test order"
+		resp := h.do(t, http.MethodPost, "/api/v1/orders", token, map[string]interface{}{
+			"payment_method": "cash",
+			"notes":          notes,
+			"items": []map[string]interface{}{
+				{"recipe_id": seededRecipeID, "quantity": 2, "unit_price": 1500},
+			},
+		})
+		var created orderEnvelope
+		decode(t, resp, http.StatusCreated, &created)
+		require.True(t, created.Success)
+		require.NotEmpty(t, created.Data.Order.ID)
+		require.Len(t, created.Data.Items, 1)
+
+		resp = h.do(t, http.MethodGet, "/api/v1/orders/"+created.Data.Order.ID, token, nil)
+		var fetched orderEnvelope
+		decode(t, resp, http.StatusOK, &fetched)
+
+		assert.Equal(t, created.Data.Order.ID, fetched.Data.Order.ID)
+		assert.Equal(t, 3000.0, fetched.Data.Order.TotalAmount)
+		assert.Equal(t, "cash", fetched.Data.Order.PaymentMethod)
+		require.Len(t, fetched.Data.Items, 1)
+		assert.Equal(t, seededRecipeID, fetched.Data.Items[0].RecipeID)
+		assert.Equal(t, 2, fetched.Data.Items[0].Quantity)
+	})
+
+	t.Run("business endpoints require a session", func(t *testing.T) {
+		testCases := map[string]struct {
+			token string
+		}{
+			"missing token": {},
+			"forged token":  {token: "not-a-real-token"},
+		}
+
+		for name, tc := range testCases {
+			t.Run(name, func(t *testing.T) {
+				resp := h.do(t, http.MethodGet, "/api/v1/orders", tc.token, nil)
+				resp.Body.Close()
+				assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+			})
+		}
+	})
+
+	t.Run("wrong password is rejected", func(t *testing.T) {
+		resp := h.do(t, http.MethodPost, "/api/v1/sessions/p/login", "", map[string]string{
+			"username": "admin",
+			"password": "wrong-password",
+		})
+		resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+}
+
+// orderEnvelope is the orders-service success envelope around an order with its items
+type orderEnvelope struct {
+	Success bool `json:"success"`
+	Data    struct {
+		Order struct {
+			ID            string  `json:"id"`
+			TotalAmount   float64 `json:"total_amount"`
+			PaymentMethod string  `json:"payment_method"`
+		} `json:"order"`
+		Items []struct {
+			RecipeID string `json:"recipe_id"`
+			Quantity int    `json:"quantity"`
+		} `json:"items"`
+	} `json:"data"`
+}
+
+// login authenticates through the gateway and returns the session token
+func (h *harness) login(t *testing.T, username, password string) string {
+	t.Helper()
+
+	resp := h.do(t, http.MethodPost, "/api/v1/sessions/p/login", "", map[string]string{
+		"username": username,
+		"password": password,
+	})
+	var body struct {
+		Token string `json:"token"`
+	}
+	decode(t, resp, http.StatusOK, &body)
+	require.NotEmpty(t, body.Token)
+	return body.Token
+}
+
+// do sends a request through the gateway, JSON-encoding body when it is not nil
+func (h *harness) do(t *testing.T, method, path, token string, body interface{}) *http.Response {
+	t.Helper()
+
+	var payload bytes.Buffer
+	if body != nil {
+		require.NoError(t, json.NewEncoder(&payload).Encode(body))
+	}
+	req, err := http.NewRequest(method, h.GatewayURL+path, &payload)
+	require.NoError(t, err)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	return resp
+}
+
+// decode checks the status and decodes the JSON body into dest
+func decode(t *testing.T, resp *http.Response, expectedStatus int, dest interface{}) {
+	t.Helper()
+	defer resp.Body.Close()
+
+	var raw bytes.Buffer
+	_, err := raw.ReadFrom(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, expectedStatus, resp.StatusCode, raw.String())
+	require.NoError(t, json.Unmarshal(raw.Bytes(), dest), raw.String())
+}
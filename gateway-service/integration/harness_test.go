@@ -0,0 +1,301 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	postgresImage    = "postgres:15-alpine"
+	postgresUser     = "postgres"
+	postgresPassword = "postgres123"
+	postgresDB       = "icecream_store"
+
+	// jwtSecret satisfies session-service's minimum secret length
+	jwtSecret = "integration-test-jwt-secret-0123456789abcdef"
+
+	// seededRecipeID is the recipe inserted by seedSQL for orders to reference
+	seededRecipeID = "00000000-0000-4000-8000-000000000001"
+
+	startupTimeout = 60 * time.Second
+)
+
+// seedSQL runs after data-service's init script and adds the fixtures the flows need
+var seedSQL = fmt.Sprintf(`INSERT INTO recipes (id, recipe_name, recipe_category_id, total_recipe_cost)
+VALUES ('%s', 'Integration Vanilla', (SELECT id FROM recipe_categories WHERE name = 'Helados'), 500.00);
+`, seededRecipeID)
+
+// harness is a running system: a seeded database and every service, reachable through GatewayURL
+type harness struct {
+	GatewayURL string
+}
+
+// serviceSpec describes how to build and configure one service binary
+type serviceSpec struct {
+	name string
+	dir  string // module directory relative to gateway-service
+	env  func(ports map[string]string, db dbEndpoint) []string
+}
+
+// dbEndpoint is where the throwaway database listens on the host
+type dbEndpoint struct {
+	host string
+	port string
+}
+
+// dbEnv is the connection environment shared by the services; orders-service spells the SSL
+// mode variable differently
+func dbEnv(db dbEndpoint) []string {
+	return []string{
+		"DB_HOST=" + db.host,
+		"DB_PORT=" + db.port,
+		"DB_USER=" + postgresUser,
+		"DB_PASSWORD=" + postgresPassword,
+		"DB_NAME=" + postgresDB,
+		"DB_SSLMODE=disable",
+		"DB_SSL_MODE=disable",
+		"HEALTH_CHECK_DATA_SERVICE=false",
+		"LOG_LEVEL=warn",
+	}
+}
+
+// services are started in order; the gateway comes last since it proxies to the others
+var services = []serviceSpec{
+	{
+		name: "session-service",
+		dir:  "../session-service",
+		env: func(ports map[string]string, db dbEndpoint) []string {
+			return append(dbEnv(db),
+				"SESSION_SERVER_HOST=127.0.0.1",
+				"SESSION_SERVER_PORT="+ports["session-service"],
+				"JWT_SECRET="+jwtSecret,
+				"BCRYPT_COST=4",
+			)
+		},
+	},
+	{
+		name: "orders-service",
+		dir:  "../orders-service",
+		env: func(ports map[string]string, db dbEndpoint) []string {
+			return append(dbEnv(db),
+				"SERVER_HOST=127.0.0.1",
+				"SERVER_PORT="+ports["orders-service"],
+				"JWT_SECRET="+jwtSecret,
+			)
+		},
+	},
+	{
+		name: "inventory-service",
+		dir:  "../inventory-service",
+		env: func(ports map[string]string, db dbEndpoint) []string {
+			return append(dbEnv(db),
+				"INVENTORY_SERVER_HOST=127.0.0.1",
+				"INVENTORY_SERVER_PORT="+ports["inventory-service"],
+			)
+		},
+	},
+	{
+		name: "invoice-service",
+		dir:  "../invoice-service",
+		env: func(ports map[string]string, db dbEndpoint) []string {
+			return append(dbEnv(db),
+				"INVOICE_SERVER_HOST=127.0.0.1",
+				"INVOICE_SERVER_PORT="+ports["invoice-service"],
+			)
+		},
+	},
+	{
+		name: "gateway-service",
+		dir:  ".",
+		env: func(ports map[string]string, db dbEndpoint) []string {
+			return []string{
+				"GATEWAY_PORT=" + ports["gateway-service"],
+				"SESSION_SERVICE_URL=http://127.0.0.1:" + ports["session-service"],
+				"ORDERS_SERVICE_URL=http://127.0.0.1:" + ports["orders-service"],
+				"INVENTORY_SERVICE_URL=http://127.0.0.1:" + ports["inventory-service"],
+				"INVOICE_SERVICE_URL=http://127.0.0.1:" + ports["invoice-service"],
+			}
+		},
+	},
+}
+
+// startHarness brings the whole system up and registers its teardown with t
+func startHarness(t *testing.T) *harness {
+	t.Helper()
+
+	if _, err := exec.LookPath("docker"); err != nil {
+		t.Skip("docker is not available; skipping integration tests")
+	}
+
+	workDir := t.TempDir()
+	db := startPostgres(t, workDir)
+
+	ports := make(map[string]string, len(services))
+	for _, service := range services {
+		ports[service.name] = freePort(t)
+	}
+
+	for _, service := range services {
+		binary := buildService(t, service, workDir)
+		env := append(os.Environ(), service.env(ports, db)...)
+		startService(t, service.name, binary, env, workDir)
+		waitForPort(t, service.name, ports[service.name])
+	}
+
+	return &harness{GatewayURL: "http://127.0.0.1:" + ports["gateway-service"]}
+}
+
+// startPostgres runs a throwaway PostgreSQL container initialised with data-service's schema and seedSQL
+func startPostgres(t *testing.T, workDir string) dbEndpoint {
+	t.Helper()
+
+	initDir := filepath.Join(workDir, "initdb")
+	if err := os.MkdirAll(initDir, 0o755); err != nil {
+		t.Fatalf("create init dir: %v", err)
+	}
+	schema, err := os.ReadFile("../../data-service/docker/init/01-init-database.sql")
+	if err != nil {
+		t.Fatalf("read schema: %v", err)
+	}
+	writeInitFile(t, filepath.Join(initDir, "01-init-database.sql"), schema)
+	writeInitFile(t, filepath.Join(initDir, "02-integration-seed.sql"), []byte(seedSQL))
+	// The container's postgres user reads the scripts, so the temp dir must be world-readable
+	if err := os.Chmod(workDir, 0o755); err != nil {
+		t.Fatalf("chmod work dir: %v", err)
+	}
+
+	containerID := strings.TrimSpace(runCommand(t, "docker", "run", "-d", "--rm",
+		"-e", "POSTGRES_USER="+postgresUser,
+		"-e", "POSTGRES_PASSWORD="+postgresPassword,
+		"-e", "POSTGRES_DB="+postgresDB,
+		"-v", initDir+":/docker-entrypoint-initdb.d:ro",
+		"-p", "127.0.0.1::5432",
+		postgresImage,
+	))
+	t.Cleanup(func() {
+		exec.Command("docker", "rm", "-f", containerID).Run()
+	})
+
+	// The init scripts run on a socket-only server, so TCP readiness means they have finished
+	deadline := time.Now().Add(startupTimeout)
+	for exec.Command("docker", "exec", containerID, "pg_isready", "-h", "127.0.0.1", "-U", postgresUser, "-d", postgresDB).Run() != nil {
+		if time.Now().After(deadline) {
+			logs, _ := exec.Command("docker", "logs", containerID).CombinedOutput()
+			t.Fatalf("postgres did not become ready within %s:\n%s", startupTimeout, logs)
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+
+	mapping := strings.TrimSpace(runCommand(t, "docker", "port", containerID, "5432/tcp"))
+	host, port, err := net.SplitHostPort(strings.Split(mapping, "\n")[0])
+	if err != nil {
+		t.Fatalf("parse postgres port mapping %q: %v", mapping, err)
+	}
+	return dbEndpoint{host: host, port: port}
+}
+
+func writeInitFile(t *testing.T, path string, content []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+// buildService compiles a service's main package into workDir
+func buildService(t *testing.T, service serviceSpec, workDir string) string {
+	t.Helper()
+
+	binary := filepath.Join(workDir, service.name)
+	cmd := exec.Command("go", "build", "-o", binary, ".")
+	cmd.Dir = filepath.Join("..", service.dir)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("build %s: %v\n%s", service.name, err, output)
+	}
+	return binary
+}
+
+// startService runs a service binary, logging to workDir, and stops it when the test ends
+func startService(t *testing.T, name, binary string, env []string, workDir string) {
+	t.Helper()
+
+	logFile, err := os.Create(filepath.Join(workDir, name+".log"))
+	if err != nil {
+		t.Fatalf("create log for %s: %v", name, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd := exec.CommandContext(ctx, binary)
+	cmd.Dir = workDir
+	cmd.Env = env
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	if err := cmd.Start(); err != nil {
+		cancel()
+		t.Fatalf("start %s: %v", name, err)
+	}
+
+	t.Cleanup(func() {
+		cancel()
+		cmd.Wait()
+		logFile.Close()
+		if t.Failed() {
+			if output, err := os.ReadFile(logFile.Name()); err == nil {
+				t.Logf("%s log:\n%s", name, output)
+			}
+		}
+	})
+}
+
+// waitForPort blocks until a service accepts connections
+func waitForPort(t *testing.T, name, port string) {
+	t.Helper()
+
+	deadline := time.Now().Add(startupTimeout)
+	for {
+		conn, err := net.DialTimeout("tcp", "127.0.0.1:"+port, time.Second)
+		if err == nil {
+			conn.Close()
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%s did not start listening on port %s within %s", name, port, startupTimeout)
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+}
+
+// freePort asks the kernel for an unused port
+func freePort(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("find free port: %v", err)
+	}
+	defer listener.Close()
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	return port
+}
+
+func runCommand(t *testing.T, name string, args ...string) string {
+	t.Helper()
+
+	output, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("%s %s: %v\n%s", name, strings.Join(args, " "), err, output)
+	}
+	return string(output)
+}
+
+// client is the HTTP client used for requests through the gateway
+var client = &http.Client{Timeout: 15 * time.Second}
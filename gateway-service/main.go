@@ -1,7 +1,9 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -11,9 +13,15 @@ import (
 	"net/url"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
+	"shared/httpx"
+	"shared/version"
+
 	"github.com/gorilla/mux"
 )
 
@@ -34,7 +42,7 @@ func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Set CORS headers - only the gateway sets these
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
 
 		// Handle preflight requests
@@ -71,6 +79,35 @@ func main() {
 	log.Printf("Gateway configured with Orders Service: %s", config.OrdersServiceURL)
 	log.Printf("Gateway configured with Inventory Service: %s", config.InventoryServiceURL)
 
+	serviceCommandTimeout = getEnvDuration("GATEWAY_COMMAND_TIMEOUT", serviceCommandTimeout)
+
+	// X-Forwarded-For is only honored when it arrives through one of these proxies
+	proxies, err := ParseTrustedProxies(getEnv("GATEWAY_TRUSTED_PROXIES", ""))
+	if err != nil {
+		log.Fatalf("Invalid GATEWAY_TRUSTED_PROXIES: %v", err)
+	}
+	trustedProxies = proxies
+	log.Printf("Gateway trusts X-Forwarded-For from %d proxy networks", len(trustedProxies))
+	log.Printf("Proxy transport: %d idle connections per backend, idle timeout %s, HTTP/2 attempted: %t",
+		proxyTransportConfig.MaxIdleConnsPerHost, proxyTransportConfig.IdleConnTimeout, proxyTransportConfig.ForceAttemptHTTP2)
+
+	// Optional watchdog that restarts services failing consecutive health checks
+	watchdogConfig := loadWatchdogConfig()
+	if watchdogConfig.Enabled {
+		watchdog := NewServiceWatchdog(watchdogConfig, serviceHealthURLs)
+		go watchdog.Start()
+		log.Printf("Service watchdog enabled (interval: %s, failure threshold: %d)", watchdogConfig.Interval, watchdogConfig.FailureThreshold)
+	}
+
+	// Asynchronous management jobs stay pollable until they have been finished for longer than the TTL
+	managementJobs := NewJobRegistry(getEnvDuration("GATEWAY_JOB_TTL", time.Hour))
+
+	// Read-only maintenance mode blocks writes to the business services, e.g. during migrations
+	readOnlyMode := NewReadOnlyMode(getEnvBool("GATEWAY_READ_ONLY", false))
+	if readOnlyMode.Enabled() {
+		log.Printf("Read-only maintenance mode enabled")
+	}
+
 	// Create session manager for authentication
 	sessionManager := NewSessionManager(config.SessionServiceURL)
 	sessionMiddleware := NewSessionMiddleware(sessionManager)
@@ -90,42 +127,18 @@ func main() {
 	managementRouter.HandleFunc("/services/{service}/start", serviceStartHandler).Methods("POST")
 	managementRouter.HandleFunc("/services/{service}/stop", serviceStopHandler).Methods("POST")
 	managementRouter.HandleFunc("/services/{service}/restart", serviceRestartHandler).Methods("POST")
+	managementRouter.HandleFunc("/start-all", createStartAllHandler(managementJobs)).Methods("POST")
+	managementRouter.HandleFunc("/jobs", managementJobs.ListHandler).Methods("GET")
+	managementRouter.HandleFunc("/jobs/{id}", managementJobs.GetHandler).Methods("GET")
 
-	// ==== PURE PROXY ROUTING TO SERVICES ====
-
-	// Session service endpoints - pure proxy routing
-	sessionRouter := api.PathPrefix("/v1/sessions").Subrouter()
-
-	// Public session endpoints (no authentication required) - /p/ prefix
-	sessionRouter.HandleFunc("/p/login", createProxyHandler(config.SessionServiceURL, "/api/v1/sessions/p/login")).Methods("POST")
-	sessionRouter.HandleFunc("/p/validate", createProxyHandler(config.SessionServiceURL, "/api/v1/sessions/p/validate")).Methods("POST")
-	sessionRouter.HandleFunc("/p/health", createProxyHandler(config.SessionServiceURL, "/api/v1/sessions/p/health")).Methods("GET")
+	// Read-only maintenance switch - admin sessions only, checked the same way as the config routes
+	readOnlyRouter := managementRouter.PathPrefix("/read-only").Subrouter()
+	readOnlyRouter.HandleFunc("", readOnlyMode.StatusHandler).Methods("GET")
+	readOnlyRouter.HandleFunc("", readOnlyMode.ToggleHandler).Methods("PUT")
+	readOnlyRouter.Use(sessionMiddleware.ValidateSession, sessionMiddleware.RequireAdmin)
 
-	// Protected session endpoints - session service handles authentication
-	sessionRouter.HandleFunc("/logout", createProxyHandler(config.SessionServiceURL, "/api/v1/sessions/logout")).Methods("POST")
-	sessionRouter.HandleFunc("/refresh", createProxyHandler(config.SessionServiceURL, "/api/v1/sessions/refresh")).Methods("POST")
-	sessionRouter.HandleFunc("/profile", createProxyHandler(config.SessionServiceURL, "/api/v1/sessions/profile")).Methods("GET")
-	sessionRouter.HandleFunc("/user/{userID}", createProxyHandler(config.SessionServiceURL, "/api/v1/sessions/user")).Methods("GET", "DELETE")
-
-	// Public health endpoints (no authentication required)
-	api.HandleFunc("/v1/orders/p/health", createProxyHandler(config.OrdersServiceURL, "/api/v1/orders/p/health")).Methods("GET")
-	api.HandleFunc("/v1/inventory/p/health", createProxyHandler(config.InventoryServiceURL, "/api/v1/inventory/p/health")).Methods("GET")
-	api.HandleFunc("/v1/invoices/p/health", createInvoiceHealthHandler(config.InvoiceServiceURL)).Methods("GET")
-
-	// Orders service endpoints - with authentication middleware
-	ordersRouter := api.PathPrefix("/v1/orders").Subrouter()
-	ordersRouter.PathPrefix("").HandlerFunc(createProxyHandler(config.OrdersServiceURL, "/api/v1/orders"))
-	ordersRouter.Use(sessionMiddleware.ValidateSession) // Add authentication for business endpoints
-
-	// Inventory service endpoints - with authentication middleware
-	inventoryRouter := api.PathPrefix("/v1/inventory").Subrouter()
-	inventoryRouter.PathPrefix("").HandlerFunc(createProxyHandler(config.InventoryServiceURL, "/api/v1/inventory"))
-	inventoryRouter.Use(sessionMiddleware.ValidateSession) // Add authentication for business endpoints
-
-	// Invoice service routes - with authentication middleware
-	invoiceRouter := api.PathPrefix("/v1/invoices").Subrouter()
-	invoiceRouter.PathPrefix("").HandlerFunc(createProxyHandler(config.InvoiceServiceURL, "/api/v1"))
-	invoiceRouter.Use(sessionMiddleware.ValidateSession) // Add authentication for business endpoints
+	// ==== PURE PROXY ROUTING TO SERVICES ====
+	registerProxyRoutes(api, config, sessionMiddleware.ValidateSession, readOnlyMode.Middleware)
 
 	// Apply CORS middleware to main router - gateway is single source of CORS
 	r.Use(corsMiddleware)
@@ -136,11 +149,18 @@ func main() {
 		w.WriteHeader(http.StatusOK)
 	})
 
+	// Unmatched routes get JSON 404/405 responses; router middleware doesn't run for them, so add CORS here
+	// OPTIONS is not probed for the Allow header because the CORS preflight route answers it for every path
+	httpx.SetRouteErrorHandlers(r, httpx.WithAllowMethods(http.MethodGet, http.MethodHead, http.MethodPost,
+		http.MethodPut, http.MethodPatch, http.MethodDelete))
+	r.NotFoundHandler = corsMiddleware(r.NotFoundHandler)
+	r.MethodNotAllowedHandler = corsMiddleware(r.MethodNotAllowedHandler)
+
 	// UI is now served by its own service on port 3000
 	// Static file serving removed - UI runs independently
 
-	fmt.Println("🚀 Gateway Service with Session Management starting on http://localhost:8082")
-	fmt.Println("📡 API available at http://localhost:8082/api")
+	fmt.Printf("🚀 Gateway Service with Session Management starting on http://localhost:%s\n", config.Port)
+	fmt.Printf("📡 API available at http://localhost:%s/api\n", config.Port)
 	fmt.Println("")
 	fmt.Println("🔐 SESSION MANAGEMENT ENDPOINTS:")
 	fmt.Println("   📂 Public:")
@@ -149,9 +169,13 @@ func main() {
 	fmt.Printf("      GET  /api/v1/sessions/p/health → %s\n", config.SessionServiceURL)
 	fmt.Printf("      POST /api/v1/sessions/p/logout   → %s (+ session revocation)\n", config.SessionServiceURL)
 	fmt.Println("   🔒 Protected (require valid session):")
+	fmt.Printf("      GET  /api/v1/{orders,inventory,invoices}/config → /api/v1/config of each service (admin only)\n")
 	fmt.Printf("      POST /api/v1/sessions/refresh  → %s\n", config.SessionServiceURL)
 	fmt.Printf("      GET  /api/v1/sessions/profile  → %s\n", config.SessionServiceURL)
 	fmt.Printf("      GET  /api/v1/sessions/user/{userID} → %s\n", config.SessionServiceURL)
+	fmt.Printf("      POST /api/v1/auth/change-password → %s\n", config.SessionServiceURL)
+	fmt.Printf("      POST /api/v1/auth/users → %s (requires auth-write)\n", config.SessionServiceURL)
+	fmt.Printf("      PATCH /api/v1/auth/users/{id} → %s (requires auth-write)\n", config.SessionServiceURL)
 	fmt.Println("")
 	fmt.Println("🛒 BUSINESS SERVICE ENDPOINTS:")
 	fmt.Println("   📂 Public Health Checks:")
@@ -180,7 +204,7 @@ func main() {
 	fmt.Println("   ✅ Session revocation on logout")
 	fmt.Println("   ✅ User context injection")
 
-	log.Fatal(http.ListenAndServe(":8082", r))
+	log.Fatal(http.ListenAndServe(":"+config.Port, r))
 }
 
 // createInvoiceHealthHandler creates a custom health handler for invoice service
@@ -204,7 +228,7 @@ func createInvoiceHealthHandler(invoiceServiceURL string) http.HandlerFunc {
 		// Add gateway headers
 		req.Header.Set("X-Gateway-Service", "ice-cream-gateway")
 		req.Header.Set("X-Gateway-Session-Managed", "true")
-		req.Header.Set("X-Forwarded-For", r.RemoteAddr)
+		req.Header.Set("X-Forwarded-For", trustedProxies.ClientIP(r))
 
 		resp, err := client.Do(req)
 		if err != nil {
@@ -227,14 +251,85 @@ func createInvoiceHealthHandler(invoiceServiceURL string) http.HandlerFunc {
 	}
 }
 
-// createProxyHandler creates a reverse proxy handler for a specific service
-func createProxyHandler(targetURL, stripPrefix string) http.HandlerFunc {
+// registerProxyRoutes routes /api/v1 requests to the backend services; businessMiddleware
+// (session validation, read-only mode) guards the orders, inventory and invoice routes
+func registerProxyRoutes(api *mux.Router, config Config, businessMiddleware ...mux.MiddlewareFunc) {
+	// One proxy per service, answering 503 instead of forwarding while the service is restarting
+	sessionProxy := unavailableWhileRestarting("session-service", createProxyHandler(config.SessionServiceURL))
+	ordersProxy := unavailableWhileRestarting("orders-service", createProxyHandler(config.OrdersServiceURL))
+	inventoryProxy := unavailableWhileRestarting("inventory-service", createProxyHandler(config.InventoryServiceURL))
+	invoiceProxy := unavailableWhileRestarting("invoice-service", createProxyHandler(config.InvoiceServiceURL))
+
+	// Session service endpoints - pure proxy routing
+	sessionRouter := api.PathPrefix("/v1/sessions").Subrouter()
+
+	// Public session endpoints (no authentication required) - /p/ prefix
+	sessionRouter.HandleFunc("/p/login", sessionProxy).Methods("POST")
+	sessionRouter.HandleFunc("/p/validate", sessionProxy).Methods("POST")
+	sessionRouter.HandleFunc("/p/health", sessionProxy).Methods("GET")
+
+	// Protected session endpoints - session service handles authentication
+	sessionRouter.HandleFunc("/logout", sessionProxy).Methods("POST")
+	sessionRouter.HandleFunc("/refresh", sessionProxy).Methods("POST")
+	sessionRouter.HandleFunc("/profile", sessionProxy).Methods("GET")
+	sessionRouter.HandleFunc("/user/{userID}", sessionProxy).Methods("GET", "DELETE")
+
+	// Account endpoints - session service authenticates the bearer token
+	authRouter := api.PathPrefix("/v1/auth").Subrouter()
+	authRouter.HandleFunc("/change-password", sessionProxy).Methods("POST")
+	authRouter.HandleFunc("/users", sessionProxy).Methods("POST")
+	authRouter.HandleFunc("/users/{id}", sessionProxy).Methods("PATCH")
+
+	// Public health endpoints (no authentication required)
+	api.HandleFunc("/v1/orders/p/health", ordersProxy).Methods("GET")
+	api.HandleFunc("/v1/inventory/p/health", inventoryProxy).Methods("GET")
+	api.HandleFunc("/v1/invoices/p/health", unavailableWhileRestarting("invoice-service", createInvoiceHealthHandler(config.InvoiceServiceURL))).Methods("GET")
+
+	// Effective configuration of each business service - authenticated like the business
+	// endpoints, so the services' admin guard only ever sees a role the gateway has set
+	configRouter := api.PathPrefix("/v1").Subrouter()
+	configRouter.HandleFunc("/orders/config", forwardToPath("/api/v1/config", ordersProxy)).Methods("GET")
+	configRouter.HandleFunc("/inventory/config", forwardToPath("/api/v1/config", inventoryProxy)).Methods("GET")
+	configRouter.HandleFunc("/invoices/config", forwardToPath("/api/v1/config", invoiceProxy)).Methods("GET")
+	configRouter.Use(businessMiddleware...)
+
+	// Orders service endpoints - with authentication middleware
+	ordersRouter := api.PathPrefix("/v1/orders").Subrouter()
+	ordersRouter.PathPrefix("").HandlerFunc(ordersProxy)
+	ordersRouter.Use(businessMiddleware...) // Add authentication for business endpoints
+
+	// Inventory service endpoints - with authentication middleware
+	inventoryRouter := api.PathPrefix("/v1/inventory").Subrouter()
+	inventoryRouter.PathPrefix("").HandlerFunc(inventoryProxy)
+	inventoryRouter.Use(businessMiddleware...) // Add authentication for business endpoints
+
+	// Invoice service routes - with authentication middleware
+	invoiceRouter := api.PathPrefix("/v1/invoices").Subrouter()
+	invoiceRouter.PathPrefix("").HandlerFunc(invoiceProxy)
+	invoiceRouter.Use(businessMiddleware...) // Add authentication for business endpoints
+}
+
+// forwardToPath rewrites the request path before handing it to a proxy, for gateway routes that
+// do not match the path the service serves
+func forwardToPath(path string, proxy http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		r.URL.Path = path
+		r.URL.RawPath = ""
+		proxy(w, r)
+	}
+}
+
+// createProxyHandler creates a reverse proxy handler for a specific service. Requests are
+// forwarded with their path and query unchanged: every service serves its routes under the same
+// /api/v1 paths the gateway exposes, so there is no prefix to strip
+func createProxyHandler(targetURL string) http.HandlerFunc {
 	target, err := url.Parse(targetURL)
 	if err != nil {
 		log.Fatalf("Invalid target URL: %v", err)
 	}
 
 	proxy := httputil.NewSingleHostReverseProxy(target)
+	proxy.Transport = proxyTransport
 
 	// Customize the proxy to handle errors and modify requests
 	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
@@ -261,7 +356,7 @@ func createProxyHandler(targetURL, stripPrefix string) http.HandlerFunc {
 		}
 
 		// Add gateway headers
-		req.Header.Set("X-Forwarded-For", req.RemoteAddr)
+		req.Header.Set("X-Forwarded-For", trustedProxies.ClientIP(req))
 		req.Header.Set("X-Gateway-Service", "ice-cream-gateway")
 		req.Header.Set("X-Gateway-Session-Managed", "true")
 	}
@@ -271,14 +366,24 @@ func createProxyHandler(targetURL, stripPrefix string) http.HandlerFunc {
 	}
 }
 
+// serviceHealthURLs maps each managed service to the health endpoint used to probe it
+var serviceHealthURLs = map[string]string{
+	"session-service":   "http://localhost:8081/api/v1/sessions/p/health",
+	"orders-service":    "http://localhost:8083/api/v1/orders/p/health",
+	"inventory-service": "http://localhost:8084/api/v1/inventory/p/health",
+	"invoice-service":   "http://localhost:8085/api/v1/invoices/p/health",
+	"data-service":      "http://localhost:8086/health", // For UI monitoring; see checkDataServiceHealth
+}
+
 func healthHandler(w http.ResponseWriter, r *http.Request) {
 	// Check all business services that appear on the dashboard + data service for UI monitoring
 	gatewayHealthy := true // Gateway is healthy if it's responding to this request
-	sessionHealthy := checkServiceHealth("http://localhost:8081/api/v1/sessions/p/health")
-	ordersHealthy := checkServiceHealth("http://localhost:8083/api/v1/orders/p/health")
-	inventoryHealthy := checkServiceHealth("http://localhost:8084/api/v1/inventory/p/health")
-	invoiceHealthy := checkServiceHealth("http://localhost:8085/api/v1/invoices/p/health")
-	dataHealthy := checkServiceHealth("http://localhost:8086/health") // For UI monitoring
+	sessionHealthy := checkServiceHealth(serviceHealthURLs["session-service"])
+	ordersHealthy := checkServiceHealth(serviceHealthURLs["orders-service"])
+	inventoryHealthy := checkServiceHealth(serviceHealthURLs["inventory-service"])
+	invoiceHealthy := checkServiceHealth(serviceHealthURLs["invoice-service"])
+	dataStatus := checkDataServiceHealth(serviceHealthURLs["data-service"], dataServiceDatabaseAddr)
+	dataHealthy := dataStatus != dataServiceUnhealthy
 
 	status := "healthy"
 	if !gatewayHealthy || !sessionHealthy || !ordersHealthy || !inventoryHealthy || !invoiceHealthy || !dataHealthy {
@@ -287,7 +392,8 @@ func healthHandler(w http.ResponseWriter, r *http.Request) {
 
 	response := map[string]interface{}{
 		"status":             status,
-		"version":            "1.0.0",
+		"version":            version.Version,
+		"build":              version.Info(),
 		"time":               time.Now(),
 		"gateway":            "operational",
 		"session_management": "enabled",
@@ -322,12 +428,7 @@ func healthHandler(w http.ResponseWriter, r *http.Request) {
 				}
 				return "unhealthy"
 			}(),
-			"data-service": func() string {
-				if dataHealthy {
-					return "healthy"
-				}
-				return "unhealthy"
-			}(),
+			"data-service": dataStatus,
 		},
 	}
 
@@ -392,6 +493,15 @@ func isServiceRunning(serviceName string) bool {
 	return true
 }
 
+// serviceLocks holds one mutex per service so management operations on the same service never overlap
+var serviceLocks sync.Map
+
+// getServiceLock returns the management lock for a service, creating it on first use
+func getServiceLock(serviceName string) *sync.Mutex {
+	lock, _ := serviceLocks.LoadOrStore(serviceName, &sync.Mutex{})
+	return lock.(*sync.Mutex)
+}
+
 // Service management handlers
 func serviceStartHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -399,6 +509,7 @@ func serviceStartHandler(w http.ResponseWriter, r *http.Request) {
 
 	var requestBody struct {
 		Environment string `json:"environment"`
+		DryRun      bool   `json:"dry_run"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
@@ -411,8 +522,18 @@ func serviceStartHandler(w http.ResponseWriter, r *http.Request) {
 		environment = "locally" // Default
 	}
 
+	if isDryRun(r, requestBody.DryRun) {
+		plan, err := buildStartPlan(serviceName, environment)
+		writeDryRunResponse(w, plan, err)
+		return
+	}
+
 	log.Printf("🔧 Starting %s service (environment: %s)", serviceName, environment)
 
+	lock := getServiceLock(serviceName)
+	lock.Lock()
+	defer lock.Unlock()
+
 	// Check if service is already running
 	isRunning := isServiceRunning(serviceName)
 	var finalOutput strings.Builder
@@ -420,6 +541,9 @@ func serviceStartHandler(w http.ResponseWriter, r *http.Request) {
 	var finalError error
 
 	if isRunning {
+		// Starting a running service restarts it
+		defer markServiceRestarting(serviceName)()
+
 		log.Printf("⚠️  Service %s is already running, stopping it first...", serviceName)
 		finalOutput.WriteString(fmt.Sprintf("Service %s was already running, stopping first...\n", serviceName))
 
@@ -487,54 +611,76 @@ func serviceStartHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// dependentServices lists the services that depend on data-service (in dependency order)
+var dependentServices = []string{
+	"session-service",
+	"orders-service",
+	"inventory-service",
+	"invoice-service",
+	"gateway-service", // Gateway last to ensure all other services are ready
+}
+
+// dependentServiceStartDelay spaces out consecutive service starts to avoid overwhelming the system
+var dependentServiceStartDelay = 3 * time.Second
+
 // restartDependentServices automatically restarts all services that depend on the database
 func restartDependentServices(environment string) {
-	// Services that depend on data-service (in dependency order)
-	dependentServices := []string{
-		"session-service",
-		"orders-service",
-		"inventory-service",
-		"invoice-service",
-		"gateway-service", // Gateway last to ensure all other services are ready
-	}
-
 	log.Printf("🔄 Starting automatic restart of dependent services...")
 
 	for _, serviceName := range dependentServices {
 		log.Printf("🔄 Auto-restarting %s...", serviceName)
+		autoRestartService(serviceName, environment)
 
-		// Check if service is running before attempting restart
-		if isServiceRunning(serviceName) {
-			// Stop the service first
-			stopTarget := fmt.Sprintf("stop-%s", environment)
-			stopSuccess, stopOutput, stopErr := executeServiceCommand(serviceName, stopTarget)
+		// Wait before starting next service to avoid overwhelming the system
+		time.Sleep(dependentServiceStartDelay)
+	}
 
-			if !stopSuccess || stopErr != nil {
-				log.Printf("❌ Failed to stop %s during auto-restart: %v", serviceName, stopErr)
-				continue // Skip to next service
-			}
+	log.Printf("🎉 Completed automatic restart of dependent services!")
+}
 
-			log.Printf("✅ Stopped %s, output: %s", serviceName, stopOutput)
+// autoRestartService restarts a single dependent service while holding its management lock
+func autoRestartService(serviceName, environment string) ServiceOperationResult {
+	unlock := lockServiceForRestart(serviceName)
+	defer unlock()
 
-			// Wait for service to fully stop
-			time.Sleep(2 * time.Second)
-		}
+	result := ServiceOperationResult{Service: serviceName}
+	var output strings.Builder
 
-		// Start the service
-		startTarget := fmt.Sprintf("start-%s", environment)
-		startSuccess, startOutput, startErr := executeServiceCommand(serviceName, startTarget)
+	// Check if service is running before attempting restart
+	if isServiceRunning(serviceName) {
+		// Stop the service first
+		stopTarget := fmt.Sprintf("stop-%s", environment)
+		stopSuccess, stopOutput, stopErr := executeServiceCommand(serviceName, stopTarget)
+		output.WriteString(fmt.Sprintf("Stop output: %s\n", stopOutput))
 
-		if !startSuccess || startErr != nil {
-			log.Printf("❌ Failed to start %s during auto-restart: %v", serviceName, startErr)
-		} else {
-			log.Printf("✅ Successfully auto-restarted %s, output: %s", serviceName, startOutput)
+		if !stopSuccess || stopErr != nil {
+			log.Printf("❌ Failed to stop %s during auto-restart: %v", serviceName, stopErr)
+			result.Output = output.String()
+			result.Error = fmt.Sprintf("failed to stop running service: %v", stopErr)
+			return result
 		}
 
-		// Wait before starting next service to avoid overwhelming the system
-		time.Sleep(3 * time.Second)
+		log.Printf("✅ Stopped %s, output: %s", serviceName, stopOutput)
+
+		// Wait for service to fully stop
+		time.Sleep(2 * time.Second)
 	}
 
-	log.Printf("🎉 Completed automatic restart of dependent services!")
+	// Start the service
+	startTarget := fmt.Sprintf("start-%s", environment)
+	startSuccess, startOutput, startErr := executeServiceCommand(serviceName, startTarget)
+	output.WriteString(fmt.Sprintf("Start output: %s", startOutput))
+	result.Output = output.String()
+
+	if !startSuccess || startErr != nil {
+		log.Printf("❌ Failed to start %s during auto-restart: %v", serviceName, startErr)
+		result.Error = fmt.Sprintf("failed to start service: %v", startErr)
+		return result
+	}
+
+	log.Printf("✅ Successfully auto-restarted %s, output: %s", serviceName, startOutput)
+	result.Success = true
+	return result
 }
 
 func serviceStopHandler(w http.ResponseWriter, r *http.Request) {
@@ -543,6 +689,7 @@ func serviceStopHandler(w http.ResponseWriter, r *http.Request) {
 
 	var requestBody struct {
 		Environment string `json:"environment"`
+		DryRun      bool   `json:"dry_run"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
@@ -555,8 +702,18 @@ func serviceStopHandler(w http.ResponseWriter, r *http.Request) {
 		environment = "locally" // Default
 	}
 
+	if isDryRun(r, requestBody.DryRun) {
+		plan, err := buildStopPlan(serviceName, environment)
+		writeDryRunResponse(w, plan, err)
+		return
+	}
+
 	log.Printf("🔧 Stopping %s service (environment: %s)", serviceName, environment)
 
+	lock := getServiceLock(serviceName)
+	lock.Lock()
+	defer lock.Unlock()
+
 	// Check if service is already stopped
 	isRunning := isServiceRunning(serviceName)
 	var success bool = true
@@ -610,6 +767,7 @@ func serviceRestartHandler(w http.ResponseWriter, r *http.Request) {
 
 	var requestBody struct {
 		Environment string `json:"environment"`
+		DryRun      bool   `json:"dry_run"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
@@ -622,40 +780,34 @@ func serviceRestartHandler(w http.ResponseWriter, r *http.Request) {
 		environment = "locally" // Default
 	}
 
-	log.Printf("🔧 Restarting %s service (environment: %s)", serviceName, environment)
-
-	// For restart, we execute stop then start
-	stopTarget := fmt.Sprintf("stop-%s", environment)
-	startTarget := fmt.Sprintf("start-%s", environment)
-
-	// First stop the service
-	stopSuccess, stopOutput, stopErr := executeServiceCommand(serviceName, stopTarget)
-
-	// Wait a moment for graceful shutdown
-	time.Sleep(2 * time.Second)
+	if isDryRun(r, requestBody.DryRun) {
+		plan, err := buildRestartPlan(serviceName, environment)
+		writeDryRunResponse(w, plan, err)
+		return
+	}
 
-	// Then start the service
-	startSuccess, startOutput, startErr := executeServiceCommand(serviceName, startTarget)
+	log.Printf("🔧 Restarting %s service (environment: %s)", serviceName, environment)
 
-	success := stopSuccess && startSuccess
-	output := fmt.Sprintf("Stop output: %s\nStart output: %s", stopOutput, startOutput)
+	unlock := lockServiceForRestart(serviceName)
+	result := restartService(serviceName, environment)
+	unlock()
 
 	response := map[string]interface{}{
 		"service":     serviceName,
 		"action":      "restart",
 		"environment": environment,
-		"success":     success,
+		"success":     result.Success,
 		"message":     fmt.Sprintf("Service %s restart command executed", serviceName),
-		"output":      output,
+		"output":      result.Output,
 	}
 
-	if stopErr != nil || startErr != nil {
+	if result.StopErr != nil || result.StartErr != nil {
 		var errMsg string
-		if stopErr != nil {
-			errMsg += fmt.Sprintf("Stop error: %v ", stopErr)
+		if result.StopErr != nil {
+			errMsg += fmt.Sprintf("Stop error: %v ", result.StopErr)
 		}
-		if startErr != nil {
-			errMsg += fmt.Sprintf("Start error: %v", startErr)
+		if result.StartErr != nil {
+			errMsg += fmt.Sprintf("Start error: %v", result.StartErr)
 		}
 		response["error"] = errMsg
 		log.Printf("❌ Failed to restart %s: %s", serviceName, errMsg)
@@ -664,7 +816,7 @@ func serviceRestartHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	if success {
+	if result.Success {
 		w.WriteHeader(http.StatusOK)
 	} else {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -672,39 +824,97 @@ func serviceRestartHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
-// Execute service command using make in the appropriate directory
-func executeServiceCommand(serviceName, makeTarget string) (bool, string, error) {
-	// Map service names to directories
-	serviceDirectories := map[string]string{
-		"data-service":      "data-service",
-		"gateway-service":   "gateway-service",
-		"session-service":   "session-service",
-		"orders-service":    "orders-service",
-		"inventory-service": "inventory-service",
-		"invoice-service":   "invoice-service",
+// serviceRestartResult captures the outcome of a stop-then-start cycle
+type serviceRestartResult struct {
+	Success  bool
+	Output   string
+	StopErr  error
+	StartErr error
+}
+
+// restartService stops and then starts a service; callers must hold the service lock
+func restartService(serviceName, environment string) serviceRestartResult {
+	// For restart, we execute stop then start
+	stopTarget := fmt.Sprintf("stop-%s", environment)
+	startTarget := fmt.Sprintf("start-%s", environment)
+
+	// First stop the service
+	stopSuccess, stopOutput, stopErr := executeServiceCommand(serviceName, stopTarget)
+
+	// Wait a moment for graceful shutdown
+	time.Sleep(2 * time.Second)
+
+	// Then start the service
+	startSuccess, startOutput, startErr := executeServiceCommand(serviceName, startTarget)
+
+	return serviceRestartResult{
+		Success:  stopSuccess && startSuccess,
+		Output:   fmt.Sprintf("Stop output: %s\nStart output: %s", stopOutput, startOutput),
+		StopErr:  stopErr,
+		StartErr: startErr,
 	}
+}
+
+// serviceDirectories maps service names to their directories
+var serviceDirectories = map[string]string{
+	"data-service":      "data-service",
+	"gateway-service":   "gateway-service",
+	"session-service":   "session-service",
+	"orders-service":    "orders-service",
+	"inventory-service": "inventory-service",
+	"invoice-service":   "invoice-service",
+}
+
+// execCommandContext builds the process used to run make targets (replaceable in tests)
+var execCommandContext = exec.CommandContext
+
+// serviceCommandTimeout bounds how long a single make target may run before it is killed
+var serviceCommandTimeout = 2 * time.Minute
 
+// Execute service command using make in the appropriate directory
+func executeServiceCommand(serviceName, makeTarget string) (bool, string, error) {
 	serviceDir, exists := serviceDirectories[serviceName]
 	if !exists {
 		return false, "", fmt.Errorf("unknown service: %s", serviceName)
 	}
 
-	// Build the command
-	cmd := exec.Command("make", makeTarget)
-	cmd.Dir = fmt.Sprintf("../%s", serviceDir) // Relative to gateway-service directory
-
 	log.Printf("🔧 Executing: cd %s && make %s", serviceDir, makeTarget)
 
+	// Relative to gateway-service directory
+	output, err := runCommandWithTimeout(fmt.Sprintf("../%s", serviceDir), serviceCommandTimeout, "make", makeTarget)
+
+	if err != nil {
+		log.Printf("❌ Command failed: %v, output: %s", err, output)
+		return false, output, err
+	}
+
+	log.Printf("✅ Command succeeded, output: %s", output)
+	return true, output, nil
+}
+
+// runCommandWithTimeout runs a command in its own process group and kills the whole group if it exceeds the timeout
+func runCommandWithTimeout(dir string, timeout time.Duration, name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := execCommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	// Run in a dedicated process group so children spawned by make are killed too
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	// Don't wait forever on output pipes held open by orphaned descendants
+	cmd.WaitDelay = time.Second
+
 	// Capture output
 	output, err := cmd.CombinedOutput()
 
-	if err != nil {
-		log.Printf("❌ Command failed: %v, output: %s", err, string(output))
-		return false, string(output), err
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return string(output), fmt.Errorf("command timed out after %s: %s %s", timeout, name, strings.Join(args, " "))
 	}
 
-	log.Printf("✅ Command succeeded, output: %s", string(output))
-	return true, string(output), nil
+	return string(output), err
 }
 
 func getEnv(key, defaultValue string) string {
@@ -714,3 +924,27 @@ func getEnv(key, defaultValue string) string {
 	}
 	return value
 }
+
+func getEnvBool(key string, defaultValue bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
@@ -9,6 +9,8 @@ import (
 	"testing"
 	"time"
 
+	"shared/version"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -32,7 +34,7 @@ func TestCorsMiddleware(t *testing.T) {
 
 		assert.Equal(t, http.StatusOK, w.Code)
 		assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
-		assert.Equal(t, "GET, POST, PUT, DELETE, OPTIONS", w.Header().Get("Access-Control-Allow-Methods"))
+		assert.Equal(t, "GET, POST, PUT, PATCH, DELETE, OPTIONS", w.Header().Get("Access-Control-Allow-Methods"))
 		assert.Equal(t, "Content-Type, Authorization", w.Header().Get("Access-Control-Allow-Headers"))
 		assert.Equal(t, "test response", w.Body.String())
 	})
@@ -45,7 +47,7 @@ func TestCorsMiddleware(t *testing.T) {
 
 		assert.Equal(t, http.StatusOK, w.Code)
 		assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
-		assert.Equal(t, "GET, POST, PUT, DELETE, OPTIONS", w.Header().Get("Access-Control-Allow-Methods"))
+		assert.Equal(t, "GET, POST, PUT, PATCH, DELETE, OPTIONS", w.Header().Get("Access-Control-Allow-Methods"))
 		assert.Equal(t, "Content-Type, Authorization", w.Header().Get("Access-Control-Allow-Headers"))
 		assert.Empty(t, w.Body.String()) // OPTIONS should not call the next handler
 	})
@@ -137,6 +139,36 @@ func TestHealthHandler(t *testing.T) {
 	assert.Equal(t, "enabled", response["session_management"])
 }
 
+// TestHealthHandlerReportsBuildInfo tests that the health JSON carries the link-time build metadata
+func TestHealthHandlerReportsBuildInfo(t *testing.T) {
+	originalVersion, originalCommit, originalBuildTime := version.Version, version.GitCommit, version.BuildTime
+	defer func() {
+		version.Version, version.GitCommit, version.BuildTime = originalVersion, originalCommit, originalBuildTime
+	}()
+
+	version.Version = "2.3.4"
+	version.GitCommit = "abc1234"
+	version.BuildTime = "2024-01-01T12:00:00Z"
+
+	req := httptest.NewRequest("GET", "/health", nil)
+	w := httptest.NewRecorder()
+
+	healthHandler(w, req)
+
+	var response struct {
+		Version string            `json:"version"`
+		Build   version.BuildInfo `json:"build"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+	assert.Equal(t, "2.3.4", response.Version)
+	assert.Equal(t, version.BuildInfo{
+		Version:   "2.3.4",
+		GitCommit: "abc1234",
+		BuildTime: "2024-01-01T12:00:00Z",
+	}, response.Build)
+}
+
 // TestResponseStructures tests the response data structures
 func TestResponseStructures(t *testing.T) {
 	t.Run("Response structure", func(t *testing.T) {
@@ -181,9 +213,8 @@ func TestResponseStructures(t *testing.T) {
 // TestCreateProxyHandler tests proxy handler creation
 func TestCreateProxyHandler(t *testing.T) {
 	targetURL := "http://localhost:8081"
-	stripPrefix := "/api/v1/sessions"
 
-	handler := createProxyHandler(targetURL, stripPrefix)
+	handler := createProxyHandler(targetURL)
 	assert.NotNil(t, handler)
 	assert.IsType(t, http.HandlerFunc(nil), handler)
 }
@@ -312,7 +343,7 @@ func TestGatewayServiceSpecifics(t *testing.T) {
 
 		// Gateway should be the only service setting CORS headers
 		assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
-		assert.Equal(t, "GET, POST, PUT, DELETE, OPTIONS", w.Header().Get("Access-Control-Allow-Methods"))
+		assert.Equal(t, "GET, POST, PUT, PATCH, DELETE, OPTIONS", w.Header().Get("Access-Control-Allow-Methods"))
 	})
 }
 
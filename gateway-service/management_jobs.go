@@ -0,0 +1,179 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"shared/httpx"
+
+	"github.com/gorilla/mux"
+)
+
+// JobStatus is the lifecycle state of an asynchronous management job
+type JobStatus string
+
+const (
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"
+)
+
+// Job tracks an asynchronous management operation and the result of each service it touched
+type Job struct {
+	ID          string                   `json:"id"`
+	Action      string                   `json:"action"`
+	Environment string                   `json:"environment"`
+	Status      JobStatus                `json:"status"`
+	Started     time.Time                `json:"started"`
+	Finished    *time.Time               `json:"finished,omitempty"`
+	Results     []ServiceOperationResult `json:"results"`
+}
+
+// JobRegistry keeps management jobs in memory so callers can poll them.
+// Finished jobs are dropped once they are older than the TTL.
+type JobRegistry struct {
+	mu   sync.Mutex
+	jobs map[string]*Job
+	ttl  time.Duration
+	now  func() time.Time
+}
+
+// NewJobRegistry creates an empty registry that expires finished jobs after ttl
+func NewJobRegistry(ttl time.Duration) *JobRegistry {
+	return &JobRegistry{
+		jobs: make(map[string]*Job),
+		ttl:  ttl,
+		now:  time.Now,
+	}
+}
+
+// Create registers a new running job and returns a snapshot of it
+func (jr *JobRegistry) Create(action, environment string) Job {
+	jr.mu.Lock()
+	defer jr.mu.Unlock()
+
+	jr.pruneLocked()
+
+	job := &Job{
+		ID:          newJobID(),
+		Action:      action,
+		Environment: environment,
+		Status:      JobRunning,
+		Started:     jr.now(),
+		Results:     []ServiceOperationResult{},
+	}
+	jr.jobs[job.ID] = job
+	return job.snapshot()
+}
+
+// Record appends the result of one service to a running job
+func (jr *JobRegistry) Record(id string, result ServiceOperationResult) {
+	jr.mu.Lock()
+	defer jr.mu.Unlock()
+
+	if job, ok := jr.jobs[id]; ok {
+		job.Results = append(job.Results, result)
+	}
+}
+
+// Finish marks a job as succeeded or failed and starts its TTL
+func (jr *JobRegistry) Finish(id string, success bool) {
+	jr.mu.Lock()
+	defer jr.mu.Unlock()
+
+	job, ok := jr.jobs[id]
+	if !ok {
+		return
+	}
+
+	finished := jr.now()
+	job.Finished = &finished
+	job.Status = JobSucceeded
+	if !success {
+		job.Status = JobFailed
+	}
+}
+
+// Get returns a snapshot of a job, or false if it is unknown or has expired
+func (jr *JobRegistry) Get(id string) (Job, bool) {
+	jr.mu.Lock()
+	defer jr.mu.Unlock()
+
+	jr.pruneLocked()
+
+	job, ok := jr.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return job.snapshot(), true
+}
+
+// List returns snapshots of every job that has not expired, oldest first
+func (jr *JobRegistry) List() []Job {
+	jr.mu.Lock()
+	defer jr.mu.Unlock()
+
+	jr.pruneLocked()
+
+	jobs := make([]Job, 0, len(jr.jobs))
+	for _, job := range jr.jobs {
+		jobs = append(jobs, job.snapshot())
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].Started.Before(jobs[j].Started)
+	})
+	return jobs
+}
+
+// GetHandler handles GET /api/management/jobs/{id}
+func (jr *JobRegistry) GetHandler(w http.ResponseWriter, r *http.Request) {
+	job, ok := jr.Get(mux.Vars(r)["id"])
+	if !ok {
+		httpx.WriteRouteError(w, http.StatusNotFound, "job_not_found", r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job)
+}
+
+// ListHandler handles GET /api/management/jobs
+func (jr *JobRegistry) ListHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"jobs": jr.List(),
+	})
+}
+
+// pruneLocked drops finished jobs older than the TTL; callers must hold jr.mu
+func (jr *JobRegistry) pruneLocked() {
+	cutoff := jr.now().Add(-jr.ttl)
+	for id, job := range jr.jobs {
+		if job.Finished != nil && job.Finished.Before(cutoff) {
+			delete(jr.jobs, id)
+		}
+	}
+}
+
+// snapshot copies a job so callers never share its mutable state
+func (job *Job) snapshot() Job {
+	copied := *job
+	copied.Results = append([]ServiceOperationResult{}, job.Results...)
+	if job.Finished != nil {
+		finished := *job.Finished
+		copied.Finished = &finished
+	}
+	return copied
+}
+
+// newJobID returns a random hex identifier for a job
+func newJobID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
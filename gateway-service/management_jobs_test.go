@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newJobsRouter wires the job endpoints the same way main does
+func newJobsRouter(jobs *JobRegistry) *mux.Router {
+	router := mux.NewRouter()
+	router.HandleFunc("/api/management/start-all", createStartAllHandler(jobs)).Methods("POST")
+	router.HandleFunc("/api/management/jobs", jobs.ListHandler).Methods("GET")
+	router.HandleFunc("/api/management/jobs/{id}", jobs.GetHandler).Methods("GET")
+	return router
+}
+
+// TestJobRegistryLifecycle tests creating a job, recording results and finishing it
+func TestJobRegistryLifecycle(t *testing.T) {
+	jobs := NewJobRegistry(time.Hour)
+
+	job := jobs.Create("start-all", "locally")
+	assert.NotEmpty(t, job.ID)
+	assert.Equal(t, JobRunning, job.Status)
+	assert.Nil(t, job.Finished)
+
+	jobs.Record(job.ID, ServiceOperationResult{Service: "data-service", Success: true})
+	jobs.Record(job.ID, ServiceOperationResult{Service: "session-service", Error: "boom"})
+	jobs.Finish(job.ID, false)
+
+	stored, ok := jobs.Get(job.ID)
+	require.True(t, ok)
+	assert.Equal(t, JobFailed, stored.Status)
+	require.NotNil(t, stored.Finished)
+	require.Len(t, stored.Results, 2)
+	assert.Equal(t, "session-service", stored.Results[1].Service)
+
+	// Snapshots must not share state with the registry
+	stored.Results[0].Service = "changed"
+	again, _ := jobs.Get(job.ID)
+	assert.Equal(t, "data-service", again.Results[0].Service)
+}
+
+// TestStartAllAsyncJob tests that an async start-all returns a job that can be polled to completion
+func TestStartAllAsyncJob(t *testing.T) {
+	started := stubBatchStart(t, "invoice-service")
+	router := newJobsRouter(NewJobRegistry(time.Hour))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/management/start-all?async=true", strings.NewReader(`{"environment": "docker"}`))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusAccepted, w.Code)
+	var created Job
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	require.NotEmpty(t, created.ID)
+	assert.Equal(t, JobRunning, created.Status)
+	assert.Equal(t, "docker", created.Environment)
+
+	var polled Job
+	require.Eventually(t, func() bool {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/management/jobs/"+created.ID, nil))
+		if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &polled) != nil {
+			return false
+		}
+		return polled.Status != JobRunning
+	}, 2*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, JobFailed, polled.Status)
+	require.NotNil(t, polled.Finished)
+	assert.Len(t, polled.Results, len(*started))
+	for _, result := range polled.Results {
+		assert.Equal(t, result.Service != "invoice-service", result.Success, result.Service)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/management/jobs", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var listed struct {
+		Jobs []Job `json:"jobs"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	require.Len(t, listed.Jobs, 1)
+	assert.Equal(t, created.ID, listed.Jobs[0].ID)
+}
+
+// TestJobRegistryExpiresFinishedJobs tests that finished jobs disappear after the TTL while running ones stay
+func TestJobRegistryExpiresFinishedJobs(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	jobs := NewJobRegistry(10 * time.Minute)
+	jobs.now = func() time.Time { return now }
+
+	finished := jobs.Create("start-all", "locally")
+	jobs.Finish(finished.ID, true)
+	running := jobs.Create("start-all", "locally")
+
+	now = now.Add(10 * time.Minute)
+	_, ok := jobs.Get(finished.ID)
+	assert.True(t, ok, "job should still be available at the TTL boundary")
+
+	now = now.Add(time.Second)
+	_, ok = jobs.Get(finished.ID)
+	assert.False(t, ok, "finished job should expire after the TTL")
+
+	_, ok = jobs.Get(running.ID)
+	assert.True(t, ok, "running jobs never expire")
+	assert.Len(t, jobs.List(), 1)
+}
+
+// TestJobHandlerNotFound tests that unknown job IDs return a JSON 404
+func TestJobHandlerNotFound(t *testing.T) {
+	router := newJobsRouter(NewJobRegistry(time.Hour))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/management/jobs/missing", nil))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "job_not_found")
+}
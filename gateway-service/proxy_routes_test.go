@@ -0,0 +1,178 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// forwardedRequest is what a stub backend saw of a proxied request
+type forwardedRequest struct {
+	service    string
+	requestURI string
+	gateway    string
+}
+
+// newStubBackends starts one recording server per service and returns a Config pointing at them
+func newStubBackends(t *testing.T) (Config, func() []forwardedRequest) {
+	var (
+		mu       sync.Mutex
+		received []forwardedRequest
+	)
+	stub := func(service string) string {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			received = append(received, forwardedRequest{
+				service:    service,
+				requestURI: r.URL.RequestURI(),
+				gateway:    r.Header.Get("X-Gateway-Service"),
+			})
+			mu.Unlock()
+			w.WriteHeader(http.StatusOK)
+		}))
+		t.Cleanup(server.Close)
+		return server.URL
+	}
+
+	config := Config{
+		SessionServiceURL:   stub("session-service"),
+		OrdersServiceURL:    stub("orders-service"),
+		InventoryServiceURL: stub("inventory-service"),
+		InvoiceServiceURL:   stub("invoice-service"),
+	}
+	return config, func() []forwardedRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]forwardedRequest(nil), received...)
+	}
+}
+
+// TestProxyRoutesForwardPaths tests that each proxied route reaches the right service with its path unchanged
+func TestProxyRoutesForwardPaths(t *testing.T) {
+	config, received := newStubBackends(t)
+
+	router := mux.NewRouter()
+	registerProxyRoutes(router.PathPrefix("/api").Subrouter(), config)
+
+	testCases := map[string]struct {
+		method          string
+		path            string
+		expectedService string
+		expectedURI     string
+	}{
+		"session login": {
+			method: http.MethodPost, path: "/api/v1/sessions/p/login",
+			expectedService: "session-service", expectedURI: "/api/v1/sessions/p/login",
+		},
+		"session user with id": {
+			method: http.MethodDelete, path: "/api/v1/sessions/user/42",
+			expectedService: "session-service", expectedURI: "/api/v1/sessions/user/42",
+		},
+		"auth user update": {
+			method: http.MethodPatch, path: "/api/v1/auth/users/7",
+			expectedService: "session-service", expectedURI: "/api/v1/auth/users/7",
+		},
+		"orders health": {
+			method: http.MethodGet, path: "/api/v1/orders/p/health",
+			expectedService: "orders-service", expectedURI: "/api/v1/orders/p/health",
+		},
+		"orders collection": {
+			method: http.MethodPost, path: "/api/v1/orders",
+			expectedService: "orders-service", expectedURI: "/api/v1/orders",
+		},
+		"orders nested path with query": {
+			method: http.MethodGet, path: "/api/v1/orders/foo/receipt?format=pdf",
+			expectedService: "orders-service", expectedURI: "/api/v1/orders/foo/receipt?format=pdf",
+		},
+		"inventory nested path": {
+			method: http.MethodPost, path: "/api/v1/inventory/ingredients/3/reprice",
+			expectedService: "inventory-service", expectedURI: "/api/v1/inventory/ingredients/3/reprice",
+		},
+		"invoices nested path": {
+			method: http.MethodGet, path: "/api/v1/invoices/expense-categories/5",
+			expectedService: "invoice-service", expectedURI: "/api/v1/invoices/expense-categories/5",
+		},
+		// Config routes map onto each service's own /api/v1/config
+		"orders config": {
+			method: http.MethodGet, path: "/api/v1/orders/config",
+			expectedService: "orders-service", expectedURI: "/api/v1/config",
+		},
+		"inventory config": {
+			method: http.MethodGet, path: "/api/v1/inventory/config",
+			expectedService: "inventory-service", expectedURI: "/api/v1/config",
+		},
+		"invoices config": {
+			method: http.MethodGet, path: "/api/v1/invoices/config",
+			expectedService: "invoice-service", expectedURI: "/api/v1/config",
+		},
+		// The invoice health route is the one exception: it probes the service's root health endpoint
+		"invoices health": {
+			method: http.MethodGet, path: "/api/v1/invoices/p/health",
+			expectedService: "invoice-service", expectedURI: "/health",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			before := len(received())
+
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(""))
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			require.Equal(t, http.StatusOK, rr.Code)
+			forwarded := received()
+			require.Len(t, forwarded, before+1)
+			assert.Equal(t, tc.expectedService, forwarded[before].service)
+			assert.Equal(t, tc.expectedURI, forwarded[before].requestURI)
+			assert.Equal(t, "ice-cream-gateway", forwarded[before].gateway)
+		})
+	}
+}
+
+// TestProxyRoutesApplyBusinessMiddleware tests that only business routes run the guarding middleware
+func TestProxyRoutesApplyBusinessMiddleware(t *testing.T) {
+	config, _ := newStubBackends(t)
+
+	deny := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		})
+	}
+	router := mux.NewRouter()
+	registerProxyRoutes(router.PathPrefix("/api").Subrouter(), config, deny)
+
+	testCases := map[string]struct {
+		method         string
+		path           string
+		expectedStatus int
+	}{
+		"orders guarded":         {method: http.MethodGet, path: "/api/v1/orders/1", expectedStatus: http.StatusUnauthorized},
+		"inventory guarded":      {method: http.MethodGet, path: "/api/v1/inventory/recipes", expectedStatus: http.StatusUnauthorized},
+		"invoices guarded":       {method: http.MethodGet, path: "/api/v1/invoices", expectedStatus: http.StatusUnauthorized},
+		"orders config guarded":  {method: http.MethodGet, path: "/api/v1/orders/config", expectedStatus: http.StatusUnauthorized},
+		"invoice config guarded": {method: http.MethodGet, path: "/api/v1/invoices/config", expectedStatus: http.StatusUnauthorized},
+		"login open":             {method: http.MethodPost, path: "/api/v1/sessions/p/login", expectedStatus: http.StatusOK},
+		"orders health open":     {method: http.MethodGet, path: "/api/v1/orders/p/health", expectedStatus: http.StatusOK},
+		"inventory health open":  {method: http.MethodGet, path: "/api/v1/inventory/p/health", expectedStatus: http.StatusOK},
+		"change password open":   {method: http.MethodPost, path: "/api/v1/auth/change-password", expectedStatus: http.StatusOK},
+		"session refresh open":   {method: http.MethodPost, path: "/api/v1/sessions/refresh", expectedStatus: http.StatusOK},
+		"invoices health open":   {method: http.MethodGet, path: "/api/v1/invoices/p/health", expectedStatus: http.StatusOK},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+		})
+	}
+}
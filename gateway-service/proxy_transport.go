@@ -0,0 +1,50 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+// ProxyTransportConfig tunes the connection pool the reverse proxies use to reach the backend services
+type ProxyTransportConfig struct {
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     time.Duration
+	ForceAttemptHTTP2   bool
+}
+
+// loadProxyTransportConfig reads the proxy connection pool settings from the environment. The defaults keep
+// enough idle connections per backend for concurrent UI traffic; net/http's default of 2 forces new dials.
+func loadProxyTransportConfig() ProxyTransportConfig {
+	return ProxyTransportConfig{
+		MaxIdleConns:        getEnvInt("GATEWAY_PROXY_MAX_IDLE_CONNS", 100),
+		MaxIdleConnsPerHost: getEnvInt("GATEWAY_PROXY_MAX_IDLE_CONNS_PER_HOST", 32),
+		IdleConnTimeout:     getEnvDuration("GATEWAY_PROXY_IDLE_CONN_TIMEOUT", 90*time.Second),
+		ForceAttemptHTTP2:   getEnvBool("GATEWAY_PROXY_FORCE_HTTP2", true),
+	}
+}
+
+// newProxyTransport builds the transport shared by every reverse proxy so keep-alive connections to a
+// backend are pooled across all of the routes that forward to it
+func newProxyTransport(config ProxyTransportConfig) *http.Transport {
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		MaxIdleConns:          config.MaxIdleConns,
+		MaxIdleConnsPerHost:   config.MaxIdleConnsPerHost,
+		IdleConnTimeout:       config.IdleConnTimeout,
+		ForceAttemptHTTP2:     config.ForceAttemptHTTP2,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+}
+
+// proxyTransportConfig holds the settings proxyTransport was built from
+var proxyTransportConfig = loadProxyTransportConfig()
+
+// proxyTransport is the single transport every proxy created by createProxyHandler forwards through
+var proxyTransport http.RoundTripper = newProxyTransport(proxyTransportConfig)
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCountingBackend starts a stub backend that counts the TCP connections opened to it
+func newCountingBackend(t testing.TB) (*httptest.Server, *atomic.Int64) {
+	t.Helper()
+
+	connections := &atomic.Int64{}
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"status":"ok"}`))
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			connections.Add(1)
+		}
+	}
+	server.Start()
+	t.Cleanup(server.Close)
+
+	return server, connections
+}
+
+// TestLoadProxyTransportConfig tests the proxy transport defaults and their environment overrides
+func TestLoadProxyTransportConfig(t *testing.T) {
+	defaults := ProxyTransportConfig{
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 32,
+		IdleConnTimeout:     90 * time.Second,
+		ForceAttemptHTTP2:   true,
+	}
+
+	testCases := map[string]struct {
+		envVars  map[string]string
+		expected ProxyTransportConfig
+	}{
+		"defaults": {
+			envVars:  map[string]string{},
+			expected: defaults,
+		},
+		"custom values": {
+			envVars: map[string]string{
+				"GATEWAY_PROXY_MAX_IDLE_CONNS":          "50",
+				"GATEWAY_PROXY_MAX_IDLE_CONNS_PER_HOST": "8",
+				"GATEWAY_PROXY_IDLE_CONN_TIMEOUT":       "30s",
+				"GATEWAY_PROXY_FORCE_HTTP2":             "false",
+			},
+			expected: ProxyTransportConfig{
+				MaxIdleConns:        50,
+				MaxIdleConnsPerHost: 8,
+				IdleConnTimeout:     30 * time.Second,
+				ForceAttemptHTTP2:   false,
+			},
+		},
+		"invalid values fall back to defaults": {
+			envVars: map[string]string{
+				"GATEWAY_PROXY_MAX_IDLE_CONNS_PER_HOST": "lots",
+				"GATEWAY_PROXY_IDLE_CONN_TIMEOUT":       "later",
+				"GATEWAY_PROXY_FORCE_HTTP2":             "maybe",
+			},
+			expected: defaults,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			for key, value := range tc.envVars {
+				os.Setenv(key, value)
+				defer os.Unsetenv(key)
+			}
+
+			config := loadProxyTransportConfig()
+			assert.Equal(t, tc.expected, config)
+
+			transport := newProxyTransport(config)
+			assert.Equal(t, tc.expected.MaxIdleConnsPerHost, transport.MaxIdleConnsPerHost)
+			assert.Equal(t, tc.expected.IdleConnTimeout, transport.IdleConnTimeout)
+			assert.Equal(t, tc.expected.ForceAttemptHTTP2, transport.ForceAttemptHTTP2)
+		})
+	}
+}
+
+// TestProxyReusesBackendConnections tests that sequential proxied requests share one keep-alive connection,
+// including requests made through different proxies to the same backend
+func TestProxyReusesBackendConnections(t *testing.T) {
+	backend, connections := newCountingBackend(t)
+
+	ordersProxy := createProxyHandler(backend.URL)
+	otherProxy := createProxyHandler(backend.URL)
+
+	for i := 0; i < 10; i++ {
+		handler := ordersProxy
+		if i%2 == 1 {
+			handler = otherProxy
+		}
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/orders", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+
+	assert.Equal(t, int64(1), connections.Load())
+}
+
+// BenchmarkProxySequentialRequests reports how many backend connections sequential proxied requests open
+func BenchmarkProxySequentialRequests(b *testing.B) {
+	backend, connections := newCountingBackend(b)
+	handler := createProxyHandler(backend.URL)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/orders", nil))
+	}
+	b.ReportMetric(float64(connections.Load()), "conns")
+}
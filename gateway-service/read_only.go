@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync/atomic"
+
+	"shared/httpx"
+)
+
+// readOnlyMessage tells clients why a write was refused while maintenance is in progress
+const readOnlyMessage = "The system is in read-only maintenance mode; changes are temporarily disabled"
+
+// readOnlyError is the JSON body returned for writes refused in read-only mode
+type readOnlyError struct {
+	Error   string `json:"error"`
+	Message string `json:"message"`
+	Path    string `json:"path"`
+	Method  string `json:"method"`
+}
+
+// readOnlyStatus is the body of the read-only toggle endpoint
+type readOnlyStatus struct {
+	Enabled bool `json:"enabled"`
+}
+
+// ReadOnlyMode blocks writes through the gateway during migrations while still serving reads.
+// It starts from GATEWAY_READ_ONLY and can be toggled at runtime through the management API
+type ReadOnlyMode struct {
+	enabled atomic.Bool
+}
+
+// NewReadOnlyMode creates a read-only switch in the given state
+func NewReadOnlyMode(enabled bool) *ReadOnlyMode {
+	mode := &ReadOnlyMode{}
+	mode.enabled.Store(enabled)
+	return mode
+}
+
+// Enabled reports whether writes are currently blocked
+func (m *ReadOnlyMode) Enabled() bool {
+	return m.enabled.Load()
+}
+
+// Set turns read-only mode on or off
+func (m *ReadOnlyMode) Set(enabled bool) {
+	m.enabled.Store(enabled)
+}
+
+// Middleware answers POST, PUT, PATCH and DELETE requests with a JSON 503 while read-only mode is on
+func (m *ReadOnlyMode) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if m.Enabled() && isWriteMethod(r.Method) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(readOnlyError{
+				Error:   "read_only_mode",
+				Message: readOnlyMessage,
+				Path:    r.URL.Path,
+				Method:  r.Method,
+			})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// StatusHandler reports whether read-only mode is on
+func (m *ReadOnlyMode) StatusHandler(w http.ResponseWriter, r *http.Request) {
+	writeReadOnlyStatus(w, m.Enabled())
+}
+
+// ToggleHandler turns read-only mode on or off from a {"enabled": bool} body
+func (m *ReadOnlyMode) ToggleHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Enabled *bool `json:"enabled"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Enabled == nil {
+		httpx.WriteRouteError(w, http.StatusBadRequest, "invalid_request", r)
+		return
+	}
+
+	m.Set(*req.Enabled)
+	log.Printf("Read-only maintenance mode set to %t", *req.Enabled)
+	writeReadOnlyStatus(w, *req.Enabled)
+}
+
+func writeReadOnlyStatus(w http.ResponseWriter, enabled bool) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(readOnlyStatus{Enabled: enabled})
+}
+
+// isWriteMethod reports whether method changes data
+func isWriteMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newReadOnlyTestRouter wires a business route behind the read-only middleware and the toggle endpoints
+func newReadOnlyTestRouter(mode *ReadOnlyMode) *mux.Router {
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+
+	router := mux.NewRouter()
+	management := router.PathPrefix("/api/management").Subrouter()
+	management.HandleFunc("/read-only", mode.StatusHandler).Methods("GET")
+	management.HandleFunc("/read-only", mode.ToggleHandler).Methods("PUT")
+
+	business := router.PathPrefix("/api/v1/inventory").Subrouter()
+	business.PathPrefix("").HandlerFunc(ok)
+	business.Use(mode.Middleware)
+	return router
+}
+
+// TestReadOnlyMiddleware tests that writes are refused with a JSON 503 while reads pass through
+func TestReadOnlyMiddleware(t *testing.T) {
+	testCases := map[string]struct {
+		enabled        bool
+		method         string
+		expectedStatus int
+	}{
+		"disabled allows writes":    {enabled: false, method: http.MethodPost, expectedStatus: http.StatusOK},
+		"enabled allows GET":        {enabled: true, method: http.MethodGet, expectedStatus: http.StatusOK},
+		"enabled blocks POST":       {enabled: true, method: http.MethodPost, expectedStatus: http.StatusServiceUnavailable},
+		"enabled blocks PUT":        {enabled: true, method: http.MethodPut, expectedStatus: http.StatusServiceUnavailable},
+		"enabled blocks PATCH":      {enabled: true, method: http.MethodPatch, expectedStatus: http.StatusServiceUnavailable},
+		"enabled blocks DELETE":     {enabled: true, method: http.MethodDelete, expectedStatus: http.StatusServiceUnavailable},
+		"enabled allows HEAD reads": {enabled: true, method: http.MethodHead, expectedStatus: http.StatusOK},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			router := newReadOnlyTestRouter(NewReadOnlyMode(tc.enabled))
+
+			req := httptest.NewRequest(tc.method, "/api/v1/inventory/suppliers", nil)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectedStatus, rr.Code)
+			if tc.expectedStatus != http.StatusServiceUnavailable {
+				return
+			}
+
+			assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+			var body readOnlyError
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+			assert.Equal(t, readOnlyError{
+				Error:   "read_only_mode",
+				Message: readOnlyMessage,
+				Path:    "/api/v1/inventory/suppliers",
+				Method:  tc.method,
+			}, body)
+		})
+	}
+}
+
+// TestReadOnlyToggle tests switching read-only mode at runtime through the management endpoint
+func TestReadOnlyToggle(t *testing.T) {
+	mode := NewReadOnlyMode(false)
+	router := newReadOnlyTestRouter(mode)
+
+	write := func() int {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/v1/inventory/suppliers", nil))
+		return rr.Code
+	}
+	read := func() int {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/inventory/suppliers", nil))
+		return rr.Code
+	}
+	toggle := func(body string) (int, readOnlyStatus) {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodPut, "/api/management/read-only", bytes.NewBufferString(body)))
+		var status readOnlyStatus
+		json.Unmarshal(rr.Body.Bytes(), &status)
+		return rr.Code, status
+	}
+
+	assert.Equal(t, http.StatusOK, write())
+
+	code, status := toggle(`{"enabled": true}`)
+	require.Equal(t, http.StatusOK, code)
+	assert.True(t, status.Enabled)
+	assert.True(t, mode.Enabled())
+	assert.Equal(t, http.StatusServiceUnavailable, write())
+	assert.Equal(t, http.StatusOK, read())
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/management/read-only", nil))
+	assert.JSONEq(t, `{"enabled": true}`, rr.Body.String())
+
+	code, _ = toggle(`{}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.True(t, mode.Enabled(), "an invalid toggle must not change the mode")
+
+	code, status = toggle(`{"enabled": false}`)
+	require.Equal(t, http.StatusOK, code)
+	assert.False(t, status.Enabled)
+	assert.Equal(t, http.StatusOK, write())
+}
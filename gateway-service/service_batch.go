@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ServiceOperationResult is the outcome of a single service within a batch management operation
+type ServiceOperationResult struct {
+	Service string `json:"service"`
+	Success bool   `json:"success"`
+	Output  string `json:"output"`
+	Error   string `json:"error,omitempty"`
+}
+
+// BatchOperationResult is the outcome of a management operation spanning several services
+type BatchOperationResult struct {
+	Action      string                   `json:"action"`
+	Environment string                   `json:"environment"`
+	Success     bool                     `json:"success"`
+	Results     []ServiceOperationResult `json:"results"`
+	StartedAt   time.Time                `json:"started_at"`
+	FinishedAt  time.Time                `json:"finished_at"`
+}
+
+// startServiceInBatch starts (or restarts) one service during a batch operation (replaceable in tests)
+var startServiceInBatch = autoRestartService
+
+// startAllOrder returns data-service followed by its dependents. The gateway is left out:
+// it is the one serving the request, and restarting it would drop the response.
+func startAllOrder() []string {
+	order := []string{"data-service"}
+	for _, serviceName := range dependentServices {
+		if serviceName != "gateway-service" {
+			order = append(order, serviceName)
+		}
+	}
+	return order
+}
+
+// runStartAll starts every service in dependency order. A failing service does not stop the
+// batch: each service reports its own result and the batch succeeds only if all of them did.
+// report, when set, receives each result as soon as its service finishes.
+func runStartAll(environment string, report func(ServiceOperationResult)) BatchOperationResult {
+	batch := BatchOperationResult{
+		Action:      "start-all",
+		Environment: environment,
+		Success:     true,
+		StartedAt:   time.Now(),
+	}
+
+	for i, serviceName := range startAllOrder() {
+		if i > 0 {
+			time.Sleep(dependentServiceStartDelay)
+		}
+
+		log.Printf("🔄 Start-all: starting %s...", serviceName)
+		result := startServiceInBatch(serviceName, environment)
+		if !result.Success {
+			batch.Success = false
+		}
+		batch.Results = append(batch.Results, result)
+		if report != nil {
+			report(result)
+		}
+	}
+
+	batch.FinishedAt = time.Now()
+	return batch
+}
+
+// isAsync reports whether the caller asked for a background job via ?async=true or the request body flag
+func isAsync(r *http.Request, bodyFlag bool) bool {
+	if bodyFlag {
+		return true
+	}
+	async, err := strconv.ParseBool(r.URL.Query().Get("async"))
+	return err == nil && async
+}
+
+// createStartAllHandler handles POST /api/management/start-all. By default every service is started
+// synchronously; async requests get a job ID back immediately and are tracked in jobs.
+func createStartAllHandler(jobs *JobRegistry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var requestBody struct {
+			Environment string `json:"environment"`
+			Async       bool   `json:"async"`
+		}
+
+		// The body is optional: an empty request starts everything locally
+		if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil && !errors.Is(err, io.EOF) {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		environment := requestBody.Environment
+		if environment == "" {
+			environment = "locally" // Default
+		}
+
+		if isAsync(r, requestBody.Async) {
+			job := jobs.Create("start-all", environment)
+			log.Printf("🔧 Starting all services in job %s (environment: %s)", job.ID, environment)
+
+			go func() {
+				batch := runStartAll(environment, func(result ServiceOperationResult) {
+					jobs.Record(job.ID, result)
+				})
+				jobs.Finish(job.ID, batch.Success)
+			}()
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusAccepted)
+			json.NewEncoder(w).Encode(job)
+			return
+		}
+
+		log.Printf("🔧 Starting all services (environment: %s)", environment)
+		batch := runStartAll(environment, nil)
+
+		if batch.Success {
+			log.Printf("🎉 Start-all completed successfully")
+		} else {
+			log.Printf("❌ Start-all completed with failures")
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if batch.Success {
+			w.WriteHeader(http.StatusOK)
+		} else {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		json.NewEncoder(w).Encode(batch)
+	}
+}
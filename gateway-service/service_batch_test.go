@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubBatchStart replaces startServiceInBatch with a recorder that fails the given services
+func stubBatchStart(t *testing.T, failing ...string) *[]string {
+	t.Helper()
+
+	var started []string
+	originalStart, originalDelay := startServiceInBatch, dependentServiceStartDelay
+	startServiceInBatch = func(serviceName, environment string) ServiceOperationResult {
+		started = append(started, serviceName)
+		for _, failed := range failing {
+			if failed == serviceName {
+				return ServiceOperationResult{Service: serviceName, Output: "make: *** [start] Error 1", Error: "failed to start service: exit status 2"}
+			}
+		}
+		return ServiceOperationResult{Service: serviceName, Success: true, Output: fmt.Sprintf("started %s in %s", serviceName, environment)}
+	}
+	dependentServiceStartDelay = 0
+	t.Cleanup(func() {
+		startServiceInBatch, dependentServiceStartDelay = originalStart, originalDelay
+	})
+
+	return &started
+}
+
+// TestStartAllHandler tests that services start in dependency order and failures are reported per service
+func TestStartAllHandler(t *testing.T) {
+	expectedOrder := []string{"data-service", "session-service", "orders-service", "inventory-service", "invoice-service"}
+
+	testCases := map[string]struct {
+		body           string
+		failing        []string
+		expectedStatus int
+		expectedEnv    string
+	}{
+		"all services start": {
+			body:           `{"environment": "docker"}`,
+			expectedStatus: http.StatusOK,
+			expectedEnv:    "docker",
+		},
+		"empty body defaults to locally": {
+			expectedStatus: http.StatusOK,
+			expectedEnv:    "locally",
+		},
+		"one failing service does not stop the batch": {
+			body:           `{}`,
+			failing:        []string{"orders-service"},
+			expectedStatus: http.StatusInternalServerError,
+			expectedEnv:    "locally",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			started := stubBatchStart(t, tc.failing...)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/management/start-all", strings.NewReader(tc.body))
+			w := httptest.NewRecorder()
+
+			createStartAllHandler(NewJobRegistry(time.Hour))(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Equal(t, expectedOrder, *started)
+
+			var batch BatchOperationResult
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &batch))
+			assert.Equal(t, "start-all", batch.Action)
+			assert.Equal(t, tc.expectedEnv, batch.Environment)
+			assert.Equal(t, len(tc.failing) == 0, batch.Success)
+			assert.False(t, batch.FinishedAt.Before(batch.StartedAt))
+
+			require.Len(t, batch.Results, len(expectedOrder))
+			for i, result := range batch.Results {
+				assert.Equal(t, expectedOrder[i], result.Service)
+				failed := false
+				for _, failing := range tc.failing {
+					failed = failed || failing == result.Service
+				}
+				assert.Equal(t, !failed, result.Success, result.Service)
+				if failed {
+					assert.NotEmpty(t, result.Error)
+					assert.NotEmpty(t, result.Output)
+				}
+			}
+		})
+	}
+}
+
+// TestStartAllHandlerInvalidBody tests that a malformed body is rejected before anything starts
+func TestStartAllHandlerInvalidBody(t *testing.T) {
+	started := stubBatchStart(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/management/start-all", strings.NewReader("{"))
+	w := httptest.NewRecorder()
+
+	createStartAllHandler(NewJobRegistry(time.Hour))(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Empty(t, *started)
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRunCommandWithTimeout tests command execution, failure and timeout handling
+func TestRunCommandWithTimeout(t *testing.T) {
+	testCases := map[string]struct {
+		args           []string
+		timeout        time.Duration
+		expectedOutput string
+		expectedError  string
+	}{
+		"successful command": {
+			args:           []string{"sh", "-c", "echo hello"},
+			timeout:        5 * time.Second,
+			expectedOutput: "hello\n",
+		},
+		"failing command": {
+			args:          []string{"sh", "-c", "echo boom; exit 3"},
+			timeout:       5 * time.Second,
+			expectedError: "exit status 3",
+		},
+		"command exceeding timeout": {
+			args:          []string{"sleep", "10"},
+			timeout:       100 * time.Millisecond,
+			expectedError: "command timed out after 100ms: sleep 10",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			output, err := runCommandWithTimeout(".", tc.timeout, tc.args[0], tc.args[1:]...)
+
+			if tc.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedOutput, output)
+		})
+	}
+}
+
+// TestRunCommandWithTimeoutKillsProcessGroup tests that children of a timed-out command are killed as well
+func TestRunCommandWithTimeoutKillsProcessGroup(t *testing.T) {
+	pidFile := filepath.Join(t.TempDir(), "child.pid")
+	start := time.Now()
+
+	// The shell forks a sleeping child and records its PID; the child would keep running and hold the
+	// output pipe open if only the shell were killed
+	_, err := runCommandWithTimeout(".", 100*time.Millisecond, "sh", "-c", `sleep 10 & echo $! > "$1"; wait`, "sh", pidFile)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out")
+	assert.Less(t, time.Since(start), 3*time.Second)
+
+	data, err := os.ReadFile(pidFile)
+	require.NoError(t, err)
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	require.NoError(t, err)
+
+	// The orphaned child only disappears once init reaps it, so allow some slack
+	assert.Eventually(t, func() bool {
+		return errors.Is(syscall.Kill(pid, 0), syscall.ESRCH)
+	}, 5*time.Second, 20*time.Millisecond, "child process %d is still running", pid)
+}
+
+// TestExecuteServiceCommandUnknownService tests that unknown services are rejected before running anything
+func TestExecuteServiceCommandUnknownService(t *testing.T) {
+	executed := forbidCommandExecution(t)
+
+	success, output, err := executeServiceCommand("unknown-service", "start-locally")
+
+	assert.False(t, success)
+	assert.Empty(t, output)
+	assert.EqualError(t, err, "unknown service: unknown-service")
+	assert.Equal(t, 0, *executed)
+}
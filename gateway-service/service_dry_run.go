@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// ServiceCommandPlan lists the commands that would run for a single service
+type ServiceCommandPlan struct {
+	Service  string   `json:"service"`
+	Commands []string `json:"commands"`
+}
+
+// ServiceActionPlan describes what a management action would do without executing it
+type ServiceActionPlan struct {
+	Service              string               `json:"service"`
+	Action               string               `json:"action"`
+	Environment          string               `json:"environment"`
+	Commands             []string             `json:"commands"`
+	DependentRestartPlan []ServiceCommandPlan `json:"dependent_restart_plan,omitempty"`
+}
+
+// isDryRun reports whether the caller asked for a preview via ?dry_run=true or the request body flag
+func isDryRun(r *http.Request, bodyFlag bool) bool {
+	if bodyFlag {
+		return true
+	}
+	dryRun, err := strconv.ParseBool(r.URL.Query().Get("dry_run"))
+	return err == nil && dryRun
+}
+
+// describeServiceCommand returns the shell command executeServiceCommand would run
+func describeServiceCommand(serviceName, makeTarget string) (string, error) {
+	serviceDir, exists := serviceDirectories[serviceName]
+	if !exists {
+		return "", fmt.Errorf("unknown service: %s", serviceName)
+	}
+	return fmt.Sprintf("cd %s && make %s", serviceDir, makeTarget), nil
+}
+
+// describeServiceCommands describes a sequence of make targets for one service
+func describeServiceCommands(serviceName string, makeTargets ...string) ([]string, error) {
+	commands := make([]string, 0, len(makeTargets))
+	for _, makeTarget := range makeTargets {
+		command, err := describeServiceCommand(serviceName, makeTarget)
+		if err != nil {
+			return nil, err
+		}
+		commands = append(commands, command)
+	}
+	return commands, nil
+}
+
+// buildStartPlan mirrors serviceStartHandler: stop first if running, then start, then restart dependents for data-service
+func buildStartPlan(serviceName, environment string) (*ServiceActionPlan, error) {
+	targets := []string{fmt.Sprintf("start-%s", environment)}
+	if isServiceRunning(serviceName) {
+		targets = append([]string{fmt.Sprintf("stop-%s", environment)}, targets...)
+	}
+
+	commands, err := describeServiceCommands(serviceName, targets...)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &ServiceActionPlan{
+		Service:     serviceName,
+		Action:      "start",
+		Environment: environment,
+		Commands:    commands,
+	}
+
+	if serviceName == "data-service" {
+		plan.DependentRestartPlan = buildDependentRestartPlan(environment)
+	}
+
+	return plan, nil
+}
+
+// buildStopPlan mirrors serviceStopHandler: nothing runs when the service is already stopped
+func buildStopPlan(serviceName, environment string) (*ServiceActionPlan, error) {
+	commands := []string{}
+	if isServiceRunning(serviceName) {
+		var err error
+		commands, err = describeServiceCommands(serviceName, fmt.Sprintf("stop-%s", environment))
+		if err != nil {
+			return nil, err
+		}
+	} else if _, exists := serviceDirectories[serviceName]; !exists {
+		return nil, fmt.Errorf("unknown service: %s", serviceName)
+	}
+
+	return &ServiceActionPlan{
+		Service:     serviceName,
+		Action:      "stop",
+		Environment: environment,
+		Commands:    commands,
+	}, nil
+}
+
+// buildRestartPlan mirrors serviceRestartHandler: always stop then start
+func buildRestartPlan(serviceName, environment string) (*ServiceActionPlan, error) {
+	commands, err := describeServiceCommands(serviceName, fmt.Sprintf("stop-%s", environment), fmt.Sprintf("start-%s", environment))
+	if err != nil {
+		return nil, err
+	}
+
+	return &ServiceActionPlan{
+		Service:     serviceName,
+		Action:      "restart",
+		Environment: environment,
+		Commands:    commands,
+	}, nil
+}
+
+// buildDependentRestartPlan mirrors restartDependentServices in dependency order
+func buildDependentRestartPlan(environment string) []ServiceCommandPlan {
+	plan := make([]ServiceCommandPlan, 0, len(dependentServices))
+	for _, serviceName := range dependentServices {
+		targets := []string{fmt.Sprintf("start-%s", environment)}
+		if isServiceRunning(serviceName) {
+			targets = append([]string{fmt.Sprintf("stop-%s", environment)}, targets...)
+		}
+
+		// dependentServices only holds known services, so describing them cannot fail
+		commands, _ := describeServiceCommands(serviceName, targets...)
+		plan = append(plan, ServiceCommandPlan{
+			Service:  serviceName,
+			Commands: commands,
+		})
+	}
+	return plan
+}
+
+// writeDryRunResponse writes the preview of a management action
+func writeDryRunResponse(w http.ResponseWriter, plan *ServiceActionPlan, err error) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"dry_run": true,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"dry_run": true,
+		"message": fmt.Sprintf("Dry run: no commands were executed for %s %s", plan.Action, plan.Service),
+		"plan":    plan,
+	})
+}
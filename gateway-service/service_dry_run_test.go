@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// forbidCommandExecution replaces execCommandContext so any attempt to run make fails the test
+func forbidCommandExecution(t *testing.T) *int {
+	t.Helper()
+
+	executed := 0
+	original := execCommandContext
+	execCommandContext = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		executed++
+		t.Errorf("unexpected command execution: %s %s", name, strings.Join(args, " "))
+		return exec.CommandContext(ctx, "true")
+	}
+	t.Cleanup(func() { execCommandContext = original })
+
+	return &executed
+}
+
+// TestServiceManagementDryRun tests that dry-run requests describe the plan without executing any command
+func TestServiceManagementDryRun(t *testing.T) {
+	testCases := map[string]struct {
+		handler          http.HandlerFunc
+		service          string
+		url              string
+		body             string
+		expectedAction   string
+		expectedCommands []string
+	}{
+		"restart via query param": {
+			handler:        serviceRestartHandler,
+			service:        "orders-service",
+			url:            "/api/management/services/orders-service/restart?dry_run=true",
+			body:           `{"environment": "locally"}`,
+			expectedAction: "restart",
+			expectedCommands: []string{
+				"cd orders-service && make stop-locally",
+				"cd orders-service && make start-locally",
+			},
+		},
+		"restart via body flag": {
+			handler:        serviceRestartHandler,
+			service:        "inventory-service",
+			url:            "/api/management/services/inventory-service/restart",
+			body:           `{"environment": "docker", "dry_run": true}`,
+			expectedAction: "restart",
+			expectedCommands: []string{
+				"cd inventory-service && make stop-docker",
+				"cd inventory-service && make start-docker",
+			},
+		},
+		"start stopped service": {
+			handler:          serviceStartHandler,
+			service:          "invoice-service",
+			url:              "/api/management/services/invoice-service/start?dry_run=true",
+			body:             `{}`,
+			expectedAction:   "start",
+			expectedCommands: []string{"cd invoice-service && make start-locally"},
+		},
+		"stop stopped service": {
+			handler:          serviceStopHandler,
+			service:          "invoice-service",
+			url:              "/api/management/services/invoice-service/stop?dry_run=1",
+			body:             `{}`,
+			expectedAction:   "stop",
+			expectedCommands: []string{},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			executed := forbidCommandExecution(t)
+
+			req := httptest.NewRequest("POST", tc.url, strings.NewReader(tc.body))
+			req = mux.SetURLVars(req, map[string]string{"service": tc.service})
+			w := httptest.NewRecorder()
+
+			tc.handler(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, 0, *executed)
+
+			var response struct {
+				Success bool              `json:"success"`
+				DryRun  bool              `json:"dry_run"`
+				Plan    ServiceActionPlan `json:"plan"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+			assert.True(t, response.Success)
+			assert.True(t, response.DryRun)
+			assert.Equal(t, tc.service, response.Plan.Service)
+			assert.Equal(t, tc.expectedAction, response.Plan.Action)
+			assert.Equal(t, tc.expectedCommands, response.Plan.Commands)
+			assert.Empty(t, response.Plan.DependentRestartPlan)
+		})
+	}
+}
+
+// TestServiceStartDryRunDataServiceListsDependents tests that the data-service plan lists dependents in restart order
+func TestServiceStartDryRunDataServiceListsDependents(t *testing.T) {
+	executed := forbidCommandExecution(t)
+
+	req := httptest.NewRequest("POST", "/api/management/services/data-service/start?dry_run=true", strings.NewReader(`{"environment": "locally"}`))
+	req = mux.SetURLVars(req, map[string]string{"service": "data-service"})
+	w := httptest.NewRecorder()
+
+	serviceStartHandler(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 0, *executed)
+
+	var response struct {
+		Plan ServiceActionPlan `json:"plan"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+	assert.Contains(t, response.Plan.Commands, "cd data-service && make start-locally")
+
+	var order []string
+	for _, dependent := range response.Plan.DependentRestartPlan {
+		order = append(order, dependent.Service)
+		assert.Contains(t, dependent.Commands, "cd "+dependent.Service+" && make start-locally")
+	}
+	assert.Equal(t, []string{
+		"session-service",
+		"orders-service",
+		"inventory-service",
+		"invoice-service",
+		"gateway-service",
+	}, order)
+}
+
+// TestServiceManagementDryRunUnknownService tests that previewing an unknown service is rejected
+func TestServiceManagementDryRunUnknownService(t *testing.T) {
+	executed := forbidCommandExecution(t)
+
+	req := httptest.NewRequest("POST", "/api/management/services/unknown-service/restart?dry_run=true", strings.NewReader(`{}`))
+	req = mux.SetURLVars(req, map[string]string{"service": "unknown-service"})
+	w := httptest.NewRecorder()
+
+	serviceRestartHandler(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, 0, *executed)
+	assert.Contains(t, w.Body.String(), "unknown service: unknown-service")
+}
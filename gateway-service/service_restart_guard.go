@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// restartRetryAfter is the Retry-After hint sent to clients while a service is being restarted
+var restartRetryAfter = 5 * time.Second
+
+// restartingServices records the services a management restart is currently cycling
+var restartingServices sync.Map
+
+// markServiceRestarting flags a service as restarting until the returned function is called.
+// Callers must hold the service's management lock so only one restart marks it at a time.
+func markServiceRestarting(serviceName string) func() {
+	restartingServices.Store(serviceName, true)
+	return func() {
+		restartingServices.Delete(serviceName)
+	}
+}
+
+// isServiceRestarting reports whether a management restart of the service is in progress
+func isServiceRestarting(serviceName string) bool {
+	_, restarting := restartingServices.Load(serviceName)
+	return restarting
+}
+
+// lockServiceForRestart takes the service's management lock and flags the service as restarting;
+// the returned function clears the flag and releases the lock
+func lockServiceForRestart(serviceName string) func() {
+	lock := getServiceLock(serviceName)
+	lock.Lock()
+	done := markServiceRestarting(serviceName)
+	return func() {
+		done()
+		lock.Unlock()
+	}
+}
+
+// unavailableWhileRestarting answers 503 with a Retry-After header instead of proxying while the
+// service is restarting, so clients see a retryable error rather than the proxy's 502
+func unavailableWhileRestarting(serviceName string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !isServiceRestarting(serviceName) {
+			next(w, r)
+			return
+		}
+
+		log.Printf("Rejecting %s %s: %s is restarting", r.Method, r.URL.Path, serviceName)
+
+		retryAfter := int(restartRetryAfter.Seconds())
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":       "Service restarting",
+			"message":     "The " + serviceName + " is restarting, retry shortly",
+			"service":     serviceName,
+			"retry_after": retryAfter,
+			"timestamp":   time.Now(),
+		})
+	}
+}
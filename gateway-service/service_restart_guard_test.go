@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestUnavailableWhileRestarting tests that requests get a 503 with Retry-After only while the service is flagged
+func TestUnavailableWhileRestarting(t *testing.T) {
+	testCases := map[string]struct {
+		restarting     string
+		expectedStatus int
+		expectForward  bool
+	}{
+		"service running":          {expectedStatus: http.StatusOK, expectForward: true},
+		"service restarting":       {restarting: "orders-service", expectedStatus: http.StatusServiceUnavailable},
+		"other service restarting": {restarting: "inventory-service", expectedStatus: http.StatusOK, expectForward: true},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if tc.restarting != "" {
+				t.Cleanup(markServiceRestarting(tc.restarting))
+			}
+
+			forwarded := false
+			handler := unavailableWhileRestarting("orders-service", func(w http.ResponseWriter, r *http.Request) {
+				forwarded = true
+				w.WriteHeader(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			handler(w, httptest.NewRequest(http.MethodGet, "/api/v1/orders", nil))
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Equal(t, tc.expectForward, forwarded)
+			if tc.expectedStatus == http.StatusServiceUnavailable {
+				assert.Equal(t, "5", w.Header().Get("Retry-After"))
+
+				var body map[string]interface{}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+				assert.Equal(t, "orders-service", body["service"])
+				assert.Equal(t, float64(5), body["retry_after"])
+			} else {
+				assert.Empty(t, w.Header().Get("Retry-After"))
+			}
+		})
+	}
+}
+
+// TestProxyRoutesDuringRestart tests that proxied requests to a service return 503 with Retry-After while the
+// management API restarts it, and are forwarded again once the restart finishes
+func TestProxyRoutesDuringRestart(t *testing.T) {
+	config, received := newStubBackends(t)
+	router := mux.NewRouter()
+	registerProxyRoutes(router.PathPrefix("/api").Subrouter(), config)
+
+	// Hold the restart inside its stop command to simulate the window in which the service is down
+	stopping := make(chan struct{})
+	release := make(chan struct{})
+	var stopOnce sync.Once
+	original := execCommandContext
+	execCommandContext = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		if strings.Contains(strings.Join(args, " "), "stop-") {
+			stopOnce.Do(func() { close(stopping) })
+			<-release
+		}
+		return exec.CommandContext(ctx, "true")
+	}
+	t.Cleanup(func() { execCommandContext = original })
+
+	restartDone := make(chan int)
+	go func() {
+		req := httptest.NewRequest(http.MethodPost, "/api/management/services/orders-service/restart", bytes.NewBufferString(`{"environment":"locally"}`))
+		req = mux.SetURLVars(req, map[string]string{"service": "orders-service"})
+		w := httptest.NewRecorder()
+		serviceRestartHandler(w, req)
+		restartDone <- w.Code
+	}()
+	<-stopping
+
+	proxied := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	during := proxied("/api/v1/orders/123")
+	assert.Equal(t, http.StatusServiceUnavailable, during.Code)
+	assert.Equal(t, "5", during.Header().Get("Retry-After"))
+	assert.Equal(t, http.StatusOK, proxied("/api/v1/inventory/ingredients").Code)
+
+	close(release)
+	require.Equal(t, http.StatusOK, <-restartDone)
+
+	after := proxied("/api/v1/orders/123")
+	assert.Equal(t, http.StatusOK, after.Code)
+	assert.Empty(t, after.Header().Get("Retry-After"))
+
+	var services []string
+	for _, request := range received() {
+		services = append(services, request.service)
+	}
+	assert.Equal(t, []string{"inventory-service", "orders-service"}, services)
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// WatchdogConfig controls the optional health watchdog that auto-restarts failing services
+type WatchdogConfig struct {
+	Enabled          bool
+	Interval         time.Duration
+	FailureThreshold int
+	Environment      string
+}
+
+// loadWatchdogConfig reads the watchdog settings from the environment; the watchdog is off by default
+func loadWatchdogConfig() WatchdogConfig {
+	return WatchdogConfig{
+		Enabled:          getEnvBool("GATEWAY_WATCHDOG_ENABLED", false),
+		Interval:         getEnvDuration("GATEWAY_WATCHDOG_INTERVAL", 30*time.Second),
+		FailureThreshold: getEnvInt("GATEWAY_WATCHDOG_FAILURE_THRESHOLD", 3),
+		Environment:      getEnv("GATEWAY_WATCHDOG_ENVIRONMENT", "locally"),
+	}
+}
+
+// ServiceWatchdog polls service health endpoints and restarts services that fail consecutive checks
+type ServiceWatchdog struct {
+	config      WatchdogConfig
+	healthURLs  map[string]string
+	failures    map[string]int
+	mu          sync.Mutex
+	stop        chan struct{}
+	stopOnce    sync.Once
+	checkHealth func(serviceName, healthURL string) bool
+	restart     func(serviceName, environment string) serviceRestartResult
+}
+
+// NewServiceWatchdog creates a watchdog using the same health check and restart logic as the management endpoints
+func NewServiceWatchdog(config WatchdogConfig, healthURLs map[string]string) *ServiceWatchdog {
+	if config.FailureThreshold < 1 {
+		config.FailureThreshold = 1
+	}
+	if config.Interval <= 0 {
+		config.Interval = 30 * time.Second
+	}
+	if config.Environment == "" {
+		config.Environment = "locally"
+	}
+
+	return &ServiceWatchdog{
+		config:      config,
+		healthURLs:  healthURLs,
+		failures:    make(map[string]int),
+		stop:        make(chan struct{}),
+		checkHealth: checkManagedServiceHealth,
+		restart:     restartService,
+	}
+}
+
+// Start runs health checks on every interval until Stop is called
+func (sw *ServiceWatchdog) Start() {
+	ticker := time.NewTicker(sw.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			sw.checkAll()
+		case <-sw.stop:
+			return
+		}
+	}
+}
+
+// Stop terminates the watchdog loop
+func (sw *ServiceWatchdog) Stop() {
+	sw.stopOnce.Do(func() {
+		close(sw.stop)
+	})
+}
+
+// checkAll runs a single health check round across every watched service
+func (sw *ServiceWatchdog) checkAll() {
+	for serviceName, healthURL := range sw.healthURLs {
+		sw.checkService(serviceName, healthURL)
+	}
+}
+
+// checkService records the health result for a service and restarts it once the failure threshold is reached.
+// It returns true when a restart was triggered.
+func (sw *ServiceWatchdog) checkService(serviceName, healthURL string) bool {
+	if sw.checkHealth(serviceName, healthURL) {
+		sw.resetFailures(serviceName)
+		return false
+	}
+
+	failures := sw.recordFailure(serviceName)
+	log.Printf("⚠️  Watchdog: %s failed health check (%d/%d)", serviceName, failures, sw.config.FailureThreshold)
+
+	if failures < sw.config.FailureThreshold {
+		return false
+	}
+
+	// Respect the per-service management lock: skip this round if an operator is already acting on the service
+	lock := getServiceLock(serviceName)
+	if !lock.TryLock() {
+		log.Printf("ℹ️  Watchdog: %s has a management operation in progress, skipping restart", serviceName)
+		return false
+	}
+	defer lock.Unlock()
+	defer markServiceRestarting(serviceName)()
+
+	log.Printf("🔄 Watchdog: restarting %s after %d consecutive failed health checks", serviceName, failures)
+	result := sw.restart(serviceName, sw.config.Environment)
+	sw.resetFailures(serviceName)
+
+	if !result.Success {
+		log.Printf("❌ Watchdog: failed to restart %s (stop error: %v, start error: %v)", serviceName, result.StopErr, result.StartErr)
+	} else {
+		log.Printf("✅ Watchdog: restarted %s", serviceName)
+	}
+
+	return true
+}
+
+func (sw *ServiceWatchdog) recordFailure(serviceName string) int {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+
+	sw.failures[serviceName]++
+	return sw.failures[serviceName]
+}
+
+func (sw *ServiceWatchdog) resetFailures(serviceName string) {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+
+	delete(sw.failures, serviceName)
+}
+
+// failureCount returns the current consecutive failure count for a service
+func (sw *ServiceWatchdog) failureCount(serviceName string) int {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+
+	return sw.failures[serviceName]
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newRecoveringBackend starts a stub backend that reports unhealthy until it is "restarted"
+func newRecoveringBackend(t *testing.T) (*httptest.Server, *atomic.Bool) {
+	t.Helper()
+
+	healthy := &atomic.Bool{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if healthy.Load() {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, healthy
+}
+
+// TestLoadWatchdogConfig tests that the watchdog is disabled by default and configurable via environment
+func TestLoadWatchdogConfig(t *testing.T) {
+	testCases := map[string]struct {
+		envVars  map[string]string
+		expected WatchdogConfig
+	}{
+		"defaults": {
+			envVars: map[string]string{},
+			expected: WatchdogConfig{
+				Enabled:          false,
+				Interval:         30 * time.Second,
+				FailureThreshold: 3,
+				Environment:      "locally",
+			},
+		},
+		"custom values": {
+			envVars: map[string]string{
+				"GATEWAY_WATCHDOG_ENABLED":           "true",
+				"GATEWAY_WATCHDOG_INTERVAL":          "5s",
+				"GATEWAY_WATCHDOG_FAILURE_THRESHOLD": "5",
+				"GATEWAY_WATCHDOG_ENVIRONMENT":       "docker",
+			},
+			expected: WatchdogConfig{
+				Enabled:          true,
+				Interval:         5 * time.Second,
+				FailureThreshold: 5,
+				Environment:      "docker",
+			},
+		},
+		"invalid values fall back to defaults": {
+			envVars: map[string]string{
+				"GATEWAY_WATCHDOG_ENABLED":           "maybe",
+				"GATEWAY_WATCHDOG_INTERVAL":          "soon",
+				"GATEWAY_WATCHDOG_FAILURE_THRESHOLD": "many",
+			},
+			expected: WatchdogConfig{
+				Enabled:          false,
+				Interval:         30 * time.Second,
+				FailureThreshold: 3,
+				Environment:      "locally",
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			for key, value := range tc.envVars {
+				os.Setenv(key, value)
+				defer os.Unsetenv(key)
+			}
+
+			assert.Equal(t, tc.expected, loadWatchdogConfig())
+		})
+	}
+}
+
+// TestServiceWatchdogRestartsAfterThreshold tests that a restart fires only after N consecutive failures
+func TestServiceWatchdogRestartsAfterThreshold(t *testing.T) {
+	backend, healthy := newRecoveringBackend(t)
+
+	var restarts atomic.Int32
+	watchdog := NewServiceWatchdog(WatchdogConfig{
+		Enabled:          true,
+		Interval:         time.Second,
+		FailureThreshold: 3,
+	}, map[string]string{"orders-service": backend.URL})
+	watchdog.restart = func(serviceName, environment string) serviceRestartResult {
+		assert.Equal(t, "orders-service", serviceName)
+		assert.Equal(t, "locally", environment)
+		restarts.Add(1)
+		healthy.Store(true) // simulated restart brings the backend back
+		return serviceRestartResult{Success: true}
+	}
+
+	assert.False(t, watchdog.checkService("orders-service", backend.URL))
+	assert.False(t, watchdog.checkService("orders-service", backend.URL))
+	assert.Equal(t, 2, watchdog.failureCount("orders-service"))
+	assert.Equal(t, int32(0), restarts.Load())
+
+	assert.True(t, watchdog.checkService("orders-service", backend.URL))
+	assert.Equal(t, int32(1), restarts.Load())
+	assert.Equal(t, 0, watchdog.failureCount("orders-service"))
+
+	// Backend recovered, so further checks must not restart again
+	assert.False(t, watchdog.checkService("orders-service", backend.URL))
+	assert.Equal(t, int32(1), restarts.Load())
+}
+
+// TestServiceWatchdogResetsOnRecovery tests that a healthy check resets the consecutive failure count
+func TestServiceWatchdogResetsOnRecovery(t *testing.T) {
+	backend, healthy := newRecoveringBackend(t)
+
+	watchdog := NewServiceWatchdog(WatchdogConfig{FailureThreshold: 2}, map[string]string{"inventory-service": backend.URL})
+	watchdog.restart = func(serviceName, environment string) serviceRestartResult {
+		t.Fatalf("restart should not be triggered for %s", serviceName)
+		return serviceRestartResult{}
+	}
+
+	assert.False(t, watchdog.checkService("inventory-service", backend.URL))
+	assert.Equal(t, 1, watchdog.failureCount("inventory-service"))
+
+	healthy.Store(true)
+	assert.False(t, watchdog.checkService("inventory-service", backend.URL))
+	assert.Equal(t, 0, watchdog.failureCount("inventory-service"))
+
+	healthy.Store(false)
+	assert.False(t, watchdog.checkService("inventory-service", backend.URL))
+	assert.Equal(t, 1, watchdog.failureCount("inventory-service"))
+}
+
+// TestServiceWatchdogRespectsServiceLock tests that the watchdog skips a restart while a management operation holds the lock
+func TestServiceWatchdogRespectsServiceLock(t *testing.T) {
+	backend, _ := newRecoveringBackend(t)
+
+	var restarts atomic.Int32
+	watchdog := NewServiceWatchdog(WatchdogConfig{FailureThreshold: 1}, map[string]string{"invoice-service": backend.URL})
+	watchdog.restart = func(serviceName, environment string) serviceRestartResult {
+		restarts.Add(1)
+		return serviceRestartResult{Success: true}
+	}
+
+	lock := getServiceLock("invoice-service")
+	lock.Lock()
+	assert.False(t, watchdog.checkService("invoice-service", backend.URL))
+	lock.Unlock()
+	assert.Equal(t, int32(0), restarts.Load())
+
+	assert.True(t, watchdog.checkService("invoice-service", backend.URL))
+	assert.Equal(t, int32(1), restarts.Load())
+}
+
+// TestServiceWatchdogStartStop tests that the polling loop triggers restarts and stops cleanly
+func TestServiceWatchdogStartStop(t *testing.T) {
+	backend, healthy := newRecoveringBackend(t)
+
+	restarted := make(chan struct{}, 1)
+	watchdog := NewServiceWatchdog(WatchdogConfig{
+		Interval:         10 * time.Millisecond,
+		FailureThreshold: 2,
+	}, map[string]string{"session-service": backend.URL})
+	watchdog.restart = func(serviceName, environment string) serviceRestartResult {
+		healthy.Store(true)
+		restarted <- struct{}{}
+		return serviceRestartResult{Success: true}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		watchdog.Start()
+		close(done)
+	}()
+
+	select {
+	case <-restarted:
+	case <-time.After(2 * time.Second):
+		t.Fatal("watchdog did not restart the unhealthy service")
+	}
+
+	watchdog.Stop()
+	watchdog.Stop() // Stop must be idempotent
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("watchdog did not stop")
+	}
+}
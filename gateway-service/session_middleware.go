@@ -21,9 +21,17 @@ func NewSessionMiddleware(sessionManager *SessionManager) *SessionMiddleware {
 	}
 }
 
+// userContextHeaders are the identity headers backend services trust; only the gateway may set them
+var userContextHeaders = []string{"X-User-ID", "X-Username", "X-User-Role", "X-User-Permissions"}
+
 // ValidateSession middleware validates the JWT token against the session service
 func (sm *SessionMiddleware) ValidateSession(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Drop client-supplied identity headers so only the validated session reaches the backend
+		for _, header := range userContextHeaders {
+			r.Header.Del(header)
+		}
+
 		// Extract token from Authorization header
 		token := extractTokenFromHeader(r)
 		if token == "" {
@@ -68,6 +76,24 @@ func (sm *SessionMiddleware) ValidateSession(next http.Handler) http.Handler {
 	})
 }
 
+// adminRoles are the session roles allowed on the gateway's own administrative endpoints
+var adminRoles = []string{"super_admin", "admin"}
+
+// RequireAdmin middleware answers 403 unless the validated session has an admin role; it must
+// run after ValidateSession, which sets X-User-Role from the session
+func (sm *SessionMiddleware) RequireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		role := r.Header.Get("X-User-Role")
+		for _, admin := range adminRoles {
+			if role == admin {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+		sm.writeErrorResponse(w, http.StatusForbidden, "forbidden", "Administrator role is required")
+	})
+}
+
 // SessionAwareLoginHandler handles login and creates sessions
 func (sm *SessionMiddleware) SessionAwareLoginHandler(sessionServiceURL string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -89,7 +115,7 @@ func (sm *SessionMiddleware) SessionAwareLoginHandler(sessionServiceURL string)
 		req.Header.Set("Content-Type", "application/json")
 		req.Header.Set("X-Gateway-Service", "ice-cream-gateway")
 		req.Header.Set("X-Gateway-Session-Managed", "true")
-		req.Header.Set("X-Forwarded-For", r.RemoteAddr)
+		req.Header.Set("X-Forwarded-For", trustedProxies.ClientIP(r))
 
 		client := &http.Client{}
 		resp, err := client.Do(req)
@@ -150,7 +176,7 @@ func (sm *SessionMiddleware) SessionAwareLogoutHandler(sessionServiceURL string)
 		req.Header.Set("Content-Type", "application/json")
 		req.Header.Set("X-Gateway-Service", "ice-cream-gateway")
 		req.Header.Set("X-Gateway-Session-Managed", "true")
-		req.Header.Set("X-Forwarded-For", r.RemoteAddr)
+		req.Header.Set("X-Forwarded-For", trustedProxies.ClientIP(r))
 
 		client := &http.Client{}
 		resp, err := client.Do(req)
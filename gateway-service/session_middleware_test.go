@@ -1,12 +1,14 @@
 package main
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestExtractTokenFromHeaderSimple tests token extraction function directly
@@ -130,6 +132,80 @@ func TestSessionMiddlewareBasicFunctionality(t *testing.T) {
 	})
 }
 
+// TestSessionMiddlewareReplacesClientUserHeaders tests that spoofed identity headers never reach the backend
+func TestSessionMiddlewareReplacesClientUserHeaders(t *testing.T) {
+	sessionService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(SessionValidationResponse{
+			IsValid: true,
+			Session: &SessionData{UserID: "7", Username: "clerk", RoleName: "cashier"},
+		})
+	}))
+	defer sessionService.Close()
+
+	var forwarded http.Header
+	protectedHandler := NewSessionMiddleware(NewSessionManager(sessionService.URL)).ValidateSession(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			forwarded = r.Header.Clone()
+			w.WriteHeader(http.StatusOK)
+		}))
+
+	req := httptest.NewRequest("GET", "/api/v1/orders/config", nil)
+	req.Header.Set("Authorization", "Bearer valid-token")
+	req.Header.Set("X-User-Role", "super_admin")
+	req.Header.Set("X-User-Permissions", "admin-write")
+	w := httptest.NewRecorder()
+
+	protectedHandler.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "cashier", forwarded.Get("X-User-Role"))
+	assert.Equal(t, "7", forwarded.Get("X-User-ID"))
+	assert.Empty(t, forwarded.Values("X-User-Permissions"))
+}
+
+// TestRequireAdmin tests that only admin roles set by the session reach administrative handlers
+func TestRequireAdmin(t *testing.T) {
+	testCases := map[string]struct {
+		role           string
+		expectedStatus int
+	}{
+		"super admin":  {role: "super_admin", expectedStatus: http.StatusOK},
+		"admin":        {role: "admin", expectedStatus: http.StatusOK},
+		"cashier":      {role: "cashier", expectedStatus: http.StatusForbidden},
+		"missing role": {role: "", expectedStatus: http.StatusForbidden},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			sessionService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(SessionValidationResponse{
+					IsValid: true,
+					Session: &SessionData{UserID: "7", Username: "clerk", RoleName: tc.role},
+				})
+			}))
+			defer sessionService.Close()
+
+			middleware := NewSessionMiddleware(NewSessionManager(sessionService.URL))
+			handler := middleware.ValidateSession(middleware.RequireAdmin(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusOK)
+				})))
+
+			req := httptest.NewRequest("PUT", "/api/management/read-only", nil)
+			req.Header.Set("Authorization", "Bearer valid-token")
+			// A client-supplied role must not grant access
+			req.Header.Set("X-User-Role", "super_admin")
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+		})
+	}
+}
+
 // Test edge cases with various header formats
 func TestSessionMiddlewareEdgeCasesSimple(t *testing.T) {
 	sessionManager := NewSessionManager("http://localhost:8081")
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// TrustedProxies lists the networks whose X-Forwarded-For entries the gateway believes. Anyone can send
+// an X-Forwarded-For header, so only hops added by proxies we operate identify the real client.
+type TrustedProxies []netip.Prefix
+
+// ParseTrustedProxies parses a comma-separated list of CIDRs or bare IP addresses; an empty list trusts no proxy
+func ParseTrustedProxies(list string) (TrustedProxies, error) {
+	var proxies TrustedProxies
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if !strings.Contains(entry, "/") {
+			addr, err := netip.ParseAddr(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+			}
+			proxies = append(proxies, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+		}
+		proxies = append(proxies, prefix.Masked())
+	}
+	return proxies, nil
+}
+
+// Trusts reports whether addr belongs to one of the trusted proxy networks
+func (t TrustedProxies) Trusts(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range t {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP returns the address of the client that sent r. Starting from the connection's peer it walks
+// X-Forwarded-For from right to left while the hop that reported the next entry is a trusted proxy, and
+// stops at the first untrusted hop. Entries left of that point were supplied by the client and are ignored.
+func (t TrustedProxies) ClientIP(r *http.Request) string {
+	peer := remoteHost(r.RemoteAddr)
+	client, err := netip.ParseAddr(peer)
+	if err != nil || !t.Trusts(client) {
+		return peer
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			// A malformed entry can't be traced any further; the last trusted hop is the best we know
+			break
+		}
+		client = hop.Unmap()
+		if !t.Trusts(client) {
+			break
+		}
+	}
+	return client.String()
+}
+
+// remoteHost strips the port from a request's RemoteAddr
+func remoteHost(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return remoteAddr
+	}
+	return host
+}
+
+// trustedProxies is consulted wherever the gateway forwards the client's address to a backend
+var trustedProxies TrustedProxies
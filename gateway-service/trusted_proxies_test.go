@@ -0,0 +1,150 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseTrustedProxies tests parsing CIDRs and bare addresses and rejecting malformed entries
+func TestParseTrustedProxies(t *testing.T) {
+	testCases := map[string]struct {
+		list          string
+		expected      []string
+		expectedError bool
+	}{
+		"empty list":         {list: "", expected: nil},
+		"cidrs and ips":      {list: "10.0.0.0/8, 192.168.1.7 ,::1", expected: []string{"10.0.0.0/8", "192.168.1.7/32", "::1/128"}},
+		"host bits masked":   {list: "172.16.5.4/12", expected: []string{"172.16.0.0/12"}},
+		"invalid address":    {list: "10.0.0.0/8,proxy.local", expectedError: true},
+		"invalid prefix len": {list: "10.0.0.0/33", expectedError: true},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			proxies, err := ParseTrustedProxies(tc.list)
+
+			if tc.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			var prefixes []string
+			for _, prefix := range proxies {
+				prefixes = append(prefixes, prefix.String())
+			}
+			assert.Equal(t, tc.expected, prefixes)
+		})
+	}
+}
+
+// TestTrustedProxiesClientIP tests that X-Forwarded-For is only walked through trusted hops
+func TestTrustedProxiesClientIP(t *testing.T) {
+	proxies, err := ParseTrustedProxies("10.0.0.0/8,127.0.0.1")
+	require.NoError(t, err)
+
+	testCases := map[string]struct {
+		proxies    TrustedProxies
+		remoteAddr string
+		xff        []string
+		expected   string
+	}{
+		"direct client without header": {
+			proxies: proxies, remoteAddr: "203.0.113.9:51234",
+			expected: "203.0.113.9",
+		},
+		"spoofed header from untrusted source is ignored": {
+			proxies: proxies, remoteAddr: "203.0.113.9:51234", xff: []string{"1.2.3.4"},
+			expected: "203.0.113.9",
+		},
+		"legitimate chain through trusted proxies is honored": {
+			proxies: proxies, remoteAddr: "10.0.0.2:443", xff: []string{"198.51.100.20, 10.0.0.1"},
+			expected: "198.51.100.20",
+		},
+		"spoofed entries left of the real client are ignored": {
+			proxies: proxies, remoteAddr: "10.0.0.2:443", xff: []string{"1.2.3.4, 198.51.100.20, 10.0.0.1"},
+			expected: "198.51.100.20",
+		},
+		"trusted proxy without header": {
+			proxies: proxies, remoteAddr: "127.0.0.1:40000",
+			expected: "127.0.0.1",
+		},
+		"repeated headers are read as one chain": {
+			proxies: proxies, remoteAddr: "127.0.0.1:40000", xff: []string{"198.51.100.20", "10.0.0.1"},
+			expected: "198.51.100.20",
+		},
+		"malformed entry stops at the last trusted hop": {
+			proxies: proxies, remoteAddr: "127.0.0.1:40000", xff: []string{"unknown, 10.0.0.1"},
+			expected: "10.0.0.1",
+		},
+		"no trusted proxies configured": {
+			remoteAddr: "10.0.0.2:443", xff: []string{"198.51.100.20"},
+			expected: "10.0.0.2",
+		},
+		"ipv6 peer": {
+			proxies: proxies, remoteAddr: "[2001:db8::1]:8080", xff: []string{"1.2.3.4"},
+			expected: "2001:db8::1",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/orders", nil)
+			req.RemoteAddr = tc.remoteAddr
+			for _, value := range tc.xff {
+				req.Header.Add("X-Forwarded-For", value)
+			}
+
+			assert.Equal(t, tc.expected, tc.proxies.ClientIP(req))
+		})
+	}
+}
+
+// TestProxyForwardsClientIP tests that backends receive the resolved client address rather than a spoofed one
+func TestProxyForwardsClientIP(t *testing.T) {
+	proxies, err := ParseTrustedProxies("10.0.0.0/8")
+	require.NoError(t, err)
+	original := trustedProxies
+	trustedProxies = proxies
+	t.Cleanup(func() { trustedProxies = original })
+
+	var forwardedFor string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forwardedFor = r.Header.Get("X-Forwarded-For")
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(backend.Close)
+
+	router := mux.NewRouter()
+	registerProxyRoutes(router.PathPrefix("/api").Subrouter(), Config{OrdersServiceURL: backend.URL})
+
+	testCases := map[string]struct {
+		remoteAddr     string
+		xff            string
+		expectedClient string
+	}{
+		"spoofed": {remoteAddr: "203.0.113.9:51234", xff: "1.2.3.4", expectedClient: "203.0.113.9"},
+		"trusted": {remoteAddr: "10.0.0.2:443", xff: "198.51.100.20", expectedClient: "198.51.100.20"},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/orders/p/health", nil)
+			req.RemoteAddr = tc.remoteAddr
+			req.Header.Set("X-Forwarded-For", tc.xff)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			// The reverse proxy appends the address of the peer it received the request from
+			assert.Regexp(t, "^"+tc.expectedClient+"(, |$)", forwardedFor)
+			assert.NotContains(t, forwardedFor, "1.2.3.4")
+		})
+	}
+}
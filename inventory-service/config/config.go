@@ -4,6 +4,8 @@ import (
 	"os"
 	"strconv"
 	"time"
+
+	"shared/configcheck"
 )
 
 // Config holds the configuration for the inventory service
@@ -17,6 +19,22 @@ type Config struct {
 	DBName     string
 	DBSSLMode  string
 	LogLevel   string
+
+	// RequestTimeout bounds how long a request handler may run before the client gets a 503 (0 disables)
+	RequestTimeout time.Duration
+
+	// RequestLogSampleRate logs one in every N successful (2xx) requests; other responses are always logged
+	RequestLogSampleRate int
+
+	// PriceRounding names the strategy used to round the final price of repriced existences
+	PriceRounding string
+
+	// MaxPageSize caps the limit a list request may ask for; larger limits are reduced (0 disables the cap)
+	MaxPageSize int
+
+	// HealthCheckDataService makes the health check depend on the data-service at DataServiceHealthURL
+	HealthCheckDataService bool
+	DataServiceHealthURL   string
 }
 
 // LoadConfig loads configuration from environment variables with defaults
@@ -31,9 +49,57 @@ func LoadConfig() *Config {
 		DBName:     getEnvString("DB_NAME", "icecream_store"),
 		DBSSLMode:  getEnvString("DB_SSLMODE", "disable"),
 		LogLevel:   getEnvString("LOG_LEVEL", "info"),
+
+		RequestTimeout: getEnvDuration("REQUEST_TIMEOUT", 10*time.Second),
+
+		RequestLogSampleRate: getEnvInt("REQUEST_LOG_SAMPLE_RATE", 1), // log every request
+
+		PriceRounding: getEnvString("PRICE_ROUNDING_STRATEGY", "ceil-to-100"),
+
+		MaxPageSize: getEnvInt("MAX_PAGE_SIZE", 100),
+
+		HealthCheckDataService: getEnvBool("HEALTH_CHECK_DATA_SERVICE", true),
+		DataServiceHealthURL:   getEnvString("DATA_SERVICE_HEALTH_URL", "http://localhost:8086/health"),
 	}
 }
 
+// Load reads the configuration like LoadConfig and validates it. The Config is returned
+// even when validation fails so the caller can set up logging before exiting
+func Load() (*Config, error) {
+	cfg := LoadConfig()
+	return cfg, cfg.Validate()
+}
+
+// Validate reports every invalid setting, including environment values LoadConfig
+// could not parse and replaced with defaults
+func (c *Config) Validate() error {
+	v := &configcheck.Validator{}
+
+	v.Port("INVENTORY_SERVER_PORT", c.ServerPort)
+	v.Port("DB_PORT", c.DBPort)
+	v.Required("DB_HOST", c.DBHost)
+	v.Required("DB_USER", c.DBUser)
+	v.Required("DB_PASSWORD", c.DBPassword)
+	v.Required("DB_NAME", c.DBName)
+	v.OneOf("DB_SSLMODE", c.DBSSLMode, configcheck.SSLModes...)
+
+	v.EnvDuration("REQUEST_TIMEOUT")
+	v.NonNegative("REQUEST_TIMEOUT", c.RequestTimeout.Seconds())
+	v.EnvInt("REQUEST_LOG_SAMPLE_RATE")
+	if c.RequestLogSampleRate < 1 {
+		v.Addf("REQUEST_LOG_SAMPLE_RATE must be at least 1, got %d", c.RequestLogSampleRate)
+	}
+	v.EnvInt("MAX_PAGE_SIZE")
+	v.NonNegative("MAX_PAGE_SIZE", float64(c.MaxPageSize))
+
+	v.EnvBool("HEALTH_CHECK_DATA_SERVICE")
+	if c.HealthCheckDataService {
+		v.Required("DATA_SERVICE_HEALTH_URL", c.DataServiceHealthURL)
+	}
+
+	return v.Err()
+}
+
 // getEnvString returns the environment variable value or default if not set
 func getEnvString(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -61,3 +127,13 @@ func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
 	}
 	return defaultValue
 }
+
+// getEnvBool returns the environment variable value as bool or default if not set
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
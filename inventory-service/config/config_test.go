@@ -5,6 +5,8 @@ import (
 	"testing"
 	"time"
 
+	"shared/configcheck"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -27,6 +29,12 @@ func TestLoadConfig(t *testing.T) {
 
 	// Logging
 	assert.Equal(t, "info", config.LogLevel)
+	assert.Equal(t, 10*time.Second, config.RequestTimeout)
+	assert.Equal(t, 1, config.RequestLogSampleRate)
+	assert.Equal(t, 100, config.MaxPageSize)
+	assert.Equal(t, "ceil-to-100", config.PriceRounding)
+	assert.True(t, config.HealthCheckDataService)
+	assert.Equal(t, "http://localhost:8086/health", config.DataServiceHealthURL)
 }
 
 // TestLoadConfigWithEnvironmentVariables tests configuration loading with environment variables
@@ -42,6 +50,9 @@ func TestLoadConfigWithEnvironmentVariables(t *testing.T) {
 		"DB_NAME":               "testdb",
 		"DB_SSLMODE":            "require",
 		"LOG_LEVEL":             "debug",
+
+		"HEALTH_CHECK_DATA_SERVICE": "false",
+		"DATA_SERVICE_HEALTH_URL":   "http://data-service:8086/health",
 	}
 
 	// Set environment variables and defer cleanup
@@ -62,6 +73,8 @@ func TestLoadConfigWithEnvironmentVariables(t *testing.T) {
 	assert.Equal(t, "testdb", config.DBName)
 	assert.Equal(t, "require", config.DBSSLMode)
 	assert.Equal(t, "debug", config.LogLevel)
+	assert.False(t, config.HealthCheckDataService)
+	assert.Equal(t, "http://data-service:8086/health", config.DataServiceHealthURL)
 }
 
 // TestGetEnvString tests the getEnvString helper function
@@ -383,3 +396,55 @@ func BenchmarkGetEnvString(b *testing.B) {
 		getEnvString("BENCH_TEST_VAR", "default")
 	}
 }
+
+// TestValidate tests that defaults pass and that invalid or missing settings are all reported together
+func TestValidate(t *testing.T) {
+	t.Run("defaults are valid", func(t *testing.T) {
+		assert.NoError(t, LoadConfig().Validate())
+	})
+
+	t.Run("invalid values are aggregated", func(t *testing.T) {
+		t.Setenv("INVENTORY_SERVER_PORT", "70000")
+		t.Setenv("DB_PORT", "postgres")
+		t.Setenv("DB_SSLMODE", "sometimes")
+
+		_, err := Load()
+
+		var validationErr *configcheck.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, []string{
+			`INVENTORY_SERVER_PORT must be a port between 1 and 65535, got "70000"`,
+			`DB_PORT must be a port between 1 and 65535, got "postgres"`,
+			`DB_SSLMODE must be one of disable, allow, prefer, require, verify-ca, verify-full, got "sometimes"`,
+		}, validationErr.Problems)
+	})
+
+	t.Run("sample rate below one", func(t *testing.T) {
+		t.Setenv("REQUEST_LOG_SAMPLE_RATE", "0")
+
+		_, err := Load()
+
+		var validationErr *configcheck.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, []string{"REQUEST_LOG_SAMPLE_RATE must be at least 1, got 0"}, validationErr.Problems)
+	})
+
+	t.Run("negative max page size", func(t *testing.T) {
+		t.Setenv("MAX_PAGE_SIZE", "-1")
+
+		_, err := Load()
+
+		var validationErr *configcheck.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, []string{"MAX_PAGE_SIZE must not be negative, got -1"}, validationErr.Problems)
+	})
+
+	t.Run("missing required secret", func(t *testing.T) {
+		cfg := LoadConfig()
+		cfg.DBPassword = ""
+
+		var validationErr *configcheck.ValidationError
+		require.ErrorAs(t, cfg.Validate(), &validationErr)
+		assert.Equal(t, []string{"DB_PASSWORD is required"}, validationErr.Problems)
+	})
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSanitized tests that secrets are redacted and every other setting is reported as loaded
+func TestSanitized(t *testing.T) {
+	cfg := LoadConfig()
+	cfg.DBPassword = "db-password-value"
+
+	settings := cfg.Sanitized()
+
+	assert.Equal(t, RedactedValue, settings["DB_PASSWORD"])
+	assert.Equal(t, "localhost", settings["DB_HOST"])
+	assert.Equal(t, "8084", settings["INVENTORY_SERVER_PORT"])
+	assert.Equal(t, "10s", settings["REQUEST_TIMEOUT"])
+	assert.Equal(t, true, settings["HEALTH_CHECK_DATA_SERVICE"])
+
+	encoded, err := json.Marshal(settings)
+	require.NoError(t, err)
+	assert.NotContains(t, string(encoded), "db-password-value")
+}
+
+// TestSanitizedUnsetSecret tests that an unset secret is reported empty rather than redacted
+func TestSanitizedUnsetSecret(t *testing.T) {
+	cfg := LoadConfig()
+	cfg.DBPassword = ""
+
+	assert.Equal(t, "", cfg.Sanitized()["DB_PASSWORD"])
+}
@@ -1,28 +1,40 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
+	"time"
 
 	"inventory-service/entities/existences/models"
 	existenceSQL "inventory-service/entities/existences/sql"
+	unitConversionHandlers "inventory-service/entities/unit_conversions/handlers"
+	"shared/pricing"
 
 	"github.com/sirupsen/logrus"
 )
 
 // DBHandler handles database operations for existences
 type DBHandler struct {
-	db     *sql.DB
-	logger *logrus.Logger
+	db         *sql.DB
+	logger     *logrus.Logger
+	roundPrice pricing.RoundingStrategy
 }
 
 // NewDBHandler creates a new database handler for existences
 func NewDBHandler(db *sql.DB, logger *logrus.Logger) *DBHandler {
 	return &DBHandler{
-		db:     db,
-		logger: logger,
+		db:         db,
+		logger:     logger,
+		roundPrice: pricing.CeilTo100,
 	}
 }
 
+// SetRoundingStrategy replaces the strategy used to round the final price of repriced existences
+func (h *DBHandler) SetRoundingStrategy(strategy pricing.RoundingStrategy) {
+	h.roundPrice = strategy
+}
+
 // CreateExistence creates a new existence in the database
 func (h *DBHandler) CreateExistence(req models.CreateExistenceRequest) (*models.Existence, error) {
 	var existence models.Existence
@@ -66,6 +78,65 @@ func (h *DBHandler) CreateExistence(req models.CreateExistenceRequest) (*models.
 	return &existence, nil
 }
 
+// CreateExistences creates a batch of existences in a single transaction, rolling back all of them if any insert fails
+func (h *DBHandler) CreateExistences(reqs models.BulkCreateExistencesRequest) ([]models.Existence, error) {
+	tx, err := h.db.Begin()
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to begin bulk existence transaction")
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	existences := make([]models.Existence, 0, len(reqs))
+	for i, req := range reqs {
+		var existence models.Existence
+
+		err := tx.QueryRow(existenceSQL.CreateExistenceQuery,
+			req.IngredientID,
+			req.InvoiceDetailID,
+			req.UnitsPurchased,
+			req.UnitsAvailable,
+			req.UnitType,
+			req.ItemsPerUnit,
+			req.CostPerUnit,
+			req.ExpirationDate,
+			req.IncomeMarginPercentage,
+			req.IvaPercentage,
+			req.ServiceTaxPercentage,
+			req.FinalPrice).
+			Scan(&existence.ID, &existence.ExistenceReferenceCode, &existence.IngredientID,
+				&existence.InvoiceDetailID, &existence.UnitsPurchased, &existence.UnitsAvailable,
+				&existence.UnitType, &existence.ItemsPerUnit, &existence.CostPerItem,
+				&existence.CostPerUnit, &existence.TotalPurchaseCost, &existence.RemainingValue,
+				&existence.ExpirationDate, &existence.IncomeMarginPercentage, &existence.IncomeMarginAmount,
+				&existence.IvaPercentage, &existence.IvaAmount, &existence.ServiceTaxPercentage,
+				&existence.ServiceTaxAmount, &existence.CalculatedPrice, &existence.FinalPrice,
+				&existence.CreatedAt, &existence.UpdatedAt)
+
+		if err != nil {
+			h.logger.WithError(err).WithFields(logrus.Fields{
+				"index":             i,
+				"ingredient_id":     req.IngredientID,
+				"invoice_detail_id": req.InvoiceDetailID,
+			}).Error("Failed to create existence in bulk, rolling back")
+			return nil, fmt.Errorf("failed to create existence at index %d: %w", i, err)
+		}
+
+		existences = append(existences, existence)
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.logger.WithError(err).Error("Failed to commit bulk existence transaction")
+		return nil, err
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"count": len(existences),
+	}).Info("Existences created in bulk successfully")
+
+	return existences, nil
+}
+
 // GetExistenceByID retrieves an existence by ID from the database
 func (h *DBHandler) GetExistenceByID(id string) (*models.Existence, error) {
 	var existence models.Existence
@@ -96,9 +167,39 @@ func (h *DBHandler) GetExistenceByID(id string) (*models.Existence, error) {
 	return &existence, nil
 }
 
+// GetExistenceByReferenceCode retrieves an existence by its sequence-assigned reference code
+func (h *DBHandler) GetExistenceByReferenceCode(code int) (*models.Existence, error) {
+	var existence models.Existence
+
+	err := h.db.QueryRow(existenceSQL.GetExistenceByReferenceCodeQuery, code).
+		Scan(&existence.ID, &existence.ExistenceReferenceCode, &existence.IngredientID,
+			&existence.InvoiceDetailID, &existence.UnitsPurchased, &existence.UnitsAvailable,
+			&existence.UnitType, &existence.ItemsPerUnit, &existence.CostPerItem,
+			&existence.CostPerUnit, &existence.TotalPurchaseCost, &existence.RemainingValue,
+			&existence.ExpirationDate, &existence.IncomeMarginPercentage, &existence.IncomeMarginAmount,
+			&existence.IvaPercentage, &existence.IvaAmount, &existence.ServiceTaxPercentage,
+			&existence.ServiceTaxAmount, &existence.CalculatedPrice, &existence.FinalPrice,
+			&existence.CreatedAt, &existence.UpdatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			h.logger.WithFields(logrus.Fields{
+				"reference_code": code,
+			}).Warn("Existence not found")
+			return nil, err
+		}
+		h.logger.WithError(err).WithFields(logrus.Fields{
+			"reference_code": code,
+		}).Error("Failed to get existence by reference code from database")
+		return nil, err
+	}
+
+	return &existence, nil
+}
+
 // ListExistences retrieves all existences from the database with optional filtering
-func (h *DBHandler) ListExistences(req models.ListExistencesRequest) ([]models.Existence, error) {
-	rows, err := h.db.Query(existenceSQL.ListExistencesQuery,
+func (h *DBHandler) ListExistences(ctx context.Context, req models.ListExistencesRequest) ([]models.Existence, error) {
+	rows, err := h.db.QueryContext(ctx, existenceSQL.ListExistencesQuery,
 		req.IngredientID, req.UnitType, req.Expired, req.LowStock, req.Limit, req.Offset)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to list existences from database")
@@ -142,6 +243,71 @@ func (h *DBHandler) ListExistences(req models.ListExistencesRequest) ([]models.E
 	return existences, nil
 }
 
+// CountExistences counts the existences matching the request filters, ignoring limit and offset
+func (h *DBHandler) CountExistences(ctx context.Context, req models.ListExistencesRequest) (int, error) {
+	var total int
+	err := h.db.QueryRowContext(ctx, existenceSQL.CountExistencesQuery,
+		req.IngredientID, req.UnitType, req.Expired, req.LowStock).Scan(&total)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to count existences in database")
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// ListExpiringExistences retrieves existences whose expiration date falls within [from, to], soonest first
+func (h *DBHandler) ListExpiringExistences(ctx context.Context, from, to time.Time) ([]models.Existence, error) {
+	fromDate, toDate := from.Format("2006-01-02"), to.Format("2006-01-02")
+
+	rows, err := h.db.QueryContext(ctx, existenceSQL.ListExpiringExistencesQuery, fromDate, toDate)
+	if err != nil {
+		h.logger.WithError(err).WithFields(logrus.Fields{
+			"from": fromDate,
+			"to":   toDate,
+		}).Error("Failed to list expiring existences from database")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var existences []models.Existence
+	for rows.Next() {
+		var existence models.Existence
+		err := rows.Scan(&existence.ID, &existence.ExistenceReferenceCode, &existence.IngredientID,
+			&existence.InvoiceDetailID, &existence.UnitsPurchased, &existence.UnitsAvailable,
+			&existence.UnitType, &existence.ItemsPerUnit, &existence.CostPerItem,
+			&existence.CostPerUnit, &existence.TotalPurchaseCost, &existence.RemainingValue,
+			&existence.ExpirationDate, &existence.IncomeMarginPercentage, &existence.IncomeMarginAmount,
+			&existence.IvaPercentage, &existence.IvaAmount, &existence.ServiceTaxPercentage,
+			&existence.ServiceTaxAmount, &existence.CalculatedPrice, &existence.FinalPrice,
+			&existence.CreatedAt, &existence.UpdatedAt)
+
+		if err != nil {
+			h.logger.WithError(err).Error("Failed to scan expiring existence row")
+			return nil, err
+		}
+		existences = append(existences, existence)
+	}
+
+	if err = rows.Err(); err != nil {
+		h.logger.WithError(err).Error("Error occurred during rows iteration")
+		return nil, err
+	}
+
+	// Return empty slice instead of nil if nothing is about to expire
+	if existences == nil {
+		existences = []models.Existence{}
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"from":  fromDate,
+		"to":    toDate,
+		"count": len(existences),
+	}).Info("Listed expiring existences successfully")
+
+	return existences, nil
+}
+
 // UpdateExistence updates an existence in the database
 func (h *DBHandler) UpdateExistence(id string, req models.UpdateExistenceRequest) (*models.Existence, error) {
 	var existence models.Existence
@@ -211,3 +377,437 @@ func (h *DBHandler) DeleteExistence(id string) error {
 
 	return nil
 }
+
+// ConsumeExistence removes units from an existence and records the movement in one transaction.
+// Units given in another unit type are converted to the existence's own first. It returns sql.ErrNoRows
+// if the existence does not exist, an error wrapping unit conversion models.ErrUndefinedConversion if the
+// units cannot be converted and models.ErrInsufficientUnits if it does not hold enough units.
+func (h *DBHandler) ConsumeExistence(id string, req models.ConsumeExistenceRequest) (*models.ExistenceMovement, error) {
+	tx, err := h.db.Begin()
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to begin transaction for existence consumption")
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	units := req.Units
+	if req.UnitType != nil {
+		var ingredientID, unitType string
+		err := tx.QueryRow(existenceSQL.GetExistenceForUpdateQuery, id).Scan(&ingredientID, &unitType)
+		if err != nil {
+			if err != sql.ErrNoRows {
+				h.logger.WithError(err).WithField("existence_id", id).Error("Failed to lock existence for consumption")
+			}
+			return nil, err
+		}
+
+		units, err = unitConversionHandlers.NewUnitConversionTxHandler(tx).ConvertUnits(req.Units, *req.UnitType, unitType, ingredientID)
+		if err != nil {
+			h.logger.WithError(err).WithFields(logrus.Fields{
+				"existence_id": id,
+				"from_unit":    *req.UnitType,
+				"to_unit":      unitType,
+			}).Warn("Failed to convert consumed units")
+			return nil, err
+		}
+	}
+
+	var unitsAvailable float64
+	err = tx.QueryRow(existenceSQL.ConsumeExistenceQuery, id, units).Scan(&unitsAvailable)
+	if err == sql.ErrNoRows {
+		// Nothing was updated: tell a missing existence apart from one without enough stock
+		if err := tx.QueryRow(existenceSQL.GetExistenceUnitsAvailableQuery, id).Scan(&unitsAvailable); err != nil {
+			if err != sql.ErrNoRows {
+				h.logger.WithError(err).WithField("existence_id", id).Error("Failed to check existence units available")
+			}
+			return nil, err
+		}
+
+		h.logger.WithFields(logrus.Fields{
+			"existence_id":    id,
+			"units_requested": units,
+			"units_available": unitsAvailable,
+		}).Warn("Rejected consumption exceeding units available")
+		return nil, models.ErrInsufficientUnits
+	}
+	if err != nil {
+		h.logger.WithError(err).WithField("existence_id", id).Error("Failed to consume existence units")
+		return nil, err
+	}
+
+	var movement models.ExistenceMovement
+	err = tx.QueryRow(existenceSQL.CreateExistenceMovementQuery, id, req.MovementType, -units, req.Notes).
+		Scan(&movement.ID, &movement.ExistenceID, &movement.MovementType, &movement.QuantityChange,
+			&movement.Notes, &movement.CreatedAt)
+	if err != nil {
+		h.logger.WithError(err).WithField("existence_id", id).Error("Failed to record existence movement")
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.logger.WithError(err).WithField("existence_id", id).Error("Failed to commit existence consumption")
+		return nil, err
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"existence_id":    id,
+		"movement_type":   movement.MovementType,
+		"units":           units,
+		"units_available": unitsAvailable,
+	}).Info("Existence units consumed successfully")
+
+	return &movement, nil
+}
+
+// ReassignExistence moves an existence to another ingredient and records a reassignment movement in one
+// transaction. It returns sql.ErrNoRows if the existence does not exist, models.ErrIngredientNotFound if the
+// target ingredient does not, and models.ErrIncompatibleUnitType if the target is stocked in units the
+// existence's unit type cannot convert to.
+func (h *DBHandler) ReassignExistence(id string, req models.ReassignExistenceRequest) (*models.Existence, error) {
+	tx, err := h.db.Begin()
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to begin transaction for existence reassignment")
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var sourceIngredientID, unitType string
+	err = tx.QueryRow(existenceSQL.GetExistenceForUpdateQuery, id).Scan(&sourceIngredientID, &unitType)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			h.logger.WithError(err).WithField("existence_id", id).Error("Failed to lock existence for reassignment")
+		}
+		return nil, err
+	}
+	if sourceIngredientID == req.IngredientID {
+		return nil, models.ErrSameIngredient
+	}
+
+	var exists bool
+	if err := tx.QueryRow(existenceSQL.IngredientExistsQuery, req.IngredientID).Scan(&exists); err != nil {
+		h.logger.WithError(err).WithField("ingredient_id", req.IngredientID).Error("Failed to check target ingredient")
+		return nil, err
+	}
+	if !exists {
+		return nil, models.ErrIngredientNotFound
+	}
+
+	compatible, err := h.unitTypeCompatible(tx, unitType, req.IngredientID)
+	if err != nil {
+		return nil, err
+	}
+	if !compatible {
+		h.logger.WithFields(logrus.Fields{
+			"existence_id":  id,
+			"unit_type":     unitType,
+			"ingredient_id": req.IngredientID,
+		}).Warn("Rejected reassignment to an ingredient stocked in incompatible units")
+		return nil, models.ErrIncompatibleUnitType
+	}
+
+	var existence models.Existence
+	err = tx.QueryRow(existenceSQL.ReassignExistenceQuery, id, req.IngredientID).
+		Scan(&existence.ID, &existence.ExistenceReferenceCode, &existence.IngredientID,
+			&existence.InvoiceDetailID, &existence.UnitsPurchased, &existence.UnitsAvailable,
+			&existence.UnitType, &existence.ItemsPerUnit, &existence.CostPerItem,
+			&existence.CostPerUnit, &existence.TotalPurchaseCost, &existence.RemainingValue,
+			&existence.ExpirationDate, &existence.IncomeMarginPercentage, &existence.IncomeMarginAmount,
+			&existence.IvaPercentage, &existence.IvaAmount, &existence.ServiceTaxPercentage,
+			&existence.ServiceTaxAmount, &existence.CalculatedPrice, &existence.FinalPrice,
+			&existence.CreatedAt, &existence.UpdatedAt)
+	if err != nil {
+		h.logger.WithError(err).WithField("existence_id", id).Error("Failed to reassign existence")
+		return nil, err
+	}
+
+	notes := fmt.Sprintf("Reassigned from ingredient %s to %s", sourceIngredientID, req.IngredientID)
+	if req.Notes != nil && *req.Notes != "" {
+		notes += ": " + *req.Notes
+	}
+	if _, err := tx.Exec(existenceSQL.CreateExistenceMovementQuery, id, models.MovementTypeReassignment, 0, notes); err != nil {
+		h.logger.WithError(err).WithField("existence_id", id).Error("Failed to record existence movement")
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.logger.WithError(err).WithField("existence_id", id).Error("Failed to commit existence reassignment")
+		return nil, err
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"existence_id":       id,
+		"from_ingredient_id": sourceIngredientID,
+		"to_ingredient_id":   req.IngredientID,
+	}).Info("Existence reassigned successfully")
+
+	return &existence, nil
+}
+
+// unitTypeCompatible checks unitType against the units the target ingredient is stocked in, loading the
+// ingredient's own conversion factors only when no stocked unit matches through a standard conversion
+func (h *DBHandler) unitTypeCompatible(tx *sql.Tx, unitType, ingredientID string) (bool, error) {
+	rows, err := tx.Query(existenceSQL.ListIngredientUnitTypesQuery, ingredientID)
+	if err != nil {
+		h.logger.WithError(err).WithField("ingredient_id", ingredientID).Error("Failed to list ingredient unit types")
+		return false, err
+	}
+	var stockedUnits []string
+	for rows.Next() {
+		var stocked string
+		if err := rows.Scan(&stocked); err != nil {
+			rows.Close()
+			h.logger.WithError(err).Error("Failed to scan ingredient unit type")
+			return false, err
+		}
+		stockedUnits = append(stockedUnits, stocked)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		h.logger.WithError(err).Error("Error occurred during rows iteration")
+		return false, err
+	}
+
+	if models.UnitTypeCompatible(unitType, stockedUnits, nil) {
+		return true, nil
+	}
+
+	conversions, err := unitConversionHandlers.NewUnitConversionTxHandler(tx).GetIngredientConversions(ingredientID)
+	if err != nil {
+		h.logger.WithError(err).WithField("ingredient_id", ingredientID).Error("Failed to load ingredient unit conversions")
+		return false, err
+	}
+
+	return models.UnitTypeCompatible(unitType, stockedUnits, conversions), nil
+}
+
+// RepriceIngredientExistences applies a new cost per unit to every existence of an ingredient in one
+// transaction, recomputing each existence's pricing. The existences are locked while they are repriced.
+func (h *DBHandler) RepriceIngredientExistences(ingredientID string, costPerUnit float64) ([]models.Existence, error) {
+	tx, err := h.db.Begin()
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to begin transaction for ingredient reprice")
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(existenceSQL.ListIngredientExistencesForUpdateQuery, ingredientID)
+	if err != nil {
+		h.logger.WithError(err).WithField("ingredient_id", ingredientID).Error("Failed to lock ingredient existences")
+		return nil, err
+	}
+
+	var current []models.Existence
+	for rows.Next() {
+		var existence models.Existence
+		err := rows.Scan(&existence.ID, &existence.ExistenceReferenceCode, &existence.IngredientID,
+			&existence.InvoiceDetailID, &existence.UnitsPurchased, &existence.UnitsAvailable,
+			&existence.UnitType, &existence.ItemsPerUnit, &existence.CostPerItem,
+			&existence.CostPerUnit, &existence.TotalPurchaseCost, &existence.RemainingValue,
+			&existence.ExpirationDate, &existence.IncomeMarginPercentage, &existence.IncomeMarginAmount,
+			&existence.IvaPercentage, &existence.IvaAmount, &existence.ServiceTaxPercentage,
+			&existence.ServiceTaxAmount, &existence.CalculatedPrice, &existence.FinalPrice,
+			&existence.CreatedAt, &existence.UpdatedAt)
+
+		if err != nil {
+			rows.Close()
+			h.logger.WithError(err).Error("Failed to scan existence row")
+			return nil, err
+		}
+		current = append(current, existence)
+	}
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		h.logger.WithError(err).Error("Error occurred during rows iteration")
+		return nil, err
+	}
+
+	existences := make([]models.Existence, 0, len(current))
+	for _, existence := range current {
+		existence.Reprice(costPerUnit, h.roundPrice)
+
+		var repriced models.Existence
+		err := tx.QueryRow(existenceSQL.RepriceExistenceQuery, existence.ID, existence.CostPerUnit,
+			existence.IncomeMarginAmount, existence.IvaAmount, existence.ServiceTaxAmount, existence.CalculatedPrice,
+			existence.FinalPrice).
+			Scan(&repriced.ID, &repriced.ExistenceReferenceCode, &repriced.IngredientID,
+				&repriced.InvoiceDetailID, &repriced.UnitsPurchased, &repriced.UnitsAvailable,
+				&repriced.UnitType, &repriced.ItemsPerUnit, &repriced.CostPerItem,
+				&repriced.CostPerUnit, &repriced.TotalPurchaseCost, &repriced.RemainingValue,
+				&repriced.ExpirationDate, &repriced.IncomeMarginPercentage, &repriced.IncomeMarginAmount,
+				&repriced.IvaPercentage, &repriced.IvaAmount, &repriced.ServiceTaxPercentage,
+				&repriced.ServiceTaxAmount, &repriced.CalculatedPrice, &repriced.FinalPrice,
+				&repriced.CreatedAt, &repriced.UpdatedAt)
+
+		if err != nil {
+			h.logger.WithError(err).WithFields(logrus.Fields{
+				"ingredient_id": ingredientID,
+				"existence_id":  existence.ID,
+			}).Error("Failed to reprice existence, rolling back")
+			return nil, fmt.Errorf("failed to reprice existence %s: %w", existence.ID, err)
+		}
+
+		existences = append(existences, repriced)
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.logger.WithError(err).WithField("ingredient_id", ingredientID).Error("Failed to commit ingredient reprice")
+		return nil, err
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"ingredient_id": ingredientID,
+		"cost_per_unit": costPerUnit,
+		"count":         len(existences),
+	}).Info("Ingredient existences repriced successfully")
+
+	return existences, nil
+}
+
+// ListIngredientCostBatches retrieves the units available and cost per item of every existence of an
+// ingredient, newest first. It returns sql.ErrNoRows when the ingredient does not exist.
+func (h *DBHandler) ListIngredientCostBatches(ctx context.Context, ingredientID string) ([]models.IngredientCostBatch, error) {
+	rows, err := h.db.QueryContext(ctx, existenceSQL.ListIngredientCostBatchesQuery, ingredientID)
+	if err != nil {
+		h.logger.WithError(err).WithFields(logrus.Fields{
+			"ingredient_id": ingredientID,
+		}).Error("Failed to list ingredient cost batches from database")
+		return nil, err
+	}
+	defer rows.Close()
+
+	found := false
+	batches := []models.IngredientCostBatch{}
+	for rows.Next() {
+		found = true
+
+		// An ingredient without existences yields a single row of NULLs from the outer join
+		var unitsAvailable, costPerItem sql.NullFloat64
+		if err := rows.Scan(&unitsAvailable, &costPerItem); err != nil {
+			h.logger.WithError(err).Error("Failed to scan ingredient cost batch row")
+			return nil, err
+		}
+		if !unitsAvailable.Valid {
+			continue
+		}
+		batches = append(batches, models.IngredientCostBatch{
+			UnitsAvailable: unitsAvailable.Float64,
+			CostPerItem:    costPerItem.Float64,
+		})
+	}
+
+	if err = rows.Err(); err != nil {
+		h.logger.WithError(err).Error("Error occurred during rows iteration")
+		return nil, err
+	}
+
+	if !found {
+		h.logger.WithField("ingredient_id", ingredientID).Warn("Ingredient not found")
+		return nil, sql.ErrNoRows
+	}
+
+	return batches, nil
+}
+
+// GetExistencesSummary aggregates the dashboard stock KPIs in a single query; existences
+// expiring within [from, to] count as expiring and those expiring before from as expired
+func (h *DBHandler) GetExistencesSummary(ctx context.Context, from, to time.Time) (*models.ExistencesSummary, error) {
+	fromDate, toDate := from.Format("2006-01-02"), to.Format("2006-01-02")
+
+	var summary models.ExistencesSummary
+	err := h.db.QueryRowContext(ctx, existenceSQL.GetExistencesSummaryQuery, fromDate, toDate).
+		Scan(&summary.TotalExistences, &summary.TotalRemainingValue, &summary.ExpiringCount,
+			&summary.ExpiredCount, &summary.LowStockCount)
+	if err != nil {
+		h.logger.WithError(err).WithFields(logrus.Fields{
+			"from": fromDate,
+			"to":   toDate,
+		}).Error("Failed to get existences summary from database")
+		return nil, err
+	}
+
+	return &summary, nil
+}
+
+// ListExistenceMovements retrieves every movement of an existence, oldest first
+func (h *DBHandler) ListExistenceMovements(ctx context.Context, existenceID string) ([]models.ExistenceMovement, error) {
+	rows, err := h.db.QueryContext(ctx, existenceSQL.ListExistenceMovementsQuery, existenceID)
+	if err != nil {
+		h.logger.WithError(err).WithFields(logrus.Fields{
+			"existence_id": existenceID,
+		}).Error("Failed to list existence movements from database")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var movements []models.ExistenceMovement
+	for rows.Next() {
+		var movement models.ExistenceMovement
+		err := rows.Scan(&movement.ID, &movement.ExistenceID, &movement.MovementType,
+			&movement.QuantityChange, &movement.Notes, &movement.CreatedAt)
+
+		if err != nil {
+			h.logger.WithError(err).Error("Failed to scan existence movement row")
+			return nil, err
+		}
+		movements = append(movements, movement)
+	}
+
+	if err = rows.Err(); err != nil {
+		h.logger.WithError(err).Error("Error occurred during rows iteration")
+		return nil, err
+	}
+
+	// Return empty slice instead of nil if there are no movements
+	if movements == nil {
+		movements = []models.ExistenceMovement{}
+	}
+
+	return movements, nil
+}
+
+// GetInventoryValuation retrieves the remaining stock value per ingredient for batches
+// that existed on asOf and had not expired by then
+func (h *DBHandler) GetInventoryValuation(asOf time.Time) ([]models.IngredientValuation, error) {
+	asOfDate := asOf.Format("2006-01-02")
+
+	rows, err := h.db.Query(existenceSQL.GetInventoryValuationQuery, asOfDate)
+	if err != nil {
+		h.logger.WithError(err).WithFields(logrus.Fields{
+			"as_of": asOfDate,
+		}).Error("Failed to get inventory valuation from database")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var valuations []models.IngredientValuation
+	for rows.Next() {
+		var valuation models.IngredientValuation
+		err := rows.Scan(&valuation.IngredientID, &valuation.IngredientName,
+			&valuation.CategoryID, &valuation.CategoryName,
+			&valuation.BatchCount, &valuation.RemainingValue)
+
+		if err != nil {
+			h.logger.WithError(err).Error("Failed to scan inventory valuation row")
+			return nil, err
+		}
+		valuations = append(valuations, valuation)
+	}
+
+	if err = rows.Err(); err != nil {
+		h.logger.WithError(err).Error("Error occurred during rows iteration")
+		return nil, err
+	}
+
+	// Return empty slice instead of nil if there is no stock
+	if valuations == nil {
+		valuations = []models.IngredientValuation{}
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"as_of": asOfDate,
+		"count": len(valuations),
+	}).Info("Calculated inventory valuation successfully")
+
+	return valuations, nil
+}
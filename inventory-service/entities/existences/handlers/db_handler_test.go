@@ -1,13 +1,16 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"regexp"
+	"sync"
 	"testing"
 	"time"
 
 	"inventory-service/entities/existences/models"
+	unitConversionModels "inventory-service/entities/unit_conversions/models"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/sirupsen/logrus"
@@ -181,6 +184,98 @@ func TestDBHandler_CreateExistence_DatabaseError(t *testing.T) {
 	assert.Contains(t, err.Error(), "database connection failed")
 }
 
+// existenceColumns lists the columns returned by the create existence query
+var existenceColumns = []string{
+	"id", "existence_reference_code", "ingredient_id", "invoice_detail_id",
+	"units_purchased", "units_available", "unit_type", "items_per_unit",
+	"cost_per_item", "cost_per_unit", "total_purchase_cost", "remaining_value",
+	"expiration_date", "income_margin_percentage", "income_margin_amount",
+	"iva_percentage", "iva_amount", "service_tax_percentage", "service_tax_amount",
+	"calculated_price", "final_price", "created_at", "updated_at",
+}
+
+func createdExistenceRow(id string, referenceCode int, req models.CreateExistenceRequest) *sqlmock.Rows {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	return sqlmock.NewRows(existenceColumns).AddRow(
+		id, referenceCode, req.IngredientID, req.InvoiceDetailID,
+		req.UnitsPurchased, req.UnitsAvailable, req.UnitType, req.ItemsPerUnit,
+		req.CostPerUnit/float64(req.ItemsPerUnit), req.CostPerUnit,
+		req.UnitsPurchased*req.CostPerUnit, req.UnitsAvailable*req.CostPerUnit,
+		nil, 30.0, 0.0, 13.0, 0.0, 10.0, 0.0, 0.0, nil, now, now,
+	)
+}
+
+func TestDBHandler_CreateExistences_AllSucceed(t *testing.T) {
+	handler, mock, cleanup := setupTestDBHandler(t)
+	defer cleanup()
+
+	reqs := models.BulkCreateExistencesRequest{
+		{IngredientID: "ingredient-1", InvoiceDetailID: "detail-1", UnitsPurchased: 10, UnitsAvailable: 10, UnitType: "Liters", ItemsPerUnit: 1, CostPerUnit: 1000},
+		{IngredientID: "ingredient-2", InvoiceDetailID: "detail-2", UnitsPurchased: 5, UnitsAvailable: 5, UnitType: "Bag", ItemsPerUnit: 20, CostPerUnit: 4000},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO existences")).
+		WithArgs(reqs[0].IngredientID, reqs[0].InvoiceDetailID, reqs[0].UnitsPurchased, reqs[0].UnitsAvailable,
+			reqs[0].UnitType, reqs[0].ItemsPerUnit, reqs[0].CostPerUnit, reqs[0].ExpirationDate,
+			reqs[0].IncomeMarginPercentage, reqs[0].IvaPercentage, reqs[0].ServiceTaxPercentage, reqs[0].FinalPrice).
+		WillReturnRows(createdExistenceRow("existence-1", 1001, reqs[0]))
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO existences")).
+		WithArgs(reqs[1].IngredientID, reqs[1].InvoiceDetailID, reqs[1].UnitsPurchased, reqs[1].UnitsAvailable,
+			reqs[1].UnitType, reqs[1].ItemsPerUnit, reqs[1].CostPerUnit, reqs[1].ExpirationDate,
+			reqs[1].IncomeMarginPercentage, reqs[1].IvaPercentage, reqs[1].ServiceTaxPercentage, reqs[1].FinalPrice).
+		WillReturnRows(createdExistenceRow("existence-2", 1002, reqs[1]))
+	mock.ExpectCommit()
+
+	result, err := handler.CreateExistences(reqs)
+
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+	assert.Equal(t, "existence-1", result[0].ID)
+	assert.Equal(t, 1001, result[0].ExistenceReferenceCode)
+	assert.Equal(t, "existence-2", result[1].ID)
+	assert.Equal(t, "ingredient-2", result[1].IngredientID)
+}
+
+func TestDBHandler_CreateExistences_OneFailsRollsBack(t *testing.T) {
+	handler, mock, cleanup := setupTestDBHandler(t)
+	defer cleanup()
+
+	reqs := models.BulkCreateExistencesRequest{
+		{IngredientID: "ingredient-1", InvoiceDetailID: "detail-1", UnitsPurchased: 10, UnitsAvailable: 10, UnitType: "Liters", ItemsPerUnit: 1, CostPerUnit: 1000},
+		{IngredientID: "missing-ingredient", InvoiceDetailID: "detail-2", UnitsPurchased: 5, UnitsAvailable: 5, UnitType: "Bag", ItemsPerUnit: 20, CostPerUnit: 4000},
+		{IngredientID: "ingredient-3", InvoiceDetailID: "detail-3", UnitsPurchased: 1, UnitsAvailable: 1, UnitType: "Units", ItemsPerUnit: 1, CostPerUnit: 500},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO existences")).
+		WillReturnRows(createdExistenceRow("existence-1", 1001, reqs[0]))
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO existences")).
+		WillReturnError(fmt.Errorf("insert or update on table \"existences\" violates foreign key constraint"))
+	// The third item must never be attempted and nothing may be committed
+	mock.ExpectRollback()
+
+	result, err := handler.CreateExistences(reqs)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "index 1")
+	assert.Nil(t, result)
+}
+
+func TestDBHandler_CreateExistences_BeginError(t *testing.T) {
+	handler, mock, cleanup := setupTestDBHandler(t)
+	defer cleanup()
+
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("connection refused"))
+
+	result, err := handler.CreateExistences(models.BulkCreateExistencesRequest{
+		{IngredientID: "ingredient-1", InvoiceDetailID: "detail-1", UnitsPurchased: 1, UnitsAvailable: 1, UnitType: "Units", ItemsPerUnit: 1, CostPerUnit: 1},
+	})
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+}
+
 func TestDBHandler_GetExistenceByID_Success(t *testing.T) {
 	handler, mock, cleanup := setupTestDBHandler(t)
 	defer cleanup()
@@ -271,6 +366,85 @@ func TestDBHandler_GetExistenceByID_NotFound(t *testing.T) {
 	assert.Nil(t, result)
 }
 
+func TestDBHandler_GetExistenceByReferenceCode_Success(t *testing.T) {
+	handler, mock, cleanup := setupTestDBHandler(t)
+	defer cleanup()
+
+	req := models.CreateExistenceRequest{
+		IngredientID: "ingredient-id-123", InvoiceDetailID: "invoice-detail-id-123",
+		UnitsPurchased: 10, UnitsAvailable: 8.5, UnitType: "Liters", ItemsPerUnit: 1, CostPerUnit: 1200,
+	}
+
+	mock.ExpectQuery(`SELECT.*FROM existences WHERE existence_reference_code = ?`).
+		WithArgs(1001).
+		WillReturnRows(createdExistenceRow("existence-id-123", 1001, req))
+
+	result, err := handler.GetExistenceByReferenceCode(1001)
+
+	require.NoError(t, err)
+	assert.Equal(t, "existence-id-123", result.ID)
+	assert.Equal(t, 1001, result.ExistenceReferenceCode)
+	assert.Equal(t, req.IngredientID, result.IngredientID)
+}
+
+func TestDBHandler_GetExistenceByReferenceCode_NotFound(t *testing.T) {
+	handler, mock, cleanup := setupTestDBHandler(t)
+	defer cleanup()
+
+	mock.ExpectQuery(`SELECT.*FROM existences WHERE existence_reference_code = ?`).
+		WithArgs(9999).
+		WillReturnError(sql.ErrNoRows)
+
+	result, err := handler.GetExistenceByReferenceCode(9999)
+
+	assert.Equal(t, sql.ErrNoRows, err)
+	assert.Nil(t, result)
+}
+
+// TestDBHandler_CreateExistence_ConcurrentReferenceCodes checks that concurrent
+// creates never supply a reference code themselves and so rely on the database
+// sequence to hand out distinct codes.
+func TestDBHandler_CreateExistence_ConcurrentReferenceCodes(t *testing.T) {
+	handler, mock, cleanup := setupTestDBHandler(t)
+	defer cleanup()
+	mock.MatchExpectationsInOrder(false)
+
+	const workers = 10
+	req := models.CreateExistenceRequest{
+		IngredientID: "ingredient-1", InvoiceDetailID: "detail-1",
+		UnitsPurchased: 1, UnitsAvailable: 1, UnitType: "Unit", ItemsPerUnit: 1, CostPerUnit: 100,
+	}
+	for i := 0; i < workers; i++ {
+		mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO existences")).
+			WithArgs(req.IngredientID, req.InvoiceDetailID, req.UnitsPurchased, req.UnitsAvailable,
+				req.UnitType, req.ItemsPerUnit, req.CostPerUnit, req.ExpirationDate,
+				req.IncomeMarginPercentage, req.IvaPercentage, req.ServiceTaxPercentage, req.FinalPrice).
+			WillReturnRows(createdExistenceRow(fmt.Sprintf("existence-%d", i), 1001+i, req))
+	}
+
+	codes := make(chan int, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			existence, err := handler.CreateExistence(req)
+			if assert.NoError(t, err) {
+				codes <- existence.ExistenceReferenceCode
+			}
+		}()
+	}
+	wg.Wait()
+	close(codes)
+
+	seen := make(map[int]bool)
+	for code := range codes {
+		assert.False(t, seen[code], "duplicate reference code %d", code)
+		seen[code] = true
+	}
+	assert.Len(t, seen, workers)
+}
+
 func TestDBHandler_ListExistences_Success(t *testing.T) {
 	handler, mock, cleanup := setupTestDBHandler(t)
 	defer cleanup()
@@ -343,7 +517,7 @@ func TestDBHandler_ListExistences_Success(t *testing.T) {
 		))
 
 	// Execute
-	result, err := handler.ListExistences(req)
+	result, err := handler.ListExistences(context.Background(), req)
 
 	// Assert
 	require.NoError(t, err)
@@ -372,7 +546,7 @@ func TestDBHandler_ListExistences_EmptyResult(t *testing.T) {
 		}))
 
 	// Execute
-	result, err := handler.ListExistences(req)
+	result, err := handler.ListExistences(context.Background(), req)
 
 	// Assert
 	require.NoError(t, err)
@@ -381,6 +555,25 @@ func TestDBHandler_ListExistences_EmptyResult(t *testing.T) {
 	assert.Equal(t, []models.Existence{}, result)
 }
 
+func TestDBHandler_CountExistences(t *testing.T) {
+	handler, mock, cleanup := setupTestDBHandler(t)
+	defer cleanup()
+
+	lowStock := true
+	limit, offset := 10, 20
+	req := models.ListExistencesRequest{LowStock: &lowStock, Limit: &limit, Offset: &offset}
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM existences WHERE 1=1`).
+		WithArgs(req.IngredientID, req.UnitType, req.Expired, req.LowStock).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(42))
+
+	total, err := handler.CountExistences(context.Background(), req)
+
+	require.NoError(t, err)
+	assert.Equal(t, 42, total)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestDBHandler_UpdateExistence_Success(t *testing.T) {
 	handler, mock, cleanup := setupTestDBHandler(t)
 	defer cleanup()
@@ -553,3 +746,552 @@ func TestDBHandler_DeleteExistence_DatabaseError(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "database connection failed")
 }
+
+func TestDBHandler_GetInventoryValuation_Success(t *testing.T) {
+	handler, mock, cleanup := setupTestDBHandler(t)
+	defer cleanup()
+
+	asOf := time.Date(2024, 6, 30, 15, 30, 0, 0, time.UTC)
+
+	rows := sqlmock.NewRows([]string{
+		"ingredient_id", "ingredient_name", "category_id", "category_name", "batch_count", "remaining_value",
+	}).
+		AddRow("milk", "Milk", "dairy", "Dairy", 2, 1500.50).
+		AddRow("salt", "Salt", nil, nil, 1, 100.0)
+
+	// The as_of timestamp is truncated to a date before it reaches the query
+	mock.ExpectQuery(regexp.QuoteMeta("FROM existences e")).
+		WithArgs("2024-06-30").
+		WillReturnRows(rows)
+
+	valuations, err := handler.GetInventoryValuation(asOf)
+
+	assert.NoError(t, err)
+	require.Len(t, valuations, 2)
+	assert.Equal(t, "Milk", valuations[0].IngredientName)
+	require.NotNil(t, valuations[0].CategoryName)
+	assert.Equal(t, "Dairy", *valuations[0].CategoryName)
+	assert.Equal(t, 2, valuations[0].BatchCount)
+	assert.Equal(t, 1500.50, valuations[0].RemainingValue)
+	assert.Nil(t, valuations[1].CategoryID)
+	assert.Nil(t, valuations[1].CategoryName)
+}
+
+func TestDBHandler_GetInventoryValuation_EmptyResult(t *testing.T) {
+	handler, mock, cleanup := setupTestDBHandler(t)
+	defer cleanup()
+
+	rows := sqlmock.NewRows([]string{
+		"ingredient_id", "ingredient_name", "category_id", "category_name", "batch_count", "remaining_value",
+	})
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM existences e")).
+		WithArgs("2024-01-01").
+		WillReturnRows(rows)
+
+	valuations, err := handler.GetInventoryValuation(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	assert.NoError(t, err)
+	assert.NotNil(t, valuations)
+	assert.Empty(t, valuations)
+}
+
+func TestDBHandler_GetInventoryValuation_DatabaseError(t *testing.T) {
+	handler, mock, cleanup := setupTestDBHandler(t)
+	defer cleanup()
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM existences e")).
+		WithArgs("2024-01-01").
+		WillReturnError(fmt.Errorf("database connection failed"))
+
+	valuations, err := handler.GetInventoryValuation(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	assert.Error(t, err)
+	assert.Nil(t, valuations)
+}
+
+func TestDBHandler_ListExpiringExistences_Success(t *testing.T) {
+	handler, mock, cleanup := setupTestDBHandler(t)
+	defer cleanup()
+
+	from := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 6, 8, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	rows := sqlmock.NewRows(existenceColumns).
+		AddRow("existence-soon", 1001, "ingredient-1", "detail-1", 10.0, 4.0, "Liters", 1,
+			1000.0, 1000.0, 10000.0, 4000.0, from, 30.0, 0.0, 13.0, 0.0, 10.0, 0.0, 0.0, nil, now, now).
+		AddRow("existence-later", 1002, "ingredient-2", "detail-2", 5.0, 5.0, "Bag", 20,
+			200.0, 4000.0, 20000.0, 20000.0, to, 30.0, 0.0, 13.0, 0.0, 10.0, 0.0, 0.0, nil, now, now)
+
+	mock.ExpectQuery(regexp.QuoteMeta("ORDER BY expiration_date ASC")).
+		WithArgs("2024-06-01", "2024-06-08").
+		WillReturnRows(rows)
+
+	existences, err := handler.ListExpiringExistences(context.Background(), from, to)
+
+	require.NoError(t, err)
+	require.Len(t, existences, 2)
+	assert.Equal(t, "existence-soon", existences[0].ID)
+	assert.Equal(t, "existence-later", existences[1].ID)
+}
+
+func TestDBHandler_ListExpiringExistences_EmptyResult(t *testing.T) {
+	handler, mock, cleanup := setupTestDBHandler(t)
+	defer cleanup()
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM existences")).
+		WithArgs("2024-06-01", "2024-06-08").
+		WillReturnRows(sqlmock.NewRows(existenceColumns))
+
+	existences, err := handler.ListExpiringExistences(context.Background(),
+		time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 6, 8, 0, 0, 0, 0, time.UTC))
+
+	require.NoError(t, err)
+	assert.NotNil(t, existences)
+	assert.Empty(t, existences)
+}
+
+func TestDBHandler_GetExistencesSummary(t *testing.T) {
+	from := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 6, 8, 0, 0, 0, 0, time.UTC)
+	summaryColumns := []string{"total_existences", "total_remaining_value", "expiring_soon", "expired", "low_stock"}
+
+	testCases := map[string]struct {
+		rows          *sqlmock.Rows
+		dbErr         error
+		expected      *models.ExistencesSummary
+		expectedError bool
+	}{
+		"aggregates every KPI": {
+			rows: sqlmock.NewRows(summaryColumns).AddRow(12, 45250.75, 3, 2, 4),
+			expected: &models.ExistencesSummary{
+				TotalExistences:     12,
+				TotalRemainingValue: 45250.75,
+				ExpiringCount:       3,
+				ExpiredCount:        2,
+				LowStockCount:       4,
+			},
+		},
+		"no existences": {
+			rows:     sqlmock.NewRows(summaryColumns).AddRow(0, 0.0, 0, 0, 0),
+			expected: &models.ExistencesSummary{},
+		},
+		"database error": {
+			dbErr:         fmt.Errorf("database connection failed"),
+			expectedError: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler, mock, cleanup := setupTestDBHandler(t)
+			defer cleanup()
+
+			expectation := mock.ExpectQuery(regexp.QuoteMeta("COUNT(*) FILTER")).
+				WithArgs("2024-06-01", "2024-06-08")
+			if tc.dbErr != nil {
+				expectation.WillReturnError(tc.dbErr)
+			} else {
+				expectation.WillReturnRows(tc.rows)
+			}
+
+			summary, err := handler.GetExistencesSummary(context.Background(), from, to)
+
+			if tc.expectedError {
+				assert.Error(t, err)
+				assert.Nil(t, summary)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected, summary)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDBHandler_ConsumeExistence_AppendsMovement(t *testing.T) {
+	handler, mock, cleanup := setupTestDBHandler(t)
+	defer cleanup()
+
+	existenceID := "existence-id-123"
+	notes := "Used for sundaes"
+	createdAt := time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SET units_available = units_available - $2")).
+		WithArgs(existenceID, 2.5).
+		WillReturnRows(sqlmock.NewRows([]string{"units_available"}).AddRow(7.5))
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO existence_movements")).
+		WithArgs(existenceID, models.MovementTypeConsumption, -2.5, &notes).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "existence_id", "movement_type", "quantity_change", "notes", "created_at"}).
+			AddRow("movement-2", existenceID, models.MovementTypeConsumption, -2.5, notes, createdAt))
+	mock.ExpectCommit()
+
+	movement, err := handler.ConsumeExistence(existenceID, models.ConsumeExistenceRequest{
+		Units:        2.5,
+		MovementType: models.MovementTypeConsumption,
+		Notes:        &notes,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "movement-2", movement.ID)
+	assert.Equal(t, -2.5, movement.QuantityChange)
+	assert.Equal(t, createdAt, movement.CreatedAt)
+}
+
+func TestDBHandler_ConsumeExistence_NothingConsumed(t *testing.T) {
+	testCases := map[string]struct {
+		unitsRows     *sqlmock.Rows
+		expectedError error
+	}{
+		"insufficient units": {
+			unitsRows:     sqlmock.NewRows([]string{"units_available"}).AddRow(1.0),
+			expectedError: models.ErrInsufficientUnits,
+		},
+		"existence not found": {
+			unitsRows:     sqlmock.NewRows([]string{"units_available"}),
+			expectedError: sql.ErrNoRows,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler, mock, cleanup := setupTestDBHandler(t)
+			defer cleanup()
+
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta("SET units_available = units_available - $2")).
+				WithArgs("existence-id-123", 5.0).
+				WillReturnRows(sqlmock.NewRows([]string{"units_available"}))
+			mock.ExpectQuery(regexp.QuoteMeta("SELECT units_available")).
+				WithArgs("existence-id-123").
+				WillReturnRows(tc.unitsRows)
+			mock.ExpectRollback()
+
+			movement, err := handler.ConsumeExistence("existence-id-123", models.ConsumeExistenceRequest{
+				Units:        5.0,
+				MovementType: models.MovementTypeWriteOff,
+			})
+
+			assert.ErrorIs(t, err, tc.expectedError)
+			assert.Nil(t, movement)
+		})
+	}
+}
+
+func TestDBHandler_ConsumeExistence_ConvertsUnitType(t *testing.T) {
+	conversionColumns := []string{"id", "ingredient_id", "from_unit", "to_unit", "factor", "created_at", "updated_at"}
+
+	testCases := map[string]struct {
+		units         float64
+		unitType      string
+		existenceUnit string
+		conversions   *sqlmock.Rows
+		expectedUnits float64
+		expectedError error
+	}{
+		"standard conversion": {
+			units:         500,
+			unitType:      "ml",
+			existenceUnit: "Liters",
+			expectedUnits: 0.5,
+		},
+		"ingredient conversion factor": {
+			units:         2,
+			unitType:      "Bag",
+			existenceUnit: "Units",
+			conversions: sqlmock.NewRows(conversionColumns).
+				AddRow("conversion-1", "ingredient-1", "Bag", "Units", 24.0, time.Now(), time.Now()),
+			expectedUnits: 48,
+		},
+		"undefined conversion": {
+			units:         1,
+			unitType:      "kg",
+			existenceUnit: "Liters",
+			conversions:   sqlmock.NewRows(conversionColumns),
+			expectedError: unitConversionModels.ErrUndefinedConversion,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler, mock, cleanup := setupTestDBHandler(t)
+			defer cleanup()
+
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE")).
+				WithArgs("existence-id-123").
+				WillReturnRows(sqlmock.NewRows([]string{"ingredient_id", "unit_type"}).AddRow("ingredient-1", tc.existenceUnit))
+			if tc.conversions != nil {
+				mock.ExpectQuery(regexp.QuoteMeta("FROM unit_conversions")).
+					WithArgs("ingredient-1").
+					WillReturnRows(tc.conversions)
+			}
+			if tc.expectedError == nil {
+				mock.ExpectQuery(regexp.QuoteMeta("SET units_available = units_available - $2")).
+					WithArgs("existence-id-123", tc.expectedUnits).
+					WillReturnRows(sqlmock.NewRows([]string{"units_available"}).AddRow(10.0))
+				mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO existence_movements")).
+					WithArgs("existence-id-123", models.MovementTypeConsumption, -tc.expectedUnits, nil).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "existence_id", "movement_type", "quantity_change", "notes", "created_at"}).
+						AddRow("movement-2", "existence-id-123", models.MovementTypeConsumption, -tc.expectedUnits, nil, time.Now()))
+				mock.ExpectCommit()
+			} else {
+				mock.ExpectRollback()
+			}
+
+			movement, err := handler.ConsumeExistence("existence-id-123", models.ConsumeExistenceRequest{
+				Units:        tc.units,
+				UnitType:     &tc.unitType,
+				MovementType: models.MovementTypeConsumption,
+			})
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.Nil(t, movement)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, -tc.expectedUnits, movement.QuantityChange)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDBHandler_ReassignExistence_Success(t *testing.T) {
+	handler, mock, cleanup := setupTestDBHandler(t)
+	defer cleanup()
+
+	existenceID := "existence-id-123"
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	notes := "Logged as skim milk by mistake"
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE")).
+		WithArgs(existenceID).
+		WillReturnRows(sqlmock.NewRows([]string{"ingredient_id", "unit_type"}).AddRow("ingredient-skim", "Liters"))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM ingredients WHERE id = $1")).
+		WithArgs("ingredient-whole").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT DISTINCT unit_type")).
+		WithArgs("ingredient-whole").
+		WillReturnRows(sqlmock.NewRows([]string{"unit_type"}).AddRow("Gallons"))
+	mock.ExpectQuery(regexp.QuoteMeta("ingredient_id = $2,")).
+		WithArgs(existenceID, "ingredient-whole").
+		WillReturnRows(sqlmock.NewRows(existenceColumns).AddRow(existenceID, 1001, "ingredient-whole", "detail-1", 10.0, 8.0, "Liters", 1,
+			1000.0, 1000.0, 10000.0, 8000.0, nil, 30.0, 3000.0, 13.0, 1690.0, 10.0, 1300.0, 15990.0, nil, now, now))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO existence_movements")).
+		WithArgs(existenceID, models.MovementTypeReassignment, 0, "Reassigned from ingredient ingredient-skim to ingredient-whole: "+notes).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	existence, err := handler.ReassignExistence(existenceID, models.ReassignExistenceRequest{
+		IngredientID: "ingredient-whole",
+		Notes:        &notes,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "ingredient-whole", existence.IngredientID)
+	assert.Equal(t, 8.0, existence.UnitsAvailable)
+}
+
+func TestDBHandler_ReassignExistence_Rejected(t *testing.T) {
+	testCases := map[string]struct {
+		existenceRows *sqlmock.Rows
+		targetExists  bool
+		stockedUnits  []string
+		conversions   *sqlmock.Rows
+		expectedError error
+	}{
+		"existence not found": {
+			existenceRows: sqlmock.NewRows([]string{"ingredient_id", "unit_type"}),
+			expectedError: sql.ErrNoRows,
+		},
+		"already on the target ingredient": {
+			existenceRows: sqlmock.NewRows([]string{"ingredient_id", "unit_type"}).AddRow("ingredient-target", "Liters"),
+			expectedError: models.ErrSameIngredient,
+		},
+		"target ingredient missing": {
+			existenceRows: sqlmock.NewRows([]string{"ingredient_id", "unit_type"}).AddRow("ingredient-source", "Liters"),
+			expectedError: models.ErrIngredientNotFound,
+		},
+		"target stocked in incompatible units": {
+			existenceRows: sqlmock.NewRows([]string{"ingredient_id", "unit_type"}).AddRow("ingredient-source", "Liters"),
+			targetExists:  true,
+			stockedUnits:  []string{"Bag"},
+			conversions: sqlmock.NewRows([]string{"id", "ingredient_id", "from_unit", "to_unit", "factor", "created_at", "updated_at"}).
+				AddRow("conversion-1", "ingredient-target", "Bag", "Units", 24.0, time.Now(), time.Now()),
+			expectedError: models.ErrIncompatibleUnitType,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler, mock, cleanup := setupTestDBHandler(t)
+			defer cleanup()
+
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE")).
+				WithArgs("existence-id-123").
+				WillReturnRows(tc.existenceRows)
+			if tc.expectedError != sql.ErrNoRows && tc.expectedError != models.ErrSameIngredient {
+				mock.ExpectQuery(regexp.QuoteMeta("FROM ingredients WHERE id = $1")).
+					WithArgs("ingredient-target").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(tc.targetExists))
+			}
+			if tc.stockedUnits != nil {
+				units := sqlmock.NewRows([]string{"unit_type"})
+				for _, unit := range tc.stockedUnits {
+					units.AddRow(unit)
+				}
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT DISTINCT unit_type")).
+					WithArgs("ingredient-target").
+					WillReturnRows(units)
+				mock.ExpectQuery(regexp.QuoteMeta("FROM unit_conversions")).
+					WithArgs("ingredient-target").
+					WillReturnRows(tc.conversions)
+			}
+			mock.ExpectRollback()
+
+			existence, err := handler.ReassignExistence("existence-id-123", models.ReassignExistenceRequest{IngredientID: "ingredient-target"})
+
+			assert.ErrorIs(t, err, tc.expectedError)
+			assert.Nil(t, existence)
+		})
+	}
+}
+
+func TestDBHandler_ListExistenceMovements_Success(t *testing.T) {
+	handler, mock, cleanup := setupTestDBHandler(t)
+	defer cleanup()
+
+	existenceID := "existence-id-123"
+	createdAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	rows := sqlmock.NewRows([]string{"id", "existence_id", "movement_type", "quantity_change", "notes", "created_at"}).
+		AddRow("movement-1", existenceID, models.MovementTypePurchase, 10.0, nil, createdAt).
+		AddRow("movement-2", existenceID, models.MovementTypeConsumption, -2.5, nil, createdAt.Add(time.Hour))
+
+	mock.ExpectQuery(regexp.QuoteMeta("ORDER BY created_at ASC")).
+		WithArgs(existenceID).
+		WillReturnRows(rows)
+
+	movements, err := handler.ListExistenceMovements(context.Background(), existenceID)
+
+	require.NoError(t, err)
+	require.Len(t, movements, 2)
+	assert.Equal(t, models.MovementTypePurchase, movements[0].MovementType)
+	assert.Equal(t, -2.5, movements[1].QuantityChange)
+}
+
+func TestDBHandler_RepriceIngredientExistences_UpdatesEveryExistence(t *testing.T) {
+	handler, mock, cleanup := setupTestDBHandler(t)
+	defer cleanup()
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	lockedRows := sqlmock.NewRows(existenceColumns).
+		AddRow("existence-1", 1001, "ingredient-1", "detail-1", 10.0, 8.5, "Liters", 31,
+			322.58, 10000.0, 100000.0, 85000.0, nil, 30.0, 30000.0, 13.0, 16900.0, 10.0, 13000.0, 159900.0, nil, now, now).
+		AddRow("existence-2", 1002, "ingredient-1", "detail-2", 4.0, 4.0, "Liters", 1,
+			10000.0, 10000.0, 40000.0, 40000.0, nil, 0.0, 0.0, 13.0, 5200.0, 0.0, 0.0, 45200.0, nil, now, now)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT.*FROM existences WHERE ingredient_id = \$1.*FOR UPDATE`).
+		WithArgs("ingredient-1").
+		WillReturnRows(lockedRows)
+	// Margin and taxes are priced per item and the final price is rounded up to the next 100
+	mock.ExpectQuery(regexp.QuoteMeta("UPDATE existences")).
+		WithArgs("existence-1", 12000.0, 116.13, 65.42, 50.32, 618.97, 700.0).
+		WillReturnRows(sqlmock.NewRows(existenceColumns).AddRow("existence-1", 1001, "ingredient-1", "detail-1", 10.0, 8.5, "Liters", 31,
+			387.10, 12000.0, 120000.0, 102000.0, nil, 30.0, 116.13, 13.0, 65.42, 10.0, 50.32, 618.97, 700.0, now, now))
+	mock.ExpectQuery(regexp.QuoteMeta("UPDATE existences")).
+		WithArgs("existence-2", 12000.0, 0.0, 1560.0, 0.0, 13560.0, 13600.0).
+		WillReturnRows(sqlmock.NewRows(existenceColumns).AddRow("existence-2", 1002, "ingredient-1", "detail-2", 4.0, 4.0, "Liters", 1,
+			12000.0, 12000.0, 48000.0, 48000.0, nil, 0.0, 0.0, 13.0, 1560.0, 0.0, 0.0, 13560.0, 13600.0, now, now))
+	mock.ExpectCommit()
+
+	result, err := handler.RepriceIngredientExistences("ingredient-1", 12000)
+
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+	for _, existence := range result {
+		assert.Equal(t, 12000.0, existence.CostPerUnit)
+	}
+	assert.Equal(t, 120000.0, result[0].TotalPurchaseCost)
+	assert.Equal(t, 102000.0, result[0].RemainingValue)
+	assert.Equal(t, 618.97, result[0].CalculatedPrice)
+	require.NotNil(t, result[0].FinalPrice)
+	assert.Equal(t, 700.0, *result[0].FinalPrice)
+	assert.Equal(t, 48000.0, result[1].TotalPurchaseCost)
+	assert.Equal(t, 13560.0, result[1].CalculatedPrice)
+	require.NotNil(t, result[1].FinalPrice)
+	assert.Equal(t, 13600.0, *result[1].FinalPrice)
+}
+
+func TestDBHandler_RepriceIngredientExistences_FailureRollsBack(t *testing.T) {
+	handler, mock, cleanup := setupTestDBHandler(t)
+	defer cleanup()
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT.*FROM existences WHERE ingredient_id = \$1.*FOR UPDATE`).
+		WithArgs("ingredient-1").
+		WillReturnRows(sqlmock.NewRows(existenceColumns).
+			AddRow("existence-1", 1001, "ingredient-1", "detail-1", 1.0, 1.0, "Units", 1,
+				100.0, 100.0, 100.0, 100.0, nil, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 100.0, nil, now, now))
+	mock.ExpectQuery(regexp.QuoteMeta("UPDATE existences")).
+		WillReturnError(fmt.Errorf("database error"))
+	mock.ExpectRollback()
+
+	result, err := handler.RepriceIngredientExistences("ingredient-1", 200)
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+}
+
+func TestDBHandler_ListIngredientCostBatches(t *testing.T) {
+	testCases := map[string]struct {
+		rows            *sqlmock.Rows
+		expectedBatches []models.IngredientCostBatch
+		expectedErr     error
+	}{
+		"batches newest first": {
+			rows: sqlmock.NewRows([]string{"units_available", "cost_per_item"}).
+				AddRow(10.0, 100.0).
+				AddRow(0.0, 90.0),
+			expectedBatches: []models.IngredientCostBatch{
+				{UnitsAvailable: 10, CostPerItem: 100},
+				{UnitsAvailable: 0, CostPerItem: 90},
+			},
+		},
+		"ingredient without existences": {
+			rows:            sqlmock.NewRows([]string{"units_available", "cost_per_item"}).AddRow(nil, nil),
+			expectedBatches: []models.IngredientCostBatch{},
+		},
+		"unknown ingredient": {
+			rows:        sqlmock.NewRows([]string{"units_available", "cost_per_item"}),
+			expectedErr: sql.ErrNoRows,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler, mock, cleanup := setupTestDBHandler(t)
+			defer cleanup()
+
+			mock.ExpectQuery(regexp.QuoteMeta("LEFT JOIN existences e ON e.ingredient_id = i.id")).
+				WithArgs("ingredient-1").
+				WillReturnRows(tc.rows)
+
+			batches, err := handler.ListIngredientCostBatches(context.Background(), "ingredient-1")
+
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedBatches, batches)
+		})
+	}
+}
@@ -1,11 +1,21 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
+	"time"
 
 	"inventory-service/entities/existences/models"
+	unitConversionModels "inventory-service/entities/unit_conversions/models"
+	"inventory-service/events"
+	"inventory-service/utils"
+	"shared/eventbus"
+	"shared/httpx"
+	"shared/validate"
 
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
@@ -14,10 +24,21 @@ import (
 // DBHandlerInterface defines the database operations interface
 type DBHandlerInterface interface {
 	CreateExistence(req models.CreateExistenceRequest) (*models.Existence, error)
+	CreateExistences(reqs models.BulkCreateExistencesRequest) ([]models.Existence, error)
 	GetExistenceByID(id string) (*models.Existence, error)
-	ListExistences(req models.ListExistencesRequest) ([]models.Existence, error)
+	GetExistenceByReferenceCode(code int) (*models.Existence, error)
+	ListExistences(ctx context.Context, req models.ListExistencesRequest) ([]models.Existence, error)
+	CountExistences(ctx context.Context, req models.ListExistencesRequest) (int, error)
+	ListExpiringExistences(ctx context.Context, from, to time.Time) ([]models.Existence, error)
+	GetExistencesSummary(ctx context.Context, from, to time.Time) (*models.ExistencesSummary, error)
 	UpdateExistence(id string, req models.UpdateExistenceRequest) (*models.Existence, error)
 	DeleteExistence(id string) error
+	GetInventoryValuation(asOf time.Time) ([]models.IngredientValuation, error)
+	ConsumeExistence(id string, req models.ConsumeExistenceRequest) (*models.ExistenceMovement, error)
+	ReassignExistence(id string, req models.ReassignExistenceRequest) (*models.Existence, error)
+	ListExistenceMovements(ctx context.Context, existenceID string) ([]models.ExistenceMovement, error)
+	RepriceIngredientExistences(ingredientID string, costPerUnit float64) ([]models.Existence, error)
+	ListIngredientCostBatches(ctx context.Context, ingredientID string) ([]models.IngredientCostBatch, error)
 }
 
 // Ensure DBHandler implements DBHandlerInterface
@@ -27,31 +48,66 @@ var _ DBHandlerInterface = (*DBHandler)(nil)
 type HttpHandler struct {
 	dbHandler DBHandlerInterface
 	logger    *logrus.Logger
+	events    *eventbus.Bus
+
+	// maxPageSize caps the limit of GET /existences; 0 disables the cap
+	maxPageSize int
 }
 
 // NewHttpHandler creates a new HTTP handler
 func NewHttpHandler(dbHandler *DBHandler, logger *logrus.Logger) *HttpHandler {
 	return &HttpHandler{
-		dbHandler: dbHandler,
-		logger:    logger,
+		dbHandler:   dbHandler,
+		logger:      logger,
+		maxPageSize: defaultMaxPageSize,
 	}
 }
 
 // NewHttpHandlerWithInterface creates a new HTTP handler with interface (for testing)
 func NewHttpHandlerWithInterface(dbHandler DBHandlerInterface, logger *logrus.Logger) *HttpHandler {
 	return &HttpHandler{
-		dbHandler: dbHandler,
-		logger:    logger,
+		dbHandler:   dbHandler,
+		logger:      logger,
+		maxPageSize: defaultMaxPageSize,
+	}
+}
+
+// SetMaxPageSize caps the limit a list request may ask for; larger limits are reduced, and 0 disables the cap
+func (h *HttpHandler) SetMaxPageSize(maxPageSize int) {
+	h.maxPageSize = maxPageSize
+}
+
+// SetEventBus publishes existence domain events on the given bus after each committed change
+func (h *HttpHandler) SetEventBus(bus *eventbus.Bus) {
+	h.events = bus
+}
+
+// publish sends an existence domain event when an event bus is configured
+func (h *HttpHandler) publish(event events.ExistenceEvent) {
+	if h.events == nil {
+		return
 	}
+	event.Timestamp = time.Now()
+	h.events.Publish(event)
 }
 
 // CreateExistence handles POST /existences
 func (h *HttpHandler) CreateExistence(w http.ResponseWriter, r *http.Request) {
 	var req models.CreateExistenceRequest
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := httpx.DecodeJSONBody(r, &req); err != nil {
 		h.logger.WithError(err).Error("Failed to decode create existence request")
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		http.Error(w, httpx.DecodeErrorMessage(err, "Invalid request body"), http.StatusBadRequest)
+		return
+	}
+
+	if validationErrors := req.Validate(); len(validationErrors) > 0 {
+		h.logger.WithFields(logrus.Fields{
+			"ingredient_id": req.IngredientID,
+			"error_count":   len(validationErrors),
+		}).Warn("Create existence request failed validation")
+
+		validate.WriteErrors(w, validationErrors)
 		return
 	}
 
@@ -67,6 +123,57 @@ func (h *HttpHandler) CreateExistence(w http.ResponseWriter, r *http.Request) {
 		Data:    *existence,
 		Message: "Existence created successfully",
 	}
+	h.logger.WithFields(httpx.RequestLogFields(r)).WithField("existence_id", existence.ID).Info("Existence created successfully")
+	h.publish(events.ExistenceEvent{Kind: events.ExistenceCreated, ExistenceID: existence.ID, IngredientID: existence.IngredientID, Quantity: existence.UnitsPurchased})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+// CreateExistencesBulk handles POST /existences/bulk
+func (h *HttpHandler) CreateExistencesBulk(w http.ResponseWriter, r *http.Request) {
+	var req models.BulkCreateExistencesRequest
+
+	if err := httpx.DecodeJSONBody(r, &req); err != nil {
+		h.logger.WithError(err).Error("Failed to decode bulk create existences request")
+		http.Error(w, httpx.DecodeErrorMessage(err, "Invalid request body"), http.StatusBadRequest)
+		return
+	}
+
+	if len(req) == 0 {
+		http.Error(w, "At least one existence is required", http.StatusBadRequest)
+		return
+	}
+
+	// Validate the whole batch before touching the database so nothing is inserted on bad input
+	if validationErrors := req.Validate(); len(validationErrors) > 0 {
+		h.logger.WithFields(logrus.Fields{
+			"count":       len(req),
+			"error_count": len(validationErrors),
+		}).Warn("Bulk create existences request failed validation")
+
+		validate.WriteErrors(w, validationErrors)
+		return
+	}
+
+	existences, err := h.dbHandler.CreateExistences(req)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to create existences in bulk")
+		http.Error(w, "Failed to create existences, no existences were created", http.StatusInternalServerError)
+		return
+	}
+
+	response := models.ExistencesResponse{
+		Success: true,
+		Data:    existences,
+		Total:   len(existences),
+		Message: "Existences created successfully",
+	}
+	h.logger.WithFields(httpx.RequestLogFields(r)).WithField("count", len(existences)).Info("Existences created successfully")
+	for _, existence := range existences {
+		h.publish(events.ExistenceEvent{Kind: events.ExistenceCreated, ExistenceID: existence.ID, IngredientID: existence.IngredientID, Quantity: existence.UnitsPurchased})
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
@@ -98,6 +205,42 @@ func (h *HttpHandler) GetExistence(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// GetExistenceByCode handles GET /existences/by-code/{code}
+func (h *HttpHandler) GetExistenceByCode(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	code, err := strconv.Atoi(vars["code"])
+	if err != nil || code <= 0 {
+		http.Error(w, "Reference code must be a positive integer", http.StatusBadRequest)
+		return
+	}
+
+	existence, err := h.dbHandler.GetExistenceByReferenceCode(code)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Existence not found", http.StatusNotFound)
+			return
+		}
+		h.logger.WithError(err).Error("Failed to get existence by reference code")
+		http.Error(w, "Failed to get existence", http.StatusInternalServerError)
+		return
+	}
+
+	response := models.ExistenceResponse{
+		Success: true,
+		Data:    *existence,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// defaultListLimit is the page size of GET /existences when no limit is given, and
+// defaultMaxPageSize the cap on requested limits until SetMaxPageSize changes it
+const (
+	defaultListLimit   = 50
+	defaultMaxPageSize = 100
+)
+
 // ListExistences handles GET /existences
 func (h *HttpHandler) ListExistences(w http.ResponseWriter, r *http.Request) {
 	// Parse query parameters
@@ -125,13 +268,80 @@ func (h *HttpHandler) ListExistences(w http.ResponseWriter, r *http.Request) {
 		req.LowStock = &lowStock
 	}
 
-	existences, err := h.dbHandler.ListExistences(req)
+	// Parse pagination window
+	limit := defaultListLimit
+	if h.maxPageSize > 0 && limit > h.maxPageSize {
+		limit = h.maxPageSize
+	}
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = httpx.ClampPageSize(w, parsed, h.maxPageSize)
+	}
+	offset := 0
+	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
+		parsed, err := strconv.Atoi(offsetStr)
+		if err != nil || parsed < 0 {
+			http.Error(w, "offset must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		offset = parsed
+	}
+	req.Limit = &limit
+	req.Offset = &offset
+
+	existences, err := h.dbHandler.ListExistences(r.Context(), req)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to list existences")
 		http.Error(w, "Failed to list existences", http.StatusInternalServerError)
 		return
 	}
 
+	total, err := h.dbHandler.CountExistences(r.Context(), req)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to count existences")
+		http.Error(w, "Failed to list existences", http.StatusInternalServerError)
+		return
+	}
+
+	response := models.ExistencesResponse{
+		Success:    true,
+		Data:       existences,
+		Total:      len(existences),
+		Pagination: utils.NewPagination(limit, offset, len(existences), total),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// defaultExpiringWithinDays is the look-ahead window used when within_days is not given
+const defaultExpiringWithinDays = 7
+
+// ListExpiringExistences handles GET /existences/expiring
+func (h *HttpHandler) ListExpiringExistences(w http.ResponseWriter, r *http.Request) {
+	withinDays := defaultExpiringWithinDays
+	if withinDaysStr := r.URL.Query().Get("within_days"); withinDaysStr != "" {
+		parsed, err := strconv.Atoi(withinDaysStr)
+		if err != nil || parsed <= 0 {
+			http.Error(w, "within_days must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		withinDays = parsed
+	}
+
+	from, to := expirationWindow(time.Now(), withinDays)
+
+	existences, err := h.dbHandler.ListExpiringExistences(r.Context(), from, to)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list expiring existences")
+		http.Error(w, "Failed to list expiring existences", http.StatusInternalServerError)
+		return
+	}
+
 	response := models.ExistencesResponse{
 		Success: true,
 		Data:    existences,
@@ -142,15 +352,43 @@ func (h *HttpHandler) ListExistences(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// GetExistencesSummary handles GET /existences/summary
+func (h *HttpHandler) GetExistencesSummary(w http.ResponseWriter, r *http.Request) {
+	from, to := expirationWindow(time.Now(), defaultExpiringWithinDays)
+
+	summary, err := h.dbHandler.GetExistencesSummary(r.Context(), from, to)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get existences summary")
+		http.Error(w, "Failed to get existences summary", http.StatusInternalServerError)
+		return
+	}
+	summary.ExpiringWithinDays = defaultExpiringWithinDays
+
+	response := models.ExistencesSummaryResponse{
+		Success: true,
+		Data:    *summary,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// expirationWindow returns the inclusive date range from today through today + withinDays.
+// Existences expiring today are still usable, so the window starts today rather than tomorrow.
+func expirationWindow(now time.Time, withinDays int) (time.Time, time.Time) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	return today, today.AddDate(0, 0, withinDays)
+}
+
 // UpdateExistence handles PUT /existences/{id}
 func (h *HttpHandler) UpdateExistence(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
 	var req models.UpdateExistenceRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := httpx.DecodeJSONBody(r, &req); err != nil {
 		h.logger.WithError(err).Error("Failed to decode update existence request")
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		http.Error(w, httpx.DecodeErrorMessage(err, "Invalid request body"), http.StatusBadRequest)
 		return
 	}
 
@@ -170,6 +408,8 @@ func (h *HttpHandler) UpdateExistence(w http.ResponseWriter, r *http.Request) {
 		Data:    *existence,
 		Message: "Existence updated successfully",
 	}
+	h.logger.WithFields(httpx.RequestLogFields(r)).WithField("existence_id", existence.ID).Info("Existence updated successfully")
+	h.publish(events.ExistenceEvent{Kind: events.ExistenceUpdated, ExistenceID: existence.ID, IngredientID: existence.IngredientID, Quantity: existence.UnitsAvailable})
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
@@ -195,7 +435,309 @@ func (h *HttpHandler) DeleteExistence(w http.ResponseWriter, r *http.Request) {
 		Success: true,
 		Message: "Existence deleted successfully",
 	}
+	h.logger.WithFields(httpx.RequestLogFields(r)).WithField("existence_id", id).Info("Existence deleted successfully")
+	h.publish(events.ExistenceEvent{Kind: events.ExistenceDeleted, ExistenceID: id})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// ConsumeExistence handles POST /existences/{id}/consume
+func (h *HttpHandler) ConsumeExistence(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req models.ConsumeExistenceRequest
+	if err := httpx.DecodeJSONBody(r, &req); err != nil {
+		h.logger.WithError(err).Error("Failed to decode consume existence request")
+		http.Error(w, httpx.DecodeErrorMessage(err, "Invalid request body"), http.StatusBadRequest)
+		return
+	}
+
+	if validationErrors := req.Validate(); len(validationErrors) > 0 {
+		h.logger.WithFields(logrus.Fields{
+			"existence_id": id,
+			"error_count":  len(validationErrors),
+		}).Warn("Consume existence request failed validation")
+
+		validate.WriteErrors(w, validationErrors)
+		return
+	}
+
+	movement, err := h.dbHandler.ConsumeExistence(id, req)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Existence not found", http.StatusNotFound)
+			return
+		}
+		if err == models.ErrInsufficientUnits {
+			http.Error(w, "Not enough units available", http.StatusConflict)
+			return
+		}
+		if errors.Is(err, unitConversionModels.ErrUndefinedConversion) {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		h.logger.WithError(err).Error("Failed to consume existence")
+		http.Error(w, "Failed to consume existence", http.StatusInternalServerError)
+		return
+	}
+
+	response := models.ExistenceMovementResponse{
+		Success: true,
+		Data:    *movement,
+		Message: "Existence units consumed successfully",
+	}
+	h.logger.WithFields(httpx.RequestLogFields(r)).WithField("existence_id", movement.ExistenceID).Info("Existence units consumed successfully")
+	h.publish(events.ExistenceEvent{Kind: events.ExistenceConsumed, ExistenceID: movement.ExistenceID, Quantity: movement.QuantityChange})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+// ReassignExistence handles POST /existences/{id}/reassign, moving stock logged against the wrong ingredient
+func (h *HttpHandler) ReassignExistence(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req models.ReassignExistenceRequest
+	if err := httpx.DecodeJSONBody(r, &req); err != nil {
+		h.logger.WithError(err).Error("Failed to decode reassign existence request")
+		http.Error(w, httpx.DecodeErrorMessage(err, "Invalid request body"), http.StatusBadRequest)
+		return
+	}
+
+	if validationErrors := req.Validate(); len(validationErrors) > 0 {
+		h.logger.WithFields(logrus.Fields{
+			"existence_id": id,
+			"error_count":  len(validationErrors),
+		}).Warn("Reassign existence request failed validation")
+
+		validate.WriteErrors(w, validationErrors)
+		return
+	}
+
+	existence, err := h.dbHandler.ReassignExistence(id, req)
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			http.Error(w, "Existence not found", http.StatusNotFound)
+		case models.ErrIngredientNotFound:
+			http.Error(w, "Target ingredient not found", http.StatusNotFound)
+		case models.ErrSameIngredient:
+			http.Error(w, "Existence already belongs to that ingredient", http.StatusUnprocessableEntity)
+		case models.ErrIncompatibleUnitType:
+			http.Error(w, "Existence unit type is not compatible with the target ingredient", http.StatusUnprocessableEntity)
+		default:
+			h.logger.WithError(err).Error("Failed to reassign existence")
+			http.Error(w, "Failed to reassign existence", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	response := models.ExistenceResponse{
+		Success: true,
+		Data:    *existence,
+		Message: "Existence reassigned successfully",
+	}
+	h.logger.WithFields(httpx.RequestLogFields(r)).WithField("existence_id", existence.ID).Info("Existence reassigned successfully")
+	h.publish(events.ExistenceEvent{Kind: events.ExistenceReassigned, ExistenceID: existence.ID, IngredientID: existence.IngredientID})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// RepriceIngredientExistences handles POST /ingredients/{id}/reprice
+func (h *HttpHandler) RepriceIngredientExistences(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	ingredientID := vars["id"]
+
+	var req models.RepriceIngredientRequest
+	if err := httpx.DecodeJSONBody(r, &req); err != nil {
+		h.logger.WithError(err).Error("Failed to decode reprice ingredient request")
+		http.Error(w, httpx.DecodeErrorMessage(err, "Invalid request body"), http.StatusBadRequest)
+		return
+	}
+
+	if validationErrors := req.Validate(); len(validationErrors) > 0 {
+		h.logger.WithFields(logrus.Fields{
+			"ingredient_id": ingredientID,
+			"error_count":   len(validationErrors),
+		}).Warn("Reprice ingredient request failed validation")
+
+		validate.WriteErrors(w, validationErrors)
+		return
+	}
+
+	existences, err := h.dbHandler.RepriceIngredientExistences(ingredientID, req.CostPerUnit)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to reprice ingredient existences")
+		http.Error(w, "Failed to reprice ingredient existences", http.StatusInternalServerError)
+		return
+	}
+
+	response := models.ExistencesResponse{
+		Success: true,
+		Data:    existences,
+		Total:   len(existences),
+		Message: "Ingredient existences repriced successfully",
+	}
+	h.logger.WithFields(httpx.RequestLogFields(r)).WithFields(logrus.Fields{
+		"ingredient_id": ingredientID,
+		"count":         len(existences),
+	}).Info("Ingredient existences repriced successfully")
+	for _, existence := range existences {
+		h.publish(events.ExistenceEvent{Kind: events.ExistenceUpdated, ExistenceID: existence.ID, IngredientID: existence.IngredientID, Quantity: existence.UnitsAvailable})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetIngredientAverageCost handles GET /ingredients/{id}/avg-cost
+func (h *HttpHandler) GetIngredientAverageCost(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	ingredientID := vars["id"]
+
+	batches, err := h.dbHandler.ListIngredientCostBatches(r.Context(), ingredientID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Ingredient not found", http.StatusNotFound)
+			return
+		}
+		h.logger.WithError(err).Error("Failed to get ingredient average cost")
+		http.Error(w, "Failed to get ingredient average cost", http.StatusInternalServerError)
+		return
+	}
+
+	response := models.IngredientAverageCostResponse{
+		Success: true,
+		Data:    models.CalculateAverageCost(ingredientID, batches),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetExistenceHistory handles GET /existences/{id}/history
+func (h *HttpHandler) GetExistenceHistory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	existence, err := h.dbHandler.GetExistenceByID(id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Existence not found", http.StatusNotFound)
+			return
+		}
+		h.logger.WithError(err).Error("Failed to get existence")
+		http.Error(w, "Failed to get existence", http.StatusInternalServerError)
+		return
+	}
+
+	movements, err := h.dbHandler.ListExistenceMovements(r.Context(), id)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list existence movements")
+		http.Error(w, "Failed to get existence history", http.StatusInternalServerError)
+		return
+	}
+
+	response := models.ExistenceHistoryResponse{
+		Success: true,
+		Data:    buildExistenceHistory(*existence, movements),
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
+
+// buildExistenceHistory attaches the running units balance to each movement, which arrive oldest first
+func buildExistenceHistory(existence models.Existence, movements []models.ExistenceMovement) models.ExistenceHistory {
+	history := models.ExistenceHistory{
+		ExistenceID:    existence.ID,
+		UnitsAvailable: existence.UnitsAvailable,
+		Movements:      make([]models.ExistenceHistoryEntry, 0, len(movements)),
+	}
+
+	balance := 0.0
+	for _, movement := range movements {
+		balance += movement.QuantityChange
+		history.Movements = append(history.Movements, models.ExistenceHistoryEntry{
+			ExistenceMovement: movement,
+			RunningBalance:    balance,
+		})
+	}
+
+	return history
+}
+
+// uncategorizedValuationName labels stock whose ingredient has no category
+const uncategorizedValuationName = "Uncategorized"
+
+// GetInventoryValuation handles GET /valuation
+func (h *HttpHandler) GetInventoryValuation(w http.ResponseWriter, r *http.Request) {
+	asOf := time.Now()
+	if asOfStr := r.URL.Query().Get("as_of"); asOfStr != "" {
+		parsed, err := time.Parse("2006-01-02", asOfStr)
+		if err != nil {
+			http.Error(w, "Invalid as_of date, expected YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+		asOf = parsed
+	}
+
+	ingredients, err := h.dbHandler.GetInventoryValuation(asOf)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get inventory valuation")
+		http.Error(w, "Failed to get inventory valuation", http.StatusInternalServerError)
+		return
+	}
+
+	response := models.InventoryValuationResponse{
+		Success: true,
+		Data:    buildInventoryValuation(asOf, ingredients),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// buildInventoryValuation rolls per-ingredient values up into category subtotals and an overall total,
+// keeping categories in the order they first appear
+func buildInventoryValuation(asOf time.Time, ingredients []models.IngredientValuation) models.InventoryValuation {
+	valuation := models.InventoryValuation{
+		AsOf:         asOf.Format("2006-01-02"),
+		ByCategory:   []models.CategoryValuation{},
+		ByIngredient: ingredients,
+	}
+
+	categoryIndex := make(map[string]int)
+	for _, ingredient := range ingredients {
+		key := ""
+		if ingredient.CategoryID != nil {
+			key = *ingredient.CategoryID
+		}
+
+		index, exists := categoryIndex[key]
+		if !exists {
+			category := models.CategoryValuation{
+				CategoryID:   ingredient.CategoryID,
+				CategoryName: uncategorizedValuationName,
+			}
+			if ingredient.CategoryName != nil {
+				category.CategoryName = *ingredient.CategoryName
+			}
+			valuation.ByCategory = append(valuation.ByCategory, category)
+			index = len(valuation.ByCategory) - 1
+			categoryIndex[key] = index
+		}
+
+		valuation.ByCategory[index].IngredientCount++
+		valuation.ByCategory[index].RemainingValue += ingredient.RemainingValue
+		valuation.TotalValue += ingredient.RemainingValue
+	}
+
+	return valuation
+}
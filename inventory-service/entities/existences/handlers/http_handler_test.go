@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -11,19 +12,38 @@ import (
 	"time"
 
 	"inventory-service/entities/existences/models"
+	unitConversionModels "inventory-service/entities/unit_conversions/models"
+	"inventory-service/events"
+	"shared/eventbus"
+	"shared/httpx"
+	"shared/validate"
 
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestMockDBHandler implements DBHandlerInterface for testing
 type TestMockDBHandler struct {
 	CreateExistenceFunc  func(req models.CreateExistenceRequest) (*models.Existence, error)
+	CreateExistencesFunc func(reqs models.BulkCreateExistencesRequest) ([]models.Existence, error)
 	GetExistenceByIDFunc func(id string) (*models.Existence, error)
+	GetByReferenceFunc   func(code int) (*models.Existence, error)
 	ListExistencesFunc   func(req models.ListExistencesRequest) ([]models.Existence, error)
+	CountExistencesFunc  func(req models.ListExistencesRequest) (int, error)
+	ListExpiringFunc     func(from, to time.Time) ([]models.Existence, error)
+	GetSummaryFunc       func(from, to time.Time) (*models.ExistencesSummary, error)
 	UpdateExistenceFunc  func(id string, req models.UpdateExistenceRequest) (*models.Existence, error)
 	DeleteExistenceFunc  func(id string) error
+
+	GetInventoryValuationFunc func(asOf time.Time) ([]models.IngredientValuation, error)
+
+	ConsumeExistenceFunc       func(id string, req models.ConsumeExistenceRequest) (*models.ExistenceMovement, error)
+	ReassignExistenceFunc      func(id string, req models.ReassignExistenceRequest) (*models.Existence, error)
+	ListExistenceMovementsFunc func(existenceID string) ([]models.ExistenceMovement, error)
+	RepriceFunc                func(ingredientID string, costPerUnit float64) ([]models.Existence, error)
+	ListCostBatchesFunc        func(ingredientID string) ([]models.IngredientCostBatch, error)
 }
 
 // Ensure TestMockDBHandler implements DBHandlerInterface
@@ -36,6 +56,13 @@ func (m *TestMockDBHandler) CreateExistence(req models.CreateExistenceRequest) (
 	return nil, nil
 }
 
+func (m *TestMockDBHandler) CreateExistences(reqs models.BulkCreateExistencesRequest) ([]models.Existence, error) {
+	if m.CreateExistencesFunc != nil {
+		return m.CreateExistencesFunc(reqs)
+	}
+	return nil, nil
+}
+
 func (m *TestMockDBHandler) GetExistenceByID(id string) (*models.Existence, error) {
 	if m.GetExistenceByIDFunc != nil {
 		return m.GetExistenceByIDFunc(id)
@@ -43,13 +70,34 @@ func (m *TestMockDBHandler) GetExistenceByID(id string) (*models.Existence, erro
 	return nil, nil
 }
 
-func (m *TestMockDBHandler) ListExistences(req models.ListExistencesRequest) ([]models.Existence, error) {
+func (m *TestMockDBHandler) GetExistenceByReferenceCode(code int) (*models.Existence, error) {
+	if m.GetByReferenceFunc != nil {
+		return m.GetByReferenceFunc(code)
+	}
+	return nil, nil
+}
+
+func (m *TestMockDBHandler) ListExistences(ctx context.Context, req models.ListExistencesRequest) ([]models.Existence, error) {
 	if m.ListExistencesFunc != nil {
 		return m.ListExistencesFunc(req)
 	}
 	return nil, nil
 }
 
+func (m *TestMockDBHandler) CountExistences(ctx context.Context, req models.ListExistencesRequest) (int, error) {
+	if m.CountExistencesFunc != nil {
+		return m.CountExistencesFunc(req)
+	}
+	return 0, nil
+}
+
+func (m *TestMockDBHandler) ListExpiringExistences(ctx context.Context, from, to time.Time) ([]models.Existence, error) {
+	if m.ListExpiringFunc != nil {
+		return m.ListExpiringFunc(from, to)
+	}
+	return nil, nil
+}
+
 func (m *TestMockDBHandler) UpdateExistence(id string, req models.UpdateExistenceRequest) (*models.Existence, error) {
 	if m.UpdateExistenceFunc != nil {
 		return m.UpdateExistenceFunc(id, req)
@@ -64,6 +112,55 @@ func (m *TestMockDBHandler) DeleteExistence(id string) error {
 	return nil
 }
 
+func (m *TestMockDBHandler) GetInventoryValuation(asOf time.Time) ([]models.IngredientValuation, error) {
+	if m.GetInventoryValuationFunc != nil {
+		return m.GetInventoryValuationFunc(asOf)
+	}
+	return nil, nil
+}
+
+func (m *TestMockDBHandler) ConsumeExistence(id string, req models.ConsumeExistenceRequest) (*models.ExistenceMovement, error) {
+	if m.ConsumeExistenceFunc != nil {
+		return m.ConsumeExistenceFunc(id, req)
+	}
+	return nil, nil
+}
+
+func (m *TestMockDBHandler) ReassignExistence(id string, req models.ReassignExistenceRequest) (*models.Existence, error) {
+	if m.ReassignExistenceFunc != nil {
+		return m.ReassignExistenceFunc(id, req)
+	}
+	return nil, nil
+}
+
+func (m *TestMockDBHandler) ListExistenceMovements(ctx context.Context, existenceID string) ([]models.ExistenceMovement, error) {
+	if m.ListExistenceMovementsFunc != nil {
+		return m.ListExistenceMovementsFunc(existenceID)
+	}
+	return nil, nil
+}
+
+func (m *TestMockDBHandler) RepriceIngredientExistences(ingredientID string, costPerUnit float64) ([]models.Existence, error) {
+	if m.RepriceFunc != nil {
+		return m.RepriceFunc(ingredientID, costPerUnit)
+	}
+	return nil, nil
+}
+
+func (m *TestMockDBHandler) ListIngredientCostBatches(ctx context.Context, ingredientID string) ([]models.IngredientCostBatch, error) {
+	if m.ListCostBatchesFunc != nil {
+		return m.ListCostBatchesFunc(ingredientID)
+	}
+	return nil, nil
+}
+
+func (m *TestMockDBHandler) GetExistencesSummary(ctx context.Context, from, to time.Time) (*models.ExistencesSummary, error) {
+	if m.GetSummaryFunc != nil {
+		return m.GetSummaryFunc(from, to)
+	}
+	return &models.ExistencesSummary{}, nil
+}
+
 func setupTestHttpHandler() (*HttpHandler, *TestMockDBHandler) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.PanicLevel) // Suppress logs during testing
@@ -163,6 +260,75 @@ func TestHttpHandler_CreateExistence_InvalidJSON(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, w.Code)
 }
 
+func TestHttpHandler_CreateExistence_UnknownField(t *testing.T) {
+	handler, _ := setupTestHttpHandler()
+
+	// Prepare request with a misspelled field
+	body := `{"ingredient_id":"550e8400-e29b-41d4-a716-446655440000","units_purchased":10,"cost_per_unitt":5}`
+	req := httptest.NewRequest(http.MethodPost, "/existences", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	// Execute
+	handler.CreateExistence(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `unknown field "cost_per_unitt" in request body`)
+}
+
+func TestHttpHandler_CreateExistence_ValidationErrors(t *testing.T) {
+	handler, mockDB := setupTestHttpHandler()
+
+	reqBody := models.CreateExistenceRequest{
+		IngredientID:           "ingredient-id-123",
+		InvoiceDetailID:        "invoice-detail-id-123",
+		UnitsPurchased:         0,
+		UnitsAvailable:         -1,
+		UnitType:               "Liters",
+		ItemsPerUnit:           0,
+		CostPerUnit:            -50,
+		IncomeMarginPercentage: float64Ptr(-1),
+		IvaPercentage:          float64Ptr(101),
+		ServiceTaxPercentage:   float64Ptr(200),
+	}
+
+	// The database must not be reached when validation fails
+	mockDB.CreateExistenceFunc = func(req models.CreateExistenceRequest) (*models.Existence, error) {
+		t.Fatal("CreateExistence should not be called for an invalid request")
+		return nil, nil
+	}
+
+	jsonBody, _ := json.Marshal(reqBody)
+	req := httptest.NewRequest(http.MethodPost, "/existences", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handler.CreateExistence(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response validate.ErrorResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.False(t, response.Success)
+	assert.Equal(t, "Validation failed", response.Error)
+
+	var fields []string
+	for _, violation := range response.Errors {
+		fields = append(fields, violation.Field)
+	}
+	assert.ElementsMatch(t, []string{
+		"units_purchased",
+		"units_available",
+		"cost_per_unit",
+		"items_per_unit",
+		"income_margin_percentage",
+		"iva_percentage",
+		"service_tax_percentage",
+	}, fields)
+}
+
 func TestHttpHandler_CreateExistence_DatabaseError(t *testing.T) {
 	handler, mockDB := setupTestHttpHandler()
 
@@ -194,6 +360,108 @@ func TestHttpHandler_CreateExistence_DatabaseError(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 }
 
+func TestHttpHandler_CreateExistencesBulk_Success(t *testing.T) {
+	handler, mockDB := setupTestHttpHandler()
+
+	reqBody := models.BulkCreateExistencesRequest{
+		{IngredientID: "ingredient-1", InvoiceDetailID: "detail-1", UnitsPurchased: 10, UnitsAvailable: 10, UnitType: "Liters", ItemsPerUnit: 1, CostPerUnit: 1000},
+		{IngredientID: "ingredient-2", InvoiceDetailID: "detail-2", UnitsPurchased: 5, UnitsAvailable: 5, UnitType: "Bag", ItemsPerUnit: 20, CostPerUnit: 4000},
+	}
+
+	mockDB.CreateExistencesFunc = func(reqs models.BulkCreateExistencesRequest) ([]models.Existence, error) {
+		assert.Len(t, reqs, 2)
+		return []models.Existence{
+			{ID: "existence-1", ExistenceReferenceCode: 1001, IngredientID: "ingredient-1"},
+			{ID: "existence-2", ExistenceReferenceCode: 1002, IngredientID: "ingredient-2"},
+		}, nil
+	}
+
+	jsonBody, _ := json.Marshal(reqBody)
+	req := httptest.NewRequest(http.MethodPost, "/existences/bulk", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handler.CreateExistencesBulk(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response models.ExistencesResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.True(t, response.Success)
+	assert.Equal(t, 2, response.Total)
+	assert.Equal(t, "existence-1", response.Data[0].ID)
+	assert.Equal(t, "existence-2", response.Data[1].ID)
+}
+
+func TestHttpHandler_CreateExistencesBulk_ValidationErrors(t *testing.T) {
+	handler, mockDB := setupTestHttpHandler()
+
+	reqBody := models.BulkCreateExistencesRequest{
+		{IngredientID: "ingredient-1", InvoiceDetailID: "detail-1", UnitsPurchased: 10, UnitsAvailable: 10, UnitType: "Liters", ItemsPerUnit: 1, CostPerUnit: 1000},
+		{IngredientID: "ingredient-2", InvoiceDetailID: "detail-2", UnitsPurchased: 0, UnitsAvailable: 5, UnitType: "Bag", ItemsPerUnit: 0, CostPerUnit: 4000},
+	}
+
+	mockDB.CreateExistencesFunc = func(reqs models.BulkCreateExistencesRequest) ([]models.Existence, error) {
+		t.Fatal("CreateExistences should not be called when any item is invalid")
+		return nil, nil
+	}
+
+	jsonBody, _ := json.Marshal(reqBody)
+	req := httptest.NewRequest(http.MethodPost, "/existences/bulk", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handler.CreateExistencesBulk(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response validate.ErrorResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Len(t, response.Errors, 2)
+	for _, violation := range response.Errors {
+		if assert.NotNil(t, violation.Index) {
+			assert.Equal(t, 1, *violation.Index)
+		}
+	}
+}
+
+func TestHttpHandler_CreateExistencesBulk_EmptyBatch(t *testing.T) {
+	handler, _ := setupTestHttpHandler()
+
+	req := httptest.NewRequest(http.MethodPost, "/existences/bulk", bytes.NewBufferString("[]"))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handler.CreateExistencesBulk(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "At least one existence is required")
+}
+
+func TestHttpHandler_CreateExistencesBulk_DatabaseError(t *testing.T) {
+	handler, mockDB := setupTestHttpHandler()
+
+	reqBody := models.BulkCreateExistencesRequest{
+		{IngredientID: "ingredient-1", InvoiceDetailID: "detail-1", UnitsPurchased: 10, UnitsAvailable: 10, UnitType: "Liters", ItemsPerUnit: 1, CostPerUnit: 1000},
+	}
+
+	mockDB.CreateExistencesFunc = func(reqs models.BulkCreateExistencesRequest) ([]models.Existence, error) {
+		return nil, fmt.Errorf("failed to create existence at index 0: database error")
+	}
+
+	jsonBody, _ := json.Marshal(reqBody)
+	req := httptest.NewRequest(http.MethodPost, "/existences/bulk", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handler.CreateExistencesBulk(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "no existences were created")
+}
+
 func TestHttpHandler_GetExistence_Success(t *testing.T) {
 	handler, mockDB := setupTestHttpHandler()
 
@@ -295,6 +563,46 @@ func TestHttpHandler_GetExistence_DatabaseError(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 }
 
+func TestHttpHandler_GetExistenceByCode(t *testing.T) {
+	tests := map[string]struct {
+		code           string
+		dbErr          error
+		expectedStatus int
+	}{
+		"found":          {code: "1001", expectedStatus: http.StatusOK},
+		"not found":      {code: "9999", dbErr: sql.ErrNoRows, expectedStatus: http.StatusNotFound},
+		"invalid code":   {code: "abc", expectedStatus: http.StatusBadRequest},
+		"zero code":      {code: "0", expectedStatus: http.StatusBadRequest},
+		"database error": {code: "1001", dbErr: fmt.Errorf("database error"), expectedStatus: http.StatusInternalServerError},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			handler, mockDB := setupTestHttpHandler()
+			mockDB.GetByReferenceFunc = func(code int) (*models.Existence, error) {
+				if tc.dbErr != nil {
+					return nil, tc.dbErr
+				}
+				return &models.Existence{ID: "existence-id-123", ExistenceReferenceCode: code}, nil
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/existences/by-code/"+tc.code, nil)
+			req = mux.SetURLVars(req, map[string]string{"code": tc.code})
+			w := httptest.NewRecorder()
+
+			handler.GetExistenceByCode(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			if tc.expectedStatus == http.StatusOK {
+				var response models.ExistenceResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, "existence-id-123", response.Data.ID)
+				assert.Equal(t, 1001, response.Data.ExistenceReferenceCode)
+			}
+		})
+	}
+}
+
 func TestHttpHandler_ListExistences_Success(t *testing.T) {
 	handler, mockDB := setupTestHttpHandler()
 
@@ -413,6 +721,140 @@ func TestHttpHandler_ListExistences_WithFilters(t *testing.T) {
 	assert.Equal(t, expectedExistences[0].ID, response.Data[0].ID)
 }
 
+func TestHttpHandler_ListExistences_Pagination(t *testing.T) {
+	testCases := map[string]struct {
+		query           string
+		returned        int
+		total           int
+		expectedLimit   int
+		expectedOffset  int
+		expectedHasMore bool
+	}{
+		"default window with more results": {
+			query:           "",
+			returned:        50,
+			total:           120,
+			expectedLimit:   50,
+			expectedOffset:  0,
+			expectedHasMore: true,
+		},
+		"explicit window with more results": {
+			query:           "?limit=10&offset=20",
+			returned:        10,
+			total:           45,
+			expectedLimit:   10,
+			expectedOffset:  20,
+			expectedHasMore: true,
+		},
+		"last page": {
+			query:           "?limit=10&offset=40",
+			returned:        5,
+			total:           45,
+			expectedLimit:   10,
+			expectedOffset:  40,
+			expectedHasMore: false,
+		},
+		"everything fits in one page": {
+			query:           "?limit=10",
+			returned:        3,
+			total:           3,
+			expectedLimit:   10,
+			expectedOffset:  0,
+			expectedHasMore: false,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler, mockDB := setupTestHttpHandler()
+
+			mockDB.ListExistencesFunc = func(req models.ListExistencesRequest) ([]models.Existence, error) {
+				assert.Equal(t, tc.expectedLimit, *req.Limit)
+				assert.Equal(t, tc.expectedOffset, *req.Offset)
+				return make([]models.Existence, tc.returned), nil
+			}
+			mockDB.CountExistencesFunc = func(req models.ListExistencesRequest) (int, error) {
+				return tc.total, nil
+			}
+
+			w := httptest.NewRecorder()
+			handler.ListExistences(w, httptest.NewRequest(http.MethodGet, "/existences"+tc.query, nil))
+
+			assert.Equal(t, http.StatusOK, w.Code)
+
+			var response models.ExistencesResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			if assert.NotNil(t, response.Pagination) {
+				assert.Equal(t, tc.expectedLimit, response.Pagination.Limit)
+				assert.Equal(t, tc.expectedOffset, response.Pagination.Offset)
+				assert.Equal(t, tc.total, response.Pagination.Total)
+				assert.Equal(t, tc.expectedHasMore, response.Pagination.HasMore)
+			}
+		})
+	}
+}
+
+func TestHttpHandler_ListExistences_MaxPageSize(t *testing.T) {
+	testCases := map[string]struct {
+		maxPageSize     int
+		query           string
+		expectedLimit   int
+		expectedWarning bool
+	}{
+		"within limit":               {maxPageSize: 100, query: "?limit=100", expectedLimit: 100},
+		"over limit is clamped":      {maxPageSize: 100, query: "?limit=101", expectedLimit: 100, expectedWarning: true},
+		"configured smaller maximum": {maxPageSize: 20, query: "?limit=30", expectedLimit: 20, expectedWarning: true},
+		"default limit within cap":   {maxPageSize: 20, query: "", expectedLimit: 20},
+		"zero maximum disables cap":  {maxPageSize: 0, query: "?limit=1000", expectedLimit: 1000},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler, mockDB := setupTestHttpHandler()
+			handler.SetMaxPageSize(tc.maxPageSize)
+
+			mockDB.ListExistencesFunc = func(req models.ListExistencesRequest) ([]models.Existence, error) {
+				assert.Equal(t, tc.expectedLimit, *req.Limit)
+				return []models.Existence{}, nil
+			}
+			mockDB.CountExistencesFunc = func(req models.ListExistencesRequest) (int, error) {
+				return 0, nil
+			}
+
+			w := httptest.NewRecorder()
+			handler.ListExistences(w, httptest.NewRequest(http.MethodGet, "/existences"+tc.query, nil))
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tc.expectedWarning, w.Header().Get(httpx.PageSizeWarningHeader) != "")
+
+			var response models.ExistencesResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			if assert.NotNil(t, response.Pagination) {
+				assert.Equal(t, tc.expectedLimit, response.Pagination.Limit)
+			}
+		})
+	}
+}
+
+func TestHttpHandler_ListExistences_InvalidPagination(t *testing.T) {
+	testCases := map[string]string{
+		"non-numeric limit": "?limit=abc",
+		"zero limit":        "?limit=0",
+		"negative offset":   "?offset=-1",
+	}
+
+	for name, query := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler, _ := setupTestHttpHandler()
+
+			w := httptest.NewRecorder()
+			handler.ListExistences(w, httptest.NewRequest(http.MethodGet, "/existences"+query, nil))
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
 func TestHttpHandler_ListExistences_DatabaseError(t *testing.T) {
 	handler, mockDB := setupTestHttpHandler()
 
@@ -615,3 +1057,677 @@ func TestHttpHandler_DeleteExistence_DatabaseError(t *testing.T) {
 	// Assert
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 }
+
+func TestHttpHandler_DeleteExistence_PublishesEvent(t *testing.T) {
+	handler, mockDB := setupTestHttpHandler()
+
+	existenceID := "existence-id-123"
+
+	// Mock setup
+	mockDB.DeleteExistenceFunc = func(id string) error {
+		return nil
+	}
+
+	bus := eventbus.New(handler.logger)
+	received := make(chan eventbus.Event, 1)
+	bus.Subscribe(events.ExistenceDeleted, func(event eventbus.Event) { received <- event })
+	handler.SetEventBus(bus)
+
+	// Prepare request
+	req := httptest.NewRequest(http.MethodDelete, "/existences/"+existenceID, nil)
+	req = mux.SetURLVars(req, map[string]string{"id": existenceID})
+	w := httptest.NewRecorder()
+
+	// Execute
+	handler.DeleteExistence(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	select {
+	case event := <-received:
+		existenceEvent, ok := event.(events.ExistenceEvent)
+		assert.True(t, ok)
+		assert.Equal(t, existenceID, existenceEvent.ExistenceID)
+		assert.False(t, existenceEvent.OccurredAt().IsZero())
+	case <-time.After(time.Second):
+		t.Fatal("expected an existence.deleted event")
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func TestHttpHandler_GetInventoryValuation_GroupsAndTotals(t *testing.T) {
+	handler, mockDB := setupTestHttpHandler()
+
+	mockDB.GetInventoryValuationFunc = func(asOf time.Time) ([]models.IngredientValuation, error) {
+		return []models.IngredientValuation{
+			{IngredientID: "milk", IngredientName: "Milk", CategoryID: stringPtr("dairy"), CategoryName: stringPtr("Dairy"), BatchCount: 2, RemainingValue: 1500.50},
+			{IngredientID: "cheese", IngredientName: "Cheese", CategoryID: stringPtr("dairy"), CategoryName: stringPtr("Dairy"), BatchCount: 1, RemainingValue: 2000},
+			{IngredientID: "flour", IngredientName: "Flour", CategoryID: stringPtr("grains"), CategoryName: stringPtr("Grains"), BatchCount: 3, RemainingValue: 750},
+			{IngredientID: "salt", IngredientName: "Salt", BatchCount: 1, RemainingValue: 100},
+		}, nil
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/valuation", nil)
+	w := httptest.NewRecorder()
+
+	handler.GetInventoryValuation(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.InventoryValuationResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.True(t, response.Success)
+	assert.Equal(t, time.Now().Format("2006-01-02"), response.Data.AsOf)
+	assert.InDelta(t, 4350.50, response.Data.TotalValue, 0.001)
+	assert.Len(t, response.Data.ByIngredient, 4)
+
+	assert.Len(t, response.Data.ByCategory, 3)
+	assert.Equal(t, "Dairy", response.Data.ByCategory[0].CategoryName)
+	assert.Equal(t, 2, response.Data.ByCategory[0].IngredientCount)
+	assert.InDelta(t, 3500.50, response.Data.ByCategory[0].RemainingValue, 0.001)
+	assert.Equal(t, "Grains", response.Data.ByCategory[1].CategoryName)
+	assert.InDelta(t, 750.0, response.Data.ByCategory[1].RemainingValue, 0.001)
+	assert.Nil(t, response.Data.ByCategory[2].CategoryID)
+	assert.Equal(t, "Uncategorized", response.Data.ByCategory[2].CategoryName)
+	assert.InDelta(t, 100.0, response.Data.ByCategory[2].RemainingValue, 0.001)
+}
+
+func TestHttpHandler_GetInventoryValuation_AsOf(t *testing.T) {
+	handler, mockDB := setupTestHttpHandler()
+
+	var receivedAsOf time.Time
+	mockDB.GetInventoryValuationFunc = func(asOf time.Time) ([]models.IngredientValuation, error) {
+		receivedAsOf = asOf
+		return []models.IngredientValuation{}, nil
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/valuation?as_of=2024-06-30", nil)
+	w := httptest.NewRecorder()
+
+	handler.GetInventoryValuation(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC), receivedAsOf)
+
+	var response models.InventoryValuationResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "2024-06-30", response.Data.AsOf)
+	assert.Equal(t, 0.0, response.Data.TotalValue)
+	assert.Empty(t, response.Data.ByCategory)
+	assert.Empty(t, response.Data.ByIngredient)
+}
+
+func TestHttpHandler_GetInventoryValuation_InvalidAsOf(t *testing.T) {
+	handler, mockDB := setupTestHttpHandler()
+
+	mockDB.GetInventoryValuationFunc = func(asOf time.Time) ([]models.IngredientValuation, error) {
+		t.Fatal("database should not be queried for an invalid as_of date")
+		return nil, nil
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/valuation?as_of=30-06-2024", nil)
+	w := httptest.NewRecorder()
+
+	handler.GetInventoryValuation(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Invalid as_of date")
+}
+
+func TestHttpHandler_GetInventoryValuation_DatabaseError(t *testing.T) {
+	handler, mockDB := setupTestHttpHandler()
+
+	mockDB.GetInventoryValuationFunc = func(asOf time.Time) ([]models.IngredientValuation, error) {
+		return nil, fmt.Errorf("database connection failed")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/valuation", nil)
+	w := httptest.NewRecorder()
+
+	handler.GetInventoryValuation(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "Failed to get inventory valuation")
+}
+
+func TestHttpHandler_ListExpiringExistences_Window(t *testing.T) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	daysFromToday := func(days int) *time.Time {
+		date := today.AddDate(0, 0, days)
+		return &date
+	}
+
+	// Fixtures cover every position relative to a 7 day window
+	fixtures := []models.Existence{
+		{ID: "expired-yesterday", ExpirationDate: daysFromToday(-1)},
+		{ID: "expires-today", ExpirationDate: daysFromToday(0)},
+		{ID: "inside-window", ExpirationDate: daysFromToday(3)},
+		{ID: "on-boundary", ExpirationDate: daysFromToday(7)},
+		{ID: "outside-window", ExpirationDate: daysFromToday(8)},
+		{ID: "no-expiration"},
+	}
+
+	testCases := map[string]struct {
+		query       string
+		expectedIDs []string
+	}{
+		"default window of 7 days includes the boundary": {
+			query:       "",
+			expectedIDs: []string{"expires-today", "inside-window", "on-boundary"},
+		},
+		"explicit 7 day window": {
+			query:       "?within_days=7",
+			expectedIDs: []string{"expires-today", "inside-window", "on-boundary"},
+		},
+		"narrow window excludes later items": {
+			query:       "?within_days=3",
+			expectedIDs: []string{"expires-today", "inside-window"},
+		},
+		"wide window includes items beyond 7 days": {
+			query:       "?within_days=8",
+			expectedIDs: []string{"expires-today", "inside-window", "on-boundary", "outside-window"},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler, mockDB := setupTestHttpHandler()
+
+			// Emulate the inclusive date filter applied by the SQL query
+			mockDB.ListExpiringFunc = func(from, to time.Time) ([]models.Existence, error) {
+				assert.Equal(t, today, from)
+				var matches []models.Existence
+				for _, existence := range fixtures {
+					if existence.ExpirationDate == nil {
+						continue
+					}
+					if !existence.ExpirationDate.Before(from) && !existence.ExpirationDate.After(to) {
+						matches = append(matches, existence)
+					}
+				}
+				return matches, nil
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/existences/expiring"+tc.query, nil)
+			w := httptest.NewRecorder()
+
+			handler.ListExpiringExistences(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+
+			var response models.ExistencesResponse
+			err := json.Unmarshal(w.Body.Bytes(), &response)
+			assert.NoError(t, err)
+
+			var ids []string
+			for _, existence := range response.Data {
+				ids = append(ids, existence.ID)
+			}
+			assert.Equal(t, tc.expectedIDs, ids)
+			assert.Equal(t, len(tc.expectedIDs), response.Total)
+		})
+	}
+}
+
+func TestHttpHandler_ListExpiringExistences_InvalidWithinDays(t *testing.T) {
+	testCases := map[string]struct {
+		query string
+	}{
+		"zero":         {query: "?within_days=0"},
+		"negative":     {query: "?within_days=-3"},
+		"not a number": {query: "?within_days=week"},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler, mockDB := setupTestHttpHandler()
+			mockDB.ListExpiringFunc = func(from, to time.Time) ([]models.Existence, error) {
+				t.Fatal("database should not be queried for an invalid window")
+				return nil, nil
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/existences/expiring"+tc.query, nil)
+			w := httptest.NewRecorder()
+
+			handler.ListExpiringExistences(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(), "within_days must be a positive integer")
+		})
+	}
+}
+
+func TestHttpHandler_ListExpiringExistences_DatabaseError(t *testing.T) {
+	handler, mockDB := setupTestHttpHandler()
+
+	mockDB.ListExpiringFunc = func(from, to time.Time) ([]models.Existence, error) {
+		return nil, fmt.Errorf("database connection failed")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/existences/expiring", nil)
+	w := httptest.NewRecorder()
+
+	handler.ListExpiringExistences(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestHttpHandler_GetExistencesSummary(t *testing.T) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	testCases := map[string]struct {
+		summary        *models.ExistencesSummary
+		dbErr          error
+		expectedStatus int
+	}{
+		"returns every KPI": {
+			summary: &models.ExistencesSummary{
+				TotalExistences:     12,
+				TotalRemainingValue: 45250.75,
+				ExpiringCount:       3,
+				ExpiredCount:        2,
+				LowStockCount:       4,
+			},
+			expectedStatus: http.StatusOK,
+		},
+		"empty inventory": {
+			summary:        &models.ExistencesSummary{},
+			expectedStatus: http.StatusOK,
+		},
+		"database error": {
+			dbErr:          fmt.Errorf("database connection failed"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler, mockDB := setupTestHttpHandler()
+			mockDB.GetSummaryFunc = func(from, to time.Time) (*models.ExistencesSummary, error) {
+				assert.Equal(t, today, from)
+				assert.Equal(t, today.AddDate(0, 0, defaultExpiringWithinDays), to)
+				return tc.summary, tc.dbErr
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/existences/summary", nil)
+			w := httptest.NewRecorder()
+
+			handler.GetExistencesSummary(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			if tc.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var response models.ExistencesSummaryResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.True(t, response.Success)
+			assert.Equal(t, tc.summary.TotalExistences, response.Data.TotalExistences)
+			assert.Equal(t, tc.summary.TotalRemainingValue, response.Data.TotalRemainingValue)
+			assert.Equal(t, tc.summary.ExpiringCount, response.Data.ExpiringCount)
+			assert.Equal(t, tc.summary.ExpiredCount, response.Data.ExpiredCount)
+			assert.Equal(t, tc.summary.LowStockCount, response.Data.LowStockCount)
+			assert.Equal(t, defaultExpiringWithinDays, response.Data.ExpiringWithinDays)
+		})
+	}
+}
+
+func TestHttpHandler_ConsumeExistence_HistoryReflectsMovement(t *testing.T) {
+	handler, mockDB := setupTestHttpHandler()
+
+	existenceID := "existence-id-123"
+	purchasedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	existence := models.Existence{ID: existenceID, UnitsPurchased: 10.0, UnitsAvailable: 10.0}
+	movements := []models.ExistenceMovement{
+		{ID: "movement-1", ExistenceID: existenceID, MovementType: models.MovementTypePurchase, QuantityChange: 10.0, CreatedAt: purchasedAt},
+	}
+
+	// In-memory store: consuming decrements the existence and appends a movement
+	mockDB.GetExistenceByIDFunc = func(id string) (*models.Existence, error) {
+		return &existence, nil
+	}
+	mockDB.ConsumeExistenceFunc = func(id string, req models.ConsumeExistenceRequest) (*models.ExistenceMovement, error) {
+		existence.UnitsAvailable -= req.Units
+		movement := models.ExistenceMovement{
+			ID:             fmt.Sprintf("movement-%d", len(movements)+1),
+			ExistenceID:    id,
+			MovementType:   req.MovementType,
+			QuantityChange: -req.Units,
+			Notes:          req.Notes,
+			CreatedAt:      purchasedAt.Add(time.Duration(len(movements)) * time.Hour),
+		}
+		movements = append(movements, movement)
+		return &movement, nil
+	}
+	mockDB.ListExistenceMovementsFunc = func(id string) ([]models.ExistenceMovement, error) {
+		return movements, nil
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/existences/"+existenceID+"/consume", bytes.NewBufferString(`{"units":2.5}`))
+	req = mux.SetURLVars(req, map[string]string{"id": existenceID})
+	w := httptest.NewRecorder()
+
+	handler.ConsumeExistence(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var consumeResponse models.ExistenceMovementResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &consumeResponse))
+	assert.Equal(t, models.MovementTypeConsumption, consumeResponse.Data.MovementType)
+	assert.Equal(t, -2.5, consumeResponse.Data.QuantityChange)
+	assert.Len(t, movements, 2)
+
+	req = httptest.NewRequest(http.MethodGet, "/existences/"+existenceID+"/history", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": existenceID})
+	w = httptest.NewRecorder()
+
+	handler.GetExistenceHistory(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var historyResponse models.ExistenceHistoryResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &historyResponse))
+	assert.True(t, historyResponse.Success)
+	assert.Equal(t, 7.5, historyResponse.Data.UnitsAvailable)
+	if assert.Len(t, historyResponse.Data.Movements, 2) {
+		assert.Equal(t, models.MovementTypePurchase, historyResponse.Data.Movements[0].MovementType)
+		assert.Equal(t, 10.0, historyResponse.Data.Movements[0].RunningBalance)
+		assert.Equal(t, models.MovementTypeConsumption, historyResponse.Data.Movements[1].MovementType)
+		assert.Equal(t, 7.5, historyResponse.Data.Movements[1].RunningBalance)
+	}
+}
+
+func TestHttpHandler_ConsumeExistence_Errors(t *testing.T) {
+	testCases := map[string]struct {
+		body           string
+		consumeErr     error
+		expectedStatus int
+		expectConsume  bool
+	}{
+		"zero units": {
+			body:           `{"units":0}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		"unknown movement type": {
+			body:           `{"units":1,"movement_type":"purchase"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		"existence not found": {
+			body:           `{"units":1}`,
+			consumeErr:     sql.ErrNoRows,
+			expectedStatus: http.StatusNotFound,
+			expectConsume:  true,
+		},
+		"not enough units": {
+			body:           `{"units":100,"movement_type":"write_off"}`,
+			consumeErr:     models.ErrInsufficientUnits,
+			expectedStatus: http.StatusConflict,
+			expectConsume:  true,
+		},
+		"undefined unit conversion": {
+			body:           `{"units":1,"unit_type":"kg"}`,
+			consumeErr:     fmt.Errorf("%w: kg to Liters", unitConversionModels.ErrUndefinedConversion),
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectConsume:  true,
+		},
+		"database error": {
+			body:           `{"units":1}`,
+			consumeErr:     fmt.Errorf("connection refused"),
+			expectedStatus: http.StatusInternalServerError,
+			expectConsume:  true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler, mockDB := setupTestHttpHandler()
+
+			consumed := false
+			mockDB.ConsumeExistenceFunc = func(id string, req models.ConsumeExistenceRequest) (*models.ExistenceMovement, error) {
+				consumed = true
+				return nil, tc.consumeErr
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/existences/existence-id-123/consume", bytes.NewBufferString(tc.body))
+			req = mux.SetURLVars(req, map[string]string{"id": "existence-id-123"})
+			w := httptest.NewRecorder()
+
+			handler.ConsumeExistence(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Equal(t, tc.expectConsume, consumed)
+		})
+	}
+}
+
+func TestHttpHandler_ReassignExistence(t *testing.T) {
+	targetID := "22222222-2222-2222-2222-222222222222"
+
+	testCases := map[string]struct {
+		body           string
+		reassignErr    error
+		expectedStatus int
+		expectReassign bool
+	}{
+		"valid reassign": {
+			body:           `{"ingredient_id":"` + targetID + `"}`,
+			expectedStatus: http.StatusOK,
+			expectReassign: true,
+		},
+		"missing ingredient_id": {
+			body:           `{}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		"incompatible unit type": {
+			body:           `{"ingredient_id":"` + targetID + `"}`,
+			reassignErr:    models.ErrIncompatibleUnitType,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectReassign: true,
+		},
+		"same ingredient": {
+			body:           `{"ingredient_id":"` + targetID + `"}`,
+			reassignErr:    models.ErrSameIngredient,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectReassign: true,
+		},
+		"target ingredient not found": {
+			body:           `{"ingredient_id":"` + targetID + `"}`,
+			reassignErr:    models.ErrIngredientNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectReassign: true,
+		},
+		"existence not found": {
+			body:           `{"ingredient_id":"` + targetID + `"}`,
+			reassignErr:    sql.ErrNoRows,
+			expectedStatus: http.StatusNotFound,
+			expectReassign: true,
+		},
+		"database error": {
+			body:           `{"ingredient_id":"` + targetID + `"}`,
+			reassignErr:    fmt.Errorf("connection refused"),
+			expectedStatus: http.StatusInternalServerError,
+			expectReassign: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler, mockDB := setupTestHttpHandler()
+
+			reassigned := false
+			mockDB.ReassignExistenceFunc = func(id string, req models.ReassignExistenceRequest) (*models.Existence, error) {
+				reassigned = true
+				if tc.reassignErr != nil {
+					return nil, tc.reassignErr
+				}
+				return &models.Existence{ID: id, IngredientID: req.IngredientID, UnitType: "Liters"}, nil
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/existences/existence-id-123/reassign", bytes.NewBufferString(tc.body))
+			req = mux.SetURLVars(req, map[string]string{"id": "existence-id-123"})
+			w := httptest.NewRecorder()
+
+			handler.ReassignExistence(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Equal(t, tc.expectReassign, reassigned)
+			if tc.expectedStatus == http.StatusOK {
+				var response models.ExistenceResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.True(t, response.Success)
+				assert.Equal(t, targetID, response.Data.IngredientID)
+			}
+		})
+	}
+}
+
+func TestHttpHandler_GetExistenceHistory_NotFound(t *testing.T) {
+	handler, mockDB := setupTestHttpHandler()
+
+	mockDB.GetExistenceByIDFunc = func(id string) (*models.Existence, error) {
+		return nil, sql.ErrNoRows
+	}
+	mockDB.ListExistenceMovementsFunc = func(id string) ([]models.ExistenceMovement, error) {
+		t.Fatal("movements should not be loaded for a missing existence")
+		return nil, nil
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/existences/nonexistent-id/history", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "nonexistent-id"})
+	w := httptest.NewRecorder()
+
+	handler.GetExistenceHistory(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestHttpHandler_RepriceIngredientExistences(t *testing.T) {
+	testCases := map[string]struct {
+		body           string
+		repriceErr     error
+		expectedStatus int
+		expectReprice  bool
+	}{
+		"repriced":       {body: `{"cost_per_unit": 12000}`, expectedStatus: http.StatusOK, expectReprice: true},
+		"zero cost":      {body: `{"cost_per_unit": 0}`, expectedStatus: http.StatusBadRequest},
+		"invalid body":   {body: `{"cost_per_unit": "abc"}`, expectedStatus: http.StatusBadRequest},
+		"database error": {body: `{"cost_per_unit": 12000}`, repriceErr: fmt.Errorf("database error"), expectedStatus: http.StatusInternalServerError, expectReprice: true},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler, mockDB := setupTestHttpHandler()
+
+			repriced := false
+			mockDB.RepriceFunc = func(ingredientID string, costPerUnit float64) ([]models.Existence, error) {
+				repriced = true
+				if tc.repriceErr != nil {
+					return nil, tc.repriceErr
+				}
+				assert.Equal(t, "ingredient-id-123", ingredientID)
+				return []models.Existence{
+					{ID: "existence-1", IngredientID: ingredientID, CostPerUnit: costPerUnit},
+					{ID: "existence-2", IngredientID: ingredientID, CostPerUnit: costPerUnit},
+				}, nil
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/ingredients/ingredient-id-123/reprice", bytes.NewBufferString(tc.body))
+			req = mux.SetURLVars(req, map[string]string{"id": "ingredient-id-123"})
+			w := httptest.NewRecorder()
+
+			handler.RepriceIngredientExistences(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Equal(t, tc.expectReprice, repriced)
+			if tc.expectedStatus == http.StatusOK {
+				var response models.ExistencesResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, 2, response.Total)
+				for _, existence := range response.Data {
+					assert.Equal(t, 12000.0, existence.CostPerUnit)
+				}
+			}
+		})
+	}
+}
+
+func TestHttpHandler_GetIngredientAverageCost(t *testing.T) {
+	testCases := map[string]struct {
+		batches         []models.IngredientCostBatch
+		listErr         error
+		expectedStatus  int
+		expectedAverage *float64
+		expectedUnits   float64
+		expectedBasis   string
+	}{
+		"weighted across batches": {
+			// (10 x 100 + 30 x 200 + 0 x 999) / 40 = 175
+			batches: []models.IngredientCostBatch{
+				{UnitsAvailable: 10, CostPerItem: 100},
+				{UnitsAvailable: 30, CostPerItem: 200},
+				{UnitsAvailable: 0, CostPerItem: 999},
+			},
+			expectedStatus:  http.StatusOK,
+			expectedAverage: float64Ptr(175),
+			expectedUnits:   40,
+			expectedBasis:   models.CostBasisWeightedAverage,
+		},
+		"zero stock falls back to last known cost": {
+			batches: []models.IngredientCostBatch{
+				{UnitsAvailable: 0, CostPerItem: 250},
+				{UnitsAvailable: 0, CostPerItem: 200},
+			},
+			expectedStatus:  http.StatusOK,
+			expectedAverage: float64Ptr(250),
+			expectedBasis:   models.CostBasisLastKnown,
+		},
+		"never stocked": {
+			batches:        []models.IngredientCostBatch{},
+			expectedStatus: http.StatusOK,
+			expectedBasis:  models.CostBasisNone,
+		},
+		"ingredient not found": {
+			listErr:        sql.ErrNoRows,
+			expectedStatus: http.StatusNotFound,
+		},
+		"database error": {
+			listErr:        fmt.Errorf("database error"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler, mockDB := setupTestHttpHandler()
+			mockDB.ListCostBatchesFunc = func(ingredientID string) ([]models.IngredientCostBatch, error) {
+				assert.Equal(t, "ingredient-id-123", ingredientID)
+				return tc.batches, tc.listErr
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/ingredients/ingredient-id-123/avg-cost", nil)
+			req = mux.SetURLVars(req, map[string]string{"id": "ingredient-id-123"})
+			w := httptest.NewRecorder()
+
+			handler.GetIngredientAverageCost(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			if tc.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var response models.IngredientAverageCostResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.True(t, response.Success)
+			assert.Equal(t, "ingredient-id-123", response.Data.IngredientID)
+			assert.Equal(t, tc.expectedAverage, response.Data.AverageCostPerItem)
+			assert.Equal(t, tc.expectedUnits, response.Data.UnitsAvailable)
+			assert.Equal(t, tc.expectedBasis, response.Data.Basis)
+			assert.Equal(t, len(tc.batches), response.Data.BatchCount)
+		})
+	}
+}
@@ -1,7 +1,15 @@
 package models
 
 import (
+	"errors"
+	"math"
+	"strings"
 	"time"
+
+	unitConversionModels "inventory-service/entities/unit_conversions/models"
+	"inventory-service/utils"
+	"shared/pricing"
+	"shared/validate"
 )
 
 // Existence represents a specific ingredient purchase/acquisition batch
@@ -47,6 +55,49 @@ type CreateExistenceRequest struct {
 	FinalPrice             *float64   `json:"final_price,omitempty" validate:"omitempty,min=0"`
 }
 
+// Validate checks the create request and returns every violation found, or nil if it is valid
+func (req *CreateExistenceRequest) Validate() []ValidationError {
+	return validate.All(validate.Validate(req))
+}
+
+// Rules registers the create request's field rules
+func (req *CreateExistenceRequest) Rules(v *validate.Validator) {
+	v.Check(req.UnitsPurchased > 0, "units_purchased", "units purchased must be greater than 0")
+	v.Check(req.UnitsAvailable >= 0, "units_available", "units available cannot be negative")
+	v.Check(req.CostPerUnit >= 0, "cost_per_unit", "cost per unit cannot be negative")
+	v.Check(req.ItemsPerUnit >= 1, "items_per_unit", "items per unit must be at least 1")
+
+	percentages := []struct {
+		field string
+		value *float64
+	}{
+		{"income_margin_percentage", req.IncomeMarginPercentage},
+		{"iva_percentage", req.IvaPercentage},
+		{"service_tax_percentage", req.ServiceTaxPercentage},
+	}
+	for _, percentage := range percentages {
+		v.Check(percentage.value == nil || (*percentage.value >= 0 && *percentage.value <= 100), percentage.field, "percentage must be between 0 and 100")
+	}
+}
+
+// BulkCreateExistencesRequest represents a batch of existences to create atomically
+type BulkCreateExistencesRequest []CreateExistenceRequest
+
+// Validate checks every item in the batch and returns all violations tagged with the item index
+func (req BulkCreateExistencesRequest) Validate() []ValidationError {
+	var violations []ValidationError
+
+	for i := range req {
+		for _, violation := range req[i].Validate() {
+			index := i
+			violation.Index = &index
+			violations = append(violations, violation)
+		}
+	}
+
+	return violations
+}
+
 // UpdateExistenceRequest represents the request to update an existence
 type UpdateExistenceRequest struct {
 	UnitsAvailable         *float64   `json:"units_available,omitempty" validate:"omitempty,min=0"`
@@ -80,6 +131,242 @@ type ListExistencesRequest struct {
 	Offset       *int    `json:"offset,omitempty" validate:"omitempty,min=0"`
 }
 
+// IngredientValuation represents the stock value held for a single ingredient
+type IngredientValuation struct {
+	IngredientID   string  `json:"ingredient_id" db:"ingredient_id"`
+	IngredientName string  `json:"ingredient_name" db:"ingredient_name"`
+	CategoryID     *string `json:"category_id" db:"category_id"`
+	CategoryName   *string `json:"category_name" db:"category_name"`
+	BatchCount     int     `json:"batch_count" db:"batch_count"`
+	RemainingValue float64 `json:"remaining_value" db:"remaining_value"`
+}
+
+// CategoryValuation represents the stock value held for an ingredient category
+type CategoryValuation struct {
+	CategoryID      *string `json:"category_id"`
+	CategoryName    string  `json:"category_name"`
+	IngredientCount int     `json:"ingredient_count"`
+	RemainingValue  float64 `json:"remaining_value"`
+}
+
+// InventoryValuation represents the value of stock on hand at a given date
+type InventoryValuation struct {
+	AsOf         string                `json:"as_of"`
+	TotalValue   float64               `json:"total_value"`
+	ByCategory   []CategoryValuation   `json:"by_category"`
+	ByIngredient []IngredientValuation `json:"by_ingredient"`
+}
+
+// ExistencesSummary holds the stock KPIs shown on the dashboard. Expiring counts existences
+// expiring from today through ExpiringWithinDays; low stock uses the same 10% of units purchased
+// threshold as the low_stock list filter
+type ExistencesSummary struct {
+	TotalExistences     int     `json:"total_existences"`
+	TotalRemainingValue float64 `json:"total_remaining_value"`
+	ExpiringWithinDays  int     `json:"expiring_within_days"`
+	ExpiringCount       int     `json:"expiring_count"`
+	ExpiredCount        int     `json:"expired_count"`
+	LowStockCount       int     `json:"low_stock_count"`
+}
+
+// Bases for an ingredient's average cost
+const (
+	CostBasisWeightedAverage = "weighted_average"
+	CostBasisLastKnown       = "last_known"
+	CostBasisNone            = "none"
+)
+
+// IngredientCostBatch is the stock and unit cost of one existence of an ingredient
+type IngredientCostBatch struct {
+	UnitsAvailable float64 `json:"units_available" db:"units_available"`
+	CostPerItem    float64 `json:"cost_per_item" db:"cost_per_item"`
+}
+
+// IngredientAverageCost represents an ingredient's cost per item averaged over its batches in stock
+type IngredientAverageCost struct {
+	IngredientID       string   `json:"ingredient_id"`
+	AverageCostPerItem *float64 `json:"average_cost_per_item"`
+	UnitsAvailable     float64  `json:"units_available"`
+	BatchCount         int      `json:"batch_count"`
+	Basis              string   `json:"basis"`
+}
+
+// CalculateAverageCost weights each batch's cost per item by its units available. Without stock on hand
+// it falls back to the cost of the most recent batch, and to no cost when the ingredient was never stocked.
+// Batches must be ordered newest first.
+func CalculateAverageCost(ingredientID string, batches []IngredientCostBatch) IngredientAverageCost {
+	result := IngredientAverageCost{
+		IngredientID: ingredientID,
+		BatchCount:   len(batches),
+		Basis:        CostBasisNone,
+	}
+
+	weightedCost := 0.0
+	for _, batch := range batches {
+		if batch.UnitsAvailable <= 0 {
+			continue
+		}
+		weightedCost += batch.UnitsAvailable * batch.CostPerItem
+		result.UnitsAvailable += batch.UnitsAvailable
+	}
+
+	switch {
+	case result.UnitsAvailable > 0:
+		average := roundCurrency(weightedCost / result.UnitsAvailable)
+		result.AverageCostPerItem = &average
+		result.Basis = CostBasisWeightedAverage
+	case len(batches) > 0:
+		lastKnown := batches[0].CostPerItem
+		result.AverageCostPerItem = &lastKnown
+		result.Basis = CostBasisLastKnown
+	}
+
+	return result
+}
+
+// Movement types recorded in an existence's history
+const (
+	MovementTypePurchase     = "purchase"
+	MovementTypeConsumption  = "consumption"
+	MovementTypeWriteOff     = "write_off"
+	MovementTypeAdjustment   = "adjustment"
+	MovementTypeReassignment = "reassignment"
+)
+
+// ErrInsufficientUnits is returned when a consumption exceeds the units available
+var ErrInsufficientUnits = errors.New("insufficient units available")
+
+// Errors returned when an existence cannot be reassigned to another ingredient
+var (
+	ErrIngredientNotFound   = errors.New("target ingredient not found")
+	ErrSameIngredient       = errors.New("existence already belongs to the target ingredient")
+	ErrIncompatibleUnitType = errors.New("unit type is not compatible with the target ingredient")
+)
+
+// ExistenceMovement represents a single change to an existence's units_available
+type ExistenceMovement struct {
+	ID             string    `json:"id" db:"id"`
+	ExistenceID    string    `json:"existence_id" db:"existence_id"`
+	MovementType   string    `json:"movement_type" db:"movement_type"`
+	QuantityChange float64   `json:"quantity_change" db:"quantity_change"`
+	Notes          *string   `json:"notes" db:"notes"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+}
+
+// ExistenceHistoryEntry is a movement together with the units available right after it
+type ExistenceHistoryEntry struct {
+	ExistenceMovement
+	RunningBalance float64 `json:"running_balance"`
+}
+
+// ExistenceHistory represents the chronological movements of an existence
+type ExistenceHistory struct {
+	ExistenceID    string                  `json:"existence_id"`
+	UnitsAvailable float64                 `json:"units_available"`
+	Movements      []ExistenceHistoryEntry `json:"movements"`
+}
+
+// ConsumeExistenceRequest represents units taken out of an existence
+type ConsumeExistenceRequest struct {
+	Units        float64 `json:"units" validate:"required,gt=0"`
+	UnitType     *string `json:"unit_type,omitempty"`                                                      // defaults to the existence's unit type
+	MovementType string  `json:"movement_type,omitempty" validate:"omitempty,oneof=consumption write_off"` // defaults to consumption
+	Notes        *string `json:"notes,omitempty"`
+}
+
+// Validate checks the consume request, defaulting the movement type to consumption
+func (req *ConsumeExistenceRequest) Validate() []ValidationError {
+	if req.MovementType == "" {
+		req.MovementType = MovementTypeConsumption
+	}
+	return validate.All(validate.Validate(req))
+}
+
+// Rules registers the consume request's field rules
+func (req *ConsumeExistenceRequest) Rules(v *validate.Validator) {
+	v.Check(req.Units > 0, "units", "units must be greater than 0")
+	v.Check(req.UnitType == nil || strings.TrimSpace(*req.UnitType) != "", "unit_type", "unit_type must not be blank")
+	v.Check(req.MovementType == MovementTypeConsumption || req.MovementType == MovementTypeWriteOff,
+		"movement_type", "movement type must be consumption or write_off")
+}
+
+// ReassignExistenceRequest moves an existence logged against the wrong ingredient to another one
+type ReassignExistenceRequest struct {
+	IngredientID string  `json:"ingredient_id" validate:"required,uuid"`
+	Notes        *string `json:"notes,omitempty"`
+}
+
+// Validate checks the reassign request and returns every violation found, or nil if it is valid
+func (req *ReassignExistenceRequest) Validate() []ValidationError {
+	return validate.All(validate.Validate(req))
+}
+
+// Rules registers the reassign request's field rules
+func (req *ReassignExistenceRequest) Rules(v *validate.Validator) {
+	v.Check(strings.TrimSpace(req.IngredientID) != "", "ingredient_id", "ingredient_id is required")
+}
+
+// UnitTypeCompatible reports whether stock kept in unitType can be moved to an ingredient stocked in
+// stockedUnits. An ingredient without stock accepts any unit; otherwise unitType must match one of the
+// stocked units or convert to it, through a standard conversion or one of the ingredient's own factors
+func UnitTypeCompatible(unitType string, stockedUnits []string, conversions []unitConversionModels.UnitConversion) bool {
+	if len(stockedUnits) == 0 {
+		return true
+	}
+	for _, stocked := range stockedUnits {
+		if _, err := unitConversionModels.ConvertStandardUnits(1, unitType, stocked); err == nil {
+			return true
+		}
+		if _, err := unitConversionModels.ApplyConversions(1, unitType, stocked, conversions); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// RepriceIngredientRequest represents a new cost per unit applied to every existence of an ingredient
+type RepriceIngredientRequest struct {
+	CostPerUnit float64 `json:"cost_per_unit" validate:"required,min=0.01"`
+}
+
+// Validate checks the reprice request and returns every violation found, or nil if it is valid
+func (req *RepriceIngredientRequest) Validate() []ValidationError {
+	return validate.All(validate.Validate(req))
+}
+
+// Rules registers the reprice request's field rules
+func (req *RepriceIngredientRequest) Rules(v *validate.Validator) {
+	v.Check(req.CostPerUnit > 0, "cost_per_unit", "cost per unit must be greater than 0")
+}
+
+// Reprice sets a new cost per unit and recomputes every cost and pricing field derived from it.
+// Margin and taxes are priced per item like a newly created existence, and roundPrice turns the
+// calculated price into the final selling price.
+func (e *Existence) Reprice(costPerUnit float64, roundPrice pricing.RoundingStrategy) {
+	e.CostPerUnit = costPerUnit
+	if e.ItemsPerUnit > 0 {
+		e.CostPerItem = roundCurrency(costPerUnit / float64(e.ItemsPerUnit))
+	}
+	e.TotalPurchaseCost = roundCurrency(e.UnitsPurchased * costPerUnit)
+	e.RemainingValue = roundCurrency(e.UnitsAvailable * costPerUnit)
+
+	price := pricing.CalculateExistencePrice(e.CostPerItem, e.IncomeMarginPercentage, e.IvaPercentage, e.ServiceTaxPercentage)
+	e.IncomeMarginAmount = price.IncomeMarginAmount.Float64()
+	e.IvaAmount = price.IvaAmount.Float64()
+	e.ServiceTaxAmount = price.ServiceTaxAmount.Float64()
+	e.CalculatedPrice = price.CalculatedPrice.Float64()
+	finalPrice := roundPrice(e.CalculatedPrice)
+	e.FinalPrice = &finalPrice
+}
+
+// roundCurrency rounds an amount to the two decimals stored by the database
+func roundCurrency(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// ValidationError represents a single invalid field in a request
+type ValidationError = validate.ValidationError
+
 // Response Structs
 // ExistenceResponse represents a single existence response
 type ExistenceResponse struct {
@@ -90,10 +377,25 @@ type ExistenceResponse struct {
 
 // ExistencesResponse represents multiple existences response
 type ExistencesResponse struct {
-	Success bool        `json:"success"`
-	Data    []Existence `json:"data"`
-	Total   int         `json:"total,omitempty"`
-	Message string      `json:"message,omitempty"`
+	Success    bool              `json:"success"`
+	Data       []Existence       `json:"data"`
+	Total      int               `json:"total,omitempty"`
+	Pagination *utils.Pagination `json:"pagination,omitempty"`
+	Message    string            `json:"message,omitempty"`
+}
+
+// ExistenceMovementResponse represents a single existence movement response
+type ExistenceMovementResponse struct {
+	Success bool              `json:"success"`
+	Data    ExistenceMovement `json:"data"`
+	Message string            `json:"message,omitempty"`
+}
+
+// ExistenceHistoryResponse represents an existence movement history response
+type ExistenceHistoryResponse struct {
+	Success bool             `json:"success"`
+	Data    ExistenceHistory `json:"data"`
+	Message string           `json:"message,omitempty"`
 }
 
 // GenericResponse represents a generic response (for delete operations)
@@ -101,3 +403,24 @@ type GenericResponse struct {
 	Success bool   `json:"success"`
 	Message string `json:"message"`
 }
+
+// InventoryValuationResponse represents the inventory valuation report response
+type InventoryValuationResponse struct {
+	Success bool               `json:"success"`
+	Data    InventoryValuation `json:"data"`
+	Message string             `json:"message,omitempty"`
+}
+
+// ExistencesSummaryResponse represents the dashboard stock summary response
+type ExistencesSummaryResponse struct {
+	Success bool              `json:"success"`
+	Data    ExistencesSummary `json:"data"`
+	Message string            `json:"message,omitempty"`
+}
+
+// IngredientAverageCostResponse represents an ingredient average cost response
+type IngredientAverageCostResponse struct {
+	Success bool                  `json:"success"`
+	Data    IngredientAverageCost `json:"data"`
+	Message string                `json:"message,omitempty"`
+}
@@ -0,0 +1,374 @@
+package models
+
+import (
+	"testing"
+
+	unitConversionModels "inventory-service/entities/unit_conversions/models"
+	"shared/pricing"
+	"shared/validate"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func float64Ptr(f float64) *float64 {
+	return &f
+}
+
+func TestCreateExistenceRequest_Validate(t *testing.T) {
+	validRequest := func() CreateExistenceRequest {
+		return CreateExistenceRequest{
+			IngredientID:           "ingredient-id-123",
+			InvoiceDetailID:        "invoice-detail-id-123",
+			UnitsPurchased:         10,
+			UnitsAvailable:         10,
+			UnitType:               "Liters",
+			ItemsPerUnit:           31,
+			CostPerUnit:            12000,
+			IncomeMarginPercentage: float64Ptr(30),
+			IvaPercentage:          float64Ptr(13),
+			ServiceTaxPercentage:   float64Ptr(10),
+		}
+	}
+
+	testCases := map[string]struct {
+		modify         func(req *CreateExistenceRequest)
+		expectedFields []string
+	}{
+		"valid request": {
+			modify:         func(req *CreateExistenceRequest) {},
+			expectedFields: nil,
+		},
+		"omitted percentages use defaults": {
+			modify: func(req *CreateExistenceRequest) {
+				req.IncomeMarginPercentage = nil
+				req.IvaPercentage = nil
+				req.ServiceTaxPercentage = nil
+			},
+			expectedFields: nil,
+		},
+		"boundary values": {
+			modify: func(req *CreateExistenceRequest) {
+				req.UnitsAvailable = 0
+				req.CostPerUnit = 0
+				req.ItemsPerUnit = 1
+				req.IncomeMarginPercentage = float64Ptr(0)
+				req.IvaPercentage = float64Ptr(100)
+			},
+			expectedFields: nil,
+		},
+		"zero units purchased": {
+			modify:         func(req *CreateExistenceRequest) { req.UnitsPurchased = 0 },
+			expectedFields: []string{"units_purchased"},
+		},
+		"negative cost": {
+			modify:         func(req *CreateExistenceRequest) { req.CostPerUnit = -1 },
+			expectedFields: []string{"cost_per_unit"},
+		},
+		"percentage above 100": {
+			modify:         func(req *CreateExistenceRequest) { req.IvaPercentage = float64Ptr(100.5) },
+			expectedFields: []string{"iva_percentage"},
+		},
+		"fully invalid request": {
+			modify: func(req *CreateExistenceRequest) {
+				req.UnitsPurchased = -5
+				req.UnitsAvailable = -1
+				req.CostPerUnit = -100
+				req.ItemsPerUnit = 0
+				req.IncomeMarginPercentage = float64Ptr(-10)
+				req.IvaPercentage = float64Ptr(150)
+				req.ServiceTaxPercentage = float64Ptr(101)
+			},
+			expectedFields: []string{
+				"units_purchased",
+				"units_available",
+				"cost_per_unit",
+				"items_per_unit",
+				"income_margin_percentage",
+				"iva_percentage",
+				"service_tax_percentage",
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			req := validRequest()
+			tc.modify(&req)
+
+			violations := req.Validate()
+
+			var fields []string
+			for _, violation := range violations {
+				assert.NotEmpty(t, violation.Message)
+				fields = append(fields, violation.Field)
+			}
+			assert.Equal(t, tc.expectedFields, fields)
+		})
+	}
+}
+
+func TestCreateExistenceRequest_ValidateAggregatesErrors(t *testing.T) {
+	req := CreateExistenceRequest{UnitsPurchased: 0, UnitsAvailable: -1, ItemsPerUnit: 1, CostPerUnit: 1}
+
+	err := validate.Validate(&req)
+
+	assert.Equal(t, validate.Errors{
+		{Field: "units_purchased", Message: "units purchased must be greater than 0"},
+		{Field: "units_available", Message: "units available cannot be negative"},
+	}, err)
+	assert.Equal(t, "validation error in units_purchased: units purchased must be greater than 0; "+
+		"validation error in units_available: units available cannot be negative", err.Error())
+
+	req.UnitsPurchased, req.UnitsAvailable = 1, 1
+	assert.NoError(t, validate.Validate(&req))
+}
+
+func TestBulkCreateExistencesRequest_Validate(t *testing.T) {
+	valid := CreateExistenceRequest{UnitsPurchased: 1, UnitsAvailable: 1, ItemsPerUnit: 1, CostPerUnit: 1}
+	invalid := CreateExistenceRequest{UnitsPurchased: 0, UnitsAvailable: 1, ItemsPerUnit: 1, CostPerUnit: 1}
+
+	violations := BulkCreateExistencesRequest{valid, invalid, valid, invalid}.Validate()
+
+	var indexes []int
+	for _, violation := range violations {
+		assert.Equal(t, "units_purchased", violation.Field)
+		if assert.NotNil(t, violation.Index) {
+			indexes = append(indexes, *violation.Index)
+		}
+	}
+	assert.Equal(t, []int{1, 3}, indexes)
+	assert.Empty(t, BulkCreateExistencesRequest{valid, valid}.Validate())
+}
+
+func TestConsumeExistenceRequest_Validate(t *testing.T) {
+	blank := " "
+	testCases := map[string]struct {
+		req                  ConsumeExistenceRequest
+		expectedFields       []string
+		expectedMovementType string
+	}{
+		"movement type defaults to consumption": {
+			req:                  ConsumeExistenceRequest{Units: 1},
+			expectedMovementType: MovementTypeConsumption,
+		},
+		"write off": {
+			req:                  ConsumeExistenceRequest{Units: 1, MovementType: MovementTypeWriteOff},
+			expectedMovementType: MovementTypeWriteOff,
+		},
+		"zero units": {
+			req:                  ConsumeExistenceRequest{Units: 0},
+			expectedFields:       []string{"units"},
+			expectedMovementType: MovementTypeConsumption,
+		},
+		"blank unit type": {
+			req:                  ConsumeExistenceRequest{Units: 1, UnitType: &blank},
+			expectedFields:       []string{"unit_type"},
+			expectedMovementType: MovementTypeConsumption,
+		},
+		"every violation at once": {
+			req:                  ConsumeExistenceRequest{Units: -1, UnitType: &blank, MovementType: MovementTypePurchase},
+			expectedFields:       []string{"units", "unit_type", "movement_type"},
+			expectedMovementType: MovementTypePurchase,
+		},
+		"purchase is not a consumption": {
+			req:                  ConsumeExistenceRequest{Units: 1, MovementType: MovementTypePurchase},
+			expectedFields:       []string{"movement_type"},
+			expectedMovementType: MovementTypePurchase,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var fields []string
+			for _, violation := range tc.req.Validate() {
+				fields = append(fields, violation.Field)
+			}
+			assert.Equal(t, tc.expectedFields, fields)
+			assert.Equal(t, tc.expectedMovementType, tc.req.MovementType)
+		})
+	}
+}
+
+func TestExistence_Reprice(t *testing.T) {
+	existence := Existence{
+		UnitsPurchased:         10,
+		UnitsAvailable:         8.5,
+		ItemsPerUnit:           31,
+		CostPerUnit:            10000,
+		IncomeMarginPercentage: 30,
+		IvaPercentage:          13,
+		ServiceTaxPercentage:   10,
+	}
+
+	existence.Reprice(12000, pricing.CeilTo100)
+
+	// Margin and taxes apply to the 387.10 cost per item, not to the whole batch
+	assert.Equal(t, 12000.0, existence.CostPerUnit)
+	assert.Equal(t, 387.10, existence.CostPerItem)
+	assert.Equal(t, 120000.0, existence.TotalPurchaseCost)
+	assert.Equal(t, 102000.0, existence.RemainingValue)
+	assert.Equal(t, 116.13, existence.IncomeMarginAmount)
+	assert.Equal(t, 65.42, existence.IvaAmount)
+	assert.Equal(t, 50.32, existence.ServiceTaxAmount)
+	assert.Equal(t, 618.97, existence.CalculatedPrice)
+	require.NotNil(t, existence.FinalPrice)
+	assert.Equal(t, 700.0, *existence.FinalPrice)
+}
+
+func TestExistence_RepriceMatchesNewExistence(t *testing.T) {
+	testCases := map[string]struct {
+		itemsPerUnit int
+		costPerUnit  float64
+		roundPrice   pricing.RoundingStrategy
+	}{
+		"one item per unit":      {itemsPerUnit: 1, costPerUnit: 1850, roundPrice: pricing.CeilTo100},
+		"several items per unit": {itemsPerUnit: 31, costPerUnit: 12000, roundPrice: pricing.RoundToNearest10},
+		"no rounding":            {itemsPerUnit: 4, costPerUnit: 10.05, roundPrice: pricing.NoRounding},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			oldFinalPrice := 100.0
+			existence := Existence{
+				UnitsPurchased:         5,
+				UnitsAvailable:         5,
+				ItemsPerUnit:           tc.itemsPerUnit,
+				CostPerUnit:            1,
+				IncomeMarginPercentage: 30,
+				IvaPercentage:          13,
+				ServiceTaxPercentage:   10,
+				FinalPrice:             &oldFinalPrice,
+			}
+
+			existence.Reprice(tc.costPerUnit, tc.roundPrice)
+
+			// A new existence at the same cost is priced per item, then rounded
+			created := pricing.CalculateExistencePrice(tc.costPerUnit/float64(tc.itemsPerUnit), 30, 13, 10)
+			assert.Equal(t, created.IncomeMarginAmount.Float64(), existence.IncomeMarginAmount)
+			assert.Equal(t, created.IvaAmount.Float64(), existence.IvaAmount)
+			assert.Equal(t, created.ServiceTaxAmount.Float64(), existence.ServiceTaxAmount)
+			assert.Equal(t, created.CalculatedPrice.Float64(), existence.CalculatedPrice)
+			require.NotNil(t, existence.FinalPrice)
+			assert.Equal(t, tc.roundPrice(created.CalculatedPrice.Float64()), *existence.FinalPrice)
+		})
+	}
+}
+
+func TestRepriceIngredientRequest_Validate(t *testing.T) {
+	testCases := map[string]struct {
+		costPerUnit    float64
+		expectedFields []string
+	}{
+		"valid cost":    {costPerUnit: 1500},
+		"zero cost":     {costPerUnit: 0, expectedFields: []string{"cost_per_unit"}},
+		"negative cost": {costPerUnit: -1, expectedFields: []string{"cost_per_unit"}},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			req := RepriceIngredientRequest{CostPerUnit: tc.costPerUnit}
+
+			var fields []string
+			for _, violation := range req.Validate() {
+				fields = append(fields, violation.Field)
+			}
+			assert.Equal(t, tc.expectedFields, fields)
+		})
+	}
+}
+
+func TestCalculateAverageCost(t *testing.T) {
+	testCases := map[string]struct {
+		batches         []IngredientCostBatch
+		expectedAverage *float64
+		expectedUnits   float64
+		expectedBasis   string
+	}{
+		"single batch": {
+			batches:         []IngredientCostBatch{{UnitsAvailable: 4, CostPerItem: 80}},
+			expectedAverage: float64Ptr(80),
+			expectedUnits:   4,
+			expectedBasis:   CostBasisWeightedAverage,
+		},
+		"weighted by units available": {
+			// (2 x 100 + 6 x 120 + 2 x 150) / 10 = 122
+			batches: []IngredientCostBatch{
+				{UnitsAvailable: 2, CostPerItem: 100},
+				{UnitsAvailable: 6, CostPerItem: 120},
+				{UnitsAvailable: 2, CostPerItem: 150},
+			},
+			expectedAverage: float64Ptr(122),
+			expectedUnits:   10,
+			expectedBasis:   CostBasisWeightedAverage,
+		},
+		"empty batches are ignored": {
+			batches: []IngredientCostBatch{
+				{UnitsAvailable: 0, CostPerItem: 500},
+				{UnitsAvailable: 3, CostPerItem: 10},
+				{UnitsAvailable: 1, CostPerItem: 20},
+			},
+			expectedAverage: float64Ptr(12.5),
+			expectedUnits:   4,
+			expectedBasis:   CostBasisWeightedAverage,
+		},
+		"average rounded to cents": {
+			batches: []IngredientCostBatch{
+				{UnitsAvailable: 1, CostPerItem: 10},
+				{UnitsAvailable: 2, CostPerItem: 10.01},
+			},
+			expectedAverage: float64Ptr(10.01),
+			expectedUnits:   3,
+			expectedBasis:   CostBasisWeightedAverage,
+		},
+		"zero stock uses the newest batch": {
+			batches: []IngredientCostBatch{
+				{UnitsAvailable: 0, CostPerItem: 120},
+				{UnitsAvailable: 0, CostPerItem: 90},
+			},
+			expectedAverage: float64Ptr(120),
+			expectedBasis:   CostBasisLastKnown,
+		},
+		"no batches": {
+			expectedBasis: CostBasisNone,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			cost := CalculateAverageCost("ingredient-1", tc.batches)
+
+			assert.Equal(t, "ingredient-1", cost.IngredientID)
+			assert.Equal(t, tc.expectedAverage, cost.AverageCostPerItem)
+			assert.Equal(t, tc.expectedUnits, cost.UnitsAvailable)
+			assert.Equal(t, tc.expectedBasis, cost.Basis)
+			assert.Equal(t, len(tc.batches), cost.BatchCount)
+		})
+	}
+}
+
+func TestUnitTypeCompatible(t *testing.T) {
+	conversions := []unitConversionModels.UnitConversion{
+		{FromUnit: "Bag", ToUnit: "Units", Factor: 24},
+	}
+
+	testCases := map[string]struct {
+		unitType     string
+		stockedUnits []string
+		conversions  []unitConversionModels.UnitConversion
+		expected     bool
+	}{
+		"ingredient without stock accepts any unit": {unitType: "Bag", expected: true},
+		"same unit":                      {unitType: "Liters", stockedUnits: []string{"Liters"}, expected: true},
+		"standard conversion":            {unitType: "Liters", stockedUnits: []string{"Gallons"}, expected: true},
+		"ingredient-specific conversion": {unitType: "Units", stockedUnits: []string{"Bag"}, conversions: conversions, expected: true},
+		"different dimension":            {unitType: "Liters", stockedUnits: []string{"Bag"}, conversions: conversions, expected: false},
+		"no conversion defined":          {unitType: "Units", stockedUnits: []string{"Bag"}, expected: false},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, UnitTypeCompatible(tc.unitType, tc.stockedUnits, tc.conversions))
+		})
+	}
+}
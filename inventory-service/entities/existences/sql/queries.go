@@ -10,11 +10,59 @@ var CreateExistenceQuery string
 //go:embed scripts/get_existence_by_id.sql
 var GetExistenceByIDQuery string
 
+//go:embed scripts/get_existence_by_reference_code.sql
+var GetExistenceByReferenceCodeQuery string
+
 //go:embed scripts/list_existences.sql
 var ListExistencesQuery string
 
+//go:embed scripts/count_existences.sql
+var CountExistencesQuery string
+
 //go:embed scripts/update_existence.sql
 var UpdateExistenceQuery string
 
 //go:embed scripts/delete_existence.sql
 var DeleteExistenceQuery string
+
+//go:embed scripts/get_inventory_valuation.sql
+var GetInventoryValuationQuery string
+
+//go:embed scripts/list_expiring_existences.sql
+var ListExpiringExistencesQuery string
+
+//go:embed scripts/consume_existence.sql
+var ConsumeExistenceQuery string
+
+//go:embed scripts/get_existence_units_available.sql
+var GetExistenceUnitsAvailableQuery string
+
+//go:embed scripts/create_existence_movement.sql
+var CreateExistenceMovementQuery string
+
+//go:embed scripts/list_existence_movements.sql
+var ListExistenceMovementsQuery string
+
+//go:embed scripts/list_ingredient_existences_for_update.sql
+var ListIngredientExistencesForUpdateQuery string
+
+//go:embed scripts/reprice_existence.sql
+var RepriceExistenceQuery string
+
+//go:embed scripts/list_ingredient_cost_batches.sql
+var ListIngredientCostBatchesQuery string
+
+//go:embed scripts/get_existences_summary.sql
+var GetExistencesSummaryQuery string
+
+//go:embed scripts/get_existence_for_update.sql
+var GetExistenceForUpdateQuery string
+
+//go:embed scripts/ingredient_exists.sql
+var IngredientExistsQuery string
+
+//go:embed scripts/list_ingredient_unit_types.sql
+var ListIngredientUnitTypesQuery string
+
+//go:embed scripts/reassign_existence.sql
+var ReassignExistenceQuery string
@@ -0,0 +1,6 @@
+UPDATE existences
+SET units_available = units_available - $2,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+  AND units_available >= $2
+RETURNING units_available;
\ No newline at end of file
@@ -0,0 +1,7 @@
+SELECT COUNT(*)
+FROM existences 
+WHERE 1=1
+    AND ($1::uuid IS NULL OR ingredient_id = $1)
+    AND ($2::varchar IS NULL OR unit_type = $2)
+    AND ($3::boolean IS NULL OR ($3 = true AND expiration_date < CURRENT_DATE) OR ($3 = false AND (expiration_date IS NULL OR expiration_date >= CURRENT_DATE)))
+    AND ($4::boolean IS NULL OR ($4 = true AND units_available <= (units_purchased * 0.1)) OR ($4 = false AND units_available > (units_purchased * 0.1)));
\ No newline at end of file
@@ -0,0 +1,11 @@
+INSERT INTO existence_movements (
+    existence_id,
+    movement_type,
+    quantity_change,
+    notes
+) VALUES (
+    $1,  -- existence_id
+    $2,  -- movement_type
+    $3,  -- quantity_change
+    $4   -- notes
+) RETURNING id, existence_id, movement_type, quantity_change, notes, created_at;
\ No newline at end of file
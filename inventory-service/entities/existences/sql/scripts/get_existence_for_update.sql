@@ -0,0 +1,4 @@
+SELECT ingredient_id, unit_type
+FROM existences
+WHERE id = $1
+FOR UPDATE;
\ No newline at end of file
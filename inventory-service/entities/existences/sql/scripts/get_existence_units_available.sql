@@ -0,0 +1,3 @@
+SELECT units_available
+FROM existences
+WHERE id = $1;
\ No newline at end of file
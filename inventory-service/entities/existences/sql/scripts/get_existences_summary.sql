@@ -0,0 +1,7 @@
+SELECT
+    COUNT(*) AS total_existences,
+    COALESCE(SUM(remaining_value), 0) AS total_remaining_value,
+    COUNT(*) FILTER (WHERE expiration_date >= $1::date AND expiration_date <= $2::date) AS expiring_soon,
+    COUNT(*) FILTER (WHERE expiration_date < $1::date) AS expired,
+    COUNT(*) FILTER (WHERE units_available <= (units_purchased * 0.1)) AS low_stock
+FROM existences;
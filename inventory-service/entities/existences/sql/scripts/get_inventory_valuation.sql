@@ -0,0 +1,14 @@
+SELECT 
+    i.id AS ingredient_id,
+    i.name AS ingredient_name,
+    ic.id AS category_id,
+    ic.name AS category_name,
+    COUNT(e.id) AS batch_count,
+    COALESCE(SUM(e.remaining_value), 0) AS remaining_value
+FROM existences e
+JOIN ingredients i ON i.id = e.ingredient_id
+LEFT JOIN ingredient_categories ic ON ic.id = i.ingredient_category_id
+WHERE e.created_at < ($1::date + INTERVAL '1 day')
+    AND (e.expiration_date IS NULL OR e.expiration_date >= $1::date)
+GROUP BY i.id, i.name, ic.id, ic.name
+ORDER BY ic.name NULLS LAST, i.name;
\ No newline at end of file
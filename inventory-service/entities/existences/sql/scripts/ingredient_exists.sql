@@ -0,0 +1 @@
+SELECT EXISTS (SELECT 1 FROM ingredients WHERE id = $1);
\ No newline at end of file
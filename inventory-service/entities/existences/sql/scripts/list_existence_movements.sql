@@ -0,0 +1,10 @@
+SELECT 
+    id,
+    existence_id,
+    movement_type,
+    quantity_change,
+    notes,
+    created_at
+FROM existence_movements
+WHERE existence_id = $1
+ORDER BY created_at ASC;
\ No newline at end of file
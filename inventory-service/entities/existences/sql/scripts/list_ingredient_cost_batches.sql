@@ -0,0 +1,5 @@
+SELECT e.units_available, e.cost_per_item
+FROM ingredients i
+LEFT JOIN existences e ON e.ingredient_id = i.id
+WHERE i.id = $1
+ORDER BY e.created_at DESC NULLS LAST;
@@ -0,0 +1,16 @@
+UPDATE existences 
+SET 
+    cost_per_unit = $2,
+    income_margin_amount = $3,
+    iva_amount = $4,
+    service_tax_amount = $5,
+    calculated_price = $6,
+    final_price = $7,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING id, existence_reference_code, ingredient_id, invoice_detail_id, 
+          units_purchased, units_available, unit_type, items_per_unit,
+          cost_per_item, cost_per_unit, total_purchase_cost, remaining_value,
+          expiration_date, income_margin_percentage, income_margin_amount,
+          iva_percentage, iva_amount, service_tax_percentage, service_tax_amount,
+          calculated_price, final_price, created_at, updated_at; 
\ No newline at end of file
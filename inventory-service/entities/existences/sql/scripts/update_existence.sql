@@ -1,19 +1,40 @@
-UPDATE existences 
-SET 
-    units_available = COALESCE($2, units_available),
-    unit_type = COALESCE($3, unit_type),
-    items_per_unit = COALESCE($4, items_per_unit),
-    cost_per_unit = COALESCE($5, cost_per_unit),
-    expiration_date = COALESCE($6, expiration_date),
-    income_margin_percentage = COALESCE($7, income_margin_percentage),
-    iva_percentage = COALESCE($8, iva_percentage),
-    service_tax_percentage = COALESCE($9, service_tax_percentage),
-    final_price = COALESCE($10, final_price),
-    updated_at = CURRENT_TIMESTAMP
-WHERE id = $1
-RETURNING id, existence_reference_code, ingredient_id, invoice_detail_id, 
-          units_purchased, units_available, unit_type, items_per_unit,
-          cost_per_item, cost_per_unit, total_purchase_cost, remaining_value,
-          expiration_date, income_margin_percentage, income_margin_amount,
-          iva_percentage, iva_amount, service_tax_percentage, service_tax_amount,
-          calculated_price, final_price, created_at, updated_at; 
\ No newline at end of file
+WITH previous AS (
+    SELECT id, units_available
+    FROM existences
+    WHERE id = $1
+    FOR UPDATE
+), updated AS (
+    UPDATE existences 
+    SET 
+        units_available = COALESCE($2, units_available),
+        unit_type = COALESCE($3, unit_type),
+        items_per_unit = COALESCE($4, items_per_unit),
+        cost_per_unit = COALESCE($5, cost_per_unit),
+        expiration_date = COALESCE($6, expiration_date),
+        income_margin_percentage = COALESCE($7, income_margin_percentage),
+        iva_percentage = COALESCE($8, iva_percentage),
+        service_tax_percentage = COALESCE($9, service_tax_percentage),
+        final_price = COALESCE($10, final_price),
+        updated_at = CURRENT_TIMESTAMP
+    WHERE id = $1
+    RETURNING id, existence_reference_code, ingredient_id, invoice_detail_id, 
+              units_purchased, units_available, unit_type, items_per_unit,
+              cost_per_item, cost_per_unit, total_purchase_cost, remaining_value,
+              expiration_date, income_margin_percentage, income_margin_amount,
+              iva_percentage, iva_amount, service_tax_percentage, service_tax_amount,
+              calculated_price, final_price, created_at, updated_at
+), adjustment AS (
+    -- Manual edits of units_available are recorded as adjustments in the movement history
+    INSERT INTO existence_movements (existence_id, movement_type, quantity_change)
+    SELECT updated.id, 'adjustment', updated.units_available - previous.units_available
+    FROM updated
+    JOIN previous ON previous.id = updated.id
+    WHERE updated.units_available <> previous.units_available
+)
+SELECT id, existence_reference_code, ingredient_id, invoice_detail_id, 
+       units_purchased, units_available, unit_type, items_per_unit,
+       cost_per_item, cost_per_unit, total_purchase_cost, remaining_value,
+       expiration_date, income_margin_percentage, income_margin_amount,
+       iva_percentage, iva_amount, service_tax_percentage, service_tax_amount,
+       calculated_price, final_price, created_at, updated_at
+FROM updated;
\ No newline at end of file
@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 
 	"inventory-service/entities/ingredient_categories/models"
 	ingredientCategorySQL "inventory-service/entities/ingredient_categories/sql"
@@ -9,6 +11,12 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// ErrCategoryInUse is returned when a delete without force targets a category that ingredients still reference
+var ErrCategoryInUse = errors.New("ingredient category is still used by ingredients")
+
+// ErrDefaultCategoryDelete is returned when a forced delete targets the default "Uncategorized" category itself
+var ErrDefaultCategoryDelete = errors.New("cannot force delete the default Uncategorized category")
+
 // DBHandler handles database operations for ingredient categories
 type DBHandler struct {
 	db     *sql.DB
@@ -68,8 +76,8 @@ func (h *DBHandler) GetIngredientCategoryByID(id string) (*models.IngredientCate
 }
 
 // ListIngredientCategories retrieves all ingredient categories from the database
-func (h *DBHandler) ListIngredientCategories() ([]models.IngredientCategory, error) {
-	rows, err := h.db.Query(ingredientCategorySQL.ListIngredientCategoriesQuery)
+func (h *DBHandler) ListIngredientCategories(ctx context.Context) ([]models.IngredientCategory, error) {
+	rows, err := h.db.QueryContext(ctx, ingredientCategorySQL.ListIngredientCategoriesQuery)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to execute ingredient categories list query")
 		return nil, err
@@ -126,32 +134,85 @@ func (h *DBHandler) UpdateIngredientCategory(id string, req models.UpdateIngredi
 	return &category, nil
 }
 
-// DeleteIngredientCategory deletes an ingredient category from the database
-func (h *DBHandler) DeleteIngredientCategory(id string) error {
-	result, err := h.db.Exec(ingredientCategorySQL.DeleteIngredientCategoryQuery, id)
+// DeleteIngredientCategory deletes an ingredient category in a single transaction. The category row is
+// locked before its ingredients are counted, so no ingredient can be assigned to it before the delete.
+// Without force a category still in use is kept and ErrCategoryInUse is returned; with force its
+// ingredients are moved to the default "Uncategorized" category first. It returns the number of
+// ingredients that referenced the category.
+func (h *DBHandler) DeleteIngredientCategory(id string, force bool) (int, error) {
+	tx, err := h.db.Begin()
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to begin transaction for ingredient category delete")
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var lockedID string
+	err = tx.QueryRow(ingredientCategorySQL.LockIngredientCategoryQuery, id).Scan(&lockedID)
+	if err == sql.ErrNoRows {
+		// Don't log as error since "not found" is a normal business case
+		return 0, sql.ErrNoRows
+	}
 	if err != nil {
 		h.logger.WithError(err).WithFields(logrus.Fields{
 			"category_id": id,
-		}).Error("Failed to execute ingredient category delete query")
-		return err
+		}).Error("Failed to lock ingredient category for delete")
+		return 0, err
 	}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
+	var count int
+	if err = tx.QueryRow(ingredientCategorySQL.CountIngredientsInCategoryQuery, id).Scan(&count); err != nil {
 		h.logger.WithError(err).WithFields(logrus.Fields{
 			"category_id": id,
-		}).Error("Failed to get rows affected after delete")
-		return err
+		}).Error("Failed to count ingredients in ingredient category")
+		return 0, err
 	}
 
-	if rowsAffected == 0 {
-		// Don't log as error since "not found" is a normal business case
-		return sql.ErrNoRows
+	if count > 0 && !force {
+		return count, ErrCategoryInUse
+	}
+
+	var uncategorizedID string
+	if count > 0 {
+		// Ensure the default category exists
+		err = tx.QueryRow(ingredientCategorySQL.UpsertUncategorizedCategoryQuery).Scan(&uncategorizedID)
+		if err != nil {
+			h.logger.WithError(err).Error("Failed to ensure Uncategorized ingredient category exists")
+			return count, err
+		}
+
+		if uncategorizedID == id {
+			return count, ErrDefaultCategoryDelete
+		}
+
+		// Reassign ingredients
+		if _, err = tx.Exec(ingredientCategorySQL.ReassignIngredientsCategoryQuery, id, uncategorizedID); err != nil {
+			h.logger.WithError(err).WithFields(logrus.Fields{
+				"category_id": id,
+			}).Error("Failed to reassign ingredients to Uncategorized category")
+			return count, err
+		}
+	}
+
+	// Delete the category; the lock guarantees the row is still there
+	if _, err = tx.Exec(ingredientCategorySQL.DeleteIngredientCategoryQuery, id); err != nil {
+		h.logger.WithError(err).WithFields(logrus.Fields{
+			"category_id": id,
+		}).Error("Failed to execute ingredient category delete query")
+		return count, err
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		h.logger.WithError(err).Error("Failed to commit ingredient category delete transaction")
+		return count, err
 	}
 
 	h.logger.WithFields(logrus.Fields{
-		"category_id": id,
+		"category_id":            id,
+		"uncategorized_id":       uncategorizedID,
+		"reassigned_ingredients": count,
 	}).Info("Ingredient category deleted successfully")
 
-	return nil
+	return count, nil
 }
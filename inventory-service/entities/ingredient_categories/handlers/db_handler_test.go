@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"testing"
 
@@ -238,7 +239,7 @@ func TestListIngredientCategories(t *testing.T) {
 			tc.setupMock(mock)
 
 			// Execute
-			results, err := handler.ListIngredientCategories()
+			results, err := handler.ListIngredientCategories(context.Background())
 
 			// Assert
 			if tc.expectedError {
@@ -333,26 +334,128 @@ func TestUpdateIngredientCategory(t *testing.T) {
 func TestDeleteIngredientCategory(t *testing.T) {
 	testCases := map[string]struct {
 		categoryID    string
+		force         bool
 		setupMock     func(sqlmock.Sqlmock)
-		expectedError bool
+		expectedError error
+		expectedCount int
 	}{
 		"successful_delete": {
 			categoryID: "category-123",
 			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT id FROM ingredient_categories WHERE id = \\$1 FOR UPDATE").
+					WithArgs("category-123").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("category-123"))
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM ingredients").
+					WithArgs("category-123").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
 				mock.ExpectExec("DELETE FROM ingredient_categories WHERE id").
 					WithArgs("category-123").
 					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
 			},
-			expectedError: false,
 		},
 		"category_not_found": {
 			categoryID: "nonexistent-id",
 			setupMock: func(mock sqlmock.Sqlmock) {
-				mock.ExpectExec("DELETE FROM ingredient_categories WHERE id").
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT id FROM ingredient_categories WHERE id = \\$1 FOR UPDATE").
 					WithArgs("nonexistent-id").
-					WillReturnResult(sqlmock.NewResult(0, 0))
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				mock.ExpectRollback()
 			},
-			expectedError: true,
+			expectedError: sql.ErrNoRows,
+		},
+		"category_in_use_without_force": {
+			categoryID: "category-123",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT id FROM ingredient_categories WHERE id = \\$1 FOR UPDATE").
+					WithArgs("category-123").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("category-123"))
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM ingredients").
+					WithArgs("category-123").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+				mock.ExpectRollback()
+			},
+			expectedError: ErrCategoryInUse,
+			expectedCount: 3,
+		},
+		"successful_reassign_and_delete": {
+			categoryID: "category-123",
+			force:      true,
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT id FROM ingredient_categories WHERE id = \\$1 FOR UPDATE").
+					WithArgs("category-123").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("category-123"))
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM ingredients").
+					WithArgs("category-123").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(4))
+				mock.ExpectQuery("INSERT INTO ingredient_categories").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("uncategorized-id"))
+				mock.ExpectExec("UPDATE ingredients").
+					WithArgs("category-123", "uncategorized-id").
+					WillReturnResult(sqlmock.NewResult(0, 4))
+				mock.ExpectExec("DELETE FROM ingredient_categories WHERE id").
+					WithArgs("category-123").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedCount: 4,
+		},
+		"default_category_cannot_be_force_deleted": {
+			categoryID: "uncategorized-id",
+			force:      true,
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT id FROM ingredient_categories WHERE id = \\$1 FOR UPDATE").
+					WithArgs("uncategorized-id").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("uncategorized-id"))
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM ingredients").
+					WithArgs("uncategorized-id").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+				mock.ExpectQuery("INSERT INTO ingredient_categories").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("uncategorized-id"))
+				mock.ExpectRollback()
+			},
+			expectedError: ErrDefaultCategoryDelete,
+			expectedCount: 2,
+		},
+		"reassign_error_rolls_back": {
+			categoryID: "category-123",
+			force:      true,
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT id FROM ingredient_categories WHERE id = \\$1 FOR UPDATE").
+					WithArgs("category-123").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("category-123"))
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM ingredients").
+					WithArgs("category-123").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(4))
+				mock.ExpectQuery("INSERT INTO ingredient_categories").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("uncategorized-id"))
+				mock.ExpectExec("UPDATE ingredients").
+					WithArgs("category-123", "uncategorized-id").
+					WillReturnError(sql.ErrConnDone)
+				mock.ExpectRollback()
+			},
+			expectedError: sql.ErrConnDone,
+			expectedCount: 4,
+		},
+		"count_error_rolls_back": {
+			categoryID: "category-123",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT id FROM ingredient_categories WHERE id = \\$1 FOR UPDATE").
+					WithArgs("category-123").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("category-123"))
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM ingredients").
+					WithArgs("category-123").
+					WillReturnError(sql.ErrConnDone)
+				mock.ExpectRollback()
+			},
+			expectedError: sql.ErrConnDone,
 		},
 	}
 
@@ -370,14 +473,15 @@ func TestDeleteIngredientCategory(t *testing.T) {
 			tc.setupMock(mock)
 
 			// Execute
-			err = handler.DeleteIngredientCategory(tc.categoryID)
+			count, err := handler.DeleteIngredientCategory(tc.categoryID, tc.force)
 
 			// Assert
-			if tc.expectedError {
-				assert.Error(t, err)
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
 			} else {
 				assert.NoError(t, err)
 			}
+			assert.Equal(t, tc.expectedCount, count)
 
 			assert.NoError(t, mock.ExpectationsWereMet())
 		})
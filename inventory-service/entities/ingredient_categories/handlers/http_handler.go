@@ -1,11 +1,15 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strconv"
 
 	"inventory-service/entities/ingredient_categories/models"
+	"shared/httpx"
 
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
@@ -15,9 +19,9 @@ import (
 type DBHandlerInterface interface {
 	CreateIngredientCategory(req models.CreateIngredientCategoryRequest) (*models.IngredientCategory, error)
 	GetIngredientCategoryByID(id string) (*models.IngredientCategory, error)
-	ListIngredientCategories() ([]models.IngredientCategory, error)
+	ListIngredientCategories(ctx context.Context) ([]models.IngredientCategory, error)
 	UpdateIngredientCategory(id string, req models.UpdateIngredientCategoryRequest) (*models.IngredientCategory, error)
-	DeleteIngredientCategory(id string) error
+	DeleteIngredientCategory(id string, force bool) (int, error)
 }
 
 // Ensure DBHandler implements DBHandlerInterface
@@ -48,9 +52,9 @@ func NewHttpHandlerWithInterface(dbHandler DBHandlerInterface, logger *logrus.Lo
 // CreateIngredientCategory handles POST /ingredient-categories
 func (h *HttpHandler) CreateIngredientCategory(w http.ResponseWriter, r *http.Request) {
 	var req models.CreateIngredientCategoryRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := httpx.DecodeJSONBody(r, &req); err != nil {
 		h.logger.WithError(err).Error("Invalid JSON in create ingredient category request")
-		h.writeErrorResponse(w, "Invalid JSON format", http.StatusBadRequest)
+		h.writeErrorResponse(w, httpx.DecodeErrorMessage(err, "Invalid JSON format"), http.StatusBadRequest)
 		return
 	}
 
@@ -71,6 +75,7 @@ func (h *HttpHandler) CreateIngredientCategory(w http.ResponseWriter, r *http.Re
 		Data:    *category,
 		Message: "Ingredient category created successfully",
 	}
+	h.logger.WithFields(httpx.RequestLogFields(r)).WithField("ingredient_category_id", category.ID).Info("Ingredient category created successfully")
 	h.writeJSONResponse(w, response, http.StatusCreated)
 }
 
@@ -122,7 +127,7 @@ func (h *HttpHandler) ListIngredientCategories(w http.ResponseWriter, r *http.Re
 	// limit := r.URL.Query().Get("limit")
 	// offset := r.URL.Query().Get("offset")
 
-	categories, err := h.dbHandler.ListIngredientCategories()
+	categories, err := h.dbHandler.ListIngredientCategories(r.Context())
 	if err != nil {
 		// DBHandler already logged the error, don't duplicate
 		response := models.IngredientCategoriesListResponse{
@@ -156,9 +161,9 @@ func (h *HttpHandler) UpdateIngredientCategory(w http.ResponseWriter, r *http.Re
 	}
 
 	var req models.UpdateIngredientCategoryRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := httpx.DecodeJSONBody(r, &req); err != nil {
 		h.logger.WithError(err).Error("Invalid JSON in update ingredient category request")
-		h.writeErrorResponse(w, "Invalid JSON format", http.StatusBadRequest)
+		h.writeErrorResponse(w, httpx.DecodeErrorMessage(err, "Invalid JSON format"), http.StatusBadRequest)
 		return
 	}
 
@@ -190,10 +195,13 @@ func (h *HttpHandler) UpdateIngredientCategory(w http.ResponseWriter, r *http.Re
 		Data:    *category,
 		Message: "Ingredient category updated successfully",
 	}
+	h.logger.WithFields(httpx.RequestLogFields(r)).WithField("ingredient_category_id", category.ID).Info("Ingredient category updated successfully")
 	h.writeJSONResponse(w, response, http.StatusOK)
 }
 
 // DeleteIngredientCategory handles DELETE /ingredient-categories/{id}
+// Deletion is blocked with 409 while ingredients reference the category, unless ?force=true
+// is given, in which case the ingredients are reassigned to the "Uncategorized" category.
 func (h *HttpHandler) DeleteIngredientCategory(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
@@ -204,7 +212,9 @@ func (h *HttpHandler) DeleteIngredientCategory(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	err := h.dbHandler.DeleteIngredientCategory(id)
+	force, _ := strconv.ParseBool(r.URL.Query().Get("force"))
+
+	count, err := h.dbHandler.DeleteIngredientCategory(id, force)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			// This is expected behavior, don't log as error
@@ -216,6 +226,26 @@ func (h *HttpHandler) DeleteIngredientCategory(w http.ResponseWriter, r *http.Re
 			return
 		}
 
+		if err == ErrCategoryInUse {
+			response := models.IngredientCategoryDeleteResponse{
+				Success:         false,
+				Message:         fmt.Sprintf("Ingredient category is used by %d ingredient(s); use force=true to reassign them to Uncategorized", count),
+				IngredientCount: count,
+			}
+			h.writeJSONResponse(w, response, http.StatusConflict)
+			return
+		}
+
+		if err == ErrDefaultCategoryDelete {
+			response := models.IngredientCategoryDeleteResponse{
+				Success:         false,
+				Message:         "Failed to delete ingredient category: " + err.Error(),
+				IngredientCount: count,
+			}
+			h.writeJSONResponse(w, response, http.StatusConflict)
+			return
+		}
+
 		// DBHandler already logged the error, don't duplicate
 		response := models.IngredientCategoryDeleteResponse{
 			Success: false,
@@ -226,9 +256,11 @@ func (h *HttpHandler) DeleteIngredientCategory(w http.ResponseWriter, r *http.Re
 	}
 
 	response := models.IngredientCategoryDeleteResponse{
-		Success: true,
-		Message: "Ingredient category deleted successfully",
+		Success:               true,
+		Message:               "Ingredient category deleted successfully",
+		ReassignedIngredients: count,
 	}
+	h.logger.WithFields(httpx.RequestLogFields(r)).WithField("ingredient_category_id", id).Info("Ingredient category deleted successfully")
 	h.writeJSONResponse(w, response, http.StatusOK)
 }
 
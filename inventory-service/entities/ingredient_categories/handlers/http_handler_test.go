@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"net/http"
@@ -38,7 +39,7 @@ func (m *MockDBHandler) GetIngredientCategoryByID(id string) (*models.Ingredient
 	return args.Get(0).(*models.IngredientCategory), args.Error(1)
 }
 
-func (m *MockDBHandler) ListIngredientCategories() ([]models.IngredientCategory, error) {
+func (m *MockDBHandler) ListIngredientCategories(ctx context.Context) ([]models.IngredientCategory, error) {
 	args := m.Called()
 	return args.Get(0).([]models.IngredientCategory), args.Error(1)
 }
@@ -51,9 +52,9 @@ func (m *MockDBHandler) UpdateIngredientCategory(id string, req models.UpdateIng
 	return args.Get(0).(*models.IngredientCategory), args.Error(1)
 }
 
-func (m *MockDBHandler) DeleteIngredientCategory(id string) error {
-	args := m.Called(id)
-	return args.Error(0)
+func (m *MockDBHandler) DeleteIngredientCategory(id string, force bool) (int, error) {
+	args := m.Called(id, force)
+	return args.Int(0), args.Error(1)
 }
 
 func TestCreateIngredientCategoryHTTP(t *testing.T) {
@@ -438,6 +439,7 @@ func TestUpdateIngredientCategoryHTTP(t *testing.T) {
 func TestDeleteIngredientCategoryHTTP(t *testing.T) {
 	testCases := map[string]struct {
 		categoryID         string
+		query              string
 		mockSetup          func(*MockDBHandler)
 		expectedStatusCode int
 		expectedResponse   interface{}
@@ -445,7 +447,7 @@ func TestDeleteIngredientCategoryHTTP(t *testing.T) {
 		"successful_delete": {
 			categoryID: "category-123",
 			mockSetup: func(mockDB *MockDBHandler) {
-				mockDB.On("DeleteIngredientCategory", "category-123").Return(nil)
+				mockDB.On("DeleteIngredientCategory", "category-123", false).Return(0, nil)
 			},
 			expectedStatusCode: http.StatusOK,
 			expectedResponse: models.IngredientCategoryDeleteResponse{
@@ -456,7 +458,7 @@ func TestDeleteIngredientCategoryHTTP(t *testing.T) {
 		"category_not_found": {
 			categoryID: "nonexistent-id",
 			mockSetup: func(mockDB *MockDBHandler) {
-				mockDB.On("DeleteIngredientCategory", "nonexistent-id").Return(sql.ErrNoRows)
+				mockDB.On("DeleteIngredientCategory", "nonexistent-id", false).Return(0, sql.ErrNoRows)
 			},
 			expectedStatusCode: http.StatusNotFound,
 			expectedResponse: models.IngredientCategoryDeleteResponse{
@@ -464,6 +466,55 @@ func TestDeleteIngredientCategoryHTTP(t *testing.T) {
 				Message: "Ingredient category not found",
 			},
 		},
+		"category_in_use_blocked": {
+			categoryID: "category-123",
+			mockSetup: func(mockDB *MockDBHandler) {
+				mockDB.On("DeleteIngredientCategory", "category-123", false).Return(4, ErrCategoryInUse)
+			},
+			expectedStatusCode: http.StatusConflict,
+			expectedResponse: models.IngredientCategoryDeleteResponse{
+				Success:         false,
+				Message:         "Ingredient category is used by 4 ingredient(s); use force=true to reassign them to Uncategorized",
+				IngredientCount: 4,
+			},
+		},
+		"category_in_use_force_reassigns": {
+			categoryID: "category-123",
+			query:      "?force=true",
+			mockSetup: func(mockDB *MockDBHandler) {
+				mockDB.On("DeleteIngredientCategory", "category-123", true).Return(4, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: models.IngredientCategoryDeleteResponse{
+				Success:               true,
+				Message:               "Ingredient category deleted successfully",
+				ReassignedIngredients: 4,
+			},
+		},
+		"force_delete_of_default_category": {
+			categoryID: "uncategorized-id",
+			query:      "?force=true",
+			mockSetup: func(mockDB *MockDBHandler) {
+				mockDB.On("DeleteIngredientCategory", "uncategorized-id", true).Return(2, ErrDefaultCategoryDelete)
+			},
+			expectedStatusCode: http.StatusConflict,
+			expectedResponse: models.IngredientCategoryDeleteResponse{
+				Success:         false,
+				Message:         "Failed to delete ingredient category: cannot force delete the default Uncategorized category",
+				IngredientCount: 2,
+			},
+		},
+		"database_error": {
+			categoryID: "category-123",
+			mockSetup: func(mockDB *MockDBHandler) {
+				mockDB.On("DeleteIngredientCategory", "category-123", false).Return(0, sql.ErrConnDone)
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedResponse: models.IngredientCategoryDeleteResponse{
+				Success: false,
+				Message: "Failed to delete ingredient category: sql: connection is already closed",
+			},
+		},
 	}
 
 	for name, tc := range testCases {
@@ -478,7 +529,7 @@ func TestDeleteIngredientCategoryHTTP(t *testing.T) {
 			handler := NewHttpHandlerWithInterface(mockDB, logger)
 
 			// Create HTTP request with mux vars
-			req := httptest.NewRequest(http.MethodDelete, "/ingredient-categories/"+tc.categoryID, nil)
+			req := httptest.NewRequest(http.MethodDelete, "/ingredient-categories/"+tc.categoryID+tc.query, nil)
 			req = mux.SetURLVars(req, map[string]string{"id": tc.categoryID})
 			recorder := httptest.NewRecorder()
 
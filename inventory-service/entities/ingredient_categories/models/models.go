@@ -58,8 +58,10 @@ type IngredientCategoriesListResponse struct {
 
 // IngredientCategoryDeleteResponse represents a delete operation response
 type IngredientCategoryDeleteResponse struct {
-	Success bool   `json:"success"`
-	Message string `json:"message"`
+	Success               bool   `json:"success"`
+	Message               string `json:"message"`
+	IngredientCount       int    `json:"ingredient_count,omitempty"`
+	ReassignedIngredients int    `json:"reassigned_ingredients,omitempty"`
 }
 
 // ErrorResponse represents an error response
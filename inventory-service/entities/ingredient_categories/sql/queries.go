@@ -18,3 +18,15 @@ var UpdateIngredientCategoryQuery string
 
 //go:embed scripts/delete_ingredient_category.sql
 var DeleteIngredientCategoryQuery string
+
+//go:embed scripts/lock_ingredient_category.sql
+var LockIngredientCategoryQuery string
+
+//go:embed scripts/count_ingredients_in_category.sql
+var CountIngredientsInCategoryQuery string
+
+//go:embed scripts/upsert_uncategorized_category.sql
+var UpsertUncategorizedCategoryQuery string
+
+//go:embed scripts/reassign_ingredients_category.sql
+var ReassignIngredientsCategoryQuery string
@@ -0,0 +1,3 @@
+SELECT COUNT(*)
+FROM ingredients
+WHERE ingredient_category_id = $1;
\ No newline at end of file
@@ -0,0 +1,4 @@
+SELECT id
+FROM ingredient_categories
+WHERE id = $1
+FOR UPDATE;
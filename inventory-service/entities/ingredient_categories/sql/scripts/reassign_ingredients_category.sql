@@ -0,0 +1,5 @@
+UPDATE ingredients
+SET 
+    ingredient_category_id = $2,
+    updated_at = CURRENT_TIMESTAMP
+WHERE ingredient_category_id = $1;
\ No newline at end of file
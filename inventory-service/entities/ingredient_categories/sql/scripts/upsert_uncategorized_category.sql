@@ -0,0 +1,4 @@
+INSERT INTO ingredient_categories (id, name, description, is_active, created_at, updated_at)
+VALUES (gen_random_uuid(), 'Uncategorized', 'Default category for ingredients whose category was removed', TRUE, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+RETURNING id;
\ No newline at end of file
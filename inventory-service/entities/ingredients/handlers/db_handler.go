@@ -1,11 +1,13 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 
 	"inventory-service/entities/ingredients/models"
 	ingredientSQL "inventory-service/entities/ingredients/sql"
 
+	"github.com/lib/pq"
 	"github.com/sirupsen/logrus"
 )
 
@@ -28,8 +30,8 @@ func (h *DBHandler) CreateIngredient(req models.CreateIngredientRequest) (*model
 	var ingredient models.Ingredient
 
 	err := h.db.QueryRow(ingredientSQL.CreateIngredientQuery,
-		req.Name, req.Description, req.IngredientCategoryID, req.SupplierID).
-		Scan(&ingredient.ID, &ingredient.Name, &ingredient.Description, &ingredient.IngredientCategoryID, &ingredient.SupplierID, &ingredient.CreatedAt, &ingredient.UpdatedAt)
+		req.Name, req.Description, req.IngredientCategoryID, req.SupplierID, req.ReorderPoint, req.ReorderTarget, pq.Array(req.Tags)).
+		Scan(&ingredient.ID, &ingredient.Name, &ingredient.Description, &ingredient.IngredientCategoryID, &ingredient.SupplierID, &ingredient.ReorderPoint, &ingredient.ReorderTarget, &ingredient.CreatedAt, &ingredient.UpdatedAt, pq.Array(&ingredient.Tags))
 
 	if err != nil {
 		h.logger.WithError(err).WithFields(logrus.Fields{
@@ -51,7 +53,7 @@ func (h *DBHandler) GetIngredientByID(id string) (*models.Ingredient, error) {
 	var ingredient models.Ingredient
 
 	err := h.db.QueryRow(ingredientSQL.GetIngredientByIDQuery, id).
-		Scan(&ingredient.ID, &ingredient.Name, &ingredient.Description, &ingredient.IngredientCategoryID, &ingredient.SupplierID, &ingredient.CreatedAt, &ingredient.UpdatedAt)
+		Scan(&ingredient.ID, &ingredient.Name, &ingredient.Description, &ingredient.IngredientCategoryID, &ingredient.SupplierID, &ingredient.ReorderPoint, &ingredient.ReorderTarget, &ingredient.CreatedAt, &ingredient.UpdatedAt, pq.Array(&ingredient.Tags))
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -67,9 +69,9 @@ func (h *DBHandler) GetIngredientByID(id string) (*models.Ingredient, error) {
 	return &ingredient, nil
 }
 
-// ListIngredients retrieves all ingredients from the database
-func (h *DBHandler) ListIngredients() ([]models.Ingredient, error) {
-	rows, err := h.db.Query(ingredientSQL.ListIngredientsQuery)
+// ListIngredients retrieves ingredients from the database, narrowed to one tag when req.Tag is set
+func (h *DBHandler) ListIngredients(ctx context.Context, req models.ListIngredientsRequest) ([]models.Ingredient, error) {
+	rows, err := h.db.QueryContext(ctx, ingredientSQL.ListIngredientsQuery, req.Tag)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to execute ingredients list query")
 		return nil, err
@@ -79,7 +81,7 @@ func (h *DBHandler) ListIngredients() ([]models.Ingredient, error) {
 	var ingredients []models.Ingredient
 	for rows.Next() {
 		var ingredient models.Ingredient
-		err := rows.Scan(&ingredient.ID, &ingredient.Name, &ingredient.Description, &ingredient.IngredientCategoryID, &ingredient.SupplierID, &ingredient.CreatedAt, &ingredient.UpdatedAt)
+		err := rows.Scan(&ingredient.ID, &ingredient.Name, &ingredient.Description, &ingredient.IngredientCategoryID, &ingredient.SupplierID, &ingredient.ReorderPoint, &ingredient.ReorderTarget, &ingredient.CreatedAt, &ingredient.UpdatedAt, pq.Array(&ingredient.Tags))
 		if err != nil {
 			h.logger.WithError(err).Warn("Failed to scan ingredient row, skipping")
 			continue
@@ -104,8 +106,8 @@ func (h *DBHandler) UpdateIngredient(id string, req models.UpdateIngredientReque
 	var ingredient models.Ingredient
 
 	err := h.db.QueryRow(ingredientSQL.UpdateIngredientQuery,
-		id, req.Name, req.Description, req.IngredientCategoryID, req.SupplierID).
-		Scan(&ingredient.ID, &ingredient.Name, &ingredient.Description, &ingredient.IngredientCategoryID, &ingredient.SupplierID, &ingredient.CreatedAt, &ingredient.UpdatedAt)
+		id, req.Name, req.Description, req.IngredientCategoryID, req.SupplierID, req.ReorderPoint, req.ReorderTarget, pq.Array(req.Tags)).
+		Scan(&ingredient.ID, &ingredient.Name, &ingredient.Description, &ingredient.IngredientCategoryID, &ingredient.SupplierID, &ingredient.ReorderPoint, &ingredient.ReorderTarget, &ingredient.CreatedAt, &ingredient.UpdatedAt, pq.Array(&ingredient.Tags))
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -155,3 +157,40 @@ func (h *DBHandler) DeleteIngredient(id string) error {
 
 	return nil
 }
+
+// ListPurchaseSuggestions returns a purchase suggestion for every ingredient whose available,
+// unexpired units are below its reorder point
+func (h *DBHandler) ListPurchaseSuggestions(ctx context.Context) ([]models.PurchaseSuggestion, error) {
+	rows, err := h.db.QueryContext(ctx, ingredientSQL.ListIngredientStockLevelsQuery)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to execute ingredient stock levels query")
+		return nil, err
+	}
+	defer rows.Close()
+
+	suggestions := []models.PurchaseSuggestion{}
+	for rows.Next() {
+		var level models.IngredientStockLevel
+		err := rows.Scan(&level.IngredientID, &level.IngredientName, &level.SupplierID,
+			&level.ReorderPoint, &level.ReorderTarget, &level.UnitsAvailable)
+		if err != nil {
+			h.logger.WithError(err).Warn("Failed to scan ingredient stock level row, skipping")
+			continue
+		}
+
+		if suggestion, ok := models.SuggestPurchase(level); ok {
+			suggestions = append(suggestions, suggestion)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		h.logger.WithError(err).Error("Failed to read ingredient stock levels")
+		return nil, err
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"suggestions_count": len(suggestions),
+	}).Info("Listed purchase suggestions successfully")
+
+	return suggestions, nil
+}
@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"testing"
+	"time"
 
 	"inventory-service/entities/ingredients/models"
 
@@ -27,10 +29,10 @@ func TestCreateIngredient(t *testing.T) {
 				SupplierID:           stringPtr("supplier-123"),
 			},
 			setupMock: func(mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"id", "name", "description", "ingredient_category_id", "supplier_id", "created_at", "updated_at"}).
-					AddRow("ingredient-123", "Vanilla Extract", "Pure vanilla extract for flavoring", "category-123", "supplier-123", "2024-01-01T00:00:00Z", "2024-01-01T00:00:00Z")
+				rows := sqlmock.NewRows([]string{"id", "name", "description", "ingredient_category_id", "supplier_id", "reorder_point", "reorder_target", "created_at", "updated_at", "tags"}).
+					AddRow("ingredient-123", "Vanilla Extract", "Pure vanilla extract for flavoring", "category-123", "supplier-123", nil, nil, "2024-01-01T00:00:00Z", "2024-01-01T00:00:00Z", "{}")
 				mock.ExpectQuery("INSERT INTO ingredients").
-					WithArgs("Vanilla Extract", "Pure vanilla extract for flavoring", "category-123", "supplier-123").
+					WithArgs("Vanilla Extract", "Pure vanilla extract for flavoring", "category-123", "supplier-123", nil, nil, nil).
 					WillReturnRows(rows)
 			},
 			expectedError: false,
@@ -42,6 +44,28 @@ func TestCreateIngredient(t *testing.T) {
 				SupplierID:           stringPtr("supplier-123"),
 				CreatedAt:            "2024-01-01T00:00:00Z",
 				UpdatedAt:            "2024-01-01T00:00:00Z",
+				Tags:                 []string{},
+			},
+		},
+		"successful_creation_with_tags": {
+			request: models.CreateIngredientRequest{
+				Name: "Cream",
+				Tags: []string{"dairy", "nuts"},
+			},
+			setupMock: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "name", "description", "ingredient_category_id", "supplier_id", "reorder_point", "reorder_target", "created_at", "updated_at", "tags"}).
+					AddRow("ingredient-789", "Cream", nil, nil, nil, nil, nil, "2024-01-01T00:00:00Z", "2024-01-01T00:00:00Z", "{dairy,nuts}")
+				mock.ExpectQuery("INSERT INTO ingredients").
+					WithArgs("Cream", nil, nil, nil, nil, nil, `{"dairy","nuts"}`).
+					WillReturnRows(rows)
+			},
+			expectedError: false,
+			expectedResult: &models.Ingredient{
+				ID:        "ingredient-789",
+				Name:      "Cream",
+				CreatedAt: "2024-01-01T00:00:00Z",
+				UpdatedAt: "2024-01-01T00:00:00Z",
+				Tags:      []string{"dairy", "nuts"},
 			},
 		},
 		"successful_creation_without_description_and_category": {
@@ -52,10 +76,10 @@ func TestCreateIngredient(t *testing.T) {
 				SupplierID:           nil,
 			},
 			setupMock: func(mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"id", "name", "description", "ingredient_category_id", "supplier_id", "created_at", "updated_at"}).
-					AddRow("ingredient-456", "Sugar", nil, nil, nil, "2024-01-01T00:00:00Z", "2024-01-01T00:00:00Z")
+				rows := sqlmock.NewRows([]string{"id", "name", "description", "ingredient_category_id", "supplier_id", "reorder_point", "reorder_target", "created_at", "updated_at", "tags"}).
+					AddRow("ingredient-456", "Sugar", nil, nil, nil, nil, nil, "2024-01-01T00:00:00Z", "2024-01-01T00:00:00Z", "{}")
 				mock.ExpectQuery("INSERT INTO ingredients").
-					WithArgs("Sugar", nil, nil, nil).
+					WithArgs("Sugar", nil, nil, nil, nil, nil, nil).
 					WillReturnRows(rows)
 			},
 			expectedError: false,
@@ -67,6 +91,7 @@ func TestCreateIngredient(t *testing.T) {
 				SupplierID:           nil,
 				CreatedAt:            "2024-01-01T00:00:00Z",
 				UpdatedAt:            "2024-01-01T00:00:00Z",
+				Tags:                 []string{},
 			},
 		},
 		"database_error": {
@@ -78,7 +103,7 @@ func TestCreateIngredient(t *testing.T) {
 			},
 			setupMock: func(mock sqlmock.Sqlmock) {
 				mock.ExpectQuery("INSERT INTO ingredients").
-					WithArgs("Test Ingredient", "Test description", "category-789", nil).
+					WithArgs("Test Ingredient", "Test description", "category-789", nil, nil, nil, nil).
 					WillReturnError(sql.ErrConnDone)
 			},
 			expectedError:  true,
@@ -126,9 +151,9 @@ func TestGetIngredientByID(t *testing.T) {
 		"successful_retrieval": {
 			ingredientID: "ingredient-123",
 			setupMock: func(mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"id", "name", "description", "ingredient_category_id", "supplier_id", "created_at", "updated_at"}).
-					AddRow("ingredient-123", "Vanilla Extract", "Pure vanilla extract", "category-123", "supplier-123", "2024-01-01T00:00:00Z", "2024-01-01T00:00:00Z")
-				mock.ExpectQuery("SELECT id, name, description, ingredient_category_id, supplier_id, created_at, updated_at FROM ingredients WHERE id").
+				rows := sqlmock.NewRows([]string{"id", "name", "description", "ingredient_category_id", "supplier_id", "reorder_point", "reorder_target", "created_at", "updated_at", "tags"}).
+					AddRow("ingredient-123", "Vanilla Extract", "Pure vanilla extract", "category-123", "supplier-123", nil, nil, "2024-01-01T00:00:00Z", "2024-01-01T00:00:00Z", "{}")
+				mock.ExpectQuery("SELECT id, name, description, ingredient_category_id, supplier_id, reorder_point, reorder_target, created_at, updated_at, tags FROM ingredients WHERE id").
 					WithArgs("ingredient-123").
 					WillReturnRows(rows)
 			},
@@ -141,12 +166,13 @@ func TestGetIngredientByID(t *testing.T) {
 				SupplierID:           stringPtr("supplier-123"),
 				CreatedAt:            "2024-01-01T00:00:00Z",
 				UpdatedAt:            "2024-01-01T00:00:00Z",
+				Tags:                 []string{},
 			},
 		},
 		"ingredient_not_found": {
 			ingredientID: "nonexistent-id",
 			setupMock: func(mock sqlmock.Sqlmock) {
-				mock.ExpectQuery("SELECT id, name, description, ingredient_category_id, supplier_id, created_at, updated_at FROM ingredients WHERE id").
+				mock.ExpectQuery("SELECT id, name, description, ingredient_category_id, supplier_id, reorder_point, reorder_target, created_at, updated_at, tags FROM ingredients WHERE id").
 					WithArgs("nonexistent-id").
 					WillReturnError(sql.ErrNoRows)
 			},
@@ -193,10 +219,10 @@ func TestListIngredients(t *testing.T) {
 	}{
 		"successful_list": {
 			setupMock: func(mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"id", "name", "description", "ingredient_category_id", "supplier_id", "created_at", "updated_at"}).
-					AddRow("ingredient-1", "Sugar", nil, "category-1", nil, "2024-01-01T00:00:00Z", "2024-01-01T00:00:00Z").
-					AddRow("ingredient-2", "Vanilla", "Pure vanilla extract", "category-2", "supplier-123", "2024-01-01T00:00:00Z", "2024-01-01T00:00:00Z")
-				mock.ExpectQuery("SELECT id, name, description, ingredient_category_id, supplier_id, created_at, updated_at FROM ingredients ORDER BY name").
+				rows := sqlmock.NewRows([]string{"id", "name", "description", "ingredient_category_id", "supplier_id", "reorder_point", "reorder_target", "created_at", "updated_at", "tags"}).
+					AddRow("ingredient-1", "Sugar", nil, "category-1", nil, nil, nil, "2024-01-01T00:00:00Z", "2024-01-01T00:00:00Z", "{}").
+					AddRow("ingredient-2", "Vanilla", "Pure vanilla extract", "category-2", "supplier-123", nil, nil, "2024-01-01T00:00:00Z", "2024-01-01T00:00:00Z", "{}")
+				mock.ExpectQuery("SELECT id, name, description, ingredient_category_id, supplier_id, reorder_point, reorder_target, created_at, updated_at, tags FROM ingredients WHERE (.+) ORDER BY name").
 					WillReturnRows(rows)
 			},
 			expectedError: false,
@@ -209,6 +235,7 @@ func TestListIngredients(t *testing.T) {
 					SupplierID:           nil,
 					CreatedAt:            "2024-01-01T00:00:00Z",
 					UpdatedAt:            "2024-01-01T00:00:00Z",
+					Tags:                 []string{},
 				},
 				{
 					ID:                   "ingredient-2",
@@ -218,13 +245,14 @@ func TestListIngredients(t *testing.T) {
 					SupplierID:           stringPtr("supplier-123"),
 					CreatedAt:            "2024-01-01T00:00:00Z",
 					UpdatedAt:            "2024-01-01T00:00:00Z",
+					Tags:                 []string{},
 				},
 			},
 		},
 		"empty_result": {
 			setupMock: func(mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"id", "name", "description", "ingredient_category_id", "supplier_id", "created_at", "updated_at"})
-				mock.ExpectQuery("SELECT id, name, description, ingredient_category_id, supplier_id, created_at, updated_at FROM ingredients ORDER BY name").
+				rows := sqlmock.NewRows([]string{"id", "name", "description", "ingredient_category_id", "supplier_id", "reorder_point", "reorder_target", "created_at", "updated_at", "tags"})
+				mock.ExpectQuery("SELECT id, name, description, ingredient_category_id, supplier_id, reorder_point, reorder_target, created_at, updated_at, tags FROM ingredients WHERE (.+) ORDER BY name").
 					WillReturnRows(rows)
 			},
 			expectedError:   false,
@@ -246,7 +274,7 @@ func TestListIngredients(t *testing.T) {
 			tc.setupMock(mock)
 
 			// Execute
-			results, err := handler.ListIngredients()
+			results, err := handler.ListIngredients(context.Background(), models.ListIngredientsRequest{})
 
 			// Assert
 			if tc.expectedError {
@@ -261,6 +289,48 @@ func TestListIngredients(t *testing.T) {
 	}
 }
 
+// TestListIngredientsCancelledContext tests that a cancelled context aborts a slow list query instead of waiting for it
+func TestListIngredientsCancelledContext(t *testing.T) {
+	testCases := map[string]struct {
+		cancelAfter time.Duration
+	}{
+		"cancelled_before_query": {cancelAfter: 0},
+		"cancelled_during_query": {cancelAfter: 20 * time.Millisecond},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			logger := logrus.New()
+			logger.SetLevel(logrus.FatalLevel)
+
+			handler := NewDBHandler(db, logger)
+			rows := sqlmock.NewRows([]string{"id", "name", "description", "ingredient_category_id", "supplier_id", "reorder_point", "reorder_target", "created_at", "updated_at", "tags"})
+			mock.ExpectQuery("SELECT (.+) FROM ingredients").
+				WillDelayFor(time.Second).
+				WillReturnRows(rows)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			if tc.cancelAfter == 0 {
+				cancel()
+			} else {
+				time.AfterFunc(tc.cancelAfter, cancel)
+				defer cancel()
+			}
+
+			start := time.Now()
+			results, err := handler.ListIngredients(ctx, models.ListIngredientsRequest{})
+
+			assert.Error(t, err)
+			assert.Nil(t, results)
+			assert.Less(t, time.Since(start), 500*time.Millisecond)
+		})
+	}
+}
+
 func TestUpdateIngredient(t *testing.T) {
 	testCases := map[string]struct {
 		ingredientID   string
@@ -278,10 +348,10 @@ func TestUpdateIngredient(t *testing.T) {
 				SupplierID:           stringPtr("new-supplier-456"),
 			},
 			setupMock: func(mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"id", "name", "description", "ingredient_category_id", "supplier_id", "created_at", "updated_at"}).
-					AddRow("ingredient-123", "Updated Vanilla", "Updated description", "new-category-456", "new-supplier-456", "2024-01-01T00:00:00Z", "2024-01-01T12:00:00Z")
+				rows := sqlmock.NewRows([]string{"id", "name", "description", "ingredient_category_id", "supplier_id", "reorder_point", "reorder_target", "created_at", "updated_at", "tags"}).
+					AddRow("ingredient-123", "Updated Vanilla", "Updated description", "new-category-456", "new-supplier-456", nil, nil, "2024-01-01T00:00:00Z", "2024-01-01T12:00:00Z", "{}")
 				mock.ExpectQuery("UPDATE ingredients SET").
-					WithArgs("ingredient-123", "Updated Vanilla", "Updated description", "new-category-456", "new-supplier-456").
+					WithArgs("ingredient-123", "Updated Vanilla", "Updated description", "new-category-456", "new-supplier-456", nil, nil, nil).
 					WillReturnRows(rows)
 			},
 			expectedError: false,
@@ -293,6 +363,7 @@ func TestUpdateIngredient(t *testing.T) {
 				SupplierID:           stringPtr("new-supplier-456"),
 				CreatedAt:            "2024-01-01T00:00:00Z",
 				UpdatedAt:            "2024-01-01T12:00:00Z",
+				Tags:                 []string{},
 			},
 		},
 		"ingredient_not_found": {
@@ -302,7 +373,7 @@ func TestUpdateIngredient(t *testing.T) {
 			},
 			setupMock: func(mock sqlmock.Sqlmock) {
 				mock.ExpectQuery("UPDATE ingredients SET").
-					WithArgs("nonexistent-id", "Test Name", nil, nil, nil).
+					WithArgs("nonexistent-id", "Test Name", nil, nil, nil, nil, nil, nil).
 					WillReturnError(sql.ErrNoRows)
 			},
 			expectedError:  true,
@@ -394,7 +465,136 @@ func TestDeleteIngredient(t *testing.T) {
 	}
 }
 
+// TestListPurchaseSuggestions tests that only ingredients below their reorder point are suggested,
+// restocking up to the reorder target when one is set
+func TestListPurchaseSuggestions(t *testing.T) {
+	columns := []string{"id", "name", "supplier_id", "reorder_point", "reorder_target", "units_available"}
+
+	testCases := map[string]struct {
+		setupMock      func(sqlmock.Sqlmock)
+		expectedError  bool
+		expectedResult []models.PurchaseSuggestion
+	}{
+		"below_at_and_above_reorder_point": {
+			setupMock: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows(columns).
+					AddRow("ingredient-1", "Cream", "supplier-1", 10.0, nil, 4.0).
+					AddRow("ingredient-2", "Milk", nil, 10.0, 25.0, 4.0).
+					AddRow("ingredient-3", "Sugar", nil, 10.0, nil, 10.0).
+					AddRow("ingredient-4", "Vanilla", nil, 10.0, 25.0, 12.5)
+				mock.ExpectQuery("FROM ingredients i").WillReturnRows(rows)
+			},
+			expectedResult: []models.PurchaseSuggestion{
+				{
+					IngredientID:      "ingredient-1",
+					IngredientName:    "Cream",
+					SupplierID:        stringPtr("supplier-1"),
+					UnitsAvailable:    4,
+					ReorderPoint:      10,
+					SuggestedQuantity: 6,
+				},
+				{
+					IngredientID:      "ingredient-2",
+					IngredientName:    "Milk",
+					UnitsAvailable:    4,
+					ReorderPoint:      10,
+					ReorderTarget:     float64Ptr(25),
+					SuggestedQuantity: 21,
+				},
+			},
+		},
+		"no_stock_at_all": {
+			setupMock: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows(columns).
+					AddRow("ingredient-1", "Cream", nil, 2.5, nil, 0.0)
+				mock.ExpectQuery("FROM ingredients i").WillReturnRows(rows)
+			},
+			expectedResult: []models.PurchaseSuggestion{
+				{
+					IngredientID:      "ingredient-1",
+					IngredientName:    "Cream",
+					UnitsAvailable:    0,
+					ReorderPoint:      2.5,
+					SuggestedQuantity: 2.5,
+				},
+			},
+		},
+		"nothing_to_reorder": {
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("FROM ingredients i").WillReturnRows(sqlmock.NewRows(columns))
+			},
+			expectedResult: []models.PurchaseSuggestion{},
+		},
+		"database_error": {
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("FROM ingredients i").WillReturnError(sql.ErrConnDone)
+			},
+			expectedError: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// Setup
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			logger := logrus.New()
+			logger.SetLevel(logrus.FatalLevel)
+
+			handler := NewDBHandler(db, logger)
+			tc.setupMock(mock)
+
+			// Execute
+			result, err := handler.ListPurchaseSuggestions(context.Background())
+
+			// Assert
+			if tc.expectedError {
+				assert.Error(t, err)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedResult, result)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
 // Helper function to create string pointers
 func stringPtr(s string) *string {
 	return &s
 }
+
+// Helper function to create float64 pointers
+func float64Ptr(f float64) *float64 {
+	return &f
+}
+
+// TestListIngredientsByTag tests that the tag filter is passed to the list query
+func TestListIngredientsByTag(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+	handler := NewDBHandler(db, logger)
+
+	rows := sqlmock.NewRows([]string{"id", "name", "description", "ingredient_category_id", "supplier_id", "reorder_point", "reorder_target", "created_at", "updated_at", "tags"}).
+		AddRow("ingredient-1", "Cream", nil, nil, nil, nil, nil, "2024-01-01T00:00:00Z", "2024-01-01T00:00:00Z", "{dairy}")
+	mock.ExpectQuery("FROM ingredients WHERE (.+) ANY\\(tags\\)").
+		WithArgs("dairy").
+		WillReturnRows(rows)
+
+	tag := "dairy"
+	results, err := handler.ListIngredients(context.Background(), models.ListIngredientsRequest{Tag: &tag})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "Cream", results[0].Name)
+	assert.Equal(t, []string{"dairy"}, results[0].Tags)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
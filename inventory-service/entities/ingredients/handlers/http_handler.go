@@ -1,11 +1,13 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"net/http"
 
 	"inventory-service/entities/ingredients/models"
+	"shared/httpx"
 
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
@@ -15,9 +17,10 @@ import (
 type DBHandlerInterface interface {
 	CreateIngredient(req models.CreateIngredientRequest) (*models.Ingredient, error)
 	GetIngredientByID(id string) (*models.Ingredient, error)
-	ListIngredients() ([]models.Ingredient, error)
+	ListIngredients(ctx context.Context, req models.ListIngredientsRequest) ([]models.Ingredient, error)
 	UpdateIngredient(id string, req models.UpdateIngredientRequest) (*models.Ingredient, error)
 	DeleteIngredient(id string) error
+	ListPurchaseSuggestions(ctx context.Context) ([]models.PurchaseSuggestion, error)
 }
 
 // Ensure DBHandler implements DBHandlerInterface
@@ -48,12 +51,24 @@ func NewHttpHandlerWithInterface(dbHandler DBHandlerInterface, logger *logrus.Lo
 // CreateIngredient handles POST /ingredients
 func (h *HttpHandler) CreateIngredient(w http.ResponseWriter, r *http.Request) {
 	var req models.CreateIngredientRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := httpx.DecodeJSONBody(r, &req); err != nil {
 		h.logger.WithError(err).Error("Invalid JSON in create ingredient request")
-		h.writeErrorResponse(w, "Invalid JSON format", http.StatusBadRequest)
+		h.writeErrorResponse(w, httpx.DecodeErrorMessage(err, "Invalid JSON format"), http.StatusBadRequest)
 		return
 	}
 
+	if err := models.ValidateReorderLevels(req.ReorderPoint, req.ReorderTarget); err != nil {
+		h.writeErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tags, err := models.NormalizeTags(req.Tags)
+	if err != nil {
+		h.writeErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.Tags = tags
+
 	ingredient, err := h.dbHandler.CreateIngredient(req)
 	if err != nil {
 		// DBHandler already logged the error, don't duplicate
@@ -71,6 +86,7 @@ func (h *HttpHandler) CreateIngredient(w http.ResponseWriter, r *http.Request) {
 		Data:    *ingredient,
 		Message: "Ingredient created successfully",
 	}
+	h.logger.WithFields(httpx.RequestLogFields(r)).WithField("ingredient_id", ingredient.ID).Info("Ingredient created successfully")
 	h.writeJSONResponse(w, response, http.StatusCreated)
 }
 
@@ -116,13 +132,23 @@ func (h *HttpHandler) GetIngredient(w http.ResponseWriter, r *http.Request) {
 	h.writeJSONResponse(w, response, http.StatusOK)
 }
 
-// ListIngredients handles GET /ingredients
+// ListIngredients handles GET /ingredients, optionally filtered with ?tag=dairy
 func (h *HttpHandler) ListIngredients(w http.ResponseWriter, r *http.Request) {
 	// TODO: Parse query parameters for pagination when needed
 	// limit := r.URL.Query().Get("limit")
 	// offset := r.URL.Query().Get("offset")
 
-	ingredients, err := h.dbHandler.ListIngredients()
+	req := models.ListIngredientsRequest{}
+	if tagParam := r.URL.Query().Get("tag"); tagParam != "" {
+		tag, err := models.NormalizeTag(tagParam)
+		if err != nil {
+			h.writeErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		req.Tag = &tag
+	}
+
+	ingredients, err := h.dbHandler.ListIngredients(r.Context(), req)
 	if err != nil {
 		// DBHandler already logged the error, don't duplicate
 		response := models.IngredientsListResponse{
@@ -156,11 +182,23 @@ func (h *HttpHandler) UpdateIngredient(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req models.UpdateIngredientRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := httpx.DecodeJSONBody(r, &req); err != nil {
 		h.logger.WithError(err).Error("Invalid JSON in update ingredient request")
-		h.writeErrorResponse(w, "Invalid JSON format", http.StatusBadRequest)
+		h.writeErrorResponse(w, httpx.DecodeErrorMessage(err, "Invalid JSON format"), http.StatusBadRequest)
+		return
+	}
+
+	if err := models.ValidateReorderLevels(req.ReorderPoint, req.ReorderTarget); err != nil {
+		h.writeErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tags, err := models.NormalizeTags(req.Tags)
+	if err != nil {
+		h.writeErrorResponse(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	req.Tags = tags
 
 	ingredient, err := h.dbHandler.UpdateIngredient(id, req)
 	if err != nil {
@@ -190,6 +228,7 @@ func (h *HttpHandler) UpdateIngredient(w http.ResponseWriter, r *http.Request) {
 		Data:    *ingredient,
 		Message: "Ingredient updated successfully",
 	}
+	h.logger.WithFields(httpx.RequestLogFields(r)).WithField("ingredient_id", ingredient.ID).Info("Ingredient updated successfully")
 	h.writeJSONResponse(w, response, http.StatusOK)
 }
 
@@ -229,6 +268,31 @@ func (h *HttpHandler) DeleteIngredient(w http.ResponseWriter, r *http.Request) {
 		Success: true,
 		Message: "Ingredient deleted successfully",
 	}
+	h.logger.WithFields(httpx.RequestLogFields(r)).WithField("ingredient_id", id).Info("Ingredient deleted successfully")
+	h.writeJSONResponse(w, response, http.StatusOK)
+}
+
+// ListPurchaseSuggestions handles GET /purchase-suggestions
+func (h *HttpHandler) ListPurchaseSuggestions(w http.ResponseWriter, r *http.Request) {
+	suggestions, err := h.dbHandler.ListPurchaseSuggestions(r.Context())
+	if err != nil {
+		// DBHandler already logged the error, don't duplicate
+		response := models.PurchaseSuggestionsResponse{
+			Success: false,
+			Data:    []models.PurchaseSuggestion{},
+			Count:   0,
+			Message: "Failed to list purchase suggestions: " + err.Error(),
+		}
+		h.writeJSONResponse(w, response, http.StatusInternalServerError)
+		return
+	}
+
+	response := models.PurchaseSuggestionsResponse{
+		Success: true,
+		Data:    suggestions,
+		Count:   len(suggestions),
+		Message: "Purchase suggestions retrieved successfully",
+	}
 	h.writeJSONResponse(w, response, http.StatusOK)
 }
 
@@ -2,14 +2,17 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"inventory-service/entities/ingredients/models"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
@@ -38,8 +41,8 @@ func (m *MockDBHandler) GetIngredientByID(id string) (*models.Ingredient, error)
 	return args.Get(0).(*models.Ingredient), args.Error(1)
 }
 
-func (m *MockDBHandler) ListIngredients() ([]models.Ingredient, error) {
-	args := m.Called()
+func (m *MockDBHandler) ListIngredients(ctx context.Context, req models.ListIngredientsRequest) ([]models.Ingredient, error) {
+	args := m.Called(req)
 	return args.Get(0).([]models.Ingredient), args.Error(1)
 }
 
@@ -56,6 +59,14 @@ func (m *MockDBHandler) DeleteIngredient(id string) error {
 	return args.Error(0)
 }
 
+func (m *MockDBHandler) ListPurchaseSuggestions(ctx context.Context) ([]models.PurchaseSuggestion, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.PurchaseSuggestion), args.Error(1)
+}
+
 func TestCreateIngredientHTTP(t *testing.T) {
 	testCases := map[string]struct {
 		requestBody        interface{}
@@ -115,6 +126,55 @@ func TestCreateIngredientHTTP(t *testing.T) {
 			},
 			expectedStatusCode: http.StatusInternalServerError,
 		},
+		"reorder_target_below_reorder_point": {
+			requestBody: models.CreateIngredientRequest{
+				Name:          "Cream",
+				ReorderPoint:  float64Ptr(10),
+				ReorderTarget: float64Ptr(5),
+			},
+			mockSetup:          func(mockDB *MockDBHandler) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		"creation_with_tags": {
+			requestBody: models.CreateIngredientRequest{
+				Name: "Cream",
+				Tags: []string{" Dairy", "nuts", "dairy"},
+			},
+			mockSetup: func(mockDB *MockDBHandler) {
+				// Tags reach the database normalized and de-duplicated
+				mockDB.On("CreateIngredient", models.CreateIngredientRequest{
+					Name: "Cream",
+					Tags: []string{"dairy", "nuts"},
+				}).Return(
+					&models.Ingredient{
+						ID:        "ingredient-123",
+						Name:      "Cream",
+						Tags:      []string{"dairy", "nuts"},
+						CreatedAt: "2024-01-01T00:00:00Z",
+						UpdatedAt: "2024-01-01T00:00:00Z",
+					}, nil)
+			},
+			expectedStatusCode: http.StatusCreated,
+			expectedResponse: models.IngredientResponse{
+				Success: true,
+				Data: models.Ingredient{
+					ID:        "ingredient-123",
+					Name:      "Cream",
+					Tags:      []string{"dairy", "nuts"},
+					CreatedAt: "2024-01-01T00:00:00Z",
+					UpdatedAt: "2024-01-01T00:00:00Z",
+				},
+				Message: "Ingredient created successfully",
+			},
+		},
+		"unknown_tag": {
+			requestBody: models.CreateIngredientRequest{
+				Name: "Cream",
+				Tags: []string{"dairy", "delicious"},
+			},
+			mockSetup:          func(mockDB *MockDBHandler) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
 	}
 
 	for name, tc := range testCases {
@@ -253,7 +313,7 @@ func TestListIngredientsHTTP(t *testing.T) {
 	}{
 		"successful_list": {
 			mockSetup: func(mockDB *MockDBHandler) {
-				mockDB.On("ListIngredients").Return([]models.Ingredient{
+				mockDB.On("ListIngredients", models.ListIngredientsRequest{}).Return([]models.Ingredient{
 					{
 						ID:                   "ingredient-1",
 						Name:                 "Sugar",
@@ -303,7 +363,7 @@ func TestListIngredientsHTTP(t *testing.T) {
 		},
 		"empty_list": {
 			mockSetup: func(mockDB *MockDBHandler) {
-				mockDB.On("ListIngredients").Return([]models.Ingredient{}, nil)
+				mockDB.On("ListIngredients", models.ListIngredientsRequest{}).Return([]models.Ingredient{}, nil)
 			},
 			expectedStatusCode: http.StatusOK,
 			expectedResponse: models.IngredientsListResponse{
@@ -348,6 +408,90 @@ func TestListIngredientsHTTP(t *testing.T) {
 	}
 }
 
+// TestListIngredientsHTTPTagFilter tests that ?tag is normalized and passed to the database, and unknown tags are rejected
+func TestListIngredientsHTTPTagFilter(t *testing.T) {
+	testCases := map[string]struct {
+		query              string
+		mockSetup          func(*MockDBHandler)
+		expectedStatusCode int
+		expectedCount      int
+	}{
+		"filtered_by_tag": {
+			query: "?tag=Dairy",
+			mockSetup: func(mockDB *MockDBHandler) {
+				mockDB.On("ListIngredients", models.ListIngredientsRequest{Tag: stringPtr("dairy")}).Return([]models.Ingredient{
+					{ID: "ingredient-1", Name: "Cream", Tags: []string{"dairy"}},
+				}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedCount:      1,
+		},
+		"unknown_tag": {
+			query:              "?tag=chocolate",
+			mockSetup:          func(mockDB *MockDBHandler) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockDB := new(MockDBHandler)
+			tc.mockSetup(mockDB)
+
+			logger := logrus.New()
+			logger.SetLevel(logrus.FatalLevel)
+
+			handler := NewHttpHandlerWithInterface(mockDB, logger)
+
+			req := httptest.NewRequest(http.MethodGet, "/ingredients"+tc.query, nil)
+			recorder := httptest.NewRecorder()
+
+			handler.ListIngredients(recorder, req)
+
+			assert.Equal(t, tc.expectedStatusCode, recorder.Code)
+			if tc.expectedStatusCode == http.StatusOK {
+				var response models.IngredientsListResponse
+				require.NoError(t, json.NewDecoder(recorder.Body).Decode(&response))
+				assert.Equal(t, tc.expectedCount, response.Count)
+			}
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
+// TestListIngredientsHTTPCancelledRequest tests that a disconnected client cancels the list query and the handler returns promptly
+func TestListIngredientsHTTPCancelledRequest(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	mock.ExpectQuery("SELECT (.+) FROM ingredients").
+		WillDelayFor(time.Second).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	handler := NewHttpHandler(NewDBHandler(db, logger), logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/inventory/ingredients", nil).WithContext(ctx)
+	recorder := httptest.NewRecorder()
+
+	start := time.Now()
+	handler.ListIngredients(recorder, req)
+
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+
+	var response models.IngredientsListResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	assert.False(t, response.Success)
+	assert.Contains(t, response.Message, context.Canceled.Error())
+}
+
 func TestUpdateIngredientHTTP(t *testing.T) {
 	testCases := map[string]struct {
 		ingredientID       string
@@ -512,3 +656,57 @@ func TestDeleteIngredientHTTP(t *testing.T) {
 		})
 	}
 }
+
+// TestListPurchaseSuggestionsHTTP tests the purchase suggestions endpoint responses
+func TestListPurchaseSuggestionsHTTP(t *testing.T) {
+	testCases := map[string]struct {
+		mockSetup          func(*MockDBHandler)
+		expectedStatusCode int
+		expectedCount      int
+	}{
+		"successful_list": {
+			mockSetup: func(mockDB *MockDBHandler) {
+				mockDB.On("ListPurchaseSuggestions").Return([]models.PurchaseSuggestion{
+					{IngredientID: "ingredient-1", IngredientName: "Cream", UnitsAvailable: 4, ReorderPoint: 10, SuggestedQuantity: 6},
+				}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedCount:      1,
+		},
+		"database_error": {
+			mockSetup: func(mockDB *MockDBHandler) {
+				mockDB.On("ListPurchaseSuggestions").Return(nil, assert.AnError)
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// Setup
+			mockDB := new(MockDBHandler)
+			tc.mockSetup(mockDB)
+
+			logger := logrus.New()
+			logger.SetLevel(logrus.FatalLevel)
+
+			handler := NewHttpHandlerWithInterface(mockDB, logger)
+
+			req := httptest.NewRequest(http.MethodGet, "/purchase-suggestions", nil)
+			recorder := httptest.NewRecorder()
+
+			// Execute
+			handler.ListPurchaseSuggestions(recorder, req)
+
+			// Assert
+			assert.Equal(t, tc.expectedStatusCode, recorder.Code)
+
+			var response models.PurchaseSuggestionsResponse
+			require.NoError(t, json.NewDecoder(recorder.Body).Decode(&response))
+			assert.Equal(t, tc.expectedCount, response.Count)
+			assert.Len(t, response.Data, tc.expectedCount)
+
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
@@ -1,30 +1,165 @@
 package models
 
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrInvalidReorderLevels is returned when a reorder point or target is negative, or the target is below the point
+var ErrInvalidReorderLevels = errors.New("reorder_point and reorder_target must not be negative and reorder_target must not be below reorder_point")
+
+// ErrInvalidTag is returned when a tag is not in AllowedTags
+var ErrInvalidTag = errors.New("invalid ingredient tag")
+
+// AllowedTags are the allergen labels an ingredient can carry for menu display
+var AllowedTags = map[string]bool{
+	"dairy":     true,
+	"eggs":      true,
+	"gluten":    true,
+	"nuts":      true,
+	"peanuts":   true,
+	"sesame":    true,
+	"soy":       true,
+	"fish":      true,
+	"shellfish": true,
+	"sulfites":  true,
+}
+
+// NormalizeTag lowercases and trims a tag and checks it against AllowedTags
+func NormalizeTag(tag string) (string, error) {
+	normalized := strings.ToLower(strings.TrimSpace(tag))
+	if !AllowedTags[normalized] {
+		return "", fmt.Errorf("%w %q (allowed: %s)", ErrInvalidTag, tag, strings.Join(allowedTagNames(), ", "))
+	}
+	return normalized, nil
+}
+
+// NormalizeTags normalizes every tag and drops duplicates, keeping the first occurrence's order.
+// A nil slice stays nil so updates can tell "unchanged" from "cleared".
+func NormalizeTags(tags []string) ([]string, error) {
+	if tags == nil {
+		return nil, nil
+	}
+
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		n, err := NormalizeTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[n] {
+			seen[n] = true
+			normalized = append(normalized, n)
+		}
+	}
+	return normalized, nil
+}
+
+func allowedTagNames() []string {
+	names := make([]string, 0, len(AllowedTags))
+	for name := range AllowedTags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // Ingredient represents an ingredient used in ice cream production
 type Ingredient struct {
-	ID                   string  `json:"id" db:"id"`
-	Name                 string  `json:"name" db:"name"`
-	Description          *string `json:"description" db:"description"`
-	IngredientCategoryID *string `json:"ingredient_category_id" db:"ingredient_category_id"`
-	SupplierID           *string `json:"supplier_id" db:"supplier_id"`
-	CreatedAt            string  `json:"created_at" db:"created_at"`
-	UpdatedAt            string  `json:"updated_at" db:"updated_at"`
+	ID                   string   `json:"id" db:"id"`
+	Name                 string   `json:"name" db:"name"`
+	Description          *string  `json:"description" db:"description"`
+	IngredientCategoryID *string  `json:"ingredient_category_id" db:"ingredient_category_id"`
+	SupplierID           *string  `json:"supplier_id" db:"supplier_id"`
+	ReorderPoint         *float64 `json:"reorder_point" db:"reorder_point"`
+	ReorderTarget        *float64 `json:"reorder_target" db:"reorder_target"`
+	Tags                 []string `json:"tags" db:"tags"`
+	CreatedAt            string   `json:"created_at" db:"created_at"`
+	UpdatedAt            string   `json:"updated_at" db:"updated_at"`
 }
 
 // CreateIngredientRequest represents the request to create a new ingredient
 type CreateIngredientRequest struct {
-	Name                 string  `json:"name" validate:"required,min=1,max=255"`
-	Description          *string `json:"description,omitempty" validate:"omitempty,max=1000"`
-	IngredientCategoryID *string `json:"ingredient_category_id,omitempty" validate:"omitempty,uuid"`
-	SupplierID           *string `json:"supplier_id,omitempty" validate:"omitempty,uuid"`
+	Name                 string   `json:"name" validate:"required,min=1,max=255"`
+	Description          *string  `json:"description,omitempty" validate:"omitempty,max=1000"`
+	IngredientCategoryID *string  `json:"ingredient_category_id,omitempty" validate:"omitempty,uuid"`
+	SupplierID           *string  `json:"supplier_id,omitempty" validate:"omitempty,uuid"`
+	ReorderPoint         *float64 `json:"reorder_point,omitempty" validate:"omitempty,min=0"`
+	ReorderTarget        *float64 `json:"reorder_target,omitempty" validate:"omitempty,min=0"`
+	Tags                 []string `json:"tags,omitempty"`
 }
 
 // UpdateIngredientRequest represents the request to update an ingredient
 type UpdateIngredientRequest struct {
-	Name                 *string `json:"name,omitempty" validate:"omitempty,min=1,max=255"`
-	Description          *string `json:"description,omitempty" validate:"omitempty,max=1000"`
-	IngredientCategoryID *string `json:"ingredient_category_id,omitempty" validate:"omitempty,uuid"`
-	SupplierID           *string `json:"supplier_id,omitempty" validate:"omitempty,uuid"`
+	Name                 *string  `json:"name,omitempty" validate:"omitempty,min=1,max=255"`
+	Description          *string  `json:"description,omitempty" validate:"omitempty,max=1000"`
+	IngredientCategoryID *string  `json:"ingredient_category_id,omitempty" validate:"omitempty,uuid"`
+	SupplierID           *string  `json:"supplier_id,omitempty" validate:"omitempty,uuid"`
+	ReorderPoint         *float64 `json:"reorder_point,omitempty" validate:"omitempty,min=0"`
+	ReorderTarget        *float64 `json:"reorder_target,omitempty" validate:"omitempty,min=0"`
+	Tags                 []string `json:"tags,omitempty"` // nil leaves tags unchanged, [] clears them
+}
+
+// ValidateReorderLevels checks the reorder levels set in a request; a target is only compared
+// with a reorder point given in the same request
+func ValidateReorderLevels(reorderPoint, reorderTarget *float64) error {
+	if reorderPoint != nil && *reorderPoint < 0 {
+		return ErrInvalidReorderLevels
+	}
+	if reorderTarget != nil && *reorderTarget < 0 {
+		return ErrInvalidReorderLevels
+	}
+	if reorderPoint != nil && reorderTarget != nil && *reorderTarget < *reorderPoint {
+		return ErrInvalidReorderLevels
+	}
+	return nil
+}
+
+// IngredientStockLevel is an ingredient with a reorder point and the units currently available for it
+type IngredientStockLevel struct {
+	IngredientID   string   `db:"id"`
+	IngredientName string   `db:"name"`
+	SupplierID     *string  `db:"supplier_id"`
+	ReorderPoint   float64  `db:"reorder_point"`
+	ReorderTarget  *float64 `db:"reorder_target"`
+	UnitsAvailable float64  `db:"units_available"`
+}
+
+// PurchaseSuggestion recommends buying an ingredient whose stock fell below its reorder point
+type PurchaseSuggestion struct {
+	IngredientID      string   `json:"ingredient_id"`
+	IngredientName    string   `json:"ingredient_name"`
+	SupplierID        *string  `json:"supplier_id"`
+	UnitsAvailable    float64  `json:"units_available"`
+	ReorderPoint      float64  `json:"reorder_point"`
+	ReorderTarget     *float64 `json:"reorder_target"`
+	SuggestedQuantity float64  `json:"suggested_quantity"`
+}
+
+// SuggestPurchase returns a purchase suggestion when the available units are below the reorder point.
+// The suggested quantity restocks up to the reorder target, or to the reorder point when no target is set
+func SuggestPurchase(level IngredientStockLevel) (PurchaseSuggestion, bool) {
+	if level.UnitsAvailable >= level.ReorderPoint {
+		return PurchaseSuggestion{}, false
+	}
+
+	target := level.ReorderPoint
+	if level.ReorderTarget != nil && *level.ReorderTarget > target {
+		target = *level.ReorderTarget
+	}
+
+	return PurchaseSuggestion{
+		IngredientID:      level.IngredientID,
+		IngredientName:    level.IngredientName,
+		SupplierID:        level.SupplierID,
+		UnitsAvailable:    level.UnitsAvailable,
+		ReorderPoint:      level.ReorderPoint,
+		ReorderTarget:     level.ReorderTarget,
+		SuggestedQuantity: target - level.UnitsAvailable,
+	}, true
 }
 
 // GetIngredientRequest represents the request to get an ingredient by ID
@@ -37,10 +172,11 @@ type DeleteIngredientRequest struct {
 	ID string `json:"id" validate:"required,uuid"`
 }
 
-// ListIngredientsRequest represents the request to list ingredients (for future pagination)
+// ListIngredientsRequest represents the request to list ingredients
 type ListIngredientsRequest struct {
-	Limit  *int `json:"limit,omitempty" validate:"omitempty,min=1,max=100"`
-	Offset *int `json:"offset,omitempty" validate:"omitempty,min=0"`
+	Tag    *string `json:"tag,omitempty"` // only ingredients carrying this tag
+	Limit  *int    `json:"limit,omitempty" validate:"omitempty,min=1,max=100"`
+	Offset *int    `json:"offset,omitempty" validate:"omitempty,min=0"`
 }
 
 // Response Structs
@@ -65,6 +201,14 @@ type IngredientDeleteResponse struct {
 	Message string `json:"message"`
 }
 
+// PurchaseSuggestionsResponse represents a list of purchase suggestions response
+type PurchaseSuggestionsResponse struct {
+	Success bool                 `json:"success"`
+	Data    []PurchaseSuggestion `json:"data"`
+	Count   int                  `json:"count"`
+	Message string               `json:"message,omitempty"`
+}
+
 // ErrorResponse represents an error response
 type ErrorResponse struct {
 	Success bool   `json:"success"`
@@ -18,3 +18,6 @@ var UpdateIngredientQuery string
 
 //go:embed scripts/delete_ingredient.sql
 var DeleteIngredientQuery string
+
+//go:embed scripts/list_ingredient_stock_levels.sql
+var ListIngredientStockLevelsQuery string
@@ -1,3 +1,3 @@
-INSERT INTO ingredients (id, name, description, ingredient_category_id, supplier_id, created_at, updated_at)
-VALUES (gen_random_uuid(), $1, $2, $3, $4, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
-RETURNING id, name, description, ingredient_category_id, supplier_id, created_at, updated_at; 
\ No newline at end of file
+INSERT INTO ingredients (id, name, description, ingredient_category_id, supplier_id, reorder_point, reorder_target, tags, created_at, updated_at)
+VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, $6, COALESCE($7::text[], '{}'), CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+RETURNING id, name, description, ingredient_category_id, supplier_id, reorder_point, reorder_target, created_at, updated_at, tags; 
\ No newline at end of file
@@ -1,3 +1,3 @@
-SELECT id, name, description, ingredient_category_id, supplier_id, created_at, updated_at
+SELECT id, name, description, ingredient_category_id, supplier_id, reorder_point, reorder_target, created_at, updated_at, tags
 FROM ingredients
 WHERE id = $1; 
\ No newline at end of file
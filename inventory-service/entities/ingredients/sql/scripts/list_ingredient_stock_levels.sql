@@ -0,0 +1,8 @@
+SELECT i.id, i.name, i.supplier_id, i.reorder_point, i.reorder_target,
+    COALESCE(SUM(e.units_available), 0) AS units_available
+FROM ingredients i
+LEFT JOIN existences e ON e.ingredient_id = i.id
+    AND (e.expiration_date IS NULL OR e.expiration_date >= CURRENT_DATE)
+WHERE i.reorder_point IS NOT NULL
+GROUP BY i.id, i.name, i.supplier_id, i.reorder_point, i.reorder_target
+ORDER BY i.name ASC; 
\ No newline at end of file
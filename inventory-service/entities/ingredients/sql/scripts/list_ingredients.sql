@@ -1,3 +1,4 @@
-SELECT id, name, description, ingredient_category_id, supplier_id, created_at, updated_at
+SELECT id, name, description, ingredient_category_id, supplier_id, reorder_point, reorder_target, created_at, updated_at, tags
 FROM ingredients
+WHERE ($1::text IS NULL OR $1 = ANY(tags))
 ORDER BY name ASC; 
\ No newline at end of file
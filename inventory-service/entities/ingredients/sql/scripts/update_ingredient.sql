@@ -4,6 +4,9 @@ SET
     description = COALESCE($3, description),
     ingredient_category_id = COALESCE($4, ingredient_category_id),
     supplier_id = COALESCE($5, supplier_id),
+    reorder_point = COALESCE($6, reorder_point),
+    reorder_target = COALESCE($7, reorder_target),
+    tags = COALESCE($8::text[], tags),
     updated_at = CURRENT_TIMESTAMP
 WHERE id = $1
-RETURNING id, name, description, ingredient_category_id, supplier_id, created_at, updated_at; 
\ No newline at end of file
+RETURNING id, name, description, ingredient_category_id, supplier_id, reorder_point, reorder_target, created_at, updated_at, tags; 
\ No newline at end of file
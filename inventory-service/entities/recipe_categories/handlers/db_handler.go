@@ -1,13 +1,18 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 
 	"inventory-service/entities/recipe_categories/models"
 	recipeSQL "inventory-service/entities/recipe_categories/sql"
 )
 
+// ErrReassignTargetNotFound is returned when the category recipes should be moved to does not exist
+var ErrReassignTargetNotFound = errors.New("reassign target recipe category not found")
+
 type RecipeCategoryDBHandler struct {
 	db *sql.DB
 }
@@ -57,7 +62,7 @@ func (h *RecipeCategoryDBHandler) GetByID(req models.GetRecipeCategoryRequest) (
 	return &recipeCategory, nil
 }
 
-func (h *RecipeCategoryDBHandler) List(req models.ListRecipeCategoriesRequest) ([]models.RecipeCategory, error) {
+func (h *RecipeCategoryDBHandler) List(ctx context.Context, req models.ListRecipeCategoriesRequest) ([]models.RecipeCategory, error) {
 	limit := 50
 	if req.Limit != nil {
 		limit = *req.Limit
@@ -68,7 +73,7 @@ func (h *RecipeCategoryDBHandler) List(req models.ListRecipeCategoriesRequest) (
 		offset = *req.Offset
 	}
 
-	rows, err := h.db.Query(
+	rows, err := h.db.QueryContext(ctx,
 		recipeSQL.ListRecipeCategoriesQuery,
 		req.Name,
 		limit,
@@ -144,3 +149,68 @@ func (h *RecipeCategoryDBHandler) Delete(req models.DeleteRecipeCategoryRequest)
 
 	return nil
 }
+
+// CountRecipes returns how many recipes reference a recipe category
+func (h *RecipeCategoryDBHandler) CountRecipes(id string) (int, error) {
+	var count int
+	if err := h.db.QueryRow(recipeSQL.CountRecipesInCategoryQuery, id).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count recipes in category: %w", err)
+	}
+
+	return count, nil
+}
+
+// ReassignAndDelete moves the category's recipes to the category reassignTo and deletes the category in a
+// single transaction. It returns the number of reassigned recipes.
+func (h *RecipeCategoryDBHandler) ReassignAndDelete(req models.DeleteRecipeCategoryRequest, reassignTo string) (int64, error) {
+	tx, err := h.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var target models.RecipeCategory
+	err = tx.QueryRow(recipeSQL.GetRecipeCategoryByIDQuery, reassignTo).Scan(
+		&target.ID,
+		&target.Name,
+		&target.Description,
+		&target.CreatedAt,
+		&target.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, ErrReassignTargetNotFound
+		}
+		return 0, fmt.Errorf("failed to get reassign target recipe category: %w", err)
+	}
+
+	result, err := tx.Exec(recipeSQL.ReassignRecipesCategoryQuery, req.ID, reassignTo)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reassign recipes: %w", err)
+	}
+
+	reassigned, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	result, err = tx.Exec(recipeSQL.DeleteRecipeCategoryQuery, req.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete recipe category: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return 0, fmt.Errorf("recipe category not found")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit recipe category delete: %w", err)
+	}
+
+	return reassigned, nil
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"testing"
 	"time"
@@ -197,7 +198,7 @@ func TestRecipeCategoryDBHandler_List(t *testing.T) {
 		WillReturnRows(rows)
 
 	req := models.ListRecipeCategoriesRequest{}
-	result, err := handler.List(req)
+	result, err := handler.List(context.Background(), req)
 	require.NoError(t, err)
 	assert.Equal(t, len(expectedRecipeCategories), len(result))
 	for i, expected := range expectedRecipeCategories {
@@ -235,7 +236,7 @@ func TestRecipeCategoryDBHandler_List_WithFilters(t *testing.T) {
 		WithArgs(&name, limit, offset).
 		WillReturnRows(rows)
 
-	result, err := handler.List(req)
+	result, err := handler.List(context.Background(), req)
 	require.NoError(t, err)
 	assert.Empty(t, result)
 
@@ -376,3 +377,22 @@ func TestRecipeCategoryDBHandler_Delete_Error(t *testing.T) {
 	err = mock.ExpectationsWereMet()
 	assert.NoError(t, err)
 }
+
+func TestRecipeCategoryDBHandler_CountRecipes(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	handler := NewRecipeCategoryDBHandler(db)
+
+	mock.ExpectQuery("SELECT COUNT").
+		WithArgs("550e8400-e29b-41d4-a716-446655440000").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+	count, err := handler.CountRecipes("550e8400-e29b-41d4-a716-446655440000")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
@@ -3,10 +3,12 @@ package handlers
 import (
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
 
 	"inventory-service/entities/recipe_categories/models"
+	"shared/httpx"
 
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
@@ -27,9 +29,9 @@ func NewRecipeCategoryHTTPHandler(db *sql.DB, logger *logrus.Logger) *RecipeCate
 // CreateRecipeCategory handles POST /recipe-categories
 func (h *RecipeCategoryHTTPHandler) CreateRecipeCategory(w http.ResponseWriter, r *http.Request) {
 	var req models.CreateRecipeCategoryRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := httpx.DecodeJSONBody(r, &req); err != nil {
 		h.logger.WithError(err).Error("Invalid JSON in create recipe category request")
-		h.writeErrorResponse(w, "Invalid JSON format", http.StatusBadRequest)
+		h.writeErrorResponse(w, httpx.DecodeErrorMessage(err, "Invalid JSON format"), http.StatusBadRequest)
 		return
 	}
 
@@ -49,6 +51,7 @@ func (h *RecipeCategoryHTTPHandler) CreateRecipeCategory(w http.ResponseWriter,
 		Data:    *recipeCategory,
 		Message: "Recipe category created successfully",
 	}
+	h.logger.WithFields(httpx.RequestLogFields(r)).WithField("recipe_category_id", recipeCategory.ID).Info("Recipe category created successfully")
 	h.writeJSONResponse(w, response, http.StatusCreated)
 }
 
@@ -114,7 +117,7 @@ func (h *RecipeCategoryHTTPHandler) ListRecipeCategories(w http.ResponseWriter,
 		}
 	}
 
-	recipeCategories, err := h.dbHandler.List(req)
+	recipeCategories, err := h.dbHandler.List(r.Context(), req)
 	if err != nil {
 		response := models.RecipeCategoriesResponse{
 			Success: false,
@@ -146,9 +149,9 @@ func (h *RecipeCategoryHTTPHandler) UpdateRecipeCategory(w http.ResponseWriter,
 	}
 
 	var req models.UpdateRecipeCategoryRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := httpx.DecodeJSONBody(r, &req); err != nil {
 		h.logger.WithError(err).Error("Invalid JSON in update recipe category request")
-		h.writeErrorResponse(w, "Invalid JSON format", http.StatusBadRequest)
+		h.writeErrorResponse(w, httpx.DecodeErrorMessage(err, "Invalid JSON format"), http.StatusBadRequest)
 		return
 	}
 
@@ -178,10 +181,13 @@ func (h *RecipeCategoryHTTPHandler) UpdateRecipeCategory(w http.ResponseWriter,
 		Data:    *recipeCategory,
 		Message: "Recipe category updated successfully",
 	}
+	h.logger.WithFields(httpx.RequestLogFields(r)).WithField("recipe_category_id", recipeCategory.ID).Info("Recipe category updated successfully")
 	h.writeJSONResponse(w, response, http.StatusOK)
 }
 
 // DeleteRecipeCategory handles DELETE /recipe-categories/{id}
+// Deletion is blocked with 409 while recipes reference the category, unless ?reassign_to={id} names
+// another category to move them to first; the move and the delete run in one transaction.
 func (h *RecipeCategoryHTTPHandler) DeleteRecipeCategory(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
@@ -192,11 +198,40 @@ func (h *RecipeCategoryHTTPHandler) DeleteRecipeCategory(w http.ResponseWriter,
 		return
 	}
 
+	reassignTo := r.URL.Query().Get("reassign_to")
+	if reassignTo == id {
+		h.writeErrorResponse(w, "reassign_to must be a different recipe category", http.StatusBadRequest)
+		return
+	}
+
 	req := models.DeleteRecipeCategoryRequest{ID: id}
-	err := h.dbHandler.Delete(req)
+
+	var (
+		reassigned int64
+		err        error
+	)
+	if reassignTo != "" {
+		reassigned, err = h.dbHandler.ReassignAndDelete(req, reassignTo)
+	} else {
+		var count int
+		count, err = h.dbHandler.CountRecipes(id)
+		if err == nil && count > 0 {
+			response := models.RecipeCategoryDeleteResponse{
+				Success:     false,
+				Message:     fmt.Sprintf("Recipe category is used by %d recipe(s); use reassign_to to move them to another category", count),
+				RecipeCount: count,
+			}
+			h.writeJSONResponse(w, response, http.StatusConflict)
+			return
+		}
+		if err == nil {
+			err = h.dbHandler.Delete(req)
+		}
+	}
+
 	if err != nil {
 		if err.Error() == "recipe category not found" {
-			response := models.GenericResponse{
+			response := models.RecipeCategoryDeleteResponse{
 				Success: false,
 				Message: "Recipe category not found",
 			}
@@ -204,7 +239,16 @@ func (h *RecipeCategoryHTTPHandler) DeleteRecipeCategory(w http.ResponseWriter,
 			return
 		}
 
-		response := models.GenericResponse{
+		if err == ErrReassignTargetNotFound {
+			response := models.RecipeCategoryDeleteResponse{
+				Success: false,
+				Message: "Failed to delete recipe category: " + err.Error(),
+			}
+			h.writeJSONResponse(w, response, http.StatusUnprocessableEntity)
+			return
+		}
+
+		response := models.RecipeCategoryDeleteResponse{
 			Success: false,
 			Message: "Failed to delete recipe category: " + err.Error(),
 		}
@@ -212,10 +256,12 @@ func (h *RecipeCategoryHTTPHandler) DeleteRecipeCategory(w http.ResponseWriter,
 		return
 	}
 
-	response := models.GenericResponse{
-		Success: true,
-		Message: "Recipe category deleted successfully",
+	response := models.RecipeCategoryDeleteResponse{
+		Success:           true,
+		Message:           "Recipe category deleted successfully",
+		ReassignedRecipes: int(reassigned),
 	}
+	h.logger.WithFields(httpx.RequestLogFields(r)).WithField("recipe_category_id", id).Info("Recipe category deleted successfully")
 	h.writeJSONResponse(w, response, http.StatusOK)
 }
 
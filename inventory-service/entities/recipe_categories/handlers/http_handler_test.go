@@ -449,6 +449,9 @@ func TestRecipeCategoryHTTPHandler_DeleteRecipeCategory(t *testing.T) {
 
 	recipeCategoryID := "550e8400-e29b-41d4-a716-446655440000"
 
+	mock.ExpectQuery("SELECT COUNT").
+		WithArgs(recipeCategoryID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
 	mock.ExpectExec("DELETE FROM recipe_categories").
 		WithArgs(recipeCategoryID).
 		WillReturnResult(sqlmock.NewResult(0, 1))
@@ -480,6 +483,9 @@ func TestRecipeCategoryHTTPHandler_DeleteRecipeCategory_NotFound(t *testing.T) {
 	logger := logrus.New()
 	handler := NewRecipeCategoryHTTPHandler(db, logger)
 
+	mock.ExpectQuery("SELECT COUNT").
+		WithArgs("non-existent-id").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
 	mock.ExpectExec("DELETE FROM recipe_categories").
 		WithArgs("non-existent-id").
 		WillReturnResult(sqlmock.NewResult(0, 0))
@@ -519,3 +525,138 @@ func TestRecipeCategoryHTTPHandler_DeleteRecipeCategory_MissingID(t *testing.T)
 
 	assert.Equal(t, http.StatusBadRequest, response.Code)
 }
+
+func TestRecipeCategoryHTTPHandler_DeleteRecipeCategory_InUse(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	handler := NewRecipeCategoryHTTPHandler(db, logrus.New())
+
+	recipeCategoryID := "550e8400-e29b-41d4-a716-446655440000"
+
+	// No DELETE is expected: the category must survive while recipes reference it
+	mock.ExpectQuery("SELECT COUNT").
+		WithArgs(recipeCategoryID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+	request := httptest.NewRequest("DELETE", "/recipe-categories/"+recipeCategoryID, nil)
+	response := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/recipe-categories/{id}", handler.DeleteRecipeCategory)
+	router.ServeHTTP(response, request)
+
+	assert.Equal(t, http.StatusConflict, response.Code)
+
+	var result models.RecipeCategoryDeleteResponse
+	err = json.Unmarshal(response.Body.Bytes(), &result)
+	require.NoError(t, err)
+	assert.False(t, result.Success)
+	assert.Equal(t, 3, result.RecipeCount)
+	assert.Contains(t, result.Message, "reassign_to")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
+
+func TestRecipeCategoryHTTPHandler_DeleteRecipeCategory_Reassign(t *testing.T) {
+	recipeCategoryID := "550e8400-e29b-41d4-a716-446655440000"
+	targetID := "550e8400-e29b-41d4-a716-446655440001"
+	now := time.Now()
+
+	testCases := map[string]struct {
+		reassignTo         string
+		setupMock          func(mock sqlmock.Sqlmock)
+		expectedStatus     int
+		expectedReassigned int
+		expectedMessage    string
+	}{
+		"recipes moved then category deleted": {
+			reassignTo: targetID,
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT id, name, description, created_at, updated_at").
+					WithArgs(targetID).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "created_at", "updated_at"}).
+						AddRow(targetID, "Helados", nil, now, now))
+				mock.ExpectExec("UPDATE recipes").
+					WithArgs(recipeCategoryID, targetID).
+					WillReturnResult(sqlmock.NewResult(0, 3))
+				mock.ExpectExec("DELETE FROM recipe_categories").
+					WithArgs(recipeCategoryID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedStatus:     http.StatusOK,
+			expectedReassigned: 3,
+			expectedMessage:    "deleted successfully",
+		},
+		"unknown target rolls back": {
+			reassignTo: targetID,
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT id, name, description, created_at, updated_at").
+					WithArgs(targetID).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			expectedStatus:  http.StatusUnprocessableEntity,
+			expectedMessage: "reassign target recipe category not found",
+		},
+		"missing category rolls back the reassignment": {
+			reassignTo: targetID,
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT id, name, description, created_at, updated_at").
+					WithArgs(targetID).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "created_at", "updated_at"}).
+						AddRow(targetID, "Helados", nil, now, now))
+				mock.ExpectExec("UPDATE recipes").
+					WithArgs(recipeCategoryID, targetID).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("DELETE FROM recipe_categories").
+					WithArgs(recipeCategoryID).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			expectedStatus:  http.StatusNotFound,
+			expectedMessage: "not found",
+		},
+		"reassigning to itself is rejected": {
+			reassignTo:      recipeCategoryID,
+			setupMock:       func(mock sqlmock.Sqlmock) {},
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: "different recipe category",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			handler := NewRecipeCategoryHTTPHandler(db, logrus.New())
+			tc.setupMock(mock)
+
+			request := httptest.NewRequest("DELETE", "/recipe-categories/"+recipeCategoryID+"?reassign_to="+tc.reassignTo, nil)
+			response := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/recipe-categories/{id}", handler.DeleteRecipeCategory)
+			router.ServeHTTP(response, request)
+
+			assert.Equal(t, tc.expectedStatus, response.Code)
+
+			var result models.RecipeCategoryDeleteResponse
+			err = json.Unmarshal(response.Body.Bytes(), &result)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedStatus == http.StatusOK, result.Success)
+			assert.Equal(t, tc.expectedReassigned, result.ReassignedRecipes)
+			assert.Contains(t, result.Message, tc.expectedMessage)
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
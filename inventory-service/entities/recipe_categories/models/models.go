@@ -63,3 +63,11 @@ type GenericResponse struct {
 	Success bool   `json:"success"`
 	Message string `json:"message"`
 }
+
+// RecipeCategoryDeleteResponse represents a delete operation response
+type RecipeCategoryDeleteResponse struct {
+	Success           bool   `json:"success"`
+	Message           string `json:"message"`
+	RecipeCount       int    `json:"recipe_count,omitempty"`
+	ReassignedRecipes int    `json:"reassigned_recipes,omitempty"`
+}
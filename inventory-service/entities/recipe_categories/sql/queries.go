@@ -18,3 +18,9 @@ var UpdateRecipeCategoryQuery string
 
 //go:embed scripts/delete_recipe_category.sql
 var DeleteRecipeCategoryQuery string
+
+//go:embed scripts/count_recipes_in_category.sql
+var CountRecipesInCategoryQuery string
+
+//go:embed scripts/reassign_recipes_category.sql
+var ReassignRecipesCategoryQuery string
@@ -0,0 +1,3 @@
+SELECT COUNT(*)
+FROM recipes
+WHERE recipe_category_id = $1;
\ No newline at end of file
@@ -0,0 +1,5 @@
+UPDATE recipes
+SET
+    recipe_category_id = $2,
+    updated_at = CURRENT_TIMESTAMP
+WHERE recipe_category_id = $1;
\ No newline at end of file
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 
@@ -63,7 +64,7 @@ func (h *RecipeIngredientDBHandler) GetByID(req models.GetRecipeIngredientReques
 	return &recipeIngredient, nil
 }
 
-func (h *RecipeIngredientDBHandler) List(req models.ListRecipeIngredientsRequest) ([]models.RecipeIngredient, error) {
+func (h *RecipeIngredientDBHandler) List(ctx context.Context, req models.ListRecipeIngredientsRequest) ([]models.RecipeIngredient, error) {
 	limit := 50
 	if req.Limit != nil {
 		limit = *req.Limit
@@ -74,7 +75,7 @@ func (h *RecipeIngredientDBHandler) List(req models.ListRecipeIngredientsRequest
 		offset = *req.Offset
 	}
 
-	rows, err := h.db.Query(
+	rows, err := h.db.QueryContext(ctx,
 		recipeIngredientSQL.ListRecipeIngredientsQuery,
 		req.RecipeID,
 		req.IngredientID,
@@ -157,3 +158,43 @@ func (h *RecipeIngredientDBHandler) Delete(req models.DeleteRecipeIngredientRequ
 
 	return nil
 }
+
+func (h *RecipeIngredientDBHandler) RecipeExists(recipeID string) (bool, error) {
+	var exists bool
+	if err := h.db.QueryRow(recipeIngredientSQL.RecipeExistsQuery, recipeID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check recipe: %w", err)
+	}
+	return exists, nil
+}
+
+func (h *RecipeIngredientDBHandler) IngredientExists(ingredientID string) (bool, error) {
+	var exists bool
+	if err := h.db.QueryRow(recipeIngredientSQL.IngredientExistsQuery, ingredientID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check ingredient: %w", err)
+	}
+	return exists, nil
+}
+
+// GetIngredientUnitTypes returns the unit types the ingredient is stocked in, taken from its existences
+func (h *RecipeIngredientDBHandler) GetIngredientUnitTypes(ingredientID string) ([]string, error) {
+	rows, err := h.db.Query(recipeIngredientSQL.GetIngredientUnitTypesQuery, ingredientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ingredient unit types: %w", err)
+	}
+	defer rows.Close()
+
+	var unitTypes []string
+	for rows.Next() {
+		var unitType string
+		if err := rows.Scan(&unitType); err != nil {
+			return nil, fmt.Errorf("failed to scan ingredient unit type: %w", err)
+		}
+		unitTypes = append(unitTypes, unitType)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating ingredient unit types: %w", err)
+	}
+
+	return unitTypes, nil
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"testing"
 	"time"
@@ -213,7 +214,7 @@ func TestRecipeIngredientDBHandler_List(t *testing.T) {
 		WillReturnRows(rows)
 
 	req := models.ListRecipeIngredientsRequest{}
-	result, err := handler.List(req)
+	result, err := handler.List(context.Background(), req)
 	require.NoError(t, err)
 	assert.Equal(t, len(expectedRecipeIngredients), len(result))
 	for i, expected := range expectedRecipeIngredients {
@@ -255,7 +256,7 @@ func TestRecipeIngredientDBHandler_List_WithFilters(t *testing.T) {
 		WithArgs(&recipeID, &ingredientID, limit, offset).
 		WillReturnRows(rows)
 
-	result, err := handler.List(req)
+	result, err := handler.List(context.Background(), req)
 	require.NoError(t, err)
 	assert.Empty(t, result)
 
@@ -406,3 +407,61 @@ func TestRecipeIngredientDBHandler_Delete_Error(t *testing.T) {
 	err = mock.ExpectationsWereMet()
 	assert.NoError(t, err)
 }
+
+func TestRecipeIngredientDBHandler_RecipeExists(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	handler := NewRecipeIngredientDBHandler(db)
+
+	mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM recipes").
+		WithArgs("recipe-123").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	exists, err := handler.RecipeExists("recipe-123")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
+
+func TestRecipeIngredientDBHandler_IngredientExists_Error(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	handler := NewRecipeIngredientDBHandler(db)
+
+	mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM ingredients").
+		WithArgs("ingredient-123").
+		WillReturnError(sql.ErrConnDone)
+
+	exists, err := handler.IngredientExists("ingredient-123")
+	assert.Error(t, err)
+	assert.False(t, exists)
+	assert.Contains(t, err.Error(), "failed to check ingredient")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
+
+func TestRecipeIngredientDBHandler_GetIngredientUnitTypes(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	handler := NewRecipeIngredientDBHandler(db)
+
+	mock.ExpectQuery("SELECT DISTINCT unit_type").
+		WithArgs("ingredient-123").
+		WillReturnRows(sqlmock.NewRows([]string{"unit_type"}).AddRow("Gallons").AddRow("Liters"))
+
+	unitTypes, err := handler.GetIngredientUnitTypes("ingredient-123")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Gallons", "Liters"}, unitTypes)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
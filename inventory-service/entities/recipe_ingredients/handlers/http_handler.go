@@ -3,33 +3,46 @@ package handlers
 import (
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"inventory-service/entities/recipe_ingredients/models"
+	unitConversionHandlers "inventory-service/entities/unit_conversions/handlers"
+	unitConversionModels "inventory-service/entities/unit_conversions/models"
+	"shared/httpx"
 
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 )
 
 type RecipeIngredientHTTPHandler struct {
-	dbHandler *RecipeIngredientDBHandler
-	logger    *logrus.Logger
+	dbHandler     *RecipeIngredientDBHandler
+	unitConverter *unitConversionHandlers.UnitConversionDBHandler
+	logger        *logrus.Logger
 }
 
 func NewRecipeIngredientHTTPHandler(db *sql.DB, logger *logrus.Logger) *RecipeIngredientHTTPHandler {
 	return &RecipeIngredientHTTPHandler{
-		dbHandler: NewRecipeIngredientDBHandler(db),
-		logger:    logger,
+		dbHandler:     NewRecipeIngredientDBHandler(db),
+		unitConverter: unitConversionHandlers.NewUnitConversionDBHandler(db),
+		logger:        logger,
 	}
 }
 
 // CreateRecipeIngredient handles POST /recipe-ingredients
 func (h *RecipeIngredientHTTPHandler) CreateRecipeIngredient(w http.ResponseWriter, r *http.Request) {
 	var req models.CreateRecipeIngredientRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := httpx.DecodeJSONBody(r, &req); err != nil {
 		h.logger.WithError(err).Error("Invalid JSON in create recipe ingredient request")
-		h.writeErrorResponse(w, "Invalid JSON format", http.StatusBadRequest)
+		h.writeErrorResponse(w, httpx.DecodeErrorMessage(err, "Invalid JSON format"), http.StatusBadRequest)
+		return
+	}
+
+	if statusCode, err := h.validateCreateRequest(req); err != nil {
+		h.logger.WithError(err).Warn("Recipe ingredient validation failed")
+		h.writeErrorResponse(w, err.Error(), statusCode)
 		return
 	}
 
@@ -49,6 +62,7 @@ func (h *RecipeIngredientHTTPHandler) CreateRecipeIngredient(w http.ResponseWrit
 		Data:    *recipeIngredient,
 		Message: "Recipe ingredient created successfully",
 	}
+	h.logger.WithFields(httpx.RequestLogFields(r)).WithField("recipe_ingredient_id", recipeIngredient.ID).Info("Recipe ingredient created successfully")
 	h.writeJSONResponse(w, response, http.StatusCreated)
 }
 
@@ -118,7 +132,7 @@ func (h *RecipeIngredientHTTPHandler) ListRecipeIngredients(w http.ResponseWrite
 		}
 	}
 
-	recipeIngredients, err := h.dbHandler.List(req)
+	recipeIngredients, err := h.dbHandler.List(r.Context(), req)
 	if err != nil {
 		response := models.RecipeIngredientsResponse{
 			Success: false,
@@ -150,9 +164,9 @@ func (h *RecipeIngredientHTTPHandler) UpdateRecipeIngredient(w http.ResponseWrit
 	}
 
 	var req models.UpdateRecipeIngredientRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := httpx.DecodeJSONBody(r, &req); err != nil {
 		h.logger.WithError(err).Error("Invalid JSON in update recipe ingredient request")
-		h.writeErrorResponse(w, "Invalid JSON format", http.StatusBadRequest)
+		h.writeErrorResponse(w, httpx.DecodeErrorMessage(err, "Invalid JSON format"), http.StatusBadRequest)
 		return
 	}
 
@@ -182,6 +196,7 @@ func (h *RecipeIngredientHTTPHandler) UpdateRecipeIngredient(w http.ResponseWrit
 		Data:    *recipeIngredient,
 		Message: "Recipe ingredient updated successfully",
 	}
+	h.logger.WithFields(httpx.RequestLogFields(r)).WithField("recipe_ingredient_id", recipeIngredient.ID).Info("Recipe ingredient updated successfully")
 	h.writeJSONResponse(w, response, http.StatusOK)
 }
 
@@ -220,9 +235,88 @@ func (h *RecipeIngredientHTTPHandler) DeleteRecipeIngredient(w http.ResponseWrit
 		Success: true,
 		Message: "Recipe ingredient deleted successfully",
 	}
+	h.logger.WithFields(httpx.RequestLogFields(r)).WithField("recipe_ingredient_id", id).Info("Recipe ingredient deleted successfully")
 	h.writeJSONResponse(w, response, http.StatusOK)
 }
 
+// validateCreateRequest checks quantity and unit rules and that the referenced recipe and ingredient exist,
+// returning the HTTP status to report when validation fails
+func (h *RecipeIngredientHTTPHandler) validateCreateRequest(req models.CreateRecipeIngredientRequest) (int, error) {
+	if req.Quantity <= 0 {
+		return http.StatusUnprocessableEntity, fmt.Errorf("quantity must be greater than zero")
+	}
+
+	if strings.TrimSpace(req.UnitType) == "" {
+		return http.StatusUnprocessableEntity, fmt.Errorf("unit_type is required")
+	}
+
+	recipeExists, err := h.dbHandler.RecipeExists(req.RecipeID)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if !recipeExists {
+		return http.StatusNotFound, fmt.Errorf("recipe not found")
+	}
+
+	ingredientExists, err := h.dbHandler.IngredientExists(req.IngredientID)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if !ingredientExists {
+		return http.StatusNotFound, fmt.Errorf("ingredient not found")
+	}
+
+	// Ingredients without stock records have no known unit yet, so any unit is accepted
+	allowedUnits, err := h.dbHandler.GetIngredientUnitTypes(req.IngredientID)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if len(allowedUnits) > 0 && !containsUnitType(allowedUnits, req.UnitType) {
+		// A unit that converts to one of the stocked units (e.g. ml when stock is in Liters) is also accepted
+		convertible, err := h.isConvertibleUnit(allowedUnits, req.UnitType, req.IngredientID)
+		if err != nil {
+			return http.StatusInternalServerError, err
+		}
+		if convertible {
+			return http.StatusOK, nil
+		}
+		return http.StatusUnprocessableEntity, fmt.Errorf("unit_type %q is not allowed for this ingredient (allowed: %s)", req.UnitType, strings.Join(allowedUnits, ", "))
+	}
+
+	return http.StatusOK, nil
+}
+
+// containsUnitType reports whether unitType is in unitTypes, ignoring case
+func containsUnitType(unitTypes []string, unitType string) bool {
+	for _, allowed := range unitTypes {
+		if strings.EqualFold(allowed, unitType) {
+			return true
+		}
+	}
+	return false
+}
+
+// isConvertibleUnit reports whether unitType converts to any of unitTypes, using standard conversions
+// first and the ingredient's own conversion factors otherwise
+func (h *RecipeIngredientHTTPHandler) isConvertibleUnit(unitTypes []string, unitType, ingredientID string) (bool, error) {
+	for _, allowed := range unitTypes {
+		if _, err := unitConversionModels.ConvertStandardUnits(1, unitType, allowed); err == nil {
+			return true, nil
+		}
+	}
+
+	conversions, err := h.unitConverter.GetIngredientConversions(ingredientID)
+	if err != nil {
+		return false, err
+	}
+	for _, allowed := range unitTypes {
+		if _, err := unitConversionModels.ApplyConversions(1, unitType, allowed, conversions); err == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // Helper methods for HTTP responses
 
 // writeJSONResponse writes a JSON response with the specified status code
@@ -68,6 +68,7 @@ func TestRecipeIngredientHTTPHandler_CreateRecipeIngredient(t *testing.T) {
 		expectedRecipeIngredient.UpdatedAt,
 	)
 
+	expectCreateValidation(mock, req.RecipeID, req.IngredientID, true, true, "cups")
 	mock.ExpectQuery("INSERT INTO recipe_ingredients").
 		WithArgs(req.RecipeID, req.IngredientID, req.Quantity, req.UnitType).
 		WillReturnRows(rows)
@@ -114,6 +115,51 @@ func TestRecipeIngredientHTTPHandler_CreateRecipeIngredient_InvalidJSON(t *testi
 	assert.Equal(t, http.StatusBadRequest, response.Code)
 }
 
+func TestRecipeIngredientHTTPHandler_UnknownField(t *testing.T) {
+	testCases := map[string]struct {
+		method string
+		path   string
+		body   string
+		call   func(*RecipeIngredientHTTPHandler, http.ResponseWriter, *http.Request)
+	}{
+		"create": {
+			method: "POST",
+			path:   "/recipe-ingredients",
+			body:   `{"recipe_id":"550e8400-e29b-41d4-a716-446655440000","ingredient_id":"550e8400-e29b-41d4-a716-446655440001","quantity":1,"unit":"Liters"}`,
+			call:   (*RecipeIngredientHTTPHandler).CreateRecipeIngredient,
+		},
+		"update": {
+			method: "PUT",
+			path:   "/recipe-ingredients/550e8400-e29b-41d4-a716-446655440002",
+			body:   `{"quantity":1,"unit":"Liters"}`,
+			call:   (*RecipeIngredientHTTPHandler).UpdateRecipeIngredient,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			logger := logrus.New()
+			logger.SetLevel(logrus.FatalLevel)
+			handler := NewRecipeIngredientHTTPHandler(db, logger)
+
+			request := httptest.NewRequest(tc.method, tc.path, bytes.NewBufferString(tc.body))
+			request = mux.SetURLVars(request, map[string]string{"id": "550e8400-e29b-41d4-a716-446655440002"})
+			request.Header.Set("Content-Type", "application/json")
+			response := httptest.NewRecorder()
+
+			tc.call(handler, response, request)
+
+			assert.Equal(t, http.StatusBadRequest, response.Code)
+			assert.Contains(t, response.Body.String(), `unknown field \"unit\" in request body`)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
 func TestRecipeIngredientHTTPHandler_CreateRecipeIngredient_DBError(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	require.NoError(t, err)
@@ -129,6 +175,7 @@ func TestRecipeIngredientHTTPHandler_CreateRecipeIngredient_DBError(t *testing.T
 		UnitType:     "cups",
 	}
 
+	expectCreateValidation(mock, req.RecipeID, req.IngredientID, true, true, "cups")
 	mock.ExpectQuery("INSERT INTO recipe_ingredients").
 		WithArgs(req.RecipeID, req.IngredientID, req.Quantity, req.UnitType).
 		WillReturnError(sql.ErrConnDone)
@@ -152,6 +199,224 @@ func TestRecipeIngredientHTTPHandler_CreateRecipeIngredient_DBError(t *testing.T
 	assert.NoError(t, err)
 }
 
+// expectCreateValidation registers the FK and unit pre-check queries run before a recipe ingredient insert
+func expectCreateValidation(mock sqlmock.Sqlmock, recipeID, ingredientID string, recipeExists, ingredientExists bool, unitTypes ...string) {
+	mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM recipes").
+		WithArgs(recipeID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(recipeExists))
+	if !recipeExists {
+		return
+	}
+
+	mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM ingredients").
+		WithArgs(ingredientID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(ingredientExists))
+	if !ingredientExists {
+		return
+	}
+
+	rows := sqlmock.NewRows([]string{"unit_type"})
+	for _, unitType := range unitTypes {
+		rows.AddRow(unitType)
+	}
+	mock.ExpectQuery("SELECT DISTINCT unit_type").
+		WithArgs(ingredientID).
+		WillReturnRows(rows)
+}
+
+func TestRecipeIngredientHTTPHandler_CreateRecipeIngredient_ValidationErrors(t *testing.T) {
+	recipeID := "550e8400-e29b-41d4-a716-446655440000"
+	ingredientID := "550e8400-e29b-41d4-a716-446655440001"
+
+	testCases := map[string]struct {
+		request         models.CreateRecipeIngredientRequest
+		setupMock       func(sqlmock.Sqlmock)
+		expectedStatus  int
+		expectedMessage string
+	}{
+		"zero_quantity": {
+			request:         models.CreateRecipeIngredientRequest{RecipeID: recipeID, IngredientID: ingredientID, Quantity: 0, UnitType: "Liters"},
+			setupMock:       func(mock sqlmock.Sqlmock) {},
+			expectedStatus:  http.StatusUnprocessableEntity,
+			expectedMessage: "quantity must be greater than zero",
+		},
+		"negative_quantity": {
+			request:         models.CreateRecipeIngredientRequest{RecipeID: recipeID, IngredientID: ingredientID, Quantity: -1.5, UnitType: "Liters"},
+			setupMock:       func(mock sqlmock.Sqlmock) {},
+			expectedStatus:  http.StatusUnprocessableEntity,
+			expectedMessage: "quantity must be greater than zero",
+		},
+		"missing_unit_type": {
+			request:         models.CreateRecipeIngredientRequest{RecipeID: recipeID, IngredientID: ingredientID, Quantity: 1, UnitType: "  "},
+			setupMock:       func(mock sqlmock.Sqlmock) {},
+			expectedStatus:  http.StatusUnprocessableEntity,
+			expectedMessage: "unit_type is required",
+		},
+		"recipe_not_found": {
+			request: models.CreateRecipeIngredientRequest{RecipeID: recipeID, IngredientID: ingredientID, Quantity: 1, UnitType: "Liters"},
+			setupMock: func(mock sqlmock.Sqlmock) {
+				expectCreateValidation(mock, recipeID, ingredientID, false, true)
+			},
+			expectedStatus:  http.StatusNotFound,
+			expectedMessage: "recipe not found",
+		},
+		"ingredient_not_found": {
+			request: models.CreateRecipeIngredientRequest{RecipeID: recipeID, IngredientID: ingredientID, Quantity: 1, UnitType: "Liters"},
+			setupMock: func(mock sqlmock.Sqlmock) {
+				expectCreateValidation(mock, recipeID, ingredientID, true, false)
+			},
+			expectedStatus:  http.StatusNotFound,
+			expectedMessage: "ingredient not found",
+		},
+		"unit_type_not_allowed": {
+			request: models.CreateRecipeIngredientRequest{RecipeID: recipeID, IngredientID: ingredientID, Quantity: 1, UnitType: "Bag"},
+			setupMock: func(mock sqlmock.Sqlmock) {
+				expectCreateValidation(mock, recipeID, ingredientID, true, true, "Gallons", "Liters")
+				mock.ExpectQuery("SELECT (.+) FROM unit_conversions").
+					WithArgs(ingredientID).
+					WillReturnRows(sqlmock.NewRows(unitConversionColumns))
+			},
+			expectedStatus:  http.StatusUnprocessableEntity,
+			expectedMessage: `unit_type "Bag" is not allowed for this ingredient (allowed: Gallons, Liters)`,
+		},
+		"existence_check_error": {
+			request: models.CreateRecipeIngredientRequest{RecipeID: recipeID, IngredientID: ingredientID, Quantity: 1, UnitType: "Liters"},
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM recipes").
+					WithArgs(recipeID).
+					WillReturnError(sql.ErrConnDone)
+			},
+			expectedStatus:  http.StatusInternalServerError,
+			expectedMessage: "failed to check recipe: sql: connection is already closed",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			logger := logrus.New()
+			logger.SetLevel(logrus.FatalLevel)
+			handler := NewRecipeIngredientHTTPHandler(db, logger)
+			tc.setupMock(mock)
+
+			body, _ := json.Marshal(tc.request)
+			request := httptest.NewRequest("POST", "/recipe-ingredients", bytes.NewBuffer(body))
+			request.Header.Set("Content-Type", "application/json")
+			response := httptest.NewRecorder()
+
+			handler.CreateRecipeIngredient(response, request)
+
+			assert.Equal(t, tc.expectedStatus, response.Code)
+
+			var result map[string]interface{}
+			err = json.Unmarshal(response.Body.Bytes(), &result)
+			require.NoError(t, err)
+			assert.Equal(t, false, result["success"])
+			assert.Equal(t, tc.expectedMessage, result["message"])
+
+			// No insert may be attempted when validation fails
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRecipeIngredientHTTPHandler_CreateRecipeIngredient_UnitTypeCaseInsensitive(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	logger := logrus.New()
+	handler := NewRecipeIngredientHTTPHandler(db, logger)
+
+	req := models.CreateRecipeIngredientRequest{
+		RecipeID:     "550e8400-e29b-41d4-a716-446655440000",
+		IngredientID: "550e8400-e29b-41d4-a716-446655440001",
+		Quantity:     0.5,
+		UnitType:     "liters",
+	}
+
+	now := time.Now()
+	expectCreateValidation(mock, req.RecipeID, req.IngredientID, true, true, "Liters")
+	mock.ExpectQuery("INSERT INTO recipe_ingredients").
+		WithArgs(req.RecipeID, req.IngredientID, req.Quantity, req.UnitType).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "recipe_id", "ingredient_id", "quantity", "unit_type", "created_at", "updated_at",
+		}).AddRow("550e8400-e29b-41d4-a716-446655440002", req.RecipeID, req.IngredientID, req.Quantity, req.UnitType, now, now))
+
+	body, _ := json.Marshal(req)
+	request := httptest.NewRequest("POST", "/recipe-ingredients", bytes.NewBuffer(body))
+	request.Header.Set("Content-Type", "application/json")
+	response := httptest.NewRecorder()
+
+	handler.CreateRecipeIngredient(response, request)
+
+	assert.Equal(t, http.StatusCreated, response.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+var unitConversionColumns = []string{"id", "ingredient_id", "from_unit", "to_unit", "factor", "created_at", "updated_at"}
+
+func TestRecipeIngredientHTTPHandler_CreateRecipeIngredient_ConvertibleUnitType(t *testing.T) {
+	recipeID := "550e8400-e29b-41d4-a716-446655440000"
+	ingredientID := "550e8400-e29b-41d4-a716-446655440001"
+
+	testCases := map[string]struct {
+		unitType  string
+		setupMock func(sqlmock.Sqlmock)
+	}{
+		"standard_conversion": {
+			unitType: "ml",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				expectCreateValidation(mock, recipeID, ingredientID, true, true, "Liters")
+			},
+		},
+		"ingredient_conversion": {
+			unitType: "Cups",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				expectCreateValidation(mock, recipeID, ingredientID, true, true, "Bag")
+				mock.ExpectQuery("SELECT (.+) FROM unit_conversions").
+					WithArgs(ingredientID).
+					WillReturnRows(sqlmock.NewRows(unitConversionColumns).
+						AddRow("550e8400-e29b-41d4-a716-446655440003", ingredientID, "Bag", "Cups", 20.0, time.Now(), time.Now()))
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			logger := logrus.New()
+			logger.SetLevel(logrus.FatalLevel)
+			handler := NewRecipeIngredientHTTPHandler(db, logger)
+
+			req := models.CreateRecipeIngredientRequest{RecipeID: recipeID, IngredientID: ingredientID, Quantity: 250, UnitType: tc.unitType}
+			now := time.Now()
+			tc.setupMock(mock)
+			mock.ExpectQuery("INSERT INTO recipe_ingredients").
+				WithArgs(req.RecipeID, req.IngredientID, req.Quantity, req.UnitType).
+				WillReturnRows(sqlmock.NewRows([]string{
+					"id", "recipe_id", "ingredient_id", "quantity", "unit_type", "created_at", "updated_at",
+				}).AddRow("550e8400-e29b-41d4-a716-446655440002", req.RecipeID, req.IngredientID, req.Quantity, req.UnitType, now, now))
+
+			body, _ := json.Marshal(req)
+			request := httptest.NewRequest("POST", "/recipe-ingredients", bytes.NewBuffer(body))
+			request.Header.Set("Content-Type", "application/json")
+			response := httptest.NewRecorder()
+
+			handler.CreateRecipeIngredient(response, request)
+
+			assert.Equal(t, http.StatusCreated, response.Code)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
 func TestRecipeIngredientHTTPHandler_GetRecipeIngredient(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	require.NoError(t, err)
@@ -18,3 +18,12 @@ var UpdateRecipeIngredientQuery string
 
 //go:embed scripts/delete_recipe_ingredient.sql
 var DeleteRecipeIngredientQuery string
+
+//go:embed scripts/recipe_exists.sql
+var RecipeExistsQuery string
+
+//go:embed scripts/ingredient_exists.sql
+var IngredientExistsQuery string
+
+//go:embed scripts/get_ingredient_unit_types.sql
+var GetIngredientUnitTypesQuery string
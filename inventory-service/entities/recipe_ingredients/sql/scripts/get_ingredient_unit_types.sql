@@ -0,0 +1,4 @@
+SELECT DISTINCT unit_type
+FROM existences
+WHERE ingredient_id = $1
+ORDER BY unit_type;
\ No newline at end of file
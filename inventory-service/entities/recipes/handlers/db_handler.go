@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 
@@ -66,7 +67,7 @@ func (h *RecipeDBHandler) GetByID(req models.GetRecipeRequest) (*models.Recipe,
 	return &recipe, nil
 }
 
-func (h *RecipeDBHandler) List(req models.ListRecipesRequest) ([]models.Recipe, error) {
+func (h *RecipeDBHandler) List(ctx context.Context, req models.ListRecipesRequest) ([]models.Recipe, error) {
 	limit := 50
 	if req.Limit != nil {
 		limit = *req.Limit
@@ -77,7 +78,7 @@ func (h *RecipeDBHandler) List(req models.ListRecipesRequest) ([]models.Recipe,
 		offset = *req.Offset
 	}
 
-	rows, err := h.db.Query(
+	rows, err := h.db.QueryContext(ctx,
 		recipeSQL.ListRecipesQuery,
 		req.RecipeName,
 		req.RecipeCategoryID,
@@ -163,3 +164,94 @@ func (h *RecipeDBHandler) Delete(req models.DeleteRecipeRequest) error {
 
 	return nil
 }
+
+// ListIngredientsWithCosts returns a recipe's ingredients with names resolved and the latest purchase cost
+// of each; line costs are left for the caller to compute since they may need a unit conversion
+func (h *RecipeDBHandler) ListIngredientsWithCosts(ctx context.Context, recipeID string) ([]models.FullRecipeIngredient, error) {
+	rows, err := h.db.QueryContext(ctx, recipeSQL.ListRecipeIngredientsWithCostsQuery, recipeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recipe ingredients: %w", err)
+	}
+	defer rows.Close()
+
+	ingredients := []models.FullRecipeIngredient{}
+	for rows.Next() {
+		var ingredient models.FullRecipeIngredient
+		err := rows.Scan(
+			&ingredient.ID,
+			&ingredient.IngredientID,
+			&ingredient.IngredientName,
+			&ingredient.Quantity,
+			&ingredient.UnitType,
+			&ingredient.CostPerUnit,
+			&ingredient.CostUnitType,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan recipe ingredient: %w", err)
+		}
+		ingredients = append(ingredients, ingredient)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating recipe ingredients: %w", err)
+	}
+
+	return ingredients, nil
+}
+
+// ListRecipeRequirements returns every recipe's ingredient lines in one query; recipes without
+// ingredients appear once with nil ingredient fields
+func (h *RecipeDBHandler) ListRecipeRequirements(ctx context.Context) ([]models.RecipeRequirement, error) {
+	rows, err := h.db.QueryContext(ctx, recipeSQL.ListRecipeRequirementsQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recipe requirements: %w", err)
+	}
+	defer rows.Close()
+
+	var requirements []models.RecipeRequirement
+	for rows.Next() {
+		var requirement models.RecipeRequirement
+		err := rows.Scan(
+			&requirement.RecipeID,
+			&requirement.RecipeName,
+			&requirement.IngredientID,
+			&requirement.Quantity,
+			&requirement.UnitType,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan recipe requirement: %w", err)
+		}
+		requirements = append(requirements, requirement)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating recipe requirements: %w", err)
+	}
+
+	return requirements, nil
+}
+
+// ListAvailableIngredientStock returns the unexpired units available of every ingredient, per unit type,
+// in one query
+func (h *RecipeDBHandler) ListAvailableIngredientStock(ctx context.Context) ([]models.IngredientStock, error) {
+	rows, err := h.db.QueryContext(ctx, recipeSQL.ListAvailableIngredientStockQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ingredient stock: %w", err)
+	}
+	defer rows.Close()
+
+	var stock []models.IngredientStock
+	for rows.Next() {
+		var s models.IngredientStock
+		if err := rows.Scan(&s.IngredientID, &s.UnitType, &s.UnitsAvailable); err != nil {
+			return nil, fmt.Errorf("failed to scan ingredient stock: %w", err)
+		}
+		stock = append(stock, s)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating ingredient stock: %w", err)
+	}
+
+	return stock, nil
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"testing"
 	"time"
@@ -231,7 +232,7 @@ func TestRecipeDBHandler_List(t *testing.T) {
 		WillReturnRows(rows)
 
 	req := models.ListRecipesRequest{}
-	result, err := handler.List(req)
+	result, err := handler.List(context.Background(), req)
 	require.NoError(t, err)
 	assert.Equal(t, len(expectedRecipes), len(result))
 	for i, expected := range expectedRecipes {
@@ -274,7 +275,7 @@ func TestRecipeDBHandler_List_WithFilters(t *testing.T) {
 		WithArgs(&recipeName, &recipeCategoryID, limit, offset).
 		WillReturnRows(rows)
 
-	result, err := handler.List(req)
+	result, err := handler.List(context.Background(), req)
 	require.NoError(t, err)
 	assert.Empty(t, result)
 
@@ -3,33 +3,39 @@ package handlers
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
 	"inventory-service/entities/recipes/models"
+	unitConversionHandlers "inventory-service/entities/unit_conversions/handlers"
+	unitConversionModels "inventory-service/entities/unit_conversions/models"
+	"shared/httpx"
 
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 )
 
 type RecipeHTTPHandler struct {
-	dbHandler *RecipeDBHandler
-	logger    *logrus.Logger
+	dbHandler     *RecipeDBHandler
+	unitConverter *unitConversionHandlers.UnitConversionDBHandler
+	logger        *logrus.Logger
 }
 
 func NewRecipeHTTPHandler(db *sql.DB, logger *logrus.Logger) *RecipeHTTPHandler {
 	return &RecipeHTTPHandler{
-		dbHandler: NewRecipeDBHandler(db),
-		logger:    logger,
+		dbHandler:     NewRecipeDBHandler(db),
+		unitConverter: unitConversionHandlers.NewUnitConversionDBHandler(db),
+		logger:        logger,
 	}
 }
 
 // CreateRecipe handles POST /recipes
 func (h *RecipeHTTPHandler) CreateRecipe(w http.ResponseWriter, r *http.Request) {
 	var req models.CreateRecipeRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := httpx.DecodeJSONBody(r, &req); err != nil {
 		h.logger.WithError(err).Error("Invalid JSON in create recipe request")
-		h.writeErrorResponse(w, "Invalid JSON format", http.StatusBadRequest)
+		h.writeErrorResponse(w, httpx.DecodeErrorMessage(err, "Invalid JSON format"), http.StatusBadRequest)
 		return
 	}
 
@@ -49,6 +55,7 @@ func (h *RecipeHTTPHandler) CreateRecipe(w http.ResponseWriter, r *http.Request)
 		Data:    *recipe,
 		Message: "Recipe created successfully",
 	}
+	h.logger.WithFields(httpx.RequestLogFields(r)).WithField("recipe_id", recipe.ID).Info("Recipe created successfully")
 	h.writeJSONResponse(w, response, http.StatusCreated)
 }
 
@@ -93,6 +100,155 @@ func (h *RecipeHTTPHandler) GetRecipe(w http.ResponseWriter, r *http.Request) {
 	h.writeJSONResponse(w, response, http.StatusOK)
 }
 
+// GetFullRecipe handles GET /recipes/{id}/full, returning the recipe, its ingredients and their computed cost
+// in one response
+func (h *RecipeHTTPHandler) GetFullRecipe(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if id == "" {
+		h.logger.Warn("Missing recipe ID in get full recipe request")
+		h.writeErrorResponse(w, "Recipe ID is required", http.StatusBadRequest)
+		return
+	}
+
+	recipe, err := h.dbHandler.GetByID(models.GetRecipeRequest{ID: id})
+	if err != nil {
+		if err.Error() == "recipe not found" {
+			response := models.FullRecipeResponse{
+				Success: false,
+				Message: "Recipe not found",
+			}
+			h.writeJSONResponse(w, response, http.StatusNotFound)
+			return
+		}
+
+		response := models.FullRecipeResponse{
+			Success: false,
+			Message: "Failed to get recipe: " + err.Error(),
+		}
+		h.writeJSONResponse(w, response, http.StatusInternalServerError)
+		return
+	}
+
+	ingredients, err := h.dbHandler.ListIngredientsWithCosts(r.Context(), id)
+	if err != nil {
+		response := models.FullRecipeResponse{
+			Success: false,
+			Message: "Failed to get recipe ingredients: " + err.Error(),
+		}
+		h.writeJSONResponse(w, response, http.StatusInternalServerError)
+		return
+	}
+
+	for i := range ingredients {
+		if err := h.priceIngredient(&ingredients[i]); err != nil {
+			response := models.FullRecipeResponse{
+				Success: false,
+				Message: "Failed to compute recipe cost: " + err.Error(),
+			}
+			h.writeJSONResponse(w, response, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	fullRecipe := models.FullRecipe{
+		Recipe:      *recipe,
+		Ingredients: ingredients,
+	}
+	fullRecipe.ComputeCost()
+
+	response := models.FullRecipeResponse{
+		Success: true,
+		Data:    fullRecipe,
+		Message: "Recipe retrieved successfully",
+	}
+	h.writeJSONResponse(w, response, http.StatusOK)
+}
+
+// priceIngredient sets the line cost, converting the recipe quantity into the unit the ingredient was
+// bought in. Ingredients never purchased, or whose units don't convert, stay unpriced.
+func (h *RecipeHTTPHandler) priceIngredient(ingredient *models.FullRecipeIngredient) error {
+	if ingredient.CostPerUnit == nil || ingredient.CostUnitType == nil {
+		return nil
+	}
+
+	quantity, err := h.unitConverter.ConvertUnits(ingredient.Quantity, ingredient.UnitType, *ingredient.CostUnitType, ingredient.IngredientID)
+	if err != nil {
+		if errors.Is(err, unitConversionModels.ErrUndefinedConversion) {
+			h.logger.WithField("ingredient_id", ingredient.IngredientID).WithError(err).Warn("Recipe ingredient left unpriced")
+			return nil
+		}
+		return err
+	}
+
+	ingredient.SetLineCost(quantity)
+	return nil
+}
+
+// GetRecipesAvailability handles GET /recipes/availability, reporting how many portions of every recipe
+// the current stock can make. It runs a fixed number of queries regardless of the menu size.
+func (h *RecipeHTTPHandler) GetRecipesAvailability(w http.ResponseWriter, r *http.Request) {
+	requirements, err := h.dbHandler.ListRecipeRequirements(r.Context())
+	if err != nil {
+		h.writeAvailabilityError(w, err)
+		return
+	}
+
+	stock, err := h.dbHandler.ListAvailableIngredientStock(r.Context())
+	if err != nil {
+		h.writeAvailabilityError(w, err)
+		return
+	}
+
+	// Load the custom conversions of only the ingredients that are both used and stocked
+	used := make(map[string]bool)
+	for _, req := range requirements {
+		if req.IngredientID != nil {
+			used[*req.IngredientID] = true
+		}
+	}
+	var stockedIDs []string
+	for _, s := range stock {
+		if used[s.IngredientID] {
+			stockedIDs = append(stockedIDs, s.IngredientID)
+			used[s.IngredientID] = false
+		}
+	}
+	conversions, err := h.unitConverter.GetConversionsForIngredients(stockedIDs)
+	if err != nil {
+		h.writeAvailabilityError(w, err)
+		return
+	}
+
+	convert := func(value float64, from, to, ingredientID string) (float64, error) {
+		converted, err := unitConversionModels.ConvertStandardUnits(value, from, to)
+		if err == nil {
+			return converted, nil
+		}
+		return unitConversionModels.ApplyConversions(value, from, to, conversions[ingredientID])
+	}
+
+	availability := models.ComputeAvailability(requirements, stock, convert)
+
+	response := models.RecipeAvailabilityResponse{
+		Success: true,
+		Data:    availability,
+		Count:   len(availability),
+		Message: "Recipe availability retrieved successfully",
+	}
+	h.writeJSONResponse(w, response, http.StatusOK)
+}
+
+func (h *RecipeHTTPHandler) writeAvailabilityError(w http.ResponseWriter, err error) {
+	response := models.RecipeAvailabilityResponse{
+		Success: false,
+		Data:    []models.RecipeAvailability{},
+		Message: "Failed to compute recipe availability: " + err.Error(),
+	}
+	h.writeJSONResponse(w, response, http.StatusInternalServerError)
+}
+
 // ListRecipes handles GET /recipes
 func (h *RecipeHTTPHandler) ListRecipes(w http.ResponseWriter, r *http.Request) {
 	req := models.ListRecipesRequest{}
@@ -118,7 +274,7 @@ func (h *RecipeHTTPHandler) ListRecipes(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
-	recipes, err := h.dbHandler.List(req)
+	recipes, err := h.dbHandler.List(r.Context(), req)
 	if err != nil {
 		response := models.RecipesResponse{
 			Success: false,
@@ -150,9 +306,9 @@ func (h *RecipeHTTPHandler) UpdateRecipe(w http.ResponseWriter, r *http.Request)
 	}
 
 	var req models.UpdateRecipeRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := httpx.DecodeJSONBody(r, &req); err != nil {
 		h.logger.WithError(err).Error("Invalid JSON in update recipe request")
-		h.writeErrorResponse(w, "Invalid JSON format", http.StatusBadRequest)
+		h.writeErrorResponse(w, httpx.DecodeErrorMessage(err, "Invalid JSON format"), http.StatusBadRequest)
 		return
 	}
 
@@ -182,6 +338,7 @@ func (h *RecipeHTTPHandler) UpdateRecipe(w http.ResponseWriter, r *http.Request)
 		Data:    *recipe,
 		Message: "Recipe updated successfully",
 	}
+	h.logger.WithFields(httpx.RequestLogFields(r)).WithField("recipe_id", recipe.ID).Info("Recipe updated successfully")
 	h.writeJSONResponse(w, response, http.StatusOK)
 }
 
@@ -220,6 +377,7 @@ func (h *RecipeHTTPHandler) DeleteRecipe(w http.ResponseWriter, r *http.Request)
 		Success: true,
 		Message: "Recipe deleted successfully",
 	}
+	h.logger.WithFields(httpx.RequestLogFields(r)).WithField("recipe_id", id).Info("Recipe deleted successfully")
 	h.writeJSONResponse(w, response, http.StatusOK)
 }
 
@@ -567,3 +567,220 @@ func TestRecipeHTTPHandler_DeleteRecipe_MissingID(t *testing.T) {
 
 	assert.Equal(t, http.StatusBadRequest, response.Code)
 }
+
+// expectRecipeByID queues the recipe lookup that GetFullRecipe starts with
+func expectRecipeByID(mock sqlmock.Sqlmock, recipeID string) {
+	now := time.Now()
+	mock.ExpectQuery("SELECT id, recipe_name, recipe_description, picture_url, recipe_category_id, total_recipe_cost, created_at, updated_at").
+		WithArgs(recipeID).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "recipe_name", "recipe_description", "picture_url", "recipe_category_id", "total_recipe_cost", "created_at", "updated_at",
+		}).AddRow(recipeID, "Vanilla Cone", nil, nil, "550e8400-e29b-41d4-a716-446655440001", 2.50, now, now))
+}
+
+var fullRecipeIngredientColumns = []string{
+	"id", "ingredient_id", "ingredient_name", "quantity", "unit_type", "cost_per_unit", "cost_unit_type",
+}
+
+func TestRecipeHTTPHandler_GetFullRecipe(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	handler := NewRecipeHTTPHandler(db, logrus.New())
+
+	recipeID := "550e8400-e29b-41d4-a716-446655440000"
+	milkID := "550e8400-e29b-41d4-a716-446655440010"
+	sugarID := "550e8400-e29b-41d4-a716-446655440011"
+	vanillaID := "550e8400-e29b-41d4-a716-446655440012"
+
+	expectRecipeByID(mock, recipeID)
+	mock.ExpectQuery("FROM recipe_ingredients ri").
+		WithArgs(recipeID).
+		WillReturnRows(sqlmock.NewRows(fullRecipeIngredientColumns).
+			// 500 ml of milk bought at 2.00 per Liter
+			AddRow("ri-1", milkID, "Milk", 500.0, "ml", 2.00, "Liters").
+			// 250 g of sugar bought at 3.00 per Bag, which needs the ingredient's own conversion
+			AddRow("ri-2", sugarID, "Sugar", 250.0, "g", 3.00, "Bag").
+			// Vanilla has never been purchased, so it has no cost
+			AddRow("ri-3", vanillaID, "Vanilla", 5.0, "ml", nil, nil))
+	mock.ExpectQuery("FROM unit_conversions").
+		WithArgs(sugarID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "ingredient_id", "from_unit", "to_unit", "factor", "created_at", "updated_at"}).
+			AddRow("uc-1", sugarID, "Bag", "g", 2000.0, time.Now(), time.Now()))
+
+	request := httptest.NewRequest("GET", "/recipes/"+recipeID+"/full", nil)
+	response := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/recipes/{id}/full", handler.GetFullRecipe)
+	router.ServeHTTP(response, request)
+
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	var result models.FullRecipeResponse
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &result))
+	assert.True(t, result.Success)
+	assert.Equal(t, recipeID, result.Data.Recipe.ID)
+	assert.Equal(t, "Vanilla Cone", result.Data.Recipe.RecipeName)
+
+	require.Len(t, result.Data.Ingredients, 3)
+	milk, sugar, vanilla := result.Data.Ingredients[0], result.Data.Ingredients[1], result.Data.Ingredients[2]
+
+	assert.Equal(t, "Milk", milk.IngredientName)
+	assert.Equal(t, milkID, milk.IngredientID)
+	require.NotNil(t, milk.LineCost)
+	assert.Equal(t, 1.00, *milk.LineCost)
+
+	assert.Equal(t, "Sugar", sugar.IngredientName)
+	require.NotNil(t, sugar.LineCost)
+	assert.Equal(t, 0.38, *sugar.LineCost)
+
+	assert.Equal(t, "Vanilla", vanilla.IngredientName)
+	assert.Nil(t, vanilla.CostPerUnit)
+	assert.Nil(t, vanilla.LineCost)
+
+	assert.Equal(t, 1.38, result.Data.ComputedCost)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRecipeHTTPHandler_GetFullRecipe_NoIngredients(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	handler := NewRecipeHTTPHandler(db, logrus.New())
+
+	recipeID := "550e8400-e29b-41d4-a716-446655440000"
+	expectRecipeByID(mock, recipeID)
+	mock.ExpectQuery("FROM recipe_ingredients ri").
+		WithArgs(recipeID).
+		WillReturnRows(sqlmock.NewRows(fullRecipeIngredientColumns))
+
+	request := httptest.NewRequest("GET", "/recipes/"+recipeID+"/full", nil)
+	response := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/recipes/{id}/full", handler.GetFullRecipe)
+	router.ServeHTTP(response, request)
+
+	require.Equal(t, http.StatusOK, response.Code)
+
+	// The UI iterates the list, so an empty recipe must serialize as [] rather than null
+	var raw struct {
+		Data struct {
+			Ingredients  json.RawMessage `json:"ingredients"`
+			ComputedCost float64         `json:"computed_cost"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &raw))
+	assert.JSONEq(t, "[]", string(raw.Data.Ingredients))
+	assert.Equal(t, 0.0, raw.Data.ComputedCost)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRecipeHTTPHandler_GetFullRecipe_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	handler := NewRecipeHTTPHandler(db, logrus.New())
+
+	mock.ExpectQuery("SELECT id, recipe_name, recipe_description, picture_url, recipe_category_id, total_recipe_cost, created_at, updated_at").
+		WithArgs("non-existent-id").
+		WillReturnError(sql.ErrNoRows)
+
+	request := httptest.NewRequest("GET", "/recipes/non-existent-id/full", nil)
+	response := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/recipes/{id}/full", handler.GetFullRecipe)
+	router.ServeHTTP(response, request)
+
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	var result models.FullRecipeResponse
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &result))
+	assert.False(t, result.Success)
+	assert.Contains(t, result.Message, "not found")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRecipeHTTPHandler_GetRecipesAvailability(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	handler := NewRecipeHTTPHandler(db, logrus.New())
+
+	sugarID := "550e8400-e29b-41d4-a716-446655440011"
+	milkID := "550e8400-e29b-41d4-a716-446655440010"
+
+	// Two recipes share milk; the menu is loaded with three queries whatever its size
+	mock.ExpectQuery("FROM recipes r").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "recipe_name", "ingredient_id", "quantity", "unit_type"}).
+			AddRow("recipe-1", "Milkshake", milkID, 250.0, "ml").
+			AddRow("recipe-1", "Milkshake", sugarID, 50.0, "g").
+			AddRow("recipe-2", "Vanilla Cone", milkID, 0.1, "Liters").
+			AddRow("recipe-3", "Water", nil, nil, nil))
+	mock.ExpectQuery("FROM existences").
+		WillReturnRows(sqlmock.NewRows([]string{"ingredient_id", "unit_type", "units_available"}).
+			AddRow(milkID, "Liters", 1.0).
+			AddRow(sugarID, "Bag", 0.1))
+	mock.ExpectQuery("FROM unit_conversions").
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "ingredient_id", "from_unit", "to_unit", "factor", "created_at", "updated_at"}).
+			AddRow("uc-1", sugarID, "Bag", "g", 2000.0, time.Now(), time.Now()))
+
+	request := httptest.NewRequest("GET", "/recipes/availability", nil)
+	response := httptest.NewRecorder()
+
+	handler.GetRecipesAvailability(response, request)
+
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	var result models.RecipeAvailabilityResponse
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &result))
+	assert.True(t, result.Success)
+	require.Len(t, result.Data, 3)
+
+	// 1 L of milk makes 4 milkshakes, and the bag of sugar (200 g) also makes 4
+	milkshake := result.Data[0]
+	assert.Equal(t, "recipe-1", milkshake.RecipeID)
+	require.NotNil(t, milkshake.MakeableQuantity)
+	assert.Equal(t, 4, *milkshake.MakeableQuantity)
+	assert.True(t, milkshake.Available)
+
+	// The same litre also makes 10 cones on its own
+	cone := result.Data[1]
+	require.NotNil(t, cone.MakeableQuantity)
+	assert.Equal(t, 10, *cone.MakeableQuantity)
+	assert.Equal(t, milkID, *cone.LimitingIngredientID)
+
+	water := result.Data[2]
+	assert.Nil(t, water.MakeableQuantity)
+	assert.True(t, water.Available)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRecipeHTTPHandler_GetRecipesAvailability_DBError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	handler := NewRecipeHTTPHandler(db, logrus.New())
+
+	mock.ExpectQuery("FROM recipes r").WillReturnError(sql.ErrConnDone)
+
+	request := httptest.NewRequest("GET", "/recipes/availability", nil)
+	response := httptest.NewRecorder()
+
+	handler.GetRecipesAvailability(response, request)
+
+	assert.Equal(t, http.StatusInternalServerError, response.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
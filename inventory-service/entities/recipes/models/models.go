@@ -1,6 +1,7 @@
 package models
 
 import (
+	"math"
 	"time"
 )
 
@@ -61,6 +62,132 @@ type ListRecipesRequest struct {
 	Offset           *int    `json:"offset,omitempty" validate:"omitempty,min=0"`
 }
 
+// FullRecipeIngredient is a recipe ingredient with its name resolved and its cost at the latest purchase price
+type FullRecipeIngredient struct {
+	ID             string   `json:"id"`
+	IngredientID   string   `json:"ingredient_id"`
+	IngredientName string   `json:"ingredient_name"`
+	Quantity       float64  `json:"quantity"`
+	UnitType       string   `json:"unit_type"`
+	CostPerUnit    *float64 `json:"cost_per_unit"`  // From the ingredient's most recent existence, in CostUnitType
+	CostUnitType   *string  `json:"cost_unit_type"` // nil when the ingredient has never been purchased
+	LineCost       *float64 `json:"line_cost"`      // nil when the ingredient is unpriced or its unit does not convert
+}
+
+// SetLineCost prices the line from its quantity expressed in CostUnitType
+func (i *FullRecipeIngredient) SetLineCost(quantityInCostUnit float64) {
+	if i.CostPerUnit == nil {
+		return
+	}
+	lineCost := roundCurrency(quantityInCostUnit * *i.CostPerUnit)
+	i.LineCost = &lineCost
+}
+
+// FullRecipe is a recipe together with its ingredients and the cost computed from them
+type FullRecipe struct {
+	Recipe       Recipe                 `json:"recipe"`
+	Ingredients  []FullRecipeIngredient `json:"ingredients"`
+	ComputedCost float64                `json:"computed_cost"`
+}
+
+// ComputeCost sums the priced ingredient lines into ComputedCost; unpriced lines are left out
+func (r *FullRecipe) ComputeCost() {
+	total := 0.0
+	for _, ingredient := range r.Ingredients {
+		if ingredient.LineCost != nil {
+			total += *ingredient.LineCost
+		}
+	}
+	r.ComputedCost = roundCurrency(total)
+}
+
+// RecipeRequirement is one ingredient line of a recipe; the ingredient fields are nil for a recipe
+// without ingredients
+type RecipeRequirement struct {
+	RecipeID     string
+	RecipeName   string
+	IngredientID *string
+	Quantity     *float64
+	UnitType     *string
+}
+
+// IngredientStock is the unexpired units available of an ingredient in one unit type
+type IngredientStock struct {
+	IngredientID   string
+	UnitType       string
+	UnitsAvailable float64
+}
+
+// UnitConverter converts a quantity of an ingredient between units
+type UnitConverter func(value float64, from, to, ingredientID string) (float64, error)
+
+// RecipeAvailability is how many portions of a recipe the current stock can make
+type RecipeAvailability struct {
+	RecipeID   string `json:"recipe_id"`
+	RecipeName string `json:"recipe_name"`
+	Available  bool   `json:"available"`
+	// MakeableQuantity is nil for recipes without ingredients, which stock does not limit
+	MakeableQuantity     *int    `json:"makeable_quantity"`
+	LimitingIngredientID *string `json:"limiting_ingredient_id,omitempty"`
+}
+
+// makeableEpsilon absorbs float error so that e.g. 0.3 / 0.1 counts as 3 portions
+const makeableEpsilon = 1e-9
+
+// ComputeAvailability works out every recipe's makeable quantity from the requirements and the stock.
+// Each recipe is evaluated against the whole stock on its own, so recipes sharing an ingredient each
+// report what they could make if they alone used it. Stock in a unit that does not convert to the
+// recipe's unit is ignored. Results keep the order in which recipes first appear in requirements.
+func ComputeAvailability(requirements []RecipeRequirement, stock []IngredientStock, convert UnitConverter) []RecipeAvailability {
+	stockByIngredient := make(map[string][]IngredientStock)
+	for _, s := range stock {
+		stockByIngredient[s.IngredientID] = append(stockByIngredient[s.IngredientID], s)
+	}
+
+	availability := []RecipeAvailability{}
+	index := make(map[string]int)
+	for _, req := range requirements {
+		i, seen := index[req.RecipeID]
+		if !seen {
+			i = len(availability)
+			index[req.RecipeID] = i
+			availability = append(availability, RecipeAvailability{
+				RecipeID:   req.RecipeID,
+				RecipeName: req.RecipeName,
+				Available:  true,
+			})
+		}
+
+		if req.IngredientID == nil || req.Quantity == nil || req.UnitType == nil || *req.Quantity <= 0 {
+			continue
+		}
+
+		available := 0.0
+		for _, s := range stockByIngredient[*req.IngredientID] {
+			units, err := convert(s.UnitsAvailable, s.UnitType, *req.UnitType, *req.IngredientID)
+			if err != nil {
+				continue
+			}
+			available += units
+		}
+
+		makeable := int(math.Floor(available / *req.Quantity + makeableEpsilon))
+		current := &availability[i]
+		if current.MakeableQuantity == nil || makeable < *current.MakeableQuantity {
+			current.MakeableQuantity = &makeable
+			current.LimitingIngredientID = req.IngredientID
+		}
+		current.Available = *current.MakeableQuantity > 0
+	}
+
+	return availability
+}
+
+// roundCurrency rounds an amount to the two decimals stored by the database
+func roundCurrency(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
 // Response Structs
 // RecipeResponse represents a single recipe response
 type RecipeResponse struct {
@@ -69,6 +196,21 @@ type RecipeResponse struct {
 	Message string `json:"message,omitempty"`
 }
 
+// FullRecipeResponse represents a recipe with its ingredients and computed cost
+type FullRecipeResponse struct {
+	Success bool       `json:"success"`
+	Data    FullRecipe `json:"data"`
+	Message string     `json:"message,omitempty"`
+}
+
+// RecipeAvailabilityResponse represents the availability of every recipe
+type RecipeAvailabilityResponse struct {
+	Success bool                 `json:"success"`
+	Data    []RecipeAvailability `json:"data"`
+	Count   int                  `json:"count"`
+	Message string               `json:"message,omitempty"`
+}
+
 // RecipesResponse represents multiple recipes response
 type RecipesResponse struct {
 	Success bool     `json:"success"`
@@ -1,10 +1,12 @@
 package models
 
 import (
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRecipe_Struct(t *testing.T) {
@@ -274,3 +276,85 @@ func TestCreateRecipeRequest_NilOptionalFields(t *testing.T) {
 	assert.Equal(t, "category-id", req.RecipeCategoryID)
 	assert.Equal(t, 15.50, req.TotalRecipeCost)
 }
+
+func TestFullRecipe_ComputeCost(t *testing.T) {
+	costPerUnit := 2.0
+	priced := FullRecipeIngredient{Quantity: 0.5, CostPerUnit: &costPerUnit}
+	priced.SetLineCost(0.5)
+	unpriced := FullRecipeIngredient{Quantity: 3}
+	unpriced.SetLineCost(3)
+
+	recipe := FullRecipe{Ingredients: []FullRecipeIngredient{priced, unpriced}}
+	recipe.ComputeCost()
+
+	if assert.NotNil(t, priced.LineCost) {
+		assert.Equal(t, 1.0, *priced.LineCost)
+	}
+	assert.Nil(t, unpriced.LineCost)
+	assert.Equal(t, 1.0, recipe.ComputedCost)
+}
+
+func TestComputeAvailability(t *testing.T) {
+	milk, cream, cone := "milk-id", "cream-id", "cone-id"
+	liters, ml, units := "Liters", "ml", "Units"
+	qty := func(v float64) *float64 { return &v }
+
+	// Both shakes share milk; the sundae shares cream with the milkshake
+	requirements := []RecipeRequirement{
+		{RecipeID: "shake", RecipeName: "Milkshake", IngredientID: &milk, Quantity: qty(300), UnitType: &ml},
+		{RecipeID: "shake", RecipeName: "Milkshake", IngredientID: &cream, Quantity: qty(0.1), UnitType: &liters},
+		{RecipeID: "latte", RecipeName: "Iced Latte", IngredientID: &milk, Quantity: qty(0.5), UnitType: &liters},
+		{RecipeID: "sundae", RecipeName: "Sundae", IngredientID: &cream, Quantity: qty(0.05), UnitType: &liters},
+		{RecipeID: "sundae", RecipeName: "Sundae", IngredientID: &cone, Quantity: qty(1), UnitType: &units},
+		{RecipeID: "water", RecipeName: "Water"},
+	}
+	stock := []IngredientStock{
+		// 2 L of milk split across two lots in different units: 2000 ml in total
+		{IngredientID: milk, UnitType: "Liters", UnitsAvailable: 1.5},
+		{IngredientID: milk, UnitType: "ml", UnitsAvailable: 500},
+		{IngredientID: cream, UnitType: "Liters", UnitsAvailable: 0.3},
+		// Stock in a unit that doesn't convert is ignored
+		{IngredientID: cone, UnitType: "Bag", UnitsAvailable: 4},
+	}
+
+	convert := func(value float64, from, to, ingredientID string) (float64, error) {
+		factors := map[string]float64{"Liters>ml": 1000, "ml>Liters": 0.001}
+		if from == to {
+			return value, nil
+		}
+		if factor, ok := factors[from+">"+to]; ok {
+			return value * factor, nil
+		}
+		return 0, errors.New("undefined unit conversion")
+	}
+
+	availability := ComputeAvailability(requirements, stock, convert)
+
+	testCases := map[string]struct {
+		index            int
+		recipeID         string
+		available        bool
+		makeable         *int
+		limitingIngredID *string
+	}{
+		"milkshake limited by cream": {index: 0, recipeID: "shake", available: true, makeable: intPtr(3), limitingIngredID: &cream},
+		"latte uses all the milk":    {index: 1, recipeID: "latte", available: true, makeable: intPtr(4), limitingIngredID: &milk},
+		"sundae has no usable cones": {index: 2, recipeID: "sundae", available: false, makeable: intPtr(0), limitingIngredID: &cone},
+		"recipe without ingredients": {index: 3, recipeID: "water", available: true},
+	}
+
+	require.Len(t, availability, 4)
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got := availability[tc.index]
+			assert.Equal(t, tc.recipeID, got.RecipeID)
+			assert.Equal(t, tc.available, got.Available)
+			assert.Equal(t, tc.makeable, got.MakeableQuantity)
+			assert.Equal(t, tc.limitingIngredID, got.LimitingIngredientID)
+		})
+	}
+}
+
+func intPtr(v int) *int {
+	return &v
+}
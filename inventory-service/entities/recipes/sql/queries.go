@@ -18,3 +18,12 @@ var UpdateRecipeQuery string
 
 //go:embed scripts/delete_recipe.sql
 var DeleteRecipeQuery string
+
+//go:embed scripts/list_recipe_ingredients_with_costs.sql
+var ListRecipeIngredientsWithCostsQuery string
+
+//go:embed scripts/list_recipe_requirements.sql
+var ListRecipeRequirementsQuery string
+
+//go:embed scripts/list_available_ingredient_stock.sql
+var ListAvailableIngredientStockQuery string
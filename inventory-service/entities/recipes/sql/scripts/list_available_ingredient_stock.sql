@@ -0,0 +1,5 @@
+SELECT ingredient_id, unit_type, SUM(units_available) AS units_available
+FROM existences
+WHERE units_available > 0
+    AND (expiration_date IS NULL OR expiration_date >= CURRENT_DATE)
+GROUP BY ingredient_id, unit_type;
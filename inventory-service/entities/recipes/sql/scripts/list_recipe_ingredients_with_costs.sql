@@ -0,0 +1,19 @@
+SELECT
+    ri.id,
+    ri.ingredient_id,
+    i.name AS ingredient_name,
+    ri.quantity,
+    ri.unit_type,
+    latest.cost_per_unit,
+    latest.unit_type AS cost_unit_type
+FROM recipe_ingredients ri
+JOIN ingredients i ON i.id = ri.ingredient_id
+LEFT JOIN LATERAL (
+    SELECT e.cost_per_unit, e.unit_type
+    FROM existences e
+    WHERE e.ingredient_id = ri.ingredient_id
+    ORDER BY e.created_at DESC
+    LIMIT 1
+) latest ON TRUE
+WHERE ri.recipe_id = $1
+ORDER BY i.name ASC;
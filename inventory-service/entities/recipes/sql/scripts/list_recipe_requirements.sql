@@ -0,0 +1,4 @@
+SELECT r.id, r.recipe_name, ri.ingredient_id, ri.quantity, ri.unit_type
+FROM recipes r
+LEFT JOIN recipe_ingredients ri ON ri.recipe_id = r.id
+ORDER BY r.recipe_name ASC, r.id ASC;
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
@@ -72,7 +73,7 @@ func (h *RunoutIngredientDBHandler) GetByID(req models.GetRunoutIngredientReques
 	return &runoutIngredient, nil
 }
 
-func (h *RunoutIngredientDBHandler) List(req models.ListRunoutIngredientsRequest) ([]models.RunoutIngredient, error) {
+func (h *RunoutIngredientDBHandler) List(ctx context.Context, req models.ListRunoutIngredientsRequest) ([]models.RunoutIngredient, error) {
 	limit := 50
 	if req.Limit != nil {
 		limit = *req.Limit
@@ -83,7 +84,7 @@ func (h *RunoutIngredientDBHandler) List(req models.ListRunoutIngredientsRequest
 		offset = *req.Offset
 	}
 
-	rows, err := h.db.Query(
+	rows, err := h.db.QueryContext(ctx,
 		runoutSQL.ListRunoutIngredientsQuery,
 		req.ExistenceID,
 		req.EmployeeID,
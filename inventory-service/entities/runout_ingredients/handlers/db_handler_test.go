@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"testing"
 	"time"
@@ -216,7 +217,7 @@ func TestRunoutIngredientDBHandler_List(t *testing.T) {
 		WillReturnRows(rows)
 
 	req := models.ListRunoutIngredientsRequest{}
-	result, err := handler.List(req)
+	result, err := handler.List(context.Background(), req)
 	require.NoError(t, err)
 	assert.Equal(t, expectedRunoutIngredients, result)
 
@@ -256,7 +257,7 @@ func TestRunoutIngredientDBHandler_List_WithFilters(t *testing.T) {
 		WithArgs(&existenceID, &employeeID, &unitType, &now, limit, offset).
 		WillReturnRows(rows)
 
-	result, err := handler.List(req)
+	result, err := handler.List(context.Background(), req)
 	require.NoError(t, err)
 	assert.Empty(t, result)
 
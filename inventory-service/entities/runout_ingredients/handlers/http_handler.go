@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"inventory-service/entities/runout_ingredients/models"
+	"shared/httpx"
 
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
@@ -28,9 +29,9 @@ func NewRunoutIngredientHTTPHandler(db *sql.DB, logger *logrus.Logger) *RunoutIn
 // CreateRunoutIngredient handles POST /runout-ingredients
 func (h *RunoutIngredientHTTPHandler) CreateRunoutIngredient(w http.ResponseWriter, r *http.Request) {
 	var req models.CreateRunoutIngredientRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := httpx.DecodeJSONBody(r, &req); err != nil {
 		h.logger.WithError(err).Error("Invalid JSON in create runout ingredient request")
-		h.writeErrorResponse(w, "Invalid JSON format", http.StatusBadRequest)
+		h.writeErrorResponse(w, httpx.DecodeErrorMessage(err, "Invalid JSON format"), http.StatusBadRequest)
 		return
 	}
 
@@ -50,6 +51,7 @@ func (h *RunoutIngredientHTTPHandler) CreateRunoutIngredient(w http.ResponseWrit
 		Data:    *runoutIngredient,
 		Message: "Runout ingredient created successfully",
 	}
+	h.logger.WithFields(httpx.RequestLogFields(r)).WithField("runout_ingredient_id", runoutIngredient.ID).Info("Runout ingredient created successfully")
 	h.writeJSONResponse(w, response, http.StatusCreated)
 }
 
@@ -129,7 +131,7 @@ func (h *RunoutIngredientHTTPHandler) ListRunoutIngredients(w http.ResponseWrite
 		}
 	}
 
-	runoutIngredients, err := h.dbHandler.List(req)
+	runoutIngredients, err := h.dbHandler.List(r.Context(), req)
 	if err != nil {
 		response := models.RunoutIngredientsResponse{
 			Success: false,
@@ -161,9 +163,9 @@ func (h *RunoutIngredientHTTPHandler) UpdateRunoutIngredient(w http.ResponseWrit
 	}
 
 	var req models.UpdateRunoutIngredientRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := httpx.DecodeJSONBody(r, &req); err != nil {
 		h.logger.WithError(err).Error("Invalid JSON in update runout ingredient request")
-		h.writeErrorResponse(w, "Invalid JSON format", http.StatusBadRequest)
+		h.writeErrorResponse(w, httpx.DecodeErrorMessage(err, "Invalid JSON format"), http.StatusBadRequest)
 		return
 	}
 
@@ -193,6 +195,7 @@ func (h *RunoutIngredientHTTPHandler) UpdateRunoutIngredient(w http.ResponseWrit
 		Data:    *runoutIngredient,
 		Message: "Runout ingredient updated successfully",
 	}
+	h.logger.WithFields(httpx.RequestLogFields(r)).WithField("runout_ingredient_id", runoutIngredient.ID).Info("Runout ingredient updated successfully")
 	h.writeJSONResponse(w, response, http.StatusOK)
 }
 
@@ -231,6 +234,7 @@ func (h *RunoutIngredientHTTPHandler) DeleteRunoutIngredient(w http.ResponseWrit
 		Success: true,
 		Message: "Runout ingredient deleted successfully",
 	}
+	h.logger.WithFields(httpx.RequestLogFields(r)).WithField("runout_ingredient_id", id).Info("Runout ingredient deleted successfully")
 	h.writeJSONResponse(w, response, http.StatusOK)
 }
 
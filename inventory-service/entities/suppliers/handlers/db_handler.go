@@ -1,11 +1,13 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 
 	"inventory-service/entities/suppliers/models"
 	supplierSQL "inventory-service/entities/suppliers/sql"
 
+	"github.com/lib/pq"
 	"github.com/sirupsen/logrus"
 )
 
@@ -72,8 +74,8 @@ func (h *DBHandler) GetSupplierByID(id string) (*models.Supplier, error) {
 }
 
 // ListSuppliers retrieves all suppliers from the database
-func (h *DBHandler) ListSuppliers() ([]models.Supplier, error) {
-	rows, err := h.db.Query(supplierSQL.ListSuppliersQuery)
+func (h *DBHandler) ListSuppliers(ctx context.Context) ([]models.Supplier, error) {
+	rows, err := h.db.QueryContext(ctx, supplierSQL.ListSuppliersQuery)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to execute suppliers list query")
 		return nil, err
@@ -158,3 +160,94 @@ func (h *DBHandler) DeleteSupplier(id string) error {
 
 	return nil
 }
+
+// MergeSuppliers repoints the invoices and ingredients of the duplicate suppliers to the primary
+// supplier and soft-deletes the duplicates in a single transaction. It returns sql.ErrNoRows when
+// any of the suppliers does not exist or was already merged.
+func (h *DBHandler) MergeSuppliers(req models.MergeSuppliersRequest) (*models.SupplierMergeResult, error) {
+	tx, err := h.db.Begin()
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to begin transaction for supplier merge")
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Lock every supplier involved so a concurrent merge or update cannot interleave
+	ids := append([]string{req.PrimaryID}, req.DuplicateIDs...)
+	rows, err := tx.Query(supplierSQL.LockSuppliersForMergeQuery, pq.Array(ids))
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to lock suppliers for merge")
+		return nil, err
+	}
+	locked := 0
+	for rows.Next() {
+		locked++
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		h.logger.WithError(err).Error("Failed to read suppliers locked for merge")
+		return nil, err
+	}
+
+	if locked != len(ids) {
+		// Don't log as error since "not found" is a normal business case
+		return nil, sql.ErrNoRows
+	}
+
+	result := &models.SupplierMergeResult{
+		PrimaryID: req.PrimaryID,
+		MergedIDs: req.DuplicateIDs,
+	}
+
+	// Repoint invoices
+	result.InvoicesRepointed, err = execRowsAffected(tx, supplierSQL.RepointSupplierInvoicesQuery, req.PrimaryID, pq.Array(req.DuplicateIDs))
+	if err != nil {
+		h.logger.WithError(err).WithFields(logrus.Fields{
+			"primary_id": req.PrimaryID,
+		}).Error("Failed to repoint invoices to primary supplier")
+		return nil, err
+	}
+
+	// Repoint ingredients
+	result.IngredientsRepointed, err = execRowsAffected(tx, supplierSQL.RepointSupplierIngredientsQuery, req.PrimaryID, pq.Array(req.DuplicateIDs))
+	if err != nil {
+		h.logger.WithError(err).WithFields(logrus.Fields{
+			"primary_id": req.PrimaryID,
+		}).Error("Failed to repoint ingredients to primary supplier")
+		return nil, err
+	}
+
+	result.RepointedReferences = result.InvoicesRepointed + result.IngredientsRepointed
+
+	// Soft delete the duplicates
+	if _, err = execRowsAffected(tx, supplierSQL.SoftDeleteSuppliersQuery, pq.Array(req.DuplicateIDs)); err != nil {
+		h.logger.WithError(err).WithFields(logrus.Fields{
+			"duplicate_ids": req.DuplicateIDs,
+		}).Error("Failed to soft delete merged suppliers")
+		return nil, err
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		h.logger.WithError(err).Error("Failed to commit supplier merge transaction")
+		return nil, err
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"primary_id":            req.PrimaryID,
+		"merged_ids":            req.DuplicateIDs,
+		"invoices_repointed":    result.InvoicesRepointed,
+		"ingredients_repointed": result.IngredientsRepointed,
+	}).Info("Suppliers merged successfully")
+
+	return result, nil
+}
+
+// execRowsAffected runs a statement within tx and returns how many rows it changed
+func execRowsAffected(tx *sql.Tx, query string, args ...interface{}) (int64, error) {
+	result, err := tx.Exec(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"regexp"
@@ -10,6 +11,7 @@ import (
 	"inventory-service/entities/suppliers/models"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -117,7 +119,7 @@ func TestDBHandler_GetSupplierByID_Success(t *testing.T) {
 		AddRow(expectedSupplier.ID, expectedSupplier.SupplierName, nil, nil, nil, nil,
 			expectedSupplier.CreatedAt, expectedSupplier.UpdatedAt)
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, supplier_name, contact_number, email, address, notes, created_at, updated_at FROM suppliers WHERE id = $1")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, supplier_name, contact_number, email, address, notes, created_at, updated_at FROM suppliers WHERE id = $1 AND deleted_at IS NULL")).
 		WithArgs(supplierID).
 		WillReturnRows(rows)
 
@@ -138,7 +140,7 @@ func TestDBHandler_GetSupplierByID_NotFound(t *testing.T) {
 	supplierID := "nonexistent-id"
 
 	// Mock no rows returned
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, supplier_name, contact_number, email, address, notes, created_at, updated_at FROM suppliers WHERE id = $1")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, supplier_name, contact_number, email, address, notes, created_at, updated_at FROM suppliers WHERE id = $1 AND deleted_at IS NULL")).
 		WithArgs(supplierID).
 		WillReturnError(sql.ErrNoRows)
 
@@ -177,11 +179,11 @@ func TestDBHandler_ListSuppliers_Success(t *testing.T) {
 		AddRow(expectedSuppliers[1].ID, expectedSuppliers[1].SupplierName, nil, nil, nil, nil,
 			expectedSuppliers[1].CreatedAt, expectedSuppliers[1].UpdatedAt)
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, supplier_name, contact_number, email, address, notes, created_at, updated_at FROM suppliers ORDER BY supplier_name ASC")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, supplier_name, contact_number, email, address, notes, created_at, updated_at FROM suppliers WHERE deleted_at IS NULL ORDER BY supplier_name ASC")).
 		WillReturnRows(rows)
 
 	// Execute
-	result, err := handler.ListSuppliers()
+	result, err := handler.ListSuppliers(context.Background())
 
 	// Assert
 	assert.NoError(t, err)
@@ -197,11 +199,11 @@ func TestDBHandler_ListSuppliers_Empty(t *testing.T) {
 	// Mock empty result set
 	rows := sqlmock.NewRows([]string{"id", "supplier_name", "contact_number", "email", "address", "notes", "created_at", "updated_at"})
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, supplier_name, contact_number, email, address, notes, created_at, updated_at FROM suppliers ORDER BY supplier_name ASC")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, supplier_name, contact_number, email, address, notes, created_at, updated_at FROM suppliers WHERE deleted_at IS NULL ORDER BY supplier_name ASC")).
 		WillReturnRows(rows)
 
 	// Execute
-	result, err := handler.ListSuppliers()
+	result, err := handler.ListSuppliers(context.Background())
 
 	// Assert
 	assert.NoError(t, err)
@@ -324,6 +326,108 @@ func TestDBHandler_DeleteSupplier_DatabaseError(t *testing.T) {
 	assert.Contains(t, err.Error(), "database connection failed")
 }
 
+// TestDBHandler_MergeSuppliers tests that references move to the primary supplier and the duplicates
+// are soft-deleted in one transaction that is rolled back as a whole on any failure
+func TestDBHandler_MergeSuppliers(t *testing.T) {
+	req := models.MergeSuppliersRequest{
+		PrimaryID:    "123e4567-e89b-12d3-a456-426614174000",
+		DuplicateIDs: []string{"223e4567-e89b-12d3-a456-426614174000", "323e4567-e89b-12d3-a456-426614174000"},
+	}
+	allIDs := append([]string{req.PrimaryID}, req.DuplicateIDs...)
+
+	expectLock := func(mock sqlmock.Sqlmock, ids ...string) {
+		rows := sqlmock.NewRows([]string{"id"})
+		for _, id := range ids {
+			rows.AddRow(id)
+		}
+		mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE")).
+			WithArgs(pq.Array(allIDs)).
+			WillReturnRows(rows)
+	}
+
+	testCases := map[string]struct {
+		setupMock      func(mock sqlmock.Sqlmock)
+		expectedResult *models.SupplierMergeResult
+		expectedError  string
+	}{
+		"repoints references and soft deletes duplicates": {
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectLock(mock, allIDs...)
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE invoice")).
+					WithArgs(req.PrimaryID, pq.Array(req.DuplicateIDs)).
+					WillReturnResult(sqlmock.NewResult(0, 4))
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE ingredients")).
+					WithArgs(req.PrimaryID, pq.Array(req.DuplicateIDs)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta("deleted_at = CURRENT_TIMESTAMP")).
+					WithArgs(pq.Array(req.DuplicateIDs)).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectCommit()
+			},
+			expectedResult: &models.SupplierMergeResult{
+				PrimaryID:            req.PrimaryID,
+				MergedIDs:            req.DuplicateIDs,
+				InvoicesRepointed:    4,
+				IngredientsRepointed: 1,
+				RepointedReferences:  5,
+			},
+		},
+		"missing supplier changes nothing": {
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectLock(mock, req.PrimaryID, req.DuplicateIDs[0])
+				mock.ExpectRollback()
+			},
+			expectedError: sql.ErrNoRows.Error(),
+		},
+		"soft delete failure rolls back repointed references": {
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectLock(mock, allIDs...)
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE invoice")).
+					WillReturnResult(sqlmock.NewResult(0, 4))
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE ingredients")).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta("deleted_at = CURRENT_TIMESTAMP")).
+					WillReturnError(fmt.Errorf("database connection failed"))
+				mock.ExpectRollback()
+			},
+			expectedError: "database connection failed",
+		},
+		"repoint failure rolls back": {
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectLock(mock, allIDs...)
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE invoice")).
+					WillReturnError(fmt.Errorf("database connection failed"))
+				mock.ExpectRollback()
+			},
+			expectedError: "database connection failed",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler, mock, cleanup := setupTestDBHandler(t)
+			defer cleanup()
+
+			tc.setupMock(mock)
+
+			result, err := handler.MergeSuppliers(req)
+
+			if tc.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedError)
+				assert.Nil(t, result)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedResult, result)
+		})
+	}
+}
+
 // Helper function to create string pointers
 func stringPtr(s string) *string {
 	return &s
@@ -1,11 +1,13 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"net/http"
 
 	"inventory-service/entities/suppliers/models"
+	"shared/httpx"
 
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
@@ -15,9 +17,10 @@ import (
 type DBHandlerInterface interface {
 	CreateSupplier(req models.CreateSupplierRequest) (*models.Supplier, error)
 	GetSupplierByID(id string) (*models.Supplier, error)
-	ListSuppliers() ([]models.Supplier, error)
+	ListSuppliers(ctx context.Context) ([]models.Supplier, error)
 	UpdateSupplier(id string, req models.UpdateSupplierRequest) (*models.Supplier, error)
 	DeleteSupplier(id string) error
+	MergeSuppliers(req models.MergeSuppliersRequest) (*models.SupplierMergeResult, error)
 }
 
 // Ensure DBHandler implements DBHandlerInterface
@@ -48,9 +51,9 @@ func NewHttpHandlerWithInterface(dbHandler DBHandlerInterface, logger *logrus.Lo
 // CreateSupplier handles POST /suppliers
 func (h *HttpHandler) CreateSupplier(w http.ResponseWriter, r *http.Request) {
 	var req models.CreateSupplierRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := httpx.DecodeJSONBody(r, &req); err != nil {
 		h.logger.WithError(err).Error("Invalid JSON in create supplier request")
-		h.writeErrorResponse(w, "Invalid JSON format", http.StatusBadRequest)
+		h.writeErrorResponse(w, httpx.DecodeErrorMessage(err, "Invalid JSON format"), http.StatusBadRequest)
 		return
 	}
 
@@ -71,6 +74,7 @@ func (h *HttpHandler) CreateSupplier(w http.ResponseWriter, r *http.Request) {
 		Data:    *supplier,
 		Message: "Supplier created successfully",
 	}
+	h.logger.WithFields(httpx.RequestLogFields(r)).WithField("supplier_id", supplier.ID).Info("Supplier created successfully")
 	h.writeJSONResponse(w, response, http.StatusCreated)
 }
 
@@ -122,7 +126,7 @@ func (h *HttpHandler) ListSuppliers(w http.ResponseWriter, r *http.Request) {
 	// limit := r.URL.Query().Get("limit")
 	// offset := r.URL.Query().Get("offset")
 
-	suppliers, err := h.dbHandler.ListSuppliers()
+	suppliers, err := h.dbHandler.ListSuppliers(r.Context())
 	if err != nil {
 		// DBHandler already logged the error, don't duplicate
 		response := models.SuppliersListResponse{
@@ -156,9 +160,9 @@ func (h *HttpHandler) UpdateSupplier(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req models.UpdateSupplierRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := httpx.DecodeJSONBody(r, &req); err != nil {
 		h.logger.WithError(err).Error("Invalid JSON in update supplier request")
-		h.writeErrorResponse(w, "Invalid JSON format", http.StatusBadRequest)
+		h.writeErrorResponse(w, httpx.DecodeErrorMessage(err, "Invalid JSON format"), http.StatusBadRequest)
 		return
 	}
 
@@ -190,6 +194,7 @@ func (h *HttpHandler) UpdateSupplier(w http.ResponseWriter, r *http.Request) {
 		Data:    *supplier,
 		Message: "Supplier updated successfully",
 	}
+	h.logger.WithFields(httpx.RequestLogFields(r)).WithField("supplier_id", supplier.ID).Info("Supplier updated successfully")
 	h.writeJSONResponse(w, response, http.StatusOK)
 }
 
@@ -229,6 +234,74 @@ func (h *HttpHandler) DeleteSupplier(w http.ResponseWriter, r *http.Request) {
 		Success: true,
 		Message: "Supplier deleted successfully",
 	}
+	h.logger.WithFields(httpx.RequestLogFields(r)).WithField("supplier_id", id).Info("Supplier deleted successfully")
+	h.writeJSONResponse(w, response, http.StatusOK)
+}
+
+// MergeSuppliers handles POST /suppliers/merge
+func (h *HttpHandler) MergeSuppliers(w http.ResponseWriter, r *http.Request) {
+	var req models.MergeSuppliersRequest
+	if err := httpx.DecodeJSONBody(r, &req); err != nil {
+		h.logger.WithError(err).Error("Invalid JSON in merge suppliers request")
+		h.writeErrorResponse(w, httpx.DecodeErrorMessage(err, "Invalid JSON format"), http.StatusBadRequest)
+		return
+	}
+
+	if req.PrimaryID == "" {
+		h.writeErrorResponse(w, "primary_id is required", http.StatusBadRequest)
+		return
+	}
+
+	// Drop repeated IDs so the lock count matches the number of distinct suppliers
+	seen := make(map[string]bool, len(req.DuplicateIDs))
+	duplicateIDs := make([]string, 0, len(req.DuplicateIDs))
+	for _, id := range req.DuplicateIDs {
+		if id == "" {
+			h.writeErrorResponse(w, "duplicate_ids must not contain empty IDs", http.StatusBadRequest)
+			return
+		}
+		if id == req.PrimaryID {
+			h.writeErrorResponse(w, "primary_id cannot also be listed in duplicate_ids", http.StatusBadRequest)
+			return
+		}
+		if !seen[id] {
+			seen[id] = true
+			duplicateIDs = append(duplicateIDs, id)
+		}
+	}
+	if len(duplicateIDs) == 0 {
+		h.writeErrorResponse(w, "duplicate_ids must list at least one supplier", http.StatusBadRequest)
+		return
+	}
+	req.DuplicateIDs = duplicateIDs
+
+	result, err := h.dbHandler.MergeSuppliers(req)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			// This is expected behavior, don't log as error
+			response := models.SupplierMergeResponse{
+				Success: false,
+				Message: "One or more suppliers not found",
+			}
+			h.writeJSONResponse(w, response, http.StatusNotFound)
+			return
+		}
+
+		// DBHandler already logged the error, don't duplicate
+		response := models.SupplierMergeResponse{
+			Success: false,
+			Message: "Failed to merge suppliers: " + err.Error(),
+		}
+		h.writeJSONResponse(w, response, http.StatusInternalServerError)
+		return
+	}
+
+	response := models.SupplierMergeResponse{
+		Success: true,
+		Data:    *result,
+		Message: "Suppliers merged successfully",
+	}
+	h.logger.WithFields(httpx.RequestLogFields(r)).WithFields(logrus.Fields{"supplier_id": result.PrimaryID, "merged_ids": result.MergedIDs}).Info("Suppliers merged successfully")
 	h.writeJSONResponse(w, response, http.StatusOK)
 }
 
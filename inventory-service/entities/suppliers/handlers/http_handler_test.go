@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -13,6 +14,9 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestMockDBHandler implements DBHandlerInterface for testing (renamed to avoid conflict)
@@ -22,6 +26,7 @@ type TestMockDBHandler struct {
 	ListSuppliersFunc   func() ([]models.Supplier, error)
 	UpdateSupplierFunc  func(id string, req models.UpdateSupplierRequest) (*models.Supplier, error)
 	DeleteSupplierFunc  func(id string) error
+	MergeSuppliersFunc  func(req models.MergeSuppliersRequest) (*models.SupplierMergeResult, error)
 }
 
 // Ensure TestMockDBHandler implements DBHandlerInterface
@@ -41,7 +46,7 @@ func (m *TestMockDBHandler) GetSupplierByID(id string) (*models.Supplier, error)
 	return nil, nil
 }
 
-func (m *TestMockDBHandler) ListSuppliers() ([]models.Supplier, error) {
+func (m *TestMockDBHandler) ListSuppliers(ctx context.Context) ([]models.Supplier, error) {
 	if m.ListSuppliersFunc != nil {
 		return m.ListSuppliersFunc()
 	}
@@ -62,6 +67,13 @@ func (m *TestMockDBHandler) DeleteSupplier(id string) error {
 	return nil
 }
 
+func (m *TestMockDBHandler) MergeSuppliers(req models.MergeSuppliersRequest) (*models.SupplierMergeResult, error) {
+	if m.MergeSuppliersFunc != nil {
+		return m.MergeSuppliersFunc(req)
+	}
+	return nil, nil
+}
+
 func setupTestHttpHandler() (*HttpHandler, *TestMockDBHandler) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.PanicLevel) // Suppress logs during testing
@@ -362,6 +374,129 @@ func TestHttpHandler_DeleteSupplier_Success(t *testing.T) {
 	}
 }
 
+// TestHttpHandler_DeleteSupplier_LogsUser tests that a deletion is logged with the user the gateway forwarded
+func TestHttpHandler_DeleteSupplier_LogsUser(t *testing.T) {
+	testCases := map[string]struct {
+		userID string
+	}{
+		"header set":    {userID: "user-123"},
+		"header absent": {},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			logger, hook := test.NewNullLogger()
+			mockDB := &TestMockDBHandler{DeleteSupplierFunc: func(id string) error { return nil }}
+			handler := NewHttpHandlerWithInterface(mockDB, logger)
+
+			supplierID := "123e4567-e89b-12d3-a456-426614174000"
+			req := httptest.NewRequest(http.MethodDelete, "/suppliers/"+supplierID, nil)
+			req = mux.SetURLVars(req, map[string]string{"id": supplierID})
+			if tc.userID != "" {
+				req.Header.Set("X-User-ID", tc.userID)
+			}
+
+			handler.DeleteSupplier(httptest.NewRecorder(), req)
+
+			entry := hook.LastEntry()
+			require.NotNil(t, entry)
+			assert.Equal(t, "Supplier deleted successfully", entry.Message)
+			assert.Equal(t, supplierID, entry.Data["supplier_id"])
+			userID, ok := entry.Data["user_id"]
+			if tc.userID == "" {
+				assert.False(t, ok)
+				return
+			}
+			assert.Equal(t, tc.userID, userID)
+		})
+	}
+}
+
+// TestHttpHandler_MergeSuppliers tests request validation, duplicate ID normalisation and the response codes
+func TestHttpHandler_MergeSuppliers(t *testing.T) {
+	primaryID := "123e4567-e89b-12d3-a456-426614174000"
+	duplicateID := "223e4567-e89b-12d3-a456-426614174000"
+
+	testCases := map[string]struct {
+		body                 string
+		mergeErr             error
+		expectedStatus       int
+		expectedDuplicateIDs []string
+	}{
+		"merges duplicates and drops repeated IDs": {
+			body:                 fmt.Sprintf(`{"primary_id": %q, "duplicate_ids": [%q, %q]}`, primaryID, duplicateID, duplicateID),
+			expectedStatus:       http.StatusOK,
+			expectedDuplicateIDs: []string{duplicateID},
+		},
+		"missing primary": {
+			body:           fmt.Sprintf(`{"duplicate_ids": [%q]}`, duplicateID),
+			expectedStatus: http.StatusBadRequest,
+		},
+		"no duplicates": {
+			body:           fmt.Sprintf(`{"primary_id": %q, "duplicate_ids": []}`, primaryID),
+			expectedStatus: http.StatusBadRequest,
+		},
+		"primary listed as duplicate": {
+			body:           fmt.Sprintf(`{"primary_id": %q, "duplicate_ids": [%q]}`, primaryID, primaryID),
+			expectedStatus: http.StatusBadRequest,
+		},
+		"invalid json": {
+			body:           `{"primary_id":`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		"supplier not found": {
+			body:                 fmt.Sprintf(`{"primary_id": %q, "duplicate_ids": [%q]}`, primaryID, duplicateID),
+			mergeErr:             sql.ErrNoRows,
+			expectedStatus:       http.StatusNotFound,
+			expectedDuplicateIDs: []string{duplicateID},
+		},
+		"database error": {
+			body:                 fmt.Sprintf(`{"primary_id": %q, "duplicate_ids": [%q]}`, primaryID, duplicateID),
+			mergeErr:             fmt.Errorf("database connection failed"),
+			expectedStatus:       http.StatusInternalServerError,
+			expectedDuplicateIDs: []string{duplicateID},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler, mockDB := setupTestHttpHandler()
+
+			called := false
+			mockDB.MergeSuppliersFunc = func(req models.MergeSuppliersRequest) (*models.SupplierMergeResult, error) {
+				called = true
+				assert.Equal(t, primaryID, req.PrimaryID)
+				assert.Equal(t, tc.expectedDuplicateIDs, req.DuplicateIDs)
+				if tc.mergeErr != nil {
+					return nil, tc.mergeErr
+				}
+				return &models.SupplierMergeResult{
+					PrimaryID:           req.PrimaryID,
+					MergedIDs:           req.DuplicateIDs,
+					InvoicesRepointed:   3,
+					RepointedReferences: 3,
+				}, nil
+			}
+
+			req := httptest.NewRequest("POST", "/suppliers/merge", bytes.NewBufferString(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			rr := httptest.NewRecorder()
+
+			handler.MergeSuppliers(rr, req)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+			assert.Equal(t, tc.expectedDuplicateIDs != nil, called)
+
+			if tc.expectedStatus == http.StatusOK {
+				var response models.SupplierMergeResponse
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+				assert.True(t, response.Success)
+				assert.Equal(t, int64(3), response.Data.RepointedReferences)
+			}
+		})
+	}
+}
+
 // Helper function to create string pointers
 func stringPtrForTest(s string) *string {
 	return &s
@@ -44,6 +44,12 @@ type DeleteSupplierRequest struct {
 	ID string `json:"id" validate:"required,uuid"`
 }
 
+// MergeSuppliersRequest represents the request to merge duplicate suppliers into a primary one
+type MergeSuppliersRequest struct {
+	PrimaryID    string   `json:"primary_id" validate:"required,uuid"`
+	DuplicateIDs []string `json:"duplicate_ids" validate:"required,min=1,dive,uuid"`
+}
+
 // ListSuppliersRequest represents the request to list suppliers (for future pagination)
 type ListSuppliersRequest struct {
 	Limit  *int `json:"limit,omitempty" validate:"omitempty,min=1,max=100"`
@@ -72,6 +78,22 @@ type SupplierDeleteResponse struct {
 	Message string `json:"message"`
 }
 
+// SupplierMergeResult reports what a supplier merge changed
+type SupplierMergeResult struct {
+	PrimaryID            string   `json:"primary_id"`
+	MergedIDs            []string `json:"merged_ids"`
+	InvoicesRepointed    int64    `json:"invoices_repointed"`
+	IngredientsRepointed int64    `json:"ingredients_repointed"`
+	RepointedReferences  int64    `json:"repointed_references"`
+}
+
+// SupplierMergeResponse represents a merge operation response
+type SupplierMergeResponse struct {
+	Success bool                `json:"success"`
+	Data    SupplierMergeResult `json:"data"`
+	Message string              `json:"message,omitempty"`
+}
+
 // ErrorResponse represents an error response
 type ErrorResponse struct {
 	Success bool   `json:"success"`
@@ -18,3 +18,17 @@ var UpdateSupplierQuery string
 
 //go:embed scripts/delete_supplier.sql
 var DeleteSupplierQuery string
+
+// Supplier merge queries
+//
+//go:embed scripts/lock_suppliers_for_merge.sql
+var LockSuppliersForMergeQuery string
+
+//go:embed scripts/repoint_supplier_invoices.sql
+var RepointSupplierInvoicesQuery string
+
+//go:embed scripts/repoint_supplier_ingredients.sql
+var RepointSupplierIngredientsQuery string
+
+//go:embed scripts/soft_delete_suppliers.sql
+var SoftDeleteSuppliersQuery string
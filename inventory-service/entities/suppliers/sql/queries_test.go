@@ -15,6 +15,10 @@ func TestQueryConstants(t *testing.T) {
 	assert.NotEmpty(t, GetSupplierByIDQuery, "GetSupplierByIDQuery should not be empty")
 	assert.NotEmpty(t, UpdateSupplierQuery, "UpdateSupplierQuery should not be empty")
 	assert.NotEmpty(t, DeleteSupplierQuery, "DeleteSupplierQuery should not be empty")
+	assert.NotEmpty(t, LockSuppliersForMergeQuery, "LockSuppliersForMergeQuery should not be empty")
+	assert.NotEmpty(t, RepointSupplierInvoicesQuery, "RepointSupplierInvoicesQuery should not be empty")
+	assert.NotEmpty(t, RepointSupplierIngredientsQuery, "RepointSupplierIngredientsQuery should not be empty")
+	assert.NotEmpty(t, SoftDeleteSuppliersQuery, "SoftDeleteSuppliersQuery should not be empty")
 }
 
 // TestQueryStructure tests that queries have expected SQL structure
@@ -56,6 +60,19 @@ func TestQueryStructure(t *testing.T) {
 		assert.Contains(t, query, "WHERE", "Should have WHERE clause")
 		assert.Contains(t, query, "ID", "Should filter by ID")
 	})
+
+	t.Run("soft deleted suppliers are hidden", func(t *testing.T) {
+		for _, query := range []string{ListSuppliersQuery, GetSupplierByIDQuery, UpdateSupplierQuery} {
+			assert.Contains(t, strings.ToUpper(query), "DELETED_AT IS NULL", "Should skip merged suppliers")
+		}
+	})
+
+	t.Run("SoftDeleteSuppliersQuery", func(t *testing.T) {
+		query := strings.ToUpper(SoftDeleteSuppliersQuery)
+		assert.Contains(t, query, "UPDATE SUPPLIERS", "Should update suppliers rather than delete them")
+		assert.Contains(t, query, "DELETED_AT = CURRENT_TIMESTAMP", "Should mark suppliers as deleted")
+		assert.NotContains(t, query, "DELETE FROM", "Should not remove rows")
+	})
 }
 
 // TestQueryParameters tests that queries have expected parameter placeholders
@@ -95,6 +112,10 @@ func TestQueryValidation(t *testing.T) {
 		{"GetSupplierByIDQuery", GetSupplierByIDQuery},
 		{"UpdateSupplierQuery", UpdateSupplierQuery},
 		{"DeleteSupplierQuery", DeleteSupplierQuery},
+		{"LockSuppliersForMergeQuery", LockSuppliersForMergeQuery},
+		{"RepointSupplierInvoicesQuery", RepointSupplierInvoicesQuery},
+		{"RepointSupplierIngredientsQuery", RepointSupplierIngredientsQuery},
+		{"SoftDeleteSuppliersQuery", SoftDeleteSuppliersQuery},
 	}
 
 	for _, q := range queries {
@@ -1,3 +1,3 @@
 SELECT id, supplier_name, contact_number, email, address, notes, created_at, updated_at
 FROM suppliers
-WHERE id = $1; 
\ No newline at end of file
+WHERE id = $1 AND deleted_at IS NULL;
\ No newline at end of file
@@ -1,3 +1,4 @@
 SELECT id, supplier_name, contact_number, email, address, notes, created_at, updated_at
 FROM suppliers
-ORDER BY supplier_name ASC; 
\ No newline at end of file
+WHERE deleted_at IS NULL
+ORDER BY supplier_name ASC;
\ No newline at end of file
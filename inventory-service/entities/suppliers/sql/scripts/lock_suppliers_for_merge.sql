@@ -0,0 +1,4 @@
+SELECT id
+FROM suppliers
+WHERE id = ANY($1::uuid[]) AND deleted_at IS NULL
+FOR UPDATE;
\ No newline at end of file
@@ -0,0 +1,5 @@
+UPDATE invoice
+SET 
+    supplier_id = $1,
+    updated_at = CURRENT_TIMESTAMP
+WHERE supplier_id = ANY($2::uuid[]);
\ No newline at end of file
@@ -0,0 +1,5 @@
+UPDATE suppliers
+SET 
+    deleted_at = CURRENT_TIMESTAMP,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ANY($1::uuid[]) AND deleted_at IS NULL;
\ No newline at end of file
@@ -6,5 +6,5 @@ SET
     address = COALESCE($5, address),
     notes = COALESCE($6, notes),
     updated_at = CURRENT_TIMESTAMP
-WHERE id = $1
+WHERE id = $1 AND deleted_at IS NULL
 RETURNING id, supplier_name, contact_number, email, address, notes, created_at, updated_at; 
\ No newline at end of file
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"inventory-service/entities/unit_conversions/models"
+	unitConversionSQL "inventory-service/entities/unit_conversions/sql"
+
+	"github.com/lib/pq"
+)
+
+// querier is implemented by both *sql.DB and *sql.Tx, so conversions can be read inside a caller's transaction
+type querier interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+type UnitConversionDBHandler struct {
+	db querier
+}
+
+func NewUnitConversionDBHandler(db *sql.DB) *UnitConversionDBHandler {
+	return &UnitConversionDBHandler{db: db}
+}
+
+// NewUnitConversionTxHandler creates a handler that reads conversions inside tx
+func NewUnitConversionTxHandler(tx *sql.Tx) *UnitConversionDBHandler {
+	return &UnitConversionDBHandler{db: tx}
+}
+
+// GetIngredientConversions returns the conversion factors defined for a single ingredient
+func (h *UnitConversionDBHandler) GetIngredientConversions(ingredientID string) ([]models.UnitConversion, error) {
+	rows, err := h.db.Query(unitConversionSQL.GetIngredientUnitConversionsQuery, ingredientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unit conversions: %w", err)
+	}
+	defer rows.Close()
+
+	return scanUnitConversions(rows)
+}
+
+// GetConversionsForIngredients loads the conversion factors of several ingredients in one query, keyed by
+// ingredient ID, for callers converting many quantities at once
+func (h *UnitConversionDBHandler) GetConversionsForIngredients(ingredientIDs []string) (map[string][]models.UnitConversion, error) {
+	conversions := make(map[string][]models.UnitConversion)
+	if len(ingredientIDs) == 0 {
+		return conversions, nil
+	}
+
+	rows, err := h.db.Query(unitConversionSQL.ListUnitConversionsForIngredientsQuery, pq.Array(ingredientIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unit conversions: %w", err)
+	}
+	defer rows.Close()
+
+	scanned, err := scanUnitConversions(rows)
+	if err != nil {
+		return nil, err
+	}
+	for _, conversion := range scanned {
+		conversions[conversion.IngredientID] = append(conversions[conversion.IngredientID], conversion)
+	}
+
+	return conversions, nil
+}
+
+// scanUnitConversions reads every unit conversion row selected by the unit conversion queries
+func scanUnitConversions(rows *sql.Rows) ([]models.UnitConversion, error) {
+	var conversions []models.UnitConversion
+	for rows.Next() {
+		var conversion models.UnitConversion
+		err := rows.Scan(
+			&conversion.ID,
+			&conversion.IngredientID,
+			&conversion.FromUnit,
+			&conversion.ToUnit,
+			&conversion.Factor,
+			&conversion.CreatedAt,
+			&conversion.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan unit conversion: %w", err)
+		}
+		conversions = append(conversions, conversion)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating unit conversions: %w", err)
+	}
+
+	return conversions, nil
+}
+
+// ConvertUnits converts value between units for an ingredient. Standard conversions (L <-> ml, kg <-> g, ...)
+// need no database access; anything else falls back to the ingredient's own conversion factors.
+// Returns an error wrapping models.ErrUndefinedConversion when no conversion exists.
+func (h *UnitConversionDBHandler) ConvertUnits(value float64, from, to, ingredientID string) (float64, error) {
+	converted, err := models.ConvertStandardUnits(value, from, to)
+	if err == nil || !errors.Is(err, models.ErrUndefinedConversion) {
+		return converted, err
+	}
+
+	conversions, err := h.GetIngredientConversions(ingredientID)
+	if err != nil {
+		return 0, err
+	}
+
+	return models.ApplyConversions(value, from, to, conversions)
+}
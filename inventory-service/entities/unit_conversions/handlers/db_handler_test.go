@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"inventory-service/entities/unit_conversions/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var unitConversionColumns = []string{"id", "ingredient_id", "from_unit", "to_unit", "factor", "created_at", "updated_at"}
+
+func TestUnitConversionDBHandler_ConvertUnits(t *testing.T) {
+	ingredientID := "550e8400-e29b-41d4-a716-446655440001"
+
+	testCases := map[string]struct {
+		value       float64
+		from        string
+		to          string
+		setupMock   func(sqlmock.Sqlmock)
+		expected    float64
+		expectedErr error
+	}{
+		"standard_liters_to_ml": {
+			value:     2,
+			from:      "Liters",
+			to:        "ml",
+			setupMock: func(mock sqlmock.Sqlmock) {},
+			expected:  2000,
+		},
+		"standard_kg_to_g": {
+			value:     0.25,
+			from:      "kg",
+			to:        "g",
+			setupMock: func(mock sqlmock.Sqlmock) {},
+			expected:  250,
+		},
+		"ingredient_conversion": {
+			value: 3,
+			from:  "Bag",
+			to:    "kg",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM unit_conversions").
+					WithArgs(ingredientID).
+					WillReturnRows(sqlmock.NewRows(unitConversionColumns).
+						AddRow("550e8400-e29b-41d4-a716-446655440002", ingredientID, "Bag", "kg", 25.0, time.Now(), time.Now()))
+			},
+			expected: 75,
+		},
+		"ingredient_conversion_reverse": {
+			value: 50,
+			from:  "kg",
+			to:    "Bag",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM unit_conversions").
+					WithArgs(ingredientID).
+					WillReturnRows(sqlmock.NewRows(unitConversionColumns).
+						AddRow("550e8400-e29b-41d4-a716-446655440002", ingredientID, "Bag", "kg", 25.0, time.Now(), time.Now()))
+			},
+			expected: 2,
+		},
+		"undefined_conversion": {
+			value: 1,
+			from:  "Bag",
+			to:    "Liters",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM unit_conversions").
+					WithArgs(ingredientID).
+					WillReturnRows(sqlmock.NewRows(unitConversionColumns))
+			},
+			expectedErr: models.ErrUndefinedConversion,
+		},
+		"database_error": {
+			value: 1,
+			from:  "Bag",
+			to:    "Liters",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM unit_conversions").
+					WithArgs(ingredientID).
+					WillReturnError(sql.ErrConnDone)
+			},
+			expectedErr: sql.ErrConnDone,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			handler := NewUnitConversionDBHandler(db)
+			tc.setupMock(mock)
+
+			result, err := handler.ConvertUnits(tc.value, tc.from, tc.to, ingredientID)
+
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+			} else {
+				require.NoError(t, err)
+				assert.InDelta(t, tc.expected, result, 1e-9)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUnitConversionDBHandler_GetConversionsForIngredients(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	handler := NewUnitConversionDBHandler(db)
+	sugarID := "550e8400-e29b-41d4-a716-446655440001"
+	coneID := "550e8400-e29b-41d4-a716-446655440002"
+
+	now := time.Now()
+	mock.ExpectQuery("FROM unit_conversions").
+		WithArgs(`{"` + sugarID + `","` + coneID + `"}`).
+		WillReturnRows(sqlmock.NewRows(unitConversionColumns).
+			AddRow("uc-1", sugarID, "Bag", "g", 2000.0, now, now).
+			AddRow("uc-2", coneID, "Box", "Units", 24.0, now, now).
+			AddRow("uc-3", coneID, "Pack", "Units", 6.0, now, now))
+
+	conversions, err := handler.GetConversionsForIngredients([]string{sugarID, coneID})
+	require.NoError(t, err)
+	assert.Len(t, conversions[sugarID], 1)
+	assert.Len(t, conversions[coneID], 2)
+
+	// No ingredients means no query at all
+	empty, err := handler.GetConversionsForIngredients(nil)
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrUndefinedConversion is returned when no conversion exists between two units
+var ErrUndefinedConversion = errors.New("undefined unit conversion")
+
+// UnitConversion represents an ingredient-specific conversion factor (value_in_to_unit = value_in_from_unit * factor)
+type UnitConversion struct {
+	ID           string    `json:"id" db:"id"`
+	IngredientID string    `json:"ingredient_id" db:"ingredient_id"`
+	FromUnit     string    `json:"from_unit" db:"from_unit"`
+	ToUnit       string    `json:"to_unit" db:"to_unit"`
+	Factor       float64   `json:"factor" db:"factor"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// standardUnit describes a unit by its dimension and its size in the dimension's base unit
+type standardUnit struct {
+	dimension string
+	toBase    float64
+}
+
+// standardUnits holds conversions that hold for every ingredient, keyed by lower-case unit name.
+// Volume is based on milliliters and mass on grams.
+var standardUnits = map[string]standardUnit{
+	"ml":          {dimension: "volume", toBase: 1},
+	"milliliters": {dimension: "volume", toBase: 1},
+	"l":           {dimension: "volume", toBase: 1000},
+	"liters":      {dimension: "volume", toBase: 1000},
+	"gal":         {dimension: "volume", toBase: 3785.411784},
+	"gallons":     {dimension: "volume", toBase: 3785.411784},
+	"g":           {dimension: "mass", toBase: 1},
+	"grams":       {dimension: "mass", toBase: 1},
+	"kg":          {dimension: "mass", toBase: 1000},
+	"kilograms":   {dimension: "mass", toBase: 1000},
+	"oz":          {dimension: "mass", toBase: 28.349523125},
+	"lb":          {dimension: "mass", toBase: 453.59237},
+}
+
+// ConvertStandardUnits converts between units of the same dimension (e.g. Liters -> ml, kg -> g).
+// Unit names are case-insensitive; identical units always convert.
+func ConvertStandardUnits(value float64, from, to string) (float64, error) {
+	fromKey := strings.ToLower(strings.TrimSpace(from))
+	toKey := strings.ToLower(strings.TrimSpace(to))
+
+	if fromKey == toKey {
+		return value, nil
+	}
+
+	fromUnit, fromKnown := standardUnits[fromKey]
+	toUnit, toKnown := standardUnits[toKey]
+	if !fromKnown || !toKnown || fromUnit.dimension != toUnit.dimension {
+		return 0, fmt.Errorf("%w: %s to %s", ErrUndefinedConversion, from, to)
+	}
+
+	return value * fromUnit.toBase / toUnit.toBase, nil
+}
+
+// ApplyConversions converts using ingredient-specific factors, trying both directions of each conversion
+func ApplyConversions(value float64, from, to string, conversions []UnitConversion) (float64, error) {
+	for _, conversion := range conversions {
+		if strings.EqualFold(conversion.FromUnit, from) && strings.EqualFold(conversion.ToUnit, to) {
+			return value * conversion.Factor, nil
+		}
+		if strings.EqualFold(conversion.FromUnit, to) && strings.EqualFold(conversion.ToUnit, from) && conversion.Factor != 0 {
+			return value / conversion.Factor, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: %s to %s", ErrUndefinedConversion, from, to)
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertStandardUnits(t *testing.T) {
+	testCases := map[string]struct {
+		value    float64
+		from     string
+		to       string
+		expected float64
+	}{
+		"liters_to_ml":      {value: 1.5, from: "Liters", to: "ml", expected: 1500},
+		"ml_to_liters":      {value: 250, from: "ml", to: "L", expected: 0.25},
+		"kg_to_g":           {value: 2, from: "kg", to: "g", expected: 2000},
+		"g_to_kg":           {value: 500, from: "grams", to: "KG", expected: 0.5},
+		"gallons_to_liters": {value: 1, from: "Gallons", to: "Liters", expected: 3.785411784},
+		"same_unit":         {value: 3, from: "Bag", to: "bag", expected: 3},
+		"lb_to_g":           {value: 1, from: "lb", to: "g", expected: 453.59237},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			result, err := ConvertStandardUnits(tc.value, tc.from, tc.to)
+			require.NoError(t, err)
+			assert.InDelta(t, tc.expected, result, 1e-9)
+		})
+	}
+}
+
+func TestConvertStandardUnits_Undefined(t *testing.T) {
+	testCases := map[string]struct {
+		from string
+		to   string
+	}{
+		"across_dimensions": {from: "kg", to: "Liters"},
+		"unknown_unit":      {from: "Bag", to: "g"},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := ConvertStandardUnits(1, tc.from, tc.to)
+			assert.ErrorIs(t, err, ErrUndefinedConversion)
+		})
+	}
+}
+
+func TestApplyConversions(t *testing.T) {
+	conversions := []UnitConversion{{FromUnit: "Bag", ToUnit: "kg", Factor: 25}}
+
+	result, err := ApplyConversions(2, "bag", "KG", conversions)
+	require.NoError(t, err)
+	assert.Equal(t, 50.0, result)
+
+	result, err = ApplyConversions(50, "kg", "Bag", conversions)
+	require.NoError(t, err)
+	assert.Equal(t, 2.0, result)
+
+	_, err = ApplyConversions(1, "Bag", "Liters", conversions)
+	assert.ErrorIs(t, err, ErrUndefinedConversion)
+}
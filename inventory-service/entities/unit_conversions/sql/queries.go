@@ -0,0 +1,11 @@
+package sql
+
+import _ "embed"
+
+// Unit Conversion SQL queries
+//
+//go:embed scripts/get_ingredient_unit_conversions.sql
+var GetIngredientUnitConversionsQuery string
+
+//go:embed scripts/list_unit_conversions_for_ingredients.sql
+var ListUnitConversionsForIngredientsQuery string
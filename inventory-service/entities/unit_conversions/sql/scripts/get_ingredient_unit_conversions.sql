@@ -0,0 +1,4 @@
+SELECT id, ingredient_id, from_unit, to_unit, factor, created_at, updated_at
+FROM unit_conversions
+WHERE ingredient_id = $1
+ORDER BY from_unit, to_unit;
\ No newline at end of file
@@ -0,0 +1,4 @@
+SELECT id, ingredient_id, from_unit, to_unit, factor, created_at, updated_at
+FROM unit_conversions
+WHERE ingredient_id = ANY($1::uuid[])
+ORDER BY ingredient_id, from_unit, to_unit;
@@ -0,0 +1,35 @@
+package events
+
+import (
+	"time"
+
+	"shared/eventbus"
+)
+
+// Existence domain event types
+const (
+	ExistenceCreated    eventbus.Type = "existence.created"
+	ExistenceUpdated    eventbus.Type = "existence.updated"
+	ExistenceDeleted    eventbus.Type = "existence.deleted"
+	ExistenceConsumed   eventbus.Type = "existence.consumed"
+	ExistenceReassigned eventbus.Type = "existence.reassigned"
+)
+
+// ExistenceEvent describes a committed change to an ingredient existence
+type ExistenceEvent struct {
+	Kind         eventbus.Type `json:"type"`
+	ExistenceID  string        `json:"existence_id"`
+	IngredientID string        `json:"ingredient_id,omitempty"`
+	Quantity     float64       `json:"quantity,omitempty"`
+	Timestamp    time.Time     `json:"timestamp"`
+}
+
+// EventType returns the kind of existence change
+func (e ExistenceEvent) EventType() eventbus.Type {
+	return e.Kind
+}
+
+// OccurredAt returns when the change was committed
+func (e ExistenceEvent) OccurredAt() time.Time {
+	return e.Timestamp
+}
@@ -12,20 +12,47 @@ import (
 	"time"
 
 	"inventory-service/config"
+	"shared/httpx"
+	"shared/pricing"
+	"shared/schema"
 
 	"github.com/gorilla/mux"
 	_ "github.com/lib/pq" // PostgreSQL driver
 	"github.com/sirupsen/logrus"
 )
 
+// requiredTables are the tables the service queries; startup fails when any is missing
+var requiredTables = []string{
+	"suppliers",
+	"ingredient_categories",
+	"ingredients",
+	"unit_conversions",
+	"existences",
+	"existence_movements",
+	"runout_ingredient_report",
+	"recipe_categories",
+	"recipes",
+	"recipe_ingredients",
+	"invoice",
+}
+
 func main() {
 	// Load configuration
-	cfg := config.LoadConfig()
+	cfg, cfgErr := config.Load()
 
 	// Setup logger
 	logger := setupLogger(cfg.LogLevel)
+	if cfgErr != nil {
+		logger.WithError(cfgErr).Fatal("Invalid configuration")
+	}
 	logger.Info("Starting Ice Cream Store Inventory Service")
 
+	// Resolve existence price rounding before touching the database
+	priceRounding, err := pricing.RoundingStrategyByName(cfg.PriceRounding)
+	if err != nil {
+		logger.WithError(err).Fatal("Invalid price rounding strategy")
+	}
+
 	// Connect to database
 	db, err := connectToDatabase(cfg, logger)
 	if err != nil {
@@ -33,11 +60,25 @@ func main() {
 	}
 	defer db.Close()
 
+	// Fail fast with the missing table names instead of query errors on the first request
+	checkCtx, cancelCheck := context.WithTimeout(context.Background(), 10*time.Second)
+	err = schema.CheckRequiredTables(checkCtx, db, requiredTables)
+	cancelCheck()
+	if err != nil {
+		logger.WithError(err).Fatal("Database schema self-check failed")
+	}
+	logger.WithField("tables", len(requiredTables)).Info("Database schema self-check passed")
+
 	// Create main HTTP handler with all entity handlers
-	mainHandler := NewMainHttpHandler(db, logger)
+	mainHandler := NewMainHttpHandler(db, logger, priceRounding)
+	if cfg.HealthCheckDataService {
+		mainHandler.SetDataServiceHealthURL(cfg.DataServiceHealthURL)
+	}
+	mainHandler.SetConfig(cfg.Sanitized())
+	mainHandler.ExistencesHandler.SetMaxPageSize(cfg.MaxPageSize)
 
 	// Setup HTTP router
-	router := setupRouter(mainHandler, logger)
+	router := setupRouter(mainHandler, cfg.RequestTimeout, cfg.RequestLogSampleRate, logger)
 
 	// Start HTTP server
 	server := &http.Server{
@@ -142,7 +183,7 @@ func connectToDatabase(cfg *config.Config, logger *logrus.Logger) (*sql.DB, erro
 }
 
 // setupRouter configures the HTTP routes
-func setupRouter(mainHandler *MainHttpHandler, logger *logrus.Logger) *mux.Router {
+func setupRouter(mainHandler *MainHttpHandler, requestTimeout time.Duration, requestLogSampleRate int, logger *logrus.Logger) *mux.Router {
 	router := mux.NewRouter()
 
 	// API versioning
@@ -165,6 +206,9 @@ func setupRouter(mainHandler *MainHttpHandler, logger *logrus.Logger) *mux.Route
 		w.Write(jsonData)
 	}).Methods("GET")
 
+	// GET /api/v1/config - Effective configuration with secrets redacted (admin role set by the gateway)
+	v1.Handle("/config", httpx.RequireAdminMiddleware(http.HandlerFunc(mainHandler.GetConfig))).Methods("GET")
+
 	// Inventory module endpoints
 	inventoryRouter := v1.PathPrefix("/inventory").Subrouter()
 
@@ -177,6 +221,9 @@ func setupRouter(mainHandler *MainHttpHandler, logger *logrus.Logger) *mux.Route
 	// POST /api/v1/inventory/suppliers - Create new supplier
 	suppliersRouter.HandleFunc("", mainHandler.GetSuppliersHandler().CreateSupplier).Methods("POST")
 
+	// POST /api/v1/inventory/suppliers/merge - Merge duplicate suppliers into a primary one
+	suppliersRouter.HandleFunc("/merge", mainHandler.GetSuppliersHandler().MergeSuppliers).Methods("POST")
+
 	// GET /api/v1/inventory/suppliers/{id} - Get supplier by ID
 	suppliersRouter.HandleFunc("/{id}", mainHandler.GetSuppliersHandler().GetSupplier).Methods("GET")
 
@@ -222,6 +269,15 @@ func setupRouter(mainHandler *MainHttpHandler, logger *logrus.Logger) *mux.Route
 	// DELETE /api/v1/inventory/ingredients/{id} - Delete ingredient
 	ingredientsRouter.HandleFunc("/{id}", mainHandler.GetIngredientsHandler().DeleteIngredient).Methods("DELETE")
 
+	// POST /api/v1/inventory/ingredients/{id}/reprice - Apply a new cost per unit to every existence of the ingredient
+	ingredientsRouter.HandleFunc("/{id}/reprice", mainHandler.GetExistencesHandler().RepriceIngredientExistences).Methods("POST")
+
+	// GET /api/v1/inventory/ingredients/{id}/avg-cost - Get the ingredient's cost per item weighted by units available
+	ingredientsRouter.HandleFunc("/{id}/avg-cost", mainHandler.GetExistencesHandler().GetIngredientAverageCost).Methods("GET")
+
+	// GET /api/v1/inventory/purchase-suggestions - Ingredients below their reorder point and how much to buy
+	inventoryRouter.HandleFunc("/purchase-suggestions", mainHandler.GetIngredientsHandler().ListPurchaseSuggestions).Methods("GET")
+
 	// Existences endpoints under inventory
 	existencesRouter := inventoryRouter.PathPrefix("/existences").Subrouter()
 
@@ -231,15 +287,39 @@ func setupRouter(mainHandler *MainHttpHandler, logger *logrus.Logger) *mux.Route
 	// POST /api/v1/inventory/existences - Create new existence
 	existencesRouter.HandleFunc("", mainHandler.GetExistencesHandler().CreateExistence).Methods("POST")
 
+	// POST /api/v1/inventory/existences/bulk - Create multiple existences atomically
+	existencesRouter.HandleFunc("/bulk", mainHandler.GetExistencesHandler().CreateExistencesBulk).Methods("POST")
+
+	// GET /api/v1/inventory/existences/expiring - Non-expired existences expiring within ?within_days= (default 7)
+	existencesRouter.HandleFunc("/expiring", mainHandler.GetExistencesHandler().ListExpiringExistences).Methods("GET")
+
+	// GET /api/v1/inventory/existences/summary - Dashboard stock KPIs (totals, expiring within 7 days, expired, low stock)
+	existencesRouter.HandleFunc("/summary", mainHandler.GetExistencesHandler().GetExistencesSummary).Methods("GET")
+
+	// GET /api/v1/inventory/existences/by-code/{code} - Get existence by its reference code
+	existencesRouter.HandleFunc("/by-code/{code}", mainHandler.GetExistencesHandler().GetExistenceByCode).Methods("GET")
+
 	// GET /api/v1/inventory/existences/{id} - Get existence by ID
 	existencesRouter.HandleFunc("/{id}", mainHandler.GetExistencesHandler().GetExistence).Methods("GET")
 
+	// GET /api/v1/inventory/existences/{id}/history - Chronological units movements with running balance
+	existencesRouter.HandleFunc("/{id}/history", mainHandler.GetExistencesHandler().GetExistenceHistory).Methods("GET")
+
+	// POST /api/v1/inventory/existences/{id}/consume - Take units out (consumption or write_off)
+	existencesRouter.HandleFunc("/{id}/consume", mainHandler.GetExistencesHandler().ConsumeExistence).Methods("POST")
+
+	// POST /api/v1/inventory/existences/{id}/reassign - Move stock logged against the wrong ingredient
+	existencesRouter.HandleFunc("/{id}/reassign", mainHandler.GetExistencesHandler().ReassignExistence).Methods("POST")
+
 	// PUT /api/v1/inventory/existences/{id} - Update existence
 	existencesRouter.HandleFunc("/{id}", mainHandler.GetExistencesHandler().UpdateExistence).Methods("PUT")
 
 	// DELETE /api/v1/inventory/existences/{id} - Delete existence
 	existencesRouter.HandleFunc("/{id}", mainHandler.GetExistencesHandler().DeleteExistence).Methods("DELETE")
 
+	// GET /api/v1/inventory/valuation - Value of stock on hand, optionally as of a date
+	inventoryRouter.HandleFunc("/valuation", mainHandler.GetExistencesHandler().GetInventoryValuation).Methods("GET")
+
 	// Runout Ingredients endpoints under inventory
 	runoutIngredientsRouter := inventoryRouter.PathPrefix("/runout-ingredients").Subrouter()
 
@@ -285,9 +365,15 @@ func setupRouter(mainHandler *MainHttpHandler, logger *logrus.Logger) *mux.Route
 	// POST /api/v1/inventory/recipes - Create new recipe
 	recipesRouter.HandleFunc("", mainHandler.GetRecipesHandler().CreateRecipe).Methods("POST")
 
+	// GET /api/v1/inventory/recipes/availability - Makeable quantity of every recipe (registered before /{id})
+	recipesRouter.HandleFunc("/availability", mainHandler.GetRecipesHandler().GetRecipesAvailability).Methods("GET")
+
 	// GET /api/v1/inventory/recipes/{id} - Get recipe by ID
 	recipesRouter.HandleFunc("/{id}", mainHandler.GetRecipesHandler().GetRecipe).Methods("GET")
 
+	// GET /api/v1/inventory/recipes/{id}/full - Get recipe with its ingredients and computed cost
+	recipesRouter.HandleFunc("/{id}/full", mainHandler.GetRecipesHandler().GetFullRecipe).Methods("GET")
+
 	// PUT /api/v1/inventory/recipes/{id} - Update recipe
 	recipesRouter.HandleFunc("/{id}", mainHandler.GetRecipesHandler().UpdateRecipe).Methods("PUT")
 
@@ -313,14 +399,33 @@ func setupRouter(mainHandler *MainHttpHandler, logger *logrus.Logger) *mux.Route
 	recipeIngredientsRouter.HandleFunc("/{id}", mainHandler.GetRecipeIngredientsHandler().DeleteRecipeIngredient).Methods("DELETE")
 
 	// Logging middleware
-	router.Use(loggingMiddleware(logger))
+	router.Use(loggingMiddleware(logger, httpx.NewRequestLogSampler(requestLogSampleRate)))
+
+	// Answer handler panics with a JSON 500 instead of dropping the connection
+	router.Use(httpx.RecoveryMiddleware(logger))
+
+	// Compress large responses for clients that accept gzip
+	router.Use(httpx.GzipMiddleware(httpx.GzipMinSize))
+
+	// Indent JSON responses for ?pretty=true (before gzip sees the body)
+	router.Use(httpx.PrettyJSONMiddleware)
+
+	// Bound handler run time so a slow query cannot hold a connection
+	router.Use(httpx.TimeoutMiddleware(requestTimeout, nil))
+
+	// Write endpoints only accept JSON bodies
+	router.Use(httpx.RequireJSONMiddleware)
+
+	// Unmatched routes get JSON 404/405 responses instead of mux's plain text
+	httpx.SetRouteErrorHandlers(router)
 
 	logger.Info("HTTP routes configured successfully")
 	return router
 }
 
-// loggingMiddleware logs HTTP requests
-func loggingMiddleware(logger *logrus.Logger) mux.MiddlewareFunc {
+// loggingMiddleware logs HTTP requests, including the gateway-supplied user when there is one.
+// sampler keeps every failed request and thins out the successful ones
+func loggingMiddleware(logger *logrus.Logger, sampler *httpx.RequestLogSampler) mux.MiddlewareFunc {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
@@ -335,8 +440,11 @@ func loggingMiddleware(logger *logrus.Logger) mux.MiddlewareFunc {
 			next.ServeHTTP(wrappedWriter, r)
 
 			// Log the request
+			if !sampler.ShouldLog(wrappedWriter.statusCode) {
+				return
+			}
 			duration := time.Since(start)
-			logger.WithFields(logrus.Fields{
+			logger.WithFields(httpx.RequestLogFields(r)).WithFields(logrus.Fields{
 				"method":     r.Method,
 				"path":       r.URL.Path,
 				"status":     wrappedWriter.statusCode,
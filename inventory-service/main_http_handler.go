@@ -2,7 +2,7 @@ package main
 
 import (
 	"database/sql"
-	"fmt"
+	"encoding/json"
 	"net/http"
 	"time"
 
@@ -14,6 +14,10 @@ import (
 	recipesHandlers "inventory-service/entities/recipes/handlers"
 	runoutIngredientsHandlers "inventory-service/entities/runout_ingredients/handlers"
 	suppliersHandlers "inventory-service/entities/suppliers/handlers"
+	"shared/eventbus"
+	"shared/httpx"
+	"shared/pricing"
+	"shared/version"
 
 	"github.com/sirupsen/logrus"
 )
@@ -24,6 +28,16 @@ type MainHttpHandler struct {
 	db     *sql.DB
 	logger *logrus.Logger
 
+	// dataServiceHealthURL is checked by HealthCheck when set
+	dataServiceHealthURL string
+	httpClient           *http.Client
+
+	// settings is the sanitized configuration served by GetConfig
+	settings map[string]interface{}
+
+	// eventBus carries domain events published by the entity handlers
+	eventBus *eventbus.Bus
+
 	// Entity handlers
 	SuppliersHandler            *suppliersHandlers.HttpHandler
 	IngredientCategoriesHandler *ingredientCategoriesHandlers.HttpHandler
@@ -35,8 +49,9 @@ type MainHttpHandler struct {
 	RecipeIngredientsHandler    *recipeIngredientsHandlers.RecipeIngredientHTTPHandler
 }
 
-// NewMainHttpHandler creates a new main HTTP handler with all entity handlers
-func NewMainHttpHandler(db *sql.DB, logger *logrus.Logger) *MainHttpHandler {
+// NewMainHttpHandler creates a new main HTTP handler with all entity handlers.
+// priceRounding rounds the final price of repriced existences.
+func NewMainHttpHandler(db *sql.DB, logger *logrus.Logger, priceRounding pricing.RoundingStrategy) *MainHttpHandler {
 	// Initialize suppliers handlers
 	suppliersDBHandler := suppliersHandlers.NewDBHandler(db, logger)
 	suppliersHttpHandler := suppliersHandlers.NewHttpHandler(suppliersDBHandler, logger)
@@ -51,7 +66,10 @@ func NewMainHttpHandler(db *sql.DB, logger *logrus.Logger) *MainHttpHandler {
 
 	// Initialize existences handlers
 	existencesDBHandler := existencesHandlers.NewDBHandler(db, logger)
+	existencesDBHandler.SetRoundingStrategy(priceRounding)
 	existencesHttpHandler := existencesHandlers.NewHttpHandler(existencesDBHandler, logger)
+	eventBus := eventbus.New(logger)
+	existencesHttpHandler.SetEventBus(eventBus)
 
 	// Initialize runout ingredients handlers
 	runoutIngredientsHttpHandler := runoutIngredientsHandlers.NewRunoutIngredientHTTPHandler(db, logger)
@@ -76,9 +94,36 @@ func NewMainHttpHandler(db *sql.DB, logger *logrus.Logger) *MainHttpHandler {
 		RecipeCategoriesHandler:     recipeCategoriesHttpHandler,
 		RecipesHandler:              recipesHttpHandler,
 		RecipeIngredientsHandler:    recipeIngredientsHttpHandler,
+		httpClient:                  &http.Client{Timeout: 5 * time.Second},
+		eventBus:                    eventBus,
 	}
 }
 
+// EventBus returns the bus the entity handlers publish domain events on
+func (h *MainHttpHandler) EventBus() *eventbus.Bus {
+	return h.eventBus
+}
+
+// SetDataServiceHealthURL makes HealthCheck depend on the data-service health endpoint; an empty URL disables the check
+func (h *MainHttpHandler) SetDataServiceHealthURL(url string) {
+	h.dataServiceHealthURL = url
+}
+
+// SetConfig sets the configuration GetConfig serves; callers pass config.Config.Sanitized() so no secret is kept
+func (h *MainHttpHandler) SetConfig(settings map[string]interface{}) {
+	h.settings = settings
+}
+
+// GetConfig returns the configuration the service loaded, with secrets redacted
+func (h *MainHttpHandler) GetConfig(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"service": "inventory-service",
+		"config":  h.settings,
+	})
+}
+
 // GetSuppliersHandler returns the suppliers HTTP handler
 func (h *MainHttpHandler) GetSuppliersHandler() *suppliersHandlers.HttpHandler {
 	return h.SuppliersHandler
@@ -121,38 +166,42 @@ func (h *MainHttpHandler) GetRecipeIngredientsHandler() *recipeIngredientsHandle
 
 // HealthCheck provides a health check endpoint for the entire service
 func (h *MainHttpHandler) HealthCheck() map[string]interface{} {
-	// Check data-service health (which checks database connectivity)
-	client := &http.Client{
-		Timeout: 5 * time.Second,
-	}
-
-	resp, err := client.Get("http://localhost:8086/health")
-	if err != nil {
-		h.logger.WithError(err).Error("Failed to connect to data-service during health check")
+	if err := h.db.Ping(); err != nil {
+		h.logger.WithError(err).Error("Database ping failed during health check")
 		return map[string]interface{}{
 			"service": "inventory-service",
 			"status":  "unhealthy",
-			"message": "Data service connection failed",
+			"message": "Database connection failed",
 			"error":   err.Error(),
 			"time":    time.Now().Format(time.RFC3339),
 		}
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		h.logger.WithField("status_code", resp.StatusCode).Error("Data service returned non-OK status during health check")
-		return map[string]interface{}{
-			"service": "inventory-service",
-			"status":  "unhealthy",
-			"message": fmt.Sprintf("Data service returned status %d", resp.StatusCode),
-			"time":    time.Now().Format(time.RFC3339),
+	dependencies := map[string]string{}
+	if h.dataServiceHealthURL != "" {
+		if err := httpx.CheckHealth(h.httpClient, h.dataServiceHealthURL); err != nil {
+			h.logger.WithError(err).Error("Data service health check failed")
+			dependencies["data-service"] = "unhealthy"
+			return map[string]interface{}{
+				"service":      "inventory-service",
+				"status":       "unhealthy",
+				"message":      "Data service is unhealthy",
+				"error":        err.Error(),
+				"time":         time.Now().Format(time.RFC3339),
+				"dependencies": dependencies,
+			}
 		}
+		dependencies["data-service"] = "healthy"
 	}
 
 	return map[string]interface{}{
-		"service": "inventory-service",
-		"status":  "healthy",
-		"message": "Service is running normally",
-		"time":    time.Now().Format(time.RFC3339),
+		"service":      "inventory-service",
+		"status":       "healthy",
+		"message":      "Service is running normally",
+		"time":         time.Now().Format(time.RFC3339),
+		"version":      version.Version,
+		"build":        version.Info(),
+		"dependencies": dependencies,
+		"panics":       httpx.PanicCount(),
 	}
 }
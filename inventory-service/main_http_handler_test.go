@@ -2,8 +2,16 @@ package main
 
 import (
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"inventory-service/config"
+	"shared/httpx"
+	"shared/pricing"
+
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
@@ -21,7 +29,7 @@ func TestNewMainHttpHandler(t *testing.T) {
 	logger.SetLevel(logrus.ErrorLevel) // Reduce noise in tests
 
 	t.Run("successful creation", func(t *testing.T) {
-		handler := NewMainHttpHandler(db, logger)
+		handler := NewMainHttpHandler(db, logger, pricing.CeilTo100)
 
 		assert.NotNil(t, handler)
 		assert.Equal(t, db, handler.db)
@@ -30,7 +38,7 @@ func TestNewMainHttpHandler(t *testing.T) {
 	})
 
 	t.Run("handlers are properly initialized", func(t *testing.T) {
-		handler := NewMainHttpHandler(db, logger)
+		handler := NewMainHttpHandler(db, logger, pricing.CeilTo100)
 
 		// Test that suppliers handler is initialized
 		suppliersHandler := handler.GetSuppliersHandler()
@@ -47,7 +55,7 @@ func TestMainHttpHandlerStructure(t *testing.T) {
 	defer db.Close()
 
 	logger := logrus.New()
-	handler := NewMainHttpHandler(db, logger)
+	handler := NewMainHttpHandler(db, logger, pricing.CeilTo100)
 
 	t.Run("has database connection", func(t *testing.T) {
 		assert.NotNil(t, handler.db)
@@ -72,7 +80,7 @@ func TestGetSuppliersHandler(t *testing.T) {
 	defer db.Close()
 
 	logger := logrus.New()
-	handler := NewMainHttpHandler(db, logger)
+	handler := NewMainHttpHandler(db, logger, pricing.CeilTo100)
 
 	t.Run("returns suppliers handler", func(t *testing.T) {
 		suppliersHandler := handler.GetSuppliersHandler()
@@ -109,7 +117,7 @@ func TestMainHttpHandlerWithDifferentLoggers(t *testing.T) {
 			logger := logrus.New()
 			logger.SetLevel(tt.logLevel)
 
-			handler := NewMainHttpHandler(db, logger)
+			handler := NewMainHttpHandler(db, logger, pricing.CeilTo100)
 			assert.NotNil(t, handler)
 			assert.Equal(t, tt.logLevel, handler.logger.Level)
 		})
@@ -124,7 +132,7 @@ func TestMainHttpHandlerResourcesCleanup(t *testing.T) {
 	defer db.Close()
 
 	logger := logrus.New()
-	handler := NewMainHttpHandler(db, logger)
+	handler := NewMainHttpHandler(db, logger, pricing.CeilTo100)
 
 	t.Run("database can be closed", func(t *testing.T) {
 		// Ensure we can close the database connection
@@ -145,7 +153,7 @@ func TestMainHttpHandlerNilInputs(t *testing.T) {
 
 		// This should not panic but would likely fail in real usage
 		// The test documents the behavior
-		handler := NewMainHttpHandler(nil, logger)
+		handler := NewMainHttpHandler(nil, logger, pricing.CeilTo100)
 		assert.NotNil(t, handler)
 		assert.Nil(t, handler.db)
 		assert.Equal(t, logger, handler.logger)
@@ -159,7 +167,7 @@ func TestMainHttpHandlerNilInputs(t *testing.T) {
 
 		// This should not panic but would likely fail in real usage
 		// The test documents the behavior
-		handler := NewMainHttpHandler(db, nil)
+		handler := NewMainHttpHandler(db, nil, pricing.CeilTo100)
 		assert.NotNil(t, handler)
 		assert.Equal(t, db, handler.db)
 		assert.Nil(t, handler.logger)
@@ -176,7 +184,7 @@ func TestMainHttpHandlerEntityHandlerIntegration(t *testing.T) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.ErrorLevel) // Reduce noise
 
-	handler := NewMainHttpHandler(db, logger)
+	handler := NewMainHttpHandler(db, logger, pricing.CeilTo100)
 
 	t.Run("suppliers handler integration", func(t *testing.T) {
 		suppliersHandler := handler.GetSuppliersHandler()
@@ -198,7 +206,7 @@ func TestMainHttpHandlerMemoryUsage(t *testing.T) {
 		require.NoError(t, err)
 
 		logger := logrus.New()
-		handler := NewMainHttpHandler(db, logger)
+		handler := NewMainHttpHandler(db, logger, pricing.CeilTo100)
 
 		assert.NotNil(t, handler)
 		assert.NotNil(t, handler.GetSuppliersHandler())
@@ -215,7 +223,7 @@ func TestMainHttpHandlerConcurrentAccess(t *testing.T) {
 	defer db.Close()
 
 	logger := logrus.New()
-	handler := NewMainHttpHandler(db, logger)
+	handler := NewMainHttpHandler(db, logger, pricing.CeilTo100)
 
 	// Test concurrent access to GetSuppliersHandler
 	done := make(chan bool)
@@ -233,6 +241,70 @@ func TestMainHttpHandlerConcurrentAccess(t *testing.T) {
 	}
 }
 
+// TestMainHttpHandlerHealthCheck tests the database ping and the optional data-service dependency
+func TestMainHttpHandlerHealthCheck(t *testing.T) {
+	healthyDataService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer healthyDataService.Close()
+
+	unreachableDataService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	unreachableURL := unreachableDataService.URL
+	unreachableDataService.Close()
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	testCases := map[string]struct {
+		dataServiceURL       string
+		pingErr              error
+		expectedStatus       string
+		expectedDependencies map[string]string
+	}{
+		"healthy without data-service check": {
+			expectedStatus:       "healthy",
+			expectedDependencies: map[string]string{},
+		},
+		"healthy data-service": {
+			dataServiceURL:       healthyDataService.URL,
+			expectedStatus:       "healthy",
+			expectedDependencies: map[string]string{"data-service": "healthy"},
+		},
+		"unreachable data-service": {
+			dataServiceURL:       unreachableURL,
+			expectedStatus:       "unhealthy",
+			expectedDependencies: map[string]string{"data-service": "unhealthy"},
+		},
+		"database ping failure": {
+			pingErr:        errors.New("connection refused"),
+			expectedStatus: "unhealthy",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+			require.NoError(t, err)
+			defer db.Close()
+
+			handler := NewMainHttpHandler(db, logger, pricing.CeilTo100)
+			handler.SetDataServiceHealthURL(tc.dataServiceURL)
+			mock.ExpectPing().WillReturnError(tc.pingErr)
+
+			health := handler.HealthCheck()
+
+			assert.Equal(t, tc.expectedStatus, health["status"])
+			if tc.expectedStatus == "healthy" {
+				assert.Equal(t, httpx.PanicCount(), health["panics"])
+			}
+			if tc.expectedDependencies != nil {
+				assert.Equal(t, tc.expectedDependencies, health["dependencies"])
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
 // BenchmarkNewMainHttpHandler benchmarks the creation of MainHttpHandler
 func BenchmarkNewMainHttpHandler(b *testing.B) {
 	// Create a mock database
@@ -245,7 +317,7 @@ func BenchmarkNewMainHttpHandler(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		NewMainHttpHandler(db, logger)
+		NewMainHttpHandler(db, logger, pricing.CeilTo100)
 	}
 }
 
@@ -257,10 +329,61 @@ func BenchmarkGetSuppliersHandler(b *testing.B) {
 	defer db.Close()
 
 	logger := logrus.New()
-	handler := NewMainHttpHandler(db, logger)
+	handler := NewMainHttpHandler(db, logger, pricing.CeilTo100)
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		handler.GetSuppliersHandler()
 	}
 }
+
+// TestGetConfigRequiresAdmin tests that the configuration is served to admin roles only, with secrets redacted
+func TestGetConfigRequiresAdmin(t *testing.T) {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	cfg := config.LoadConfig()
+	cfg.DBPassword = "db-password-value"
+
+	handler := NewMainHttpHandler(db, logger, pricing.CeilTo100)
+	handler.SetConfig(cfg.Sanitized())
+	router := setupRouter(handler, 0, 1, logger)
+
+	testCases := map[string]struct {
+		role           string
+		expectedStatus int
+	}{
+		"admin reads the config": {role: "admin", expectedStatus: http.StatusOK},
+		"cashier is forbidden":   {role: "cashier", expectedStatus: http.StatusForbidden},
+		"no role is forbidden":   {expectedStatus: http.StatusForbidden},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/config", nil)
+			if tc.role != "" {
+				req.Header.Set(httpx.UserRoleHeader, tc.role)
+			}
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectedStatus, rr.Code)
+			assert.NotContains(t, rr.Body.String(), "db-password-value")
+			if tc.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var response struct {
+				Config map[string]interface{} `json:"config"`
+			}
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+			assert.Equal(t, config.RedactedValue, response.Config["DB_PASSWORD"])
+			assert.Equal(t, "localhost", response.Config["DB_HOST"])
+		})
+	}
+}
@@ -2,13 +2,18 @@ package main
 
 import (
 	"database/sql"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 
 	"inventory-service/config"
+	"shared/httpx"
+	"shared/pricing"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -146,7 +151,7 @@ func TestApplicationComponents(t *testing.T) {
 		logger := setupLogger("error") // Use error level to reduce test noise
 
 		// Test that NewMainHttpHandler can be created
-		mainHandler := NewMainHttpHandler(db, logger)
+		mainHandler := NewMainHttpHandler(db, logger, pricing.CeilTo100)
 		assert.NotNil(t, mainHandler)
 		assert.NotNil(t, mainHandler.GetSuppliersHandler())
 	})
@@ -246,6 +251,65 @@ func TestLoggerLevels(t *testing.T) {
 	}
 }
 
+// TestLoggingMiddlewareUserID tests that the request log carries user_id only when the gateway sent X-User-ID
+func TestLoggingMiddlewareUserID(t *testing.T) {
+	testCases := map[string]struct {
+		userID string
+	}{
+		"header set":    {userID: "user-123"},
+		"header absent": {},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			logger, hook := test.NewNullLogger()
+			handler := loggingMiddleware(logger, httpx.NewRequestLogSampler(1))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusCreated)
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/inventory/suppliers", nil)
+			if tc.userID != "" {
+				req.Header.Set("X-User-ID", tc.userID)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			entry := hook.LastEntry()
+			require.NotNil(t, entry)
+			assert.Equal(t, http.StatusCreated, entry.Data["status"])
+			userID, ok := entry.Data["user_id"]
+			if tc.userID == "" {
+				assert.False(t, ok)
+				return
+			}
+			assert.Equal(t, tc.userID, userID)
+		})
+	}
+}
+
+// TestLoggingMiddlewareSampling tests that error responses are always logged while successes are sampled
+func TestLoggingMiddlewareSampling(t *testing.T) {
+	logger, hook := test.NewNullLogger()
+	handler := loggingMiddleware(logger, httpx.NewRequestLogSampler(3))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("fail") != "" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for i := 0; i < 6; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/inventory/suppliers", nil))
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/inventory/suppliers?fail=1", nil))
+	}
+
+	statuses := map[interface{}]int{}
+	for _, entry := range hook.AllEntries() {
+		statuses[entry.Data["status"]]++
+	}
+	assert.Equal(t, 2, statuses[http.StatusOK])
+	assert.Equal(t, 6, statuses[http.StatusInternalServerError])
+}
+
 // TestApplicationStartupSequence tests the startup sequence components
 func TestApplicationStartupSequence(t *testing.T) {
 	t.Run("config then logger", func(t *testing.T) {
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"shared/pricing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRouter builds the service's real router over a mock database
+func newTestRouter(t *testing.T) *mux.Router {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	logger := setupLogger("error") // Use error level to reduce test noise
+	return setupRouter(NewMainHttpHandler(db, logger, pricing.CeilTo100), 0, 1, logger)
+}
+
+// handlerName returns the method name behind a route handler, e.g. "ListSuppliers"
+func handlerName(handler http.Handler) string {
+	name := runtime.FuncForPC(reflect.ValueOf(handler).Pointer()).Name()
+	name = strings.TrimSuffix(name, "-fm")
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+// TestRouterDispatch tests that every inventory path and method reaches the intended handler
+func TestRouterDispatch(t *testing.T) {
+	router := newTestRouter(t)
+
+	const id = "7d3f2c1e-5b4a-4e6f-8a9b-0c1d2e3f4a5b"
+
+	testCases := map[string]struct {
+		method           string
+		path             string
+		expectedTemplate string
+		expectedHandler  string
+	}{
+		"list suppliers":      {http.MethodGet, "/api/v1/inventory/suppliers", "/api/v1/inventory/suppliers", "ListSuppliers"},
+		"create supplier":     {http.MethodPost, "/api/v1/inventory/suppliers", "/api/v1/inventory/suppliers", "CreateSupplier"},
+		"merge suppliers":     {http.MethodPost, "/api/v1/inventory/suppliers/merge", "/api/v1/inventory/suppliers/merge", "MergeSuppliers"},
+		"get supplier":        {http.MethodGet, "/api/v1/inventory/suppliers/" + id, "/api/v1/inventory/suppliers/{id}", "GetSupplier"},
+		"update supplier":     {http.MethodPut, "/api/v1/inventory/suppliers/" + id, "/api/v1/inventory/suppliers/{id}", "UpdateSupplier"},
+		"delete supplier":     {http.MethodDelete, "/api/v1/inventory/suppliers/" + id, "/api/v1/inventory/suppliers/{id}", "DeleteSupplier"},
+		"list categories":     {http.MethodGet, "/api/v1/inventory/ingredient-categories", "/api/v1/inventory/ingredient-categories", "ListIngredientCategories"},
+		"create category":     {http.MethodPost, "/api/v1/inventory/ingredient-categories", "/api/v1/inventory/ingredient-categories", "CreateIngredientCategory"},
+		"get category":        {http.MethodGet, "/api/v1/inventory/ingredient-categories/" + id, "/api/v1/inventory/ingredient-categories/{id}", "GetIngredientCategory"},
+		"update category":     {http.MethodPut, "/api/v1/inventory/ingredient-categories/" + id, "/api/v1/inventory/ingredient-categories/{id}", "UpdateIngredientCategory"},
+		"delete category":     {http.MethodDelete, "/api/v1/inventory/ingredient-categories/" + id, "/api/v1/inventory/ingredient-categories/{id}", "DeleteIngredientCategory"},
+		"list ingredients":    {http.MethodGet, "/api/v1/inventory/ingredients", "/api/v1/inventory/ingredients", "ListIngredients"},
+		"create ingredient":   {http.MethodPost, "/api/v1/inventory/ingredients", "/api/v1/inventory/ingredients", "CreateIngredient"},
+		"get ingredient":      {http.MethodGet, "/api/v1/inventory/ingredients/" + id, "/api/v1/inventory/ingredients/{id}", "GetIngredient"},
+		"update ingredient":   {http.MethodPut, "/api/v1/inventory/ingredients/" + id, "/api/v1/inventory/ingredients/{id}", "UpdateIngredient"},
+		"delete ingredient":   {http.MethodDelete, "/api/v1/inventory/ingredients/" + id, "/api/v1/inventory/ingredients/{id}", "DeleteIngredient"},
+		"reprice ingredient":  {http.MethodPost, "/api/v1/inventory/ingredients/" + id + "/reprice", "/api/v1/inventory/ingredients/{id}/reprice", "RepriceIngredientExistences"},
+		"ingredient avg cost": {http.MethodGet, "/api/v1/inventory/ingredients/" + id + "/avg-cost", "/api/v1/inventory/ingredients/{id}/avg-cost", "GetIngredientAverageCost"},
+		"purchase suggestions": {
+			http.MethodGet, "/api/v1/inventory/purchase-suggestions", "/api/v1/inventory/purchase-suggestions", "ListPurchaseSuggestions",
+		},
+		"list existences":         {http.MethodGet, "/api/v1/inventory/existences", "/api/v1/inventory/existences", "ListExistences"},
+		"create existence":        {http.MethodPost, "/api/v1/inventory/existences", "/api/v1/inventory/existences", "CreateExistence"},
+		"bulk create existences":  {http.MethodPost, "/api/v1/inventory/existences/bulk", "/api/v1/inventory/existences/bulk", "CreateExistencesBulk"},
+		"expiring existences":     {http.MethodGet, "/api/v1/inventory/existences/expiring", "/api/v1/inventory/existences/expiring", "ListExpiringExistences"},
+		"existences summary":      {http.MethodGet, "/api/v1/inventory/existences/summary", "/api/v1/inventory/existences/summary", "GetExistencesSummary"},
+		"existence by code":       {http.MethodGet, "/api/v1/inventory/existences/by-code/EX-0001", "/api/v1/inventory/existences/by-code/{code}", "GetExistenceByCode"},
+		"get existence":           {http.MethodGet, "/api/v1/inventory/existences/" + id, "/api/v1/inventory/existences/{id}", "GetExistence"},
+		"existence history":       {http.MethodGet, "/api/v1/inventory/existences/" + id + "/history", "/api/v1/inventory/existences/{id}/history", "GetExistenceHistory"},
+		"consume existence":       {http.MethodPost, "/api/v1/inventory/existences/" + id + "/consume", "/api/v1/inventory/existences/{id}/consume", "ConsumeExistence"},
+		"reassign existence":      {http.MethodPost, "/api/v1/inventory/existences/" + id + "/reassign", "/api/v1/inventory/existences/{id}/reassign", "ReassignExistence"},
+		"update existence":        {http.MethodPut, "/api/v1/inventory/existences/" + id, "/api/v1/inventory/existences/{id}", "UpdateExistence"},
+		"delete existence":        {http.MethodDelete, "/api/v1/inventory/existences/" + id, "/api/v1/inventory/existences/{id}", "DeleteExistence"},
+		"inventory valuation":     {http.MethodGet, "/api/v1/inventory/valuation", "/api/v1/inventory/valuation", "GetInventoryValuation"},
+		"list runout ingredients": {http.MethodGet, "/api/v1/inventory/runout-ingredients", "/api/v1/inventory/runout-ingredients", "ListRunoutIngredients"},
+		"create runout ingredient": {
+			http.MethodPost, "/api/v1/inventory/runout-ingredients", "/api/v1/inventory/runout-ingredients", "CreateRunoutIngredient",
+		},
+		"get runout ingredient":    {http.MethodGet, "/api/v1/inventory/runout-ingredients/" + id, "/api/v1/inventory/runout-ingredients/{id}", "GetRunoutIngredient"},
+		"update runout ingredient": {http.MethodPut, "/api/v1/inventory/runout-ingredients/" + id, "/api/v1/inventory/runout-ingredients/{id}", "UpdateRunoutIngredient"},
+		"delete runout ingredient": {http.MethodDelete, "/api/v1/inventory/runout-ingredients/" + id, "/api/v1/inventory/runout-ingredients/{id}", "DeleteRunoutIngredient"},
+		"list recipe categories":   {http.MethodGet, "/api/v1/inventory/recipe-categories", "/api/v1/inventory/recipe-categories", "ListRecipeCategories"},
+		"create recipe category":   {http.MethodPost, "/api/v1/inventory/recipe-categories", "/api/v1/inventory/recipe-categories", "CreateRecipeCategory"},
+		"get recipe category":      {http.MethodGet, "/api/v1/inventory/recipe-categories/" + id, "/api/v1/inventory/recipe-categories/{id}", "GetRecipeCategory"},
+		"update recipe category":   {http.MethodPut, "/api/v1/inventory/recipe-categories/" + id, "/api/v1/inventory/recipe-categories/{id}", "UpdateRecipeCategory"},
+		"delete recipe category":   {http.MethodDelete, "/api/v1/inventory/recipe-categories/" + id, "/api/v1/inventory/recipe-categories/{id}", "DeleteRecipeCategory"},
+		"list recipes":             {http.MethodGet, "/api/v1/inventory/recipes", "/api/v1/inventory/recipes", "ListRecipes"},
+		"create recipe":            {http.MethodPost, "/api/v1/inventory/recipes", "/api/v1/inventory/recipes", "CreateRecipe"},
+		"get recipe":               {http.MethodGet, "/api/v1/inventory/recipes/" + id, "/api/v1/inventory/recipes/{id}", "GetRecipe"},
+		"recipes availability":     {http.MethodGet, "/api/v1/inventory/recipes/availability", "/api/v1/inventory/recipes/availability", "GetRecipesAvailability"},
+		"full recipe":              {http.MethodGet, "/api/v1/inventory/recipes/" + id + "/full", "/api/v1/inventory/recipes/{id}/full", "GetFullRecipe"},
+		"update recipe":            {http.MethodPut, "/api/v1/inventory/recipes/" + id, "/api/v1/inventory/recipes/{id}", "UpdateRecipe"},
+		"delete recipe":            {http.MethodDelete, "/api/v1/inventory/recipes/" + id, "/api/v1/inventory/recipes/{id}", "DeleteRecipe"},
+		"list recipe ingredients":  {http.MethodGet, "/api/v1/inventory/recipe-ingredients", "/api/v1/inventory/recipe-ingredients", "ListRecipeIngredients"},
+		"create recipe ingredient": {http.MethodPost, "/api/v1/inventory/recipe-ingredients", "/api/v1/inventory/recipe-ingredients", "CreateRecipeIngredient"},
+		"get recipe ingredient":    {http.MethodGet, "/api/v1/inventory/recipe-ingredients/" + id, "/api/v1/inventory/recipe-ingredients/{id}", "GetRecipeIngredient"},
+		"update recipe ingredient": {http.MethodPut, "/api/v1/inventory/recipe-ingredients/" + id, "/api/v1/inventory/recipe-ingredients/{id}", "UpdateRecipeIngredient"},
+		"delete recipe ingredient": {http.MethodDelete, "/api/v1/inventory/recipe-ingredients/" + id, "/api/v1/inventory/recipe-ingredients/{id}", "DeleteRecipeIngredient"},
+		// {id} is unconstrained, so other methods on the literal action paths fall through to the item routes
+		"GET merge falls through": {http.MethodGet, "/api/v1/inventory/suppliers/merge", "/api/v1/inventory/suppliers/{id}", "GetSupplier"},
+		"PUT bulk falls through":  {http.MethodPut, "/api/v1/inventory/existences/bulk", "/api/v1/inventory/existences/{id}", "UpdateExistence"},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+
+			var match mux.RouteMatch
+			require.True(t, router.Match(req, &match), "no route for %s %s", tc.method, tc.path)
+			require.NoError(t, match.MatchErr)
+
+			template, err := match.Route.GetPathTemplate()
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedTemplate, template)
+			assert.Equal(t, tc.expectedHandler, handlerName(match.Route.GetHandler()))
+		})
+	}
+
+	t.Run("health", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/inventory/p/health", nil)
+
+		var match mux.RouteMatch
+		require.True(t, router.Match(req, &match))
+		template, err := match.Route.GetPathTemplate()
+		require.NoError(t, err)
+		assert.Equal(t, "/api/v1/inventory/p/health", template)
+	})
+}
+
+// TestRouterRejectsUnknownRoutes tests that wrong methods get a 405 and unknown paths a 404
+func TestRouterRejectsUnknownRoutes(t *testing.T) {
+	router := newTestRouter(t)
+
+	const id = "7d3f2c1e-5b4a-4e6f-8a9b-0c1d2e3f4a5b"
+
+	testCases := map[string]struct {
+		method         string
+		path           string
+		expectedStatus int
+		expectedAllow  string
+	}{
+		"PATCH supplier":             {http.MethodPatch, "/api/v1/inventory/suppliers/" + id, http.StatusMethodNotAllowed, "GET, PUT, DELETE"},
+		"DELETE suppliers":           {http.MethodDelete, "/api/v1/inventory/suppliers", http.StatusMethodNotAllowed, "GET, POST"},
+		"POST ingredient category":   {http.MethodPost, "/api/v1/inventory/ingredient-categories/" + id, http.StatusMethodNotAllowed, "GET, PUT, DELETE"},
+		"GET reprice":                {http.MethodGet, "/api/v1/inventory/ingredients/" + id + "/reprice", http.StatusMethodNotAllowed, "POST"},
+		"POST avg cost":              {http.MethodPost, "/api/v1/inventory/ingredients/" + id + "/avg-cost", http.StatusMethodNotAllowed, "GET"},
+		"POST valuation":             {http.MethodPost, "/api/v1/inventory/valuation", http.StatusMethodNotAllowed, "GET"},
+		"GET consume":                {http.MethodGet, "/api/v1/inventory/existences/" + id + "/consume", http.StatusMethodNotAllowed, "POST"},
+		"DELETE existence history":   {http.MethodDelete, "/api/v1/inventory/existences/" + id + "/history", http.StatusMethodNotAllowed, "GET"},
+		"POST full recipe":           {http.MethodPost, "/api/v1/inventory/recipes/" + id + "/full", http.StatusMethodNotAllowed, "GET"},
+		"PUT recipes":                {http.MethodPut, "/api/v1/inventory/recipes", http.StatusMethodNotAllowed, "GET, POST"},
+		"POST health":                {http.MethodPost, "/api/v1/inventory/p/health", http.StatusMethodNotAllowed, "GET"},
+		"unknown entity":             {http.MethodGet, "/api/v1/inventory/widgets", http.StatusNotFound, ""},
+		"ingredients prefix sibling": {http.MethodGet, "/api/v1/inventory/ingredientsx", http.StatusNotFound, ""},
+		"unknown ingredient action":  {http.MethodPost, "/api/v1/inventory/ingredients/" + id + "/archive", http.StatusNotFound, ""},
+		"missing version prefix":     {http.MethodGet, "/inventory/suppliers", http.StatusNotFound, ""},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+			assert.Equal(t, tc.expectedAllow, rr.Header().Get("Allow"))
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package utils
+
+// Pagination describes the window a paginated list response covers
+type Pagination struct {
+	Limit   int  `json:"limit"`
+	Offset  int  `json:"offset"`
+	Total   int  `json:"total"`
+	HasMore bool `json:"has_more"`
+}
+
+// NewPagination builds the metadata for a page of returned items starting at offset
+// out of total matching items
+func NewPagination(limit, offset, returned, total int) *Pagination {
+	return &Pagination{
+		Limit:   limit,
+		Offset:  offset,
+		Total:   total,
+		HasMore: offset+returned < total,
+	}
+}
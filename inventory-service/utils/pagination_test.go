@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPagination(t *testing.T) {
+	testCases := map[string]struct {
+		limit, offset, returned, total int
+		expectedHasMore                bool
+	}{
+		"first page of many":    {limit: 10, offset: 0, returned: 10, total: 25, expectedHasMore: true},
+		"last partial page":     {limit: 10, offset: 20, returned: 5, total: 25, expectedHasMore: false},
+		"page ends exactly":     {limit: 10, offset: 10, returned: 10, total: 20, expectedHasMore: false},
+		"empty result":          {limit: 10, offset: 0, returned: 0, total: 0, expectedHasMore: false},
+		"offset beyond the end": {limit: 10, offset: 30, returned: 0, total: 25, expectedHasMore: false},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			pagination := NewPagination(tc.limit, tc.offset, tc.returned, tc.total)
+
+			assert.Equal(t, tc.limit, pagination.Limit)
+			assert.Equal(t, tc.offset, pagination.Offset)
+			assert.Equal(t, tc.total, pagination.Total)
+			assert.Equal(t, tc.expectedHasMore, pagination.HasMore)
+		})
+	}
+}
@@ -3,7 +3,10 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 	"time"
+
+	"shared/configcheck"
 )
 
 // Config holds the configuration for the invoice service
@@ -17,6 +20,26 @@ type Config struct {
 	DBName     string
 	DBSSLMode  string
 	LogLevel   string
+
+	// Currency settings (ISO 4217 codes)
+	DefaultCurrency     string
+	SupportedCurrencies []string
+
+	// PriceRounding names the strategy used to round existence final prices
+	PriceRounding string
+
+	// TotalsReconcileInterval is how often invoice totals are recomputed from their details (0 disables)
+	TotalsReconcileInterval time.Duration
+
+	// RequestTimeout bounds how long a request handler may run before the client gets a 503 (0 disables)
+	RequestTimeout time.Duration
+
+	// RequestLogSampleRate logs one in every N successful (2xx) requests; other responses are always logged
+	RequestLogSampleRate int
+
+	// HealthCheckDataService makes the health check depend on the data-service at DataServiceHealthURL
+	HealthCheckDataService bool
+	DataServiceHealthURL   string
 }
 
 // LoadConfig loads configuration from environment variables with defaults
@@ -31,9 +54,65 @@ func LoadConfig() *Config {
 		DBName:     getEnvString("DB_NAME", "icecream_store"),
 		DBSSLMode:  getEnvString("DB_SSLMODE", "disable"),
 		LogLevel:   getEnvString("LOG_LEVEL", "info"),
+
+		DefaultCurrency:     strings.ToUpper(getEnvString("STORE_CURRENCY", "CRC")),
+		SupportedCurrencies: getEnvList("SUPPORTED_CURRENCIES", "CRC,USD"),
+
+		PriceRounding: getEnvString("PRICE_ROUNDING_STRATEGY", "ceil-to-100"),
+
+		TotalsReconcileInterval: getEnvDuration("INVOICE_TOTALS_RECONCILE_INTERVAL", 24*time.Hour),
+
+		RequestTimeout: getEnvDuration("REQUEST_TIMEOUT", 10*time.Second),
+
+		RequestLogSampleRate: getEnvInt("REQUEST_LOG_SAMPLE_RATE", 1), // log every request
+
+		HealthCheckDataService: getEnvBool("HEALTH_CHECK_DATA_SERVICE", true),
+		DataServiceHealthURL:   getEnvString("DATA_SERVICE_HEALTH_URL", "http://localhost:8086/health"),
 	}
 }
 
+// Load reads the configuration like LoadConfig and validates it. The Config is returned
+// even when validation fails so the caller can set up logging before exiting
+func Load() (*Config, error) {
+	cfg := LoadConfig()
+	return cfg, cfg.Validate()
+}
+
+// Validate reports every invalid setting, including environment values LoadConfig
+// could not parse and replaced with defaults
+func (c *Config) Validate() error {
+	v := &configcheck.Validator{}
+
+	v.Port("INVOICE_SERVER_PORT", c.ServerPort)
+	v.Port("DB_PORT", c.DBPort)
+	v.Required("DB_HOST", c.DBHost)
+	v.Required("DB_USER", c.DBUser)
+	v.Required("DB_PASSWORD", c.DBPassword)
+	v.Required("DB_NAME", c.DBName)
+	v.OneOf("DB_SSLMODE", c.DBSSLMode, configcheck.SSLModes...)
+
+	v.Required("STORE_CURRENCY", c.DefaultCurrency)
+	if len(c.SupportedCurrencies) > 0 {
+		v.OneOf("STORE_CURRENCY", c.DefaultCurrency, c.SupportedCurrencies...)
+	}
+
+	v.EnvDuration("INVOICE_TOTALS_RECONCILE_INTERVAL")
+	v.NonNegative("INVOICE_TOTALS_RECONCILE_INTERVAL", c.TotalsReconcileInterval.Seconds())
+	v.EnvDuration("REQUEST_TIMEOUT")
+	v.NonNegative("REQUEST_TIMEOUT", c.RequestTimeout.Seconds())
+	v.EnvInt("REQUEST_LOG_SAMPLE_RATE")
+	if c.RequestLogSampleRate < 1 {
+		v.Addf("REQUEST_LOG_SAMPLE_RATE must be at least 1, got %d", c.RequestLogSampleRate)
+	}
+
+	v.EnvBool("HEALTH_CHECK_DATA_SERVICE")
+	if c.HealthCheckDataService {
+		v.Required("DATA_SERVICE_HEALTH_URL", c.DataServiceHealthURL)
+	}
+
+	return v.Err()
+}
+
 // getEnvString returns the environment variable value or default if not set
 func getEnvString(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -42,6 +121,17 @@ func getEnvString(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getEnvList returns a comma-separated environment variable as upper-cased, trimmed values
+func getEnvList(key, defaultValue string) []string {
+	var values []string
+	for _, value := range strings.Split(getEnvString(key, defaultValue), ",") {
+		if value = strings.ToUpper(strings.TrimSpace(value)); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 // getEnvInt returns the environment variable value as int or default if not set
 func getEnvInt(key string, defaultValue int) int {
 	if value := os.Getenv(key); value != "" {
@@ -61,3 +151,13 @@ func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
 	}
 	return defaultValue
 }
+
+// getEnvBool returns the environment variable value as bool or default if not set
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
@@ -5,7 +5,10 @@ import (
 	"testing"
 	"time"
 
+	"shared/configcheck"
+
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestLoadConfigDefaults(t *testing.T) {
@@ -25,6 +28,14 @@ func TestLoadConfigDefaults(t *testing.T) {
 	assert.Equal(t, "icecream_store", cfg.DBName)
 	assert.Equal(t, "disable", cfg.DBSSLMode)
 	assert.Equal(t, "info", cfg.LogLevel)
+	assert.Equal(t, "CRC", cfg.DefaultCurrency)
+	assert.Equal(t, []string{"CRC", "USD"}, cfg.SupportedCurrencies)
+	assert.Equal(t, "ceil-to-100", cfg.PriceRounding)
+	assert.Equal(t, 24*time.Hour, cfg.TotalsReconcileInterval)
+	assert.Equal(t, 10*time.Second, cfg.RequestTimeout)
+	assert.Equal(t, 1, cfg.RequestLogSampleRate)
+	assert.True(t, cfg.HealthCheckDataService)
+	assert.Equal(t, "http://localhost:8086/health", cfg.DataServiceHealthURL)
 }
 
 func TestLoadConfigFromEnvironment(t *testing.T) {
@@ -42,6 +53,12 @@ func TestLoadConfigFromEnvironment(t *testing.T) {
 	os.Setenv("DB_NAME", "invoice_db")
 	os.Setenv("DB_SSLMODE", "require")
 	os.Setenv("LOG_LEVEL", "debug")
+	os.Setenv("STORE_CURRENCY", "usd")
+	os.Setenv("SUPPORTED_CURRENCIES", "usd, eur ,")
+	os.Setenv("PRICE_ROUNDING_STRATEGY", "none")
+	os.Setenv("INVOICE_TOTALS_RECONCILE_INTERVAL", "6h")
+	os.Setenv("HEALTH_CHECK_DATA_SERVICE", "false")
+	os.Setenv("DATA_SERVICE_HEALTH_URL", "http://data-service:8086/health")
 
 	cfg := LoadConfig()
 
@@ -55,6 +72,12 @@ func TestLoadConfigFromEnvironment(t *testing.T) {
 	assert.Equal(t, "invoice_db", cfg.DBName)
 	assert.Equal(t, "require", cfg.DBSSLMode)
 	assert.Equal(t, "debug", cfg.LogLevel)
+	assert.Equal(t, "USD", cfg.DefaultCurrency)
+	assert.Equal(t, []string{"USD", "EUR"}, cfg.SupportedCurrencies)
+	assert.Equal(t, "none", cfg.PriceRounding)
+	assert.Equal(t, 6*time.Hour, cfg.TotalsReconcileInterval)
+	assert.False(t, cfg.HealthCheckDataService)
+	assert.Equal(t, "http://data-service:8086/health", cfg.DataServiceHealthURL)
 }
 
 func TestLoadConfigPartialEnvironment(t *testing.T) {
@@ -222,6 +245,12 @@ func clearEnvVars() {
 		"DB_NAME",
 		"DB_SSLMODE",
 		"LOG_LEVEL",
+		"STORE_CURRENCY",
+		"SUPPORTED_CURRENCIES",
+		"PRICE_ROUNDING_STRATEGY",
+		"INVOICE_TOTALS_RECONCILE_INTERVAL",
+		"HEALTH_CHECK_DATA_SERVICE",
+		"DATA_SERVICE_HEALTH_URL",
 		"TEST_STRING_VAR",
 		"NON_EXISTING_VAR",
 		"EMPTY_VAR",
@@ -239,3 +268,45 @@ func clearEnvVars() {
 		os.Unsetenv(v)
 	}
 }
+
+// TestValidate tests that defaults pass and that invalid or missing settings are all reported together
+func TestValidate(t *testing.T) {
+	t.Run("defaults are valid", func(t *testing.T) {
+		assert.NoError(t, LoadConfig().Validate())
+	})
+
+	t.Run("invalid values are aggregated", func(t *testing.T) {
+		t.Setenv("INVOICE_SERVER_PORT", "0")
+		t.Setenv("DB_SSLMODE", "sometimes")
+		t.Setenv("INVOICE_TOTALS_RECONCILE_INTERVAL", "daily")
+
+		_, err := Load()
+
+		var validationErr *configcheck.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, []string{
+			`INVOICE_SERVER_PORT must be a port between 1 and 65535, got "0"`,
+			`DB_SSLMODE must be one of disable, allow, prefer, require, verify-ca, verify-full, got "sometimes"`,
+			`INVOICE_TOTALS_RECONCILE_INTERVAL must be a duration such as 30s or 5m, got "daily"`,
+		}, validationErr.Problems)
+	})
+
+	t.Run("sample rate below one", func(t *testing.T) {
+		t.Setenv("REQUEST_LOG_SAMPLE_RATE", "0")
+
+		_, err := Load()
+
+		var validationErr *configcheck.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, []string{"REQUEST_LOG_SAMPLE_RATE must be at least 1, got 0"}, validationErr.Problems)
+	})
+
+	t.Run("missing required secret", func(t *testing.T) {
+		cfg := LoadConfig()
+		cfg.DBPassword = ""
+
+		var validationErr *configcheck.ValidationError
+		require.ErrorAs(t, cfg.Validate(), &validationErr)
+		assert.Equal(t, []string{"DB_PASSWORD is required"}, validationErr.Problems)
+	})
+}
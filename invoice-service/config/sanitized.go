@@ -0,0 +1,43 @@
+package config
+
+// RedactedValue replaces secrets in the sanitized configuration
+const RedactedValue = "[REDACTED]"
+
+// Sanitized returns the effective configuration keyed by the environment variable that sets
+// each value, with secrets redacted so it can be shown to operators. An unset secret stays
+// empty rather than redacted, so a missing secret is still visible
+func (c *Config) Sanitized() map[string]interface{} {
+	return map[string]interface{}{
+		"INVOICE_SERVER_PORT": c.ServerPort,
+		"INVOICE_SERVER_HOST": c.ServerHost,
+		"DB_HOST":             c.DBHost,
+		"DB_PORT":             c.DBPort,
+		"DB_USER":             c.DBUser,
+		"DB_PASSWORD":         redact(c.DBPassword),
+		"DB_NAME":             c.DBName,
+		"DB_SSLMODE":          c.DBSSLMode,
+		"LOG_LEVEL":           c.LogLevel,
+
+		"STORE_CURRENCY":       c.DefaultCurrency,
+		"SUPPORTED_CURRENCIES": c.SupportedCurrencies,
+
+		"PRICE_ROUNDING_STRATEGY": c.PriceRounding,
+
+		"INVOICE_TOTALS_RECONCILE_INTERVAL": c.TotalsReconcileInterval.String(),
+
+		"REQUEST_TIMEOUT": c.RequestTimeout.String(),
+
+		"REQUEST_LOG_SAMPLE_RATE": c.RequestLogSampleRate,
+
+		"HEALTH_CHECK_DATA_SERVICE": c.HealthCheckDataService,
+		"DATA_SERVICE_HEALTH_URL":   c.DataServiceHealthURL,
+	}
+}
+
+// redact hides a secret's value while keeping whether it is set
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return RedactedValue
+}
@@ -0,0 +1,34 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitized(t *testing.T) {
+	cfg := LoadConfig()
+	cfg.DBPassword = "db-password-value"
+
+	settings := cfg.Sanitized()
+
+	assert.Equal(t, RedactedValue, settings["DB_PASSWORD"])
+	assert.Equal(t, "localhost", settings["DB_HOST"])
+	assert.Equal(t, "8085", settings["INVOICE_SERVER_PORT"])
+	assert.Equal(t, "CRC", settings["STORE_CURRENCY"])
+	assert.Equal(t, []string{"CRC", "USD"}, settings["SUPPORTED_CURRENCIES"])
+	assert.Equal(t, "24h0m0s", settings["INVOICE_TOTALS_RECONCILE_INTERVAL"])
+
+	encoded, err := json.Marshal(settings)
+	require.NoError(t, err)
+	assert.NotContains(t, string(encoded), "db-password-value")
+}
+
+func TestSanitizedUnsetSecret(t *testing.T) {
+	cfg := LoadConfig()
+	cfg.DBPassword = ""
+
+	assert.Equal(t, "", cfg.Sanitized()["DB_PASSWORD"])
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 
 	"invoice-service/entities/expense_categories/models"
@@ -74,8 +75,8 @@ func (h *DBHandler) GetExpenseCategoryByID(id string) (*models.ExpenseCategory,
 }
 
 // ListExpenseCategories retrieves all expense categories from the database
-func (h *DBHandler) ListExpenseCategories() ([]models.ExpenseCategory, error) {
-	rows, err := h.db.Query(expenseCategorySQL.ListExpenseCategoriesQuery)
+func (h *DBHandler) ListExpenseCategories(ctx context.Context) ([]models.ExpenseCategory, error) {
+	rows, err := h.db.QueryContext(ctx, expenseCategorySQL.ListExpenseCategoriesQuery)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to execute expense categories list query")
 		return nil, err
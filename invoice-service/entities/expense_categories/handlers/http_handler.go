@@ -1,11 +1,13 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"net/http"
 
 	"invoice-service/entities/expense_categories/models"
+	"shared/httpx"
 
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
@@ -15,7 +17,7 @@ import (
 type DBHandlerInterface interface {
 	CreateExpenseCategory(req models.CreateExpenseCategoryRequest) (*models.ExpenseCategory, error)
 	GetExpenseCategoryByID(id string) (*models.ExpenseCategory, error)
-	ListExpenseCategories() ([]models.ExpenseCategory, error)
+	ListExpenseCategories(ctx context.Context) ([]models.ExpenseCategory, error)
 	UpdateExpenseCategory(id string, req models.UpdateExpenseCategoryRequest) (*models.ExpenseCategory, error)
 	DeleteExpenseCategory(id string) error
 }
@@ -45,9 +47,9 @@ func NewHttpHandlerWithInterface(dbHandler DBHandlerInterface, logger *logrus.Lo
 // CreateExpenseCategory handles POST /expense-categories
 func (h *HttpHandler) CreateExpenseCategory(w http.ResponseWriter, r *http.Request) {
 	var req models.CreateExpenseCategoryRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := httpx.DecodeJSONBody(r, &req); err != nil {
 		h.logger.WithError(err).Error("Invalid JSON in create expense category request")
-		h.writeErrorResponse(w, "Invalid JSON format", http.StatusBadRequest)
+		h.writeErrorResponse(w, httpx.DecodeErrorMessage(err, "Invalid JSON format"), http.StatusBadRequest)
 		return
 	}
 
@@ -68,6 +70,7 @@ func (h *HttpHandler) CreateExpenseCategory(w http.ResponseWriter, r *http.Reque
 		Data:    *expenseCategory,
 		Message: "Expense category created successfully",
 	}
+	h.logger.WithFields(httpx.RequestLogFields(r)).WithField("expense_category_id", expenseCategory.ID).Info("Expense category created successfully")
 	h.writeJSONResponse(w, response, http.StatusCreated)
 }
 
@@ -115,7 +118,7 @@ func (h *HttpHandler) GetExpenseCategory(w http.ResponseWriter, r *http.Request)
 
 // ListExpenseCategories handles GET /expense-categories
 func (h *HttpHandler) ListExpenseCategories(w http.ResponseWriter, r *http.Request) {
-	expenseCategories, err := h.dbHandler.ListExpenseCategories()
+	expenseCategories, err := h.dbHandler.ListExpenseCategories(r.Context())
 	if err != nil {
 		// DBHandler already logged the error, don't duplicate
 		response := models.ExpenseCategoryListResponse{
@@ -149,9 +152,9 @@ func (h *HttpHandler) UpdateExpenseCategory(w http.ResponseWriter, r *http.Reque
 	}
 
 	var req models.UpdateExpenseCategoryRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := httpx.DecodeJSONBody(r, &req); err != nil {
 		h.logger.WithError(err).Error("Invalid JSON in update expense category request")
-		h.writeErrorResponse(w, "Invalid JSON format", http.StatusBadRequest)
+		h.writeErrorResponse(w, httpx.DecodeErrorMessage(err, "Invalid JSON format"), http.StatusBadRequest)
 		return
 	}
 
@@ -183,6 +186,7 @@ func (h *HttpHandler) UpdateExpenseCategory(w http.ResponseWriter, r *http.Reque
 		Data:    *expenseCategory,
 		Message: "Expense category updated successfully",
 	}
+	h.logger.WithFields(httpx.RequestLogFields(r)).WithField("expense_category_id", expenseCategory.ID).Info("Expense category updated successfully")
 	h.writeJSONResponse(w, response, http.StatusOK)
 }
 
@@ -222,6 +226,7 @@ func (h *HttpHandler) DeleteExpenseCategory(w http.ResponseWriter, r *http.Reque
 		Success: true,
 		Message: "Expense category deleted successfully",
 	}
+	h.logger.WithFields(httpx.RequestLogFields(r)).WithField("expense_category_id", id).Info("Expense category deleted successfully")
 	h.writeJSONResponse(w, response, http.StatusOK)
 }
 
@@ -1,27 +1,107 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"invoice-service/entities/invoices/models"
 	invoiceSQL "invoice-service/entities/invoices/sql"
 	"math"
+	"shared/money"
+	"shared/pricing"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/lib/pq"
 	"github.com/sirupsen/logrus"
 )
 
+// uniqueViolation is the PostgreSQL error code for a unique constraint violation
+const uniqueViolation = "23505"
+
+// isUniqueViolation reports whether err was caused by a unique constraint
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == uniqueViolation
+}
+
 // DBHandler handles database operations for invoices
 type DBHandler struct {
-	db     *sql.DB
-	logger *logrus.Logger
+	db         *sql.DB
+	logger     *logrus.Logger
+	roundPrice pricing.RoundingStrategy
+	pricing    models.ExistencePricingDefaults
 }
 
 // NewDBHandler creates a new database handler for invoices
 func NewDBHandler(db *sql.DB, logger *logrus.Logger) *DBHandler {
 	return &DBHandler{
-		db:     db,
-		logger: logger,
+		db:         db,
+		logger:     logger,
+		roundPrice: pricing.CeilTo100,
+		pricing:    models.DefaultExistencePricing(),
+	}
+}
+
+// SetRoundingStrategy replaces the strategy used to round existence final prices
+func (h *DBHandler) SetRoundingStrategy(strategy pricing.RoundingStrategy) {
+	h.roundPrice = strategy
+}
+
+// LoadPricingDefaults reads the existence pricing percentages from system_configuration and caches them.
+// Keys that are missing or not numeric keep their compiled-in default.
+func (h *DBHandler) LoadPricingDefaults() error {
+	rows, err := h.db.Query(invoiceSQL.GetExistencePricingDefaultsQuery)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to load existence pricing defaults")
+		return err
+	}
+	defer rows.Close()
+
+	pricing := models.DefaultExistencePricing()
+	targets := map[string]*float64{
+		models.ConfigKeyIncomeMarginPercentage: &pricing.IncomeMarginPercentage,
+		models.ConfigKeyIvaPercentage:          &pricing.IvaPercentage,
+		models.ConfigKeyServiceTaxPercentage:   &pricing.ServiceTaxPercentage,
+	}
+
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			h.logger.WithError(err).Error("Failed to scan existence pricing default")
+			return err
+		}
+
+		target, ok := targets[key]
+		if !ok {
+			continue
+		}
+		percentage, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || percentage < 0 || percentage > 100 {
+			h.logger.WithFields(logrus.Fields{
+				"config_key":   key,
+				"config_value": value,
+			}).Warn("Ignoring invalid existence pricing default")
+			continue
+		}
+		*target = percentage
 	}
+
+	if err := rows.Err(); err != nil {
+		h.logger.WithError(err).Error("Error iterating existence pricing defaults")
+		return err
+	}
+
+	h.pricing = pricing
+	h.logger.WithFields(logrus.Fields{
+		"income_margin_percentage": pricing.IncomeMarginPercentage,
+		"iva_percentage":           pricing.IvaPercentage,
+		"service_tax_percentage":   pricing.ServiceTaxPercentage,
+	}).Info("Existence pricing defaults loaded")
+
+	return nil
 }
 
 // getExpenseCategoryName retrieves the expense category name by ID
@@ -57,10 +137,14 @@ func (h *DBHandler) CreateInvoice(req models.CreateInvoiceRequest) (*models.Invo
 
 	// Create the invoice
 	err = tx.QueryRow(invoiceSQL.CreateInvoiceQuery,
-		req.InvoiceNumber, transactionDate, req.TransactionType, req.SupplierID, req.ExpenseCategoryID, req.ImageURL, req.Notes).
-		Scan(&invoice.ID, &invoice.InvoiceNumber, &invoice.TransactionDate, &invoice.TransactionType, &invoice.SupplierID, &invoice.ExpenseCategoryID, &invoice.TotalAmount, &invoice.ImageURL, &invoice.Notes, &invoice.CreatedAt, &invoice.UpdatedAt)
+		req.InvoiceNumber, transactionDate, req.TransactionType, req.SupplierID, req.ExpenseCategoryID, req.ImageURL, req.Notes, req.Currency, req.TaxExempt).
+		Scan(&invoice.ID, &invoice.InvoiceNumber, &invoice.TransactionDate, &invoice.TransactionType, &invoice.SupplierID, &invoice.ExpenseCategoryID, &invoice.TotalAmount, &invoice.ImageURL, &invoice.Notes, &invoice.Currency, &invoice.TaxExempt, &invoice.CreatedAt, &invoice.UpdatedAt, &invoice.DeletedAt)
 
 	if err != nil {
+		if isUniqueViolation(err) {
+			h.logger.WithField("invoice_number", req.InvoiceNumber).Warn("Invoice number already exists")
+			return nil, models.ErrDuplicateInvoiceNumber
+		}
 		h.logger.WithError(err).WithFields(logrus.Fields{
 			"invoice_number": req.InvoiceNumber,
 		}).Error("Failed to create invoice in database")
@@ -76,13 +160,13 @@ func (h *DBHandler) CreateInvoice(req models.CreateInvoiceRequest) (*models.Invo
 		return nil, err
 	}
 
-	// Create invoice details
-	var totalAmount float64 = 0
+	// Create invoice details, summing their totals in cents
+	var total money.Money
 	for _, item := range req.Items {
 		var detail models.InvoiceDetail
 		err = tx.QueryRow(invoiceSQL.CreateInvoiceDetailQuery,
-			invoice.ID, item.IngredientID, item.Detail, item.Count, item.UnitType, item.Price, item.ExpirationDate).
-			Scan(&detail.ID, &detail.InvoiceID, &detail.IngredientID, &detail.Detail, &detail.Count, &detail.UnitType, &detail.Price, &detail.Total, &detail.ExpirationDate, &detail.CreatedAt, &detail.UpdatedAt)
+			invoice.ID, item.IngredientID, item.Detail, item.Count, item.UnitType, item.Price, item.ExpirationDate, item.Currency, item.Received()).
+			Scan(&detail.ID, &detail.InvoiceID, &detail.IngredientID, &detail.Detail, &detail.Count, &detail.UnitType, &detail.Price, &detail.Total, &detail.ReceivedCount, &detail.OutstandingCount, &detail.ExpirationDate, &detail.Currency, &detail.CreatedAt, &detail.UpdatedAt)
 
 		if err != nil {
 			h.logger.WithError(err).WithFields(logrus.Fields{
@@ -92,21 +176,23 @@ func (h *DBHandler) CreateInvoice(req models.CreateInvoiceRequest) (*models.Invo
 			return nil, err
 		}
 
-		totalAmount += detail.Total
+		total += money.FromFloat(detail.Total)
 
-		// Create existence if this is an ingredient item AND expense category is "Ingredients"
+		// Create existence if this is an ingredient item AND expense category is "Ingredients";
+		// only the received units are stocked, the outstanding ones arrive through receipts
 		//pvillalobos - get rid of hardcoded values
-		if item.IngredientID != nil && expenseCategoryName == "Ingredients" {
+		if item.IngredientID != nil && expenseCategoryName == "Ingredients" && detail.ReceivedCount > 0 {
 			existenceReq := models.CreateExistenceRequest{
 				IngredientID:           *item.IngredientID,
 				InvoiceDetailID:        detail.ID,
-				UnitsPurchased:         item.Count,
+				UnitsPurchased:         detail.ReceivedCount,
 				UnitType:               item.UnitType,
 				CostPerUnit:            item.Price,
 				ExpirationDate:         item.ExpirationDate,
-				IncomeMarginPercentage: 30.0, // Default 30%
-				IvaPercentage:          13.0, // Default 13%
-				ServiceTaxPercentage:   10.0, // Default 10%
+				IncomeMarginPercentage: h.pricing.IncomeMarginPercentage,
+				IvaPercentage:          h.pricing.IvaPercentage,
+				ServiceTaxPercentage:   h.pricing.ServiceTaxPercentage,
+				TaxExempt:              req.TaxExempt,
 			}
 
 			err = h.CreateInventoryExistence(tx, existenceReq)
@@ -121,6 +207,7 @@ func (h *DBHandler) CreateInvoice(req models.CreateInvoiceRequest) (*models.Invo
 	}
 
 	// Update invoice total
+	totalAmount := total.Float64()
 	_, err = tx.Exec(invoiceSQL.UpdateInvoiceTotalQuery, invoice.ID, totalAmount)
 	if err != nil {
 		h.logger.WithError(err).WithFields(logrus.Fields{
@@ -152,7 +239,7 @@ func (h *DBHandler) GetInvoiceByID(id string) (*models.Invoice, error) {
 	var invoice models.Invoice
 
 	err := h.db.QueryRow(invoiceSQL.GetInvoiceByIDQuery, id).
-		Scan(&invoice.ID, &invoice.InvoiceNumber, &invoice.TransactionDate, &invoice.TransactionType, &invoice.SupplierID, &invoice.ExpenseCategoryID, &invoice.TotalAmount, &invoice.ImageURL, &invoice.Notes, &invoice.CreatedAt, &invoice.UpdatedAt)
+		Scan(&invoice.ID, &invoice.InvoiceNumber, &invoice.TransactionDate, &invoice.TransactionType, &invoice.SupplierID, &invoice.ExpenseCategoryID, &invoice.TotalAmount, &invoice.ImageURL, &invoice.Notes, &invoice.Currency, &invoice.TaxExempt, &invoice.CreatedAt, &invoice.UpdatedAt, &invoice.DeletedAt)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -173,7 +260,7 @@ func (h *DBHandler) GetInvoiceByNumber(number string) (*models.Invoice, error) {
 	var invoice models.Invoice
 
 	err := h.db.QueryRow(invoiceSQL.GetInvoiceByNumberQuery, number).
-		Scan(&invoice.ID, &invoice.InvoiceNumber, &invoice.TransactionDate, &invoice.TransactionType, &invoice.SupplierID, &invoice.ExpenseCategoryID, &invoice.TotalAmount, &invoice.ImageURL, &invoice.Notes, &invoice.CreatedAt, &invoice.UpdatedAt)
+		Scan(&invoice.ID, &invoice.InvoiceNumber, &invoice.TransactionDate, &invoice.TransactionType, &invoice.SupplierID, &invoice.ExpenseCategoryID, &invoice.TotalAmount, &invoice.ImageURL, &invoice.Notes, &invoice.Currency, &invoice.TaxExempt, &invoice.CreatedAt, &invoice.UpdatedAt, &invoice.DeletedAt)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -189,9 +276,9 @@ func (h *DBHandler) GetInvoiceByNumber(number string) (*models.Invoice, error) {
 	return &invoice, nil
 }
 
-// ListInvoices retrieves all invoices from the database
-func (h *DBHandler) ListInvoices() ([]models.Invoice, error) {
-	rows, err := h.db.Query(invoiceSQL.ListInvoicesQuery)
+// ListInvoices retrieves all invoices from the database, skipping soft-deleted ones unless includeDeleted is set
+func (h *DBHandler) ListInvoices(ctx context.Context, includeDeleted bool) ([]models.Invoice, error) {
+	rows, err := h.db.QueryContext(ctx, invoiceSQL.ListInvoicesQuery, includeDeleted)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to execute invoices list query")
 		return nil, err
@@ -201,7 +288,7 @@ func (h *DBHandler) ListInvoices() ([]models.Invoice, error) {
 	var invoices []models.Invoice
 	for rows.Next() {
 		var invoice models.Invoice
-		err := rows.Scan(&invoice.ID, &invoice.InvoiceNumber, &invoice.TransactionDate, &invoice.TransactionType, &invoice.SupplierID, &invoice.ExpenseCategoryID, &invoice.TotalAmount, &invoice.ImageURL, &invoice.Notes, &invoice.CreatedAt, &invoice.UpdatedAt)
+		err := rows.Scan(&invoice.ID, &invoice.InvoiceNumber, &invoice.TransactionDate, &invoice.TransactionType, &invoice.SupplierID, &invoice.ExpenseCategoryID, &invoice.TotalAmount, &invoice.ImageURL, &invoice.Notes, &invoice.Currency, &invoice.TaxExempt, &invoice.CreatedAt, &invoice.UpdatedAt, &invoice.DeletedAt)
 		if err != nil {
 			h.logger.WithError(err).Warn("Failed to scan invoice row, skipping")
 			continue
@@ -221,13 +308,104 @@ func (h *DBHandler) ListInvoices() ([]models.Invoice, error) {
 	return invoices, nil
 }
 
+// ListInvoicesBySupplier retrieves all invoices issued by a supplier, skipping soft-deleted ones unless includeDeleted is set
+func (h *DBHandler) ListInvoicesBySupplier(ctx context.Context, supplierID string, includeDeleted bool) ([]models.Invoice, error) {
+	rows, err := h.db.QueryContext(ctx, invoiceSQL.ListInvoicesBySupplierQuery, supplierID, includeDeleted)
+	if err != nil {
+		h.logger.WithError(err).WithFields(logrus.Fields{
+			"supplier_id": supplierID,
+		}).Error("Failed to execute supplier invoices list query")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var invoices []models.Invoice
+	for rows.Next() {
+		var invoice models.Invoice
+		err := rows.Scan(&invoice.ID, &invoice.InvoiceNumber, &invoice.TransactionDate, &invoice.TransactionType, &invoice.SupplierID, &invoice.ExpenseCategoryID, &invoice.TotalAmount, &invoice.ImageURL, &invoice.Notes, &invoice.Currency, &invoice.TaxExempt, &invoice.CreatedAt, &invoice.UpdatedAt, &invoice.DeletedAt)
+		if err != nil {
+			h.logger.WithError(err).Warn("Failed to scan invoice row, skipping")
+			continue
+		}
+		invoices = append(invoices, invoice)
+	}
+
+	// Ensure we return an empty slice instead of nil for consistency
+	if invoices == nil {
+		invoices = []models.Invoice{}
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"supplier_id":    supplierID,
+		"invoices_count": len(invoices),
+	}).Info("Listed supplier invoices successfully")
+
+	return invoices, nil
+}
+
+// GetSupplierReport aggregates a supplier's non-deleted invoices dated between from and to (inclusive)
+// and the existences derived from them; asOf decides which existences count as expired
+func (h *DBHandler) GetSupplierReport(ctx context.Context, supplierID string, from, to, asOf time.Time) (*models.SupplierReport, error) {
+	logFields := logrus.Fields{
+		"supplier_id": supplierID,
+		"from":        from.Format("2006-01-02"),
+		"to":          to.Format("2006-01-02"),
+	}
+
+	invoiceRows, err := h.db.QueryContext(ctx, invoiceSQL.ListSupplierReportInvoicesQuery, supplierID, from, to)
+	if err != nil {
+		h.logger.WithError(err).WithFields(logFields).Error("Failed to execute supplier report invoices query")
+		return nil, err
+	}
+	defer invoiceRows.Close()
+
+	var invoices []models.SupplierReportInvoice
+	for invoiceRows.Next() {
+		var invoice models.SupplierReportInvoice
+		if err := invoiceRows.Scan(&invoice.ID, &invoice.Currency, &invoice.TotalAmount, &invoice.OrderedUnits, &invoice.ReceivedUnits); err != nil {
+			h.logger.WithError(err).WithFields(logFields).Error("Failed to scan supplier report invoice row")
+			return nil, err
+		}
+		invoices = append(invoices, invoice)
+	}
+	if err := invoiceRows.Err(); err != nil {
+		return nil, err
+	}
+
+	existenceRows, err := h.db.QueryContext(ctx, invoiceSQL.ListSupplierReportExistencesQuery, supplierID, from, to)
+	if err != nil {
+		h.logger.WithError(err).WithFields(logFields).Error("Failed to execute supplier report existences query")
+		return nil, err
+	}
+	defer existenceRows.Close()
+
+	var existences []models.SupplierReportExistence
+	for existenceRows.Next() {
+		var existence models.SupplierReportExistence
+		if err := existenceRows.Scan(&existence.Currency, &existence.TransactionDate, &existence.ExpirationDate, &existence.UnitsPurchased, &existence.UnitsAvailable, &existence.RemainingValue); err != nil {
+			h.logger.WithError(err).WithFields(logFields).Error("Failed to scan supplier report existence row")
+			return nil, err
+		}
+		existences = append(existences, existence)
+	}
+	if err := existenceRows.Err(); err != nil {
+		return nil, err
+	}
+
+	report := models.BuildSupplierReport(supplierID, from, to, asOf, invoices, existences)
+
+	h.logger.WithFields(logFields).WithField("invoices_count", report.InvoiceCount).Info("Built supplier report successfully")
+
+	return &report, nil
+}
+
 // UpdateInvoice updates an invoice in the database
 func (h *DBHandler) UpdateInvoice(id string, req models.UpdateInvoiceRequest) (*models.Invoice, error) {
 	var invoice models.Invoice
 
 	err := h.db.QueryRow(invoiceSQL.UpdateInvoiceQuery,
 		id, req.InvoiceNumber, req.TransactionDate, req.TransactionType, req.SupplierID, req.ExpenseCategoryID, req.ImageURL, req.Notes).
-		Scan(&invoice.ID, &invoice.InvoiceNumber, &invoice.TransactionDate, &invoice.TransactionType, &invoice.SupplierID, &invoice.ExpenseCategoryID, &invoice.TotalAmount, &invoice.ImageURL, &invoice.Notes, &invoice.CreatedAt, &invoice.UpdatedAt)
+		Scan(&invoice.ID, &invoice.InvoiceNumber, &invoice.TransactionDate, &invoice.TransactionType, &invoice.SupplierID, &invoice.ExpenseCategoryID, &invoice.TotalAmount, &invoice.ImageURL, &invoice.Notes, &invoice.Currency, &invoice.TaxExempt, &invoice.CreatedAt, &invoice.UpdatedAt, &invoice.DeletedAt)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -248,8 +426,85 @@ func (h *DBHandler) UpdateInvoice(id string, req models.UpdateInvoiceRequest) (*
 	return &invoice, nil
 }
 
-// DeleteInvoice deletes an invoice from the database
+// PatchInvoice updates only the invoice columns set in req, leaving the others intact
+func (h *DBHandler) PatchInvoice(id string, req models.PatchInvoiceRequest) (*models.Invoice, error) {
+	var invoice models.Invoice
+
+	query, args := buildPatchInvoiceQuery(id, req)
+	err := h.db.QueryRow(query, args...).
+		Scan(&invoice.ID, &invoice.InvoiceNumber, &invoice.TransactionDate, &invoice.TransactionType, &invoice.SupplierID, &invoice.ExpenseCategoryID, &invoice.TotalAmount, &invoice.ImageURL, &invoice.Notes, &invoice.Currency, &invoice.TaxExempt, &invoice.CreatedAt, &invoice.UpdatedAt, &invoice.DeletedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			// Don't log as error since "not found" is a normal business case
+			return nil, err
+		}
+		h.logger.WithError(err).WithFields(logrus.Fields{
+			"invoice_id": id,
+		}).Error("Failed to patch invoice in database")
+		return nil, err
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"invoice_id":     invoice.ID,
+		"invoice_number": invoice.InvoiceNumber,
+	}).Info("Invoice patched successfully")
+
+	return &invoice, nil
+}
+
+// buildPatchInvoiceQuery builds an UPDATE whose SET clause lists only the fields present in req.
+// The invoice ID is always $1; deleted invoices are left untouched.
+func buildPatchInvoiceQuery(id string, req models.PatchInvoiceRequest) (string, []interface{}) {
+	fields := []struct {
+		column string
+		value  interface{}
+		set    bool
+	}{
+		{"invoice_number", req.InvoiceNumber, req.InvoiceNumber != nil},
+		{"transaction_date", req.TransactionDate, req.TransactionDate != nil},
+		{"transaction_type", req.TransactionType, req.TransactionType != nil},
+		{"supplier_id", req.SupplierID, req.SupplierID != nil},
+		{"expense_category_id", req.ExpenseCategoryID, req.ExpenseCategoryID != nil},
+		{"image_url", req.ImageURL, req.ImageURL != nil},
+		{"notes", req.Notes, req.Notes != nil},
+	}
+
+	args := []interface{}{id}
+	assignments := []string{}
+	for _, field := range fields {
+		if !field.set {
+			continue
+		}
+		args = append(args, field.value)
+		assignments = append(assignments, fmt.Sprintf("%s = $%d", field.column, len(args)))
+	}
+	assignments = append(assignments, "updated_at = CURRENT_TIMESTAMP")
+
+	query := "UPDATE invoice SET " + strings.Join(assignments, ", ") +
+		" WHERE id = $1 AND deleted_at IS NULL RETURNING id, invoice_number, transaction_date, transaction_type, supplier_id, expense_category_id, total_amount, image_url, notes, currency, tax_exempt, created_at, updated_at, deleted_at"
+
+	return query, args
+}
+
+// DeleteInvoice soft-deletes an invoice so its details and existences stay on record.
+// It refuses with ErrInvoiceExistencesConsumed once any existence derived from the invoice has been used.
 func (h *DBHandler) DeleteInvoice(id string) error {
+	var consumed int
+	if err := h.db.QueryRow(invoiceSQL.CountConsumedInvoiceExistencesQuery, id).Scan(&consumed); err != nil {
+		h.logger.WithError(err).WithFields(logrus.Fields{
+			"invoice_id": id,
+		}).Error("Failed to check consumed existences for invoice delete")
+		return err
+	}
+	if consumed > 0 {
+		h.logger.WithFields(logrus.Fields{
+			"invoice_id":          id,
+			"consumed_existences": consumed,
+		}).Warn("Refusing to delete invoice with consumed existences")
+		return models.ErrInvoiceExistencesConsumed
+	}
+
 	result, err := h.db.Exec(invoiceSQL.DeleteInvoiceQuery, id)
 	if err != nil {
 		h.logger.WithError(err).WithFields(logrus.Fields{
@@ -279,6 +534,32 @@ func (h *DBHandler) DeleteInvoice(id string) error {
 	return nil
 }
 
+// RestoreInvoice clears the soft-delete marker of an invoice and returns it
+func (h *DBHandler) RestoreInvoice(id string) (*models.Invoice, error) {
+	var invoice models.Invoice
+
+	err := h.db.QueryRow(invoiceSQL.RestoreInvoiceQuery, id).
+		Scan(&invoice.ID, &invoice.InvoiceNumber, &invoice.TransactionDate, &invoice.TransactionType, &invoice.SupplierID, &invoice.ExpenseCategoryID, &invoice.TotalAmount, &invoice.ImageURL, &invoice.Notes, &invoice.Currency, &invoice.TaxExempt, &invoice.CreatedAt, &invoice.UpdatedAt, &invoice.DeletedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			// Either the invoice does not exist or it is not deleted
+			return nil, err
+		}
+		h.logger.WithError(err).WithFields(logrus.Fields{
+			"invoice_id": id,
+		}).Error("Failed to restore invoice in database")
+		return nil, err
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"invoice_id":     invoice.ID,
+		"invoice_number": invoice.InvoiceNumber,
+	}).Info("Invoice restored successfully")
+
+	return &invoice, nil
+}
+
 // CreateInvoiceDetail creates a new invoice detail in the database
 func (h *DBHandler) CreateInvoiceDetail(req models.CreateInvoiceDetailRequest) (*models.InvoiceDetail, error) {
 	tx, err := h.db.Begin()
@@ -288,12 +569,25 @@ func (h *DBHandler) CreateInvoiceDetail(req models.CreateInvoiceDetailRequest) (
 	}
 	defer tx.Rollback()
 
+	// Lock the parent invoice so a detail cannot be added to a deleted one
+	var invoiceID string
+	if err := tx.QueryRow(invoiceSQL.LockActiveInvoiceQuery, req.InvoiceID).Scan(&invoiceID); err != nil {
+		if err == sql.ErrNoRows {
+			// Don't log as error since "not found" is a normal business case
+			return nil, err
+		}
+		h.logger.WithError(err).WithFields(logrus.Fields{
+			"invoice_id": req.InvoiceID,
+		}).Error("Failed to lock invoice for detail creation")
+		return nil, err
+	}
+
 	var detail models.InvoiceDetail
 
 	// Create the invoice detail
 	err = tx.QueryRow(invoiceSQL.CreateInvoiceDetailQuery,
-		req.InvoiceID, req.IngredientID, req.Detail, req.Count, req.UnitType, req.Price, req.ExpirationDate).
-		Scan(&detail.ID, &detail.InvoiceID, &detail.IngredientID, &detail.Detail, &detail.Count, &detail.UnitType, &detail.Price, &detail.Total, &detail.ExpirationDate, &detail.CreatedAt, &detail.UpdatedAt)
+		req.InvoiceID, req.IngredientID, req.Detail, req.Count, req.UnitType, req.Price, req.ExpirationDate, req.Currency, req.Received()).
+		Scan(&detail.ID, &detail.InvoiceID, &detail.IngredientID, &detail.Detail, &detail.Count, &detail.UnitType, &detail.Price, &detail.Total, &detail.ReceivedCount, &detail.OutstandingCount, &detail.ExpirationDate, &detail.Currency, &detail.CreatedAt, &detail.UpdatedAt)
 
 	if err != nil {
 		h.logger.WithError(err).WithFields(logrus.Fields{
@@ -336,12 +630,91 @@ func (h *DBHandler) CreateInvoiceDetail(req models.CreateInvoiceDetailRequest) (
 	return &detail, nil
 }
 
+// RecordInvoiceDetailReceipt adds a late delivery to an invoice detail and stocks the received units.
+// The detail row stays locked until commit, so concurrent receipts cannot over-receive it. Existences are
+// created the same way as on invoice creation: for ingredient details of "Ingredients" invoices only.
+func (h *DBHandler) RecordInvoiceDetailReceipt(invoiceID, detailID string, req models.RecordReceiptRequest) (*models.InvoiceDetail, error) {
+	tx, err := h.db.Begin()
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to begin transaction for invoice detail receipt")
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var outstanding float64
+	var taxExempt bool
+	var expenseCategoryName string
+	err = tx.QueryRow(invoiceSQL.LockInvoiceDetailForReceiptQuery, detailID, invoiceID).Scan(&outstanding, &taxExempt, &expenseCategoryName)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			// Don't log as error since "not found" is a normal business case
+			return nil, err
+		}
+		h.logger.WithError(err).WithFields(logrus.Fields{
+			"invoice_id":        invoiceID,
+			"invoice_detail_id": detailID,
+		}).Error("Failed to lock invoice detail for receipt")
+		return nil, err
+	}
+
+	// Counts are stored as DECIMAL(10,2), so compare at cent precision
+	if money.FromFloat(req.Count) > money.FromFloat(outstanding) {
+		return nil, models.ErrReceiptExceedsOutstanding
+	}
+
+	var detail models.InvoiceDetail
+	err = tx.QueryRow(invoiceSQL.RecordInvoiceDetailReceiptQuery, detailID, req.Count).
+		Scan(&detail.ID, &detail.InvoiceID, &detail.IngredientID, &detail.Detail, &detail.Count, &detail.UnitType, &detail.Price, &detail.Total, &detail.ReceivedCount, &detail.OutstandingCount, &detail.ExpirationDate, &detail.Currency, &detail.CreatedAt, &detail.UpdatedAt)
+	if err != nil {
+		h.logger.WithError(err).WithFields(logrus.Fields{
+			"invoice_detail_id": detailID,
+		}).Error("Failed to record invoice detail receipt")
+		return nil, err
+	}
+
+	if detail.IngredientID != nil && expenseCategoryName == "Ingredients" {
+		expirationDate := detail.ExpirationDate
+		if req.ExpirationDate != nil {
+			expirationDate = req.ExpirationDate
+		}
+		existenceReq := models.CreateExistenceRequest{
+			IngredientID:           *detail.IngredientID,
+			InvoiceDetailID:        detail.ID,
+			UnitsPurchased:         req.Count,
+			UnitType:               detail.UnitType,
+			CostPerUnit:            detail.Price,
+			ExpirationDate:         expirationDate,
+			IncomeMarginPercentage: h.pricing.IncomeMarginPercentage,
+			IvaPercentage:          h.pricing.IvaPercentage,
+			ServiceTaxPercentage:   h.pricing.ServiceTaxPercentage,
+			TaxExempt:              taxExempt,
+		}
+		if err := h.CreateInventoryExistence(tx, existenceReq); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		h.logger.WithError(err).Error("Failed to commit invoice detail receipt transaction")
+		return nil, err
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"invoice_detail_id": detail.ID,
+		"invoice_id":        detail.InvoiceID,
+		"received":          req.Count,
+		"outstanding":       detail.OutstandingCount,
+	}).Info("Invoice detail receipt recorded successfully")
+
+	return &detail, nil
+}
+
 // GetInvoiceDetailByID retrieves an invoice detail by ID from the database
 func (h *DBHandler) GetInvoiceDetailByID(id string) (*models.InvoiceDetail, error) {
 	var detail models.InvoiceDetail
 
 	err := h.db.QueryRow(invoiceSQL.GetInvoiceDetailByIDQuery, id).
-		Scan(&detail.ID, &detail.InvoiceID, &detail.IngredientID, &detail.Detail, &detail.Count, &detail.UnitType, &detail.Price, &detail.Total, &detail.ExpirationDate, &detail.CreatedAt, &detail.UpdatedAt)
+		Scan(&detail.ID, &detail.InvoiceID, &detail.IngredientID, &detail.Detail, &detail.Count, &detail.UnitType, &detail.Price, &detail.Total, &detail.ReceivedCount, &detail.OutstandingCount, &detail.ExpirationDate, &detail.Currency, &detail.CreatedAt, &detail.UpdatedAt)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -371,7 +744,7 @@ func (h *DBHandler) GetInvoiceDetailsByInvoiceID(invoiceID string) ([]models.Inv
 	var details []models.InvoiceDetail
 	for rows.Next() {
 		var detail models.InvoiceDetail
-		err := rows.Scan(&detail.ID, &detail.InvoiceID, &detail.IngredientID, &detail.Detail, &detail.Count, &detail.UnitType, &detail.Price, &detail.Total, &detail.ExpirationDate, &detail.CreatedAt, &detail.UpdatedAt)
+		err := rows.Scan(&detail.ID, &detail.InvoiceID, &detail.IngredientID, &detail.Detail, &detail.Count, &detail.UnitType, &detail.Price, &detail.Total, &detail.ReceivedCount, &detail.OutstandingCount, &detail.ExpirationDate, &detail.Currency, &detail.CreatedAt, &detail.UpdatedAt)
 		if err != nil {
 			h.logger.WithError(err).Warn("Failed to scan invoice detail row, skipping")
 			continue
@@ -393,8 +766,8 @@ func (h *DBHandler) GetInvoiceDetailsByInvoiceID(invoiceID string) ([]models.Inv
 }
 
 // ListInvoiceDetails retrieves all invoice details from the database
-func (h *DBHandler) ListInvoiceDetails() ([]models.InvoiceDetail, error) {
-	rows, err := h.db.Query(invoiceSQL.ListInvoiceDetailsQuery)
+func (h *DBHandler) ListInvoiceDetails(ctx context.Context) ([]models.InvoiceDetail, error) {
+	rows, err := h.db.QueryContext(ctx, invoiceSQL.ListInvoiceDetailsQuery)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to execute invoice details list query")
 		return nil, err
@@ -404,7 +777,7 @@ func (h *DBHandler) ListInvoiceDetails() ([]models.InvoiceDetail, error) {
 	var details []models.InvoiceDetail
 	for rows.Next() {
 		var detail models.InvoiceDetail
-		err := rows.Scan(&detail.ID, &detail.InvoiceID, &detail.IngredientID, &detail.Detail, &detail.Count, &detail.UnitType, &detail.Price, &detail.Total, &detail.ExpirationDate, &detail.CreatedAt, &detail.UpdatedAt)
+		err := rows.Scan(&detail.ID, &detail.InvoiceID, &detail.IngredientID, &detail.Detail, &detail.Count, &detail.UnitType, &detail.Price, &detail.Total, &detail.ReceivedCount, &detail.OutstandingCount, &detail.ExpirationDate, &detail.Currency, &detail.CreatedAt, &detail.UpdatedAt)
 		if err != nil {
 			h.logger.WithError(err).Warn("Failed to scan invoice detail row, skipping")
 			continue
@@ -437,7 +810,7 @@ func (h *DBHandler) UpdateInvoiceDetail(id string, req models.UpdateInvoiceDetai
 
 	err = tx.QueryRow(invoiceSQL.UpdateInvoiceDetailQuery,
 		id, req.IngredientID, req.Detail, req.Count, req.UnitType, req.Price, req.ExpirationDate).
-		Scan(&detail.ID, &detail.InvoiceID, &detail.IngredientID, &detail.Detail, &detail.Count, &detail.UnitType, &detail.Price, &detail.Total, &detail.ExpirationDate, &detail.CreatedAt, &detail.UpdatedAt)
+		Scan(&detail.ID, &detail.InvoiceID, &detail.IngredientID, &detail.Detail, &detail.Count, &detail.UnitType, &detail.Price, &detail.Total, &detail.ReceivedCount, &detail.OutstandingCount, &detail.ExpirationDate, &detail.Currency, &detail.CreatedAt, &detail.UpdatedAt)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -563,21 +936,129 @@ func (h *DBHandler) DeleteInvoiceDetail(id string) error {
 	return nil
 }
 
+// ReconcileInvoiceTotals recomputes every invoice total from its details and corrects the ones that drifted.
+// It returns how many invoices were corrected. Invoices that fail are logged and skipped.
+func (h *DBHandler) ReconcileInvoiceTotals() (int, error) {
+	rows, err := h.db.Query(invoiceSQL.ListInvoiceIDsQuery)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list invoices for total reconciliation")
+		return 0, err
+	}
+
+	var invoiceIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			h.logger.WithError(err).Error("Failed to scan invoice ID for total reconciliation")
+			return 0, err
+		}
+		invoiceIDs = append(invoiceIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		h.logger.WithError(err).Error("Error iterating invoices for total reconciliation")
+		return 0, err
+	}
+
+	corrected, failed := 0, 0
+	for _, id := range invoiceIDs {
+		fixed, err := h.reconcileInvoiceTotal(id)
+		if err != nil {
+			failed++
+			continue
+		}
+		if fixed {
+			corrected++
+		}
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"invoices_checked":   len(invoiceIDs),
+		"invoices_corrected": corrected,
+		"invoices_failed":    failed,
+	}).Info("Invoice total reconciliation completed")
+
+	if failed > 0 {
+		return corrected, fmt.Errorf("failed to reconcile %d of %d invoices", failed, len(invoiceIDs))
+	}
+	return corrected, nil
+}
+
+// reconcileInvoiceTotal locks one invoice row and rewrites its total if it no longer matches its details.
+// The row lock serializes concurrent reconciliations and detail writes on the same invoice.
+func (h *DBHandler) reconcileInvoiceTotal(id string) (bool, error) {
+	tx, err := h.db.Begin()
+	if err != nil {
+		h.logger.WithError(err).WithField("invoice_id", id).Error("Failed to begin transaction for total reconciliation")
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var storedTotal *float64
+	if err := tx.QueryRow(invoiceSQL.LockInvoiceTotalQuery, id).Scan(&storedTotal); err != nil {
+		if err == sql.ErrNoRows {
+			// Deleted since it was listed, nothing to reconcile
+			return false, nil
+		}
+		h.logger.WithError(err).WithField("invoice_id", id).Error("Failed to lock invoice for total reconciliation")
+		return false, err
+	}
+
+	var detailsTotal float64
+	if err := tx.QueryRow(invoiceSQL.GetInvoiceTotalFromDetailsQuery, id).Scan(&detailsTotal); err != nil {
+		h.logger.WithError(err).WithField("invoice_id", id).Error("Failed to get invoice total from details")
+		return false, err
+	}
+
+	// Totals are stored as DECIMAL(12,2), so compare at cent precision
+	if storedTotal != nil && math.Round(*storedTotal*100) == math.Round(detailsTotal*100) {
+		return false, nil
+	}
+
+	if _, err := tx.Exec(invoiceSQL.UpdateInvoiceTotalQuery, id, detailsTotal); err != nil {
+		h.logger.WithError(err).WithField("invoice_id", id).Error("Failed to correct invoice total")
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.logger.WithError(err).WithField("invoice_id", id).Error("Failed to commit invoice total correction")
+		return false, err
+	}
+
+	fields := logrus.Fields{
+		"invoice_id":    id,
+		"details_total": detailsTotal,
+	}
+	if storedTotal != nil {
+		fields["stored_total"] = *storedTotal
+	}
+	h.logger.WithFields(fields).Warn("Corrected drifted invoice total")
+
+	return true, nil
+}
+
 // CreateInventoryExistence creates an existence record from an invoice detail
 func (h *DBHandler) CreateInventoryExistence(tx *sql.Tx, req models.CreateExistenceRequest) error {
 	// Calculate derived fields
 	itemsPerUnit := 1 //pvillalobos - we would have to request this in the invoice item
 	costPerItem := req.CostPerUnit / float64(itemsPerUnit)
 
-	// Calculate margins and taxes
-	incomeMarginAmount := costPerItem * req.IncomeMarginPercentage / 100
-	ivaAmount := (costPerItem + incomeMarginAmount) * req.IvaPercentage / 100
-	serviceTaxAmount := (costPerItem + incomeMarginAmount) * req.ServiceTaxPercentage / 100
+	// Tax-exempt purchases are priced and recorded without IVA or service tax
+	if req.TaxExempt {
+		req.IvaPercentage = 0
+		req.ServiceTaxPercentage = 0
+	}
+
+	// Calculate margins and taxes in cents so the price lands exactly on rounding boundaries
+	price := pricing.CalculateExistencePrice(costPerItem, req.IncomeMarginPercentage, req.IvaPercentage, req.ServiceTaxPercentage)
+	incomeMarginAmount := price.IncomeMarginAmount.Float64()
+	ivaAmount := price.IvaAmount.Float64()
+	serviceTaxAmount := price.ServiceTaxAmount.Float64()
 
 	// Calculate final price
-	calculatedPrice := costPerItem + incomeMarginAmount + ivaAmount + serviceTaxAmount
-	// Round up to nearest 100
-	finalPrice := math.Ceil(calculatedPrice/100) * 100
+	calculatedPrice := price.CalculatedPrice.Float64()
+	finalPrice := h.roundPrice(calculatedPrice)
 
 	// Log calculations for debugging
 	h.logger.WithFields(logrus.Fields{
@@ -588,6 +1069,7 @@ func (h *DBHandler) CreateInventoryExistence(tx *sql.Tx, req models.CreateExiste
 		"iva_amount":               ivaAmount,
 		"service_tax_percentage":   req.ServiceTaxPercentage,
 		"service_tax_amount":       serviceTaxAmount,
+		"tax_exempt":               req.TaxExempt,
 		"calculated_price":         calculatedPrice,
 		"final_price":              finalPrice,
 	}).Debug("Existence calculations completed")
@@ -0,0 +1,1055 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"invoice-service/entities/invoices/models"
+	"shared/pricing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTestDBHandler(t *testing.T) (*DBHandler, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, mock.ExpectationsWereMet())
+		db.Close()
+	})
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	return NewDBHandler(db, logger), mock
+}
+
+var invoiceColumns = []string{
+	"id", "invoice_number", "transaction_date", "transaction_type", "supplier_id",
+	"expense_category_id", "total_amount", "image_url", "notes", "currency", "tax_exempt", "created_at", "updated_at", "deleted_at",
+}
+
+var invoiceDetailColumns = []string{
+	"id", "invoice_id", "ingredient_id", "detail", "count", "unit_type", "price", "total",
+	"received_count", "outstanding_count", "expiration_date", "currency", "created_at", "updated_at",
+}
+
+func TestDBHandler_ListInvoicesBySupplier(t *testing.T) {
+	supplierID := "11111111-1111-1111-1111-111111111111"
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := map[string]struct {
+		rows          *sqlmock.Rows
+		queryErr      error
+		expectedIDs   []string
+		expectedError bool
+	}{
+		"returns the supplier's invoices": {
+			rows: sqlmock.NewRows(invoiceColumns).
+				AddRow("invoice-2", "INV-002", now, "outcome", supplierID, "category-1", 2500.0, "img2.png", nil, "CRC", false, now, now, nil).
+				AddRow("invoice-1", "INV-001", now.AddDate(0, -1, 0), "outcome", supplierID, "category-1", 1000.0, "img1.png", nil, "CRC", false, now, now, nil),
+			expectedIDs: []string{"invoice-2", "invoice-1"},
+		},
+		"supplier without invoices returns empty slice": {
+			rows:        sqlmock.NewRows(invoiceColumns),
+			expectedIDs: []string{},
+		},
+		"database error": {
+			queryErr:      fmt.Errorf("connection refused"),
+			expectedError: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler, mock := setupTestDBHandler(t)
+
+			expectation := mock.ExpectQuery(regexp.QuoteMeta("WHERE supplier_id = $1")).WithArgs(supplierID, false)
+			if tc.queryErr != nil {
+				expectation.WillReturnError(tc.queryErr)
+			} else {
+				expectation.WillReturnRows(tc.rows)
+			}
+
+			invoices, err := handler.ListInvoicesBySupplier(context.Background(), supplierID, false)
+
+			if tc.expectedError {
+				assert.Error(t, err)
+				assert.Nil(t, invoices)
+				return
+			}
+
+			require.NoError(t, err)
+			ids := []string{}
+			for _, invoice := range invoices {
+				require.NotNil(t, invoice.SupplierID)
+				assert.Equal(t, supplierID, *invoice.SupplierID)
+				ids = append(ids, invoice.ID)
+			}
+			assert.Equal(t, tc.expectedIDs, ids)
+		})
+	}
+}
+
+func TestDBHandler_GetSupplierReport(t *testing.T) {
+	supplierID := "11111111-1111-1111-1111-111111111111"
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+	asOf := time.Date(2024, 4, 15, 0, 0, 0, 0, time.UTC)
+	date := func(month time.Month, day int) time.Time { return time.Date(2024, month, day, 0, 0, 0, 0, time.UTC) }
+
+	handler, mock := setupTestDBHandler(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM invoice i")).WithArgs(supplierID, from, to).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "currency", "total_amount", "ordered_units", "received_units"}).
+			AddRow("invoice-1", "CRC", 10000.0, 10.0, 10.0).
+			AddRow("invoice-2", "CRC", 20001.0, 5.0, 2.0).
+			AddRow("invoice-3", "USD", 150.5, 3.0, 3.0))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM existences e")).WithArgs(supplierID, from, to).
+		WillReturnRows(sqlmock.NewRows([]string{"currency", "transaction_date", "expiration_date", "units_purchased", "units_available", "remaining_value"}).
+			AddRow("CRC", date(1, 10), date(4, 10), 10.0, 3.0, 1500.0). // expired with stock left
+			AddRow("USD", date(1, 20), date(4, 1), 4.0, 1.5, 12.75).    // expired with stock left, other currency
+			AddRow("CRC", date(2, 1), date(5, 1), 2.0, 2.0, 4000.0).    // not expired yet
+			AddRow("CRC", date(3, 1), date(3, 31), 3.0, 0.0, 0.0).      // expired but used up
+			AddRow("USD", date(3, 5), nil, 1.0, 1.0, 50.0))             // never expires
+
+	report, err := handler.GetSupplierReport(context.Background(), supplierID, from, to, asOf)
+	require.NoError(t, err)
+
+	assert.Equal(t, supplierID, report.SupplierID)
+	assert.Equal(t, "2024-01-01", report.From)
+	assert.Equal(t, "2024-03-31", report.To)
+	assert.Equal(t, 3, report.InvoiceCount)
+	assert.Equal(t, []models.SupplierSpend{
+		{Currency: "CRC", InvoiceCount: 2, TotalSpend: 30001, AverageInvoiceValue: 15000.5},
+		{Currency: "USD", InvoiceCount: 1, TotalSpend: 150.5, AverageInvoiceValue: 150.5},
+	}, report.Spend)
+	assert.Equal(t, models.SupplierDeliveryQuality{
+		FullyReceivedInvoices: 2,
+		OutstandingInvoices:   1,
+		FillRatePercentage:    83.33,
+	}, report.Delivery)
+
+	assert.Equal(t, 5, report.Expiry.Existences)
+	assert.Equal(t, 4, report.Expiry.WithExpirationDate)
+	assert.Equal(t, 2, report.Expiry.ExpiredWithStock)
+	assert.Equal(t, 4.5, report.Expiry.ExpiredUnits)
+	assert.Equal(t, []models.SupplierStockValue{
+		{Currency: "CRC", Value: 1500},
+		{Currency: "USD", Value: 12.75},
+	}, report.Expiry.ExpiredStockValue)
+	require.NotNil(t, report.Expiry.AverageShelfLifeDays)
+	assert.Equal(t, 70.8, *report.Expiry.AverageShelfLifeDays) // (91 + 72 + 90 + 30) / 4
+}
+
+func TestDBHandler_GetSupplierReport_NoInvoices(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+
+	handler, mock := setupTestDBHandler(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM invoice i")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "currency", "total_amount", "ordered_units", "received_units"}))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM existences e")).
+		WillReturnRows(sqlmock.NewRows([]string{"currency", "transaction_date", "expiration_date", "units_purchased", "units_available", "remaining_value"}))
+
+	report, err := handler.GetSupplierReport(context.Background(), "supplier-1", from, to, to)
+	require.NoError(t, err)
+
+	assert.Equal(t, 0, report.InvoiceCount)
+	assert.Equal(t, []models.SupplierSpend{}, report.Spend)
+	assert.Zero(t, report.Delivery.FillRatePercentage)
+	assert.Equal(t, []models.SupplierStockValue{}, report.Expiry.ExpiredStockValue)
+	assert.Nil(t, report.Expiry.AverageShelfLifeDays)
+}
+
+func TestDBHandler_GetSupplierReport_DatabaseError(t *testing.T) {
+	handler, mock := setupTestDBHandler(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM invoice i")).WillReturnError(fmt.Errorf("connection refused"))
+
+	report, err := handler.GetSupplierReport(context.Background(), "supplier-1", time.Now(), time.Now(), time.Now())
+	assert.Error(t, err)
+	assert.Nil(t, report)
+}
+
+func TestDBHandler_ListInvoicesCancelledContext(t *testing.T) {
+	testCases := map[string]struct {
+		cancelAfter time.Duration
+	}{
+		"context cancelled before the query":     {cancelAfter: 0},
+		"context cancelled while the query runs": {cancelAfter: 20 * time.Millisecond},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			logger := logrus.New()
+			logger.SetLevel(logrus.FatalLevel)
+			handler := NewDBHandler(db, logger)
+
+			mock.ExpectQuery("FROM invoices").
+				WillDelayFor(time.Second).
+				WillReturnRows(sqlmock.NewRows(invoiceColumns))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			if tc.cancelAfter == 0 {
+				cancel()
+			} else {
+				time.AfterFunc(tc.cancelAfter, cancel)
+				defer cancel()
+			}
+
+			start := time.Now()
+			invoices, err := handler.ListInvoices(ctx, false)
+
+			assert.Error(t, err)
+			assert.Nil(t, invoices)
+			assert.Less(t, time.Since(start), 500*time.Millisecond)
+		})
+	}
+}
+
+func TestDBHandler_ListInvoicesExcludesDeleted(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	deletedAt := now.Add(time.Hour)
+
+	testCases := map[string]struct {
+		includeDeleted bool
+		rows           *sqlmock.Rows
+		expectedIDs    []string
+	}{
+		"deleted invoices are filtered out by default": {
+			rows: sqlmock.NewRows(invoiceColumns).
+				AddRow("invoice-1", "INV-001", now, "outcome", nil, "category-1", 1000.0, "img1.png", nil, "CRC", false, now, now, nil),
+			expectedIDs: []string{"invoice-1"},
+		},
+		"include_deleted returns deleted invoices with their deletion time": {
+			includeDeleted: true,
+			rows: sqlmock.NewRows(invoiceColumns).
+				AddRow("invoice-2", "INV-002", now, "outcome", nil, "category-1", 700.0, "img2.png", nil, "CRC", false, now, now, deletedAt).
+				AddRow("invoice-1", "INV-001", now, "outcome", nil, "category-1", 1000.0, "img1.png", nil, "CRC", false, now, now, nil),
+			expectedIDs: []string{"invoice-2", "invoice-1"},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler, mock := setupTestDBHandler(t)
+
+			mock.ExpectQuery(regexp.QuoteMeta("WHERE $1 OR deleted_at IS NULL")).
+				WithArgs(tc.includeDeleted).
+				WillReturnRows(tc.rows)
+
+			invoices, err := handler.ListInvoices(context.Background(), tc.includeDeleted)
+
+			require.NoError(t, err)
+			ids := []string{}
+			for _, invoice := range invoices {
+				ids = append(ids, invoice.ID)
+				if invoice.ID == "invoice-2" {
+					require.NotNil(t, invoice.DeletedAt)
+					assert.True(t, deletedAt.Equal(*invoice.DeletedAt))
+				} else {
+					assert.Nil(t, invoice.DeletedAt)
+				}
+			}
+			assert.Equal(t, tc.expectedIDs, ids)
+		})
+	}
+}
+
+func TestDBHandler_DeleteInvoice(t *testing.T) {
+	testCases := map[string]struct {
+		consumed      int
+		rowsAffected  int64
+		expectUpdate  bool
+		expectedError error
+	}{
+		"unconsumed invoice is soft-deleted": {
+			rowsAffected: 1,
+			expectUpdate: true,
+		},
+		"consumed existences block the delete": {
+			consumed:      2,
+			expectedError: models.ErrInvoiceExistencesConsumed,
+		},
+		"missing or already deleted invoice": {
+			rowsAffected:  0,
+			expectUpdate:  true,
+			expectedError: sql.ErrNoRows,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler, mock := setupTestDBHandler(t)
+
+			mock.ExpectQuery(regexp.QuoteMeta("e.units_available < e.units_purchased")).
+				WithArgs("invoice-1").
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(tc.consumed))
+			if tc.expectUpdate {
+				mock.ExpectExec(regexp.QuoteMeta("SET deleted_at = CURRENT_TIMESTAMP")).
+					WithArgs("invoice-1").
+					WillReturnResult(sqlmock.NewResult(0, tc.rowsAffected))
+			}
+
+			err := handler.DeleteInvoice("invoice-1")
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestDBHandler_RestoreInvoice(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := map[string]struct {
+		rows          *sqlmock.Rows
+		expectedError error
+	}{
+		"deleted invoice is restored": {
+			rows: sqlmock.NewRows(invoiceColumns).
+				AddRow("invoice-1", "INV-001", now, "outcome", nil, "category-1", 1000.0, "img1.png", nil, "CRC", false, now, now, nil),
+		},
+		"invoice that is not deleted": {
+			rows:          sqlmock.NewRows(invoiceColumns),
+			expectedError: sql.ErrNoRows,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler, mock := setupTestDBHandler(t)
+
+			mock.ExpectQuery(regexp.QuoteMeta("SET deleted_at = NULL")).
+				WithArgs("invoice-1").
+				WillReturnRows(tc.rows)
+
+			invoice, err := handler.RestoreInvoice("invoice-1")
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.Nil(t, invoice)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "invoice-1", invoice.ID)
+			assert.Nil(t, invoice.DeletedAt)
+		})
+	}
+}
+
+func TestDBHandler_CreateInventoryExistenceRounding(t *testing.T) {
+	req := models.CreateExistenceRequest{
+		IngredientID:    "22222222-2222-2222-2222-222222222222",
+		InvoiceDetailID: "33333333-3333-3333-3333-333333333333",
+		UnitsPurchased:  2,
+		UnitType:        "Units",
+		CostPerUnit:     1234,
+	}
+
+	testCases := map[string]struct {
+		strategy           pricing.RoundingStrategy
+		expectedFinalPrice float64
+	}{
+		"default rounds up to 100": {
+			expectedFinalPrice: 1300,
+		},
+		"ceil to 100": {
+			strategy:           pricing.CeilTo100,
+			expectedFinalPrice: 1300,
+		},
+		"round to nearest 10": {
+			strategy:           pricing.RoundToNearest10,
+			expectedFinalPrice: 1230,
+		},
+		"no rounding": {
+			strategy:           pricing.NoRounding,
+			expectedFinalPrice: 1234,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler, mock := setupTestDBHandler(t)
+			if tc.strategy != nil {
+				handler.SetRoundingStrategy(tc.strategy)
+			}
+
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta("INSERT INTO existences")).
+				WithArgs(req.IngredientID, req.InvoiceDetailID, req.UnitsPurchased, req.UnitType, req.CostPerUnit,
+					req.ExpirationDate, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 1234.0, tc.expectedFinalPrice).
+				WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectCommit()
+
+			tx, err := handler.db.Begin()
+			require.NoError(t, err)
+			require.NoError(t, handler.CreateInventoryExistence(tx, req))
+			require.NoError(t, tx.Commit())
+		})
+	}
+}
+
+func TestDBHandler_CreateInventoryExistenceTaxExempt(t *testing.T) {
+	testCases := map[string]struct {
+		taxExempt          bool
+		expectedIva        float64
+		expectedIvaAmount  float64
+		expectedService    float64
+		expectedServiceAmt float64
+		expectedCalculated float64
+		expectedFinalPrice float64
+	}{
+		"taxed purchase": {
+			// 1000 cost + 30% margin = 1300; 13% IVA (169) and 10% service (130)
+			expectedIva:        13.0,
+			expectedIvaAmount:  169.0,
+			expectedService:    10.0,
+			expectedServiceAmt: 130.0,
+			expectedCalculated: 1599.0,
+			expectedFinalPrice: 1600.0,
+		},
+		"exempt purchase skips iva and service tax": {
+			taxExempt:          true,
+			expectedCalculated: 1300.0,
+			expectedFinalPrice: 1300.0,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler, mock := setupTestDBHandler(t)
+			req := models.CreateExistenceRequest{
+				IngredientID:           "22222222-2222-2222-2222-222222222222",
+				InvoiceDetailID:        "33333333-3333-3333-3333-333333333333",
+				UnitsPurchased:         2,
+				UnitType:               "Units",
+				CostPerUnit:            1000,
+				IncomeMarginPercentage: 30,
+				IvaPercentage:          13,
+				ServiceTaxPercentage:   10,
+				TaxExempt:              tc.taxExempt,
+			}
+
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta("INSERT INTO existences")).
+				WithArgs(req.IngredientID, req.InvoiceDetailID, req.UnitsPurchased, req.UnitType, req.CostPerUnit, req.ExpirationDate,
+					30.0, 300.0, tc.expectedIva, tc.expectedIvaAmount, tc.expectedService, tc.expectedServiceAmt,
+					tc.expectedCalculated, tc.expectedFinalPrice).
+				WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectCommit()
+
+			tx, err := handler.db.Begin()
+			require.NoError(t, err)
+			require.NoError(t, handler.CreateInventoryExistence(tx, req))
+			require.NoError(t, tx.Commit())
+		})
+	}
+}
+
+func TestDBHandler_CreateInvoiceTaxExempt(t *testing.T) {
+	handler, mock := setupTestDBHandler(t)
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	ingredientID := "22222222-2222-2222-2222-222222222222"
+	req := models.CreateInvoiceRequest{
+		InvoiceNumber:     "INV-001",
+		TransactionDate:   &now,
+		TransactionType:   "outcome",
+		ExpenseCategoryID: "category-1",
+		ImageURL:          "img.png",
+		Currency:          "CRC",
+		TaxExempt:         true,
+		Items: []models.CreateInvoiceDetailRequest{
+			{IngredientID: &ingredientID, Detail: "Milk", Count: 2, UnitType: "Liters", Price: 1000, Currency: "CRC"},
+		},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO invoice ")).
+		WithArgs(req.InvoiceNumber, now, req.TransactionType, nil, req.ExpenseCategoryID, req.ImageURL, nil, "CRC", true).
+		WillReturnRows(sqlmock.NewRows(invoiceColumns).
+			AddRow("invoice-1", "INV-001", now, "outcome", nil, "category-1", nil, "img.png", nil, "CRC", true, now, now, nil))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM expense_categories")).
+		WithArgs("category-1").
+		WillReturnRows(sqlmock.NewRows([]string{"category_name"}).AddRow("Ingredients"))
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO invoice_details")).
+		WillReturnRows(sqlmock.NewRows(invoiceDetailColumns).
+			AddRow("detail-1", "invoice-1", ingredientID, "Milk", 2.0, "Liters", 1000.0, 2000.0, 2.0, 0.0, nil, "CRC", now, now))
+	// Default 30% margin applies; IVA and service tax are recorded as zero
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO existences")).
+		WithArgs(ingredientID, "detail-1", 2.0, "Liters", 1000.0, nil,
+			30.0, 300.0, 0.0, 0.0, 0.0, 0.0, 1300.0, 1300.0).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("SET total_amount = $2")).
+		WithArgs("invoice-1", 2000.0).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	invoice, err := handler.CreateInvoice(req)
+
+	require.NoError(t, err)
+	assert.True(t, invoice.TaxExempt)
+}
+
+func TestBuildPatchInvoiceQuery(t *testing.T) {
+	notes := "Delivered late"
+	supplierID := "11111111-1111-1111-1111-111111111111"
+	date := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := map[string]struct {
+		req              models.PatchInvoiceRequest
+		expectedSet      string
+		expectedArgs     []interface{}
+		untouchedColumns []string
+	}{
+		"notes only": {
+			req:              models.PatchInvoiceRequest{Notes: &notes},
+			expectedSet:      "SET notes = $2, updated_at = CURRENT_TIMESTAMP WHERE",
+			expectedArgs:     []interface{}{"invoice-1", &notes},
+			untouchedColumns: []string{"supplier_id =", "transaction_date =", "invoice_number =", "image_url ="},
+		},
+		"several fields keep declaration order": {
+			req:              models.PatchInvoiceRequest{Notes: &notes, SupplierID: &supplierID, TransactionDate: &date},
+			expectedSet:      "SET transaction_date = $2, supplier_id = $3, notes = $4, updated_at = CURRENT_TIMESTAMP WHERE",
+			expectedArgs:     []interface{}{"invoice-1", &date, &supplierID, &notes},
+			untouchedColumns: []string{"invoice_number =", "expense_category_id =", "image_url ="},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			query, args := buildPatchInvoiceQuery("invoice-1", tc.req)
+
+			assert.Contains(t, query, tc.expectedSet)
+			assert.Contains(t, query, "WHERE id = $1 AND deleted_at IS NULL RETURNING")
+			assert.Equal(t, tc.expectedArgs, args)
+			for _, column := range tc.untouchedColumns {
+				assert.NotContains(t, query, column)
+			}
+		})
+	}
+}
+
+func TestDBHandler_PatchInvoice(t *testing.T) {
+	notes := "Delivered late"
+	supplierID := "11111111-1111-1111-1111-111111111111"
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := map[string]struct {
+		rows          *sqlmock.Rows
+		expectedError error
+	}{
+		"patches notes and returns the stored invoice": {
+			rows: sqlmock.NewRows(invoiceColumns).
+				AddRow("invoice-1", "INV-001", now, "outcome", supplierID, "category-1", 1000.0, "img1.png", notes, "CRC", false, now, now, nil),
+		},
+		"missing invoice": {
+			rows:          sqlmock.NewRows(invoiceColumns),
+			expectedError: sql.ErrNoRows,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler, mock := setupTestDBHandler(t)
+
+			mock.ExpectQuery(regexp.QuoteMeta("UPDATE invoice SET notes = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $1")).
+				WithArgs("invoice-1", notes).
+				WillReturnRows(tc.rows)
+
+			invoice, err := handler.PatchInvoice("invoice-1", models.PatchInvoiceRequest{Notes: &notes})
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.Nil(t, invoice)
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotNil(t, invoice.Notes)
+			assert.Equal(t, notes, *invoice.Notes)
+			require.NotNil(t, invoice.SupplierID)
+			assert.Equal(t, supplierID, *invoice.SupplierID)
+			assert.Equal(t, now, invoice.TransactionDate)
+		})
+	}
+}
+
+func TestDBHandler_UpdateInvoice_SkipsDeletedInvoice(t *testing.T) {
+	handler, mock := setupTestDBHandler(t)
+	notes := "Delivered late"
+
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE id = $1 AND deleted_at IS NULL")).
+		WithArgs("invoice-1", nil, nil, nil, nil, nil, nil, &notes).
+		WillReturnRows(sqlmock.NewRows(invoiceColumns))
+
+	invoice, err := handler.UpdateInvoice("invoice-1", models.UpdateInvoiceRequest{Notes: &notes})
+
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.Nil(t, invoice)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDBHandler_CreateInvoiceDetail(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := map[string]struct {
+		invoiceRows   *sqlmock.Rows
+		expectedError error
+	}{
+		"active invoice gets the detail": {
+			invoiceRows: sqlmock.NewRows([]string{"id"}).AddRow("invoice-1"),
+		},
+		"deleted or missing invoice": {
+			invoiceRows:   sqlmock.NewRows([]string{"id"}),
+			expectedError: sql.ErrNoRows,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler, mock := setupTestDBHandler(t)
+
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta("WHERE id = $1 AND deleted_at IS NULL\nFOR UPDATE")).
+				WithArgs("invoice-1").
+				WillReturnRows(tc.invoiceRows)
+			if tc.expectedError == nil {
+				mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO invoice_details")).
+					WillReturnRows(sqlmock.NewRows(invoiceDetailColumns).
+						AddRow("detail-1", "invoice-1", nil, "Milk", 2.0, "Liters", 3.0, 6.0, 2.0, 0.0, nil, "USD", now, now))
+				mock.ExpectQuery(regexp.QuoteMeta("FROM invoice_details")).
+					WithArgs("invoice-1").
+					WillReturnRows(sqlmock.NewRows([]string{"total"}).AddRow(6.0))
+				mock.ExpectExec(regexp.QuoteMeta("SET total_amount = $2")).
+					WithArgs("invoice-1", 6.0).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			} else {
+				mock.ExpectRollback()
+			}
+
+			detail, err := handler.CreateInvoiceDetail(models.CreateInvoiceDetailRequest{
+				InvoiceID: "invoice-1",
+				Detail:    "Milk",
+				Count:     2,
+				UnitType:  "Liters",
+				Price:     3,
+				Currency:  "USD",
+			})
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.Nil(t, detail)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, "detail-1", detail.ID)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDBHandler_ReconcileInvoiceTotals(t *testing.T) {
+	testCases := map[string]struct {
+		storedTotal       interface{}
+		detailsTotal      float64
+		expectCorrection  bool
+		expectedCorrected int
+	}{
+		"drifted invoice is corrected": {
+			storedTotal:       1500.0,
+			detailsTotal:      1250.5,
+			expectCorrection:  true,
+			expectedCorrected: 1,
+		},
+		"invoice without stored total is corrected": {
+			storedTotal:       nil,
+			detailsTotal:      300.0,
+			expectCorrection:  true,
+			expectedCorrected: 1,
+		},
+		"consistent invoice is untouched": {
+			storedTotal:  1250.5,
+			detailsTotal: 1250.5,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler, mock := setupTestDBHandler(t)
+
+			mock.ExpectQuery(regexp.QuoteMeta("SELECT id\nFROM invoice")).
+				WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("invoice-1"))
+
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE")).
+				WithArgs("invoice-1").
+				WillReturnRows(sqlmock.NewRows([]string{"total_amount"}).AddRow(tc.storedTotal))
+			mock.ExpectQuery(regexp.QuoteMeta("FROM invoice_details")).
+				WithArgs("invoice-1").
+				WillReturnRows(sqlmock.NewRows([]string{"total_amount"}).AddRow(tc.detailsTotal))
+			if tc.expectCorrection {
+				mock.ExpectExec(regexp.QuoteMeta("SET total_amount = $2")).
+					WithArgs("invoice-1", tc.detailsTotal).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			} else {
+				mock.ExpectRollback()
+			}
+
+			corrected, err := handler.ReconcileInvoiceTotals()
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedCorrected, corrected)
+		})
+	}
+}
+
+func TestDBHandler_ReconcileInvoiceTotalsContinuesAfterFailure(t *testing.T) {
+	handler, mock := setupTestDBHandler(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id\nFROM invoice")).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("invoice-1").AddRow("invoice-2"))
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE")).
+		WithArgs("invoice-1").
+		WillReturnError(fmt.Errorf("lock timeout"))
+	mock.ExpectRollback()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE")).
+		WithArgs("invoice-2").
+		WillReturnRows(sqlmock.NewRows([]string{"total_amount"}).AddRow(10.0))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM invoice_details")).
+		WithArgs("invoice-2").
+		WillReturnRows(sqlmock.NewRows([]string{"total_amount"}).AddRow(20.0))
+	mock.ExpectExec(regexp.QuoteMeta("SET total_amount = $2")).
+		WithArgs("invoice-2", 20.0).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	corrected, err := handler.ReconcileInvoiceTotals()
+
+	assert.Error(t, err)
+	assert.Equal(t, 1, corrected)
+}
+
+func TestDBHandler_LoadPricingDefaults(t *testing.T) {
+	configColumns := []string{"config_key", "config_value"}
+
+	testCases := map[string]struct {
+		rows          *sqlmock.Rows
+		queryErr      error
+		expected      models.ExistencePricingDefaults
+		expectedError bool
+	}{
+		"configured values replace the defaults": {
+			rows: sqlmock.NewRows(configColumns).
+				AddRow("income_margin_percentage", "25").
+				AddRow("iva_percentage", "4").
+				AddRow("service_tax_percentage", "0"),
+			expected: models.ExistencePricingDefaults{IncomeMarginPercentage: 25, IvaPercentage: 4, ServiceTaxPercentage: 0},
+		},
+		"missing rows keep the defaults": {
+			rows: sqlmock.NewRows(configColumns).
+				AddRow("income_margin_percentage", "35.5"),
+			expected: models.ExistencePricingDefaults{IncomeMarginPercentage: 35.5, IvaPercentage: 13, ServiceTaxPercentage: 10},
+		},
+		"invalid values keep the defaults": {
+			rows: sqlmock.NewRows(configColumns).
+				AddRow("income_margin_percentage", "lots").
+				AddRow("iva_percentage", "150"),
+			expected: models.DefaultExistencePricing(),
+		},
+		"query error keeps the defaults": {
+			queryErr:      fmt.Errorf("relation \"system_configuration\" does not exist"),
+			expected:      models.DefaultExistencePricing(),
+			expectedError: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler, mock := setupTestDBHandler(t)
+
+			expectation := mock.ExpectQuery(regexp.QuoteMeta("FROM system_configuration"))
+			if tc.queryErr != nil {
+				expectation.WillReturnError(tc.queryErr)
+			} else {
+				expectation.WillReturnRows(tc.rows)
+			}
+
+			err := handler.LoadPricingDefaults()
+
+			if tc.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expected, handler.pricing)
+		})
+	}
+}
+
+func TestDBHandler_CreateInvoicePartiallyReceived(t *testing.T) {
+	handler, mock := setupTestDBHandler(t)
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	ingredientID := "22222222-2222-2222-2222-222222222222"
+	received := 4.0
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO invoice ")).
+		WillReturnRows(sqlmock.NewRows(invoiceColumns).
+			AddRow("invoice-1", "INV-001", now, "outcome", nil, "category-1", nil, "img.png", nil, "CRC", false, now, now, nil))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM expense_categories")).
+		WithArgs("category-1").
+		WillReturnRows(sqlmock.NewRows([]string{"category_name"}).AddRow("Ingredients"))
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO invoice_details")).
+		WithArgs("invoice-1", ingredientID, "Milk", 10.0, "Liters", 1000.0, nil, "CRC", received).
+		WillReturnRows(sqlmock.NewRows(invoiceDetailColumns).
+			AddRow("detail-1", "invoice-1", ingredientID, "Milk", 10.0, "Liters", 1000.0, 10000.0, 4.0, 6.0, nil, "CRC", now, now))
+	// Only the 4 received liters are stocked; the invoice is still owed for all 10
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO existences")).
+		WithArgs(ingredientID, "detail-1", 4.0, "Liters", 1000.0, nil,
+			30.0, 300.0, 13.0, 169.0, 10.0, 130.0, 1599.0, 1600.0).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("SET total_amount = $2")).
+		WithArgs("invoice-1", 10000.0).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	_, err := handler.CreateInvoice(models.CreateInvoiceRequest{
+		InvoiceNumber:     "INV-001",
+		TransactionDate:   &now,
+		TransactionType:   "outcome",
+		ExpenseCategoryID: "category-1",
+		ImageURL:          "img.png",
+		Currency:          "CRC",
+		Items: []models.CreateInvoiceDetailRequest{
+			{IngredientID: &ingredientID, Detail: "Milk", Count: 10, UnitType: "Liters", Price: 1000, Currency: "CRC", ReceivedCount: &received},
+		},
+	})
+
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDBHandler_RecordInvoiceDetailReceipt(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	ingredientID := "22222222-2222-2222-2222-222222222222"
+	lockColumns := []string{"outstanding_count", "tax_exempt", "category_name"}
+
+	testCases := map[string]struct {
+		count           float64
+		outstanding     float64
+		category        string
+		missing         bool
+		expectExistence bool
+		expectedErr     error
+	}{
+		"partial receipt stocks the received units": {
+			count: 4, outstanding: 6, category: "Ingredients", expectExistence: true,
+		},
+		"receipt on a non-inventory invoice only updates the counts": {
+			count: 4, outstanding: 6, category: "Supplies",
+		},
+		"receipt above the outstanding count is rejected": {
+			count: 7, outstanding: 6, category: "Ingredients", expectedErr: models.ErrReceiptExceedsOutstanding,
+		},
+		"unknown detail": {
+			count: 4, missing: true, expectedErr: sql.ErrNoRows,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler, mock := setupTestDBHandler(t)
+
+			mock.ExpectBegin()
+			lock := mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE OF d")).WithArgs("detail-1", "invoice-1")
+			if tc.missing {
+				lock.WillReturnError(sql.ErrNoRows)
+			} else {
+				lock.WillReturnRows(sqlmock.NewRows(lockColumns).AddRow(tc.outstanding, false, tc.category))
+			}
+			if tc.expectedErr == nil {
+				mock.ExpectQuery(regexp.QuoteMeta("SET received_count = received_count + $2")).
+					WithArgs("detail-1", tc.count).
+					WillReturnRows(sqlmock.NewRows(invoiceDetailColumns).
+						AddRow("detail-1", "invoice-1", ingredientID, "Milk", 10.0, "Liters", 1000.0, 10000.0,
+							10-tc.outstanding+tc.count, tc.outstanding-tc.count, nil, "CRC", now, now))
+				if tc.expectExistence {
+					mock.ExpectExec(regexp.QuoteMeta("INSERT INTO existences")).
+						WithArgs(ingredientID, "detail-1", tc.count, "Liters", 1000.0, nil,
+							30.0, 300.0, 13.0, 169.0, 10.0, 130.0, 1599.0, 1600.0).
+						WillReturnResult(sqlmock.NewResult(1, 1))
+				}
+				mock.ExpectCommit()
+			} else {
+				mock.ExpectRollback()
+			}
+
+			detail, err := handler.RecordInvoiceDetailReceipt("invoice-1", "detail-1", models.RecordReceiptRequest{Count: tc.count})
+
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				assert.Nil(t, detail)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, 8.0, detail.ReceivedCount)
+				assert.Equal(t, 2.0, detail.OutstandingCount)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDBHandler_CreateInvoiceUsesConfiguredPricing(t *testing.T) {
+	handler, mock := setupTestDBHandler(t)
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	ingredientID := "22222222-2222-2222-2222-222222222222"
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM system_configuration")).
+		WillReturnRows(sqlmock.NewRows([]string{"config_key", "config_value"}).
+			AddRow("income_margin_percentage", "25").
+			AddRow("service_tax_percentage", "5"))
+	require.NoError(t, handler.LoadPricingDefaults())
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO invoice ")).
+		WillReturnRows(sqlmock.NewRows(invoiceColumns).
+			AddRow("invoice-1", "INV-001", now, "outcome", nil, "category-1", nil, "img.png", nil, "CRC", false, now, now, nil))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM expense_categories")).
+		WithArgs("category-1").
+		WillReturnRows(sqlmock.NewRows([]string{"category_name"}).AddRow("Ingredients"))
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO invoice_details")).
+		WillReturnRows(sqlmock.NewRows(invoiceDetailColumns).
+			AddRow("detail-1", "invoice-1", ingredientID, "Milk", 2.0, "Liters", 1000.0, 2000.0, 2.0, 0.0, nil, "CRC", now, now))
+	// 1000 cost + 25% margin = 1250; IVA keeps its 13% default (162.5); service 5% (62.5)
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO existences")).
+		WithArgs(ingredientID, "detail-1", 2.0, "Liters", 1000.0, nil,
+			25.0, 250.0, 13.0, 162.5, 5.0, 62.5, 1475.0, 1500.0).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("SET total_amount = $2")).
+		WithArgs("invoice-1", 2000.0).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	_, err := handler.CreateInvoice(models.CreateInvoiceRequest{
+		InvoiceNumber:     "INV-001",
+		TransactionDate:   &now,
+		TransactionType:   "outcome",
+		ExpenseCategoryID: "category-1",
+		ImageURL:          "img.png",
+		Currency:          "CRC",
+		Items: []models.CreateInvoiceDetailRequest{
+			{IngredientID: &ingredientID, Detail: "Milk", Count: 2, UnitType: "Liters", Price: 1000, Currency: "CRC"},
+		},
+	})
+
+	require.NoError(t, err)
+}
+
+func TestDBHandler_CreateInvoiceDuplicateNumber(t *testing.T) {
+	testCases := map[string]struct {
+		insertErr   error
+		expectedErr error
+	}{
+		"unique violation maps to duplicate invoice number": {
+			insertErr:   &pq.Error{Code: "23505", Constraint: "invoice_invoice_number_key"},
+			expectedErr: models.ErrDuplicateInvoiceNumber,
+		},
+		"other errors are returned unchanged": {
+			insertErr: fmt.Errorf("connection refused"),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler, mock := setupTestDBHandler(t)
+
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO invoice ")).WillReturnError(tc.insertErr)
+			mock.ExpectRollback()
+
+			invoice, err := handler.CreateInvoice(models.CreateInvoiceRequest{
+				InvoiceNumber:     "INV-001",
+				TransactionType:   "outcome",
+				ExpenseCategoryID: "category-1",
+				ImageURL:          "img.png",
+				Currency:          "CRC",
+			})
+
+			assert.Nil(t, invoice)
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+			} else {
+				assert.Equal(t, tc.insertErr, err)
+			}
+		})
+	}
+}
+
+func TestDBHandler_CreateInvoiceSumsDetailTotalsExactly(t *testing.T) {
+	handler, mock := setupTestDBHandler(t)
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	var items []models.CreateInvoiceDetailRequest
+	floatTotal := 0.0
+	for i := 0; i < 10; i++ {
+		items = append(items, models.CreateInvoiceDetailRequest{Detail: "Napkins", Count: 1, UnitType: "Units", Price: 0.1, Currency: "USD"})
+		floatTotal += 0.1
+	}
+	// Ten 0.10 lines summed as float64 miss 1.00; the stored total must not
+	require.NotEqual(t, 1.0, floatTotal)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO invoice ")).
+		WillReturnRows(sqlmock.NewRows(invoiceColumns).
+			AddRow("invoice-1", "INV-001", now, "outcome", nil, "category-1", nil, "img.png", nil, "USD", false, now, now, nil))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM expense_categories")).
+		WithArgs("category-1").
+		WillReturnRows(sqlmock.NewRows([]string{"category_name"}).AddRow("Supplies"))
+	for i := range items {
+		mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO invoice_details")).
+			WillReturnRows(sqlmock.NewRows(invoiceDetailColumns).
+				AddRow(fmt.Sprintf("detail-%d", i), "invoice-1", nil, "Napkins", 1.0, "Units", 0.1, 0.1, 1.0, 0.0, nil, "USD", now, now))
+	}
+	mock.ExpectExec(regexp.QuoteMeta("SET total_amount = $2")).
+		WithArgs("invoice-1", 1.0).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	invoice, err := handler.CreateInvoice(models.CreateInvoiceRequest{
+		InvoiceNumber:     "INV-001",
+		TransactionDate:   &now,
+		TransactionType:   "outcome",
+		ExpenseCategoryID: "category-1",
+		ImageURL:          "img.png",
+		Currency:          "USD",
+		Items:             items,
+	})
+
+	require.NoError(t, err)
+	require.NotNil(t, invoice.TotalAmount)
+	assert.Equal(t, 1.0, *invoice.TotalAmount)
+}
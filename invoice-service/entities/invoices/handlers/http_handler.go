@@ -1,12 +1,18 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
 	"invoice-service/entities/invoices/models"
+	"invoice-service/events"
+	"shared/eventbus"
+	"shared/httpx"
 
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
@@ -17,15 +23,20 @@ type DBHandlerInterface interface {
 	CreateInvoice(req models.CreateInvoiceRequest) (*models.Invoice, error)
 	GetInvoiceByID(id string) (*models.Invoice, error)
 	GetInvoiceByNumber(number string) (*models.Invoice, error)
-	ListInvoices() ([]models.Invoice, error)
+	ListInvoices(ctx context.Context, includeDeleted bool) ([]models.Invoice, error)
+	ListInvoicesBySupplier(ctx context.Context, supplierID string, includeDeleted bool) ([]models.Invoice, error)
+	GetSupplierReport(ctx context.Context, supplierID string, from, to, asOf time.Time) (*models.SupplierReport, error)
 	UpdateInvoice(id string, req models.UpdateInvoiceRequest) (*models.Invoice, error)
+	PatchInvoice(id string, req models.PatchInvoiceRequest) (*models.Invoice, error)
 	DeleteInvoice(id string) error
+	RestoreInvoice(id string) (*models.Invoice, error)
 	//pvillalobos - delete invoice details features if needed.
 	CreateInvoiceDetail(req models.CreateInvoiceDetailRequest) (*models.InvoiceDetail, error)
 	GetInvoiceDetailByID(id string) (*models.InvoiceDetail, error)
 	GetInvoiceDetailsByInvoiceID(invoiceID string) ([]models.InvoiceDetail, error)
-	ListInvoiceDetails() ([]models.InvoiceDetail, error)
+	ListInvoiceDetails(ctx context.Context) ([]models.InvoiceDetail, error)
 	UpdateInvoiceDetail(id string, req models.UpdateInvoiceDetailRequest) (*models.InvoiceDetail, error)
+	RecordInvoiceDetailReceipt(invoiceID, detailID string, req models.RecordReceiptRequest) (*models.InvoiceDetail, error)
 	DeleteInvoiceDetail(id string) error
 }
 
@@ -34,32 +45,55 @@ var _ DBHandlerInterface = (*DBHandler)(nil)
 
 // HttpHandler handles HTTP requests for invoice operations
 type HttpHandler struct {
-	dbHandler DBHandlerInterface
-	logger    *logrus.Logger
+	dbHandler  DBHandlerInterface
+	logger     *logrus.Logger
+	currencies models.CurrencyPolicy
+	events     *eventbus.Bus
 }
 
 // NewHttpHandler creates a new HTTP handler
 func NewHttpHandler(dbHandler *DBHandler, logger *logrus.Logger) *HttpHandler {
 	return &HttpHandler{
-		dbHandler: dbHandler,
-		logger:    logger,
+		dbHandler:  dbHandler,
+		logger:     logger,
+		currencies: models.DefaultCurrencyPolicy(),
 	}
 }
 
 // NewHttpHandlerWithInterface creates a new HTTP handler with interface (for testing)
 func NewHttpHandlerWithInterface(dbHandler DBHandlerInterface, logger *logrus.Logger) *HttpHandler {
 	return &HttpHandler{
-		dbHandler: dbHandler,
-		logger:    logger,
+		dbHandler:  dbHandler,
+		logger:     logger,
+		currencies: models.DefaultCurrencyPolicy(),
 	}
 }
 
+// SetEventBus publishes invoice domain events on the given bus after each committed change
+func (h *HttpHandler) SetEventBus(bus *eventbus.Bus) {
+	h.events = bus
+}
+
+// publish sends an invoice domain event when an event bus is configured
+func (h *HttpHandler) publish(event events.InvoiceEvent) {
+	if h.events == nil {
+		return
+	}
+	event.Timestamp = time.Now()
+	h.events.Publish(event)
+}
+
+// SetCurrencyPolicy overrides the default and supported invoice currencies
+func (h *HttpHandler) SetCurrencyPolicy(policy models.CurrencyPolicy) {
+	h.currencies = policy
+}
+
 // CreateInvoiceWithDetails handles POST /invoices
 func (h *HttpHandler) CreateInvoiceWithDetails(w http.ResponseWriter, r *http.Request) {
 	var req models.CreateInvoiceRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := httpx.DecodeJSONBody(r, &req); err != nil {
 		h.logger.WithError(err).Error("Invalid JSON in create invoice request")
-		h.writeErrorResponse(w, "Invalid JSON format", http.StatusBadRequest)
+		h.writeErrorResponse(w, httpx.DecodeErrorMessage(err, "Invalid JSON format"), http.StatusBadRequest)
 		return
 	}
 
@@ -70,15 +104,25 @@ func (h *HttpHandler) CreateInvoiceWithDetails(w http.ResponseWriter, r *http.Re
 		h.logger.WithField("invoice_number", req.InvoiceNumber).Info("Setting default transaction date to current timestamp")
 	}
 
+	if err := h.currencies.ResolveInvoice(&req); err != nil {
+		h.logger.WithError(err).WithField("invoice_number", req.InvoiceNumber).Warn("Rejected invoice currency")
+		h.writeErrorResponse(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
 	invoice, err := h.dbHandler.CreateInvoice(req)
 	if err != nil {
 		// DBHandler already logged the error, don't duplicate
+		status := http.StatusInternalServerError
+		if errors.Is(err, models.ErrDuplicateInvoiceNumber) {
+			status = http.StatusConflict
+		}
 		response := models.InvoiceResponse{
 			Success: false,
 			Data:    models.Invoice{},
 			Message: "Failed to create invoice: " + err.Error(),
 		}
-		h.writeJSONResponse(w, response, http.StatusInternalServerError)
+		h.writeJSONResponse(w, response, status)
 		return
 	}
 
@@ -87,6 +131,8 @@ func (h *HttpHandler) CreateInvoiceWithDetails(w http.ResponseWriter, r *http.Re
 		Data:    *invoice,
 		Message: "Invoice created successfully",
 	}
+	h.logger.WithFields(httpx.RequestLogFields(r)).WithField("invoice_id", invoice.ID).Info("Invoice created successfully")
+	h.publish(events.InvoiceEvent{Kind: events.InvoiceCreated, InvoiceID: invoice.ID, InvoiceNumber: invoice.InvoiceNumber})
 	h.writeJSONResponse(w, response, http.StatusCreated)
 }
 
@@ -132,6 +178,66 @@ func (h *HttpHandler) GetInvoiceByID(w http.ResponseWriter, r *http.Request) {
 	h.writeJSONResponse(w, response, http.StatusOK)
 }
 
+// GetInvoiceWithDetails handles GET /invoices/{id}/full
+func (h *HttpHandler) GetInvoiceWithDetails(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if id == "" {
+		h.logger.Warn("Missing invoice ID in get full invoice request")
+		h.writeErrorResponse(w, "Invoice ID is required", http.StatusBadRequest)
+		return
+	}
+
+	invoice, err := h.dbHandler.GetInvoiceByID(id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			// This is expected behavior, don't log as error
+			response := models.InvoiceWithDetailsResponse{
+				Success: false,
+				Data:    models.InvoiceWithDetails{},
+				Message: "Invoice not found",
+			}
+			h.writeJSONResponse(w, response, http.StatusNotFound)
+			return
+		}
+
+		// DBHandler already logged the error, don't duplicate
+		response := models.InvoiceWithDetailsResponse{
+			Success: false,
+			Data:    models.InvoiceWithDetails{},
+			Message: "Failed to retrieve invoice: " + err.Error(),
+		}
+		h.writeJSONResponse(w, response, http.StatusInternalServerError)
+		return
+	}
+
+	details, err := h.dbHandler.GetInvoiceDetailsByInvoiceID(id)
+	if err != nil {
+		// DBHandler already logged the error, don't duplicate
+		response := models.InvoiceWithDetailsResponse{
+			Success: false,
+			Data:    models.InvoiceWithDetails{},
+			Message: "Failed to retrieve invoice details: " + err.Error(),
+		}
+		h.writeJSONResponse(w, response, http.StatusInternalServerError)
+		return
+	}
+	if details == nil {
+		details = []models.InvoiceDetail{}
+	}
+
+	response := models.InvoiceWithDetailsResponse{
+		Success: true,
+		Data: models.InvoiceWithDetails{
+			Invoice: *invoice,
+			Details: details,
+		},
+		Message: "Invoice retrieved successfully",
+	}
+	h.writeJSONResponse(w, response, http.StatusOK)
+}
+
 // GetInvoiceByNumber handles GET /invoices/number/{number}
 func (h *HttpHandler) GetInvoiceByNumber(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -174,9 +280,54 @@ func (h *HttpHandler) GetInvoiceByNumber(w http.ResponseWriter, r *http.Request)
 	h.writeJSONResponse(w, response, http.StatusOK)
 }
 
-// ListInvoices handles GET /invoices
+// CheckInvoiceNumberAvailable handles GET /invoices/number/{number}/available
+func (h *HttpHandler) CheckInvoiceNumberAvailable(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	number := vars["number"]
+
+	if number == "" {
+		h.logger.Warn("Missing invoice number in availability request")
+		h.writeErrorResponse(w, "Invoice number is required", http.StatusBadRequest)
+		return
+	}
+
+	available := false
+	if _, err := h.dbHandler.GetInvoiceByNumber(number); err != nil {
+		if err != sql.ErrNoRows {
+			// DBHandler already logged the error, don't duplicate
+			h.writeErrorResponse(w, "Failed to check invoice number: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		available = true
+	}
+
+	response := models.InvoiceNumberAvailabilityResponse{
+		Success: true,
+		Data:    models.InvoiceNumberAvailability{InvoiceNumber: number, Available: available},
+		Message: "Invoice number availability checked",
+	}
+	h.writeJSONResponse(w, response, http.StatusOK)
+}
+
+// ListInvoices handles GET /invoices, optionally filtered by ?supplier_id=.
+// Soft-deleted invoices are only listed with ?include_deleted=true
 func (h *HttpHandler) ListInvoices(w http.ResponseWriter, r *http.Request) {
-	invoices, err := h.dbHandler.ListInvoices()
+	includeDeleted := false
+	if value := r.URL.Query().Get("include_deleted"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			h.writeErrorResponse(w, "include_deleted must be true or false", http.StatusBadRequest)
+			return
+		}
+		includeDeleted = parsed
+	}
+
+	if supplierID := r.URL.Query().Get("supplier_id"); supplierID != "" {
+		h.listSupplierInvoices(w, r, supplierID, includeDeleted)
+		return
+	}
+
+	invoices, err := h.dbHandler.ListInvoices(r.Context(), includeDeleted)
 	if err != nil {
 		// DBHandler already logged the error, don't duplicate
 		response := models.InvoicesListResponse{
@@ -198,6 +349,86 @@ func (h *HttpHandler) ListInvoices(w http.ResponseWriter, r *http.Request) {
 	h.writeJSONResponse(w, response, http.StatusOK)
 }
 
+// listSupplierInvoices writes a supplier's invoices together with the sum of their totals
+func (h *HttpHandler) listSupplierInvoices(w http.ResponseWriter, r *http.Request, supplierID string, includeDeleted bool) {
+	invoices, err := h.dbHandler.ListInvoicesBySupplier(r.Context(), supplierID, includeDeleted)
+	if err != nil {
+		// DBHandler already logged the error, don't duplicate
+		response := models.InvoicesListResponse{
+			Success: false,
+			Data:    []models.Invoice{},
+			Count:   0,
+			Message: "Failed to list supplier invoices: " + err.Error(),
+		}
+		h.writeJSONResponse(w, response, http.StatusInternalServerError)
+		return
+	}
+
+	totalAmount := 0.0
+	for _, invoice := range invoices {
+		if invoice.TotalAmount != nil {
+			totalAmount += *invoice.TotalAmount
+		}
+	}
+
+	response := models.InvoicesListResponse{
+		Success:     true,
+		Data:        invoices,
+		Count:       len(invoices),
+		TotalAmount: &totalAmount,
+		Message:     "Supplier invoices listed successfully",
+	}
+	h.writeJSONResponse(w, response, http.StatusOK)
+}
+
+// GetSupplierReport handles GET /invoices/suppliers/{id}/report?from=&to=, with dates as YYYY-MM-DD.
+// The period defaults to the DefaultSupplierReportPeriod ending today
+func (h *HttpHandler) GetSupplierReport(w http.ResponseWriter, r *http.Request) {
+	supplierID := mux.Vars(r)["id"]
+	if supplierID == "" {
+		h.writeErrorResponse(w, "Supplier ID is required", http.StatusBadRequest)
+		return
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	to := today
+	if value := r.URL.Query().Get("to"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			h.writeErrorResponse(w, "to must be a date in YYYY-MM-DD format", http.StatusBadRequest)
+			return
+		}
+		to = parsed
+	}
+	from := to.Add(-models.DefaultSupplierReportPeriod)
+	if value := r.URL.Query().Get("from"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			h.writeErrorResponse(w, "from must be a date in YYYY-MM-DD format", http.StatusBadRequest)
+			return
+		}
+		from = parsed
+	}
+	if from.After(to) {
+		h.writeErrorResponse(w, "from must not be after to", http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.dbHandler.GetSupplierReport(r.Context(), supplierID, from, to, today)
+	if err != nil {
+		// DBHandler already logged the error, don't duplicate
+		h.writeErrorResponse(w, "Failed to build supplier report: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := models.SupplierReportResponse{
+		Success: true,
+		Data:    *report,
+		Message: "Supplier report retrieved successfully",
+	}
+	h.writeJSONResponse(w, response, http.StatusOK)
+}
+
 // UpdateInvoice handles PUT /invoices/{id}
 func (h *HttpHandler) UpdateInvoice(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -210,9 +441,9 @@ func (h *HttpHandler) UpdateInvoice(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req models.UpdateInvoiceRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := httpx.DecodeJSONBody(r, &req); err != nil {
 		h.logger.WithError(err).Error("Invalid JSON in update invoice request")
-		h.writeErrorResponse(w, "Invalid JSON format", http.StatusBadRequest)
+		h.writeErrorResponse(w, httpx.DecodeErrorMessage(err, "Invalid JSON format"), http.StatusBadRequest)
 		return
 	}
 
@@ -244,10 +475,69 @@ func (h *HttpHandler) UpdateInvoice(w http.ResponseWriter, r *http.Request) {
 		Data:    *invoice,
 		Message: "Invoice updated successfully",
 	}
+	h.logger.WithFields(httpx.RequestLogFields(r)).WithField("invoice_id", invoice.ID).Info("Invoice updated successfully")
+	h.publish(events.InvoiceEvent{Kind: events.InvoiceUpdated, InvoiceID: invoice.ID, InvoiceNumber: invoice.InvoiceNumber})
+	h.writeJSONResponse(w, response, http.StatusOK)
+}
+
+// PatchInvoice handles PATCH /invoices/{id}, changing only the fields present in the body
+func (h *HttpHandler) PatchInvoice(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if id == "" {
+		h.logger.Warn("Missing invoice ID in patch request")
+		h.writeErrorResponse(w, "Invoice ID is required", http.StatusBadRequest)
+		return
+	}
+
+	var req models.PatchInvoiceRequest
+	if err := httpx.DecodeJSONBody(r, &req); err != nil {
+		h.logger.WithError(err).Error("Invalid JSON in patch invoice request")
+		h.writeErrorResponse(w, httpx.DecodeErrorMessage(err, "Invalid JSON format"), http.StatusBadRequest)
+		return
+	}
+
+	if req.IsEmpty() {
+		h.logger.WithField("invoice_id", id).Warn("Empty patch invoice request")
+		h.writeErrorResponse(w, "At least one field must be provided", http.StatusBadRequest)
+		return
+	}
+
+	invoice, err := h.dbHandler.PatchInvoice(id, req)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			// This is expected behavior, don't log as error
+			response := models.InvoiceResponse{
+				Success: false,
+				Data:    models.Invoice{},
+				Message: "Invoice not found",
+			}
+			h.writeJSONResponse(w, response, http.StatusNotFound)
+			return
+		}
+
+		// DBHandler already logged the error, don't duplicate
+		response := models.InvoiceResponse{
+			Success: false,
+			Data:    models.Invoice{},
+			Message: "Failed to update invoice: " + err.Error(),
+		}
+		h.writeJSONResponse(w, response, http.StatusInternalServerError)
+		return
+	}
+
+	response := models.InvoiceResponse{
+		Success: true,
+		Data:    *invoice,
+		Message: "Invoice updated successfully",
+	}
+	h.logger.WithFields(httpx.RequestLogFields(r)).WithField("invoice_id", invoice.ID).Info("Invoice updated successfully")
+	h.publish(events.InvoiceEvent{Kind: events.InvoiceUpdated, InvoiceID: invoice.ID, InvoiceNumber: invoice.InvoiceNumber})
 	h.writeJSONResponse(w, response, http.StatusOK)
 }
 
-// DeleteInvoice handles DELETE /invoices/{id}
+// DeleteInvoice handles DELETE /invoices/{id} by soft-deleting the invoice
 func (h *HttpHandler) DeleteInvoice(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
@@ -269,6 +559,14 @@ func (h *HttpHandler) DeleteInvoice(w http.ResponseWriter, r *http.Request) {
 			h.writeJSONResponse(w, response, http.StatusNotFound)
 			return
 		}
+		if errors.Is(err, models.ErrInvoiceExistencesConsumed) {
+			response := models.InvoiceDeleteResponse{
+				Success: false,
+				Message: "Invoice cannot be deleted: " + err.Error(),
+			}
+			h.writeJSONResponse(w, response, http.StatusConflict)
+			return
+		}
 
 		// DBHandler already logged the error, don't duplicate
 		response := models.InvoiceDeleteResponse{
@@ -283,6 +581,52 @@ func (h *HttpHandler) DeleteInvoice(w http.ResponseWriter, r *http.Request) {
 		Success: true,
 		Message: "Invoice deleted successfully",
 	}
+	h.logger.WithFields(httpx.RequestLogFields(r)).WithField("invoice_id", id).Info("Invoice deleted successfully")
+	h.publish(events.InvoiceEvent{Kind: events.InvoiceDeleted, InvoiceID: id})
+	h.writeJSONResponse(w, response, http.StatusOK)
+}
+
+// RestoreInvoice handles POST /invoices/{id}/restore
+func (h *HttpHandler) RestoreInvoice(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if id == "" {
+		h.logger.Warn("Missing invoice ID in restore request")
+		h.writeErrorResponse(w, "Invoice ID is required", http.StatusBadRequest)
+		return
+	}
+
+	invoice, err := h.dbHandler.RestoreInvoice(id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			// This is expected behavior, don't log as error
+			response := models.InvoiceResponse{
+				Success: false,
+				Data:    models.Invoice{},
+				Message: "Deleted invoice not found",
+			}
+			h.writeJSONResponse(w, response, http.StatusNotFound)
+			return
+		}
+
+		// DBHandler already logged the error, don't duplicate
+		response := models.InvoiceResponse{
+			Success: false,
+			Data:    models.Invoice{},
+			Message: "Failed to restore invoice: " + err.Error(),
+		}
+		h.writeJSONResponse(w, response, http.StatusInternalServerError)
+		return
+	}
+
+	response := models.InvoiceResponse{
+		Success: true,
+		Data:    *invoice,
+		Message: "Invoice restored successfully",
+	}
+	h.logger.WithFields(httpx.RequestLogFields(r)).WithField("invoice_id", invoice.ID).Info("Invoice restored successfully")
+	h.publish(events.InvoiceEvent{Kind: events.InvoiceRestored, InvoiceID: invoice.ID, InvoiceNumber: invoice.InvoiceNumber})
 	h.writeJSONResponse(w, response, http.StatusOK)
 }
 
@@ -298,17 +642,53 @@ func (h *HttpHandler) CreateInvoiceDetail(w http.ResponseWriter, r *http.Request
 	}
 
 	var req models.CreateInvoiceDetailRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := httpx.DecodeJSONBody(r, &req); err != nil {
 		h.logger.WithError(err).Error("Invalid JSON in create invoice detail request")
-		h.writeErrorResponse(w, "Invalid JSON format", http.StatusBadRequest)
+		h.writeErrorResponse(w, httpx.DecodeErrorMessage(err, "Invalid JSON format"), http.StatusBadRequest)
 		return
 	}
 
 	// Set the invoice ID from the URL
 	req.InvoiceID = invoiceID
 
+	if errs := req.Validate(); len(errs) > 0 {
+		h.logger.WithField("invoice_id", invoiceID).WithField("errors", errs).Warn("Rejected invalid invoice detail")
+		h.writeJSONResponse(w, models.ValidationErrorResponse{
+			Success: false,
+			Error:   "Invalid invoice detail",
+			Errors:  errs,
+		}, http.StatusBadRequest)
+		return
+	}
+
+	// Details must share the invoice's currency so its total never mixes currencies
+	invoice, err := h.dbHandler.GetInvoiceByID(invoiceID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			h.writeErrorResponse(w, "Invoice not found", http.StatusNotFound)
+			return
+		}
+		h.writeErrorResponse(w, "Failed to retrieve invoice: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if invoice.DeletedAt != nil {
+		h.writeErrorResponse(w, "Invoice not found", http.StatusNotFound)
+		return
+	}
+
+	if err := h.currencies.ResolveDetail(&req, invoice.Currency); err != nil {
+		h.logger.WithError(err).WithField("invoice_id", invoiceID).Warn("Rejected invoice detail currency")
+		h.writeErrorResponse(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
 	detail, err := h.dbHandler.CreateInvoiceDetail(req)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			// Deleted after it was read above
+			h.writeErrorResponse(w, "Invoice not found", http.StatusNotFound)
+			return
+		}
 		// DBHandler already logged the error, don't duplicate
 		response := models.InvoiceDetailResponse{
 			Success: false,
@@ -324,9 +704,72 @@ func (h *HttpHandler) CreateInvoiceDetail(w http.ResponseWriter, r *http.Request
 		Data:    *detail,
 		Message: "Invoice detail created successfully",
 	}
+	h.logger.WithFields(httpx.RequestLogFields(r)).WithFields(logrus.Fields{"invoice_id": invoiceID, "invoice_detail_id": detail.ID}).Info("Invoice detail created successfully")
+	h.publish(events.InvoiceEvent{Kind: events.InvoiceDetailCreated, InvoiceID: invoiceID, InvoiceNumber: invoice.InvoiceNumber, DetailID: detail.ID})
 	h.writeJSONResponse(w, response, http.StatusCreated)
 }
 
+// RecordInvoiceDetailReceipt handles POST /invoices/{id}/details/{detailId}/receipts, recording units
+// delivered after the invoice and stocking them
+func (h *HttpHandler) RecordInvoiceDetailReceipt(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	invoiceID := vars["id"]
+	detailID := vars["detailId"]
+
+	if invoiceID == "" || detailID == "" {
+		h.logger.Warn("Missing invoice or detail ID in receipt request")
+		h.writeErrorResponse(w, "Invoice ID and detail ID are required", http.StatusBadRequest)
+		return
+	}
+
+	var req models.RecordReceiptRequest
+	if err := httpx.DecodeJSONBody(r, &req); err != nil {
+		h.logger.WithError(err).Error("Invalid JSON in invoice detail receipt request")
+		h.writeErrorResponse(w, httpx.DecodeErrorMessage(err, "Invalid JSON format"), http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(); len(errs) > 0 {
+		h.logger.WithField("invoice_detail_id", detailID).WithField("errors", errs).Warn("Rejected invalid invoice detail receipt")
+		h.writeJSONResponse(w, models.ValidationErrorResponse{
+			Success: false,
+			Error:   "Invalid receipt",
+			Errors:  errs,
+		}, http.StatusBadRequest)
+		return
+	}
+
+	detail, err := h.dbHandler.RecordInvoiceDetailReceipt(invoiceID, detailID, req)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			h.writeErrorResponse(w, "Invoice detail not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, models.ErrReceiptExceedsOutstanding) {
+			h.writeErrorResponse(w, "Receipt rejected: "+err.Error(), http.StatusConflict)
+			return
+		}
+
+		// DBHandler already logged the error, don't duplicate
+		response := models.InvoiceDetailResponse{
+			Success: false,
+			Data:    models.InvoiceDetail{},
+			Message: "Failed to record receipt: " + err.Error(),
+		}
+		h.writeJSONResponse(w, response, http.StatusInternalServerError)
+		return
+	}
+
+	response := models.InvoiceDetailResponse{
+		Success: true,
+		Data:    *detail,
+		Message: "Receipt recorded successfully",
+	}
+	h.logger.WithFields(httpx.RequestLogFields(r)).WithFields(logrus.Fields{"invoice_id": invoiceID, "invoice_detail_id": detail.ID}).Info("Invoice detail receipt recorded successfully")
+	h.publish(events.InvoiceEvent{Kind: events.InvoiceDetailReceived, InvoiceID: invoiceID, DetailID: detail.ID})
+	h.writeJSONResponse(w, response, http.StatusOK)
+}
+
 // GetInvoiceDetailsByInvoiceID handles GET /invoices/{id}/details
 func (h *HttpHandler) GetInvoiceDetailsByInvoiceID(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -362,7 +805,7 @@ func (h *HttpHandler) GetInvoiceDetailsByInvoiceID(w http.ResponseWriter, r *htt
 
 // ListInvoiceDetails handles GET /invoice-details
 func (h *HttpHandler) ListInvoiceDetails(w http.ResponseWriter, r *http.Request) {
-	details, err := h.dbHandler.ListInvoiceDetails()
+	details, err := h.dbHandler.ListInvoiceDetails(r.Context())
 	if err != nil {
 		// DBHandler already logged the error, don't duplicate
 		response := models.InvoiceDetailsListResponse{
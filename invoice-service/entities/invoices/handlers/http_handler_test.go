@@ -0,0 +1,1087 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"invoice-service/entities/invoices/models"
+	"invoice-service/events"
+	"shared/eventbus"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockInvoiceDB overrides only the methods under test; calling anything else panics on the nil embedded interface
+type mockInvoiceDB struct {
+	DBHandlerInterface
+	listInvoicesFunc           func(includeDeleted bool) ([]models.Invoice, error)
+	listInvoicesBySupplierFunc func(supplierID string, includeDeleted bool) ([]models.Invoice, error)
+	createInvoiceFunc          func(req models.CreateInvoiceRequest) (*models.Invoice, error)
+	getInvoiceByIDFunc         func(id string) (*models.Invoice, error)
+	getInvoiceByNumberFunc     func(number string) (*models.Invoice, error)
+	createInvoiceDetailFunc    func(req models.CreateInvoiceDetailRequest) (*models.InvoiceDetail, error)
+	getInvoiceDetailsFunc      func(invoiceID string) ([]models.InvoiceDetail, error)
+	patchInvoiceFunc           func(id string, req models.PatchInvoiceRequest) (*models.Invoice, error)
+	deleteInvoiceFunc          func(id string) error
+	restoreInvoiceFunc         func(id string) (*models.Invoice, error)
+	recordReceiptFunc          func(invoiceID, detailID string, req models.RecordReceiptRequest) (*models.InvoiceDetail, error)
+	getSupplierReportFunc      func(supplierID string, from, to time.Time) (*models.SupplierReport, error)
+}
+
+func (m *mockInvoiceDB) CreateInvoice(req models.CreateInvoiceRequest) (*models.Invoice, error) {
+	return m.createInvoiceFunc(req)
+}
+
+func (m *mockInvoiceDB) GetInvoiceByID(id string) (*models.Invoice, error) {
+	return m.getInvoiceByIDFunc(id)
+}
+
+func (m *mockInvoiceDB) GetInvoiceByNumber(number string) (*models.Invoice, error) {
+	return m.getInvoiceByNumberFunc(number)
+}
+
+func (m *mockInvoiceDB) CreateInvoiceDetail(req models.CreateInvoiceDetailRequest) (*models.InvoiceDetail, error) {
+	return m.createInvoiceDetailFunc(req)
+}
+
+func (m *mockInvoiceDB) GetInvoiceDetailsByInvoiceID(invoiceID string) ([]models.InvoiceDetail, error) {
+	return m.getInvoiceDetailsFunc(invoiceID)
+}
+
+func (m *mockInvoiceDB) PatchInvoice(id string, req models.PatchInvoiceRequest) (*models.Invoice, error) {
+	return m.patchInvoiceFunc(id, req)
+}
+
+func (m *mockInvoiceDB) ListInvoices(ctx context.Context, includeDeleted bool) ([]models.Invoice, error) {
+	return m.listInvoicesFunc(includeDeleted)
+}
+
+func (m *mockInvoiceDB) ListInvoicesBySupplier(ctx context.Context, supplierID string, includeDeleted bool) ([]models.Invoice, error) {
+	return m.listInvoicesBySupplierFunc(supplierID, includeDeleted)
+}
+
+func (m *mockInvoiceDB) DeleteInvoice(id string) error {
+	return m.deleteInvoiceFunc(id)
+}
+
+func (m *mockInvoiceDB) RestoreInvoice(id string) (*models.Invoice, error) {
+	return m.restoreInvoiceFunc(id)
+}
+
+func (m *mockInvoiceDB) RecordInvoiceDetailReceipt(invoiceID, detailID string, req models.RecordReceiptRequest) (*models.InvoiceDetail, error) {
+	return m.recordReceiptFunc(invoiceID, detailID, req)
+}
+
+func (m *mockInvoiceDB) GetSupplierReport(ctx context.Context, supplierID string, from, to, asOf time.Time) (*models.SupplierReport, error) {
+	return m.getSupplierReportFunc(supplierID, from, to)
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
+
+func TestHttpHandler_ListInvoices_FilterBySupplier(t *testing.T) {
+	supplierA := "supplier-a"
+	supplierB := "supplier-b"
+	allInvoices := []models.Invoice{
+		{ID: "invoice-1", SupplierID: &supplierA, TotalAmount: floatPtr(1000)},
+		{ID: "invoice-2", SupplierID: &supplierB, TotalAmount: floatPtr(700)},
+		{ID: "invoice-3", SupplierID: &supplierA, TotalAmount: floatPtr(250.5)},
+		{ID: "invoice-4", SupplierID: &supplierA}, // total not computed yet
+	}
+
+	testCases := map[string]struct {
+		query         string
+		expectedIDs   []string
+		expectedTotal *float64
+	}{
+		"supplier with several invoices": {
+			query:         "?supplier_id=supplier-a",
+			expectedIDs:   []string{"invoice-1", "invoice-3", "invoice-4"},
+			expectedTotal: floatPtr(1250.5),
+		},
+		"supplier with a single invoice": {
+			query:         "?supplier_id=supplier-b",
+			expectedIDs:   []string{"invoice-2"},
+			expectedTotal: floatPtr(700),
+		},
+		"unknown supplier": {
+			query:         "?supplier_id=supplier-c",
+			expectedIDs:   []string{},
+			expectedTotal: floatPtr(0),
+		},
+		"no filter lists everything without a total": {
+			query:       "",
+			expectedIDs: []string{"invoice-1", "invoice-2", "invoice-3", "invoice-4"},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			logger := logrus.New()
+			logger.SetLevel(logrus.FatalLevel)
+
+			mockDB := &mockInvoiceDB{
+				listInvoicesFunc: func(includeDeleted bool) ([]models.Invoice, error) {
+					return allInvoices, nil
+				},
+				listInvoicesBySupplierFunc: func(supplierID string, includeDeleted bool) ([]models.Invoice, error) {
+					filtered := []models.Invoice{}
+					for _, invoice := range allInvoices {
+						if *invoice.SupplierID == supplierID {
+							filtered = append(filtered, invoice)
+						}
+					}
+					return filtered, nil
+				},
+			}
+			handler := NewHttpHandlerWithInterface(mockDB, logger)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/invoices"+tc.query, nil)
+			w := httptest.NewRecorder()
+
+			handler.ListInvoices(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+
+			var response models.InvoicesListResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.True(t, response.Success)
+
+			ids := []string{}
+			for _, invoice := range response.Data {
+				ids = append(ids, invoice.ID)
+			}
+			assert.Equal(t, tc.expectedIDs, ids)
+			assert.Equal(t, len(tc.expectedIDs), response.Count)
+
+			if tc.expectedTotal == nil {
+				assert.Nil(t, response.TotalAmount)
+			} else {
+				require.NotNil(t, response.TotalAmount)
+				assert.InDelta(t, *tc.expectedTotal, *response.TotalAmount, 0.001)
+			}
+		})
+	}
+}
+
+func TestHttpHandler_ListInvoices_SupplierDatabaseError(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	handler := NewHttpHandlerWithInterface(&mockInvoiceDB{
+		listInvoicesBySupplierFunc: func(supplierID string, includeDeleted bool) ([]models.Invoice, error) {
+			return nil, fmt.Errorf("connection refused")
+		},
+	}, logger)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/invoices?supplier_id=supplier-a", nil)
+	w := httptest.NewRecorder()
+
+	handler.ListInvoices(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "Failed to list supplier invoices")
+}
+
+func TestHttpHandler_ListInvoices_IncludeDeleted(t *testing.T) {
+	supplierA := "supplier-a"
+	deletedAt := time.Date(2024, 3, 2, 9, 0, 0, 0, time.UTC)
+	allInvoices := []models.Invoice{
+		{ID: "invoice-1", SupplierID: &supplierA, TotalAmount: floatPtr(1000)},
+		{ID: "invoice-2", SupplierID: &supplierA, TotalAmount: floatPtr(700), DeletedAt: &deletedAt},
+	}
+	visible := func(includeDeleted bool, supplierID string) []models.Invoice {
+		filtered := []models.Invoice{}
+		for _, invoice := range allInvoices {
+			if supplierID != "" && *invoice.SupplierID != supplierID {
+				continue
+			}
+			if invoice.DeletedAt != nil && !includeDeleted {
+				continue
+			}
+			filtered = append(filtered, invoice)
+		}
+		return filtered
+	}
+
+	testCases := map[string]struct {
+		query          string
+		expectedStatus int
+		expectedIDs    []string
+	}{
+		"deleted invoices are hidden by default": {
+			query:          "",
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []string{"invoice-1"},
+		},
+		"include_deleted lists deleted invoices": {
+			query:          "?include_deleted=true",
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []string{"invoice-1", "invoice-2"},
+		},
+		"include_deleted false behaves like the default": {
+			query:          "?include_deleted=false",
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []string{"invoice-1"},
+		},
+		"supplier filter hides deleted invoices": {
+			query:          "?supplier_id=supplier-a",
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []string{"invoice-1"},
+		},
+		"supplier filter with include_deleted": {
+			query:          "?supplier_id=supplier-a&include_deleted=1",
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []string{"invoice-1", "invoice-2"},
+		},
+		"invalid include_deleted": {
+			query:          "?include_deleted=maybe",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			logger := logrus.New()
+			logger.SetLevel(logrus.FatalLevel)
+
+			handler := NewHttpHandlerWithInterface(&mockInvoiceDB{
+				listInvoicesFunc: func(includeDeleted bool) ([]models.Invoice, error) {
+					return visible(includeDeleted, ""), nil
+				},
+				listInvoicesBySupplierFunc: func(supplierID string, includeDeleted bool) ([]models.Invoice, error) {
+					return visible(includeDeleted, supplierID), nil
+				},
+			}, logger)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/invoices"+tc.query, nil)
+			w := httptest.NewRecorder()
+
+			handler.ListInvoices(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			if tc.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var response models.InvoicesListResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			ids := []string{}
+			for _, invoice := range response.Data {
+				ids = append(ids, invoice.ID)
+			}
+			assert.Equal(t, tc.expectedIDs, ids)
+		})
+	}
+}
+
+func TestHttpHandler_DeleteInvoice(t *testing.T) {
+	testCases := map[string]struct {
+		deleteErr      error
+		expectedStatus int
+		expectEvent    bool
+	}{
+		"soft-deletes the invoice": {
+			expectedStatus: http.StatusOK,
+			expectEvent:    true,
+		},
+		"consumed existences block the delete": {
+			deleteErr:      models.ErrInvoiceExistencesConsumed,
+			expectedStatus: http.StatusConflict,
+		},
+		"missing invoice": {
+			deleteErr:      sql.ErrNoRows,
+			expectedStatus: http.StatusNotFound,
+		},
+		"database error": {
+			deleteErr:      fmt.Errorf("connection refused"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			logger := logrus.New()
+			logger.SetLevel(logrus.FatalLevel)
+
+			handler := NewHttpHandlerWithInterface(&mockInvoiceDB{
+				deleteInvoiceFunc: func(id string) error {
+					return tc.deleteErr
+				},
+			}, logger)
+
+			bus := eventbus.New(logger)
+			received := make(chan eventbus.Event, 1)
+			bus.Subscribe(events.InvoiceDeleted, func(event eventbus.Event) { received <- event })
+			handler.SetEventBus(bus)
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/v1/invoices/invoice-1", nil)
+			req = mux.SetURLVars(req, map[string]string{"id": "invoice-1"})
+			rec := httptest.NewRecorder()
+
+			handler.DeleteInvoice(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			var response models.InvoiceDeleteResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+			assert.Equal(t, tc.expectEvent, response.Success)
+
+			select {
+			case event := <-received:
+				assert.True(t, tc.expectEvent, "unexpected event: %v", event)
+			case <-time.After(20 * time.Millisecond):
+				assert.False(t, tc.expectEvent, "expected an invoice.deleted event")
+			}
+		})
+	}
+}
+
+func TestHttpHandler_RestoreInvoice(t *testing.T) {
+	testCases := map[string]struct {
+		restoreErr     error
+		expectedStatus int
+	}{
+		"restores a deleted invoice": {
+			expectedStatus: http.StatusOK,
+		},
+		"invoice is not deleted": {
+			restoreErr:     sql.ErrNoRows,
+			expectedStatus: http.StatusNotFound,
+		},
+		"database error": {
+			restoreErr:     fmt.Errorf("connection refused"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			logger := logrus.New()
+			logger.SetLevel(logrus.FatalLevel)
+
+			handler := NewHttpHandlerWithInterface(&mockInvoiceDB{
+				restoreInvoiceFunc: func(id string) (*models.Invoice, error) {
+					if tc.restoreErr != nil {
+						return nil, tc.restoreErr
+					}
+					return &models.Invoice{ID: id, InvoiceNumber: "INV-1"}, nil
+				},
+			}, logger)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/invoices/invoice-1/restore", nil)
+			req = mux.SetURLVars(req, map[string]string{"id": "invoice-1"})
+			rec := httptest.NewRecorder()
+
+			handler.RestoreInvoice(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			if tc.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var response models.InvoiceResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+			assert.True(t, response.Success)
+			assert.Equal(t, "invoice-1", response.Data.ID)
+			assert.Nil(t, response.Data.DeletedAt)
+		})
+	}
+}
+
+func TestHttpHandler_CreateInvoiceWithDetails_Currency(t *testing.T) {
+	testCases := map[string]struct {
+		body             string
+		expectedStatus   int
+		expectedCurrency string
+		expectedMessage  string
+	}{
+		"valid currency": {
+			body:             `{"invoice_number":"INV-1","transaction_type":"outcome","expense_category_id":"category-1","image_url":"http://img","currency":"usd","items":[{"detail":"Milk","count":2,"unit_type":"Liters","price":3}]}`,
+			expectedStatus:   http.StatusCreated,
+			expectedCurrency: "USD",
+		},
+		"defaults to the store currency": {
+			body:             `{"invoice_number":"INV-1","transaction_type":"outcome","expense_category_id":"category-1","image_url":"http://img","items":[{"detail":"Milk","count":2,"unit_type":"Liters","price":3}]}`,
+			expectedStatus:   http.StatusCreated,
+			expectedCurrency: "CRC",
+		},
+		"unsupported currency": {
+			body:            `{"invoice_number":"INV-1","transaction_type":"outcome","expense_category_id":"category-1","image_url":"http://img","currency":"JPY","items":[]}`,
+			expectedStatus:  http.StatusUnprocessableEntity,
+			expectedMessage: `unsupported currency "JPY" (supported: CRC, USD)`,
+		},
+		"mixed currency detail": {
+			body:            `{"invoice_number":"INV-1","transaction_type":"outcome","expense_category_id":"category-1","image_url":"http://img","currency":"USD","items":[{"detail":"Milk","count":2,"unit_type":"Liters","price":3,"currency":"CRC"}]}`,
+			expectedStatus:  http.StatusUnprocessableEntity,
+			expectedMessage: "item 0: invoice details must use the invoice currency: got CRC, invoice is in USD",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			logger := logrus.New()
+			logger.SetLevel(logrus.FatalLevel)
+
+			var received *models.CreateInvoiceRequest
+			handler := NewHttpHandlerWithInterface(&mockInvoiceDB{
+				createInvoiceFunc: func(req models.CreateInvoiceRequest) (*models.Invoice, error) {
+					received = &req
+					return &models.Invoice{ID: "invoice-1", InvoiceNumber: req.InvoiceNumber, Currency: req.Currency}, nil
+				},
+			}, logger)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/invoices", bytes.NewBufferString(tc.body))
+			rec := httptest.NewRecorder()
+
+			handler.CreateInvoiceWithDetails(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			if tc.expectedStatus != http.StatusCreated {
+				assert.Nil(t, received, "invalid currencies must not reach the database")
+
+				var response models.ErrorResponse
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+				assert.Equal(t, tc.expectedMessage, response.Error)
+				return
+			}
+
+			require.NotNil(t, received)
+			assert.Equal(t, tc.expectedCurrency, received.Currency)
+			for _, item := range received.Items {
+				assert.Equal(t, tc.expectedCurrency, item.Currency)
+			}
+		})
+	}
+}
+
+func TestHttpHandler_CreateInvoiceWithDetails_DuplicateNumber(t *testing.T) {
+	testCases := map[string]struct {
+		createErr      error
+		expectedStatus int
+	}{
+		"duplicate invoice number": {
+			createErr:      models.ErrDuplicateInvoiceNumber,
+			expectedStatus: http.StatusConflict,
+		},
+		"other database error": {
+			createErr:      fmt.Errorf("connection refused"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			logger := logrus.New()
+			logger.SetLevel(logrus.FatalLevel)
+
+			handler := NewHttpHandlerWithInterface(&mockInvoiceDB{
+				createInvoiceFunc: func(req models.CreateInvoiceRequest) (*models.Invoice, error) {
+					return nil, tc.createErr
+				},
+			}, logger)
+
+			body := `{"invoice_number":"INV-1","transaction_type":"outcome","expense_category_id":"category-1","image_url":"http://img","items":[]}`
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/invoices", bytes.NewBufferString(body))
+			rec := httptest.NewRecorder()
+
+			handler.CreateInvoiceWithDetails(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			var response models.InvoiceResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+			assert.False(t, response.Success)
+			assert.Contains(t, response.Message, tc.createErr.Error())
+		})
+	}
+}
+
+func TestHttpHandler_CheckInvoiceNumberAvailable(t *testing.T) {
+	testCases := map[string]struct {
+		lookupErr         error
+		expectedStatus    int
+		expectedAvailable bool
+	}{
+		"number is available": {
+			lookupErr:         sql.ErrNoRows,
+			expectedStatus:    http.StatusOK,
+			expectedAvailable: true,
+		},
+		"number is taken": {
+			expectedStatus:    http.StatusOK,
+			expectedAvailable: false,
+		},
+		"database error": {
+			lookupErr:      fmt.Errorf("connection refused"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			logger := logrus.New()
+			logger.SetLevel(logrus.FatalLevel)
+
+			var looked string
+			handler := NewHttpHandlerWithInterface(&mockInvoiceDB{
+				getInvoiceByNumberFunc: func(number string) (*models.Invoice, error) {
+					looked = number
+					if tc.lookupErr != nil {
+						return nil, tc.lookupErr
+					}
+					return &models.Invoice{ID: "invoice-1", InvoiceNumber: number}, nil
+				},
+			}, logger)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/invoices/number/INV-1/available", nil)
+			req = mux.SetURLVars(req, map[string]string{"number": "INV-1"})
+			rec := httptest.NewRecorder()
+
+			handler.CheckInvoiceNumberAvailable(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			assert.Equal(t, "INV-1", looked)
+			if tc.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var response models.InvoiceNumberAvailabilityResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+			assert.True(t, response.Success)
+			assert.Equal(t, "INV-1", response.Data.InvoiceNumber)
+			assert.Equal(t, tc.expectedAvailable, response.Data.Available)
+		})
+	}
+}
+
+func TestHttpHandler_CreateInvoiceDetail_Currency(t *testing.T) {
+	testCases := map[string]struct {
+		body           string
+		getInvoiceErr  error
+		deleted        bool
+		createErr      error
+		expectedStatus int
+	}{
+		"inherits the invoice currency": {
+			body:           `{"detail":"Milk","count":2,"unit_type":"Liters","price":3}`,
+			expectedStatus: http.StatusCreated,
+		},
+		"mixed currency detail": {
+			body:           `{"detail":"Milk","count":2,"unit_type":"Liters","price":3,"currency":"CRC"}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		"missing invoice": {
+			body:           `{"detail":"Milk","count":2,"unit_type":"Liters","price":3}`,
+			getInvoiceErr:  sql.ErrNoRows,
+			expectedStatus: http.StatusNotFound,
+		},
+		"deleted invoice": {
+			body:           `{"detail":"Milk","count":2,"unit_type":"Liters","price":3}`,
+			deleted:        true,
+			expectedStatus: http.StatusNotFound,
+		},
+		"invoice deleted before the detail is stored": {
+			body:           `{"detail":"Milk","count":2,"unit_type":"Liters","price":3}`,
+			createErr:      sql.ErrNoRows,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			logger := logrus.New()
+			logger.SetLevel(logrus.FatalLevel)
+
+			var received *models.CreateInvoiceDetailRequest
+			handler := NewHttpHandlerWithInterface(&mockInvoiceDB{
+				getInvoiceByIDFunc: func(id string) (*models.Invoice, error) {
+					if tc.getInvoiceErr != nil {
+						return nil, tc.getInvoiceErr
+					}
+					invoice := &models.Invoice{ID: id, Currency: "USD"}
+					if tc.deleted {
+						deletedAt := time.Now()
+						invoice.DeletedAt = &deletedAt
+					}
+					return invoice, nil
+				},
+				createInvoiceDetailFunc: func(req models.CreateInvoiceDetailRequest) (*models.InvoiceDetail, error) {
+					if tc.createErr != nil {
+						return nil, tc.createErr
+					}
+					received = &req
+					return &models.InvoiceDetail{ID: "detail-1", InvoiceID: req.InvoiceID, Currency: req.Currency}, nil
+				},
+			}, logger)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/invoices/invoice-1/details", bytes.NewBufferString(tc.body))
+			req = mux.SetURLVars(req, map[string]string{"id": "invoice-1"})
+			rec := httptest.NewRecorder()
+
+			handler.CreateInvoiceDetail(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			if tc.expectedStatus == http.StatusCreated {
+				require.NotNil(t, received)
+				assert.Equal(t, "USD", received.Currency)
+			} else {
+				assert.Nil(t, received)
+			}
+		})
+	}
+}
+
+func TestHttpHandler_CreateInvoiceDetail_Validation(t *testing.T) {
+	testCases := map[string]struct {
+		body           string
+		expectedStatus int
+		expectedFields []string
+	}{
+		"valid detail": {
+			body:           `{"detail":"Milk","count":2,"unit_type":"Liters","price":3}`,
+			expectedStatus: http.StatusCreated,
+		},
+		"free inventory detail": {
+			body:           `{"ingredient_id":"22222222-2222-2222-2222-222222222222","detail":"Sample","count":1,"unit_type":"Units","price":0,"generates_inventory":true}`,
+			expectedStatus: http.StatusCreated,
+		},
+		"zero count": {
+			body:           `{"detail":"Milk","count":0,"unit_type":"Liters","price":3}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedFields: []string{"count"},
+		},
+		"negative count": {
+			body:           `{"detail":"Milk","count":-2,"unit_type":"Liters","price":3}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedFields: []string{"count"},
+		},
+		"negative price": {
+			body:           `{"detail":"Milk","count":2,"unit_type":"Liters","price":-3}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedFields: []string{"price"},
+		},
+		"inventory detail without ingredient": {
+			body:           `{"detail":"Milk","count":2,"unit_type":"Liters","price":3,"generates_inventory":true}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedFields: []string{"ingredient_id"},
+		},
+		"partially received detail": {
+			body:           `{"detail":"Milk","count":10,"unit_type":"Liters","price":3,"received_count":4}`,
+			expectedStatus: http.StatusCreated,
+		},
+		"received more than ordered": {
+			body:           `{"detail":"Milk","count":2,"unit_type":"Liters","price":3,"received_count":3}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedFields: []string{"received_count"},
+		},
+		"every violation reported": {
+			body:           `{"detail":"Milk","count":-1,"unit_type":"Liters","price":-1,"generates_inventory":true}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedFields: []string{"count", "price", "ingredient_id"},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			logger := logrus.New()
+			logger.SetLevel(logrus.FatalLevel)
+
+			created := false
+			handler := NewHttpHandlerWithInterface(&mockInvoiceDB{
+				getInvoiceByIDFunc: func(id string) (*models.Invoice, error) {
+					return &models.Invoice{ID: id, Currency: "CRC"}, nil
+				},
+				createInvoiceDetailFunc: func(req models.CreateInvoiceDetailRequest) (*models.InvoiceDetail, error) {
+					created = true
+					return &models.InvoiceDetail{ID: "detail-1", InvoiceID: req.InvoiceID, Currency: req.Currency}, nil
+				},
+			}, logger)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/invoices/invoice-1/details", bytes.NewBufferString(tc.body))
+			req = mux.SetURLVars(req, map[string]string{"id": "invoice-1"})
+			rec := httptest.NewRecorder()
+
+			handler.CreateInvoiceDetail(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			if tc.expectedStatus == http.StatusCreated {
+				assert.True(t, created)
+				return
+			}
+
+			assert.False(t, created)
+			var response models.ValidationErrorResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+			assert.False(t, response.Success)
+			var fields []string
+			for _, fieldErr := range response.Errors {
+				fields = append(fields, fieldErr.Field)
+				assert.NotEmpty(t, fieldErr.Message)
+			}
+			assert.Equal(t, tc.expectedFields, fields)
+		})
+	}
+}
+
+func TestHttpHandler_RecordInvoiceDetailReceipt(t *testing.T) {
+	testCases := map[string]struct {
+		body           string
+		recordErr      error
+		expectedStatus int
+		expectRecorded bool
+	}{
+		"partial receipt recorded": {
+			body:           `{"count":4}`,
+			expectedStatus: http.StatusOK,
+			expectRecorded: true,
+		},
+		"zero count rejected": {
+			body:           `{"count":0}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		"invalid JSON": {
+			body:           `{"count":`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		"detail not found": {
+			body:           `{"count":4}`,
+			recordErr:      sql.ErrNoRows,
+			expectedStatus: http.StatusNotFound,
+			expectRecorded: true,
+		},
+		"more than outstanding": {
+			body:           `{"count":40}`,
+			recordErr:      models.ErrReceiptExceedsOutstanding,
+			expectedStatus: http.StatusConflict,
+			expectRecorded: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			logger := logrus.New()
+			logger.SetLevel(logrus.FatalLevel)
+
+			var recorded *models.RecordReceiptRequest
+			handler := NewHttpHandlerWithInterface(&mockInvoiceDB{
+				recordReceiptFunc: func(invoiceID, detailID string, req models.RecordReceiptRequest) (*models.InvoiceDetail, error) {
+					recorded = &req
+					if tc.recordErr != nil {
+						return nil, tc.recordErr
+					}
+					return &models.InvoiceDetail{ID: detailID, InvoiceID: invoiceID, Count: 10, ReceivedCount: 8, OutstandingCount: 2}, nil
+				},
+			}, logger)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/invoices/invoice-1/details/detail-1/receipts", bytes.NewBufferString(tc.body))
+			req = mux.SetURLVars(req, map[string]string{"id": "invoice-1", "detailId": "detail-1"})
+			rec := httptest.NewRecorder()
+
+			handler.RecordInvoiceDetailReceipt(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			assert.Equal(t, tc.expectRecorded, recorded != nil)
+			if tc.expectedStatus == http.StatusOK {
+				assert.Equal(t, 4.0, recorded.Count)
+				var response models.InvoiceDetailResponse
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+				assert.Equal(t, 8.0, response.Data.ReceivedCount)
+				assert.Equal(t, 2.0, response.Data.OutstandingCount)
+			}
+		})
+	}
+}
+
+func TestHttpHandler_GetSupplierReport(t *testing.T) {
+	testCases := map[string]struct {
+		query          string
+		reportErr      error
+		expectedStatus int
+		expectedFrom   string
+		expectedTo     string
+	}{
+		"explicit period": {
+			query:          "?from=2024-01-01&to=2024-03-31",
+			expectedStatus: http.StatusOK,
+			expectedFrom:   "2024-01-01",
+			expectedTo:     "2024-03-31",
+		},
+		"from defaults to 90 days before to": {
+			query:          "?to=2024-03-31",
+			expectedStatus: http.StatusOK,
+			expectedFrom:   "2024-01-01",
+			expectedTo:     "2024-03-31",
+		},
+		"malformed from": {
+			query:          "?from=01/01/2024",
+			expectedStatus: http.StatusBadRequest,
+		},
+		"from after to": {
+			query:          "?from=2024-04-01&to=2024-03-31",
+			expectedStatus: http.StatusBadRequest,
+		},
+		"database error": {
+			query:          "?from=2024-01-01&to=2024-03-31",
+			reportErr:      fmt.Errorf("connection refused"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			logger := logrus.New()
+			logger.SetLevel(logrus.FatalLevel)
+
+			handler := NewHttpHandlerWithInterface(&mockInvoiceDB{
+				getSupplierReportFunc: func(supplierID string, from, to time.Time) (*models.SupplierReport, error) {
+					if tc.reportErr != nil {
+						return nil, tc.reportErr
+					}
+					report := models.BuildSupplierReport(supplierID, from, to, to, nil, nil)
+					return &report, nil
+				},
+			}, logger)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/invoices/suppliers/supplier-1/report"+tc.query, nil)
+			req = mux.SetURLVars(req, map[string]string{"id": "supplier-1"})
+			rec := httptest.NewRecorder()
+
+			handler.GetSupplierReport(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			if tc.expectedStatus == http.StatusOK {
+				var response models.SupplierReportResponse
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+				assert.Equal(t, "supplier-1", response.Data.SupplierID)
+				assert.Equal(t, tc.expectedFrom, response.Data.From)
+				assert.Equal(t, tc.expectedTo, response.Data.To)
+			}
+		})
+	}
+}
+
+func TestHttpHandler_GetInvoiceWithDetails(t *testing.T) {
+	testCases := map[string]struct {
+		getInvoiceErr     error
+		getDetailsErr     error
+		details           []models.InvoiceDetail
+		expectedStatus    int
+		expectedDetailIDs []string
+	}{
+		"embeds the invoice details": {
+			details: []models.InvoiceDetail{
+				{ID: "detail-1", InvoiceID: "invoice-1", Detail: "Milk"},
+				{ID: "detail-2", InvoiceID: "invoice-1", Detail: "Sugar"},
+			},
+			expectedStatus:    http.StatusOK,
+			expectedDetailIDs: []string{"detail-1", "detail-2"},
+		},
+		"invoice without details returns empty list": {
+			expectedStatus:    http.StatusOK,
+			expectedDetailIDs: []string{},
+		},
+		"missing invoice": {
+			getInvoiceErr:  sql.ErrNoRows,
+			expectedStatus: http.StatusNotFound,
+		},
+		"invoice lookup fails": {
+			getInvoiceErr:  fmt.Errorf("connection refused"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+		"details lookup fails": {
+			getDetailsErr:  fmt.Errorf("connection refused"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			logger := logrus.New()
+			logger.SetLevel(logrus.FatalLevel)
+
+			detailsLoaded := false
+			handler := NewHttpHandlerWithInterface(&mockInvoiceDB{
+				getInvoiceByIDFunc: func(id string) (*models.Invoice, error) {
+					if tc.getInvoiceErr != nil {
+						return nil, tc.getInvoiceErr
+					}
+					return &models.Invoice{ID: id, InvoiceNumber: "INV-001", Currency: "CRC"}, nil
+				},
+				getInvoiceDetailsFunc: func(invoiceID string) ([]models.InvoiceDetail, error) {
+					detailsLoaded = true
+					assert.Equal(t, "invoice-1", invoiceID)
+					return tc.details, tc.getDetailsErr
+				},
+			}, logger)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/invoices/invoice-1/full", nil)
+			req = mux.SetURLVars(req, map[string]string{"id": "invoice-1"})
+			rec := httptest.NewRecorder()
+
+			handler.GetInvoiceWithDetails(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			if tc.expectedStatus != http.StatusOK {
+				assert.Equal(t, tc.getDetailsErr != nil, detailsLoaded)
+				return
+			}
+
+			var response struct {
+				Success bool `json:"success"`
+				Data    struct {
+					ID            string                 `json:"id"`
+					InvoiceNumber string                 `json:"invoice_number"`
+					Details       []models.InvoiceDetail `json:"details"`
+				} `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+			assert.True(t, response.Success)
+			assert.Equal(t, "invoice-1", response.Data.ID)
+			assert.Equal(t, "INV-001", response.Data.InvoiceNumber)
+			require.NotNil(t, response.Data.Details)
+
+			ids := []string{}
+			for _, detail := range response.Data.Details {
+				assert.Equal(t, "invoice-1", detail.InvoiceID)
+				ids = append(ids, detail.ID)
+			}
+			assert.Equal(t, tc.expectedDetailIDs, ids)
+		})
+	}
+}
+
+func TestHttpHandler_PatchInvoice(t *testing.T) {
+	testCases := map[string]struct {
+		body           string
+		patchErr       error
+		expectedStatus int
+		expectPatched  bool
+	}{
+		"notes only": {
+			body:           `{"notes":"Delivered late"}`,
+			expectedStatus: http.StatusOK,
+			expectPatched:  true,
+		},
+		"empty body": {
+			body:           `{}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		"unknown field": {
+			body:           `{"note":"Delivered late"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		"missing invoice": {
+			body:           `{"notes":"Delivered late"}`,
+			patchErr:       sql.ErrNoRows,
+			expectedStatus: http.StatusNotFound,
+			expectPatched:  true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			logger := logrus.New()
+			logger.SetLevel(logrus.FatalLevel)
+
+			var received *models.PatchInvoiceRequest
+			handler := NewHttpHandlerWithInterface(&mockInvoiceDB{
+				patchInvoiceFunc: func(id string, req models.PatchInvoiceRequest) (*models.Invoice, error) {
+					received = &req
+					if tc.patchErr != nil {
+						return nil, tc.patchErr
+					}
+					return &models.Invoice{ID: id, Notes: req.Notes}, nil
+				},
+			}, logger)
+
+			req := httptest.NewRequest(http.MethodPatch, "/api/v1/invoices/invoice-1", bytes.NewBufferString(tc.body))
+			req = mux.SetURLVars(req, map[string]string{"id": "invoice-1"})
+			rec := httptest.NewRecorder()
+
+			handler.PatchInvoice(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			if !tc.expectPatched {
+				assert.Nil(t, received)
+				return
+			}
+
+			require.NotNil(t, received)
+			require.NotNil(t, received.Notes)
+			assert.Equal(t, "Delivered late", *received.Notes)
+			assert.Nil(t, received.SupplierID)
+			assert.Nil(t, received.TransactionDate)
+		})
+	}
+}
+
+func TestHttpHandler_PatchInvoice_PublishesEvent(t *testing.T) {
+	testCases := map[string]struct {
+		patchErr    error
+		expectEvent bool
+	}{
+		"committed patch publishes invoice.updated": {
+			expectEvent: true,
+		},
+		"failed patch publishes nothing": {
+			patchErr: sql.ErrNoRows,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			logger := logrus.New()
+			logger.SetLevel(logrus.FatalLevel)
+
+			handler := NewHttpHandlerWithInterface(&mockInvoiceDB{
+				patchInvoiceFunc: func(id string, req models.PatchInvoiceRequest) (*models.Invoice, error) {
+					if tc.patchErr != nil {
+						return nil, tc.patchErr
+					}
+					return &models.Invoice{ID: id, InvoiceNumber: "INV-1", Notes: req.Notes}, nil
+				},
+			}, logger)
+
+			bus := eventbus.New(logger)
+			received := make(chan eventbus.Event, 1)
+			bus.Subscribe(events.InvoiceUpdated, func(event eventbus.Event) { received <- event })
+			handler.SetEventBus(bus)
+
+			req := httptest.NewRequest(http.MethodPatch, "/api/v1/invoices/invoice-1", bytes.NewBufferString(`{"notes":"Delivered late"}`))
+			req = mux.SetURLVars(req, map[string]string{"id": "invoice-1"})
+			rec := httptest.NewRecorder()
+
+			handler.PatchInvoice(rec, req)
+
+			if !tc.expectEvent {
+				select {
+				case event := <-received:
+					t.Fatalf("unexpected event: %v", event)
+				case <-time.After(20 * time.Millisecond):
+				}
+				return
+			}
+
+			select {
+			case event := <-received:
+				invoiceEvent, ok := event.(events.InvoiceEvent)
+				require.True(t, ok)
+				assert.Equal(t, "invoice-1", invoiceEvent.InvoiceID)
+				assert.Equal(t, "INV-1", invoiceEvent.InvoiceNumber)
+				assert.False(t, invoiceEvent.OccurredAt().IsZero())
+			case <-time.After(time.Second):
+				t.Fatal("expected an invoice.updated event")
+			}
+		})
+	}
+}
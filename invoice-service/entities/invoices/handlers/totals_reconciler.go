@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TotalsReconcilerStore is the storage operation the totals reconciler runs on each tick
+type TotalsReconcilerStore interface {
+	ReconcileInvoiceTotals() (int, error)
+}
+
+// TotalsReconciler periodically corrects invoice totals that drifted from their details
+type TotalsReconciler struct {
+	store    TotalsReconcilerStore
+	interval time.Duration
+	logger   *logrus.Logger
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewTotalsReconciler creates a reconciler that runs every interval
+func NewTotalsReconciler(store TotalsReconcilerStore, interval time.Duration, logger *logrus.Logger) *TotalsReconciler {
+	return &TotalsReconciler{
+		store:    store,
+		interval: interval,
+		logger:   logger,
+		stop:     make(chan struct{}),
+	}
+}
+
+// Start runs a reconciliation on every interval until Stop is called
+func (r *TotalsReconciler) Start() {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.RunOnce()
+		case <-r.stop:
+			return
+		}
+	}
+}
+
+// Stop terminates the reconciler loop
+func (r *TotalsReconciler) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.stop)
+	})
+}
+
+// RunOnce performs a single reconciliation pass and returns how many invoices were corrected
+func (r *TotalsReconciler) RunOnce() int {
+	corrected, err := r.store.ReconcileInvoiceTotals()
+	if err != nil {
+		r.logger.WithError(err).WithField("invoices_corrected", corrected).Error("Invoice total reconciliation finished with errors")
+	}
+	return corrected
+}
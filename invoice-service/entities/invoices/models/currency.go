@@ -0,0 +1,76 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnsupportedCurrency is returned for currency codes outside the supported list
+var ErrUnsupportedCurrency = errors.New("unsupported currency")
+
+// ErrMixedCurrencies is returned when an invoice's details use a different currency than the invoice
+var ErrMixedCurrencies = errors.New("invoice details must use the invoice currency")
+
+// CurrencyPolicy holds the store's default currency and the ISO 4217 codes invoices may use
+type CurrencyPolicy struct {
+	Default   string
+	Supported []string
+}
+
+// DefaultCurrencyPolicy returns the policy used when none is configured
+func DefaultCurrencyPolicy() CurrencyPolicy {
+	return CurrencyPolicy{
+		Default:   "CRC",
+		Supported: []string{"CRC", "USD"},
+	}
+}
+
+// Resolve normalizes a currency code, falling back to fallback when code is empty,
+// and checks it against the supported list
+func (p CurrencyPolicy) Resolve(code, fallback string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		code = fallback
+	}
+
+	for _, supported := range p.Supported {
+		if strings.EqualFold(supported, code) {
+			return code, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w %q (supported: %s)", ErrUnsupportedCurrency, code, strings.Join(p.Supported, ", "))
+}
+
+// ResolveInvoice fills in and validates the currency of an invoice and each of its items.
+// Items default to the invoice currency and may not use any other.
+func (p CurrencyPolicy) ResolveInvoice(req *CreateInvoiceRequest) error {
+	currency, err := p.Resolve(req.Currency, p.Default)
+	if err != nil {
+		return err
+	}
+	req.Currency = currency
+
+	for i := range req.Items {
+		if err := p.ResolveDetail(&req.Items[i], currency); err != nil {
+			return fmt.Errorf("item %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// ResolveDetail fills in and validates a detail's currency against its invoice's currency
+func (p CurrencyPolicy) ResolveDetail(req *CreateInvoiceDetailRequest, invoiceCurrency string) error {
+	currency, err := p.Resolve(req.Currency, invoiceCurrency)
+	if err != nil {
+		return err
+	}
+	if currency != invoiceCurrency {
+		return fmt.Errorf("%w: got %s, invoice is in %s", ErrMixedCurrencies, currency, invoiceCurrency)
+	}
+	req.Currency = currency
+
+	return nil
+}
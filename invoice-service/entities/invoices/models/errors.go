@@ -0,0 +1,12 @@
+package models
+
+import "errors"
+
+// ErrDuplicateInvoiceNumber is returned when an invoice number is already used by another invoice
+var ErrDuplicateInvoiceNumber = errors.New("invoice number already exists")
+
+// ErrInvoiceExistencesConsumed is returned when deleting an invoice whose derived existences have already been used
+var ErrInvoiceExistencesConsumed = errors.New("invoice existences have already been consumed")
+
+// ErrReceiptExceedsOutstanding is returned when a receipt records more units than an invoice detail still has outstanding
+var ErrReceiptExceedsOutstanding = errors.New("received count exceeds the outstanding count")
@@ -6,32 +6,38 @@ import (
 
 // Invoice represents an invoice in the database
 type Invoice struct {
-	ID                string    `json:"id" db:"id"`
-	InvoiceNumber     string    `json:"invoice_number" db:"invoice_number"`
-	TransactionDate   time.Time `json:"transaction_date" db:"transaction_date"`
-	TransactionType   string    `json:"transaction_type" db:"transaction_type"`
-	SupplierID        *string   `json:"supplier_id" db:"supplier_id"`
-	ExpenseCategoryID string    `json:"expense_category_id" db:"expense_category_id"`
-	TotalAmount       *float64  `json:"total_amount" db:"total_amount"`
-	ImageURL          string    `json:"image_url" db:"image_url"`
-	Notes             *string   `json:"notes" db:"notes"`
-	CreatedAt         time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt         time.Time `json:"updated_at" db:"updated_at"`
+	ID                string     `json:"id" db:"id"`
+	InvoiceNumber     string     `json:"invoice_number" db:"invoice_number"`
+	TransactionDate   time.Time  `json:"transaction_date" db:"transaction_date"`
+	TransactionType   string     `json:"transaction_type" db:"transaction_type"`
+	SupplierID        *string    `json:"supplier_id" db:"supplier_id"`
+	ExpenseCategoryID string     `json:"expense_category_id" db:"expense_category_id"`
+	TotalAmount       *float64   `json:"total_amount" db:"total_amount"`
+	ImageURL          string     `json:"image_url" db:"image_url"`
+	Notes             *string    `json:"notes" db:"notes"`
+	Currency          string     `json:"currency" db:"currency"`
+	TaxExempt         bool       `json:"tax_exempt" db:"tax_exempt"`
+	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at" db:"updated_at"`
+	DeletedAt         *time.Time `json:"deleted_at,omitempty" db:"deleted_at"` // set when the invoice is soft-deleted
 }
 
 // InvoiceDetail represents a line item within an invoice
 type InvoiceDetail struct {
-	ID             string     `json:"id" db:"id"`
-	InvoiceID      string     `json:"invoice_id" db:"invoice_id"`
-	IngredientID   *string    `json:"ingredient_id" db:"ingredient_id"`
-	Detail         string     `json:"detail" db:"detail"`
-	Count          float64    `json:"count" db:"count"`
-	UnitType       string     `json:"unit_type" db:"unit_type"`
-	Price          float64    `json:"price" db:"price"`
-	Total          float64    `json:"total" db:"total"`
-	ExpirationDate *time.Time `json:"expiration_date" db:"expiration_date"`
-	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+	ID               string     `json:"id" db:"id"`
+	InvoiceID        string     `json:"invoice_id" db:"invoice_id"`
+	IngredientID     *string    `json:"ingredient_id" db:"ingredient_id"`
+	Detail           string     `json:"detail" db:"detail"`
+	Count            float64    `json:"count" db:"count"`
+	UnitType         string     `json:"unit_type" db:"unit_type"`
+	Price            float64    `json:"price" db:"price"`
+	Total            float64    `json:"total" db:"total"`
+	ReceivedCount    float64    `json:"received_count" db:"received_count"`       // units delivered so far
+	OutstandingCount float64    `json:"outstanding_count" db:"outstanding_count"` // units still to be delivered
+	ExpirationDate   *time.Time `json:"expiration_date" db:"expiration_date"`
+	Currency         string     `json:"currency" db:"currency"`
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at" db:"updated_at"`
 }
 
 // CreateInvoiceDetailRequest represents the request to create a new invoice detail
@@ -41,8 +47,55 @@ type CreateInvoiceDetailRequest struct {
 	Detail         string     `json:"detail" validate:"required"`
 	Count          float64    `json:"count" validate:"required,gt=0"`
 	UnitType       string     `json:"unit_type" validate:"required,oneof=Liters Gallons Units Bag"`
-	Price          float64    `json:"price" validate:"required,gt=0"`
+	Price          float64    `json:"price" validate:"min=0"`
 	ExpirationDate *time.Time `json:"expiration_date,omitempty"`
+	Currency       string     `json:"currency,omitempty" validate:"omitempty,len=3"` // defaults to the invoice currency
+	// ReceivedCount is how much of Count arrived with the invoice; it defaults to all of it, and the
+	// rest stays outstanding until recorded as a receipt
+	ReceivedCount *float64 `json:"received_count,omitempty"`
+	// GeneratesInventory marks a detail that stocks an ingredient, so it must name one
+	GeneratesInventory bool `json:"generates_inventory,omitempty"`
+}
+
+// Validate checks the detail's quantity, price and ingredient, returning every violation found
+func (r CreateInvoiceDetailRequest) Validate() []FieldError {
+	var errs []FieldError
+	if r.Count <= 0 {
+		errs = append(errs, FieldError{Field: "count", Message: "count must be greater than 0"})
+	}
+	if r.Price < 0 {
+		errs = append(errs, FieldError{Field: "price", Message: "price must not be negative"})
+	}
+	if r.GeneratesInventory && (r.IngredientID == nil || *r.IngredientID == "") {
+		errs = append(errs, FieldError{Field: "ingredient_id", Message: "ingredient_id is required for inventory-generating details"})
+	}
+	if r.ReceivedCount != nil && (*r.ReceivedCount < 0 || *r.ReceivedCount > r.Count) {
+		errs = append(errs, FieldError{Field: "received_count", Message: "received_count must be between 0 and count"})
+	}
+	return errs
+}
+
+// Received returns how much of the detail arrived with the invoice
+func (r CreateInvoiceDetailRequest) Received() float64 {
+	if r.ReceivedCount == nil {
+		return r.Count
+	}
+	return *r.ReceivedCount
+}
+
+// RecordReceiptRequest records units of an invoice detail delivered after the invoice
+type RecordReceiptRequest struct {
+	Count          float64    `json:"count" validate:"required,gt=0"`
+	ExpirationDate *time.Time `json:"expiration_date,omitempty"` // defaults to the detail's expiration date
+}
+
+// Validate checks the received quantity
+func (r RecordReceiptRequest) Validate() []FieldError {
+	var errs []FieldError
+	if r.Count <= 0 {
+		errs = append(errs, FieldError{Field: "count", Message: "count must be greater than 0"})
+	}
+	return errs
 }
 
 // CreateInvoiceRequest represents the request to create a new invoice with details
@@ -54,6 +107,8 @@ type CreateInvoiceRequest struct {
 	ExpenseCategoryID string                       `json:"expense_category_id" validate:"required,uuid"`
 	ImageURL          string                       `json:"image_url" validate:"required,url"`
 	Notes             *string                      `json:"notes,omitempty"`
+	Currency          string                       `json:"currency,omitempty" validate:"omitempty,len=3"` // defaults to the store currency
+	TaxExempt         bool                         `json:"tax_exempt,omitempty"`                          // skips IVA and service tax on existences
 	Items             []CreateInvoiceDetailRequest `json:"items" validate:"required,dive"`
 }
 
@@ -68,6 +123,23 @@ type UpdateInvoiceRequest struct {
 	Notes             *string    `json:"notes,omitempty"`
 }
 
+// PatchInvoiceRequest represents a partial invoice update; only non-nil fields are written
+type PatchInvoiceRequest struct {
+	InvoiceNumber     *string    `json:"invoice_number,omitempty" validate:"omitempty,min=1"`
+	TransactionDate   *time.Time `json:"transaction_date,omitempty"`
+	TransactionType   *string    `json:"transaction_type,omitempty" validate:"omitempty,oneof=income outcome"`
+	SupplierID        *string    `json:"supplier_id,omitempty" validate:"omitempty,uuid"`
+	ExpenseCategoryID *string    `json:"expense_category_id,omitempty" validate:"omitempty,uuid"`
+	ImageURL          *string    `json:"image_url,omitempty" validate:"omitempty,url"`
+	Notes             *string    `json:"notes,omitempty"`
+}
+
+// IsEmpty reports whether the patch sets no fields
+func (r PatchInvoiceRequest) IsEmpty() bool {
+	return r.InvoiceNumber == nil && r.TransactionDate == nil && r.TransactionType == nil &&
+		r.SupplierID == nil && r.ExpenseCategoryID == nil && r.ImageURL == nil && r.Notes == nil
+}
+
 // UpdateInvoiceDetailRequest represents the request to update an invoice detail
 type UpdateInvoiceDetailRequest struct {
 	IngredientID   *string    `json:"ingredient_id,omitempty" validate:"omitempty,uuid"`
@@ -123,12 +195,39 @@ type InvoiceResponse struct {
 	Message string  `json:"message,omitempty"`
 }
 
+// InvoiceWithDetails is an invoice header together with its line items
+type InvoiceWithDetails struct {
+	Invoice
+	Details []InvoiceDetail `json:"details"`
+}
+
+// InvoiceWithDetailsResponse represents a single invoice with its details response
+type InvoiceWithDetailsResponse struct {
+	Success bool               `json:"success"`
+	Data    InvoiceWithDetails `json:"data"`
+	Message string             `json:"message,omitempty"`
+}
+
 // InvoicesListResponse represents a list of invoices response
 type InvoicesListResponse struct {
-	Success bool      `json:"success"`
-	Data    []Invoice `json:"data"`
-	Count   int       `json:"count"`
-	Message string    `json:"message,omitempty"`
+	Success     bool      `json:"success"`
+	Data        []Invoice `json:"data"`
+	Count       int       `json:"count"`
+	TotalAmount *float64  `json:"total_amount,omitempty"`
+	Message     string    `json:"message,omitempty"`
+}
+
+// InvoiceNumberAvailability reports whether an invoice number is still free to use
+type InvoiceNumberAvailability struct {
+	InvoiceNumber string `json:"invoice_number"`
+	Available     bool   `json:"available"`
+}
+
+// InvoiceNumberAvailabilityResponse represents an invoice number availability response
+type InvoiceNumberAvailabilityResponse struct {
+	Success bool                      `json:"success"`
+	Data    InvoiceNumberAvailability `json:"data"`
+	Message string                    `json:"message,omitempty"`
 }
 
 // InvoiceDeleteResponse represents a delete operation response
@@ -165,6 +264,19 @@ type ErrorResponse struct {
 	Message string `json:"message,omitempty"`
 }
 
+// FieldError describes why a single request field was rejected
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationErrorResponse represents a request rejected for one or more invalid fields
+type ValidationErrorResponse struct {
+	Success bool         `json:"success"`
+	Error   string       `json:"error"`
+	Errors  []FieldError `json:"errors"`
+}
+
 // Existence represents a specific ingredient purchase/acquisition batch
 type Existence struct {
 	ID                     string     `json:"id" db:"id"`
@@ -203,4 +315,5 @@ type CreateExistenceRequest struct {
 	IncomeMarginPercentage float64    `json:"income_margin_percentage" validate:"required,min=0,max=100"`
 	IvaPercentage          float64    `json:"iva_percentage" validate:"required,min=0,max=100"`
 	ServiceTaxPercentage   float64    `json:"service_tax_percentage" validate:"required,min=0,max=100"`
+	TaxExempt              bool       `json:"tax_exempt"` // IVA and service tax are not charged when set
 }
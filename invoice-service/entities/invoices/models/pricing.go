@@ -0,0 +1,24 @@
+package models
+
+// System configuration keys holding the existence pricing defaults
+const (
+	ConfigKeyIncomeMarginPercentage = "income_margin_percentage"
+	ConfigKeyIvaPercentage          = "iva_percentage"
+	ConfigKeyServiceTaxPercentage   = "service_tax_percentage"
+)
+
+// ExistencePricingDefaults holds the percentages applied to existences created from invoices
+type ExistencePricingDefaults struct {
+	IncomeMarginPercentage float64
+	IvaPercentage          float64
+	ServiceTaxPercentage   float64
+}
+
+// DefaultExistencePricing returns the percentages used when system_configuration does not define them
+func DefaultExistencePricing() ExistencePricingDefaults {
+	return ExistencePricingDefaults{
+		IncomeMarginPercentage: 30.0,
+		IvaPercentage:          13.0,
+		ServiceTaxPercentage:   10.0,
+	}
+}
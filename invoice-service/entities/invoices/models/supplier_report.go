@@ -0,0 +1,159 @@
+package models
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"shared/money"
+)
+
+// DefaultSupplierReportPeriod is how far back a supplier report looks when no ?from= is given
+const DefaultSupplierReportPeriod = 90 * 24 * time.Hour
+
+// SupplierReportInvoice is one of a supplier's invoices in the report period, with its ordered and received units
+type SupplierReportInvoice struct {
+	ID            string
+	Currency      string
+	TotalAmount   float64
+	OrderedUnits  float64
+	ReceivedUnits float64
+}
+
+// SupplierReportExistence is an existence derived from one of a supplier's invoices in the report period
+type SupplierReportExistence struct {
+	Currency        string
+	TransactionDate time.Time
+	ExpirationDate  *time.Time
+	UnitsPurchased  float64
+	UnitsAvailable  float64
+	RemainingValue  float64
+}
+
+// SupplierSpend totals a supplier's invoices in one currency
+type SupplierSpend struct {
+	Currency            string  `json:"currency"`
+	InvoiceCount        int     `json:"invoice_count"`
+	TotalSpend          float64 `json:"total_spend"`
+	AverageInvoiceValue float64 `json:"average_invoice_value"`
+}
+
+// SupplierStockValue is the value of a supplier's stock in one currency
+type SupplierStockValue struct {
+	Currency string  `json:"currency"`
+	Value    float64 `json:"value"`
+}
+
+// SupplierDeliveryQuality measures how completely a supplier delivered what was invoiced
+type SupplierDeliveryQuality struct {
+	FullyReceivedInvoices int     `json:"fully_received_invoices"`
+	OutstandingInvoices   int     `json:"outstanding_invoices"`
+	FillRatePercentage    float64 `json:"fill_rate_percentage"`
+}
+
+// SupplierExpiryQuality measures how the existences bought from a supplier aged.
+// The expired stock value is broken down by the currency of the invoice each existence came from
+type SupplierExpiryQuality struct {
+	Existences           int                  `json:"existences"`
+	WithExpirationDate   int                  `json:"with_expiration_date"`
+	ExpiredWithStock     int                  `json:"expired_with_stock"`
+	ExpiredUnits         float64              `json:"expired_units"`
+	ExpiredStockValue    []SupplierStockValue `json:"expired_stock_value"`
+	AverageShelfLifeDays *float64             `json:"average_shelf_life_days"`
+}
+
+// SupplierReport summarizes a supplier's invoices between From and To (inclusive, YYYY-MM-DD).
+// Spend is broken down by currency because invoices are never converted
+type SupplierReport struct {
+	SupplierID   string                  `json:"supplier_id"`
+	From         string                  `json:"from"`
+	To           string                  `json:"to"`
+	InvoiceCount int                     `json:"invoice_count"`
+	Spend        []SupplierSpend         `json:"spend"`
+	Delivery     SupplierDeliveryQuality `json:"delivery"`
+	Expiry       SupplierExpiryQuality   `json:"expiry"`
+}
+
+// SupplierReportResponse represents a supplier report response
+type SupplierReportResponse struct {
+	Success bool           `json:"success"`
+	Data    SupplierReport `json:"data"`
+	Message string         `json:"message,omitempty"`
+}
+
+// BuildSupplierReport aggregates a supplier's invoice and existence rows; existences expiring
+// before asOf that still have units available count as expired stock
+func BuildSupplierReport(supplierID string, from, to, asOf time.Time, invoices []SupplierReportInvoice, existences []SupplierReportExistence) SupplierReport {
+	report := SupplierReport{
+		SupplierID:   supplierID,
+		From:         from.Format("2006-01-02"),
+		To:           to.Format("2006-01-02"),
+		InvoiceCount: len(invoices),
+		Spend:        []SupplierSpend{},
+	}
+	report.Expiry.ExpiredStockValue = []SupplierStockValue{}
+
+	totals := map[string]money.Money{}
+	counts := map[string]int{}
+	ordered, received := 0.0, 0.0
+	for _, invoice := range invoices {
+		totals[invoice.Currency] += money.FromFloat(invoice.TotalAmount)
+		counts[invoice.Currency]++
+
+		if invoice.ReceivedUnits >= invoice.OrderedUnits {
+			report.Delivery.FullyReceivedInvoices++
+		} else {
+			report.Delivery.OutstandingInvoices++
+		}
+		ordered += invoice.OrderedUnits
+		received += invoice.ReceivedUnits
+	}
+
+	for currency, total := range totals {
+		average := money.FromCents(int64(math.Round(float64(total.Cents()) / float64(counts[currency]))))
+		report.Spend = append(report.Spend, SupplierSpend{
+			Currency:            currency,
+			InvoiceCount:        counts[currency],
+			TotalSpend:          total.Float64(),
+			AverageInvoiceValue: average.Float64(),
+		})
+	}
+	sort.Slice(report.Spend, func(i, j int) bool { return report.Spend[i].Currency < report.Spend[j].Currency })
+
+	if ordered > 0 {
+		report.Delivery.FillRatePercentage = math.Round(received/ordered*10000) / 100
+	}
+
+	shelfLifeDays := 0.0
+	expiredValues := map[string]money.Money{}
+	for _, existence := range existences {
+		report.Expiry.Existences++
+		if existence.ExpirationDate == nil {
+			continue
+		}
+		report.Expiry.WithExpirationDate++
+		shelfLifeDays += existence.ExpirationDate.Sub(existence.TransactionDate).Hours() / 24
+
+		if existence.ExpirationDate.Before(asOf) && existence.UnitsAvailable > 0 {
+			report.Expiry.ExpiredWithStock++
+			report.Expiry.ExpiredUnits += existence.UnitsAvailable
+			expiredValues[existence.Currency] += money.FromFloat(existence.RemainingValue)
+		}
+	}
+	report.Expiry.ExpiredUnits = math.Round(report.Expiry.ExpiredUnits*100) / 100
+	for currency, value := range expiredValues {
+		report.Expiry.ExpiredStockValue = append(report.Expiry.ExpiredStockValue, SupplierStockValue{
+			Currency: currency,
+			Value:    value.Float64(),
+		})
+	}
+	sort.Slice(report.Expiry.ExpiredStockValue, func(i, j int) bool {
+		return report.Expiry.ExpiredStockValue[i].Currency < report.Expiry.ExpiredStockValue[j].Currency
+	})
+	if report.Expiry.WithExpirationDate > 0 {
+		average := math.Round(shelfLifeDays/float64(report.Expiry.WithExpirationDate)*10) / 10
+		report.Expiry.AverageShelfLifeDays = &average
+	}
+
+	return report
+}
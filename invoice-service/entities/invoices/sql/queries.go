@@ -16,15 +16,32 @@ var GetInvoiceByNumberQuery string
 //go:embed scripts/list_invoices.sql
 var ListInvoicesQuery string
 
+//go:embed scripts/list_invoices_by_supplier.sql
+var ListInvoicesBySupplierQuery string
+
 //go:embed scripts/update_invoice.sql
 var UpdateInvoiceQuery string
 
 //go:embed scripts/delete_invoice.sql
 var DeleteInvoiceQuery string
 
+//go:embed scripts/restore_invoice.sql
+var RestoreInvoiceQuery string
+
+//go:embed scripts/count_consumed_invoice_existences.sql
+var CountConsumedInvoiceExistencesQuery string
+
 //go:embed scripts/count_invoices.sql
 var CountInvoicesQuery string
 
+// Supplier report SQL queries
+//
+//go:embed scripts/list_supplier_report_invoices.sql
+var ListSupplierReportInvoicesQuery string
+
+//go:embed scripts/list_supplier_report_existences.sql
+var ListSupplierReportExistencesQuery string
+
 // Invoice Details SQL queries
 //
 //go:embed scripts/create_invoice_detail.sql
@@ -51,7 +68,25 @@ var GetInvoiceTotalFromDetailsQuery string
 //go:embed scripts/update_invoice_total.sql
 var UpdateInvoiceTotalQuery string
 
+//go:embed scripts/list_invoice_ids.sql
+var ListInvoiceIDsQuery string
+
+//go:embed scripts/lock_invoice_total.sql
+var LockInvoiceTotalQuery string
+
+//go:embed scripts/lock_active_invoice.sql
+var LockActiveInvoiceQuery string
+
+//go:embed scripts/lock_invoice_detail_for_receipt.sql
+var LockInvoiceDetailForReceiptQuery string
+
+//go:embed scripts/record_invoice_detail_receipt.sql
+var RecordInvoiceDetailReceiptQuery string
+
 // Existence SQL queries
 //
 //go:embed scripts/create_existence.sql
 var CreateExistenceQuery string
+
+//go:embed scripts/get_existence_pricing_defaults.sql
+var GetExistencePricingDefaultsQuery string
@@ -0,0 +1,5 @@
+SELECT COUNT(*)
+FROM existences e
+JOIN invoice_details d ON d.id = e.invoice_detail_id
+WHERE d.invoice_id = $1
+  AND e.units_available < e.units_purchased;
\ No newline at end of file
@@ -1 +1 @@
-SELECT COUNT(*) FROM invoice; 
\ No newline at end of file
+SELECT COUNT(*) FROM invoice WHERE deleted_at IS NULL; 
\ No newline at end of file
@@ -1,3 +1,3 @@
-INSERT INTO invoice_details (invoice_id, ingredient_id, detail, count, unit_type, price, expiration_date)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
-RETURNING id, invoice_id, ingredient_id, detail, count, unit_type, price, total, expiration_date, created_at, updated_at; 
\ No newline at end of file
+INSERT INTO invoice_details (invoice_id, ingredient_id, detail, count, unit_type, price, expiration_date, currency, received_count)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING id, invoice_id, ingredient_id, detail, count, unit_type, price, total, received_count, outstanding_count, expiration_date, currency, created_at, updated_at;
@@ -1 +1,4 @@
-DELETE FROM invoice WHERE id = $1; 
\ No newline at end of file
+UPDATE invoice
+SET deleted_at = CURRENT_TIMESTAMP,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND deleted_at IS NULL;
\ No newline at end of file
@@ -0,0 +1,3 @@
+SELECT config_key, config_value
+FROM system_configuration
+WHERE config_key IN ('income_margin_percentage', 'iva_percentage', 'service_tax_percentage');
\ No newline at end of file
@@ -1,3 +1,3 @@
-SELECT id, invoice_number, transaction_date, transaction_type, supplier_id, expense_category_id, total_amount, image_url, notes, created_at, updated_at
+SELECT id, invoice_number, transaction_date, transaction_type, supplier_id, expense_category_id, total_amount, image_url, notes, currency, tax_exempt, created_at, updated_at, deleted_at
 FROM invoice
 WHERE id = $1; 
\ No newline at end of file
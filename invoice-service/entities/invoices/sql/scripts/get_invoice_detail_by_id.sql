@@ -1,3 +1,3 @@
-SELECT id, invoice_id, ingredient_id, detail, count, unit_type, price, total, expiration_date, created_at, updated_at
+SELECT id, invoice_id, ingredient_id, detail, count, unit_type, price, total, received_count, outstanding_count, expiration_date, currency, created_at, updated_at
 FROM invoice_details
 WHERE id = $1; 
\ No newline at end of file
@@ -1,4 +1,4 @@
-SELECT id, invoice_id, ingredient_id, detail, count, unit_type, price, total, expiration_date, created_at, updated_at
+SELECT id, invoice_id, ingredient_id, detail, count, unit_type, price, total, received_count, outstanding_count, expiration_date, currency, created_at, updated_at
 FROM invoice_details
 WHERE invoice_id = $1
 ORDER BY created_at ASC; 
\ No newline at end of file
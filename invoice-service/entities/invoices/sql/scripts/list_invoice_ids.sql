@@ -0,0 +1,3 @@
+SELECT id
+FROM invoice
+ORDER BY created_at;
\ No newline at end of file
@@ -0,0 +1,5 @@
+SELECT id, invoice_number, transaction_date, transaction_type, supplier_id, expense_category_id, total_amount, image_url, notes, currency, tax_exempt, created_at, updated_at, deleted_at
+FROM invoice
+WHERE supplier_id = $1
+  AND ($2 OR deleted_at IS NULL)
+ORDER BY transaction_date DESC, created_at DESC;
\ No newline at end of file
@@ -0,0 +1,7 @@
+SELECT i.currency, i.transaction_date, e.expiration_date, e.units_purchased, e.units_available, e.remaining_value
+FROM existences e
+JOIN invoice_details d ON d.id = e.invoice_detail_id
+JOIN invoice i ON i.id = d.invoice_id
+WHERE i.supplier_id = $1
+  AND i.deleted_at IS NULL
+  AND i.transaction_date BETWEEN $2 AND $3;
\ No newline at end of file
@@ -0,0 +1,8 @@
+SELECT i.id, i.currency, COALESCE(i.total_amount, 0), COALESCE(SUM(d.count), 0), COALESCE(SUM(d.received_count), 0)
+FROM invoice i
+LEFT JOIN invoice_details d ON d.invoice_id = i.id
+WHERE i.supplier_id = $1
+  AND i.deleted_at IS NULL
+  AND i.transaction_date BETWEEN $2 AND $3
+GROUP BY i.id
+ORDER BY i.transaction_date, i.id;
\ No newline at end of file
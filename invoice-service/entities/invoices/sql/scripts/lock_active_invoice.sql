@@ -0,0 +1,4 @@
+SELECT id
+FROM invoice
+WHERE id = $1 AND deleted_at IS NULL
+FOR UPDATE;
\ No newline at end of file
@@ -0,0 +1,6 @@
+SELECT d.outstanding_count, i.tax_exempt, ec.category_name
+FROM invoice_details d
+JOIN invoice i ON i.id = d.invoice_id
+JOIN expense_categories ec ON ec.id = i.expense_category_id
+WHERE d.id = $1 AND d.invoice_id = $2 AND i.deleted_at IS NULL
+FOR UPDATE OF d;
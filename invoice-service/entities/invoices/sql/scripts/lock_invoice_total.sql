@@ -0,0 +1,4 @@
+SELECT total_amount
+FROM invoice
+WHERE id = $1
+FOR UPDATE;
\ No newline at end of file
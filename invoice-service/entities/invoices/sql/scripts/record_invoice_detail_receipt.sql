@@ -0,0 +1,5 @@
+UPDATE invoice_details 
+SET received_count = received_count + $2,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING id, invoice_id, ingredient_id, detail, count, unit_type, price, total, received_count, outstanding_count, expiration_date, currency, created_at, updated_at;
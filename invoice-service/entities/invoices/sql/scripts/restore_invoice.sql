@@ -0,0 +1,5 @@
+UPDATE invoice
+SET deleted_at = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND deleted_at IS NOT NULL
+RETURNING id, invoice_number, transaction_date, transaction_type, supplier_id, expense_category_id, total_amount, image_url, notes, currency, tax_exempt, created_at, updated_at, deleted_at;
\ No newline at end of file
@@ -7,5 +7,5 @@ SET invoice_number = COALESCE($2, invoice_number),
     image_url = COALESCE($7, image_url),
     notes = COALESCE($8, notes),
     updated_at = CURRENT_TIMESTAMP
-WHERE id = $1
-RETURNING id, invoice_number, transaction_date, transaction_type, supplier_id, expense_category_id, total_amount, image_url, notes, created_at, updated_at; 
\ No newline at end of file
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, invoice_number, transaction_date, transaction_type, supplier_id, expense_category_id, total_amount, image_url, notes, currency, tax_exempt, created_at, updated_at, deleted_at; 
\ No newline at end of file
@@ -7,4 +7,4 @@ SET ingredient_id = COALESCE($2, ingredient_id),
     expiration_date = COALESCE($7, expiration_date),
     updated_at = CURRENT_TIMESTAMP
 WHERE id = $1
-RETURNING id, invoice_id, ingredient_id, detail, count, unit_type, price, total, expiration_date, created_at, updated_at; 
\ No newline at end of file
+RETURNING id, invoice_id, ingredient_id, detail, count, unit_type, price, total, received_count, outstanding_count, expiration_date, currency, created_at, updated_at; 
\ No newline at end of file
@@ -0,0 +1,36 @@
+package events
+
+import (
+	"time"
+
+	"shared/eventbus"
+)
+
+// Invoice domain event types
+const (
+	InvoiceCreated        eventbus.Type = "invoice.created"
+	InvoiceUpdated        eventbus.Type = "invoice.updated"
+	InvoiceDeleted        eventbus.Type = "invoice.deleted"
+	InvoiceRestored       eventbus.Type = "invoice.restored"
+	InvoiceDetailCreated  eventbus.Type = "invoice.detail_created"
+	InvoiceDetailReceived eventbus.Type = "invoice.detail_received"
+)
+
+// InvoiceEvent describes a committed change to an invoice or one of its details
+type InvoiceEvent struct {
+	Kind          eventbus.Type `json:"type"`
+	InvoiceID     string        `json:"invoice_id"`
+	InvoiceNumber string        `json:"invoice_number,omitempty"`
+	DetailID      string        `json:"detail_id,omitempty"`
+	Timestamp     time.Time     `json:"timestamp"`
+}
+
+// EventType returns the kind of invoice change
+func (e InvoiceEvent) EventType() eventbus.Type {
+	return e.Kind
+}
+
+// OccurredAt returns when the change was committed
+func (e InvoiceEvent) OccurredAt() time.Time {
+	return e.Timestamp
+}
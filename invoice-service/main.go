@@ -12,20 +12,43 @@ import (
 	"time"
 
 	"invoice-service/config"
+	invoicesHandlers "invoice-service/entities/invoices/handlers"
+	invoicesModels "invoice-service/entities/invoices/models"
+	"shared/httpx"
+	"shared/pricing"
+	"shared/schema"
 
 	"github.com/gorilla/mux"
 	_ "github.com/lib/pq" // PostgreSQL driver
 	"github.com/sirupsen/logrus"
 )
 
+// requiredTables are the tables the service queries; startup fails when any is missing
+var requiredTables = []string{
+	"invoice",
+	"invoice_details",
+	"expense_categories",
+	"existences",
+	"system_configuration",
+}
+
 func main() {
 	// Load configuration
-	cfg := config.LoadConfig()
+	cfg, cfgErr := config.Load()
 
 	// Setup logger
 	logger := setupLogger(cfg.LogLevel)
+	if cfgErr != nil {
+		logger.WithError(cfgErr).Fatal("Invalid configuration")
+	}
 	logger.Info("Starting Ice Cream Store Invoice Service")
 
+	// Resolve existence price rounding before touching the database
+	priceRounding, err := pricing.RoundingStrategyByName(cfg.PriceRounding)
+	if err != nil {
+		logger.WithError(err).Fatal("Invalid price rounding strategy")
+	}
+
 	// Connect to database
 	db, err := connectToDatabase(cfg, logger)
 	if err != nil {
@@ -33,11 +56,36 @@ func main() {
 	}
 	defer db.Close()
 
+	// Fail fast with the missing table names instead of query errors on the first request
+	checkCtx, cancelCheck := context.WithTimeout(context.Background(), 10*time.Second)
+	err = schema.CheckRequiredTables(checkCtx, db, requiredTables)
+	cancelCheck()
+	if err != nil {
+		logger.WithError(err).Fatal("Database schema self-check failed")
+	}
+	logger.WithField("tables", len(requiredTables)).Info("Database schema self-check passed")
+
 	// Create main HTTP handler with all entity handlers
-	mainHandler := NewMainHttpHandler(db, logger)
+	mainHandler := NewMainHttpHandler(db, logger, priceRounding)
+	if cfg.HealthCheckDataService {
+		mainHandler.SetDataServiceHealthURL(cfg.DataServiceHealthURL)
+	}
+	mainHandler.SetConfig(cfg.Sanitized())
+	mainHandler.GetInvoicesHandler().SetCurrencyPolicy(invoicesModels.CurrencyPolicy{
+		Default:   cfg.DefaultCurrency,
+		Supported: cfg.SupportedCurrencies,
+	})
+
+	// Periodically correct invoice totals that drifted from their details
+	if cfg.TotalsReconcileInterval > 0 {
+		reconciler := invoicesHandlers.NewTotalsReconciler(invoicesHandlers.NewDBHandler(db, logger), cfg.TotalsReconcileInterval, logger)
+		go reconciler.Start()
+		defer reconciler.Stop()
+		logger.WithField("interval", cfg.TotalsReconcileInterval).Info("Invoice totals reconciliation scheduled")
+	}
 
 	// Setup HTTP router
-	router := setupRouter(mainHandler, logger)
+	router := setupRouter(mainHandler, cfg.RequestTimeout, cfg.RequestLogSampleRate, logger)
 
 	// Start HTTP server
 	server := &http.Server{
@@ -126,11 +174,26 @@ func connectToDatabase(cfg *config.Config, logger *logrus.Logger) (*sql.DB, erro
 }
 
 // setupRouter configures the HTTP router with all routes
-func setupRouter(mainHandler *MainHttpHandler, logger *logrus.Logger) *mux.Router {
+func setupRouter(mainHandler *MainHttpHandler, requestTimeout time.Duration, requestLogSampleRate int, logger *logrus.Logger) *mux.Router {
 	router := mux.NewRouter()
 
 	// Add logging middleware
-	router.Use(loggingMiddleware(logger))
+	router.Use(loggingMiddleware(logger, httpx.NewRequestLogSampler(requestLogSampleRate)))
+
+	// Answer handler panics with a JSON 500 instead of dropping the connection
+	router.Use(httpx.RecoveryMiddleware(logger))
+
+	// Compress large responses for clients that accept gzip
+	router.Use(httpx.GzipMiddleware(httpx.GzipMinSize))
+
+	// Indent JSON responses for ?pretty=true (before gzip sees the body)
+	router.Use(httpx.PrettyJSONMiddleware)
+
+	// Bound handler run time so a slow query cannot hold a connection
+	router.Use(httpx.TimeoutMiddleware(requestTimeout, nil))
+
+	// Write endpoints only accept JSON bodies
+	router.Use(httpx.RequireJSONMiddleware)
 
 	// CORS removed - gateway handles all CORS headers
 
@@ -171,6 +234,9 @@ func setupRouter(mainHandler *MainHttpHandler, logger *logrus.Logger) *mux.Route
 		w.Write(jsonData)
 	}).Methods("GET")
 
+	// GET /api/v1/config - Effective configuration with secrets redacted (admin role set by the gateway)
+	api.Handle("/config", httpx.RequireAdminMiddleware(http.HandlerFunc(mainHandler.GetConfig))).Methods("GET")
+
 	// Invoices routes (includes invoice details management)
 	invoicesRouter := api.PathPrefix("/invoices").Subrouter()
 	invoicesHandler := mainHandler.GetInvoicesHandler()
@@ -188,20 +254,30 @@ func setupRouter(mainHandler *MainHttpHandler, logger *logrus.Logger) *mux.Route
 
 	// Main invoice operations (MUST be after specific routes)
 	invoicesRouter.HandleFunc("", invoicesHandler.CreateInvoiceWithDetails).Methods("POST")
-	invoicesRouter.HandleFunc("", invoicesHandler.ListInvoices).Methods("GET")
+	invoicesRouter.HandleFunc("", invoicesHandler.ListInvoices).Methods("GET") // ?supplier_id= lists one supplier's invoices, ?include_deleted=true adds soft-deleted ones
 	invoicesRouter.HandleFunc("/{id}", invoicesHandler.GetInvoiceByID).Methods("GET")
+	invoicesRouter.HandleFunc("/{id}/full", invoicesHandler.GetInvoiceWithDetails).Methods("GET") // invoice with its details embedded
 	invoicesRouter.HandleFunc("/{id}", invoicesHandler.UpdateInvoice).Methods("PUT")
-	invoicesRouter.HandleFunc("/{id}", invoicesHandler.DeleteInvoice).Methods("DELETE")
+	invoicesRouter.HandleFunc("/{id}", invoicesHandler.PatchInvoice).Methods("PATCH")
+	invoicesRouter.HandleFunc("/{id}", invoicesHandler.DeleteInvoice).Methods("DELETE") // soft delete; 409 once derived existences are consumed
+	invoicesRouter.HandleFunc("/{id}/restore", invoicesHandler.RestoreInvoice).Methods("POST")
+	invoicesRouter.HandleFunc("/{id}/details/{detailId}/receipts", invoicesHandler.RecordInvoiceDetailReceipt).Methods("POST") // late delivery of an outstanding line; 409 when it exceeds what is outstanding
 	invoicesRouter.HandleFunc("/number/{number}", invoicesHandler.GetInvoiceByNumber).Methods("GET")
+	invoicesRouter.HandleFunc("/number/{number}/available", invoicesHandler.CheckInvoiceNumberAvailable).Methods("GET")
+	invoicesRouter.HandleFunc("/suppliers/{id}/report", invoicesHandler.GetSupplierReport).Methods("GET") // ?from=&to= (YYYY-MM-DD), defaults to the last 90 days
 
 	// Invoice details are managed through the main invoice APIs
 
+	// Unmatched routes get JSON 404/405 responses instead of mux's plain text
+	httpx.SetRouteErrorHandlers(router)
+
 	logger.Info("HTTP router configured successfully")
 	return router
 }
 
-// loggingMiddleware logs HTTP requests
-func loggingMiddleware(logger *logrus.Logger) func(http.Handler) http.Handler {
+// loggingMiddleware logs HTTP requests, including the gateway-supplied user when there is one.
+// sampler keeps every failed request and thins out the successful ones
+func loggingMiddleware(logger *logrus.Logger, sampler *httpx.RequestLogSampler) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
@@ -212,9 +288,12 @@ func loggingMiddleware(logger *logrus.Logger) func(http.Handler) http.Handler {
 			// Process request
 			next.ServeHTTP(wrappedWriter, r)
 
-			// Log request
+			// Log request, thinning out successful ones
+			if !sampler.ShouldLog(wrappedWriter.statusCode) {
+				return
+			}
 			duration := time.Since(start)
-			logger.WithFields(logrus.Fields{
+			logger.WithFields(httpx.RequestLogFields(r)).WithFields(logrus.Fields{
 				"method":      r.Method,
 				"uri":         r.RequestURI,
 				"status":      wrappedWriter.statusCode,
@@ -2,12 +2,16 @@ package main
 
 import (
 	"database/sql"
-	"fmt"
+	"encoding/json"
 	"net/http"
 	"time"
 
 	expenseCategoriesHandlers "invoice-service/entities/expense_categories/handlers"
 	invoicesHandlers "invoice-service/entities/invoices/handlers"
+	"shared/eventbus"
+	"shared/httpx"
+	"shared/pricing"
+	"shared/version"
 
 	"github.com/sirupsen/logrus"
 )
@@ -18,29 +22,73 @@ type MainHttpHandler struct {
 	db     *sql.DB
 	logger *logrus.Logger
 
+	// dataServiceHealthURL is checked by HealthCheck when set
+	dataServiceHealthURL string
+	httpClient           *http.Client
+
+	// settings is the sanitized configuration served by GetConfig
+	settings map[string]interface{}
+
+	// eventBus carries domain events published by the entity handlers
+	eventBus *eventbus.Bus
+
 	// Entity handlers
-	InvoicesHandler         *invoicesHandlers.HttpHandler
+	InvoicesHandler          *invoicesHandlers.HttpHandler
 	ExpenseCategoriesHandler *expenseCategoriesHandlers.HttpHandler
 }
 
-// NewMainHttpHandler creates a new main HTTP handler with all entity handlers
-func NewMainHttpHandler(db *sql.DB, logger *logrus.Logger) *MainHttpHandler {
+// NewMainHttpHandler creates a new main HTTP handler with all entity handlers.
+// priceRounding rounds the final price of existences created from invoices.
+func NewMainHttpHandler(db *sql.DB, logger *logrus.Logger, priceRounding pricing.RoundingStrategy) *MainHttpHandler {
 	// Initialize invoices handlers
 	invoicesDBHandler := invoicesHandlers.NewDBHandler(db, logger)
+	invoicesDBHandler.SetRoundingStrategy(priceRounding)
+	if err := invoicesDBHandler.LoadPricingDefaults(); err != nil {
+		logger.WithError(err).Warn("Using built-in existence pricing defaults")
+	}
 	invoicesHttpHandler := invoicesHandlers.NewHttpHandler(invoicesDBHandler, logger)
+	eventBus := eventbus.New(logger)
+	invoicesHttpHandler.SetEventBus(eventBus)
 
 	// Initialize expense categories handlers
 	expenseCategoriesDBHandler := expenseCategoriesHandlers.NewDBHandler(db, logger)
 	expenseCategoriesHttpHandler := expenseCategoriesHandlers.NewHttpHandler(expenseCategoriesDBHandler, logger)
 
 	return &MainHttpHandler{
-		db:                      db,
-		logger:                  logger,
-		InvoicesHandler:         invoicesHttpHandler,
+		db:                       db,
+		logger:                   logger,
+		InvoicesHandler:          invoicesHttpHandler,
 		ExpenseCategoriesHandler: expenseCategoriesHttpHandler,
+		httpClient:               &http.Client{Timeout: 5 * time.Second},
+		eventBus:                 eventBus,
 	}
 }
 
+// EventBus returns the bus the entity handlers publish domain events on
+func (h *MainHttpHandler) EventBus() *eventbus.Bus {
+	return h.eventBus
+}
+
+// SetDataServiceHealthURL makes HealthCheck depend on the data-service health endpoint; an empty URL disables the check
+func (h *MainHttpHandler) SetDataServiceHealthURL(url string) {
+	h.dataServiceHealthURL = url
+}
+
+// SetConfig sets the configuration GetConfig serves; callers pass config.Config.Sanitized() so no secret is kept
+func (h *MainHttpHandler) SetConfig(settings map[string]interface{}) {
+	h.settings = settings
+}
+
+// GetConfig returns the configuration the service loaded, with secrets redacted
+func (h *MainHttpHandler) GetConfig(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"service": "invoice-service",
+		"config":  h.settings,
+	})
+}
+
 // GetInvoicesHandler returns the invoices HTTP handler
 func (h *MainHttpHandler) GetInvoicesHandler() *invoicesHandlers.HttpHandler {
 	return h.InvoicesHandler
@@ -53,36 +101,39 @@ func (h *MainHttpHandler) GetExpenseCategoriesHandler() *expenseCategoriesHandle
 
 // HealthCheck provides a health check endpoint for the entire service
 func (h *MainHttpHandler) HealthCheck() map[string]interface{} {
-	// Check data-service health (which checks database connectivity)
-	client := &http.Client{
-		Timeout: 5 * time.Second,
-	}
-
-	resp, err := client.Get("http://localhost:8086/health")
-	if err != nil {
-		h.logger.WithError(err).Error("Failed to connect to data-service during health check")
+	if err := h.db.Ping(); err != nil {
+		h.logger.WithError(err).Error("Database ping failed during health check")
 		return map[string]interface{}{
 			"service": "invoice-service",
 			"status":  "unhealthy",
-			"message": "Data service connection failed",
+			"message": "Database connection failed",
 			"error":   err.Error(),
 		}
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		h.logger.WithField("status_code", resp.StatusCode).Error("Data service health check failed")
-		return map[string]interface{}{
-			"service": "invoice-service",
-			"status":  "unhealthy",
-			"message": "Data service is unhealthy",
-			"error":   fmt.Sprintf("Data service returned status %d", resp.StatusCode),
+	dependencies := map[string]string{}
+	if h.dataServiceHealthURL != "" {
+		if err := httpx.CheckHealth(h.httpClient, h.dataServiceHealthURL); err != nil {
+			h.logger.WithError(err).Error("Data service health check failed")
+			dependencies["data-service"] = "unhealthy"
+			return map[string]interface{}{
+				"service":      "invoice-service",
+				"status":       "unhealthy",
+				"message":      "Data service is unhealthy",
+				"error":        err.Error(),
+				"dependencies": dependencies,
+			}
 		}
+		dependencies["data-service"] = "healthy"
 	}
 
 	return map[string]interface{}{
-		"service": "invoice-service",
-		"status":  "healthy",
+		"service":      "invoice-service",
+		"status":       "healthy",
+		"version":      version.Version,
+		"build":        version.Info(),
+		"dependencies": dependencies,
+		"panics":       httpx.PanicCount(),
 		"entities": map[string]string{
 			"invoices":           "ready",
 			"expense_categories": "ready",
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"invoice-service/config"
+	"shared/httpx"
+	"shared/pricing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMainHttpHandlerHealthCheck tests the database ping and the optional data-service dependency
+func TestMainHttpHandlerHealthCheck(t *testing.T) {
+	healthyDataService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer healthyDataService.Close()
+
+	unreachableDataService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	unreachableURL := unreachableDataService.URL
+	unreachableDataService.Close()
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	testCases := map[string]struct {
+		dataServiceURL       string
+		pingErr              error
+		expectedStatus       string
+		expectedDependencies map[string]string
+	}{
+		"healthy without data-service check": {
+			expectedStatus:       "healthy",
+			expectedDependencies: map[string]string{},
+		},
+		"healthy data-service": {
+			dataServiceURL:       healthyDataService.URL,
+			expectedStatus:       "healthy",
+			expectedDependencies: map[string]string{"data-service": "healthy"},
+		},
+		"unreachable data-service": {
+			dataServiceURL:       unreachableURL,
+			expectedStatus:       "unhealthy",
+			expectedDependencies: map[string]string{"data-service": "unhealthy"},
+		},
+		"database ping failure": {
+			pingErr:        errors.New("connection refused"),
+			expectedStatus: "unhealthy",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+			require.NoError(t, err)
+			defer db.Close()
+
+			handler := NewMainHttpHandler(db, logger, pricing.NoRounding)
+			handler.SetDataServiceHealthURL(tc.dataServiceURL)
+			mock.ExpectPing().WillReturnError(tc.pingErr)
+
+			health := handler.HealthCheck()
+
+			assert.Equal(t, tc.expectedStatus, health["status"])
+			if tc.expectedStatus == "healthy" {
+				assert.Equal(t, httpx.PanicCount(), health["panics"])
+			}
+			if tc.expectedDependencies != nil {
+				assert.Equal(t, tc.expectedDependencies, health["dependencies"])
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// TestGetConfigRequiresAdmin tests that the configuration is served to admin roles only, with secrets redacted
+func TestGetConfigRequiresAdmin(t *testing.T) {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	cfg := config.LoadConfig()
+	cfg.DBPassword = "db-password-value"
+
+	handler := NewMainHttpHandler(db, logger, pricing.NoRounding)
+	handler.SetConfig(cfg.Sanitized())
+	router := setupRouter(handler, 0, 1, logger)
+
+	testCases := map[string]struct {
+		role           string
+		expectedStatus int
+	}{
+		"admin reads the config": {role: "admin", expectedStatus: http.StatusOK},
+		"cashier is forbidden":   {role: "cashier", expectedStatus: http.StatusForbidden},
+		"no role is forbidden":   {expectedStatus: http.StatusForbidden},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/config", nil)
+			if tc.role != "" {
+				req.Header.Set(httpx.UserRoleHeader, tc.role)
+			}
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectedStatus, rr.Code)
+			assert.NotContains(t, rr.Body.String(), "db-password-value")
+			if tc.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var response struct {
+				Config map[string]interface{} `json:"config"`
+			}
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+			assert.Equal(t, config.RedactedValue, response.Config["DB_PASSWORD"])
+			assert.Equal(t, "localhost", response.Config["DB_HOST"])
+		})
+	}
+}
@@ -3,8 +3,17 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
+
+	"shared/configcheck"
 )
 
+// OrderNumberResets are the accepted ORDER_NUMBER_RESET values
+var OrderNumberResets = []string{"daily", "never"}
+
+// DefaultCancellationReasons are the cancellation reasons accepted when CANCELLATION_REASONS is not set
+var DefaultCancellationReasons = []string{"customer_request", "out_of_stock", "error", "other"}
+
 type Config struct {
 	// Server configuration
 	ServerHost string
@@ -28,6 +37,35 @@ type Config struct {
 	DefaultTaxRate     float64
 	DefaultServiceRate float64
 	OrderTimeout       int // minutes
+	ReopenGracePeriod  int // minutes a cancelled order can still be reopened
+
+	// MaxDiscountPercentage caps an order's discount as a percentage of its subtotal
+	MaxDiscountPercentage float64
+
+	// MaxItemsPerOrder caps the line items of one order, bounding the inventory deductions it triggers; 0 disables the cap
+	MaxItemsPerOrder int
+
+	// MaxPageSize caps the limit a list request may ask for; larger limits are reduced and 0 disables the cap
+	MaxPageSize int
+
+	// Human-readable order numbers: OrderNumberReset is "daily" (20240301-0001) or "never" (a running count)
+	OrderNumberReset  string
+	OrderNumberDigits int
+
+	// CancellationReasons are the reasons a cancel request may give; "other" is recorded when none is given
+	CancellationReasons []string
+
+	// RequestTimeout bounds how long a request handler may run before the client gets a 503; 0 disables the limit
+	RequestTimeout int // seconds
+
+	// Health check dependencies
+	HealthCheckDataService bool
+	DataServiceHealthURL   string
+
+	// Outbox relay; an empty target URL delivers to the in-process event bus
+	OutboxTargetURL    string
+	OutboxPollInterval int // seconds
+	OutboxMaxAttempts  int
 }
 
 func LoadConfig() *Config {
@@ -51,12 +89,94 @@ func LoadConfig() *Config {
 		LogLevel: getEnv("LOG_LEVEL", "info"),
 
 		// Business
-		DefaultTaxRate:     getEnvFloat("DEFAULT_TAX_RATE", 13.0),     // 13% IVA
-		DefaultServiceRate: getEnvFloat("DEFAULT_SERVICE_RATE", 10.0), // 10% servicio
-		OrderTimeout:       getEnvInt("ORDER_TIMEOUT", 30),            // 30 minutes
+		DefaultTaxRate:     getEnvFloat("DEFAULT_TAX_RATE", 13.0),      // 13% IVA
+		DefaultServiceRate: getEnvFloat("DEFAULT_SERVICE_RATE", 10.0),  // 10% servicio
+		OrderTimeout:       getEnvInt("ORDER_TIMEOUT", 30),             // 30 minutes
+		ReopenGracePeriod:  getEnvInt("ORDER_REOPEN_GRACE_PERIOD", 15), // 15 minutes
+
+		MaxDiscountPercentage: getEnvFloat("MAX_DISCOUNT_PERCENTAGE", 50.0), // 50% of the subtotal
+		MaxItemsPerOrder:      getEnvInt("MAX_ITEMS_PER_ORDER", 100),
+		MaxPageSize:           getEnvInt("MAX_PAGE_SIZE", 100),
+		OrderNumberReset:      getEnv("ORDER_NUMBER_RESET", "daily"),
+		OrderNumberDigits:     getEnvInt("ORDER_NUMBER_DIGITS", 4),
+		CancellationReasons:   getEnvList("CANCELLATION_REASONS", DefaultCancellationReasons),
+
+		RequestTimeout: getEnvInt("REQUEST_TIMEOUT", 10), // 10 seconds
+
+		// Health check dependencies
+		HealthCheckDataService: getEnvBool("HEALTH_CHECK_DATA_SERVICE", true),
+		DataServiceHealthURL:   getEnv("DATA_SERVICE_HEALTH_URL", "http://localhost:8086/health"),
+
+		// Outbox relay
+		OutboxTargetURL:    getEnv("OUTBOX_TARGET_URL", ""),
+		OutboxPollInterval: getEnvInt("OUTBOX_POLL_INTERVAL", 5), // 5 seconds
+		OutboxMaxAttempts:  getEnvInt("OUTBOX_MAX_ATTEMPTS", 10),
 	}
 }
 
+// Load reads the configuration like LoadConfig and validates it. The Config is returned
+// even when validation fails so the caller can set up logging before exiting
+func Load() (*Config, error) {
+	cfg := LoadConfig()
+	return cfg, cfg.Validate()
+}
+
+// Validate reports every invalid setting, including environment values LoadConfig
+// could not parse and replaced with defaults
+func (c *Config) Validate() error {
+	v := &configcheck.Validator{}
+
+	v.Port("SERVER_PORT", c.ServerPort)
+	v.Port("DB_PORT", c.DBPort)
+	v.Required("DB_HOST", c.DBHost)
+	v.Required("DB_USER", c.DBUser)
+	v.Required("DB_PASSWORD", c.DBPassword)
+	v.Required("DB_NAME", c.DBName)
+	v.OneOf("DB_SSL_MODE", c.DBSSLMode, configcheck.SSLModes...)
+	v.Required("JWT_SECRET", c.JWTSecret)
+
+	v.EnvFloat("DEFAULT_TAX_RATE")
+	v.EnvFloat("DEFAULT_SERVICE_RATE")
+	v.EnvFloat("MAX_DISCOUNT_PERCENTAGE")
+	v.EnvInt("ORDER_TIMEOUT")
+	v.EnvInt("ORDER_REOPEN_GRACE_PERIOD")
+	v.EnvInt("MAX_ITEMS_PER_ORDER")
+	v.EnvInt("MAX_PAGE_SIZE")
+	v.EnvInt("ORDER_NUMBER_DIGITS")
+	v.EnvInt("REQUEST_TIMEOUT")
+	v.EnvBool("HEALTH_CHECK_DATA_SERVICE")
+	v.EnvInt("OUTBOX_POLL_INTERVAL")
+	v.EnvInt("OUTBOX_MAX_ATTEMPTS")
+
+	v.NonNegative("DEFAULT_TAX_RATE", c.DefaultTaxRate)
+	v.NonNegative("DEFAULT_SERVICE_RATE", c.DefaultServiceRate)
+	v.NonNegative("ORDER_REOPEN_GRACE_PERIOD", float64(c.ReopenGracePeriod))
+	v.NonNegative("MAX_ITEMS_PER_ORDER", float64(c.MaxItemsPerOrder))
+	v.NonNegative("MAX_PAGE_SIZE", float64(c.MaxPageSize))
+	v.NonNegative("REQUEST_TIMEOUT", float64(c.RequestTimeout))
+	if c.MaxDiscountPercentage < 0 || c.MaxDiscountPercentage > 100 {
+		v.Addf("MAX_DISCOUNT_PERCENTAGE must be between 0 and 100, got %v", c.MaxDiscountPercentage)
+	}
+	v.OneOf("ORDER_NUMBER_RESET", c.OrderNumberReset, OrderNumberResets...)
+	if c.OrderNumberDigits < 1 || c.OrderNumberDigits > 10 {
+		v.Addf("ORDER_NUMBER_DIGITS must be between 1 and 10, got %d", c.OrderNumberDigits)
+	}
+	if !containsString(c.CancellationReasons, "other") {
+		v.Addf("CANCELLATION_REASONS must include other, got %q", strings.Join(c.CancellationReasons, ","))
+	}
+	if c.OutboxPollInterval < 1 {
+		v.Addf("OUTBOX_POLL_INTERVAL must be at least 1 second, got %d", c.OutboxPollInterval)
+	}
+	if c.OutboxMaxAttempts < 1 {
+		v.Addf("OUTBOX_MAX_ATTEMPTS must be at least 1, got %d", c.OutboxMaxAttempts)
+	}
+	if c.HealthCheckDataService {
+		v.Required("DATA_SERVICE_HEALTH_URL", c.DataServiceHealthURL)
+	}
+
+	return v.Err()
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -64,6 +184,29 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getEnvList splits a comma-separated environment variable, dropping empty entries
+func getEnvList(key string, defaultValue []string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	if len(values) == 0 {
+		return defaultValue
+	}
+	return values
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
+
 func getEnvFloat(key string, defaultValue float64) float64 {
 	if value := os.Getenv(key); value != "" {
 		if parsed, err := strconv.ParseFloat(value, 64); err == nil {
@@ -81,3 +224,12 @@ func getEnvInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.ParseBool(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
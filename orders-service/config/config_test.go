@@ -4,7 +4,10 @@ import (
 	"os"
 	"testing"
 
+	"shared/configcheck"
+
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestLoadConfig tests the default configuration loading
@@ -27,6 +30,23 @@ func TestLoadConfig(t *testing.T) {
 	assert.Equal(t, 13.0, config.DefaultTaxRate)
 	assert.Equal(t, 10.0, config.DefaultServiceRate)
 	assert.Equal(t, 30, config.OrderTimeout)
+	assert.Equal(t, 15, config.ReopenGracePeriod)
+	assert.Equal(t, 50.0, config.MaxDiscountPercentage)
+	assert.Equal(t, 100, config.MaxItemsPerOrder)
+	assert.Equal(t, 100, config.MaxPageSize)
+	assert.Equal(t, "daily", config.OrderNumberReset)
+	assert.Equal(t, 4, config.OrderNumberDigits)
+	assert.Equal(t, []string{"customer_request", "out_of_stock", "error", "other"}, config.CancellationReasons)
+	assert.Equal(t, 10, config.RequestTimeout)
+
+	// Health check dependencies
+	assert.True(t, config.HealthCheckDataService)
+	assert.Equal(t, "http://localhost:8086/health", config.DataServiceHealthURL)
+
+	// Outbox relay
+	assert.Empty(t, config.OutboxTargetURL)
+	assert.Equal(t, 5, config.OutboxPollInterval)
+	assert.Equal(t, 10, config.OutboxMaxAttempts)
 }
 
 // TestGetEnv tests the getEnv helper function
@@ -46,3 +66,61 @@ func TestGetEnv(t *testing.T) {
 	result = getEnv("TEST_VAR", "default")
 	assert.Equal(t, "default", result)
 }
+
+// TestValidate tests that defaults pass and that invalid or missing settings are all reported together
+func TestValidate(t *testing.T) {
+	t.Run("defaults are valid", func(t *testing.T) {
+		assert.NoError(t, LoadConfig().Validate())
+	})
+
+	t.Run("invalid values are aggregated", func(t *testing.T) {
+		t.Setenv("SERVER_PORT", "-1")
+		t.Setenv("DB_SSL_MODE", "sometimes")
+		t.Setenv("ORDER_TIMEOUT", "soon")
+
+		_, err := Load()
+
+		var validationErr *configcheck.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, []string{
+			`SERVER_PORT must be a port between 1 and 65535, got "-1"`,
+			`DB_SSL_MODE must be one of disable, allow, prefer, require, verify-ca, verify-full, got "sometimes"`,
+			`ORDER_TIMEOUT must be an integer, got "soon"`,
+		}, validationErr.Problems)
+	})
+
+	t.Run("invalid order numbering", func(t *testing.T) {
+		t.Setenv("ORDER_NUMBER_RESET", "weekly")
+		t.Setenv("ORDER_NUMBER_DIGITS", "12")
+
+		_, err := Load()
+
+		var validationErr *configcheck.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, []string{
+			`ORDER_NUMBER_RESET must be one of daily, never, got "weekly"`,
+			`ORDER_NUMBER_DIGITS must be between 1 and 10, got 12`,
+		}, validationErr.Problems)
+	})
+
+	t.Run("cancellation reasons without other", func(t *testing.T) {
+		t.Setenv("CANCELLATION_REASONS", "customer_request, error")
+
+		cfg, err := Load()
+
+		assert.Equal(t, []string{"customer_request", "error"}, cfg.CancellationReasons)
+		var validationErr *configcheck.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, []string{`CANCELLATION_REASONS must include other, got "customer_request,error"`}, validationErr.Problems)
+	})
+
+	t.Run("missing required secret", func(t *testing.T) {
+		cfg := LoadConfig()
+		cfg.JWTSecret = ""
+		cfg.DBPassword = ""
+
+		var validationErr *configcheck.ValidationError
+		require.ErrorAs(t, cfg.Validate(), &validationErr)
+		assert.Equal(t, []string{"DB_PASSWORD is required", "JWT_SECRET is required"}, validationErr.Problems)
+	})
+}
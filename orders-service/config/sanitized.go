@@ -0,0 +1,52 @@
+package config
+
+// RedactedValue replaces secrets in the sanitized configuration
+const RedactedValue = "[REDACTED]"
+
+// Sanitized returns the effective configuration keyed by the environment variable that sets
+// each value, with secrets redacted so it can be shown to operators. An unset secret stays
+// empty rather than redacted, so a missing secret is still visible
+func (c *Config) Sanitized() map[string]interface{} {
+	return map[string]interface{}{
+		"SERVER_HOST": c.ServerHost,
+		"SERVER_PORT": c.ServerPort,
+
+		"DB_HOST":     c.DBHost,
+		"DB_PORT":     c.DBPort,
+		"DB_USER":     c.DBUser,
+		"DB_PASSWORD": redact(c.DBPassword),
+		"DB_NAME":     c.DBName,
+		"DB_SSL_MODE": c.DBSSLMode,
+
+		"JWT_SECRET": redact(c.JWTSecret),
+
+		"LOG_LEVEL": c.LogLevel,
+
+		"DEFAULT_TAX_RATE":          c.DefaultTaxRate,
+		"DEFAULT_SERVICE_RATE":      c.DefaultServiceRate,
+		"ORDER_TIMEOUT":             c.OrderTimeout,
+		"ORDER_REOPEN_GRACE_PERIOD": c.ReopenGracePeriod,
+		"MAX_DISCOUNT_PERCENTAGE":   c.MaxDiscountPercentage,
+		"MAX_ITEMS_PER_ORDER":       c.MaxItemsPerOrder,
+		"MAX_PAGE_SIZE":             c.MaxPageSize,
+		"ORDER_NUMBER_RESET":        c.OrderNumberReset,
+		"ORDER_NUMBER_DIGITS":       c.OrderNumberDigits,
+		"CANCELLATION_REASONS":      c.CancellationReasons,
+		"REQUEST_TIMEOUT":           c.RequestTimeout,
+
+		"HEALTH_CHECK_DATA_SERVICE": c.HealthCheckDataService,
+		"DATA_SERVICE_HEALTH_URL":   c.DataServiceHealthURL,
+
+		"OUTBOX_TARGET_URL":    c.OutboxTargetURL,
+		"OUTBOX_POLL_INTERVAL": c.OutboxPollInterval,
+		"OUTBOX_MAX_ATTEMPTS":  c.OutboxMaxAttempts,
+	}
+}
+
+// redact hides a secret's value while keeping whether it is set
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return RedactedValue
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSanitized tests that secrets are redacted and every other setting is reported as loaded
+func TestSanitized(t *testing.T) {
+	cfg := LoadConfig()
+	cfg.DBPassword = "db-password-value"
+	cfg.JWTSecret = "jwt-secret-value"
+
+	settings := cfg.Sanitized()
+
+	assert.Equal(t, RedactedValue, settings["DB_PASSWORD"])
+	assert.Equal(t, RedactedValue, settings["JWT_SECRET"])
+	assert.Equal(t, "localhost", settings["DB_HOST"])
+	assert.Equal(t, "8083", settings["SERVER_PORT"])
+	assert.Equal(t, 13.0, settings["DEFAULT_TAX_RATE"])
+	assert.Equal(t, "daily", settings["ORDER_NUMBER_RESET"])
+	assert.Equal(t, cfg.CancellationReasons, settings["CANCELLATION_REASONS"])
+
+	// The secrets must not leak anywhere in the encoded response
+	encoded, err := json.Marshal(settings)
+	require.NoError(t, err)
+	assert.NotContains(t, string(encoded), "db-password-value")
+	assert.NotContains(t, string(encoded), "jwt-secret-value")
+}
+
+// TestSanitizedUnsetSecret tests that an unset secret is reported empty rather than redacted
+func TestSanitizedUnsetSecret(t *testing.T) {
+	cfg := LoadConfig()
+	cfg.JWTSecret = ""
+
+	assert.Equal(t, "", cfg.Sanitized()["JWT_SECRET"])
+}
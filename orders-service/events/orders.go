@@ -0,0 +1,50 @@
+package events
+
+import (
+	"time"
+
+	"shared/eventbus"
+
+	"github.com/google/uuid"
+)
+
+// Order status change actions
+const (
+	ActionCreated   = "created"
+	ActionUpdated   = "updated"
+	ActionCancelled = "cancelled"
+	ActionReopened  = "reopened"
+	ActionCompleted = "completed"
+)
+
+// Order domain event types, one per status change action
+const (
+	OrderCreated   eventbus.Type = "order." + ActionCreated
+	OrderUpdated   eventbus.Type = "order." + ActionUpdated
+	OrderCancelled eventbus.Type = "order." + ActionCancelled
+	OrderReopened  eventbus.Type = "order." + ActionReopened
+
+	// OrderCompleted is relayed through the outbox rather than published directly
+	OrderCompleted eventbus.Type = "order." + ActionCompleted
+)
+
+// OrderEventTypes lists every order domain event type
+var OrderEventTypes = []eventbus.Type{OrderCreated, OrderUpdated, OrderCancelled, OrderReopened}
+
+// OrderStatusEvent describes a change to an order's status
+type OrderStatusEvent struct {
+	OrderID   uuid.UUID `json:"order_id"`
+	Status    string    `json:"status"`
+	Action    string    `json:"action"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// EventType returns the domain event type for the event's action
+func (e OrderStatusEvent) EventType() eventbus.Type {
+	return eventbus.Type("order." + e.Action)
+}
+
+// OccurredAt returns when the status change happened
+func (e OrderStatusEvent) OccurredAt() time.Time {
+	return e.Timestamp
+}
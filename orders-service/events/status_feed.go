@@ -0,0 +1,106 @@
+package events
+
+import (
+	"sync"
+	"time"
+
+	"shared/eventbus"
+
+	"github.com/google/uuid"
+)
+
+// subscriberBuffer is how many events a subscriber can fall behind before events are dropped
+const subscriberBuffer = 16
+
+// Subscription receives the order status events it is interested in
+type Subscription struct {
+	orderID *uuid.UUID
+	events  chan OrderStatusEvent
+}
+
+// Events returns the channel the subscription's events are delivered on
+func (s *Subscription) Events() <-chan OrderStatusEvent {
+	return s.events
+}
+
+// matches reports whether the subscription wants the given event
+func (s *Subscription) matches(event OrderStatusEvent) bool {
+	return s.orderID == nil || *s.orderID == event.OrderID
+}
+
+// StatusFeed fans order status events out to live subscribers such as WebSocket clients
+type StatusFeed struct {
+	mu          sync.RWMutex
+	subscribers map[*Subscription]struct{}
+}
+
+// NewStatusFeed creates a new order status feed
+func NewStatusFeed() *StatusFeed {
+	return &StatusFeed{
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+// Subscribe registers a subscriber; a nil orderID receives events for every order
+func (f *StatusFeed) Subscribe(orderID *uuid.UUID) *Subscription {
+	sub := &Subscription{
+		orderID: orderID,
+		events:  make(chan OrderStatusEvent, subscriberBuffer),
+	}
+
+	f.mu.Lock()
+	f.subscribers[sub] = struct{}{}
+	f.mu.Unlock()
+
+	return sub
+}
+
+// Unsubscribe removes a subscriber and closes its events channel
+func (f *StatusFeed) Unsubscribe(sub *Subscription) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.subscribers[sub]; ok {
+		delete(f.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+// Follow feeds every order domain event published on the bus to the feed's subscribers
+func (f *StatusFeed) Follow(bus *eventbus.Bus) {
+	for _, eventType := range OrderEventTypes {
+		bus.Subscribe(eventType, func(event eventbus.Event) {
+			if statusEvent, ok := event.(OrderStatusEvent); ok {
+				f.Publish(statusEvent)
+			}
+		})
+	}
+}
+
+// SubscriberCount returns the number of active subscribers
+func (f *StatusFeed) SubscriberCount() int {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return len(f.subscribers)
+}
+
+// Publish delivers an event to every matching subscriber without blocking;
+// subscribers whose buffer is full miss the event
+func (f *StatusFeed) Publish(event OrderStatusEvent) {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	for sub := range f.subscribers {
+		if !sub.matches(event) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+		}
+	}
+}
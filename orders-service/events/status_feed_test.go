@@ -0,0 +1,76 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestStatusFeedPublish tests delivery to matching subscribers
+func TestStatusFeedPublish(t *testing.T) {
+	orderID := uuid.New()
+	otherID := uuid.New()
+
+	tests := map[string]struct {
+		filter   *uuid.UUID
+		expected []uuid.UUID
+	}{
+		"unfiltered subscriber receives every order": {
+			filter:   nil,
+			expected: []uuid.UUID{orderID, otherID},
+		},
+		"filtered subscriber receives only its order": {
+			filter:   &orderID,
+			expected: []uuid.UUID{orderID},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			feed := NewStatusFeed()
+			sub := feed.Subscribe(tc.filter)
+			defer feed.Unsubscribe(sub)
+
+			feed.Publish(OrderStatusEvent{OrderID: orderID, Status: "completed", Action: ActionUpdated})
+			feed.Publish(OrderStatusEvent{OrderID: otherID, Status: "cancelled", Action: ActionCancelled})
+
+			var received []uuid.UUID
+			for len(received) < len(tc.expected) {
+				event := <-sub.Events()
+				assert.False(t, event.Timestamp.IsZero())
+				received = append(received, event.OrderID)
+			}
+			assert.Equal(t, tc.expected, received)
+			assert.Empty(t, sub.Events())
+		})
+	}
+}
+
+// TestStatusFeedPublishDoesNotBlock tests that a subscriber that stops reading does not block publishers
+func TestStatusFeedPublishDoesNotBlock(t *testing.T) {
+	feed := NewStatusFeed()
+	sub := feed.Subscribe(nil)
+
+	for i := 0; i < subscriberBuffer*2; i++ {
+		feed.Publish(OrderStatusEvent{OrderID: uuid.New(), Status: "pending", Action: ActionCreated})
+	}
+
+	assert.Len(t, sub.Events(), subscriberBuffer)
+}
+
+// TestStatusFeedUnsubscribe tests that unsubscribing closes the channel and stops delivery
+func TestStatusFeedUnsubscribe(t *testing.T) {
+	feed := NewStatusFeed()
+	sub := feed.Subscribe(nil)
+	require.Equal(t, 1, feed.SubscriberCount())
+
+	feed.Unsubscribe(sub)
+	feed.Unsubscribe(sub)
+	feed.Publish(OrderStatusEvent{OrderID: uuid.New(), Status: "pending", Action: ActionCreated})
+
+	_, ok := <-sub.Events()
+	assert.False(t, ok)
+	assert.Equal(t, 0, feed.SubscriberCount())
+}
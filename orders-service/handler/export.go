@@ -0,0 +1,225 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"orders-service/models"
+)
+
+// exportOrderColumns are the CSV columns written for every order
+var exportOrderColumns = []string{
+	"order_id", "order_date", "order_status", "payment_method",
+	"subtotal", "tax", "service", "discount", "total",
+}
+
+// exportItemColumns are appended to exportOrderColumns when line items are included
+var exportItemColumns = []string{
+	"item_id", "recipe_id", "quantity", "unit_price", "item_total",
+}
+
+// ExportOrders streams the orders placed in a date range as CSV or JSON lines
+// Query parameters: from and to (YYYY-MM-DD, inclusive), format (csv or json, default csv), include_items
+func (h *ordersHandler) ExportOrders(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	format := query.Get("format")
+	if format == "" {
+		format = models.ExportFormatCSV
+	}
+	if format != models.ExportFormatCSV && format != models.ExportFormatJSON {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid format, use csv or json", nil)
+		return
+	}
+
+	if query.Get("from") == "" || query.Get("to") == "" {
+		h.respondWithError(w, http.StatusBadRequest, "from and to are required", nil)
+		return
+	}
+	from, err := time.Parse("2006-01-02", query.Get("from"))
+	if err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid from format, use YYYY-MM-DD", err)
+		return
+	}
+	to, err := time.Parse("2006-01-02", query.Get("to"))
+	if err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid to format, use YYYY-MM-DD", err)
+		return
+	}
+	if to.Before(from) {
+		h.respondWithError(w, http.StatusBadRequest, "to must not be before from", nil)
+		return
+	}
+	// Set to end of day
+	to = to.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
+
+	includeItems := false
+	if raw := query.Get("include_items"); raw != "" {
+		includeItems, err = strconv.ParseBool(raw)
+		if err != nil {
+			h.respondWithError(w, http.StatusBadRequest, "Invalid include_items", err)
+			return
+		}
+	}
+
+	filename := fmt.Sprintf("orders_%s_%s", query.Get("from"), query.Get("to"))
+	var writer exportWriter
+	if format == models.ExportFormatCSV {
+		writer = &csvExportWriter{w: w, filename: filename + ".csv", includeItems: includeItems}
+	} else {
+		writer = &jsonExportWriter{w: w, filename: filename + ".jsonl"}
+	}
+
+	count := 0
+	err = h.repo.StreamOrdersForExport(r.Context(), from, to, includeItems, func(order models.Order, items []models.OrderedRecipe) error {
+		count++
+		return writer.Write(models.NewOrderExport(order, items))
+	})
+	if err != nil {
+		// Once rows are on the wire the status is already sent, so the error can only be logged
+		if !writer.Started() {
+			h.respondWithError(w, http.StatusInternalServerError, "Failed to export orders", err)
+			return
+		}
+		h.logger.WithError(err).Error("Order export aborted mid-stream")
+		return
+	}
+
+	if err := writer.Close(); err != nil {
+		h.logger.WithError(err).Error("Failed to finish order export")
+		return
+	}
+
+	h.logger.WithField("orders", count).Info("Orders exported")
+}
+
+// exportWriter writes export records to a response, sending headers with the first write
+type exportWriter interface {
+	Write(record models.OrderExport) error
+	Started() bool
+	Close() error
+}
+
+// csvExportWriter writes one row per order, or one row per line item when items are included
+type csvExportWriter struct {
+	w            http.ResponseWriter
+	filename     string
+	includeItems bool
+	csv          *csv.Writer
+}
+
+func (e *csvExportWriter) start() error {
+	if e.csv != nil {
+		return nil
+	}
+
+	e.w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	e.w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", e.filename))
+	e.w.WriteHeader(http.StatusOK)
+
+	e.csv = csv.NewWriter(e.w)
+	header := exportOrderColumns
+	if e.includeItems {
+		header = append(append([]string{}, exportOrderColumns...), exportItemColumns...)
+	}
+	return e.csv.Write(header)
+}
+
+func (e *csvExportWriter) Write(record models.OrderExport) error {
+	if err := e.start(); err != nil {
+		return err
+	}
+
+	row := []string{
+		record.OrderID.String(),
+		record.OrderDate.Format(time.RFC3339),
+		record.OrderStatus,
+		record.PaymentMethod,
+		formatExportAmount(record.Subtotal),
+		formatExportAmount(record.Tax),
+		formatExportAmount(record.Service),
+		formatExportAmount(record.Discount),
+		formatExportAmount(record.Total),
+	}
+
+	if !e.includeItems {
+		if err := e.csv.Write(row); err != nil {
+			return err
+		}
+	} else if len(record.Items) == 0 {
+		if err := e.csv.Write(append(row, make([]string, len(exportItemColumns))...)); err != nil {
+			return err
+		}
+	} else {
+		for _, item := range record.Items {
+			itemRow := append(append([]string{}, row...),
+				item.ID.String(),
+				item.RecipeID.String(),
+				strconv.Itoa(item.Quantity),
+				formatExportAmount(item.UnitPrice),
+				formatExportAmount(item.TotalPrice),
+			)
+			if err := e.csv.Write(itemRow); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Flush per order so rows reach the client instead of accumulating in the writer
+	e.csv.Flush()
+	return e.csv.Error()
+}
+
+func (e *csvExportWriter) Started() bool {
+	return e.csv != nil
+}
+
+func (e *csvExportWriter) Close() error {
+	// An empty range still gets the header row
+	if err := e.start(); err != nil {
+		return err
+	}
+	e.csv.Flush()
+	return e.csv.Error()
+}
+
+// jsonExportWriter writes one JSON object per line
+type jsonExportWriter struct {
+	w        http.ResponseWriter
+	filename string
+	encoder  *json.Encoder
+}
+
+func (e *jsonExportWriter) start() {
+	if e.encoder != nil {
+		return
+	}
+
+	e.w.Header().Set("Content-Type", "application/x-ndjson")
+	e.w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", e.filename))
+	e.w.WriteHeader(http.StatusOK)
+	e.encoder = json.NewEncoder(e.w)
+}
+
+func (e *jsonExportWriter) Write(record models.OrderExport) error {
+	e.start()
+	return e.encoder.Encode(record)
+}
+
+func (e *jsonExportWriter) Started() bool {
+	return e.encoder != nil
+}
+
+func (e *jsonExportWriter) Close() error {
+	e.start()
+	return nil
+}
+
+// formatExportAmount formats a currency amount with two decimals
+func formatExportAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"orders-service/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seedExportOrders stores three orders on 2024-03-10 and 2024-03-11 and one outside the range
+func seedExportOrders(mockRepo *mockOrderRepository) []uuid.UUID {
+	dates := []time.Time{
+		time.Date(2024, 3, 10, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 10, 18, 30, 0, 0, time.UTC),
+		time.Date(2024, 3, 11, 12, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 12, 12, 0, 0, 0, time.UTC),
+	}
+
+	ids := make([]uuid.UUID, 0, len(dates))
+	for _, date := range dates {
+		order := &models.Order{
+			ID:             uuid.New(),
+			OrderDate:      date,
+			TotalAmount:    100.0,
+			TaxAmount:      13.0,
+			ServiceAmount:  10.0,
+			DiscountAmount: 5.0,
+			FinalAmount:    118.0,
+			PaymentMethod:  "cash",
+			OrderStatus:    models.OrderStatusCompleted,
+		}
+		mockRepo.orders[order.ID] = order
+		mockRepo.orderedRecipes[order.ID] = []models.OrderedRecipe{
+			{ID: uuid.New(), OrderID: order.ID, RecipeID: uuid.New(), Quantity: 2, UnitPrice: 25.0, TotalPrice: 50.0},
+			{ID: uuid.New(), OrderID: order.ID, RecipeID: uuid.New(), Quantity: 1, UnitPrice: 50.0, TotalPrice: 50.0},
+		}
+		ids = append(ids, order.ID)
+	}
+	return ids
+}
+
+// TestExportOrders tests the CSV header, one row per order in range, item rows, and JSON lines output
+func TestExportOrders(t *testing.T) {
+	t.Run("csv has a header and one row per order", func(t *testing.T) {
+		handler, mockRepo := setupTestHandler()
+		ids := seedExportOrders(mockRepo)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/orders/export?format=csv&from=2024-03-10&to=2024-03-11", nil)
+		rr := httptest.NewRecorder()
+
+		handler.ExportOrders(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", rr.Header().Get("Content-Type"))
+		assert.Contains(t, rr.Header().Get("Content-Disposition"), "orders_2024-03-10_2024-03-11.csv")
+
+		records, err := csv.NewReader(rr.Body).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 4)
+		assert.Equal(t, []string{"order_id", "order_date", "order_status", "payment_method", "subtotal", "tax", "service", "discount", "total"}, records[0])
+
+		for i, record := range records[1:] {
+			assert.Equal(t, ids[i].String(), record[0], "orders should be oldest first")
+			assert.Equal(t, []string{"100.00", "13.00", "10.00", "5.00", "118.00"}, record[4:])
+		}
+	})
+
+	t.Run("csv with items has one row per line item", func(t *testing.T) {
+		handler, mockRepo := setupTestHandler()
+		seedExportOrders(mockRepo)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/orders/export?from=2024-03-10&to=2024-03-10&include_items=true", nil)
+		rr := httptest.NewRecorder()
+
+		handler.ExportOrders(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		records, err := csv.NewReader(rr.Body).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 5)
+		assert.Equal(t, "item_total", records[0][len(records[0])-1])
+		assert.Equal(t, "2", records[1][11])
+	})
+
+	t.Run("json writes one object per line", func(t *testing.T) {
+		handler, mockRepo := setupTestHandler()
+		ids := seedExportOrders(mockRepo)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/orders/export?format=json&from=2024-03-10&to=2024-03-11", nil)
+		rr := httptest.NewRecorder()
+
+		handler.ExportOrders(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "application/x-ndjson", rr.Header().Get("Content-Type"))
+
+		scanner := bufio.NewScanner(rr.Body)
+		var lines []models.OrderExport
+		for scanner.Scan() {
+			var record models.OrderExport
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+			lines = append(lines, record)
+		}
+		require.Len(t, lines, 3)
+		assert.Equal(t, ids[0], lines[0].OrderID)
+		assert.InDelta(t, 10.0, lines[0].Service, 0.001)
+		assert.InDelta(t, 118.0, lines[0].Total, 0.001)
+		assert.Empty(t, lines[0].Items)
+	})
+
+	t.Run("empty range still writes the header", func(t *testing.T) {
+		handler, _ := setupTestHandler()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/orders/export?from=2024-01-01&to=2024-01-31", nil)
+		rr := httptest.NewRecorder()
+
+		handler.ExportOrders(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, strings.Join(exportOrderColumns, ",")+"\n", rr.Body.String())
+	})
+
+	invalid := map[string]string{
+		"missing range":  "?format=csv",
+		"unknown format": "?format=xml&from=2024-03-10&to=2024-03-11",
+		"bad date":       "?from=10-03-2024&to=2024-03-11",
+		"reversed range": "?from=2024-03-11&to=2024-03-10",
+	}
+	for name, query := range invalid {
+		t.Run(name, func(t *testing.T) {
+			handler, _ := setupTestHandler()
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/orders/export"+query, nil)
+			rr := httptest.NewRecorder()
+
+			handler.ExportOrders(rr, req)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+		})
+	}
+
+	t.Run("repository error before any rows", func(t *testing.T) {
+		handler, mockRepo := setupTestHandler()
+		mockRepo.shouldError = true
+		mockRepo.errorMessage = "database error"
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/orders/export?from=2024-03-10&to=2024-03-11", nil)
+		rr := httptest.NewRecorder()
+
+		handler.ExportOrders(rr, req)
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+}
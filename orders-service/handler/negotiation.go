@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Response representation versions a client can request through the Accept header
+const (
+	APIVersion1 = 1
+	APIVersion2 = 2
+)
+
+// vendorMediaTypePrefix and vendorMediaTypeSuffix wrap the version number in
+// application/vnd.icecream.v<N>+json
+const (
+	vendorMediaTypePrefix = "application/vnd.icecream.v"
+	vendorMediaTypeSuffix = "+json"
+)
+
+// errUnsupportedVersion is returned when the Accept header only names vendor versions the endpoint cannot produce
+var errUnsupportedVersion = errors.New("unsupported API version")
+
+// vendorMediaType returns the Content-Type used for a versioned response
+func vendorMediaType(version int) string {
+	return vendorMediaTypePrefix + strconv.Itoa(version) + vendorMediaTypeSuffix
+}
+
+// negotiateVersion picks the response version requested in the Accept header. The first
+// supported vendor media type listed wins; a missing header, plain JSON or a wildcard
+// selects v1 so existing clients keep today's shapes. A q of 0 refuses a media type
+func negotiateVersion(r *http.Request, supported ...int) (int, error) {
+	header := r.Header.Get("Accept")
+	if strings.TrimSpace(header) == "" {
+		return APIVersion1, nil
+	}
+
+	acceptsDefault := false
+	var requested []string
+	for _, part := range strings.Split(header, ",") {
+		mediaType, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+		if refusesMediaType(params) {
+			continue
+		}
+
+		versionText, ok := strings.CutPrefix(mediaType, vendorMediaTypePrefix)
+		if !ok {
+			switch mediaType {
+			case "application/json", "application/*", "*/*":
+				acceptsDefault = true
+			}
+			continue
+		}
+
+		versionText, ok = strings.CutSuffix(versionText, vendorMediaTypeSuffix)
+		version, err := strconv.Atoi(versionText)
+		if !ok || err != nil {
+			requested = append(requested, mediaType)
+			continue
+		}
+		for _, candidate := range supported {
+			if candidate == version {
+				return version, nil
+			}
+		}
+		requested = append(requested, mediaType)
+	}
+
+	if acceptsDefault || len(requested) == 0 {
+		return APIVersion1, nil
+	}
+	return 0, fmt.Errorf("%w: %s", errUnsupportedVersion, strings.Join(requested, ", "))
+}
+
+// refusesMediaType reports whether Accept parameters carry q=0
+func refusesMediaType(params string) bool {
+	for _, param := range strings.Split(params, ";") {
+		if value, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+			if q, err := strconv.ParseFloat(value, 64); err == nil && q == 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// respondWithVersion writes a success response in the negotiated representation. v1 keeps the
+// plain application/json Content-Type; later versions answer with their vendor media type
+func (h *ordersHandler) respondWithVersion(w http.ResponseWriter, status int, version int, message string, data interface{}) {
+	w.Header().Add("Vary", "Accept")
+	if version == APIVersion1 {
+		h.respondWithSuccess(w, status, message, data)
+		return
+	}
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": message,
+		"data":    data,
+	}
+
+	w.Header().Set("Content-Type", vendorMediaType(version))
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
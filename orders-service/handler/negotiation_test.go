@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNegotiateVersion tests picking a response version from the Accept header
+func TestNegotiateVersion(t *testing.T) {
+	testCases := map[string]struct {
+		accept          string
+		expectedVersion int
+		expectedError   bool
+	}{
+		"no accept header":          {accept: "", expectedVersion: APIVersion1},
+		"plain json":                {accept: "application/json", expectedVersion: APIVersion1},
+		"wildcard":                  {accept: "*/*", expectedVersion: APIVersion1},
+		"explicit v1":               {accept: "application/vnd.icecream.v1+json", expectedVersion: APIVersion1},
+		"explicit v2":               {accept: "application/vnd.icecream.v2+json", expectedVersion: APIVersion2},
+		"case insensitive":          {accept: "Application/VND.icecream.V2+JSON", expectedVersion: APIVersion2},
+		"v2 with parameters":        {accept: "application/vnd.icecream.v2+json; charset=utf-8", expectedVersion: APIVersion2},
+		"first supported wins":      {accept: "application/vnd.icecream.v9+json, application/vnd.icecream.v2+json, application/json", expectedVersion: APIVersion2},
+		"refused v2 falls back":     {accept: "application/vnd.icecream.v2+json;q=0, application/json", expectedVersion: APIVersion1},
+		"unsupported with fallback": {accept: "application/vnd.icecream.v9+json, */*;q=0.1", expectedVersion: APIVersion1},
+		"unsupported only":          {accept: "application/vnd.icecream.v9+json", expectedError: true},
+		"malformed version":         {accept: "application/vnd.icecream.vtwo+json", expectedError: true},
+		"unrelated type":            {accept: "text/html", expectedVersion: APIVersion1},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/orders/1", nil)
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+
+			version, err := negotiateVersion(req, APIVersion1, APIVersion2)
+
+			if tc.expectedError {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, errUnsupportedVersion)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedVersion, version)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"orders-service/events"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// wsWriteTimeout bounds how long a single push to a client may take
+	wsWriteTimeout = 10 * time.Second
+	// wsPongTimeout is how long a client may stay silent before it is dropped
+	wsPongTimeout = 60 * time.Second
+	// wsPingInterval must be shorter than wsPongTimeout
+	wsPingInterval = (wsPongTimeout * 9) / 10
+)
+
+// upgrader accepts any origin; the gateway in front of the service handles CORS
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin:     func(r *http.Request) bool { return true },
+}
+
+// OrderUpdates streams order status changes to a WebSocket client,
+// optionally limited to a single order via the order_id query parameter
+func (h *ordersHandler) OrderUpdates(w http.ResponseWriter, r *http.Request) {
+	var orderID *uuid.UUID
+	orderIDStr := r.URL.Query().Get("order_id")
+	if orderIDStr != "" {
+		id, err := uuid.Parse(orderIDStr)
+		if err != nil {
+			h.respondWithError(w, http.StatusBadRequest, "Invalid order ID", err)
+			return
+		}
+		orderID = &id
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already written an error response
+		h.logger.WithError(err).Warn("Failed to upgrade order updates connection")
+		return
+	}
+	defer conn.Close()
+
+	sub := h.updates.Subscribe(orderID)
+	defer h.updates.Unsubscribe(sub)
+
+	h.logger.WithField("order_id", orderIDStr).Debug("Order updates client connected")
+
+	// Clients only send control frames; reading keeps pongs flowing and detects disconnects
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+		})
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(wsPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+			conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+			if err := conn.WriteJSON(event); err != nil {
+				h.logger.WithError(err).Debug("Failed to push order update")
+				return
+			}
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		case <-closed:
+			h.logger.WithField("order_id", orderIDStr).Debug("Order updates client disconnected")
+			return
+		}
+	}
+}
+
+// publishStatus publishes an order status change as a domain event
+func (h *ordersHandler) publishStatus(orderID uuid.UUID, status, action string) {
+	if h.bus == nil {
+		return
+	}
+	h.bus.Publish(events.OrderStatusEvent{
+		OrderID:   orderID,
+		Status:    status,
+		Action:    action,
+		Timestamp: time.Now(),
+	})
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"orders-service/events"
+	"orders-service/models"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dialOrderUpdates starts a test server for the handler and connects a WebSocket client to it
+func dialOrderUpdates(t *testing.T, h *ordersHandler, query string) *websocket.Conn {
+	server := httptest.NewServer(http.HandlerFunc(h.OrderUpdates))
+	t.Cleanup(server.Close)
+	subscribers := h.updates.SubscriberCount()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/v1/orders/ws" + query
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	// The handler subscribes right after the upgrade; wait for it before publishing
+	require.Eventually(t, func() bool {
+		return h.updates.SubscriberCount() > subscribers
+	}, 2*time.Second, 10*time.Millisecond)
+
+	return conn
+}
+
+// TestOrderUpdatesPushesStatusChange tests that a status update is pushed to a connected client
+func TestOrderUpdatesPushesStatusChange(t *testing.T) {
+	handler, mockRepo := setupTestHandler()
+
+	orderID := uuid.New()
+	mockRepo.orders[orderID] = &models.Order{
+		ID:            orderID,
+		TotalAmount:   100.0,
+		PaymentMethod: "cash",
+		OrderStatus:   models.OrderStatusPending,
+	}
+
+	conn := dialOrderUpdates(t, handler, "")
+
+	req := httptest.NewRequest("PUT", "/orders/"+orderID.String(), bytes.NewBufferString(`{"order_status":"completed"}`))
+	req = mux.SetURLVars(req, map[string]string{"id": orderID.String()})
+	w := httptest.NewRecorder()
+	handler.UpdateOrder(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var event events.OrderStatusEvent
+	require.NoError(t, conn.ReadJSON(&event))
+
+	assert.Equal(t, orderID, event.OrderID)
+	assert.Equal(t, models.OrderStatusCompleted, event.Status)
+	assert.Equal(t, events.ActionUpdated, event.Action)
+}
+
+// TestOrderUpdatesFiltersByOrderID tests that a client subscribed to one order only receives its events
+func TestOrderUpdatesFiltersByOrderID(t *testing.T) {
+	handler, _ := setupTestHandler()
+
+	watched := uuid.New()
+	conn := dialOrderUpdates(t, handler, "?order_id="+watched.String())
+
+	handler.publishStatus(uuid.New(), models.OrderStatusCancelled, events.ActionCancelled)
+	handler.publishStatus(watched, models.OrderStatusCompleted, events.ActionUpdated)
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var event events.OrderStatusEvent
+	require.NoError(t, conn.ReadJSON(&event))
+
+	assert.Equal(t, watched, event.OrderID)
+	assert.Equal(t, models.OrderStatusCompleted, event.Status)
+}
+
+// TestOrderUpdatesInvalidOrderID tests that an invalid order filter is rejected before upgrading
+func TestOrderUpdatesInvalidOrderID(t *testing.T) {
+	handler, _ := setupTestHandler()
+
+	req := httptest.NewRequest("GET", "/orders/ws?order_id=invalid-id", nil)
+	w := httptest.NewRecorder()
+
+	handler.OrderUpdates(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -1,19 +1,27 @@
 package handler
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
 	"orders-service/config"
+	"orders-service/events"
+	"orders-service/ids"
 	"orders-service/models"
 	ordersql "orders-service/sql"
-
-	// Removed utils import - gateway handles all auth
+	"shared/eventbus"
+	"shared/httpx"
+	"shared/money"
+	"shared/validate"
+	"shared/version"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
@@ -24,33 +32,51 @@ type OrdersHandler interface {
 	// Order operations
 	CreateOrder(w http.ResponseWriter, r *http.Request)
 	GetOrder(w http.ResponseWriter, r *http.Request)
+	GetOrderByNumber(w http.ResponseWriter, r *http.Request)
+	GetOrderReceipt(w http.ResponseWriter, r *http.Request)
+	GetOrderTimeline(w http.ResponseWriter, r *http.Request)
 	UpdateOrder(w http.ResponseWriter, r *http.Request)
 	CancelOrder(w http.ResponseWriter, r *http.Request)
+	ReopenOrder(w http.ResponseWriter, r *http.Request)
+	BulkUpdateOrderStatus(w http.ResponseWriter, r *http.Request)
 	ListOrders(w http.ResponseWriter, r *http.Request)
+	ExportOrders(w http.ResponseWriter, r *http.Request)
+	OrderUpdates(w http.ResponseWriter, r *http.Request)
 
 	// Statistics and reports
 	GetOrderSummary(w http.ResponseWriter, r *http.Request)
 	GetPaymentMethodStats(w http.ResponseWriter, r *http.Request)
 
+	// Operations
+	GetConfig(w http.ResponseWriter, r *http.Request)
+
 	// Health check
 	HealthCheck(w http.ResponseWriter, r *http.Request)
 
+	// EventBus returns the bus order domain events are published on
+	EventBus() *eventbus.Bus
+
 	// No longer needed - gateway handles all auth
 	// GetJWTManager() *utils.JWTManager
 }
 
 // OrderRepository defines the interface for order data operations
 type OrderRepository interface {
-	CreateOrder(order *models.Order, items []models.OrderedRecipe) error
-	GetOrderByID(id uuid.UUID) (*models.Order, error)
-	GetOrderWithItems(id uuid.UUID) (*models.OrderWithItems, error)
-	GetOrderedRecipesByOrderID(orderID uuid.UUID) ([]models.OrderedRecipe, error)
-	UpdateOrder(id uuid.UUID, updates *models.UpdateOrderRequest) error
-	CancelOrder(id uuid.UUID) error
-	ListOrders(filter *models.OrderFilter) ([]models.Order, int, error)
-	GetOrderSummary() (*models.OrderSummary, error)
-	GetPaymentMethodStats() ([]models.PaymentMethodStats, error)
-	HealthCheck() error
+	CreateOrder(ctx context.Context, order *models.Order, items []models.OrderedRecipe, payments []models.Payment) error
+	GetOrderByID(ctx context.Context, id uuid.UUID) (*models.Order, error)
+	GetOrderByNumber(ctx context.Context, number string) (*models.Order, error)
+	GetOrderWithItems(ctx context.Context, id uuid.UUID) (*models.OrderWithItems, error)
+	GetOrderedRecipesByOrderID(ctx context.Context, orderID uuid.UUID) ([]models.OrderedRecipe, error)
+	GetOrderTimeline(ctx context.Context, orderID uuid.UUID) ([]models.OrderEvent, error)
+	UpdateOrder(ctx context.Context, id uuid.UUID, updates *models.UpdateOrderRequest) error
+	BulkUpdateOrderStatus(ctx context.Context, ids []uuid.UUID, status string) ([]models.BulkStatusResult, error)
+	CancelOrder(ctx context.Context, id uuid.UUID, reason string) error
+	ReopenOrder(ctx context.Context, id uuid.UUID, cutoff time.Time) error
+	ListOrders(ctx context.Context, filter *models.OrderFilter) ([]models.Order, int, error)
+	StreamOrdersForExport(ctx context.Context, from, to time.Time, includeItems bool, emit func(order models.Order, items []models.OrderedRecipe) error) error
+	GetOrderSummary(ctx context.Context) (*models.OrderSummary, error)
+	GetPaymentMethodStats(ctx context.Context) ([]models.PaymentMethodStats, error)
+	HealthCheck(ctx context.Context) error
 }
 
 type ordersHandler struct {
@@ -59,6 +85,16 @@ type ordersHandler struct {
 	logger *logrus.Logger
 	// Removed jwtManager - gateway handles all auth
 	repo OrderRepository
+
+	// bus carries domain events; updates relays order status events to WebSocket clients
+	bus     *eventbus.Bus
+	updates *events.StatusFeed
+
+	// clock stamps new orders and bounds the reopen window
+	clock ids.Clock
+
+	// httpClient calls the data-service health endpoint
+	httpClient *http.Client
 }
 
 // New creates a new orders handler instance
@@ -69,84 +105,135 @@ func New(db *sql.DB, cfg *config.Config, logger *logrus.Logger) (OrdersHandler,
 	if err != nil {
 		return nil, fmt.Errorf("failed to create repository: %w", err)
 	}
+	repo.SetOrderNumberFormat(models.OrderNumberFormat{Reset: cfg.OrderNumberReset, Digits: cfg.OrderNumberDigits})
+
+	bus := eventbus.New(logger)
+	updates := events.NewStatusFeed()
+	updates.Follow(bus)
 
 	return &ordersHandler{
 		db:     db,
 		config: cfg,
 		logger: logger,
 		// Removed jwtManager - gateway handles all auth
-		repo: repo,
+		repo:       repo,
+		bus:        bus,
+		updates:    updates,
+		clock:      ids.SystemClock{},
+		httpClient: &http.Client{Timeout: 5 * time.Second},
 	}, nil
 }
 
+// EventBus returns the bus order domain events are published on
+func (h *ordersHandler) EventBus() *eventbus.Bus {
+	return h.bus
+}
+
 // === ORDER ENDPOINTS ===
 
 // CreateOrder creates a new order
 func (h *ordersHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 	var req models.CreateOrderRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.respondWithError(w, http.StatusBadRequest, "Invalid JSON payload", err)
+	if err := httpx.DecodeJSONBody(r, &req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, httpx.DecodeErrorMessage(err, "Invalid JSON payload"), err)
 		return
 	}
 
 	// Validate request
 	if err := req.Validate(); err != nil {
-		h.respondWithError(w, http.StatusBadRequest, "Validation failed", err)
+		h.respondWithValidationError(w, err)
+		return
+	}
+
+	// Every item fans out into inventory deductions, so the item count is capped
+	if err := models.ValidateItemCount(len(req.Items), h.config.MaxItemsPerOrder); err != nil {
+		h.respondWithError(w, http.StatusUnprocessableEntity, "Too many items", err)
 		return
 	}
 
-	// Calculate totals
-	totalAmount := 0.0
+	// Calculate totals in cents so many line items add up exactly
+	var subtotal money.Money
 	for _, item := range req.Items {
-		totalAmount += float64(item.Quantity) * item.UnitPrice
+		subtotal += models.LineTotal(item.Quantity, item.UnitPrice)
 	}
+	totalAmount := subtotal.Float64()
 
-	// Calculate tax
-	taxAmount := totalAmount * (h.config.DefaultTaxRate / 100)
+	// Apply the discount policy, converting a percentage discount into an amount
+	discountAmount, err := models.ResolveDiscount(totalAmount, req.DiscountAmount, req.DiscountPercentage, h.config.MaxDiscountPercentage)
+	if err != nil {
+		h.respondWithError(w, http.StatusUnprocessableEntity, "Discount not allowed", err)
+		return
+	}
 
-	// Create order
+	// Create order; every row written for it shares one timestamp
+	now := h.clock.Now()
 	order := &models.Order{
-		ID:             uuid.New(),
+		ID:             ids.NewUUID(),
 		CustomerID:     req.CustomerID,
-		OrderDate:      time.Now(),
+		OrderDate:      now,
 		TotalAmount:    totalAmount,
-		TaxAmount:      taxAmount,
-		DiscountAmount: req.DiscountAmount,
+		DiscountAmount: discountAmount,
 		PaymentMethod:  req.PaymentMethod,
 		OrderStatus:    models.OrderStatusPending,
 		Notes:          req.Notes,
-		CreatedAt:      time.Now(),
-		UpdatedAt:      time.Now(),
+		CreatedAt:      now,
+		UpdatedAt:      now,
 	}
 
 	// Create ordered recipes
 	var items []models.OrderedRecipe
 	for _, reqItem := range req.Items {
-		totalPrice := float64(reqItem.Quantity) * reqItem.UnitPrice
+		totalPrice := models.LineTotal(reqItem.Quantity, reqItem.UnitPrice).Float64()
 		item := models.OrderedRecipe{
-			ID:                  uuid.New(),
+			ID:                  ids.NewUUID(),
 			OrderID:             order.ID,
 			RecipeID:            reqItem.RecipeID,
 			Quantity:            reqItem.Quantity,
 			UnitPrice:           reqItem.UnitPrice,
 			TotalPrice:          totalPrice,
 			SpecialInstructions: reqItem.SpecialInstructions,
-			CreatedAt:           time.Now(),
+			CreatedAt:           now,
 		}
 		items = append(items, item)
 	}
 
-	// Calculate final amount (total + tax - discount)
-	order.FinalAmount = order.TotalAmount + order.TaxAmount - order.DiscountAmount
+	// Apply tax and the service charge to the subtotal; receipts print these stored amounts
+	totals := models.CalculateOrderTotals(items, h.config.DefaultTaxRate, h.config.DefaultServiceRate, discountAmount)
+	order.TaxAmount = totals.Tax.Float64()
+	order.ServiceAmount = totals.Service.Float64()
+	order.FinalAmount = totals.Total.Float64()
+
+	// Split payments must cover the final amount exactly; a single method pays all of it
+	paymentRequests := req.Payments
+	if len(paymentRequests) > 0 {
+		if err := models.ValidatePaymentsTotal(paymentRequests, order.FinalAmount); err != nil {
+			h.respondWithError(w, http.StatusUnprocessableEntity, "Payments do not match order total", err)
+			return
+		}
+		order.PaymentMethod = models.PaymentsMethod(paymentRequests)
+	} else {
+		paymentRequests = []models.PaymentRequest{{PaymentMethod: req.PaymentMethod, Amount: order.FinalAmount}}
+	}
+
+	var payments []models.Payment
+	for _, paymentRequest := range paymentRequests {
+		payments = append(payments, models.Payment{
+			ID:            ids.NewUUID(),
+			OrderID:       order.ID,
+			PaymentMethod: paymentRequest.PaymentMethod,
+			Amount:        paymentRequest.Amount,
+			CreatedAt:     now,
+		})
+	}
 
 	// Save to database
-	if err := h.repo.CreateOrder(order, items); err != nil {
+	if err := h.repo.CreateOrder(r.Context(), order, items, payments); err != nil {
 		h.respondWithError(w, http.StatusInternalServerError, "Failed to create order", err)
 		return
 	}
 
 	// Get the complete order with calculated final_amount
-	createdOrder, err := h.repo.GetOrderWithItems(order.ID)
+	createdOrder, err := h.repo.GetOrderWithItems(r.Context(), order.ID)
 	if err != nil {
 		h.respondWithError(w, http.StatusInternalServerError, "Failed to retrieve created order", err)
 		return
@@ -155,15 +242,24 @@ func (h *ordersHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 	h.logger.WithFields(logrus.Fields{
 		"order_id":     order.ID,
 		"total_amount": totalAmount,
-		"tax_amount":   taxAmount,
+		"tax_amount":   order.TaxAmount,
 		"final_amount": createdOrder.Order.FinalAmount,
 	}).Info("Order created successfully")
 
+	h.publishStatus(order.ID, createdOrder.Order.OrderStatus, events.ActionCreated)
+
 	h.respondWithSuccess(w, http.StatusCreated, "Order created successfully", createdOrder)
 }
 
-// GetOrder retrieves an order by ID
+// GetOrder retrieves an order by ID. Clients asking for application/vnd.icecream.v2+json
+// get the flattened OrderV2 shape with a totals breakdown
 func (h *ordersHandler) GetOrder(w http.ResponseWriter, r *http.Request) {
+	version, err := negotiateVersion(r, APIVersion1, APIVersion2)
+	if err != nil {
+		h.respondWithError(w, http.StatusNotAcceptable, "Requested API version is not available", err)
+		return
+	}
+
 	vars := mux.Vars(r)
 	orderID, err := uuid.Parse(vars["id"])
 	if err != nil {
@@ -171,7 +267,29 @@ func (h *ordersHandler) GetOrder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	order, err := h.repo.GetOrderWithItems(orderID)
+	order, err := h.repo.GetOrderWithItems(r.Context(), orderID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			h.respondWithError(w, http.StatusNotFound, "Order not found", err)
+			return
+		}
+		h.respondWithError(w, http.StatusInternalServerError, "Failed to retrieve order", err)
+		return
+	}
+
+	if version == APIVersion2 {
+		totals := models.OrderTotalsFromOrder(order.Order)
+		h.respondWithVersion(w, http.StatusOK, version, "Order retrieved successfully", models.NewOrderV2(order, totals))
+		return
+	}
+	h.respondWithVersion(w, http.StatusOK, version, "Order retrieved successfully", order)
+}
+
+// GetOrderByNumber retrieves an order by its human-readable number, e.g. 20240301-0001
+func (h *ordersHandler) GetOrderByNumber(w http.ResponseWriter, r *http.Request) {
+	number := mux.Vars(r)["number"]
+
+	found, err := h.repo.GetOrderByNumber(r.Context(), number)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			h.respondWithError(w, http.StatusNotFound, "Order not found", err)
@@ -181,9 +299,75 @@ func (h *ordersHandler) GetOrder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	order, err := h.repo.GetOrderWithItems(r.Context(), found.ID)
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, "Failed to retrieve order", err)
+		return
+	}
+
 	h.respondWithSuccess(w, http.StatusOK, "Order retrieved successfully", order)
 }
 
+// GetOrderReceipt renders a printable plain-text receipt for an order
+func (h *ordersHandler) GetOrderReceipt(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	orderID, err := uuid.Parse(vars["id"])
+	if err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid order ID", err)
+		return
+	}
+
+	order, err := h.repo.GetOrderWithItems(r.Context(), orderID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			h.respondWithError(w, http.StatusNotFound, "Order not found", err)
+			return
+		}
+		h.respondWithError(w, http.StatusInternalServerError, "Failed to retrieve order", err)
+		return
+	}
+
+	totals := models.OrderTotalsFromOrder(order.Order)
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(renderReceipt(order, totals)))
+}
+
+// GetOrderTimeline retrieves the lifecycle events of an order, oldest first
+func (h *ordersHandler) GetOrderTimeline(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	orderID, err := uuid.Parse(vars["id"])
+	if err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid order ID", err)
+		return
+	}
+
+	order, err := h.repo.GetOrderByID(r.Context(), orderID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) || strings.Contains(err.Error(), "not found") {
+			h.respondWithError(w, http.StatusNotFound, "Order not found", err)
+			return
+		}
+		h.respondWithError(w, http.StatusInternalServerError, "Failed to retrieve order", err)
+		return
+	}
+
+	orderEvents, err := h.repo.GetOrderTimeline(r.Context(), orderID)
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, "Failed to retrieve order timeline", err)
+		return
+	}
+
+	timeline := models.OrderTimeline{
+		OrderID:     order.ID,
+		OrderNumber: order.OrderNumber,
+		OrderStatus: order.OrderStatus,
+		Events:      orderEvents,
+	}
+	h.respondWithSuccess(w, http.StatusOK, "Order timeline retrieved successfully", timeline)
+}
+
 // UpdateOrder updates an existing order
 func (h *ordersHandler) UpdateOrder(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -194,8 +378,8 @@ func (h *ordersHandler) UpdateOrder(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req models.UpdateOrderRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.respondWithError(w, http.StatusBadRequest, "Invalid JSON payload", err)
+	if err := httpx.DecodeJSONBody(r, &req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, httpx.DecodeErrorMessage(err, "Invalid JSON payload"), err)
 		return
 	}
 
@@ -217,7 +401,7 @@ func (h *ordersHandler) UpdateOrder(w http.ResponseWriter, r *http.Request) {
 
 	// Validate order status if provided
 	if req.OrderStatus != nil {
-		validStatuses := []string{models.OrderStatusPending, models.OrderStatusCompleted, models.OrderStatusCancelled}
+		validStatuses := []string{models.OrderStatusPending, models.OrderStatusPreparing, models.OrderStatusCompleted, models.OrderStatusCancelled}
 		valid := false
 		for _, status := range validStatuses {
 			if *req.OrderStatus == status {
@@ -229,10 +413,87 @@ func (h *ordersHandler) UpdateOrder(w http.ResponseWriter, r *http.Request) {
 			h.respondWithError(w, http.StatusBadRequest, "Invalid order status", nil)
 			return
 		}
+
+		// Cancelling records when and why, so it only goes through the cancel endpoint
+		if *req.OrderStatus == models.OrderStatusCancelled {
+			h.respondWithError(w, http.StatusUnprocessableEntity, "Use POST /api/v1/orders/{id}/cancel to cancel an order",
+				models.ErrStatusTransitionNotAllowed)
+			return
+		}
+	}
+
+	if err := req.Validate(); err != nil {
+		h.respondWithValidationError(w, err)
+		return
+	}
+
+	if req.Payments != nil {
+		if err := models.ValidatePayments(req.Payments); err != nil {
+			h.respondWithError(w, http.StatusBadRequest, "Validation failed", err)
+			return
+		}
+	}
+
+	// Discount and payment changes are checked against the stored order amounts
+	if req.Payments != nil || req.PaymentMethod != nil || req.DiscountAmount != nil || req.DiscountPercentage != nil {
+		stored, err := h.repo.GetOrderWithItems(r.Context(), orderID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) || strings.Contains(err.Error(), "not found") {
+				h.respondWithError(w, http.StatusNotFound, "Order not found", err)
+				return
+			}
+			h.respondWithError(w, http.StatusInternalServerError, "Failed to retrieve order", err)
+			return
+		}
+		order := stored.Order
+
+		discount := order.DiscountAmount
+		if req.DiscountAmount != nil || req.DiscountPercentage != nil {
+			requested := 0.0
+			if req.DiscountAmount != nil {
+				requested = *req.DiscountAmount
+			}
+			discount, err = models.ResolveDiscount(order.TotalAmount, requested, req.DiscountPercentage, h.config.MaxDiscountPercentage)
+			if err != nil {
+				h.respondWithError(w, http.StatusUnprocessableEntity, "Discount not allowed", err)
+				return
+			}
+			req.DiscountAmount = &discount
+		}
+		finalAmount := order.TotalAmount + order.TaxAmount + order.ServiceAmount - discount
+
+		// Replacement payments must cover the order's final amount, including any discount change
+		if req.Payments != nil {
+			if err := models.ValidatePaymentsTotal(req.Payments, finalAmount); err != nil {
+				h.respondWithError(w, http.StatusUnprocessableEntity, "Payments do not match order total", err)
+				return
+			}
+		} else if req.PaymentMethod != nil {
+			// A new payment method replaces the order's single payment; split payments must be sent explicitly
+			if len(stored.Payments) > 1 {
+				h.respondWithError(w, http.StatusUnprocessableEntity, "Order has split payments; send payments to change the payment method",
+					models.ErrSplitPaymentMethodChange)
+				return
+			}
+		} else if len(stored.Payments) > 0 {
+			// Without replacements, the payments already recorded must still add up after the discount change
+			if err := models.ValidatePaymentsTotal(models.PaymentRequestsFrom(stored.Payments), finalAmount); err != nil {
+				h.respondWithError(w, http.StatusUnprocessableEntity, "Recorded payments do not match the discounted total; send payments with the discount", err)
+				return
+			}
+		}
 	}
 
 	// Update order
-	if err := h.repo.UpdateOrder(orderID, &req); err != nil {
+	if err := h.repo.UpdateOrder(r.Context(), orderID, &req); err != nil {
+		if errors.Is(err, models.ErrStatusTransitionNotAllowed) {
+			h.respondWithError(w, http.StatusConflict, "Order status cannot be changed", err)
+			return
+		}
+		if errors.Is(err, models.ErrSplitPaymentMethodChange) {
+			h.respondWithError(w, http.StatusUnprocessableEntity, "Order has split payments; send payments to change the payment method", err)
+			return
+		}
 		if strings.Contains(err.Error(), "not found") {
 			h.respondWithError(w, http.StatusNotFound, "Order not found", err)
 			return
@@ -242,7 +503,7 @@ func (h *ordersHandler) UpdateOrder(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get updated order
-	updatedOrder, err := h.repo.GetOrderWithItems(orderID)
+	updatedOrder, err := h.repo.GetOrderWithItems(r.Context(), orderID)
 	if err != nil {
 		h.respondWithError(w, http.StatusInternalServerError, "Failed to retrieve updated order", err)
 		return
@@ -252,10 +513,48 @@ func (h *ordersHandler) UpdateOrder(w http.ResponseWriter, r *http.Request) {
 		"order_id": orderID,
 	}).Info("Order updated successfully")
 
+	h.publishStatus(orderID, updatedOrder.Order.OrderStatus, events.ActionUpdated)
+
 	h.respondWithSuccess(w, http.StatusOK, "Order updated successfully", updatedOrder)
 }
 
-// CancelOrder cancels an order
+// BulkUpdateOrderStatus moves several orders to preparing or completed at once for the kitchen display.
+// Orders whose current status does not allow the transition are reported as failed without blocking the rest
+func (h *ordersHandler) BulkUpdateOrderStatus(w http.ResponseWriter, r *http.Request) {
+	var req models.BulkStatusRequest
+	if err := httpx.DecodeJSONBody(r, &req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, httpx.DecodeErrorMessage(err, "Invalid JSON payload"), err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		h.respondWithValidationError(w, err)
+		return
+	}
+
+	results, err := h.repo.BulkUpdateOrderStatus(r.Context(), req.IDs, req.Status)
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, "Failed to update order statuses", err)
+		return
+	}
+
+	response := models.NewBulkStatusResponse(results)
+	for _, result := range results {
+		if result.Updated {
+			h.publishStatus(result.OrderID, result.Status, events.ActionUpdated)
+		}
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"status":  req.Status,
+		"updated": response.Updated,
+		"failed":  response.Failed,
+	}).Info("Bulk order status update applied")
+
+	h.respondWithSuccess(w, http.StatusOK, "Order statuses updated", response)
+}
+
+// CancelOrder cancels an order; it is served on POST /orders/{id}/cancel
 func (h *ordersHandler) CancelOrder(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	orderID, err := uuid.Parse(vars["id"])
@@ -264,9 +563,24 @@ func (h *ordersHandler) CancelOrder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.repo.CancelOrder(orderID); err != nil {
-		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "completed") {
-			h.respondWithError(w, http.StatusBadRequest, "Order cannot be cancelled", err)
+	// The body is optional; a cancel without one is recorded as "other"
+	var req models.CancelOrderRequest
+	if err := httpx.DecodeJSONBody(r, &req); err != nil && !errors.Is(err, io.EOF) {
+		h.respondWithError(w, http.StatusBadRequest, httpx.DecodeErrorMessage(err, "Invalid JSON payload"), err)
+		return
+	}
+	if err := req.Validate(h.config.CancellationReasons); err != nil {
+		h.respondWithError(w, http.StatusUnprocessableEntity, "Invalid cancellation reason", err)
+		return
+	}
+
+	if err := h.repo.CancelOrder(r.Context(), orderID, req.Reason); err != nil {
+		if errors.Is(err, models.ErrStatusTransitionNotAllowed) {
+			h.respondWithError(w, http.StatusConflict, "Order cannot be cancelled", err)
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			h.respondWithError(w, http.StatusNotFound, "Order not found", err)
 			return
 		}
 		h.respondWithError(w, http.StatusInternalServerError, "Failed to cancel order", err)
@@ -275,11 +589,66 @@ func (h *ordersHandler) CancelOrder(w http.ResponseWriter, r *http.Request) {
 
 	h.logger.WithFields(logrus.Fields{
 		"order_id": orderID,
+		"reason":   req.Reason,
 	}).Info("Order cancelled successfully")
 
+	h.publishStatus(orderID, models.OrderStatusCancelled, events.ActionCancelled)
+
 	h.respondWithSuccess(w, http.StatusOK, "Order cancelled successfully", map[string]interface{}{
+		"order_id":            orderID,
+		"status":              "cancelled",
+		"cancellation_reason": req.Reason,
+	})
+}
+
+// ReopenOrder restores a cancelled order to pending within the configured grace period
+func (h *ordersHandler) ReopenOrder(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	orderID, err := uuid.Parse(vars["id"])
+	if err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid order ID", err)
+		return
+	}
+
+	order, err := h.repo.GetOrderByID(r.Context(), orderID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) || strings.Contains(err.Error(), "not found") {
+			h.respondWithError(w, http.StatusNotFound, "Order not found", err)
+			return
+		}
+		h.respondWithError(w, http.StatusInternalServerError, "Failed to retrieve order", err)
+		return
+	}
+
+	if order.OrderStatus != models.OrderStatusCancelled {
+		h.respondWithError(w, http.StatusConflict, "Only cancelled orders can be reopened", nil)
+		return
+	}
+
+	cutoff := h.clock.Now().Add(-time.Duration(h.config.ReopenGracePeriod) * time.Minute)
+	if order.CancelledAt == nil || order.CancelledAt.Before(cutoff) {
+		h.respondWithError(w, http.StatusConflict, "Reopen grace period has expired", models.ErrReopenWindowExpired)
+		return
+	}
+
+	if err := h.repo.ReopenOrder(r.Context(), orderID, cutoff); err != nil {
+		if errors.Is(err, models.ErrReopenWindowExpired) {
+			h.respondWithError(w, http.StatusConflict, "Reopen grace period has expired", err)
+			return
+		}
+		h.respondWithError(w, http.StatusInternalServerError, "Failed to reopen order", err)
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"order_id": orderID,
+	}).Info("Order reopened successfully")
+
+	h.publishStatus(orderID, models.OrderStatusPending, events.ActionReopened)
+
+	h.respondWithSuccess(w, http.StatusOK, "Order reopened successfully", map[string]interface{}{
 		"order_id": orderID,
-		"status":   "cancelled",
+		"status":   models.OrderStatusPending,
 	})
 }
 
@@ -357,9 +726,12 @@ func (h *ordersHandler) ListOrders(w http.ResponseWriter, r *http.Request) {
 			h.respondWithError(w, http.StatusBadRequest, "Invalid limit", err)
 			return
 		}
-		filter.Limit = limit
+		filter.Limit = httpx.ClampPageSize(w, limit, h.config.MaxPageSize)
 	} else {
 		filter.Limit = 50 // default
+		if h.config.MaxPageSize > 0 && filter.Limit > h.config.MaxPageSize {
+			filter.Limit = h.config.MaxPageSize
+		}
 	}
 
 	if offsetStr := query.Get("offset"); offsetStr != "" {
@@ -397,18 +769,20 @@ func (h *ordersHandler) ListOrders(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get orders
-	orders, totalCount, err := h.repo.ListOrders(filter)
+	orders, totalCount, err := h.repo.ListOrders(r.Context(), filter)
 	if err != nil {
 		h.respondWithError(w, http.StatusInternalServerError, "Failed to retrieve orders", err)
 		return
 	}
 
+	pagination := models.NewPagination(filter.Limit, filter.Offset, len(orders), totalCount)
 	response := map[string]interface{}{
 		"orders":      orders,
 		"total_count": totalCount,
 		"limit":       filter.Limit,
 		"offset":      filter.Offset,
-		"has_more":    filter.Offset+len(orders) < totalCount,
+		"has_more":    pagination.HasMore,
+		"pagination":  pagination,
 	}
 
 	h.respondWithSuccess(w, http.StatusOK, "Orders retrieved successfully", response)
@@ -418,7 +792,7 @@ func (h *ordersHandler) ListOrders(w http.ResponseWriter, r *http.Request) {
 
 // GetOrderSummary retrieves order statistics
 func (h *ordersHandler) GetOrderSummary(w http.ResponseWriter, r *http.Request) {
-	summary, err := h.repo.GetOrderSummary()
+	summary, err := h.repo.GetOrderSummary(r.Context())
 	if err != nil {
 		h.respondWithError(w, http.StatusInternalServerError, "Failed to retrieve order summary", err)
 		return
@@ -429,7 +803,7 @@ func (h *ordersHandler) GetOrderSummary(w http.ResponseWriter, r *http.Request)
 
 // GetPaymentMethodStats retrieves payment method statistics
 func (h *ordersHandler) GetPaymentMethodStats(w http.ResponseWriter, r *http.Request) {
-	stats, err := h.repo.GetPaymentMethodStats()
+	stats, err := h.repo.GetPaymentMethodStats(r.Context())
 	if err != nil {
 		h.respondWithError(w, http.StatusInternalServerError, "Failed to retrieve payment method stats", err)
 		return
@@ -438,33 +812,52 @@ func (h *ordersHandler) GetPaymentMethodStats(w http.ResponseWriter, r *http.Req
 	h.respondWithSuccess(w, http.StatusOK, "Payment method stats retrieved successfully", stats)
 }
 
+// GetConfig returns the configuration the service loaded, with secrets redacted
+func (h *ordersHandler) GetConfig(w http.ResponseWriter, r *http.Request) {
+	h.respondWithSuccess(w, http.StatusOK, "Configuration retrieved successfully", h.config.Sanitized())
+}
+
 // === HEALTH CHECK ===
 
 // HealthCheck checks the health of the orders service
 func (h *ordersHandler) HealthCheck(w http.ResponseWriter, r *http.Request) {
-	// Check data-service health (which checks database connectivity)
-	client := &http.Client{
-		Timeout: 5 * time.Second,
-	}
-
-	resp, err := client.Get("http://localhost:8086/health")
-	if err != nil {
-		h.respondWithError(w, http.StatusServiceUnavailable, "Data service connection failed", err)
+	if err := h.repo.HealthCheck(r.Context()); err != nil {
+		h.respondWithError(w, http.StatusServiceUnavailable, "Database connection failed", err)
 		return
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		err := fmt.Errorf("data service returned status %d", resp.StatusCode)
-		h.respondWithError(w, http.StatusServiceUnavailable, "Data service is unhealthy", err)
-		return
+	dependencies := map[string]string{}
+	if h.config.HealthCheckDataService {
+		if err := httpx.CheckHealth(h.httpClient, h.config.DataServiceHealthURL); err != nil {
+			dependencies["data-service"] = "unhealthy"
+			h.logger.WithError(err).Error("Data service is unhealthy")
+			response := map[string]interface{}{
+				"success": false,
+				"message": "Data service is unhealthy",
+				"error":   err.Error(),
+				"data": map[string]interface{}{
+					"service":      "orders-service",
+					"status":       "unhealthy",
+					"dependencies": dependencies,
+				},
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(response)
+			return
+		}
+		dependencies["data-service"] = "healthy"
 	}
 
 	response := map[string]interface{}{
-		"service": "orders-service",
-		"status":  "healthy",
-		"time":    time.Now(),
-		"version": "1.0.0",
+		"service":      "orders-service",
+		"status":       "healthy",
+		"time":         time.Now(),
+		"version":      version.Version,
+		"build":        version.Info(),
+		"dependencies": dependencies,
+		"panics":       httpx.PanicCount(),
 	}
 
 	h.respondWithSuccess(w, http.StatusOK, "Orders service is healthy", response)
@@ -484,6 +877,15 @@ func (h *ordersHandler) respondWithSuccess(w http.ResponseWriter, status int, me
 	json.NewEncoder(w).Encode(response)
 }
 
+// respondWithValidationError rejects an invalid request, using 422 for discount policy violations
+func (h *ordersHandler) respondWithValidationError(w http.ResponseWriter, err error) {
+	if models.IsDiscountError(err) {
+		h.respondWithError(w, http.StatusUnprocessableEntity, "Discount not allowed", err)
+		return
+	}
+	h.respondWithError(w, http.StatusBadRequest, "Validation failed", err)
+}
+
 func (h *ordersHandler) respondWithError(w http.ResponseWriter, status int, message string, err error) {
 	response := map[string]interface{}{
 		"success": false,
@@ -493,6 +895,9 @@ func (h *ordersHandler) respondWithError(w http.ResponseWriter, status int, mess
 	if err != nil {
 		h.logger.WithError(err).Error(message)
 		response["error"] = err.Error()
+		if violations := validate.All(err); len(violations) > 0 {
+			response["errors"] = violations
+		}
 	}
 
 	w.Header().Set("Content-Type", "application/json")
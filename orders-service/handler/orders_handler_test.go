@@ -2,16 +2,23 @@ package handler
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sort"
+	"sync"
 	"testing"
 	"time"
 
 	"orders-service/config"
+	"orders-service/events"
+	"orders-service/ids"
 	"orders-service/models"
+	"shared/eventbus"
+	"shared/httpx"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
@@ -25,28 +32,58 @@ import (
 type mockOrderRepository struct {
 	orders         map[uuid.UUID]*models.Order
 	orderedRecipes map[uuid.UUID][]models.OrderedRecipe
+	payments       map[uuid.UUID][]models.Payment
+	events         map[uuid.UUID][]models.OrderEvent
 	shouldError    bool
 	errorMessage   string
+
+	// mu serializes order creation the way the order number counter row lock does
+	mu             sync.Mutex
+	numberCounters map[string]int
 }
 
 func newMockRepository() *mockOrderRepository {
 	return &mockOrderRepository{
 		orders:         make(map[uuid.UUID]*models.Order),
 		orderedRecipes: make(map[uuid.UUID][]models.OrderedRecipe),
+		payments:       make(map[uuid.UUID][]models.Payment),
+		events:         make(map[uuid.UUID][]models.OrderEvent),
 		shouldError:    false,
+		numberCounters: make(map[string]int),
 	}
 }
 
-func (m *mockOrderRepository) CreateOrder(order *models.Order, items []models.OrderedRecipe) error {
+func (m *mockOrderRepository) CreateOrder(ctx context.Context, order *models.Order, items []models.OrderedRecipe, payments []models.Payment) error {
 	if m.shouldError {
 		return fmt.Errorf(m.errorMessage)
 	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	scope := models.DefaultOrderNumberFormat.Scope(order.OrderDate)
+	m.numberCounters[scope]++
+	order.OrderNumber = models.DefaultOrderNumberFormat.Format(scope, m.numberCounters[scope])
 	m.orders[order.ID] = order
 	m.orderedRecipes[order.ID] = items
+	m.payments[order.ID] = payments
+	m.recordEvent(ctx, order.ID, models.OrderEventCreated, nil)
 	return nil
 }
 
-func (m *mockOrderRepository) GetOrderByID(id uuid.UUID) (*models.Order, error) {
+// recordEvent appends a timeline event stamped with the order's current status, as the repository does
+func (m *mockOrderRepository) recordEvent(ctx context.Context, id uuid.UUID, eventType string, notes *string) {
+	m.events[id] = append(m.events[id], models.OrderEvent{
+		ID:          uuid.New(),
+		OrderID:     id,
+		EventType:   eventType,
+		OrderStatus: m.orders[id].OrderStatus,
+		Actor:       models.ActorFromContext(ctx),
+		Notes:       notes,
+		CreatedAt:   time.Now(),
+	})
+}
+
+func (m *mockOrderRepository) GetOrderByID(ctx context.Context, id uuid.UUID) (*models.Order, error) {
 	if m.shouldError {
 		return nil, fmt.Errorf(m.errorMessage)
 	}
@@ -57,19 +94,37 @@ func (m *mockOrderRepository) GetOrderByID(id uuid.UUID) (*models.Order, error)
 	return order, nil
 }
 
-func (m *mockOrderRepository) GetOrderWithItems(id uuid.UUID) (*models.OrderWithItems, error) {
+func (m *mockOrderRepository) GetOrderByNumber(ctx context.Context, number string) (*models.Order, error) {
+	if m.shouldError {
+		return nil, fmt.Errorf(m.errorMessage)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, order := range m.orders {
+		if order.OrderNumber == number {
+			return order, nil
+		}
+	}
+	return nil, fmt.Errorf("order not found")
+}
+
+func (m *mockOrderRepository) GetOrderWithItems(ctx context.Context, id uuid.UUID) (*models.OrderWithItems, error) {
 	if m.shouldError {
 		return nil, fmt.Errorf(m.errorMessage)
 	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	order, exists := m.orders[id]
 	if !exists {
 		return nil, fmt.Errorf("order not found")
 	}
 	items := m.orderedRecipes[id]
-	return &models.OrderWithItems{Order: *order, Items: items}, nil
+	return &models.OrderWithItems{Order: *order, Items: items, Payments: m.payments[id]}, nil
 }
 
-func (m *mockOrderRepository) GetOrderedRecipesByOrderID(orderID uuid.UUID) ([]models.OrderedRecipe, error) {
+func (m *mockOrderRepository) GetOrderedRecipesByOrderID(ctx context.Context, orderID uuid.UUID) ([]models.OrderedRecipe, error) {
 	if m.shouldError {
 		return nil, fmt.Errorf(m.errorMessage)
 	}
@@ -80,7 +135,7 @@ func (m *mockOrderRepository) GetOrderedRecipesByOrderID(orderID uuid.UUID) ([]m
 	return items, nil
 }
 
-func (m *mockOrderRepository) UpdateOrder(id uuid.UUID, updates *models.UpdateOrderRequest) error {
+func (m *mockOrderRepository) UpdateOrder(ctx context.Context, id uuid.UUID, updates *models.UpdateOrderRequest) error {
 	if m.shouldError {
 		return fmt.Errorf(m.errorMessage)
 	}
@@ -88,9 +143,23 @@ func (m *mockOrderRepository) UpdateOrder(id uuid.UUID, updates *models.UpdateOr
 	if !exists {
 		return fmt.Errorf("order not found")
 	}
+	if updates.OrderStatus != nil && !models.CanUpdateOrderStatus(order.OrderStatus, *updates.OrderStatus) {
+		return fmt.Errorf("%w: %s", models.ErrStatusTransitionNotAllowed, models.StatusTransitionError(order.OrderStatus, *updates.OrderStatus))
+	}
+
+	if updates.PaymentMethod != nil && updates.Payments == nil && len(m.payments[id]) > 1 {
+		return models.ErrSplitPaymentMethodChange
+	}
 
 	// Apply updates
-	if updates.PaymentMethod != nil {
+	if updates.Payments != nil {
+		order.PaymentMethod = models.PaymentsMethod(updates.Payments)
+		payments := make([]models.Payment, 0, len(updates.Payments))
+		for _, payment := range updates.Payments {
+			payments = append(payments, models.Payment{ID: uuid.New(), OrderID: id, PaymentMethod: payment.PaymentMethod, Amount: payment.Amount})
+		}
+		m.payments[id] = payments
+	} else if updates.PaymentMethod != nil {
 		order.PaymentMethod = *updates.PaymentMethod
 	}
 	if updates.OrderStatus != nil {
@@ -101,13 +170,47 @@ func (m *mockOrderRepository) UpdateOrder(id uuid.UUID, updates *models.UpdateOr
 	}
 	if updates.DiscountAmount != nil {
 		order.DiscountAmount = *updates.DiscountAmount
+		order.FinalAmount = order.TotalAmount + order.TaxAmount + order.ServiceAmount - order.DiscountAmount
+	}
+	if updates.PaymentMethod != nil && updates.Payments == nil {
+		m.payments[id] = []models.Payment{{ID: uuid.New(), OrderID: id, PaymentMethod: *updates.PaymentMethod, Amount: order.FinalAmount}}
 	}
 	order.UpdatedAt = time.Now()
+	if updates.OrderStatus != nil {
+		m.recordEvent(ctx, id, models.OrderEventStatusChanged, nil)
+	}
 
 	return nil
 }
 
-func (m *mockOrderRepository) CancelOrder(id uuid.UUID) error {
+func (m *mockOrderRepository) BulkUpdateOrderStatus(ctx context.Context, ids []uuid.UUID, status string) ([]models.BulkStatusResult, error) {
+	if m.shouldError {
+		return nil, fmt.Errorf(m.errorMessage)
+	}
+	results := make([]models.BulkStatusResult, 0, len(ids))
+	for _, id := range ids {
+		order, exists := m.orders[id]
+		if !exists {
+			results = append(results, models.BulkStatusResult{OrderID: id, Error: "order not found"})
+			continue
+		}
+		result := models.BulkStatusResult{OrderID: id, PreviousStatus: order.OrderStatus, Status: order.OrderStatus}
+		if !models.CanTransitionOrderStatus(order.OrderStatus, status) {
+			result.Error = models.StatusTransitionError(order.OrderStatus, status)
+			results = append(results, result)
+			continue
+		}
+		order.OrderStatus = status
+		order.UpdatedAt = time.Now()
+		m.recordEvent(ctx, id, models.OrderEventStatusChanged, nil)
+		result.Updated = true
+		result.Status = status
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (m *mockOrderRepository) CancelOrder(ctx context.Context, id uuid.UUID, reason string) error {
 	if m.shouldError {
 		return fmt.Errorf(m.errorMessage)
 	}
@@ -115,12 +218,42 @@ func (m *mockOrderRepository) CancelOrder(id uuid.UUID) error {
 	if !exists {
 		return fmt.Errorf("order not found")
 	}
+	if order.OrderStatus != models.OrderStatusPending && order.OrderStatus != models.OrderStatusPreparing {
+		return fmt.Errorf("%w: %s", models.ErrStatusTransitionNotAllowed, models.StatusTransitionError(order.OrderStatus, models.OrderStatusCancelled))
+	}
+	now := time.Now()
 	order.OrderStatus = "cancelled"
+	order.CancelledAt = &now
+	order.CancellationReason = &reason
+	order.UpdatedAt = now
+	m.recordEvent(ctx, id, models.OrderEventCancelled, &reason)
+	return nil
+}
+
+func (m *mockOrderRepository) ReopenOrder(ctx context.Context, id uuid.UUID, cutoff time.Time) error {
+	if m.shouldError {
+		return fmt.Errorf(m.errorMessage)
+	}
+	order, exists := m.orders[id]
+	if !exists || order.OrderStatus != "cancelled" || order.CancelledAt == nil || order.CancelledAt.Before(cutoff) {
+		return models.ErrReopenWindowExpired
+	}
+	order.OrderStatus = "pending"
+	order.CancelledAt = nil
+	order.CancellationReason = nil
 	order.UpdatedAt = time.Now()
+	m.recordEvent(ctx, id, models.OrderEventReopened, nil)
 	return nil
 }
 
-func (m *mockOrderRepository) ListOrders(filter *models.OrderFilter) ([]models.Order, int, error) {
+func (m *mockOrderRepository) GetOrderTimeline(ctx context.Context, orderID uuid.UUID) ([]models.OrderEvent, error) {
+	if m.shouldError {
+		return nil, fmt.Errorf(m.errorMessage)
+	}
+	return append([]models.OrderEvent{}, m.events[orderID]...), nil
+}
+
+func (m *mockOrderRepository) ListOrders(ctx context.Context, filter *models.OrderFilter) ([]models.Order, int, error) {
 	if m.shouldError {
 		return nil, 0, fmt.Errorf(m.errorMessage)
 	}
@@ -130,21 +263,57 @@ func (m *mockOrderRepository) ListOrders(filter *models.OrderFilter) ([]models.O
 		orders = append(orders, *order)
 	}
 
-	return orders, len(orders), nil
+	// Apply the requested window like the SQL repository does
+	total := len(orders)
+	if filter.Offset >= total {
+		return []models.Order{}, total, nil
+	}
+	orders = orders[filter.Offset:]
+	if filter.Limit > 0 && filter.Limit < len(orders) {
+		orders = orders[:filter.Limit]
+	}
+
+	return orders, total, nil
+}
+
+func (m *mockOrderRepository) StreamOrdersForExport(ctx context.Context, from, to time.Time, includeItems bool, emit func(order models.Order, items []models.OrderedRecipe) error) error {
+	if m.shouldError {
+		return fmt.Errorf(m.errorMessage)
+	}
+
+	orders := make([]models.Order, 0, len(m.orders))
+	for _, order := range m.orders {
+		if !order.OrderDate.Before(from) && !order.OrderDate.After(to) {
+			orders = append(orders, *order)
+		}
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].OrderDate.Before(orders[j].OrderDate) })
+
+	for _, order := range orders {
+		var items []models.OrderedRecipe
+		if includeItems {
+			items = m.orderedRecipes[order.ID]
+		}
+		if err := emit(order, items); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func (m *mockOrderRepository) GetOrderSummary() (*models.OrderSummary, error) {
+func (m *mockOrderRepository) GetOrderSummary(ctx context.Context) (*models.OrderSummary, error) {
 	if m.shouldError {
 		return nil, fmt.Errorf(m.errorMessage)
 	}
 
 	summary := &models.OrderSummary{
-		TotalOrders:     len(m.orders),
-		PendingOrders:   0,
-		CompletedOrders: 0,
-		CancelledOrders: 0,
-		TotalRevenue:    0.0,
-		AverageOrder:    0.0,
+		TotalOrders:         len(m.orders),
+		PendingOrders:       0,
+		CompletedOrders:     0,
+		CancelledOrders:     0,
+		TotalRevenue:        0.0,
+		AverageOrder:        0.0,
+		CancellationReasons: map[string]int{},
 	}
 
 	for _, order := range m.orders {
@@ -156,6 +325,11 @@ func (m *mockOrderRepository) GetOrderSummary() (*models.OrderSummary, error) {
 			summary.TotalRevenue += order.FinalAmount
 		case "cancelled":
 			summary.CancelledOrders++
+			reason := models.CancellationReasonOther
+			if order.CancellationReason != nil {
+				reason = *order.CancellationReason
+			}
+			summary.CancellationReasons[reason]++
 		}
 	}
 
@@ -166,7 +340,7 @@ func (m *mockOrderRepository) GetOrderSummary() (*models.OrderSummary, error) {
 	return summary, nil
 }
 
-func (m *mockOrderRepository) GetPaymentMethodStats() ([]models.PaymentMethodStats, error) {
+func (m *mockOrderRepository) GetPaymentMethodStats(ctx context.Context) ([]models.PaymentMethodStats, error) {
 	if m.shouldError {
 		return nil, fmt.Errorf(m.errorMessage)
 	}
@@ -180,7 +354,7 @@ func (m *mockOrderRepository) GetPaymentMethodStats() ([]models.PaymentMethodSta
 	return stats, nil
 }
 
-func (m *mockOrderRepository) HealthCheck() error {
+func (m *mockOrderRepository) HealthCheck(ctx context.Context) error {
 	if m.shouldError {
 		return fmt.Errorf(m.errorMessage)
 	}
@@ -192,9 +366,14 @@ func setupTestHandler() (*ordersHandler, *mockOrderRepository) {
 	db, _, _ := sqlmock.New()
 
 	cfg := &config.Config{
-		DefaultTaxRate:     13.0,
-		DefaultServiceRate: 10.0,
-		OrderTimeout:       30,
+		DefaultTaxRate:        13.0,
+		DefaultServiceRate:    10.0,
+		OrderTimeout:          30,
+		ReopenGracePeriod:     15,
+		MaxDiscountPercentage: 50.0,
+		MaxItemsPerOrder:      100,
+		MaxPageSize:           100,
+		CancellationReasons:   config.DefaultCancellationReasons,
 	}
 
 	logger := logrus.New()
@@ -202,11 +381,19 @@ func setupTestHandler() (*ordersHandler, *mockOrderRepository) {
 
 	mockRepo := newMockRepository()
 
+	bus := eventbus.New(logger)
+	updates := events.NewStatusFeed()
+	updates.Follow(bus)
+
 	handler := &ordersHandler{
-		db:     db,
-		config: cfg,
-		logger: logger,
-		repo:   mockRepo,
+		db:         db,
+		config:     cfg,
+		logger:     logger,
+		repo:       mockRepo,
+		bus:        bus,
+		updates:    updates,
+		clock:      ids.SystemClock{},
+		httpClient: &http.Client{Timeout: 5 * time.Second},
 	}
 
 	return handler, mockRepo
@@ -236,6 +423,7 @@ func TestHealthCheck(t *testing.T) {
 		data, ok := response["data"].(map[string]interface{})
 		require.True(t, ok)
 		assert.Equal(t, "healthy", data["status"])
+		assert.Equal(t, float64(httpx.PanicCount()), data["panics"])
 	})
 
 	t.Run("health check with database error", func(t *testing.T) {
@@ -251,6 +439,59 @@ func TestHealthCheck(t *testing.T) {
 	})
 }
 
+// TestHealthCheckDataServiceDependency tests the optional data-service dependency check
+func TestHealthCheckDataServiceDependency(t *testing.T) {
+	healthyDataService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer healthyDataService.Close()
+
+	unreachableDataService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	unreachableURL := unreachableDataService.URL
+	unreachableDataService.Close()
+
+	testCases := map[string]struct {
+		dataServiceURL  string
+		expectedStatus  int
+		expectedHealth  string
+		expectedDataSvc string
+	}{
+		"healthy data-service": {
+			dataServiceURL:  healthyDataService.URL,
+			expectedStatus:  http.StatusOK,
+			expectedHealth:  "healthy",
+			expectedDataSvc: "healthy",
+		},
+		"unreachable data-service": {
+			dataServiceURL:  unreachableURL,
+			expectedStatus:  http.StatusServiceUnavailable,
+			expectedHealth:  "unhealthy",
+			expectedDataSvc: "unhealthy",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler, _ := setupTestHandler()
+			handler.config.HealthCheckDataService = true
+			handler.config.DataServiceHealthURL = tc.dataServiceURL
+
+			w := httptest.NewRecorder()
+			handler.HealthCheck(w, httptest.NewRequest("GET", "/health", nil))
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+			data, ok := response["data"].(map[string]interface{})
+			require.True(t, ok)
+			assert.Equal(t, tc.expectedHealth, data["status"])
+			assert.Equal(t, map[string]interface{}{"data-service": tc.expectedDataSvc}, data["dependencies"])
+		})
+	}
+}
+
 // TestCreateOrder tests the create order endpoint
 func TestCreateOrder(t *testing.T) {
 	handler, mockRepo := setupTestHandler()
@@ -331,6 +572,32 @@ func TestCreateOrder(t *testing.T) {
 		assert.Equal(t, http.StatusBadRequest, w.Code)
 	})
 
+	t.Run("multiple validation failures", func(t *testing.T) {
+		body := `{"payment_method":"bitcoin","discount_amount":-1,"items":[{"recipe_id":"` + uuid.New().String() + `","quantity":0,"unit_price":-2}]}`
+		req := httptest.NewRequest("POST", "/orders", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		handler.CreateOrder(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var response struct {
+			Message string `json:"message"`
+			Errors  []struct {
+				Field string `json:"field"`
+			} `json:"errors"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "Validation failed", response.Message)
+
+		fields := make([]string, len(response.Errors))
+		for i, violation := range response.Errors {
+			fields[i] = violation.Field
+		}
+		assert.Equal(t, []string{"payment_method", "items", "items", "discount_amount"}, fields)
+	})
+
 	t.Run("repository error", func(t *testing.T) {
 		mockRepo.shouldError = true
 		mockRepo.errorMessage = "database error"
@@ -346,6 +613,396 @@ func TestCreateOrder(t *testing.T) {
 	})
 }
 
+// TestCreateOrderAssignsOrderNumbers tests that orders get increasing daily numbers that can be looked up
+func TestCreateOrderAssignsOrderNumbers(t *testing.T) {
+	handler, _ := setupTestHandler()
+	handler.clock = ids.FixedClock{Time: time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)}
+
+	body, err := json.Marshal(models.CreateOrderRequest{
+		PaymentMethod: "cash",
+		Items:         []models.CreateOrderedRecipeRequest{{RecipeID: uuid.New(), Quantity: 1, UnitPrice: 10.0}},
+	})
+	require.NoError(t, err)
+
+	createOrder := func() (id, number string) {
+		req := httptest.NewRequest("POST", "/orders", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		handler.CreateOrder(w, req)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		var response struct {
+			Data models.OrderWithItems `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response.Data.Order.ID.String(), response.Data.Order.OrderNumber
+	}
+
+	firstID, first := createOrder()
+	_, second := createOrder()
+	assert.Equal(t, "20240301-0001", first)
+	assert.Equal(t, "20240301-0002", second)
+
+	testCases := map[string]struct {
+		number         string
+		expectedStatus int
+		expectedID     string
+	}{
+		"existing number": {number: first, expectedStatus: http.StatusOK, expectedID: firstID},
+		"unknown number":  {number: "20240301-9999", expectedStatus: http.StatusNotFound},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/orders/number/"+tc.number, nil)
+			req = mux.SetURLVars(req, map[string]string{"number": tc.number})
+			w := httptest.NewRecorder()
+
+			handler.GetOrderByNumber(w, req)
+
+			require.Equal(t, tc.expectedStatus, w.Code)
+			if tc.expectedStatus != http.StatusOK {
+				return
+			}
+			var response struct {
+				Data models.OrderWithItems `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tc.expectedID, response.Data.Order.ID.String())
+			assert.Equal(t, tc.number, response.Data.Order.OrderNumber)
+			assert.Len(t, response.Data.Items, 1)
+		})
+	}
+}
+
+// TestCreateOrderConcurrentOrderNumbersUnique tests that orders created at the same time never share a number
+func TestCreateOrderConcurrentOrderNumbersUnique(t *testing.T) {
+	handler, _ := setupTestHandler()
+	handler.clock = ids.FixedClock{Time: time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)}
+
+	body, err := json.Marshal(models.CreateOrderRequest{
+		PaymentMethod: "card",
+		Items:         []models.CreateOrderedRecipeRequest{{RecipeID: uuid.New(), Quantity: 1, UnitPrice: 10.0}},
+	})
+	require.NoError(t, err)
+
+	const orders = 50
+	numbers := make(chan string, orders)
+	var wg sync.WaitGroup
+	for i := 0; i < orders; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req := httptest.NewRequest("POST", "/orders", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			handler.CreateOrder(w, req)
+
+			var response struct {
+				Data models.OrderWithItems `json:"data"`
+			}
+			if w.Code == http.StatusCreated && json.Unmarshal(w.Body.Bytes(), &response) == nil {
+				numbers <- response.Data.Order.OrderNumber
+			}
+		}()
+	}
+	wg.Wait()
+	close(numbers)
+
+	seen := make(map[string]bool)
+	for number := range numbers {
+		assert.False(t, seen[number], "order number %s assigned twice", number)
+		seen[number] = true
+	}
+	require.Len(t, seen, orders)
+	for i := 1; i <= orders; i++ {
+		assert.True(t, seen[fmt.Sprintf("20240301-%04d", i)], "order number %d missing", i)
+	}
+}
+
+// TestCreateOrderUsesClock tests that a fixed clock gives the order, its items and payments deterministic timestamps
+func TestCreateOrderUsesClock(t *testing.T) {
+	handler, mockRepo := setupTestHandler()
+	now := time.Date(2024, time.March, 1, 12, 30, 0, 0, time.UTC)
+	handler.clock = ids.FixedClock{Time: now}
+
+	request := models.CreateOrderRequest{
+		PaymentMethod: "cash",
+		Items: []models.CreateOrderedRecipeRequest{
+			{RecipeID: uuid.New(), Quantity: 2, UnitPrice: 25.0},
+			{RecipeID: uuid.New(), Quantity: 1, UnitPrice: 10.0},
+		},
+	}
+	jsonData, _ := json.Marshal(request)
+	req := httptest.NewRequest("POST", "/orders", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handler.CreateOrder(w, req)
+
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.Len(t, mockRepo.orders, 1)
+	for orderID, order := range mockRepo.orders {
+		assert.Equal(t, now, order.OrderDate)
+		assert.Equal(t, now, order.CreatedAt)
+		assert.Equal(t, now, order.UpdatedAt)
+
+		require.Len(t, mockRepo.orderedRecipes[orderID], 2)
+		for _, item := range mockRepo.orderedRecipes[orderID] {
+			assert.Equal(t, now, item.CreatedAt)
+		}
+		require.Len(t, mockRepo.payments[orderID], 1)
+		assert.Equal(t, now, mockRepo.payments[orderID][0].CreatedAt)
+	}
+
+	var response struct {
+		Data struct {
+			Order models.Order `json:"order"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, now.Equal(response.Data.Order.CreatedAt))
+	assert.True(t, now.Equal(response.Data.Order.UpdatedAt))
+}
+
+// TestCreateOrderItemLimit tests that orders over the configured item cap are rejected with 422
+func TestCreateOrderItemLimit(t *testing.T) {
+	testCases := map[string]struct {
+		itemCount      int
+		maxItems       int
+		expectedStatus int
+	}{
+		"at the cap": {
+			itemCount:      100,
+			maxItems:       100,
+			expectedStatus: http.StatusCreated,
+		},
+		"over the cap": {
+			itemCount:      101,
+			maxItems:       100,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		"cap disabled": {
+			itemCount:      150,
+			maxItems:       0,
+			expectedStatus: http.StatusCreated,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler, mockRepo := setupTestHandler()
+			handler.config.MaxItemsPerOrder = tc.maxItems
+
+			items := make([]models.CreateOrderedRecipeRequest, tc.itemCount)
+			for i := range items {
+				items[i] = models.CreateOrderedRecipeRequest{RecipeID: uuid.New(), Quantity: 1, UnitPrice: 1.0}
+			}
+
+			jsonData, _ := json.Marshal(models.CreateOrderRequest{PaymentMethod: "cash", Items: items})
+			w := httptest.NewRecorder()
+
+			handler.CreateOrder(w, httptest.NewRequest("POST", "/orders", bytes.NewBuffer(jsonData)))
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			if tc.expectedStatus == http.StatusUnprocessableEntity {
+				assert.Empty(t, mockRepo.orders)
+				assert.Contains(t, w.Body.String(), "too many items")
+			}
+		})
+	}
+}
+
+// TestCreateOrderSplitPayments tests creating an order paid with several methods
+func TestCreateOrderSplitPayments(t *testing.T) {
+	// 2 x 25.00 plus 13% tax and a 10% service charge gives a final amount of 61.50
+	items := []models.CreateOrderedRecipeRequest{{RecipeID: uuid.New(), Quantity: 2, UnitPrice: 25.0}}
+
+	testCases := map[string]struct {
+		payments       []models.PaymentRequest
+		expectedStatus int
+		expectedMethod string
+	}{
+		"valid split": {
+			payments:       []models.PaymentRequest{{PaymentMethod: "cash", Amount: 30.0}, {PaymentMethod: "card", Amount: 31.5}},
+			expectedStatus: http.StatusCreated,
+			expectedMethod: models.PaymentMethodSplit,
+		},
+		"single payment entry": {
+			payments:       []models.PaymentRequest{{PaymentMethod: "sinpe", Amount: 61.5}},
+			expectedStatus: http.StatusCreated,
+			expectedMethod: "sinpe",
+		},
+		"underpayment": {
+			payments:       []models.PaymentRequest{{PaymentMethod: "cash", Amount: 30.0}, {PaymentMethod: "card", Amount: 20.0}},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		"overpayment": {
+			payments:       []models.PaymentRequest{{PaymentMethod: "cash", Amount: 30.0}, {PaymentMethod: "card", Amount: 40.0}},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		"invalid payment method": {
+			payments:       []models.PaymentRequest{{PaymentMethod: "bitcoin", Amount: 61.5}},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler, mockRepo := setupTestHandler()
+
+			jsonData, _ := json.Marshal(models.CreateOrderRequest{Items: items, Payments: tc.payments})
+			req := httptest.NewRequest("POST", "/orders", bytes.NewBuffer(jsonData))
+			w := httptest.NewRecorder()
+
+			handler.CreateOrder(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			if tc.expectedStatus != http.StatusCreated {
+				assert.Empty(t, mockRepo.orders)
+				return
+			}
+
+			require.Len(t, mockRepo.orders, 1)
+			for id, order := range mockRepo.orders {
+				assert.Equal(t, tc.expectedMethod, order.PaymentMethod)
+				require.Len(t, mockRepo.payments[id], len(tc.payments))
+				for i, payment := range mockRepo.payments[id] {
+					assert.Equal(t, tc.payments[i].PaymentMethod, payment.PaymentMethod)
+					assert.Equal(t, tc.payments[i].Amount, payment.Amount)
+				}
+			}
+		})
+	}
+
+	t.Run("single payment method records the full amount", func(t *testing.T) {
+		handler, mockRepo := setupTestHandler()
+
+		jsonData, _ := json.Marshal(models.CreateOrderRequest{PaymentMethod: "cash", Items: items})
+		w := httptest.NewRecorder()
+
+		handler.CreateOrder(w, httptest.NewRequest("POST", "/orders", bytes.NewBuffer(jsonData)))
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		for id := range mockRepo.orders {
+			require.Len(t, mockRepo.payments[id], 1)
+			assert.Equal(t, "cash", mockRepo.payments[id][0].PaymentMethod)
+			assert.InDelta(t, 61.5, mockRepo.payments[id][0].Amount, 0.001)
+		}
+	})
+}
+
+// TestCreateOrderDiscountPolicy tests discount bounds and percentage discounts on order creation
+func TestCreateOrderDiscountPolicy(t *testing.T) {
+	// Subtotal is 2 x 25.00 = 50.00; the test config caps discounts at 50%
+	items := []models.CreateOrderedRecipeRequest{{RecipeID: uuid.New(), Quantity: 2, UnitPrice: 25.0}}
+	percentage := func(p float64) *float64 { return &p }
+
+	testCases := map[string]struct {
+		discountAmount     float64
+		discountPercentage *float64
+		expectedStatus     int
+		expectedDiscount   float64
+	}{
+		"amount within policy": {
+			discountAmount:   10.0,
+			expectedStatus:   http.StatusCreated,
+			expectedDiscount: 10.0,
+		},
+		"percentage converted to amount": {
+			discountPercentage: percentage(20.0),
+			expectedStatus:     http.StatusCreated,
+			expectedDiscount:   10.0,
+		},
+		"excessive amount": {
+			discountAmount: 30.0,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		"excessive percentage": {
+			discountPercentage: percentage(75.0),
+			expectedStatus:     http.StatusUnprocessableEntity,
+		},
+		"negative amount": {
+			discountAmount: -5.0,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		"amount and percentage together": {
+			discountAmount:     5.0,
+			discountPercentage: percentage(10.0),
+			expectedStatus:     http.StatusUnprocessableEntity,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler, mockRepo := setupTestHandler()
+
+			jsonData, _ := json.Marshal(models.CreateOrderRequest{
+				PaymentMethod:      "cash",
+				Items:              items,
+				DiscountAmount:     tc.discountAmount,
+				DiscountPercentage: tc.discountPercentage,
+			})
+			w := httptest.NewRecorder()
+
+			handler.CreateOrder(w, httptest.NewRequest("POST", "/orders", bytes.NewBuffer(jsonData)))
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			if tc.expectedStatus != http.StatusCreated {
+				assert.Empty(t, mockRepo.orders)
+				return
+			}
+
+			require.Len(t, mockRepo.orders, 1)
+			for _, order := range mockRepo.orders {
+				assert.InDelta(t, tc.expectedDiscount, order.DiscountAmount, 0.001)
+				assert.InDelta(t, 50.0+6.5+5.0-tc.expectedDiscount, order.FinalAmount, 0.001)
+			}
+		})
+	}
+}
+
+// TestUpdateOrderDiscountPolicy tests that discount changes are bounded by the order subtotal
+func TestUpdateOrderDiscountPolicy(t *testing.T) {
+	percentage := 10.0
+	excessive := 60.0
+
+	testCases := map[string]struct {
+		update           models.UpdateOrderRequest
+		expectedStatus   int
+		expectedDiscount float64
+	}{
+		"percentage converted to amount": {
+			update:           models.UpdateOrderRequest{DiscountPercentage: &percentage},
+			expectedStatus:   http.StatusOK,
+			expectedDiscount: 10.0,
+		},
+		"excessive amount": {
+			update:         models.UpdateOrderRequest{DiscountAmount: &excessive},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler, mockRepo := setupTestHandler()
+
+			orderID := uuid.New()
+			mockRepo.orders[orderID] = &models.Order{ID: orderID, TotalAmount: 100.0, TaxAmount: 13.0, FinalAmount: 113.0, PaymentMethod: "cash", OrderStatus: "pending"}
+
+			jsonData, _ := json.Marshal(tc.update)
+			req := httptest.NewRequest("PUT", "/orders/"+orderID.String(), bytes.NewBuffer(jsonData))
+			req = mux.SetURLVars(req, map[string]string{"id": orderID.String()})
+			w := httptest.NewRecorder()
+
+			handler.UpdateOrder(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.InDelta(t, tc.expectedDiscount, mockRepo.orders[orderID].DiscountAmount, 0.001)
+		})
+	}
+}
+
 // TestGetOrder tests the get order endpoint
 func TestGetOrder(t *testing.T) {
 	handler, mockRepo := setupTestHandler()
@@ -411,6 +1068,157 @@ func TestGetOrder(t *testing.T) {
 	})
 }
 
+// TestGetOrderVersionNegotiation tests that the Accept header selects the order representation
+func TestGetOrderVersionNegotiation(t *testing.T) {
+	handler, mockRepo := setupTestHandler()
+
+	orderID := uuid.New()
+	mockRepo.orders[orderID] = &models.Order{
+		ID:             orderID,
+		OrderDate:      time.Now(),
+		TotalAmount:    30.0,
+		TaxAmount:      3.9,
+		ServiceAmount:  3.0,
+		DiscountAmount: 1.0,
+		FinalAmount:    35.9,
+		PaymentMethod:  "card",
+		OrderStatus:    "pending",
+	}
+	mockRepo.orderedRecipes[orderID] = []models.OrderedRecipe{
+		{ID: uuid.New(), OrderID: orderID, RecipeID: uuid.New(), Quantity: 3, UnitPrice: 10.0, TotalPrice: 30.0},
+	}
+
+	testCases := map[string]struct {
+		accept              string
+		expectedStatus      int
+		expectedContentType string
+		expectedVersion     int
+	}{
+		"v1 by default": {
+			expectedStatus: http.StatusOK, expectedContentType: "application/json", expectedVersion: APIVersion1,
+		},
+		"v1 for plain json": {
+			accept:         "application/json",
+			expectedStatus: http.StatusOK, expectedContentType: "application/json", expectedVersion: APIVersion1,
+		},
+		"v2 explicit": {
+			accept:         "application/vnd.icecream.v2+json",
+			expectedStatus: http.StatusOK, expectedContentType: "application/vnd.icecream.v2+json", expectedVersion: APIVersion2,
+		},
+		"unsupported version": {
+			accept:         "application/vnd.icecream.v7+json",
+			expectedStatus: http.StatusNotAcceptable, expectedContentType: "application/json",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/orders/"+orderID.String(), nil)
+			req = mux.SetURLVars(req, map[string]string{"id": orderID.String()})
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			w := httptest.NewRecorder()
+
+			handler.GetOrder(w, req)
+
+			require.Equal(t, tc.expectedStatus, w.Code)
+			assert.Equal(t, tc.expectedContentType, w.Header().Get("Content-Type"))
+			if tc.expectedStatus != http.StatusOK {
+				return
+			}
+			assert.Equal(t, "Accept", w.Header().Get("Vary"))
+
+			var response struct {
+				Data map[string]interface{} `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+			switch tc.expectedVersion {
+			case APIVersion1:
+				order, ok := response.Data["order"].(map[string]interface{})
+				require.True(t, ok)
+				assert.Equal(t, orderID.String(), order["id"])
+				assert.NotContains(t, response.Data, "totals")
+			case APIVersion2:
+				assert.Equal(t, orderID.String(), response.Data["id"])
+				assert.NotContains(t, response.Data, "order")
+				assert.Len(t, response.Data["items"], 1)
+
+				totals, ok := response.Data["totals"].(map[string]interface{})
+				require.True(t, ok)
+				// the stored amounts: 30.00 subtotal + 3.90 tax + 3.00 service - 1.00 discount
+				assert.Equal(t, 30.0, totals["subtotal"])
+				assert.Equal(t, 3.9, totals["tax"])
+				assert.Equal(t, 3.0, totals["service"])
+				assert.Equal(t, 35.9, totals["total"])
+			}
+		})
+	}
+}
+
+// TestGetOrderReceipt tests the printable receipt endpoint
+func TestGetOrderReceipt(t *testing.T) {
+	handler, mockRepo := setupTestHandler()
+
+	orderID := uuid.New()
+	mockRepo.orders[orderID] = &models.Order{
+		ID:             orderID,
+		OrderDate:      time.Date(2024, 6, 1, 15, 30, 0, 0, time.UTC),
+		TotalAmount:    5000.0,
+		TaxAmount:      650.0,
+		ServiceAmount:  500.0,
+		DiscountAmount: 250.0,
+		FinalAmount:    5900.0,
+		PaymentMethod:  "cash",
+		OrderStatus:    "completed",
+	}
+	items := []models.OrderedRecipe{
+		{ID: uuid.New(), OrderID: orderID, RecipeID: uuid.New(), Quantity: 2, UnitPrice: 1500.0, TotalPrice: 3000.0},
+		{ID: uuid.New(), OrderID: orderID, RecipeID: uuid.New(), Quantity: 1, UnitPrice: 2000.0, TotalPrice: 2000.0},
+	}
+	mockRepo.orderedRecipes[orderID] = items
+
+	testCases := map[string]struct {
+		id             string
+		expectedStatus int
+	}{
+		"existing order":   {id: orderID.String(), expectedStatus: http.StatusOK},
+		"missing order":    {id: uuid.New().String(), expectedStatus: http.StatusNotFound},
+		"invalid order id": {id: "not-a-uuid", expectedStatus: http.StatusBadRequest},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/orders/"+tc.id+"/receipt", nil)
+			req = mux.SetURLVars(req, map[string]string{"id": tc.id})
+			w := httptest.NewRecorder()
+
+			handler.GetOrderReceipt(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			if tc.expectedStatus != http.StatusOK {
+				return
+			}
+
+			assert.Equal(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+
+			receipt := w.Body.String()
+			for _, item := range items {
+				assert.Contains(t, receipt, fmt.Sprintf("%d x %s", item.Quantity, item.RecipeID))
+			}
+
+			// the receipt shows what was charged, which is what the shared calculation gives for the items
+			totals := models.CalculateOrderTotals(items, handler.config.DefaultTaxRate, handler.config.DefaultServiceRate, 250.0)
+			assert.Regexp(t, fmt.Sprintf(`(?m)^Subtotal +%s$`, totals.Subtotal), receipt)
+			assert.Regexp(t, fmt.Sprintf(`(?m)^Tax +%s$`, totals.Tax), receipt)
+			assert.Regexp(t, fmt.Sprintf(`(?m)^Service +%s$`, totals.Service), receipt)
+			assert.Regexp(t, `(?m)^Discount +-250\.00$`, receipt)
+			assert.Regexp(t, fmt.Sprintf(`(?m)^TOTAL +%s$`, totals.Total), receipt)
+		})
+	}
+}
+
 // TestUpdateOrder tests the update order endpoint
 func TestUpdateOrder(t *testing.T) {
 	handler, mockRepo := setupTestHandler()
@@ -474,6 +1282,205 @@ func TestUpdateOrder(t *testing.T) {
 	})
 }
 
+// TestUpdateOrderDiscountUpdatesReceipt tests that a discount change moves the stored total the receipt
+// prints, and that it needs payments once the recorded ones no longer add up
+func TestUpdateOrderDiscountUpdatesReceipt(t *testing.T) {
+	handler, mockRepo := setupTestHandler()
+
+	body, err := json.Marshal(models.CreateOrderRequest{
+		PaymentMethod: "cash",
+		Items:         []models.CreateOrderedRecipeRequest{{RecipeID: uuid.New(), Quantity: 2, UnitPrice: 50.0}},
+	})
+	require.NoError(t, err)
+	req := httptest.NewRequest("POST", "/orders", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	handler.CreateOrder(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var created struct {
+		Data models.OrderWithItems `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	orderID := created.Data.Order.ID.String()
+
+	update := func(update models.UpdateOrderRequest) int {
+		body, err := json.Marshal(update)
+		require.NoError(t, err)
+		req := httptest.NewRequest("PUT", "/orders/"+orderID, bytes.NewReader(body))
+		req = mux.SetURLVars(req, map[string]string{"id": orderID})
+		w := httptest.NewRecorder()
+		handler.UpdateOrder(w, req)
+		return w.Code
+	}
+
+	// the order was paid 123.00 in cash; a 10.00 discount leaves that payment 10.00 over
+	discount := 10.0
+	assert.Equal(t, http.StatusUnprocessableEntity, update(models.UpdateOrderRequest{DiscountAmount: &discount}))
+	assert.Zero(t, mockRepo.orders[created.Data.Order.ID].DiscountAmount)
+
+	assert.Equal(t, http.StatusOK, update(models.UpdateOrderRequest{
+		DiscountAmount: &discount,
+		Payments:       []models.PaymentRequest{{PaymentMethod: "cash", Amount: 113.0}},
+	}))
+
+	req = httptest.NewRequest("GET", "/orders/"+orderID+"/receipt", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": orderID})
+	w = httptest.NewRecorder()
+	handler.GetOrderReceipt(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	receipt := w.Body.String()
+	assert.Regexp(t, `(?m)^Discount +-10\.00$`, receipt)
+	assert.Regexp(t, `(?m)^TOTAL +113\.00$`, receipt)
+}
+
+// TestUpdateOrderStatusTransitions tests that an update only moves an order along its allowed transitions
+func TestUpdateOrderStatusTransitions(t *testing.T) {
+	testCases := map[string]struct {
+		from           string
+		to             string
+		expectedStatus int
+	}{
+		"pending to preparing":       {from: models.OrderStatusPending, to: models.OrderStatusPreparing, expectedStatus: http.StatusOK},
+		"keeping the current status": {from: models.OrderStatusPreparing, to: models.OrderStatusPreparing, expectedStatus: http.StatusOK},
+		"completed back to pending":  {from: models.OrderStatusCompleted, to: models.OrderStatusPending, expectedStatus: http.StatusConflict},
+		"cancelled back to pending":  {from: models.OrderStatusCancelled, to: models.OrderStatusPending, expectedStatus: http.StatusConflict},
+		"cancelled to completed":     {from: models.OrderStatusCancelled, to: models.OrderStatusCompleted, expectedStatus: http.StatusConflict},
+		"cancelling through update":  {from: models.OrderStatusPending, to: models.OrderStatusCancelled, expectedStatus: http.StatusUnprocessableEntity},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler, mockRepo := setupTestHandler()
+			orderID := uuid.New()
+			mockRepo.orders[orderID] = &models.Order{ID: orderID, TotalAmount: 100.0, PaymentMethod: "cash", OrderStatus: tc.from}
+
+			body, err := json.Marshal(models.UpdateOrderRequest{OrderStatus: &tc.to})
+			require.NoError(t, err)
+			req := httptest.NewRequest("PUT", "/orders/"+orderID.String(), bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req = mux.SetURLVars(req, map[string]string{"id": orderID.String()})
+			w := httptest.NewRecorder()
+
+			handler.UpdateOrder(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			if tc.expectedStatus != http.StatusOK {
+				assert.Equal(t, tc.from, mockRepo.orders[orderID].OrderStatus)
+			}
+		})
+	}
+}
+
+// TestUpdateOrderSplitPayments tests replacing an order's payments
+func TestUpdateOrderSplitPayments(t *testing.T) {
+	discount := 13.0
+
+	testCases := map[string]struct {
+		update         models.UpdateOrderRequest
+		expectedStatus int
+	}{
+		"payments matching the final amount": {
+			update:         models.UpdateOrderRequest{Payments: []models.PaymentRequest{{PaymentMethod: "cash", Amount: 50.0}, {PaymentMethod: "card", Amount: 63.0}}},
+			expectedStatus: http.StatusOK,
+		},
+		"payments matching the discounted amount": {
+			update: models.UpdateOrderRequest{
+				DiscountAmount: &discount,
+				Payments:       []models.PaymentRequest{{PaymentMethod: "cash", Amount: 50.0}, {PaymentMethod: "sinpe", Amount: 50.0}},
+			},
+			expectedStatus: http.StatusOK,
+		},
+		"underpayment": {
+			update:         models.UpdateOrderRequest{Payments: []models.PaymentRequest{{PaymentMethod: "cash", Amount: 50.0}}},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		"overpayment": {
+			update:         models.UpdateOrderRequest{Payments: []models.PaymentRequest{{PaymentMethod: "cash", Amount: 100.0}, {PaymentMethod: "card", Amount: 100.0}}},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler, mockRepo := setupTestHandler()
+
+			orderID := uuid.New()
+			mockRepo.orders[orderID] = &models.Order{
+				ID:            orderID,
+				TotalAmount:   100.0,
+				TaxAmount:     13.0,
+				FinalAmount:   113.0,
+				PaymentMethod: "cash",
+				OrderStatus:   "pending",
+			}
+
+			jsonData, _ := json.Marshal(tc.update)
+			req := httptest.NewRequest("PUT", "/orders/"+orderID.String(), bytes.NewBuffer(jsonData))
+			req = mux.SetURLVars(req, map[string]string{"id": orderID.String()})
+			w := httptest.NewRecorder()
+
+			handler.UpdateOrder(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			if tc.expectedStatus == http.StatusOK {
+				assert.Equal(t, models.PaymentMethodSplit, mockRepo.orders[orderID].PaymentMethod)
+				assert.Len(t, mockRepo.payments[orderID], len(tc.update.Payments))
+			} else {
+				assert.Equal(t, "cash", mockRepo.orders[orderID].PaymentMethod)
+				assert.Empty(t, mockRepo.payments[orderID])
+			}
+		})
+	}
+}
+
+// TestUpdateOrderPaymentMethodReplacesPayment tests that changing only the payment method moves the order's payment to it
+func TestUpdateOrderPaymentMethodReplacesPayment(t *testing.T) {
+	testCases := map[string]struct {
+		payments         []models.Payment
+		expectedStatus   int
+		expectedPayments []models.PaymentRequest
+	}{
+		"single payment": {
+			payments:         []models.Payment{{PaymentMethod: "cash", Amount: 113.0}},
+			expectedStatus:   http.StatusOK,
+			expectedPayments: []models.PaymentRequest{{PaymentMethod: "card", Amount: 113.0}},
+		},
+		"split payments": {
+			payments:         []models.Payment{{PaymentMethod: "cash", Amount: 50.0}, {PaymentMethod: "sinpe", Amount: 63.0}},
+			expectedStatus:   http.StatusUnprocessableEntity,
+			expectedPayments: []models.PaymentRequest{{PaymentMethod: "cash", Amount: 50.0}, {PaymentMethod: "sinpe", Amount: 63.0}},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler, mockRepo := setupTestHandler()
+
+			orderID := uuid.New()
+			mockRepo.orders[orderID] = &models.Order{
+				ID:            orderID,
+				TotalAmount:   100.0,
+				TaxAmount:     13.0,
+				FinalAmount:   113.0,
+				PaymentMethod: models.PaymentsMethod(models.PaymentRequestsFrom(tc.payments)),
+				OrderStatus:   "pending",
+			}
+			mockRepo.payments[orderID] = tc.payments
+
+			jsonData, _ := json.Marshal(map[string]string{"payment_method": "card"})
+			req := httptest.NewRequest("PUT", "/orders/"+orderID.String(), bytes.NewBuffer(jsonData))
+			req = mux.SetURLVars(req, map[string]string{"id": orderID.String()})
+			w := httptest.NewRecorder()
+
+			handler.UpdateOrder(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Equal(t, tc.expectedPayments, models.PaymentRequestsFrom(mockRepo.payments[orderID]))
+		})
+	}
+}
+
 // TestCancelOrder tests the cancel order endpoint
 func TestCancelOrder(t *testing.T) {
 	handler, mockRepo := setupTestHandler()
@@ -492,7 +1499,7 @@ func TestCancelOrder(t *testing.T) {
 	mockRepo.orders[orderID] = testOrder
 
 	t.Run("successful order cancellation", func(t *testing.T) {
-		req := httptest.NewRequest("DELETE", "/orders/"+orderID.String(), nil)
+		req := httptest.NewRequest("POST", "/orders/"+orderID.String()+"/cancel", nil)
 		req = mux.SetURLVars(req, map[string]string{"id": orderID.String()})
 		w := httptest.NewRecorder()
 
@@ -502,8 +1509,42 @@ func TestCancelOrder(t *testing.T) {
 		assert.Equal(t, "cancelled", testOrder.OrderStatus)
 	})
 
+	t.Run("repeat cancellation conflicts", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/orders/"+orderID.String()+"/cancel", nil)
+		req = mux.SetURLVars(req, map[string]string{"id": orderID.String()})
+		w := httptest.NewRecorder()
+
+		handler.CancelOrder(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("completed order conflicts", func(t *testing.T) {
+		completedID := uuid.New()
+		mockRepo.orders[completedID] = &models.Order{ID: completedID, OrderStatus: "completed"}
+		req := httptest.NewRequest("POST", "/orders/"+completedID.String()+"/cancel", nil)
+		req = mux.SetURLVars(req, map[string]string{"id": completedID.String()})
+		w := httptest.NewRecorder()
+
+		handler.CancelOrder(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Equal(t, "completed", mockRepo.orders[completedID].OrderStatus)
+	})
+
+	t.Run("missing order", func(t *testing.T) {
+		missingID := uuid.New()
+		req := httptest.NewRequest("POST", "/orders/"+missingID.String()+"/cancel", nil)
+		req = mux.SetURLVars(req, map[string]string{"id": missingID.String()})
+		w := httptest.NewRecorder()
+
+		handler.CancelOrder(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
 	t.Run("invalid order ID", func(t *testing.T) {
-		req := httptest.NewRequest("DELETE", "/orders/invalid-id", nil)
+		req := httptest.NewRequest("POST", "/orders/invalid-id/cancel", nil)
 		req = mux.SetURLVars(req, map[string]string{"id": "invalid-id"})
 		w := httptest.NewRecorder()
 
@@ -513,6 +1554,421 @@ func TestCancelOrder(t *testing.T) {
 	})
 }
 
+// TestBulkUpdateOrderStatus tests that legal transitions are applied and illegal ones reported per order
+func TestBulkUpdateOrderStatus(t *testing.T) {
+	handler, mockRepo := setupTestHandler()
+
+	pendingID := uuid.New()
+	preparingID := uuid.New()
+	completedID := uuid.New()
+	missingID := uuid.New()
+	mockRepo.orders[pendingID] = &models.Order{ID: pendingID, OrderStatus: "pending"}
+	mockRepo.orders[preparingID] = &models.Order{ID: preparingID, OrderStatus: "preparing"}
+	mockRepo.orders[completedID] = &models.Order{ID: completedID, OrderStatus: "completed"}
+
+	body, err := json.Marshal(models.BulkStatusRequest{
+		IDs:    []uuid.UUID{pendingID, completedID, preparingID, missingID},
+		Status: "preparing",
+	})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("POST", "/orders/bulk-status", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	handler.BulkUpdateOrderStatus(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data models.BulkStatusResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.Data.Updated)
+	assert.Equal(t, 3, response.Data.Failed)
+
+	results := response.Data.Results
+	require.Len(t, results, 4)
+	assert.Equal(t, models.BulkStatusResult{OrderID: pendingID, Updated: true, PreviousStatus: "pending", Status: "preparing"}, results[0])
+	assert.Equal(t, "cannot move a completed order to preparing", results[1].Error)
+	assert.Equal(t, "cannot move a preparing order to preparing", results[2].Error)
+	assert.Equal(t, "order not found", results[3].Error)
+
+	assert.Equal(t, "preparing", mockRepo.orders[pendingID].OrderStatus)
+	assert.Equal(t, "completed", mockRepo.orders[completedID].OrderStatus)
+}
+
+// TestBulkUpdateOrderStatusValidation tests that malformed bulk requests are rejected before any order changes
+func TestBulkUpdateOrderStatusValidation(t *testing.T) {
+	orderID := uuid.New()
+
+	testCases := map[string]struct {
+		body           string
+		shouldError    bool
+		expectedStatus int
+	}{
+		"malformed json":     {body: `{"ids":`, expectedStatus: http.StatusBadRequest},
+		"no ids":             {body: `{"ids":[],"status":"completed"}`, expectedStatus: http.StatusBadRequest},
+		"duplicate ids":      {body: `{"ids":["` + orderID.String() + `","` + orderID.String() + `"],"status":"completed"}`, expectedStatus: http.StatusBadRequest},
+		"cancel not in bulk": {body: `{"ids":["` + orderID.String() + `"],"status":"cancelled"}`, expectedStatus: http.StatusBadRequest},
+		"repository error":   {body: `{"ids":["` + orderID.String() + `"],"status":"completed"}`, shouldError: true, expectedStatus: http.StatusInternalServerError},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler, mockRepo := setupTestHandler()
+			mockRepo.orders[orderID] = &models.Order{ID: orderID, OrderStatus: "pending"}
+			mockRepo.shouldError = tc.shouldError
+			mockRepo.errorMessage = "database unavailable"
+
+			req := httptest.NewRequest("POST", "/orders/bulk-status", bytes.NewBufferString(tc.body))
+			w := httptest.NewRecorder()
+
+			handler.BulkUpdateOrderStatus(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Equal(t, "pending", mockRepo.orders[orderID].OrderStatus)
+		})
+	}
+}
+
+// TestOrderWritesRejectUnknownFields tests that misspelled fields are reported instead of silently dropped
+func TestOrderWritesRejectUnknownFields(t *testing.T) {
+	orderID := uuid.New()
+
+	testCases := map[string]struct {
+		body          string
+		call          func(h *ordersHandler, w http.ResponseWriter, r *http.Request)
+		expectedField string
+	}{
+		"create order": {
+			body:          `{"payment_method":"cash","itmes":[]}`,
+			call:          func(h *ordersHandler, w http.ResponseWriter, r *http.Request) { h.CreateOrder(w, r) },
+			expectedField: "itmes",
+		},
+		"update order": {
+			body:          `{"discount":5}`,
+			call:          func(h *ordersHandler, w http.ResponseWriter, r *http.Request) { h.UpdateOrder(w, r) },
+			expectedField: "discount",
+		},
+		"bulk status update": {
+			body:          `{"ids":["` + orderID.String() + `"],"state":"completed"}`,
+			call:          func(h *ordersHandler, w http.ResponseWriter, r *http.Request) { h.BulkUpdateOrderStatus(w, r) },
+			expectedField: "state",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler, mockRepo := setupTestHandler()
+			mockRepo.orders[orderID] = &models.Order{ID: orderID, OrderStatus: models.OrderStatusPending}
+
+			req := httptest.NewRequest("POST", "/orders", bytes.NewBufferString(tc.body))
+			req = mux.SetURLVars(req, map[string]string{"id": orderID.String()})
+			w := httptest.NewRecorder()
+
+			tc.call(handler, w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(), `unknown field \"`+tc.expectedField+`\" in request body`)
+			assert.Equal(t, models.OrderStatusPending, mockRepo.orders[orderID].OrderStatus)
+		})
+	}
+}
+
+// TestGetConfig tests that the configuration is returned with its secrets redacted
+func TestGetConfig(t *testing.T) {
+	handler, _ := setupTestHandler()
+	handler.config.DBPassword = "db-password-value"
+	handler.config.JWTSecret = "jwt-secret-value"
+
+	w := httptest.NewRecorder()
+	handler.GetConfig(w, httptest.NewRequest("GET", "/config", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "db-password-value")
+	assert.NotContains(t, w.Body.String(), "jwt-secret-value")
+
+	var response struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, config.RedactedValue, response.Data["DB_PASSWORD"])
+	assert.Equal(t, config.RedactedValue, response.Data["JWT_SECRET"])
+	assert.Equal(t, 13.0, response.Data["DEFAULT_TAX_RATE"])
+}
+
+// TestGetOrderTimeline tests that the timeline lists create, update and complete in order with their actor
+func TestGetOrderTimeline(t *testing.T) {
+	handler, _ := setupTestHandler()
+	ctx := models.WithActor(context.Background(), "cashier-1")
+
+	body, err := json.Marshal(models.CreateOrderRequest{
+		PaymentMethod: "cash",
+		Items:         []models.CreateOrderedRecipeRequest{{RecipeID: uuid.New(), Quantity: 1, UnitPrice: 10.0}},
+	})
+	require.NoError(t, err)
+	req := httptest.NewRequest("POST", "/orders", bytes.NewReader(body)).WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	handler.CreateOrder(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var created struct {
+		Data models.OrderWithItems `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	orderID := created.Data.Order.ID.String()
+
+	for _, status := range []string{"preparing", "completed"} {
+		body, err := json.Marshal(models.UpdateOrderRequest{OrderStatus: &status})
+		require.NoError(t, err)
+		req := httptest.NewRequest("PUT", "/orders/"+orderID, bytes.NewReader(body)).WithContext(ctx)
+		req.Header.Set("Content-Type", "application/json")
+		req = mux.SetURLVars(req, map[string]string{"id": orderID})
+		w := httptest.NewRecorder()
+		handler.UpdateOrder(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+
+	req = httptest.NewRequest("GET", "/orders/"+orderID+"/timeline", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": orderID})
+	w = httptest.NewRecorder()
+	handler.GetOrderTimeline(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data models.OrderTimeline `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "completed", response.Data.OrderStatus)
+
+	events := response.Data.Events
+	require.Len(t, events, 3)
+	assert.Equal(t, models.OrderEventCreated, events[0].EventType)
+	assert.Equal(t, "pending", events[0].OrderStatus)
+	assert.Equal(t, models.OrderEventStatusChanged, events[1].EventType)
+	assert.Equal(t, "preparing", events[1].OrderStatus)
+	assert.Equal(t, models.OrderEventStatusChanged, events[2].EventType)
+	assert.Equal(t, "completed", events[2].OrderStatus)
+	for i, event := range events {
+		require.NotNil(t, event.Actor)
+		assert.Equal(t, "cashier-1", *event.Actor)
+		if i > 0 {
+			assert.False(t, event.CreatedAt.Before(events[i-1].CreatedAt))
+		}
+	}
+}
+
+// TestGetOrderTimelineErrors tests the responses for bad and unknown order IDs
+func TestGetOrderTimelineErrors(t *testing.T) {
+	testCases := map[string]struct {
+		id             string
+		expectedStatus int
+	}{
+		"invalid order ID": {id: "invalid-id", expectedStatus: http.StatusBadRequest},
+		"unknown order":    {id: uuid.New().String(), expectedStatus: http.StatusNotFound},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler, _ := setupTestHandler()
+
+			req := httptest.NewRequest("GET", "/orders/"+tc.id+"/timeline", nil)
+			req = mux.SetURLVars(req, map[string]string{"id": tc.id})
+			w := httptest.NewRecorder()
+			handler.GetOrderTimeline(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+		})
+	}
+}
+
+// TestCancelOrderReason tests that the cancellation reason is validated, defaulted and recorded
+func TestCancelOrderReason(t *testing.T) {
+	testCases := map[string]struct {
+		body           string
+		expectedStatus int
+		expectedReason string
+	}{
+		"provided reason":     {body: `{"reason":"out_of_stock"}`, expectedStatus: http.StatusOK, expectedReason: "out_of_stock"},
+		"no body defaults":    {body: "", expectedStatus: http.StatusOK, expectedReason: "other"},
+		"empty reason":        {body: `{}`, expectedStatus: http.StatusOK, expectedReason: "other"},
+		"invalid reason":      {body: `{"reason":"bored"}`, expectedStatus: http.StatusUnprocessableEntity},
+		"malformed json body": {body: `{"reason":`, expectedStatus: http.StatusBadRequest},
+		"unknown field":       {body: `{"reason":"out_of_stock","note":"x"}`, expectedStatus: http.StatusBadRequest},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler, mockRepo := setupTestHandler()
+			orderID := uuid.New()
+			mockRepo.orders[orderID] = &models.Order{ID: orderID, OrderStatus: "pending"}
+
+			req := httptest.NewRequest("POST", "/orders/"+orderID.String()+"/cancel", bytes.NewBufferString(tc.body))
+			req = mux.SetURLVars(req, map[string]string{"id": orderID.String()})
+			w := httptest.NewRecorder()
+
+			handler.CancelOrder(w, req)
+
+			require.Equal(t, tc.expectedStatus, w.Code)
+			order := mockRepo.orders[orderID]
+			if tc.expectedStatus != http.StatusOK {
+				assert.Equal(t, "pending", order.OrderStatus)
+				assert.Nil(t, order.CancellationReason)
+				return
+			}
+
+			require.NotNil(t, order.CancellationReason)
+			assert.Equal(t, tc.expectedReason, *order.CancellationReason)
+
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			data := response["data"].(map[string]interface{})
+			assert.Equal(t, tc.expectedReason, data["cancellation_reason"])
+
+			// The reason is surfaced on the order and in the summary breakdown
+			getReq := mux.SetURLVars(httptest.NewRequest("GET", "/orders/"+orderID.String(), nil), map[string]string{"id": orderID.String()})
+			getW := httptest.NewRecorder()
+			handler.GetOrder(getW, getReq)
+			assert.Contains(t, getW.Body.String(), `"cancellation_reason":"`+tc.expectedReason+`"`)
+
+			summaryW := httptest.NewRecorder()
+			handler.GetOrderSummary(summaryW, httptest.NewRequest("GET", "/orders/summary", nil))
+			assert.Contains(t, summaryW.Body.String(), `"cancellation_reasons":{"`+tc.expectedReason+`":1}`)
+		})
+	}
+}
+
+// TestCancelOrderRepeatKeepsFirstReason tests that cancelling an already cancelled order neither overwrites
+// the recorded reason nor records a second cancellation event
+func TestCancelOrderRepeatKeepsFirstReason(t *testing.T) {
+	handler, mockRepo := setupTestHandler()
+	orderID := uuid.New()
+	mockRepo.orders[orderID] = &models.Order{ID: orderID, OrderStatus: "pending"}
+
+	published := make(chan eventbus.Event, 2)
+	handler.EventBus().Subscribe(events.OrderStatusEvent{Action: events.ActionCancelled}.EventType(), func(event eventbus.Event) {
+		published <- event
+	})
+
+	cancel := func(body string) int {
+		req := httptest.NewRequest("POST", "/orders/"+orderID.String()+"/cancel", bytes.NewBufferString(body))
+		req = mux.SetURLVars(req, map[string]string{"id": orderID.String()})
+		w := httptest.NewRecorder()
+		handler.CancelOrder(w, req)
+		return w.Code
+	}
+
+	require.Equal(t, http.StatusOK, cancel(`{"reason":"out_of_stock"}`))
+	assert.Equal(t, http.StatusConflict, cancel(`{"reason":"customer_request"}`))
+
+	order := mockRepo.orders[orderID]
+	require.NotNil(t, order.CancellationReason)
+	assert.Equal(t, "out_of_stock", *order.CancellationReason)
+
+	cancellations := 0
+	for _, event := range mockRepo.events[orderID] {
+		if event.EventType == models.OrderEventCancelled {
+			cancellations++
+		}
+	}
+	assert.Equal(t, 1, cancellations)
+
+	// Only the first cancellation is published; handlers run asynchronously so give a second one time to arrive
+	select {
+	case <-published:
+	case <-time.After(2 * time.Second):
+		t.Fatal("cancellation event was not published")
+	}
+	select {
+	case event := <-published:
+		t.Fatalf("unexpected second cancellation event: %+v", event)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// TestReopenOrder tests the reopen order endpoint
+func TestReopenOrder(t *testing.T) {
+	tests := map[string]struct {
+		status         string
+		cancelledAgo   time.Duration
+		missing        bool
+		expectedStatus int
+		expectedOrder  string
+	}{
+		"cancelled within grace period": {
+			status:         "cancelled",
+			cancelledAgo:   5 * time.Minute,
+			expectedStatus: http.StatusOK,
+			expectedOrder:  "pending",
+		},
+		"cancelled after grace period": {
+			status:         "cancelled",
+			cancelledAgo:   30 * time.Minute,
+			expectedStatus: http.StatusConflict,
+			expectedOrder:  "cancelled",
+		},
+		"order not cancelled": {
+			status:         "confirmed",
+			expectedStatus: http.StatusConflict,
+			expectedOrder:  "confirmed",
+		},
+		"order not found": {
+			missing:        true,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			handler, mockRepo := setupTestHandler()
+			now := time.Date(2024, time.March, 1, 12, 30, 0, 0, time.UTC)
+			handler.clock = ids.FixedClock{Time: now}
+
+			orderID := uuid.New()
+			testOrder := &models.Order{
+				ID:            orderID,
+				OrderDate:     now,
+				TotalAmount:   100.0,
+				PaymentMethod: "cash",
+				OrderStatus:   tc.status,
+				CreatedAt:     now,
+				UpdatedAt:     now,
+			}
+			if tc.status == "cancelled" {
+				cancelledAt := now.Add(-tc.cancelledAgo)
+				testOrder.CancelledAt = &cancelledAt
+			}
+			if !tc.missing {
+				mockRepo.orders[orderID] = testOrder
+			}
+
+			req := httptest.NewRequest("POST", "/orders/"+orderID.String()+"/reopen", nil)
+			req = mux.SetURLVars(req, map[string]string{"id": orderID.String()})
+			w := httptest.NewRecorder()
+
+			handler.ReopenOrder(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			if !tc.missing {
+				assert.Equal(t, tc.expectedOrder, testOrder.OrderStatus)
+			}
+		})
+	}
+
+	t.Run("invalid order ID", func(t *testing.T) {
+		handler, _ := setupTestHandler()
+
+		req := httptest.NewRequest("POST", "/orders/invalid-id/reopen", nil)
+		req = mux.SetURLVars(req, map[string]string{"id": "invalid-id"})
+		w := httptest.NewRecorder()
+
+		handler.ReopenOrder(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
 // TestListOrders tests the list orders endpoint
 func TestListOrders(t *testing.T) {
 	handler, mockRepo := setupTestHandler()
@@ -556,6 +2012,74 @@ func TestListOrders(t *testing.T) {
 		require.True(t, ok)
 		assert.Len(t, orders, 3)
 	})
+
+	paginationCases := map[string]struct {
+		query           string
+		expectedLen     int
+		expectedHasMore bool
+	}{
+		"window smaller than total": {query: "?limit=2", expectedLen: 2, expectedHasMore: true},
+		"last page":                 {query: "?limit=2&offset=2", expectedLen: 1, expectedHasMore: false},
+		"window covers everything":  {query: "?limit=10", expectedLen: 3, expectedHasMore: false},
+	}
+
+	for name, tc := range paginationCases {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/orders"+tc.query, nil)
+			w := httptest.NewRecorder()
+
+			handler.ListOrders(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+			data, ok := response["data"].(map[string]interface{})
+			require.True(t, ok)
+			assert.Len(t, data["orders"], tc.expectedLen)
+
+			pagination, ok := data["pagination"].(map[string]interface{})
+			require.True(t, ok)
+			assert.Equal(t, float64(3), pagination["total"])
+			assert.Equal(t, tc.expectedHasMore, pagination["has_more"])
+			assert.Equal(t, tc.expectedHasMore, data["has_more"])
+		})
+	}
+}
+
+// TestListOrdersMaxPageSize tests that limits above the configured maximum are reduced with a warning header
+func TestListOrdersMaxPageSize(t *testing.T) {
+	testCases := map[string]struct {
+		maxPageSize     int
+		query           string
+		expectedLimit   float64
+		expectedWarning bool
+	}{
+		"within limit":              {maxPageSize: 100, query: "?limit=100", expectedLimit: 100},
+		"over limit is clamped":     {maxPageSize: 100, query: "?limit=500", expectedLimit: 100, expectedWarning: true},
+		"default limit within cap":  {maxPageSize: 20, query: "", expectedLimit: 20},
+		"zero maximum disables cap": {maxPageSize: 0, query: "?limit=500", expectedLimit: 500},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler, _ := setupTestHandler()
+			handler.config.MaxPageSize = tc.maxPageSize
+
+			w := httptest.NewRecorder()
+			handler.ListOrders(w, httptest.NewRequest("GET", "/orders"+tc.query, nil))
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tc.expectedWarning, w.Header().Get(httpx.PageSizeWarningHeader) != "")
+
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			data, ok := response["data"].(map[string]interface{})
+			require.True(t, ok)
+			assert.Equal(t, tc.expectedLimit, data["limit"])
+		})
+	}
 }
 
 // TestGetOrderSummary tests the order summary endpoint
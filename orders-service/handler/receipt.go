@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+
+	"orders-service/models"
+	"shared/money"
+)
+
+// receiptWidth is the character width of a printed receipt line
+const receiptWidth = 40
+
+// renderReceipt formats an order and its totals as a plain-text customer receipt
+func renderReceipt(order *models.OrderWithItems, totals models.OrderTotals) string {
+	var b strings.Builder
+	separator := strings.Repeat("-", receiptWidth) + "\n"
+
+	b.WriteString(centerReceiptLine("ICE CREAM STORE"))
+	b.WriteString(separator)
+	if order.Order.OrderNumber != "" {
+		fmt.Fprintf(&b, "Number:  %s\n", order.Order.OrderNumber)
+	}
+	fmt.Fprintf(&b, "Order:   %s\n", order.Order.ID)
+	fmt.Fprintf(&b, "Date:    %s\n", order.Order.OrderDate.Format("2006-01-02 15:04"))
+	fmt.Fprintf(&b, "Payment: %s\n", order.Order.PaymentMethod)
+	b.WriteString(separator)
+
+	for _, item := range order.Items {
+		fmt.Fprintf(&b, "%d x %s\n", item.Quantity, item.RecipeID)
+		if item.SpecialInstructions != nil && *item.SpecialInstructions != "" {
+			fmt.Fprintf(&b, "    %s\n", *item.SpecialInstructions)
+		}
+		b.WriteString(receiptAmountLine(fmt.Sprintf("    @ %.2f", item.UnitPrice), models.LineTotal(item.Quantity, item.UnitPrice)))
+	}
+
+	b.WriteString(separator)
+	b.WriteString(receiptAmountLine("Subtotal", totals.Subtotal))
+	b.WriteString(receiptAmountLine("Tax", totals.Tax))
+	b.WriteString(receiptAmountLine("Service", totals.Service))
+	b.WriteString(receiptAmountLine("Discount", -totals.Discount))
+	b.WriteString(separator)
+	b.WriteString(receiptAmountLine("TOTAL", totals.Total))
+	b.WriteString(separator)
+	b.WriteString(centerReceiptLine("Thank you for your visit!"))
+
+	return b.String()
+}
+
+// receiptAmountLine left-aligns label and right-aligns amount within the receipt width
+func receiptAmountLine(label string, amount money.Money) string {
+	value := amount.String()
+	padding := receiptWidth - len(label) - len(value)
+	if padding < 1 {
+		padding = 1
+	}
+	return label + strings.Repeat(" ", padding) + value + "\n"
+}
+
+// centerReceiptLine centers text within the receipt width
+func centerReceiptLine(text string) string {
+	padding := (receiptWidth - len(text)) / 2
+	if padding < 0 {
+		padding = 0
+	}
+	return strings.Repeat(" ", padding) + text + "\n"
+}
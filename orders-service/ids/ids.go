@@ -0,0 +1,35 @@
+package ids
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NewUUID returns a new random identifier for a row generated in Go rather than by a database default
+func NewUUID() uuid.UUID {
+	return uuid.New()
+}
+
+// Clock supplies the current time so code stamping CreatedAt/UpdatedAt can be given a fixed time in tests
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock reads the wall clock
+type SystemClock struct{}
+
+// Now returns the current local time
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// FixedClock always returns the same time
+type FixedClock struct {
+	Time time.Time
+}
+
+// Now returns the fixed time
+func (c FixedClock) Now() time.Time {
+	return c.Time
+}
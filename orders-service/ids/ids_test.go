@@ -0,0 +1,51 @@
+package ids
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNewUUID tests that generated identifiers are set and unique
+func TestNewUUID(t *testing.T) {
+	first := NewUUID()
+	second := NewUUID()
+
+	assert.NotEqual(t, uuid.Nil, first)
+	assert.NotEqual(t, first, second)
+}
+
+// TestClocks tests that the fixed clock never moves and the system clock follows the wall clock
+func TestClocks(t *testing.T) {
+	fixed := time.Date(2024, time.March, 1, 12, 30, 0, 0, time.UTC)
+
+	testCases := map[string]struct {
+		clock  Clock
+		verify func(t *testing.T, first, second time.Time)
+	}{
+		"fixed clock": {
+			clock: FixedClock{Time: fixed},
+			verify: func(t *testing.T, first, second time.Time) {
+				assert.Equal(t, fixed, first)
+				assert.Equal(t, fixed, second)
+			},
+		},
+		"system clock": {
+			clock: SystemClock{},
+			verify: func(t *testing.T, first, second time.Time) {
+				assert.WithinDuration(t, time.Now(), first, time.Second)
+				assert.False(t, second.Before(first))
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			first := tc.clock.Now()
+			second := tc.clock.Now()
+			tc.verify(t, first, second)
+		})
+	}
+}
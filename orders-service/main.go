@@ -1,7 +1,9 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
@@ -11,6 +13,12 @@ import (
 
 	"orders-service/config"
 	"orders-service/handler"
+	"orders-service/outbox"
+	ordersql "orders-service/sql"
+	"orders-service/utils"
+	"shared/httpx"
+	"shared/schema"
+	"shared/version"
 
 	// Removed middleware import - gateway handles all auth
 
@@ -19,12 +27,25 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// requiredTables are the tables the service queries; startup fails when any is missing
+var requiredTables = []string{
+	"orders",
+	"ordered_receipes",
+	"order_payments",
+	"order_events",
+	"order_number_counters",
+	"outbox",
+}
+
 func main() {
 	// Load configuration
-	cfg := config.LoadConfig()
+	cfg, cfgErr := config.Load()
 
 	// Setup logger
 	logger := setupLogger(cfg.LogLevel)
+	if cfgErr != nil {
+		logger.WithError(cfgErr).Fatal("Invalid configuration")
+	}
 	logger.Info("Starting Ice Cream Store Orders Service")
 
 	// Connect to database
@@ -34,14 +55,30 @@ func main() {
 	}
 	defer db.Close()
 
+	// Fail fast with the missing table names instead of query errors on the first request
+	checkCtx, cancelCheck := context.WithTimeout(context.Background(), 10*time.Second)
+	err = schema.CheckRequiredTables(checkCtx, db, requiredTables)
+	cancelCheck()
+	if err != nil {
+		logger.WithError(err).Fatal("Database schema self-check failed")
+	}
+	logger.WithField("tables", len(requiredTables)).Info("Database schema self-check passed")
+
 	// Create orders handler
 	ordersHandler, err := handler.New(db, cfg, logger)
 	if err != nil {
 		logger.WithError(err).Fatal("Failed to create orders handler")
 	}
 
+	// Start the outbox relay
+	relayCtx, stopRelay := context.WithCancel(context.Background())
+	defer stopRelay()
+	if err := startOutboxRelay(relayCtx, db, cfg, ordersHandler, logger); err != nil {
+		logger.WithError(err).Fatal("Failed to start outbox relay")
+	}
+
 	// Setup HTTP router
-	router := setupRouter(ordersHandler, logger)
+	router := setupRouter(ordersHandler, time.Duration(cfg.RequestTimeout)*time.Second, logger)
 
 	// Start HTTP server
 	server := &http.Server{
@@ -70,6 +107,7 @@ func main() {
 	<-quit
 
 	logger.Info("Shutting down orders service...")
+	stopRelay()
 
 	// Graceful shutdown
 	if err := server.Close(); err != nil {
@@ -141,8 +179,30 @@ func connectToDatabase(cfg *config.Config, logger *logrus.Logger) (*sql.DB, erro
 	return db, nil
 }
 
+// startOutboxRelay delivers outbox messages to the configured target service, or the event bus when none is set
+func startOutboxRelay(ctx context.Context, db *sql.DB, cfg *config.Config, ordersHandler handler.OrdersHandler, logger *logrus.Logger) error {
+	repo, err := ordersql.NewRepository(db)
+	if err != nil {
+		return fmt.Errorf("failed to create outbox repository: %w", err)
+	}
+
+	var deliverer outbox.Deliverer = outbox.NewBusDeliverer(ordersHandler.EventBus())
+	if cfg.OutboxTargetURL != "" {
+		deliverer = outbox.NewHTTPDeliverer(cfg.OutboxTargetURL)
+	}
+
+	relay := outbox.NewRelay(repo, deliverer, time.Duration(cfg.OutboxPollInterval)*time.Second, cfg.OutboxMaxAttempts, logger)
+	go relay.Start(ctx)
+
+	logger.WithFields(logrus.Fields{
+		"target":        cfg.OutboxTargetURL,
+		"poll_interval": cfg.OutboxPollInterval,
+	}).Info("Outbox relay started")
+	return nil
+}
+
 // setupRouter configures the HTTP routes
-func setupRouter(ordersHandler handler.OrdersHandler, logger *logrus.Logger) *mux.Router {
+func setupRouter(ordersHandler handler.OrdersHandler, requestTimeout time.Duration, logger *logrus.Logger) *mux.Router {
 	router := mux.NewRouter()
 
 	// Create middleware
@@ -152,6 +212,27 @@ func setupRouter(ordersHandler handler.OrdersHandler, logger *logrus.Logger) *mu
 	// Removed authMiddleware.LoggingMiddleware - gateway handles all logging
 	// router.Use(authMiddleware.CORS) // Disabled: Gateway handles CORS for all services
 
+	// Answer handler panics with a JSON 500 instead of dropping the connection
+	router.Use(httpx.RecoveryMiddleware(logger))
+
+	// Compress large responses for clients that accept gzip
+	router.Use(httpx.GzipMiddleware(httpx.GzipMinSize))
+
+	// Indent JSON responses for ?pretty=true (before gzip sees the body)
+	router.Use(httpx.PrettyJSONMiddleware)
+
+	// Bound handler run time; the WebSocket feed and the export stream are long-lived by design
+	router.Use(httpx.TimeoutMiddleware(requestTimeout, map[string]time.Duration{
+		"/api/v1/orders/ws":     0,
+		"/api/v1/orders/export": 0,
+	}))
+
+	// Write endpoints only accept JSON bodies
+	router.Use(httpx.RequireJSONMiddleware)
+
+	// Attribute order timeline events to the user the gateway authenticated
+	router.Use(utils.ActorMiddleware)
+
 	// Public routes (no authentication required)
 	publicRouter := router.PathPrefix("/api/v1").Subrouter()
 	publicRouter.HandleFunc("/orders/p/health", ordersHandler.HealthCheck).Methods("GET")
@@ -166,11 +247,41 @@ func setupRouter(ordersHandler handler.OrdersHandler, logger *logrus.Logger) *mu
 		// Removed authMiddleware.RequireOrdersPermission("write") - gateway handles all auth
 		http.HandlerFunc(ordersHandler.CreateOrder)).Methods("POST")
 
+	// Order status updates over WebSocket - registered before /orders/{id} so "ws" is not taken as an ID
+	protectedRouter.Handle("/orders/ws",
+		// Removed authMiddleware.RequireOrdersPermission("read") - gateway handles all auth
+		http.HandlerFunc(ordersHandler.OrderUpdates)).Methods("GET")
+
+	// Export orders for accounting - registered before /orders/{id} so "export" is not taken as an ID
+	protectedRouter.Handle("/orders/export",
+		// Removed authMiddleware.RequireOrdersPermission("read") - gateway handles all auth
+		http.HandlerFunc(ordersHandler.ExportOrders)).Methods("GET")
+
+	// Bulk status update for the kitchen display - registered before /orders/{id} so "bulk-status" is not taken as an ID
+	protectedRouter.Handle("/orders/bulk-status",
+		// Removed authMiddleware.RequireOrdersPermission("write") - gateway handles all auth
+		http.HandlerFunc(ordersHandler.BulkUpdateOrderStatus)).Methods("POST")
+
+	// Get order by its human-readable number - requires orders-read permission
+	protectedRouter.Handle("/orders/number/{number}",
+		// Removed authMiddleware.RequireOrdersPermission("read") - gateway handles all auth
+		http.HandlerFunc(ordersHandler.GetOrderByNumber)).Methods("GET")
+
 	// Get order - requires orders-read permission
 	protectedRouter.Handle("/orders/{id}",
 		// Removed authMiddleware.RequireOrdersPermission("read") - gateway handles all auth
 		http.HandlerFunc(ordersHandler.GetOrder)).Methods("GET")
 
+	// Get order receipt - requires orders-read permission
+	protectedRouter.Handle("/orders/{id}/receipt",
+		// Removed authMiddleware.RequireOrdersPermission("read") - gateway handles all auth
+		http.HandlerFunc(ordersHandler.GetOrderReceipt)).Methods("GET")
+
+	// Get order timeline - requires orders-read permission
+	protectedRouter.Handle("/orders/{id}/timeline",
+		// Removed authMiddleware.RequireOrdersPermission("read") - gateway handles all auth
+		http.HandlerFunc(ordersHandler.GetOrderTimeline)).Methods("GET")
+
 	// Update order - requires orders-write permission
 	protectedRouter.Handle("/orders/{id}",
 		// Removed authMiddleware.RequireOrdersPermission("write") - gateway handles all auth
@@ -181,6 +292,11 @@ func setupRouter(ordersHandler handler.OrdersHandler, logger *logrus.Logger) *mu
 		// Removed authMiddleware.RequireOrdersPermission("write") - gateway handles all auth
 		http.HandlerFunc(ordersHandler.CancelOrder)).Methods("POST")
 
+	// Reopen order - requires orders-write permission
+	protectedRouter.Handle("/orders/{id}/reopen",
+		// Removed authMiddleware.RequireOrdersPermission("write") - gateway handles all auth
+		http.HandlerFunc(ordersHandler.ReopenOrder)).Methods("POST")
+
 	// List orders - requires orders-read permission
 	protectedRouter.Handle("/orders",
 		// Removed authMiddleware.RequireOrdersPermission("read") - gateway handles all auth
@@ -193,22 +309,32 @@ func setupRouter(ordersHandler handler.OrdersHandler, logger *logrus.Logger) *mu
 	adminRouter.HandleFunc("/orders/summary", ordersHandler.GetOrderSummary).Methods("GET")
 	adminRouter.HandleFunc("/orders/stats/payment-methods", ordersHandler.GetPaymentMethodStats).Methods("GET")
 
+	// Effective configuration with secrets redacted - admin role set by the gateway
+	adminRouter.Handle("/config",
+		httpx.RequireAdminMiddleware(http.HandlerFunc(ordersHandler.GetConfig))).Methods("GET")
+
 	// Root endpoint
 	router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
-		fmt.Fprintf(w, `{
-			"service": "ice-cream-orders-service",
-			"version": "1.0.0",
-			"status": "healthy",
-			"timestamp": "%s",
-			"endpoints": {
-				"health": "/api/v1/orders/p/health",
-				"orders": "/api/v1/orders",
-				"statistics": "/api/v1/orders/summary"
-			}
-		}`, time.Now().Format(time.RFC3339))
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"service":   "ice-cream-orders-service",
+			"version":   version.Version,
+			"build":     version.Info(),
+			"status":    "healthy",
+			"timestamp": time.Now().Format(time.RFC3339),
+			"endpoints": map[string]string{
+				"health":     "/api/v1/orders/p/health",
+				"orders":     "/api/v1/orders",
+				"updates":    "/api/v1/orders/ws",
+				"export":     "/api/v1/orders/export",
+				"statistics": "/api/v1/orders/summary",
+			},
+		})
 	}).Methods("GET")
 
+	// Unmatched routes get JSON 404/405 responses instead of mux's plain text
+	httpx.SetRouteErrorHandlers(router)
+
 	return router
 }
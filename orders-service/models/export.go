@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Export formats accepted by the orders export endpoint
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatJSON = "json"
+)
+
+// OrderExport is one order as written to an accounting export
+type OrderExport struct {
+	OrderID       uuid.UUID       `json:"order_id"`
+	OrderDate     time.Time       `json:"order_date"`
+	OrderStatus   string          `json:"order_status"`
+	PaymentMethod string          `json:"payment_method"`
+	Subtotal      float64         `json:"subtotal"`
+	Tax           float64         `json:"tax"`
+	Service       float64         `json:"service"`
+	Discount      float64         `json:"discount"`
+	Total         float64         `json:"total"`
+	Items         []OrderedRecipe `json:"items,omitempty"`
+}
+
+// NewOrderExport builds an export record from the amounts stored with an order, so that
+// subtotal + tax + service - discount adds up to the total the customer was charged
+func NewOrderExport(order Order, items []OrderedRecipe) OrderExport {
+	return OrderExport{
+		OrderID:       order.ID,
+		OrderDate:     order.OrderDate,
+		OrderStatus:   order.OrderStatus,
+		PaymentMethod: order.PaymentMethod,
+		Subtotal:      order.TotalAmount,
+		Tax:           order.TaxAmount,
+		Service:       order.ServiceAmount,
+		Discount:      order.DiscountAmount,
+		Total:         order.FinalAmount,
+		Items:         items,
+	}
+}
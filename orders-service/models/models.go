@@ -1,25 +1,36 @@
 package models
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
 	"time"
 
+	"shared/validate"
+
 	"github.com/google/uuid"
 )
 
 // Order represents an ice cream order
 type Order struct {
-	ID             uuid.UUID  `json:"id" db:"id"`
-	CustomerID     *uuid.UUID `json:"customer_id" db:"customer_id"`
-	OrderDate      time.Time  `json:"order_date" db:"order_date"`
-	TotalAmount    float64    `json:"total_amount" db:"total_amount"`
-	TaxAmount      float64    `json:"tax_amount" db:"tax_amount"`
-	DiscountAmount float64    `json:"discount_amount" db:"discount_amount"`
-	FinalAmount    float64    `json:"final_amount" db:"final_amount"`
-	PaymentMethod  string     `json:"payment_method" db:"payment_method"`
-	OrderStatus    string     `json:"order_status" db:"order_status"`
-	Notes          *string    `json:"notes" db:"notes"`
-	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+	ID                 uuid.UUID  `json:"id" db:"id"`
+	OrderNumber        string     `json:"order_number" db:"order_number"`
+	CustomerID         *uuid.UUID `json:"customer_id" db:"customer_id"`
+	OrderDate          time.Time  `json:"order_date" db:"order_date"`
+	TotalAmount        float64    `json:"total_amount" db:"total_amount"`
+	TaxAmount          float64    `json:"tax_amount" db:"tax_amount"`
+	ServiceAmount      float64    `json:"service_amount" db:"service_amount"`
+	DiscountAmount     float64    `json:"discount_amount" db:"discount_amount"`
+	FinalAmount        float64    `json:"final_amount" db:"final_amount"`
+	PaymentMethod      string     `json:"payment_method" db:"payment_method"`
+	OrderStatus        string     `json:"order_status" db:"order_status"`
+	Notes              *string    `json:"notes" db:"notes"`
+	CancelledAt        *time.Time `json:"cancelled_at,omitempty" db:"cancelled_at"`
+	CancellationReason *string    `json:"cancellation_reason,omitempty" db:"cancellation_reason"`
+	CreatedAt          time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at" db:"updated_at"`
 }
 
 // OrderedRecipe represents a recipe item within an order
@@ -34,13 +45,47 @@ type OrderedRecipe struct {
 	CreatedAt           time.Time `json:"created_at" db:"created_at"`
 }
 
-// CreateOrderRequest represents the request to create a new order
+// Payment represents one tender applied to an order; split payments have several
+type Payment struct {
+	ID            uuid.UUID `json:"id" db:"id"`
+	OrderID       uuid.UUID `json:"order_id" db:"order_id"`
+	PaymentMethod string    `json:"payment_method" db:"payment_method"`
+	Amount        float64   `json:"amount" db:"amount"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+
+// OutboxMessage is a notification recorded alongside a business change and relayed after commit
+type OutboxMessage struct {
+	ID            uuid.UUID       `json:"id" db:"id"`
+	AggregateID   uuid.UUID       `json:"aggregate_id" db:"aggregate_id"`
+	EventType     string          `json:"event_type" db:"event_type"`
+	Payload       json.RawMessage `json:"payload" db:"payload"`
+	Attempts      int             `json:"attempts" db:"attempts"`
+	LastError     *string         `json:"last_error,omitempty" db:"last_error"`
+	CreatedAt     time.Time       `json:"created_at" db:"created_at"`
+	NextAttemptAt time.Time       `json:"next_attempt_at" db:"next_attempt_at"`
+	SentAt        *time.Time      `json:"sent_at,omitempty" db:"sent_at"`
+}
+
+// PaymentRequest represents a payment entry in an order create or update request
+type PaymentRequest struct {
+	PaymentMethod string  `json:"payment_method"`
+	Amount        float64 `json:"amount"`
+}
+
+// CreateOrderRequest represents the request to create a new order.
+// Payments splits the order across several methods; when omitted the whole
+// amount is paid with PaymentMethod.
 type CreateOrderRequest struct {
 	CustomerID     *uuid.UUID                   `json:"customer_id"`
 	PaymentMethod  string                       `json:"payment_method"`
 	Notes          *string                      `json:"notes"`
 	DiscountAmount float64                      `json:"discount_amount"`
 	Items          []CreateOrderedRecipeRequest `json:"items"`
+	Payments       []PaymentRequest             `json:"payments,omitempty"`
+
+	// DiscountPercentage discounts a share of the subtotal instead of DiscountAmount
+	DiscountPercentage *float64 `json:"discount_percentage,omitempty"`
 }
 
 // CreateOrderedRecipeRequest represents a recipe item in the order creation request
@@ -57,12 +102,56 @@ type UpdateOrderRequest struct {
 	OrderStatus    *string  `json:"order_status"`
 	Notes          *string  `json:"notes"`
 	DiscountAmount *float64 `json:"discount_amount"`
+
+	// DiscountPercentage discounts a share of the subtotal instead of DiscountAmount
+	DiscountPercentage *float64 `json:"discount_percentage,omitempty"`
+
+	// Payments replaces the order's payment entries when provided
+	Payments []PaymentRequest `json:"payments,omitempty"`
+}
+
+// CancelOrderRequest represents the optional body of a cancel request
+type CancelOrderRequest struct {
+	Reason string `json:"reason"`
+}
+
+// Validate defaults a missing reason to other and checks it is one of allowed
+func (req *CancelOrderRequest) Validate(allowed []string) error {
+	if req.Reason == "" {
+		req.Reason = CancellationReasonOther
+	}
+	for _, reason := range allowed {
+		if req.Reason == reason {
+			return nil
+		}
+	}
+	return &ValidationError{Field: "reason", Message: "reason must be one of " + strings.Join(allowed, ", ")}
 }
 
 // OrderWithItems represents an order with its ordered recipes
 type OrderWithItems struct {
-	Order Order           `json:"order"`
-	Items []OrderedRecipe `json:"items"`
+	Order    Order           `json:"order"`
+	Items    []OrderedRecipe `json:"items"`
+	Payments []Payment       `json:"payments"`
+}
+
+// OrderV2 is the v2 representation of an order: the order fields sit at the top level
+// next to its items and payments, and the amounts come as an exact totals breakdown
+type OrderV2 struct {
+	Order
+	Items    []OrderedRecipe `json:"items"`
+	Payments []Payment       `json:"payments"`
+	Totals   OrderTotals     `json:"totals"`
+}
+
+// NewOrderV2 flattens an order with its items into the v2 representation
+func NewOrderV2(order *OrderWithItems, totals OrderTotals) OrderV2 {
+	return OrderV2{
+		Order:    order.Order,
+		Items:    order.Items,
+		Payments: order.Payments,
+		Totals:   totals,
+	}
 }
 
 // OrderSummary represents a summary of order statistics
@@ -73,6 +162,9 @@ type OrderSummary struct {
 	CancelledOrders int     `json:"cancelled_orders"`
 	TotalRevenue    float64 `json:"total_revenue"`
 	AverageOrder    float64 `json:"average_order"`
+
+	// CancellationReasons counts cancelled orders by reason; orders cancelled without one count as other
+	CancellationReasons map[string]int `json:"cancellation_reasons"`
 }
 
 // PaymentMethodStats represents payment method statistics
@@ -98,6 +190,25 @@ type OrderFilter struct {
 	SortOrder     string     `json:"sort_order"`
 }
 
+// Pagination describes the window a paginated list response covers
+type Pagination struct {
+	Limit   int  `json:"limit"`
+	Offset  int  `json:"offset"`
+	Total   int  `json:"total"`
+	HasMore bool `json:"has_more"`
+}
+
+// NewPagination builds the metadata for a page of returned items starting at offset
+// out of total matching items
+func NewPagination(limit, offset, returned, total int) Pagination {
+	return Pagination{
+		Limit:   limit,
+		Offset:  offset,
+		Total:   total,
+		HasMore: offset+returned < total,
+	}
+}
+
 // Validation methods
 
 // ValidatePaymentMethod checks if payment method is valid
@@ -113,7 +224,7 @@ func (o *Order) ValidatePaymentMethod() bool {
 
 // ValidateOrderStatus checks if order status is valid
 func (o *Order) ValidateOrderStatus() bool {
-	validStatuses := []string{"pending", "completed", "cancelled"}
+	validStatuses := []string{OrderStatusPending, OrderStatusPreparing, OrderStatusCompleted, OrderStatusCancelled}
 	for _, status := range validStatuses {
 		if o.OrderStatus == status {
 			return true
@@ -122,65 +233,192 @@ func (o *Order) ValidateOrderStatus() bool {
 	return false
 }
 
-// ValidateCreateRequest validates the create order request
+// Validate checks the create order request and reports every violation found
 func (req *CreateOrderRequest) Validate() error {
-	if req.PaymentMethod == "" {
-		return &ValidationError{Field: "payment_method", Message: "payment method is required"}
+	return validate.Validate(req)
+}
+
+// Rules registers the create order request's field rules
+func (req *CreateOrderRequest) Rules(v *validate.Validator) {
+	if len(req.Payments) > 0 {
+		paymentRules(v, req.Payments)
+	} else if req.PaymentMethod == "" {
+		v.Check(false, "payment_method", "payment method is required")
+	} else {
+		v.Check(isValidPaymentMethod(req.PaymentMethod), "payment_method", "invalid payment method")
 	}
 
-	validMethods := []string{"cash", "card", "sinpe"}
-	valid := false
-	for _, method := range validMethods {
-		if req.PaymentMethod == method {
-			valid = true
-			break
-		}
+	v.Check(len(req.Items) > 0, "items", "at least one item is required")
+	for i, item := range req.Items {
+		v.CheckAt(item.Quantity > 0, "items", i, "quantity must be greater than 0")
+		v.CheckAt(item.UnitPrice >= 0, "items", i, "unit price cannot be negative")
 	}
-	if !valid {
-		return &ValidationError{Field: "payment_method", Message: "invalid payment method"}
+
+	v.Check(req.DiscountAmount >= 0, "discount_amount", "discount amount cannot be negative")
+	if err := validateDiscountPercentage(req.DiscountAmount != 0, req.DiscountPercentage); err != nil {
+		v.Add(validate.All(err)...)
 	}
+}
 
-	if len(req.Items) == 0 {
-		return &ValidationError{Field: "items", Message: "at least one item is required"}
+// validateDiscountPercentage checks a percentage discount is within 0-100 and not combined with an amount
+func validateDiscountPercentage(hasAmount bool, percentage *float64) error {
+	if percentage == nil {
+		return nil
+	}
+	if hasAmount {
+		return &ValidationError{Field: "discount_percentage", Message: "use either discount_amount or discount_percentage, not both"}
 	}
+	if *percentage < 0 || *percentage > 100 {
+		return &ValidationError{Field: "discount_percentage", Message: "discount percentage must be between 0 and 100"}
+	}
+	return nil
+}
 
-	for i, item := range req.Items {
-		if item.Quantity <= 0 {
-			return &ValidationError{Field: "items", Message: "quantity must be greater than 0", Index: &i}
-		}
-		if item.UnitPrice < 0 {
-			return &ValidationError{Field: "items", Message: "unit price cannot be negative", Index: &i}
+// Validate checks the discount fields of an update request
+func (req *UpdateOrderRequest) Validate() error {
+	if req.DiscountAmount != nil && *req.DiscountAmount < 0 {
+		return &ValidationError{Field: "discount_amount", Message: "discount amount cannot be negative"}
+	}
+	return validateDiscountPercentage(req.DiscountAmount != nil, req.DiscountPercentage)
+}
+
+// ErrReopenWindowExpired is returned when a cancelled order is reopened after its grace period
+var ErrReopenWindowExpired = errors.New("order can no longer be reopened")
+
+// ErrTooManyItems is returned when an order has more line items than the configured cap
+var ErrTooManyItems = errors.New("order has too many items")
+
+// ValidateItemCount rejects orders with more than maxItems line items; a maxItems of 0 disables the cap
+func ValidateItemCount(count, maxItems int) error {
+	if maxItems > 0 && count > maxItems {
+		return fmt.Errorf("%w: %d items, at most %d allowed", ErrTooManyItems, count, maxItems)
+	}
+	return nil
+}
+
+// ErrDiscountExceedsMaximum is returned when a discount is larger than the policy allows
+var ErrDiscountExceedsMaximum = errors.New("discount exceeds the maximum allowed")
+
+// ResolveDiscount converts a percentage discount into an amount of subtotal, or keeps
+// amount when no percentage is given, and rejects discounts above maxPercentage of the subtotal
+func ResolveDiscount(subtotal, amount float64, percentage *float64, maxPercentage float64) (float64, error) {
+	if percentage != nil {
+		amount = math.Round(subtotal*(*percentage)) / 100
+	}
+
+	maxAmount := subtotal * (maxPercentage / 100)
+	if math.Round(amount*100) > math.Round(maxAmount*100) {
+		return 0, fmt.Errorf("%w: %.2f is more than %.2f%% of the %.2f subtotal", ErrDiscountExceedsMaximum, amount, maxPercentage, subtotal)
+	}
+	return amount, nil
+}
+
+// IsDiscountError reports whether err rejects an order's discount, as opposed to other request
+// problems; an aggregated validation error counts only when every violation is about the discount
+func IsDiscountError(err error) bool {
+	if errors.Is(err, ErrDiscountExceedsMaximum) {
+		return true
+	}
+	violations := validate.All(err)
+	if len(violations) == 0 {
+		return false
+	}
+	for _, violation := range violations {
+		if violation.Field != "discount_amount" && violation.Field != "discount_percentage" {
+			return false
 		}
 	}
+	return true
+}
 
-	if req.DiscountAmount < 0 {
-		return &ValidationError{Field: "discount_amount", Message: "discount amount cannot be negative"}
+// ValidatePayments checks that every payment entry uses a valid method and a positive amount
+func ValidatePayments(payments []PaymentRequest) error {
+	var v validate.Validator
+	paymentRules(&v, payments)
+	return v.Err()
+}
+
+// paymentRules registers the method and amount rules of each payment entry
+func paymentRules(v *validate.Validator, payments []PaymentRequest) {
+	for i, payment := range payments {
+		v.CheckAt(isValidPaymentMethod(payment.PaymentMethod), "payments", i, "invalid payment method")
+		v.CheckAt(payment.Amount > 0, "payments", i, "amount must be greater than 0")
 	}
+}
+
+// ErrPaymentsTotalMismatch is returned when payment entries don't add up to the order's final amount
+var ErrPaymentsTotalMismatch = errors.New("payments do not add up to the order total")
+
+// ErrSplitPaymentMethodChange is returned when only the payment method of an order paid with several entries is changed
+var ErrSplitPaymentMethodChange = errors.New("order has split payments; send payments to change them")
 
+// ValidatePaymentsTotal checks that payments sum to finalAmount, compared at cent precision
+func ValidatePaymentsTotal(payments []PaymentRequest, finalAmount float64) error {
+	sum := 0.0
+	for _, payment := range payments {
+		sum += payment.Amount
+	}
+
+	if math.Round(sum*100) != math.Round(finalAmount*100) {
+		return fmt.Errorf("%w: payments sum to %.2f, order total is %.2f", ErrPaymentsTotalMismatch, sum, finalAmount)
+	}
 	return nil
 }
 
-// ValidationError represents a validation error
-type ValidationError struct {
-	Field   string `json:"field"`
-	Message string `json:"message"`
-	Index   *int   `json:"index,omitempty"`
+// PaymentRequestsFrom returns the method and amount of each recorded payment, so stored payments
+// can be checked with the same rules as requested ones
+func PaymentRequestsFrom(payments []Payment) []PaymentRequest {
+	requests := make([]PaymentRequest, 0, len(payments))
+	for _, payment := range payments {
+		requests = append(requests, PaymentRequest{PaymentMethod: payment.PaymentMethod, Amount: payment.Amount})
+	}
+	return requests
 }
 
-func (e *ValidationError) Error() string {
-	if e.Index != nil {
-		return "validation error in " + e.Field + "[" + string(rune(*e.Index)) + "]: " + e.Message
+// PaymentsMethod returns the order-level payment method for a set of payments:
+// the shared method when all entries use the same one, PaymentMethodSplit otherwise
+func PaymentsMethod(payments []PaymentRequest) string {
+	if len(payments) == 0 {
+		return ""
+	}
+
+	method := payments[0].PaymentMethod
+	for _, payment := range payments[1:] {
+		if payment.PaymentMethod != method {
+			return PaymentMethodSplit
+		}
 	}
-	return "validation error in " + e.Field + ": " + e.Message
+	return method
 }
 
+// isValidPaymentMethod reports whether method is a method a payment can be made with
+func isValidPaymentMethod(method string) bool {
+	validMethods := []string{PaymentMethodCash, PaymentMethodCard, PaymentMethodSinpe}
+	for _, valid := range validMethods {
+		if method == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidationError represents a validation error
+type ValidationError = validate.ValidationError
+
 // Constants for order statuses and payment methods
 const (
 	OrderStatusPending   = "pending"
+	OrderStatusPreparing = "preparing"
 	OrderStatusCompleted = "completed"
 	OrderStatusCancelled = "cancelled"
 
 	PaymentMethodCash  = "cash"
 	PaymentMethodCard  = "card"
 	PaymentMethodSinpe = "sinpe"
+
+	// PaymentMethodSplit marks an order paid with more than one method; see its payments for the breakdown
+	PaymentMethodSplit = "split"
+
+	// CancellationReasonOther is recorded when an order is cancelled without a reason
+	CancellationReasonOther = "other"
 )
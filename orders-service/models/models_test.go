@@ -5,6 +5,9 @@ import (
 	"testing"
 	"time"
 
+	"shared/money"
+	"shared/validate"
+
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -114,6 +117,7 @@ func TestValidateOrderStatus(t *testing.T) {
 		{"valid pending", "pending", true},
 		{"valid completed", "completed", true},
 		{"valid cancelled", "cancelled", true},
+		{"valid preparing", "preparing", true},
 		{"invalid status", "processing", false},
 		{"empty status", "", false},
 		{"uppercase status", "PENDING", false},
@@ -129,6 +133,90 @@ func TestValidateOrderStatus(t *testing.T) {
 	}
 }
 
+// TestCanTransitionOrderStatus tests the order status state machine
+func TestCanTransitionOrderStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     string
+		to       string
+		expected bool
+	}{
+		{"pending to preparing", "pending", "preparing", true},
+		{"pending to completed", "pending", "completed", true},
+		{"preparing to completed", "preparing", "completed", true},
+		{"preparing to cancelled", "preparing", "cancelled", true},
+		{"cancelled to pending", "cancelled", "pending", true},
+		{"preparing back to pending", "preparing", "pending", false},
+		{"completed is final", "completed", "preparing", false},
+		{"same status", "pending", "pending", false},
+		{"unknown status", "processing", "completed", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, CanTransitionOrderStatus(tt.from, tt.to))
+		})
+	}
+}
+
+// TestCanUpdateOrderStatus tests which statuses an order update may set
+func TestCanUpdateOrderStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     string
+		to       string
+		expected bool
+	}{
+		{"pending to preparing", "pending", "preparing", true},
+		{"same status", "completed", "completed", true},
+		{"cancelling needs the cancel endpoint", "pending", "cancelled", false},
+		{"reopening needs the reopen endpoint", "cancelled", "pending", false},
+		{"completed is final", "completed", "pending", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, CanUpdateOrderStatus(tt.from, tt.to))
+		})
+	}
+}
+
+// TestBulkStatusRequestValidate tests the Validate method of BulkStatusRequest
+func TestBulkStatusRequestValidate(t *testing.T) {
+	id := uuid.New()
+	tooMany := make([]uuid.UUID, MaxBulkStatusOrders+1)
+	for i := range tooMany {
+		tooMany[i] = uuid.New()
+	}
+
+	tests := []struct {
+		name          string
+		request       BulkStatusRequest
+		expectedField string
+	}{
+		{"valid preparing", BulkStatusRequest{IDs: []uuid.UUID{id}, Status: "preparing"}, ""},
+		{"valid completed", BulkStatusRequest{IDs: []uuid.UUID{id, uuid.New()}, Status: "completed"}, ""},
+		{"no ids", BulkStatusRequest{Status: "completed"}, "ids"},
+		{"too many ids", BulkStatusRequest{IDs: tooMany, Status: "completed"}, "ids"},
+		{"duplicate ids", BulkStatusRequest{IDs: []uuid.UUID{id, id}, Status: "completed"}, "ids"},
+		{"cancelled not allowed", BulkStatusRequest{IDs: []uuid.UUID{id}, Status: "cancelled"}, "status"},
+		{"pending not allowed", BulkStatusRequest{IDs: []uuid.UUID{id}, Status: "pending"}, "status"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.request.Validate()
+			if tt.expectedField == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var validationErr *ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, tt.expectedField, validationErr.Field)
+		})
+	}
+}
+
 // TestCreateOrderRequestValidate tests the Validate method of CreateOrderRequest
 func TestCreateOrderRequestValidate(t *testing.T) {
 	validItem := CreateOrderedRecipeRequest{
@@ -249,8 +337,8 @@ func TestCreateOrderRequestValidate(t *testing.T) {
 			if tt.expectError {
 				assert.Error(t, err)
 				if tt.errorField != "" {
-					validationErr, ok := err.(*ValidationError)
-					require.True(t, ok, "Expected ValidationError")
+					var validationErr *ValidationError
+					require.ErrorAs(t, err, &validationErr)
 					assert.Equal(t, tt.errorField, validationErr.Field)
 				}
 			} else {
@@ -280,14 +368,39 @@ func TestValidationError(t *testing.T) {
 			Index:   &index,
 		}
 
-		// Note: The current implementation has a bug using string(rune(*e.Index))
-		// This converts the number to its ASCII character
-		result := err.Error()
-		assert.Contains(t, result, "validation error in items")
-		assert.Contains(t, result, "quantity must be greater than 0")
+		assert.Equal(t, "validation error in items[2]: quantity must be greater than 0", err.Error())
 	})
 }
 
+// TestCreateOrderRequestValidateReportsAllViolations tests that every invalid field is reported at once
+func TestCreateOrderRequestValidateReportsAllViolations(t *testing.T) {
+	percentage := 150.0
+	request := &CreateOrderRequest{
+		Payments: []PaymentRequest{
+			{PaymentMethod: "bitcoin", Amount: 10},
+			{PaymentMethod: "cash", Amount: 0},
+		},
+		Items: []CreateOrderedRecipeRequest{
+			{RecipeID: uuid.New(), Quantity: 1, UnitPrice: 10},
+			{RecipeID: uuid.New(), Quantity: 0, UnitPrice: -5},
+		},
+		DiscountPercentage: &percentage,
+	}
+
+	err := request.Validate()
+	require.Error(t, err)
+
+	zero, one := 0, 1
+	assert.Equal(t, []ValidationError{
+		{Field: "payments", Message: "invalid payment method", Index: &zero},
+		{Field: "payments", Message: "amount must be greater than 0", Index: &one},
+		{Field: "items", Message: "quantity must be greater than 0", Index: &one},
+		{Field: "items", Message: "unit price cannot be negative", Index: &one},
+		{Field: "discount_percentage", Message: "discount percentage must be between 0 and 100"},
+	}, validate.All(err))
+	assert.False(t, IsDiscountError(err), "mixed violations are not a discount rejection")
+}
+
 // TestOrderWithItems tests the OrderWithItems struct
 func TestOrderWithItems(t *testing.T) {
 	now := time.Now()
@@ -547,8 +660,8 @@ func TestValidationWithMultipleItems(t *testing.T) {
 	err := request.Validate()
 	assert.Error(t, err)
 
-	validationErr, ok := err.(*ValidationError)
-	require.True(t, ok)
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
 	assert.Equal(t, "items", validationErr.Field)
 	assert.NotNil(t, validationErr.Index)
 	assert.Equal(t, 1, *validationErr.Index) // Second item (index 1) is invalid
@@ -598,3 +711,240 @@ func BenchmarkOrderSerialization(b *testing.B) {
 		}
 	}
 }
+
+// TestCalculateOrderTotals tests the receipt totals breakdown
+func TestCalculateOrderTotals(t *testing.T) {
+	items := []OrderedRecipe{
+		{Quantity: 2, UnitPrice: 1500.0},
+		{Quantity: 1, UnitPrice: 2000.0},
+	}
+
+	testCases := map[string]struct {
+		items       []OrderedRecipe
+		taxRate     float64
+		serviceRate float64
+		discount    float64
+		expected    OrderTotals
+	}{
+		"tax and service": {
+			items:       items,
+			taxRate:     13.0,
+			serviceRate: 10.0,
+			expected:    OrderTotals{Subtotal: money.FromFloat(5000.0), Tax: money.FromFloat(650.0), Service: money.FromFloat(500.0), Total: money.FromFloat(6150.0)},
+		},
+		"discount subtracted after tax and service": {
+			items:       items,
+			taxRate:     13.0,
+			serviceRate: 10.0,
+			discount:    150.0,
+			expected:    OrderTotals{Subtotal: money.FromFloat(5000.0), Tax: money.FromFloat(650.0), Service: money.FromFloat(500.0), Discount: money.FromFloat(150.0), Total: money.FromFloat(6000.0)},
+		},
+		"no items": {
+			taxRate:     13.0,
+			serviceRate: 10.0,
+			expected:    OrderTotals{},
+		},
+		"tax rounded to the cent": {
+			items:       []OrderedRecipe{{Quantity: 1, UnitPrice: 10.05}},
+			taxRate:     13.0,
+			serviceRate: 10.0,
+			expected:    OrderTotals{Subtotal: money.FromFloat(10.05), Tax: money.FromFloat(1.31), Service: money.FromFloat(1.01), Total: money.FromFloat(12.37)},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			totals := CalculateOrderTotals(tc.items, tc.taxRate, tc.serviceRate, tc.discount)
+
+			assert.Equal(t, tc.expected, totals)
+		})
+	}
+}
+
+// TestOrderTotalsFromOrder tests that the totals breakdown uses the amounts stored with the order
+func TestOrderTotalsFromOrder(t *testing.T) {
+	order := Order{TotalAmount: 5000.0, TaxAmount: 650.0, ServiceAmount: 500.0, DiscountAmount: 150.0, FinalAmount: 6000.0}
+
+	totals := OrderTotalsFromOrder(order)
+
+	assert.Equal(t, OrderTotals{
+		Subtotal: money.FromFloat(5000.0),
+		Tax:      money.FromFloat(650.0),
+		Service:  money.FromFloat(500.0),
+		Discount: money.FromFloat(150.0),
+		Total:    money.FromFloat(6000.0),
+	}, totals)
+	assert.Equal(t, totals.Total, totals.Subtotal+totals.Tax+totals.Service-totals.Discount)
+}
+
+// TestOrderSubtotal tests that many small line items add up exactly, unlike a float64 sum
+func TestOrderSubtotal(t *testing.T) {
+	var items []OrderedRecipe
+	floatSum := 0.0
+	for i := 0; i < 10; i++ {
+		items = append(items, OrderedRecipe{Quantity: 1, UnitPrice: 0.10})
+		floatSum += 0.10
+	}
+
+	assert.NotEqual(t, 1.0, floatSum)
+	assert.Equal(t, money.FromFloat(1.0), OrderSubtotal(items))
+
+	data, err := json.Marshal(CalculateOrderTotals(items, 13.0, 0, 0))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"subtotal":1.00`)
+	assert.Contains(t, string(data), `"tax":0.13`)
+}
+
+// TestValidatePaymentsTotal tests that split payments must add up to the final amount
+func TestValidatePaymentsTotal(t *testing.T) {
+	testCases := map[string]struct {
+		payments    []PaymentRequest
+		finalAmount float64
+		expectErr   bool
+	}{
+		"exact split":        {payments: []PaymentRequest{{"cash", 30.0}, {"card", 26.5}}, finalAmount: 56.5},
+		"float rounding":     {payments: []PaymentRequest{{"cash", 0.1}, {"card", 0.2}}, finalAmount: 0.3},
+		"underpayment":       {payments: []PaymentRequest{{"cash", 30.0}, {"card", 20.0}}, finalAmount: 56.5, expectErr: true},
+		"overpayment":        {payments: []PaymentRequest{{"cash", 30.0}, {"card", 30.0}}, finalAmount: 56.5, expectErr: true},
+		"off by one centimo": {payments: []PaymentRequest{{"cash", 56.49}}, finalAmount: 56.5, expectErr: true},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			err := ValidatePaymentsTotal(tc.payments, tc.finalAmount)
+			if tc.expectErr {
+				assert.ErrorIs(t, err, ErrPaymentsTotalMismatch)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// TestPaymentsMethod tests the order-level method derived from payment entries
+func TestPaymentsMethod(t *testing.T) {
+	assert.Equal(t, "", PaymentsMethod(nil))
+	assert.Equal(t, "card", PaymentsMethod([]PaymentRequest{{"card", 10.0}}))
+	assert.Equal(t, "cash", PaymentsMethod([]PaymentRequest{{"cash", 10.0}, {"cash", 5.0}}))
+	assert.Equal(t, PaymentMethodSplit, PaymentsMethod([]PaymentRequest{{"cash", 10.0}, {"sinpe", 5.0}}))
+}
+
+// TestCreateOrderRequestValidateWithPayments tests validation when payments replace payment_method
+func TestCreateOrderRequestValidateWithPayments(t *testing.T) {
+	items := []CreateOrderedRecipeRequest{{RecipeID: uuid.New(), Quantity: 1, UnitPrice: 10.0}}
+
+	testCases := map[string]struct {
+		payments  []PaymentRequest
+		expectErr bool
+	}{
+		"valid payments":         {payments: []PaymentRequest{{"cash", 5.0}, {"card", 6.3}}},
+		"invalid payment method": {payments: []PaymentRequest{{"bitcoin", 11.3}}, expectErr: true},
+		"zero amount":            {payments: []PaymentRequest{{"cash", 11.3}, {"card", 0}}, expectErr: true},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			req := &CreateOrderRequest{Items: items, Payments: tc.payments}
+			err := req.Validate()
+			if tc.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// TestResolveDiscount tests percentage conversion and the maximum-discount policy
+func TestResolveDiscount(t *testing.T) {
+	percentage := func(p float64) *float64 { return &p }
+
+	testCases := map[string]struct {
+		subtotal       float64
+		amount         float64
+		percentage     *float64
+		maxPercentage  float64
+		expectedAmount float64
+		expectErr      bool
+	}{
+		"no discount":                {subtotal: 100.0, maxPercentage: 50.0},
+		"amount within the maximum":  {subtotal: 100.0, amount: 20.0, maxPercentage: 50.0, expectedAmount: 20.0},
+		"amount at the maximum":      {subtotal: 100.0, amount: 50.0, maxPercentage: 50.0, expectedAmount: 50.0},
+		"amount above the maximum":   {subtotal: 100.0, amount: 50.01, maxPercentage: 50.0, expectErr: true},
+		"percentage converted":       {subtotal: 3000.0, percentage: percentage(15.0), maxPercentage: 50.0, expectedAmount: 450.0},
+		"percentage rounded to cent": {subtotal: 33.33, percentage: percentage(10.0), maxPercentage: 50.0, expectedAmount: 3.33},
+		"percentage above maximum":   {subtotal: 100.0, percentage: percentage(60.0), maxPercentage: 50.0, expectErr: true},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			amount, err := ResolveDiscount(tc.subtotal, tc.amount, tc.percentage, tc.maxPercentage)
+			if tc.expectErr {
+				assert.ErrorIs(t, err, ErrDiscountExceedsMaximum)
+				assert.True(t, IsDiscountError(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.InDelta(t, tc.expectedAmount, amount, 0.001)
+		})
+	}
+}
+
+// TestIsDiscountError tests which errors are reported as discount rejections
+func TestIsDiscountError(t *testing.T) {
+	assert.True(t, IsDiscountError(&ValidationError{Field: "discount_amount", Message: "discount amount cannot be negative"}))
+	assert.True(t, IsDiscountError(&ValidationError{Field: "discount_percentage", Message: "out of range"}))
+	assert.False(t, IsDiscountError(&ValidationError{Field: "payment_method", Message: "invalid payment method"}))
+	assert.False(t, IsDiscountError(ErrPaymentsTotalMismatch))
+	assert.True(t, IsDiscountError(validate.Errors{{Field: "discount_amount", Message: "discount amount cannot be negative"}}))
+}
+
+// TestValidateItemCount tests the per-order item cap and that zero disables it
+func TestValidateItemCount(t *testing.T) {
+	testCases := map[string]struct {
+		count     int
+		maxItems  int
+		expectErr bool
+	}{
+		"under the cap": {count: 5, maxItems: 100},
+		"at the cap":    {count: 100, maxItems: 100},
+		"over the cap":  {count: 101, maxItems: 100, expectErr: true},
+		"cap disabled":  {count: 1000, maxItems: 0},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateItemCount(tc.count, tc.maxItems)
+			if tc.expectErr {
+				assert.ErrorIs(t, err, ErrTooManyItems)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// TestOrderNumberFormat tests the counter scope and rendering of human-readable order numbers
+func TestOrderNumberFormat(t *testing.T) {
+	placedAt := time.Date(2024, time.March, 1, 23, 59, 0, 0, time.UTC)
+
+	testCases := map[string]struct {
+		format         OrderNumberFormat
+		n              int
+		expectedScope  string
+		expectedNumber string
+	}{
+		"daily first order":     {format: DefaultOrderNumberFormat, n: 1, expectedScope: "20240301", expectedNumber: "20240301-0001"},
+		"daily past the digits": {format: DefaultOrderNumberFormat, n: 12345, expectedScope: "20240301", expectedNumber: "20240301-12345"},
+		"never reset":           {format: OrderNumberFormat{Reset: OrderNumberResetNever, Digits: 6}, n: 42, expectedScope: "all", expectedNumber: "000042"},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			scope := tc.format.Scope(placedAt)
+
+			assert.Equal(t, tc.expectedScope, scope)
+			assert.Equal(t, tc.expectedNumber, tc.format.Format(scope, tc.n))
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Order event types recorded on an order's timeline
+const (
+	OrderEventCreated         = "created"
+	OrderEventStatusChanged   = "status_changed"
+	OrderEventCancelled       = "cancelled"
+	OrderEventReopened        = "reopened"
+	OrderEventPaymentRecorded = "payment_recorded"
+	OrderEventPaymentsUpdated = "payments_updated"
+	OrderEventUpdated         = "updated"
+)
+
+// OrderEvent is one entry of an order's lifecycle history
+type OrderEvent struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	OrderID     uuid.UUID `json:"order_id" db:"order_id"`
+	EventType   string    `json:"event_type" db:"event_type"`
+	OrderStatus string    `json:"order_status" db:"order_status"`
+	Actor       *string   `json:"actor" db:"actor"`
+	Notes       *string   `json:"notes" db:"notes"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+// OrderTimeline is an order's lifecycle events, oldest first
+type OrderTimeline struct {
+	OrderID     uuid.UUID    `json:"order_id"`
+	OrderNumber string       `json:"order_number"`
+	OrderStatus string       `json:"order_status"`
+	Events      []OrderEvent `json:"events"`
+}
+
+type actorContextKey struct{}
+
+// WithActor returns a context naming the user behind the request, for the events it records
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorContextKey{}, actor)
+}
+
+// ActorFromContext returns the user set by WithActor, or nil when the request is anonymous
+func ActorFromContext(ctx context.Context) *string {
+	actor, ok := ctx.Value(actorContextKey{}).(string)
+	if !ok || actor == "" {
+		return nil
+	}
+	return &actor
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Order number reset policies
+const (
+	OrderNumberResetDaily = "daily"
+	OrderNumberResetNever = "never"
+)
+
+// orderNumberScopeAll is the counter scope shared by every order when numbers never reset
+const orderNumberScopeAll = "all"
+
+// OrderNumberFormat describes the human-readable number given to each order next to its UUID.
+// With a daily reset numbers look like 20240301-0001; otherwise they are a zero-padded running count
+type OrderNumberFormat struct {
+	Reset  string
+	Digits int
+}
+
+// DefaultOrderNumberFormat numbers orders per day with four digits
+var DefaultOrderNumberFormat = OrderNumberFormat{Reset: OrderNumberResetDaily, Digits: 4}
+
+// Scope returns the counter an order placed at t draws its number from
+func (f OrderNumberFormat) Scope(t time.Time) string {
+	if f.Reset == OrderNumberResetNever {
+		return orderNumberScopeAll
+	}
+	return t.Format("20060102")
+}
+
+// Format renders the n-th number of scope
+func (f OrderNumberFormat) Format(scope string, n int) string {
+	number := fmt.Sprintf("%0*d", f.Digits, n)
+	if scope == orderNumberScopeAll {
+		return number
+	}
+	return scope + "-" + number
+}
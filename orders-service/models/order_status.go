@@ -0,0 +1,115 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// MaxBulkStatusOrders caps how many orders one bulk status update may touch
+const MaxBulkStatusOrders = 100
+
+// orderStatusTransitions lists the statuses each status may move to. Completed orders are final;
+// cancelled orders only come back to pending through the reopen endpoint
+var orderStatusTransitions = map[string][]string{
+	OrderStatusPending:   {OrderStatusPreparing, OrderStatusCompleted, OrderStatusCancelled},
+	OrderStatusPreparing: {OrderStatusCompleted, OrderStatusCancelled},
+	OrderStatusCancelled: {OrderStatusPending},
+}
+
+// bulkStatuses are the statuses the kitchen display may set in bulk; cancelling needs a reason
+// and goes through the cancel endpoint
+var bulkStatuses = []string{OrderStatusPreparing, OrderStatusCompleted}
+
+// CanTransitionOrderStatus reports whether an order may move from one status to another
+func CanTransitionOrderStatus(from, to string) bool {
+	for _, allowed := range orderStatusTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrStatusTransitionNotAllowed is returned when an order update asks for a status the order cannot move to
+var ErrStatusTransitionNotAllowed = errors.New("order status transition not allowed")
+
+// CanUpdateOrderStatus reports whether an order update may set status to on an order in status from.
+// Keeping the current status is allowed; cancelling and reopening need their own endpoints, so an
+// update can neither cancel an order nor move a cancelled order back to pending
+func CanUpdateOrderStatus(from, to string) bool {
+	if from == to {
+		return true
+	}
+	if from == OrderStatusCancelled || to == OrderStatusCancelled {
+		return false
+	}
+	return CanTransitionOrderStatus(from, to)
+}
+
+// StatusTransitionError explains why an order cannot move to the requested status
+func StatusTransitionError(from, to string) string {
+	return fmt.Sprintf("cannot move a %s order to %s", from, to)
+}
+
+// BulkStatusRequest moves several orders to the same status at once
+type BulkStatusRequest struct {
+	IDs    []uuid.UUID `json:"ids"`
+	Status string      `json:"status"`
+}
+
+// Validate checks the order IDs and that the status can be set in bulk
+func (req *BulkStatusRequest) Validate() error {
+	if len(req.IDs) == 0 {
+		return &ValidationError{Field: "ids", Message: "at least one order ID is required"}
+	}
+	if len(req.IDs) > MaxBulkStatusOrders {
+		return &ValidationError{Field: "ids", Message: fmt.Sprintf("at most %d orders can be updated at once", MaxBulkStatusOrders)}
+	}
+
+	seen := make(map[uuid.UUID]bool, len(req.IDs))
+	for i, id := range req.IDs {
+		if seen[id] {
+			index := i
+			return &ValidationError{Field: "ids", Message: "duplicate order ID " + id.String(), Index: &index}
+		}
+		seen[id] = true
+	}
+
+	for _, status := range bulkStatuses {
+		if req.Status == status {
+			return nil
+		}
+	}
+	return &ValidationError{Field: "status", Message: "status must be preparing or completed"}
+}
+
+// BulkStatusResult is the outcome of a bulk status update for one order
+type BulkStatusResult struct {
+	OrderID        uuid.UUID `json:"order_id"`
+	Updated        bool      `json:"updated"`
+	PreviousStatus string    `json:"previous_status,omitempty"`
+	Status         string    `json:"status,omitempty"`
+	Error          string    `json:"error,omitempty"`
+}
+
+// BulkStatusResponse reports every order of a bulk status update, in request order
+type BulkStatusResponse struct {
+	Results []BulkStatusResult `json:"results"`
+	Updated int                `json:"updated"`
+	Failed  int                `json:"failed"`
+}
+
+// NewBulkStatusResponse counts the updated and failed orders of results
+func NewBulkStatusResponse(results []BulkStatusResult) BulkStatusResponse {
+	response := BulkStatusResponse{Results: results}
+	for _, result := range results {
+		if result.Updated {
+			response.Updated++
+		} else {
+			response.Failed++
+		}
+	}
+	return response
+}
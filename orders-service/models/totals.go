@@ -0,0 +1,56 @@
+package models
+
+import "shared/money"
+
+// OrderTotals breaks an order's amount down into the figures printed on a receipt.
+// Amounts are kept in cents so they add up exactly and marshal with two decimals.
+type OrderTotals struct {
+	Subtotal money.Money `json:"subtotal"`
+	Tax      money.Money `json:"tax"`
+	Service  money.Money `json:"service"`
+	Discount money.Money `json:"discount"`
+	Total    money.Money `json:"total"`
+}
+
+// CalculateOrderTotals computes the subtotal of items and applies the tax and service
+// percentages to it; the discount is subtracted after tax and service are added
+func CalculateOrderTotals(items []OrderedRecipe, taxRate, serviceRate, discount float64) OrderTotals {
+	subtotal := OrderSubtotal(items)
+	tax := subtotal.Percent(taxRate)
+	service := subtotal.Percent(serviceRate)
+	discountAmount := money.FromFloat(discount)
+
+	return OrderTotals{
+		Subtotal: subtotal,
+		Tax:      tax,
+		Service:  service,
+		Discount: discountAmount,
+		Total:    subtotal + tax + service - discountAmount,
+	}
+}
+
+// OrderTotalsFromOrder returns the amounts stored when the order was charged, so receipts
+// and exports match what the customer paid even after the configured rates change
+func OrderTotalsFromOrder(order Order) OrderTotals {
+	return OrderTotals{
+		Subtotal: money.FromFloat(order.TotalAmount),
+		Tax:      money.FromFloat(order.TaxAmount),
+		Service:  money.FromFloat(order.ServiceAmount),
+		Discount: money.FromFloat(order.DiscountAmount),
+		Total:    money.FromFloat(order.FinalAmount),
+	}
+}
+
+// OrderSubtotal sums quantity times unit price over items in cents
+func OrderSubtotal(items []OrderedRecipe) money.Money {
+	var subtotal money.Money
+	for _, item := range items {
+		subtotal += LineTotal(item.Quantity, item.UnitPrice)
+	}
+	return subtotal
+}
+
+// LineTotal returns quantity times unit price in cents
+func LineTotal(quantity int, unitPrice float64) money.Money {
+	return money.FromFloat(unitPrice).Mul(quantity)
+}
@@ -0,0 +1,169 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"orders-service/events"
+	"orders-service/models"
+	"shared/eventbus"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Store reads pending outbox messages and records delivery outcomes
+type Store interface {
+	ListPendingOutboxMessages(now time.Time, maxAttempts, limit int) ([]models.OutboxMessage, error)
+	MarkOutboxMessageSent(id uuid.UUID, sentAt time.Time) error
+	MarkOutboxMessageFailed(id uuid.UUID, deliveryErr string, nextAttemptAt time.Time) error
+}
+
+// Deliverer sends a single outbox message to its destination
+type Deliverer interface {
+	Deliver(message models.OutboxMessage) error
+}
+
+// HTTPDeliverer posts each message as JSON to a target service
+type HTTPDeliverer struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPDeliverer creates a deliverer that posts messages to url
+func NewHTTPDeliverer(url string) *HTTPDeliverer {
+	return &HTTPDeliverer{
+		url:    url,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Deliver posts the message; any non-2xx response counts as a failed delivery
+func (d *HTTPDeliverer) Deliver(message models.OutboxMessage) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to encode outbox message: %w", err)
+	}
+
+	resp, err := d.client.Post(d.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to deliver outbox message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("outbox target returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// BusDeliverer republishes order messages on the in-process event bus
+type BusDeliverer struct {
+	bus *eventbus.Bus
+}
+
+// NewBusDeliverer creates a deliverer that publishes messages on bus
+func NewBusDeliverer(bus *eventbus.Bus) *BusDeliverer {
+	return &BusDeliverer{bus: bus}
+}
+
+// Deliver decodes the order status event stored in the message and publishes it
+func (d *BusDeliverer) Deliver(message models.OutboxMessage) error {
+	var event events.OrderStatusEvent
+	if err := json.Unmarshal(message.Payload, &event); err != nil {
+		return fmt.Errorf("failed to decode outbox payload: %w", err)
+	}
+	d.bus.Publish(event)
+	return nil
+}
+
+// Relay periodically delivers unsent outbox messages, backing off after failures
+type Relay struct {
+	store       Store
+	deliverer   Deliverer
+	logger      *logrus.Logger
+	interval    time.Duration
+	batchSize   int
+	maxAttempts int
+	retryDelay  time.Duration
+	now         func() time.Time
+}
+
+// NewRelay creates a relay that polls the store every interval
+func NewRelay(store Store, deliverer Deliverer, interval time.Duration, maxAttempts int, logger *logrus.Logger) *Relay {
+	return &Relay{
+		store:       store,
+		deliverer:   deliverer,
+		logger:      logger,
+		interval:    interval,
+		batchSize:   100,
+		maxAttempts: maxAttempts,
+		retryDelay:  interval,
+		now:         time.Now,
+	}
+}
+
+// Start runs the relay until ctx is cancelled
+func (r *Relay) Start(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.ProcessPending(); err != nil {
+			r.logger.WithError(err).Error("Failed to relay outbox messages")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessPending attempts delivery of every due message once
+func (r *Relay) ProcessPending() error {
+	messages, err := r.store.ListPendingOutboxMessages(r.now(), r.maxAttempts, r.batchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, message := range messages {
+		if err := r.deliverer.Deliver(message); err != nil {
+			nextAttemptAt := r.now().Add(r.backoff(message.Attempts))
+			r.logger.WithError(err).WithFields(logrus.Fields{
+				"outbox_id":       message.ID,
+				"event_type":      message.EventType,
+				"attempts":        message.Attempts + 1,
+				"next_attempt_at": nextAttemptAt,
+			}).Warn("Outbox delivery failed")
+
+			if err := r.store.MarkOutboxMessageFailed(message.ID, err.Error(), nextAttemptAt); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := r.store.MarkOutboxMessageSent(message.ID, r.now()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// backoff doubles the retry delay for each previous attempt, capped at an hour
+func (r *Relay) backoff(attempts int) time.Duration {
+	delay := r.retryDelay
+	for i := 0; i < attempts && delay < time.Hour; i++ {
+		delay *= 2
+	}
+	if delay > time.Hour {
+		delay = time.Hour
+	}
+	return delay
+}
@@ -0,0 +1,197 @@
+package outbox
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"orders-service/events"
+	"orders-service/models"
+	"shared/eventbus"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStore keeps outbox messages in memory
+type fakeStore struct {
+	messages map[uuid.UUID]*models.OutboxMessage
+}
+
+func newFakeStore(messages ...models.OutboxMessage) *fakeStore {
+	store := &fakeStore{messages: make(map[uuid.UUID]*models.OutboxMessage)}
+	for i := range messages {
+		store.messages[messages[i].ID] = &messages[i]
+	}
+	return store
+}
+
+func (s *fakeStore) ListPendingOutboxMessages(now time.Time, maxAttempts, limit int) ([]models.OutboxMessage, error) {
+	pending := []models.OutboxMessage{}
+	for _, message := range s.messages {
+		if message.SentAt == nil && !message.NextAttemptAt.After(now) && message.Attempts < maxAttempts {
+			pending = append(pending, *message)
+		}
+	}
+	return pending, nil
+}
+
+func (s *fakeStore) MarkOutboxMessageSent(id uuid.UUID, sentAt time.Time) error {
+	s.messages[id].SentAt = &sentAt
+	return nil
+}
+
+func (s *fakeStore) MarkOutboxMessageFailed(id uuid.UUID, deliveryErr string, nextAttemptAt time.Time) error {
+	message := s.messages[id]
+	message.Attempts++
+	message.LastError = &deliveryErr
+	message.NextAttemptAt = nextAttemptAt
+	return nil
+}
+
+// fakeDeliverer fails the first failures deliveries
+type fakeDeliverer struct {
+	failures  int
+	delivered []uuid.UUID
+}
+
+func (d *fakeDeliverer) Deliver(message models.OutboxMessage) error {
+	if d.failures > 0 {
+		d.failures--
+		return errors.New("target unavailable")
+	}
+	d.delivered = append(d.delivered, message.ID)
+	return nil
+}
+
+// newTestRelay creates a relay on a controllable clock
+func newTestRelay(store Store, deliverer Deliverer, now *time.Time) *Relay {
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+
+	relay := NewRelay(store, deliverer, time.Second, 3, logger)
+	relay.now = func() time.Time { return *now }
+	return relay
+}
+
+// TestRelayMarksDeliveredMessagesSent tests that a successful delivery marks the message sent
+func TestRelayMarksDeliveredMessagesSent(t *testing.T) {
+	now := time.Now()
+	message := models.OutboxMessage{ID: uuid.New(), EventType: "order.completed", NextAttemptAt: now}
+	store := newFakeStore(message)
+	deliverer := &fakeDeliverer{}
+
+	require.NoError(t, newTestRelay(store, deliverer, &now).ProcessPending())
+
+	assert.Equal(t, []uuid.UUID{message.ID}, deliverer.delivered)
+	require.NotNil(t, store.messages[message.ID].SentAt)
+	assert.Equal(t, 0, store.messages[message.ID].Attempts)
+}
+
+// TestRelayRetriesFailedDeliveries tests that failed deliveries are retried with a growing delay
+func TestRelayRetriesFailedDeliveries(t *testing.T) {
+	now := time.Now()
+	message := models.OutboxMessage{ID: uuid.New(), EventType: "order.completed", NextAttemptAt: now}
+	store := newFakeStore(message)
+	deliverer := &fakeDeliverer{failures: 2}
+	relay := newTestRelay(store, deliverer, &now)
+
+	// First attempt fails and schedules a retry after the base delay
+	require.NoError(t, relay.ProcessPending())
+	stored := store.messages[message.ID]
+	assert.Nil(t, stored.SentAt)
+	assert.Equal(t, 1, stored.Attempts)
+	require.NotNil(t, stored.LastError)
+	assert.Equal(t, "target unavailable", *stored.LastError)
+	assert.Equal(t, now.Add(time.Second), stored.NextAttemptAt)
+
+	// Nothing is retried before the scheduled time
+	require.NoError(t, relay.ProcessPending())
+	assert.Equal(t, 1, stored.Attempts)
+
+	// Second attempt fails and backs off further
+	now = now.Add(time.Second)
+	require.NoError(t, relay.ProcessPending())
+	assert.Equal(t, 2, stored.Attempts)
+	assert.Equal(t, now.Add(2*time.Second), stored.NextAttemptAt)
+
+	// Third attempt succeeds
+	now = now.Add(2 * time.Second)
+	require.NoError(t, relay.ProcessPending())
+	assert.NotNil(t, stored.SentAt)
+	assert.Equal(t, []uuid.UUID{message.ID}, deliverer.delivered)
+}
+
+// TestRelayStopsAfterMaxAttempts tests that messages out of attempts are no longer delivered
+func TestRelayStopsAfterMaxAttempts(t *testing.T) {
+	now := time.Now()
+	message := models.OutboxMessage{ID: uuid.New(), EventType: "order.completed", Attempts: 3, NextAttemptAt: now}
+	store := newFakeStore(message)
+	deliverer := &fakeDeliverer{}
+
+	require.NoError(t, newTestRelay(store, deliverer, &now).ProcessPending())
+
+	assert.Empty(t, deliverer.delivered)
+	assert.Nil(t, store.messages[message.ID].SentAt)
+}
+
+// TestHTTPDeliverer tests that non-2xx responses count as failed deliveries
+func TestHTTPDeliverer(t *testing.T) {
+	tests := map[string]struct {
+		status    int
+		expectErr bool
+	}{
+		"accepted":     {status: http.StatusAccepted},
+		"server error": {status: http.StatusInternalServerError, expectErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var received models.OutboxMessage
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+				w.WriteHeader(tc.status)
+			}))
+			defer server.Close()
+
+			message := models.OutboxMessage{ID: uuid.New(), EventType: "order.completed", Payload: json.RawMessage(`{"status":"completed"}`)}
+			err := NewHTTPDeliverer(server.URL).Deliver(message)
+
+			if tc.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, message.ID, received.ID)
+		})
+	}
+}
+
+// TestBusDelivererPublishesOrderEvent tests that bus delivery republishes the stored order event
+func TestBusDelivererPublishesOrderEvent(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	bus := eventbus.New(logger)
+
+	received := make(chan eventbus.Event, 1)
+	bus.Subscribe(events.OrderCompleted, func(event eventbus.Event) { received <- event })
+
+	orderID := uuid.New()
+	payload, err := json.Marshal(events.OrderStatusEvent{OrderID: orderID, Status: "completed", Action: events.ActionCompleted})
+	require.NoError(t, err)
+
+	require.NoError(t, NewBusDeliverer(bus).Deliver(models.OutboxMessage{ID: uuid.New(), Payload: payload}))
+
+	select {
+	case event := <-received:
+		assert.Equal(t, orderID, event.(events.OrderStatusEvent).OrderID)
+	case <-time.After(time.Second):
+		t.Fatal("expected an order.completed event")
+	}
+}
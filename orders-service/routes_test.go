@@ -0,0 +1,208 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"shared/eventbus"
+	"shared/httpx"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingOrdersHandler answers every route with 200 and records which handler method ran, so
+// router tests check the routing table rather than handler behavior
+type recordingOrdersHandler struct {
+	called string
+	id     string
+}
+
+func (h *recordingOrdersHandler) record(name string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		h.called = name
+		h.id = mux.Vars(r)["id"]
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func (h *recordingOrdersHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
+	h.record("CreateOrder")(w, r)
+}
+func (h *recordingOrdersHandler) GetOrder(w http.ResponseWriter, r *http.Request) {
+	h.record("GetOrder")(w, r)
+}
+func (h *recordingOrdersHandler) GetOrderByNumber(w http.ResponseWriter, r *http.Request) {
+	h.record("GetOrderByNumber")(w, r)
+}
+func (h *recordingOrdersHandler) GetOrderReceipt(w http.ResponseWriter, r *http.Request) {
+	h.record("GetOrderReceipt")(w, r)
+}
+func (h *recordingOrdersHandler) GetOrderTimeline(w http.ResponseWriter, r *http.Request) {
+	h.record("GetOrderTimeline")(w, r)
+}
+func (h *recordingOrdersHandler) UpdateOrder(w http.ResponseWriter, r *http.Request) {
+	h.record("UpdateOrder")(w, r)
+}
+func (h *recordingOrdersHandler) CancelOrder(w http.ResponseWriter, r *http.Request) {
+	h.record("CancelOrder")(w, r)
+}
+func (h *recordingOrdersHandler) ReopenOrder(w http.ResponseWriter, r *http.Request) {
+	h.record("ReopenOrder")(w, r)
+}
+func (h *recordingOrdersHandler) BulkUpdateOrderStatus(w http.ResponseWriter, r *http.Request) {
+	h.record("BulkUpdateOrderStatus")(w, r)
+}
+func (h *recordingOrdersHandler) ListOrders(w http.ResponseWriter, r *http.Request) {
+	h.record("ListOrders")(w, r)
+}
+func (h *recordingOrdersHandler) ExportOrders(w http.ResponseWriter, r *http.Request) {
+	h.record("ExportOrders")(w, r)
+}
+func (h *recordingOrdersHandler) OrderUpdates(w http.ResponseWriter, r *http.Request) {
+	h.record("OrderUpdates")(w, r)
+}
+func (h *recordingOrdersHandler) GetOrderSummary(w http.ResponseWriter, r *http.Request) {
+	h.record("GetOrderSummary")(w, r)
+}
+func (h *recordingOrdersHandler) GetPaymentMethodStats(w http.ResponseWriter, r *http.Request) {
+	h.record("GetPaymentMethodStats")(w, r)
+}
+func (h *recordingOrdersHandler) GetConfig(w http.ResponseWriter, r *http.Request) {
+	h.record("GetConfig")(w, r)
+}
+func (h *recordingOrdersHandler) HealthCheck(w http.ResponseWriter, r *http.Request) {
+	h.record("HealthCheck")(w, r)
+}
+func (h *recordingOrdersHandler) EventBus() *eventbus.Bus {
+	return eventbus.New(logrus.New())
+}
+
+// TestCancelOrderRoute tests that cancelling is POST /orders/{id}/cancel and other methods get a 405
+func TestCancelOrderRoute(t *testing.T) {
+	const orderID = "6f1c2a9e-8d1b-4c3e-9a57-2f4b8e6d0c11"
+
+	testCases := map[string]struct {
+		method          string
+		path            string
+		expectedStatus  int
+		expectedHandler string
+		expectedAllow   string
+	}{
+		"POST cancels": {
+			method:          http.MethodPost,
+			path:            "/api/v1/orders/" + orderID + "/cancel",
+			expectedStatus:  http.StatusOK,
+			expectedHandler: "CancelOrder",
+		},
+		"GET on cancel is not allowed": {
+			method:         http.MethodGet,
+			path:           "/api/v1/orders/" + orderID + "/cancel",
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedAllow:  "POST",
+		},
+		"DELETE on cancel is not allowed": {
+			method:         http.MethodDelete,
+			path:           "/api/v1/orders/" + orderID + "/cancel",
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedAllow:  "POST",
+		},
+		"DELETE on the order does not cancel": {
+			method:         http.MethodDelete,
+			path:           "/api/v1/orders/" + orderID,
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedAllow:  "GET, PUT",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ordersHandler := &recordingOrdersHandler{}
+			router := setupRouter(ordersHandler, 0, logrus.New())
+
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+			assert.Equal(t, tc.expectedHandler, ordersHandler.called)
+			assert.Equal(t, tc.expectedAllow, rr.Header().Get("Allow"))
+			if tc.expectedHandler != "" {
+				assert.Equal(t, orderID, ordersHandler.id)
+			}
+		})
+	}
+}
+
+// TestGetOrderByNumberRoute tests that order numbers are looked up under /orders/number/{number}
+func TestGetOrderByNumberRoute(t *testing.T) {
+	ordersHandler := &recordingOrdersHandler{}
+	router := setupRouter(ordersHandler, 0, logrus.New())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/orders/number/20240301-0001", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "GetOrderByNumber", ordersHandler.called)
+}
+
+// TestOrderTimelineRoute tests that the timeline is GET /orders/{id}/timeline
+func TestOrderTimelineRoute(t *testing.T) {
+	const orderID = "6f1c2a9e-8d1b-4c3e-9a57-2f4b8e6d0c11"
+	ordersHandler := &recordingOrdersHandler{}
+	router := setupRouter(ordersHandler, 0, logrus.New())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/orders/"+orderID+"/timeline", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "GetOrderTimeline", ordersHandler.called)
+	assert.Equal(t, orderID, ordersHandler.id)
+}
+
+// TestBulkOrderStatusRoute tests that bulk-status is not swallowed by the /orders/{id} route
+func TestBulkOrderStatusRoute(t *testing.T) {
+	ordersHandler := &recordingOrdersHandler{}
+	router := setupRouter(ordersHandler, 0, logrus.New())
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/orders/bulk-status", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "BulkUpdateOrderStatus", ordersHandler.called)
+}
+
+// TestConfigRouteRequiresAdmin tests that the effective configuration is only served to admin roles
+func TestConfigRouteRequiresAdmin(t *testing.T) {
+	testCases := map[string]struct {
+		role            string
+		expectedStatus  int
+		expectedHandler string
+	}{
+		"admin reads the config": {role: "admin", expectedStatus: http.StatusOK, expectedHandler: "GetConfig"},
+		"cashier is forbidden":   {role: "cashier", expectedStatus: http.StatusForbidden},
+		"no role is forbidden":   {expectedStatus: http.StatusForbidden},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ordersHandler := &recordingOrdersHandler{}
+			router := setupRouter(ordersHandler, 0, logrus.New())
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/config", nil)
+			if tc.role != "" {
+				req.Header.Set(httpx.UserRoleHeader, tc.role)
+			}
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+			assert.Equal(t, tc.expectedHandler, ordersHandler.called)
+		})
+	}
+}
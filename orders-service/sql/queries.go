@@ -1,14 +1,19 @@
 package sql
 
 import (
+	"context"
 	"database/sql"
 	"embed"
+	"encoding/json"
 	"fmt"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"orders-service/events"
+	"orders-service/ids"
 	"orders-service/models"
+	"shared/eventbus"
 
 	"github.com/google/uuid"
 )
@@ -102,6 +107,10 @@ func loadQuery(filename string) (string, error) {
 type Repository struct {
 	db      *sql.DB
 	queries SQLQueries
+	clock   ids.Clock
+
+	// numbering builds the human-readable number assigned to each new order
+	numbering models.OrderNumberFormat
 }
 
 // NewRepository creates a new repository instance with loaded queries
@@ -112,26 +121,47 @@ func NewRepository(db *sql.DB) (*Repository, error) {
 	}
 
 	return &Repository{
-		db:      db,
-		queries: queries,
+		db:        db,
+		queries:   queries,
+		clock:     ids.SystemClock{},
+		numbering: models.DefaultOrderNumberFormat,
 	}, nil
 }
 
+// SetClock replaces the clock used to stamp updated_at, cancelled_at and new payments
+func (r *Repository) SetClock(clock ids.Clock) {
+	r.clock = clock
+}
+
+// SetOrderNumberFormat replaces how new orders' human-readable numbers are built
+func (r *Repository) SetOrderNumberFormat(format models.OrderNumberFormat) {
+	r.numbering = format
+}
+
 // === ORDER QUERIES ===
 
-// CreateOrder creates a new order with its items in a transaction
-func (r *Repository) CreateOrder(order *models.Order, items []models.OrderedRecipe) error {
-	tx, err := r.db.Begin()
+// CreateOrder creates a new order with its items and payments in a transaction, assigning
+// the order its human-readable number
+func (r *Repository) CreateOrder(ctx context.Context, order *models.Order, items []models.OrderedRecipe, payments []models.Payment) error {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback()
 
+	// The counter row stays locked until commit, so numbers are unique and a rolled back order frees its number
+	scope := r.numbering.Scope(order.OrderDate)
+	var number int
+	if err := tx.QueryRowContext(ctx, r.queries.MustGet("next_order_number"), scope).Scan(&number); err != nil {
+		return fmt.Errorf("failed to assign order number: %w", err)
+	}
+	order.OrderNumber = r.numbering.Format(scope, number)
+
 	// Insert order
 	orderQuery := r.queries.MustGet("create_order")
-	_, err = tx.Exec(orderQuery,
-		order.ID, order.CustomerID, order.OrderDate, order.TotalAmount,
-		order.TaxAmount, order.DiscountAmount, order.FinalAmount, order.PaymentMethod,
+	_, err = tx.ExecContext(ctx, orderQuery,
+		order.ID, order.OrderNumber, order.CustomerID, order.OrderDate, order.TotalAmount,
+		order.TaxAmount, order.ServiceAmount, order.DiscountAmount, order.FinalAmount, order.PaymentMethod,
 		order.OrderStatus, order.Notes, order.CreatedAt, order.UpdatedAt,
 	)
 	if err != nil {
@@ -142,7 +172,7 @@ func (r *Repository) CreateOrder(order *models.Order, items []models.OrderedReci
 	if len(items) > 0 {
 		itemQuery := r.queries.MustGet("create_ordered_recipe")
 		for _, item := range items {
-			_, err = tx.Exec(itemQuery,
+			_, err = tx.ExecContext(ctx, itemQuery,
 				item.ID, item.OrderID, item.RecipeID, item.Quantity,
 				item.UnitPrice, item.TotalPrice, item.SpecialInstructions, item.CreatedAt,
 			)
@@ -152,19 +182,87 @@ func (r *Repository) CreateOrder(order *models.Order, items []models.OrderedReci
 		}
 	}
 
+	if err := r.insertPayments(ctx, tx, payments); err != nil {
+		return err
+	}
+
+	if err := r.insertOrderEvent(ctx, tx, order.ID, models.OrderEventCreated, ""); err != nil {
+		return err
+	}
+	for _, payment := range payments {
+		if err := r.insertOrderEvent(ctx, tx, order.ID, models.OrderEventPaymentRecorded, formatPayment(payment.PaymentMethod, payment.Amount)); err != nil {
+			return err
+		}
+	}
+
 	return tx.Commit()
 }
 
+// insertPayments records payment entries within tx
+func (r *Repository) insertPayments(ctx context.Context, tx *sql.Tx, payments []models.Payment) error {
+	paymentQuery := r.queries.MustGet("create_order_payment")
+	for _, payment := range payments {
+		_, err := tx.ExecContext(ctx, paymentQuery,
+			payment.ID, payment.OrderID, payment.PaymentMethod, payment.Amount, payment.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert order payment: %w", err)
+		}
+	}
+	return nil
+}
+
+// insertOrderEvent records an event on the order's timeline within tx, attributed to the user in ctx.
+// Empty notes are stored as NULL
+func (r *Repository) insertOrderEvent(ctx context.Context, tx *sql.Tx, orderID uuid.UUID, eventType, notes string) error {
+	var eventNotes *string
+	if notes != "" {
+		eventNotes = &notes
+	}
+
+	query := r.queries.MustGet("create_order_event")
+	if _, err := tx.ExecContext(ctx, query, orderID, eventType, models.ActorFromContext(ctx), eventNotes); err != nil {
+		return fmt.Errorf("failed to record order event: %w", err)
+	}
+	return nil
+}
+
+// formatPayment describes a payment entry for the timeline, e.g. "cash 60.00"
+func formatPayment(method string, amount float64) string {
+	return fmt.Sprintf("%s %.2f", method, amount)
+}
+
 // GetOrderByID retrieves an order by its ID
-func (r *Repository) GetOrderByID(id uuid.UUID) (*models.Order, error) {
+func (r *Repository) GetOrderByID(ctx context.Context, id uuid.UUID) (*models.Order, error) {
 	query := r.queries.MustGet("get_order_by_id")
 
 	var order models.Order
-	err := r.db.QueryRow(query, id).Scan(
-		&order.ID, &order.CustomerID, &order.OrderDate, &order.TotalAmount,
-		&order.TaxAmount, &order.DiscountAmount, &order.FinalAmount,
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&order.ID, &order.OrderNumber, &order.CustomerID, &order.OrderDate, &order.TotalAmount,
+		&order.TaxAmount, &order.ServiceAmount, &order.DiscountAmount, &order.FinalAmount,
 		&order.PaymentMethod, &order.OrderStatus, &order.Notes,
-		&order.CreatedAt, &order.UpdatedAt,
+		&order.CancelledAt, &order.CancellationReason, &order.CreatedAt, &order.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("order not found")
+		}
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+
+	return &order, nil
+}
+
+// GetOrderByNumber retrieves an order by its human-readable number
+func (r *Repository) GetOrderByNumber(ctx context.Context, number string) (*models.Order, error) {
+	query := r.queries.MustGet("get_order_by_number")
+
+	var order models.Order
+	err := r.db.QueryRowContext(ctx, query, number).Scan(
+		&order.ID, &order.OrderNumber, &order.CustomerID, &order.OrderDate, &order.TotalAmount,
+		&order.TaxAmount, &order.ServiceAmount, &order.DiscountAmount, &order.FinalAmount,
+		&order.PaymentMethod, &order.OrderStatus, &order.Notes,
+		&order.CancelledAt, &order.CancellationReason, &order.CreatedAt, &order.UpdatedAt,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -177,28 +275,59 @@ func (r *Repository) GetOrderByID(id uuid.UUID) (*models.Order, error) {
 }
 
 // GetOrderWithItems retrieves an order with its ordered recipes
-func (r *Repository) GetOrderWithItems(id uuid.UUID) (*models.OrderWithItems, error) {
-	order, err := r.GetOrderByID(id)
+func (r *Repository) GetOrderWithItems(ctx context.Context, id uuid.UUID) (*models.OrderWithItems, error) {
+	order, err := r.GetOrderByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := r.GetOrderedRecipesByOrderID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
-	items, err := r.GetOrderedRecipesByOrderID(id)
+	payments, err := r.GetOrderPaymentsByOrderID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
 	return &models.OrderWithItems{
-		Order: *order,
-		Items: items,
+		Order:    *order,
+		Items:    items,
+		Payments: payments,
 	}, nil
 }
 
+// GetOrderPaymentsByOrderID retrieves all payment entries for an order
+func (r *Repository) GetOrderPaymentsByOrderID(ctx context.Context, orderID uuid.UUID) ([]models.Payment, error) {
+	query := r.queries.MustGet("get_order_payments_by_order_id")
+
+	rows, err := r.db.QueryContext(ctx, query, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query order payments: %w", err)
+	}
+	defer rows.Close()
+
+	payments := []models.Payment{}
+	for rows.Next() {
+		var payment models.Payment
+		err := rows.Scan(
+			&payment.ID, &payment.OrderID, &payment.PaymentMethod, &payment.Amount, &payment.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan order payment: %w", err)
+		}
+		payments = append(payments, payment)
+	}
+
+	return payments, rows.Err()
+}
+
 // GetOrderedRecipesByOrderID retrieves all ordered recipes for an order
-func (r *Repository) GetOrderedRecipesByOrderID(orderID uuid.UUID) ([]models.OrderedRecipe, error) {
+func (r *Repository) GetOrderedRecipesByOrderID(ctx context.Context, orderID uuid.UUID) ([]models.OrderedRecipe, error) {
 	query := r.queries.MustGet("get_ordered_recipes_by_order_id")
 
-	rows, err := r.db.Query(query, orderID)
+	rows, err := r.db.QueryContext(ctx, query, orderID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query ordered recipes: %w", err)
 	}
@@ -222,12 +351,17 @@ func (r *Repository) GetOrderedRecipesByOrderID(orderID uuid.UUID) ([]models.Ord
 }
 
 // UpdateOrder updates an order
-func (r *Repository) UpdateOrder(id uuid.UUID, updates *models.UpdateOrderRequest) error {
+func (r *Repository) UpdateOrder(ctx context.Context, id uuid.UUID, updates *models.UpdateOrderRequest) error {
 	setParts := []string{}
 	args := []interface{}{}
 	argIndex := 1
 
-	if updates.PaymentMethod != nil {
+	// Replaced payments determine the order's payment method
+	if updates.Payments != nil {
+		setParts = append(setParts, fmt.Sprintf("payment_method = $%d", argIndex))
+		args = append(args, models.PaymentsMethod(updates.Payments))
+		argIndex++
+	} else if updates.PaymentMethod != nil {
 		setParts = append(setParts, fmt.Sprintf("payment_method = $%d", argIndex))
 		args = append(args, *updates.PaymentMethod)
 		argIndex++
@@ -245,8 +379,9 @@ func (r *Repository) UpdateOrder(id uuid.UUID, updates *models.UpdateOrderReques
 		argIndex++
 	}
 
+	// The final amount is stored with the order, so it follows the discount in the same statement
 	if updates.DiscountAmount != nil {
-		setParts = append(setParts, fmt.Sprintf("discount_amount = $%d", argIndex))
+		setParts = append(setParts, fmt.Sprintf("discount_amount = $%d, final_amount = total_amount + tax_amount + service_amount - $%d", argIndex, argIndex))
 		args = append(args, *updates.DiscountAmount)
 		argIndex++
 	}
@@ -257,7 +392,7 @@ func (r *Repository) UpdateOrder(id uuid.UUID, updates *models.UpdateOrderReques
 
 	// Always update the updated_at timestamp
 	setParts = append(setParts, fmt.Sprintf("updated_at = $%d", argIndex))
-	args = append(args, time.Now())
+	args = append(args, r.clock.Now())
 	argIndex++
 
 	// Add the ID for the WHERE clause
@@ -269,7 +404,41 @@ func (r *Repository) UpdateOrder(id uuid.UUID, updates *models.UpdateOrderReques
 		WHERE id = $%d`,
 		strings.Join(setParts, ", "), argIndex)
 
-	result, err := r.db.Exec(query, args...)
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// A payment method change without payments moves the order's single payment to the new method
+	replacePayment := updates.PaymentMethod != nil && updates.Payments == nil
+
+	// Lock the order before checking its status or payments so a concurrent change cannot slip in between
+	if updates.OrderStatus != nil || replacePayment {
+		var current string
+		err := tx.QueryRowContext(ctx, r.queries.MustGet("lock_order_status"), id).Scan(&current)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("order not found")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to lock order: %w", err)
+		}
+		if updates.OrderStatus != nil && !models.CanUpdateOrderStatus(current, *updates.OrderStatus) {
+			return fmt.Errorf("%w: %s", models.ErrStatusTransitionNotAllowed, models.StatusTransitionError(current, *updates.OrderStatus))
+		}
+	}
+
+	if replacePayment {
+		var count int
+		if err := tx.QueryRowContext(ctx, r.queries.MustGet("count_order_payments"), id).Scan(&count); err != nil {
+			return fmt.Errorf("failed to count order payments: %w", err)
+		}
+		if count > 1 {
+			return models.ErrSplitPaymentMethodChange
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("failed to update order: %w", err)
 	}
@@ -283,14 +452,230 @@ func (r *Repository) UpdateOrder(id uuid.UUID, updates *models.UpdateOrderReques
 		return fmt.Errorf("order not found")
 	}
 
+	if updates.Payments != nil {
+		if _, err := tx.ExecContext(ctx, r.queries.MustGet("delete_order_payments"), id); err != nil {
+			return fmt.Errorf("failed to delete order payments: %w", err)
+		}
+
+		now := r.clock.Now()
+		payments := make([]models.Payment, 0, len(updates.Payments))
+		for _, payment := range updates.Payments {
+			payments = append(payments, models.Payment{
+				ID:            ids.NewUUID(),
+				OrderID:       id,
+				PaymentMethod: payment.PaymentMethod,
+				Amount:        payment.Amount,
+				CreatedAt:     now,
+			})
+		}
+		if err := r.insertPayments(ctx, tx, payments); err != nil {
+			return err
+		}
+	} else if replacePayment {
+		// The payment covers the final amount as updated above, so a discount in the same request is included
+		if _, err := tx.ExecContext(ctx, r.queries.MustGet("delete_order_payments"), id); err != nil {
+			return fmt.Errorf("failed to delete order payments: %w", err)
+		}
+		_, err := tx.ExecContext(ctx, r.queries.MustGet("create_order_payment_for_final_amount"),
+			ids.NewUUID(), id, *updates.PaymentMethod, r.clock.Now())
+		if err != nil {
+			return fmt.Errorf("failed to insert order payment: %w", err)
+		}
+	}
+
+	if err := r.recordUpdateEvents(ctx, tx, id, updates); err != nil {
+		return err
+	}
+
+	// Completion notifies other services, so record it with the change to survive a crash before delivery
+	if updates.OrderStatus != nil && *updates.OrderStatus == models.OrderStatusCompleted {
+		event := events.OrderStatusEvent{
+			OrderID:   id,
+			Status:    models.OrderStatusCompleted,
+			Action:    events.ActionCompleted,
+			Timestamp: r.clock.Now(),
+		}
+		if err := r.insertOutboxMessage(ctx, tx, id, event); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// recordUpdateEvents adds the timeline events of an order update: changed fields first, then replaced
+// payments, then the status change, so the timeline ends on the order's new status
+func (r *Repository) recordUpdateEvents(ctx context.Context, tx *sql.Tx, id uuid.UUID, updates *models.UpdateOrderRequest) error {
+	changed := []string{}
+	if updates.PaymentMethod != nil && updates.Payments == nil {
+		changed = append(changed, "payment_method")
+	}
+	if updates.Notes != nil {
+		changed = append(changed, "notes")
+	}
+	if updates.DiscountAmount != nil {
+		changed = append(changed, "discount_amount")
+	}
+	if len(changed) > 0 {
+		if err := r.insertOrderEvent(ctx, tx, id, models.OrderEventUpdated, "changed "+strings.Join(changed, ", ")); err != nil {
+			return err
+		}
+	}
+
+	if updates.Payments != nil {
+		entries := make([]string, 0, len(updates.Payments))
+		for _, payment := range updates.Payments {
+			entries = append(entries, formatPayment(payment.PaymentMethod, payment.Amount))
+		}
+		if err := r.insertOrderEvent(ctx, tx, id, models.OrderEventPaymentsUpdated, strings.Join(entries, ", ")); err != nil {
+			return err
+		}
+	}
+
+	if updates.OrderStatus != nil {
+		if err := r.insertOrderEvent(ctx, tx, id, models.OrderEventStatusChanged, ""); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-// CancelOrder sets an order status to cancelled
-func (r *Repository) CancelOrder(id uuid.UUID) error {
+// BulkUpdateOrderStatus moves each order to status in one transaction, locking every order before checking
+// its transition. Missing orders and illegal transitions are reported in their result and skipped; the
+// legal ones are committed together. Results follow the order of ids.
+func (r *Repository) BulkUpdateOrderStatus(ctx context.Context, ids []uuid.UUID, status string) ([]models.BulkStatusResult, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := r.clock.Now()
+	results := make([]models.BulkStatusResult, 0, len(ids))
+	for _, id := range ids {
+		result := models.BulkStatusResult{OrderID: id}
+
+		var current string
+		err := tx.QueryRowContext(ctx, r.queries.MustGet("lock_order_status"), id).Scan(&current)
+		if err == sql.ErrNoRows {
+			result.Error = "order not found"
+			results = append(results, result)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to lock order %s: %w", id, err)
+		}
+
+		result.PreviousStatus = current
+		if !models.CanTransitionOrderStatus(current, status) {
+			result.Status = current
+			result.Error = models.StatusTransitionError(current, status)
+			results = append(results, result)
+			continue
+		}
+
+		if _, err := tx.ExecContext(ctx, r.queries.MustGet("update_order_status"), status, now, id); err != nil {
+			return nil, fmt.Errorf("failed to update order %s status: %w", id, err)
+		}
+		if err := r.insertOrderEvent(ctx, tx, id, models.OrderEventStatusChanged, ""); err != nil {
+			return nil, err
+		}
+
+		// Completion notifies other services the same way a single order update does
+		if status == models.OrderStatusCompleted {
+			event := events.OrderStatusEvent{
+				OrderID:   id,
+				Status:    models.OrderStatusCompleted,
+				Action:    events.ActionCompleted,
+				Timestamp: now,
+			}
+			if err := r.insertOutboxMessage(ctx, tx, id, event); err != nil {
+				return nil, err
+			}
+		}
+
+		result.Updated = true
+		result.Status = status
+		results = append(results, result)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit bulk status update: %w", err)
+	}
+	return results, nil
+}
+
+// insertOutboxMessage records an event for the outbox relay within the caller's transaction
+func (r *Repository) insertOutboxMessage(ctx context.Context, tx *sql.Tx, aggregateID uuid.UUID, event eventbus.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode outbox payload: %w", err)
+	}
+
+	query := r.queries.MustGet("create_outbox_message")
+	if _, err := tx.ExecContext(ctx, query, ids.NewUUID(), aggregateID, string(event.EventType()), payload, event.OccurredAt()); err != nil {
+		return fmt.Errorf("failed to create outbox message: %w", err)
+	}
+	return nil
+}
+
+// === OUTBOX QUERIES ===
+
+// ListPendingOutboxMessages retrieves unsent messages due at now that have attempts left
+func (r *Repository) ListPendingOutboxMessages(now time.Time, maxAttempts, limit int) ([]models.OutboxMessage, error) {
+	query := r.queries.MustGet("list_pending_outbox_messages")
+
+	rows, err := r.db.Query(query, now, maxAttempts, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query outbox messages: %w", err)
+	}
+	defer rows.Close()
+
+	messages := []models.OutboxMessage{}
+	for rows.Next() {
+		var message models.OutboxMessage
+		var payload []byte
+		err := rows.Scan(
+			&message.ID, &message.AggregateID, &message.EventType, &payload, &message.Attempts,
+			&message.LastError, &message.CreatedAt, &message.NextAttemptAt, &message.SentAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan outbox message: %w", err)
+		}
+		message.Payload = payload
+		messages = append(messages, message)
+	}
+
+	return messages, rows.Err()
+}
+
+// MarkOutboxMessageSent records a successful delivery
+func (r *Repository) MarkOutboxMessageSent(id uuid.UUID, sentAt time.Time) error {
+	if _, err := r.db.Exec(r.queries.MustGet("mark_outbox_message_sent"), sentAt, id); err != nil {
+		return fmt.Errorf("failed to mark outbox message sent: %w", err)
+	}
+	return nil
+}
+
+// MarkOutboxMessageFailed records a failed delivery and when to try again
+func (r *Repository) MarkOutboxMessageFailed(id uuid.UUID, deliveryErr string, nextAttemptAt time.Time) error {
+	if _, err := r.db.Exec(r.queries.MustGet("mark_outbox_message_failed"), deliveryErr, nextAttemptAt, id); err != nil {
+		return fmt.Errorf("failed to mark outbox message failed: %w", err)
+	}
+	return nil
+}
+
+// CancelOrder sets an order status to cancelled and records the reason
+func (r *Repository) CancelOrder(ctx context.Context, id uuid.UUID, reason string) error {
 	query := r.queries.MustGet("cancel_order")
 
-	result, err := r.db.Exec(query, time.Now(), id)
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, query, r.clock.Now(), id, reason)
 	if err != nil {
 		return fmt.Errorf("failed to cancel order: %w", err)
 	}
@@ -300,15 +685,85 @@ func (r *Repository) CancelOrder(id uuid.UUID) error {
 		return fmt.Errorf("failed to get rows affected: %w", err)
 	}
 
+	// Nothing was cancelled: tell a missing order from one that is no longer pending or preparing
 	if rowsAffected == 0 {
-		return fmt.Errorf("order not found or already completed")
+		var current string
+		err := tx.QueryRowContext(ctx, r.queries.MustGet("lock_order_status"), id).Scan(&current)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("order not found")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to lock order: %w", err)
+		}
+		return fmt.Errorf("%w: %s", models.ErrStatusTransitionNotAllowed, models.StatusTransitionError(current, models.OrderStatusCancelled))
 	}
 
-	return nil
+	if err := r.insertOrderEvent(ctx, tx, id, models.OrderEventCancelled, reason); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ReopenOrder restores a cancelled order to pending if it was cancelled at or after cutoff
+func (r *Repository) ReopenOrder(ctx context.Context, id uuid.UUID, cutoff time.Time) error {
+	query := r.queries.MustGet("reopen_order")
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, query, r.clock.Now(), id, cutoff)
+	if err != nil {
+		return fmt.Errorf("failed to reopen order: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return models.ErrReopenWindowExpired
+	}
+
+	if err := r.insertOrderEvent(ctx, tx, id, models.OrderEventReopened, ""); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetOrderTimeline retrieves the lifecycle events of an order, oldest first
+func (r *Repository) GetOrderTimeline(ctx context.Context, orderID uuid.UUID) ([]models.OrderEvent, error) {
+	query := r.queries.MustGet("get_order_timeline")
+
+	rows, err := r.db.QueryContext(ctx, query, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query order events: %w", err)
+	}
+	defer rows.Close()
+
+	timeline := []models.OrderEvent{}
+	for rows.Next() {
+		var event models.OrderEvent
+		err := rows.Scan(
+			&event.ID, &event.OrderID, &event.EventType, &event.OrderStatus,
+			&event.Actor, &event.Notes, &event.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan order event: %w", err)
+		}
+		timeline = append(timeline, event)
+	}
+
+	return timeline, rows.Err()
 }
 
 // ListOrders retrieves orders with filtering and pagination
-func (r *Repository) ListOrders(filter *models.OrderFilter) ([]models.Order, int, error) {
+func (r *Repository) ListOrders(ctx context.Context, filter *models.OrderFilter) ([]models.Order, int, error) {
 	// Build WHERE conditions
 	whereParts := []string{}
 	args := []interface{}{}
@@ -366,7 +821,7 @@ func (r *Repository) ListOrders(filter *models.OrderFilter) ([]models.Order, int
 	baseCountQuery := r.queries.MustGet("count_orders_base")
 	countQuery := fmt.Sprintf("%s %s", baseCountQuery, whereClause)
 	var totalCount int
-	err := r.db.QueryRow(countQuery, args...).Scan(&totalCount)
+	err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&totalCount)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to get order count: %w", err)
 	}
@@ -402,7 +857,7 @@ func (r *Repository) ListOrders(filter *models.OrderFilter) ([]models.Order, int
 
 	args = append(args, limit, offset)
 
-	rows, err := r.db.Query(query, args...)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to query orders: %w", err)
 	}
@@ -412,10 +867,10 @@ func (r *Repository) ListOrders(filter *models.OrderFilter) ([]models.Order, int
 	for rows.Next() {
 		var order models.Order
 		err := rows.Scan(
-			&order.ID, &order.CustomerID, &order.OrderDate, &order.TotalAmount,
-			&order.TaxAmount, &order.DiscountAmount, &order.FinalAmount,
+			&order.ID, &order.OrderNumber, &order.CustomerID, &order.OrderDate, &order.TotalAmount,
+			&order.TaxAmount, &order.ServiceAmount, &order.DiscountAmount, &order.FinalAmount,
 			&order.PaymentMethod, &order.OrderStatus, &order.Notes,
-			&order.CreatedAt, &order.UpdatedAt,
+			&order.CancelledAt, &order.CancellationReason, &order.CreatedAt, &order.UpdatedAt,
 		)
 		if err != nil {
 			return nil, 0, fmt.Errorf("failed to scan order: %w", err)
@@ -426,12 +881,90 @@ func (r *Repository) ListOrders(filter *models.OrderFilter) ([]models.Order, int
 	return orders, totalCount, rows.Err()
 }
 
+// StreamOrdersForExport calls emit for each order placed between from and to, oldest first, without
+// buffering the range; with includeItems the order's line items are loaded by the same query
+func (r *Repository) StreamOrdersForExport(ctx context.Context, from, to time.Time, includeItems bool, emit func(order models.Order, items []models.OrderedRecipe) error) error {
+	queryName := "export_orders"
+	if includeItems {
+		queryName = "export_orders_with_items"
+	}
+
+	rows, err := r.db.QueryContext(ctx, r.queries.MustGet(queryName), from, to)
+	if err != nil {
+		return fmt.Errorf("failed to query orders for export: %w", err)
+	}
+	defer rows.Close()
+
+	var current *models.Order
+	var items []models.OrderedRecipe
+
+	for rows.Next() {
+		var order models.Order
+		dest := []interface{}{
+			&order.ID, &order.OrderNumber, &order.CustomerID, &order.OrderDate, &order.TotalAmount,
+			&order.TaxAmount, &order.ServiceAmount, &order.DiscountAmount, &order.FinalAmount,
+			&order.PaymentMethod, &order.OrderStatus, &order.Notes,
+			&order.CancelledAt, &order.CancellationReason, &order.CreatedAt, &order.UpdatedAt,
+		}
+
+		// Item columns are NULL for orders without line items (LEFT JOIN)
+		var itemID, recipeID uuid.NullUUID
+		var quantity sql.NullInt64
+		var unitPrice, totalPrice sql.NullFloat64
+		var instructions sql.NullString
+		var itemCreatedAt sql.NullTime
+		if includeItems {
+			dest = append(dest, &itemID, &recipeID, &quantity, &unitPrice, &totalPrice, &instructions, &itemCreatedAt)
+		}
+
+		if err := rows.Scan(dest...); err != nil {
+			return fmt.Errorf("failed to scan order for export: %w", err)
+		}
+
+		// Rows of one order are adjacent, so a new ID means the previous order is complete
+		if current != nil && current.ID != order.ID {
+			if err := emit(*current, items); err != nil {
+				return err
+			}
+			items = nil
+		}
+		if current == nil || current.ID != order.ID {
+			current = &order
+		}
+
+		if itemID.Valid {
+			item := models.OrderedRecipe{
+				ID:         itemID.UUID,
+				OrderID:    order.ID,
+				RecipeID:   recipeID.UUID,
+				Quantity:   int(quantity.Int64),
+				UnitPrice:  unitPrice.Float64,
+				TotalPrice: totalPrice.Float64,
+				CreatedAt:  itemCreatedAt.Time,
+			}
+			if instructions.Valid {
+				item.SpecialInstructions = &instructions.String
+			}
+			items = append(items, item)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating orders for export: %w", err)
+	}
+
+	if current != nil {
+		return emit(*current, items)
+	}
+	return nil
+}
+
 // GetOrderSummary retrieves order statistics
-func (r *Repository) GetOrderSummary() (*models.OrderSummary, error) {
+func (r *Repository) GetOrderSummary(ctx context.Context) (*models.OrderSummary, error) {
 	query := r.queries.MustGet("get_order_summary")
 
 	var summary models.OrderSummary
-	err := r.db.QueryRow(query).Scan(
+	err := r.db.QueryRowContext(ctx, query).Scan(
 		&summary.TotalOrders, &summary.PendingOrders, &summary.CompletedOrders,
 		&summary.CancelledOrders, &summary.TotalRevenue, &summary.AverageOrder,
 	)
@@ -439,14 +972,30 @@ func (r *Repository) GetOrderSummary() (*models.OrderSummary, error) {
 		return nil, fmt.Errorf("failed to get order summary: %w", err)
 	}
 
-	return &summary, nil
+	rows, err := r.db.QueryContext(ctx, r.queries.MustGet("get_cancellation_reason_counts"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cancellation reasons: %w", err)
+	}
+	defer rows.Close()
+
+	summary.CancellationReasons = make(map[string]int)
+	for rows.Next() {
+		var reason string
+		var count int
+		if err := rows.Scan(&reason, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan cancellation reason: %w", err)
+		}
+		summary.CancellationReasons[reason] = count
+	}
+
+	return &summary, rows.Err()
 }
 
 // GetPaymentMethodStats retrieves payment method statistics
-func (r *Repository) GetPaymentMethodStats() ([]models.PaymentMethodStats, error) {
+func (r *Repository) GetPaymentMethodStats(ctx context.Context) ([]models.PaymentMethodStats, error) {
 	query := r.queries.MustGet("get_payment_method_stats")
 
-	rows, err := r.db.Query(query)
+	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query payment method stats: %w", err)
 	}
@@ -470,10 +1019,10 @@ func (r *Repository) GetPaymentMethodStats() ([]models.PaymentMethodStats, error
 // === HEALTH CHECK ===
 
 // HealthCheck verifies database connectivity
-func (r *Repository) HealthCheck() error {
+func (r *Repository) HealthCheck(ctx context.Context) error {
 	query := r.queries.MustGet("health_check")
 	var result int
-	err := r.db.QueryRow(query).Scan(&result)
+	err := r.db.QueryRowContext(ctx, query).Scan(&result)
 	if err != nil {
 		return fmt.Errorf("health check failed: %w", err)
 	}
@@ -0,0 +1,673 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"orders-service/ids"
+	"orders-service/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupTestRepository creates a repository backed by sqlmock
+func setupTestRepository(t *testing.T) (*Repository, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	repo, err := NewRepository(db)
+	require.NoError(t, err)
+
+	return repo, mock
+}
+
+// TestCreateOrderRecordsPayments tests that every payment entry is inserted with the order
+func TestCreateOrderRecordsPayments(t *testing.T) {
+	repo, mock := setupTestRepository(t)
+
+	now := time.Now()
+	order := &models.Order{ID: uuid.New(), TotalAmount: 50.0, TaxAmount: 6.5, FinalAmount: 56.5, PaymentMethod: models.PaymentMethodSplit, OrderStatus: models.OrderStatusPending}
+	payments := []models.Payment{
+		{ID: uuid.New(), OrderID: order.ID, PaymentMethod: "cash", Amount: 30.0, CreatedAt: now},
+		{ID: uuid.New(), OrderID: order.ID, PaymentMethod: "card", Amount: 26.5, CreatedAt: now},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO order_number_counters").WillReturnRows(sqlmock.NewRows([]string{"last_number"}).AddRow(1))
+	mock.ExpectExec("INSERT INTO orders").WillReturnResult(sqlmock.NewResult(0, 1))
+	for _, payment := range payments {
+		mock.ExpectExec("INSERT INTO order_payments").
+			WithArgs(payment.ID, order.ID, payment.PaymentMethod, payment.Amount, now).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectExec("INSERT INTO order_events").
+		WithArgs(order.ID, models.OrderEventCreated, nil, nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO order_events").
+		WithArgs(order.ID, models.OrderEventPaymentRecorded, nil, "cash 30.00").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO order_events").
+		WithArgs(order.ID, models.OrderEventPaymentRecorded, nil, "card 26.50").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	require.NoError(t, repo.CreateOrder(context.Background(), order, nil, payments))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestCreateOrderAssignsOrderNumber tests that the order takes the next number of its scope inside the transaction
+func TestCreateOrderAssignsOrderNumber(t *testing.T) {
+	orderDate := time.Date(2024, time.March, 1, 18, 45, 0, 0, time.UTC)
+
+	testCases := map[string]struct {
+		format         models.OrderNumberFormat
+		nextNumber     int
+		expectedScope  string
+		expectedNumber string
+	}{
+		"daily reset": {
+			format: models.DefaultOrderNumberFormat, nextNumber: 7,
+			expectedScope: "20240301", expectedNumber: "20240301-0007",
+		},
+		"never reset": {
+			format: models.OrderNumberFormat{Reset: models.OrderNumberResetNever, Digits: 6}, nextNumber: 1234,
+			expectedScope: "all", expectedNumber: "001234",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			repo, mock := setupTestRepository(t)
+			repo.SetOrderNumberFormat(tc.format)
+			order := &models.Order{ID: uuid.New(), OrderDate: orderDate, PaymentMethod: "cash", OrderStatus: models.OrderStatusPending}
+
+			mock.ExpectBegin()
+			mock.ExpectQuery("ON CONFLICT \\(scope\\) DO UPDATE").
+				WithArgs(tc.expectedScope).
+				WillReturnRows(sqlmock.NewRows([]string{"last_number"}).AddRow(tc.nextNumber))
+			mock.ExpectExec("INSERT INTO orders").
+				WithArgs(order.ID, tc.expectedNumber, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+					sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec("INSERT INTO order_events").WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+
+			require.NoError(t, repo.CreateOrder(context.Background(), order, nil, nil))
+			assert.Equal(t, tc.expectedNumber, order.OrderNumber)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// TestCreateOrderNumberFailureRollsBack tests that the order is not inserted when no number can be assigned
+func TestCreateOrderNumberFailureRollsBack(t *testing.T) {
+	repo, mock := setupTestRepository(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO order_number_counters").WillReturnError(assert.AnError)
+	mock.ExpectRollback()
+
+	err := repo.CreateOrder(context.Background(), &models.Order{ID: uuid.New()}, nil, nil)
+	assert.ErrorContains(t, err, "failed to assign order number")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestGetOrderByNumber tests looking an order up by its human-readable number
+func TestGetOrderByNumber(t *testing.T) {
+	repo, mock := setupTestRepository(t)
+	orderID := uuid.New()
+	now := time.Now()
+
+	mock.ExpectQuery("WHERE order_number = \\$1").
+		WithArgs("20240301-0001").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "order_number", "customer_id", "order_date", "total_amount", "tax_amount",
+			"service_amount", "discount_amount", "final_amount", "payment_method", "order_status",
+			"notes", "cancelled_at", "cancellation_reason", "created_at", "updated_at",
+		}).AddRow(orderID, "20240301-0001", nil, now, 10.0, 1.3, 1.0, 0.0, 12.3, "cash", "pending", nil, nil, nil, now, now))
+	mock.ExpectQuery("WHERE order_number = \\$1").
+		WithArgs("20240301-0002").
+		WillReturnError(sql.ErrNoRows)
+
+	order, err := repo.GetOrderByNumber(context.Background(), "20240301-0001")
+	require.NoError(t, err)
+	assert.Equal(t, orderID, order.ID)
+	assert.Equal(t, "20240301-0001", order.OrderNumber)
+
+	_, err = repo.GetOrderByNumber(context.Background(), "20240301-0002")
+	assert.EqualError(t, err, "order not found")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestUpdateOrderReplacesPayments tests that updated payments replace the existing entries
+func TestUpdateOrderReplacesPayments(t *testing.T) {
+	repo, mock := setupTestRepository(t)
+	now := time.Date(2024, time.March, 1, 12, 30, 0, 0, time.UTC)
+	repo.SetClock(ids.FixedClock{Time: now})
+
+	orderID := uuid.New()
+	updates := &models.UpdateOrderRequest{
+		Payments: []models.PaymentRequest{{PaymentMethod: "cash", Amount: 60.0}, {PaymentMethod: "sinpe", Amount: 53.0}},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE orders SET payment_method = \\$1, updated_at = \\$2 WHERE id = \\$3").
+		WithArgs(models.PaymentMethodSplit, now, orderID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM order_payments").WithArgs(orderID).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO order_payments").
+		WithArgs(sqlmock.AnyArg(), orderID, "cash", 60.0, now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO order_payments").
+		WithArgs(sqlmock.AnyArg(), orderID, "sinpe", 53.0, now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO order_events").
+		WithArgs(orderID, models.OrderEventPaymentsUpdated, nil, "cash 60.00, sinpe 53.00").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	require.NoError(t, repo.UpdateOrder(context.Background(), orderID, updates))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestUpdateOrderPaymentMethodReplacesPayment tests that a payment method change rewrites the single payment
+// entry, which the payment method stats attribute the order to
+func TestUpdateOrderPaymentMethodReplacesPayment(t *testing.T) {
+	repo, mock := setupTestRepository(t)
+	now := time.Date(2024, time.March, 1, 12, 30, 0, 0, time.UTC)
+	repo.SetClock(ids.FixedClock{Time: now})
+
+	orderID := uuid.New()
+	method := models.PaymentMethodCard
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT order_status FROM orders WHERE id = \\$1 FOR UPDATE").
+		WithArgs(orderID).
+		WillReturnRows(sqlmock.NewRows([]string{"order_status"}).AddRow(models.OrderStatusCompleted))
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM order_payments").
+		WithArgs(orderID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectExec("UPDATE orders SET payment_method = \\$1, updated_at = \\$2 WHERE id = \\$3").
+		WithArgs(method, now, orderID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM order_payments").WithArgs(orderID).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO order_payments .* SELECT \\$1, id, \\$3, final_amount, \\$4 FROM orders WHERE id = \\$2").
+		WithArgs(sqlmock.AnyArg(), orderID, method, now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO order_events").
+		WithArgs(orderID, models.OrderEventUpdated, nil, "changed payment_method").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	require.NoError(t, repo.UpdateOrder(context.Background(), orderID, &models.UpdateOrderRequest{PaymentMethod: &method}))
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// The stats group by the payment entry's method before the order's, so the old entry would keep the old method
+	queries, err := LoadQueries()
+	require.NoError(t, err)
+	assert.Contains(t, queries.MustGet("get_payment_method_stats"), "GROUP BY COALESCE(p.payment_method, o.payment_method)")
+}
+
+// TestUpdateOrderPaymentMethodRejectsSplitPayments tests that split payments are not collapsed by a payment method change
+func TestUpdateOrderPaymentMethodRejectsSplitPayments(t *testing.T) {
+	repo, mock := setupTestRepository(t)
+	orderID := uuid.New()
+	method := models.PaymentMethodCard
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT order_status FROM orders").
+		WithArgs(orderID).
+		WillReturnRows(sqlmock.NewRows([]string{"order_status"}).AddRow(models.OrderStatusCompleted))
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM order_payments").
+		WithArgs(orderID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectRollback()
+
+	err := repo.UpdateOrder(context.Background(), orderID, &models.UpdateOrderRequest{PaymentMethod: &method})
+	assert.ErrorIs(t, err, models.ErrSplitPaymentMethodChange)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestUpdateOrderDiscountUpdatesFinalAmount tests that a discount change rewrites the stored final amount
+func TestUpdateOrderDiscountUpdatesFinalAmount(t *testing.T) {
+	repo, mock := setupTestRepository(t)
+	orderID := uuid.New()
+	discount := 10.0
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE orders SET discount_amount = \\$1, final_amount = total_amount \\+ tax_amount \\+ service_amount - \\$1, updated_at = \\$2 WHERE id = \\$3").
+		WithArgs(discount, sqlmock.AnyArg(), orderID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO order_events").
+		WithArgs(orderID, models.OrderEventUpdated, nil, "changed discount_amount").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	require.NoError(t, repo.UpdateOrder(context.Background(), orderID, &models.UpdateOrderRequest{DiscountAmount: &discount}))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestReopenOrder tests that a cancelled order is reopened only inside the grace period
+func TestReopenOrder(t *testing.T) {
+	tests := map[string]struct {
+		rowsAffected int64
+		expectedErr  error
+	}{
+		"reopened within grace period": {rowsAffected: 1},
+		"grace period expired":         {rowsAffected: 0, expectedErr: models.ErrReopenWindowExpired},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			repo, mock := setupTestRepository(t)
+			now := time.Date(2024, time.March, 1, 12, 30, 0, 0, time.UTC)
+			repo.SetClock(ids.FixedClock{Time: now})
+
+			orderID := uuid.New()
+			cutoff := now.Add(-15 * time.Minute)
+
+			mock.ExpectBegin()
+			mock.ExpectExec("UPDATE orders SET order_status = 'pending'").
+				WithArgs(now, orderID, cutoff).
+				WillReturnResult(sqlmock.NewResult(0, tc.rowsAffected))
+			if tc.expectedErr == nil {
+				mock.ExpectExec("INSERT INTO order_events").
+					WithArgs(orderID, models.OrderEventReopened, nil, nil).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			} else {
+				mock.ExpectRollback()
+			}
+
+			err := repo.ReopenOrder(context.Background(), orderID, cutoff)
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// TestCancelOrderRecordsReason tests that the cancellation reason is written with the cancelled status
+func TestCancelOrderRecordsReason(t *testing.T) {
+	repo, mock := setupTestRepository(t)
+	now := time.Date(2024, time.March, 1, 12, 30, 0, 0, time.UTC)
+	repo.SetClock(ids.FixedClock{Time: now})
+	orderID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE orders SET order_status = 'cancelled', cancelled_at = \\$1, cancellation_reason = \\$3").
+		WithArgs(now, orderID, "out_of_stock").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO order_events").
+		WithArgs(orderID, models.OrderEventCancelled, nil, "out_of_stock").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	require.NoError(t, repo.CancelOrder(context.Background(), orderID, "out_of_stock"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestCancelOrderRejectsNonCancellableStatus tests that an order which is no longer pending or preparing
+// is left untouched and reported as a disallowed transition, and a missing order as not found
+func TestCancelOrderRejectsNonCancellableStatus(t *testing.T) {
+	tests := map[string]struct {
+		status        string
+		missing       bool
+		expectedError error
+	}{
+		"already cancelled": {status: "cancelled", expectedError: models.ErrStatusTransitionNotAllowed},
+		"completed":         {status: "completed", expectedError: models.ErrStatusTransitionNotAllowed},
+		"missing":           {missing: true},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			repo, mock := setupTestRepository(t)
+			now := time.Date(2024, time.March, 1, 12, 30, 0, 0, time.UTC)
+			repo.SetClock(ids.FixedClock{Time: now})
+			orderID := uuid.New()
+
+			mock.ExpectBegin()
+			mock.ExpectExec("UPDATE orders SET order_status = 'cancelled'.*WHERE id = \\$2 AND order_status IN \\('pending', 'preparing'\\)").
+				WithArgs(now, orderID, "other").
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			lock := mock.ExpectQuery("SELECT order_status FROM orders WHERE id = \\$1 FOR UPDATE").WithArgs(orderID)
+			if tt.missing {
+				lock.WillReturnError(sql.ErrNoRows)
+			} else {
+				lock.WillReturnRows(sqlmock.NewRows([]string{"order_status"}).AddRow(tt.status))
+			}
+			mock.ExpectRollback()
+
+			err := repo.CancelOrder(context.Background(), orderID, "other")
+			require.Error(t, err)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.Contains(t, err.Error(), "not found")
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// TestUpdateOrderRecordsTimelineEvents tests that an update records its field changes before the status change,
+// attributed to the user in the context
+func TestUpdateOrderRecordsTimelineEvents(t *testing.T) {
+	repo, mock := setupTestRepository(t)
+	orderID := uuid.New()
+	status := models.OrderStatusPreparing
+	notes := "no sprinkles"
+	discount := 5.0
+	ctx := models.WithActor(context.Background(), "cashier-1")
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT order_status FROM orders").
+		WithArgs(orderID).
+		WillReturnRows(sqlmock.NewRows([]string{"order_status"}).AddRow(models.OrderStatusPending))
+	mock.ExpectExec("UPDATE orders").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO order_events").
+		WithArgs(orderID, models.OrderEventUpdated, "cashier-1", "changed notes, discount_amount").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO order_events").
+		WithArgs(orderID, models.OrderEventStatusChanged, "cashier-1", nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	updates := &models.UpdateOrderRequest{OrderStatus: &status, Notes: &notes, DiscountAmount: &discount}
+	require.NoError(t, repo.UpdateOrder(ctx, orderID, updates))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestGetOrderTimeline tests that events are read back oldest first
+func TestGetOrderTimeline(t *testing.T) {
+	repo, mock := setupTestRepository(t)
+	orderID := uuid.New()
+	created := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("FROM order_events\\s+WHERE order_id = \\$1\\s+ORDER BY created_at, id").
+		WithArgs(orderID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "order_id", "event_type", "order_status", "actor", "notes", "created_at"}).
+			AddRow(uuid.New(), orderID, models.OrderEventCreated, models.OrderStatusPending, "cashier-1", nil, created).
+			AddRow(uuid.New(), orderID, models.OrderEventStatusChanged, models.OrderStatusCompleted, nil, nil, created.Add(time.Minute)))
+
+	timeline, err := repo.GetOrderTimeline(context.Background(), orderID)
+	require.NoError(t, err)
+	require.Len(t, timeline, 2)
+	assert.Equal(t, models.OrderEventCreated, timeline[0].EventType)
+	require.NotNil(t, timeline[0].Actor)
+	assert.Equal(t, "cashier-1", *timeline[0].Actor)
+	assert.Equal(t, models.OrderStatusCompleted, timeline[1].OrderStatus)
+	assert.Nil(t, timeline[1].Actor)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestGetOrderSummaryCountsCancellationReasons tests that the summary carries the per-reason breakdown
+func TestGetOrderSummaryCountsCancellationReasons(t *testing.T) {
+	repo, mock := setupTestRepository(t)
+
+	mock.ExpectQuery("COUNT\\(\\*\\) as total_orders").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"total_orders", "pending_orders", "completed_orders", "cancelled_orders", "total_revenue", "average_order",
+		}).AddRow(10, 2, 3, 5, 150.0, 50.0))
+	mock.ExpectQuery("COALESCE\\(cancellation_reason, 'other'\\)").
+		WillReturnRows(sqlmock.NewRows([]string{"reason", "count"}).
+			AddRow("customer_request", 2).
+			AddRow("other", 3))
+
+	summary, err := repo.GetOrderSummary(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 5, summary.CancelledOrders)
+	assert.Equal(t, map[string]int{"customer_request": 2, "other": 3}, summary.CancellationReasons)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestUpdateOrderCompletionWritesOutbox tests that completing an order records an outbox message in the same transaction
+func TestUpdateOrderCompletionWritesOutbox(t *testing.T) {
+	tests := map[string]struct {
+		status       string
+		expectOutbox bool
+	}{
+		"completed order writes outbox message": {status: models.OrderStatusCompleted, expectOutbox: true},
+		"pending order writes nothing":          {status: models.OrderStatusPending},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			repo, mock := setupTestRepository(t)
+
+			orderID := uuid.New()
+			status := tc.status
+
+			mock.ExpectBegin()
+			mock.ExpectQuery("SELECT order_status FROM orders").
+				WithArgs(orderID).
+				WillReturnRows(sqlmock.NewRows([]string{"order_status"}).AddRow(models.OrderStatusPending))
+			mock.ExpectExec("UPDATE orders").
+				WithArgs(status, sqlmock.AnyArg(), orderID).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec("INSERT INTO order_events").
+				WithArgs(orderID, models.OrderEventStatusChanged, nil, nil).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			if tc.expectOutbox {
+				mock.ExpectExec("INSERT INTO outbox").
+					WithArgs(sqlmock.AnyArg(), orderID, "order.completed", sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+			mock.ExpectCommit()
+
+			require.NoError(t, repo.UpdateOrder(context.Background(), orderID, &models.UpdateOrderRequest{OrderStatus: &status}))
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// TestUpdateOrderOutboxFailureRollsBack tests that the order change is not committed without its outbox message
+func TestUpdateOrderOutboxFailureRollsBack(t *testing.T) {
+	repo, mock := setupTestRepository(t)
+
+	orderID := uuid.New()
+	status := models.OrderStatusCompleted
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT order_status FROM orders").
+		WillReturnRows(sqlmock.NewRows([]string{"order_status"}).AddRow(models.OrderStatusPreparing))
+	mock.ExpectExec("UPDATE orders").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO order_events").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO outbox").WillReturnError(assert.AnError)
+	mock.ExpectRollback()
+
+	assert.Error(t, repo.UpdateOrder(context.Background(), orderID, &models.UpdateOrderRequest{OrderStatus: &status}))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestUpdateOrderRejectsStatusTransition tests that the order is locked and a disallowed status is not written
+func TestUpdateOrderRejectsStatusTransition(t *testing.T) {
+	testCases := map[string]struct {
+		current string
+		status  string
+	}{
+		"completed back to pending": {current: models.OrderStatusCompleted, status: models.OrderStatusPending},
+		"cancelled back to pending": {current: models.OrderStatusCancelled, status: models.OrderStatusPending},
+		"pending to cancelled":      {current: models.OrderStatusPending, status: models.OrderStatusCancelled},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			repo, mock := setupTestRepository(t)
+			orderID := uuid.New()
+			status := tc.status
+
+			mock.ExpectBegin()
+			mock.ExpectQuery("SELECT order_status FROM orders WHERE id = \\$1 FOR UPDATE").
+				WithArgs(orderID).
+				WillReturnRows(sqlmock.NewRows([]string{"order_status"}).AddRow(tc.current))
+			mock.ExpectRollback()
+
+			err := repo.UpdateOrder(context.Background(), orderID, &models.UpdateOrderRequest{OrderStatus: &status})
+			assert.ErrorIs(t, err, models.ErrStatusTransitionNotAllowed)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// TestBulkUpdateOrderStatus tests that each order is locked and only legal transitions are written
+func TestBulkUpdateOrderStatus(t *testing.T) {
+	repo, mock := setupTestRepository(t)
+
+	pendingID := uuid.New()
+	completedID := uuid.New()
+	missingID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT order_status FROM orders WHERE id = \\$1 FOR UPDATE").
+		WithArgs(pendingID).
+		WillReturnRows(sqlmock.NewRows([]string{"order_status"}).AddRow(models.OrderStatusPending))
+	mock.ExpectExec("UPDATE orders SET order_status").
+		WithArgs(models.OrderStatusCompleted, sqlmock.AnyArg(), pendingID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO order_events").
+		WithArgs(pendingID, models.OrderEventStatusChanged, nil, nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO outbox").
+		WithArgs(sqlmock.AnyArg(), pendingID, "order.completed", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT order_status FROM orders").
+		WithArgs(completedID).
+		WillReturnRows(sqlmock.NewRows([]string{"order_status"}).AddRow(models.OrderStatusCompleted))
+	mock.ExpectQuery("SELECT order_status FROM orders").
+		WithArgs(missingID).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectCommit()
+
+	results, err := repo.BulkUpdateOrderStatus(context.Background(), []uuid.UUID{pendingID, completedID, missingID}, models.OrderStatusCompleted)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	assert.True(t, results[0].Updated)
+	assert.Equal(t, models.OrderStatusPending, results[0].PreviousStatus)
+	assert.Equal(t, models.OrderStatusCompleted, results[0].Status)
+	assert.False(t, results[1].Updated)
+	assert.Equal(t, "cannot move a completed order to completed", results[1].Error)
+	assert.False(t, results[2].Updated)
+	assert.Equal(t, "order not found", results[2].Error)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestBulkUpdateOrderStatusFailureRollsBack tests that a failed write leaves every order unchanged
+func TestBulkUpdateOrderStatusFailureRollsBack(t *testing.T) {
+	repo, mock := setupTestRepository(t)
+
+	orderID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT order_status FROM orders").
+		WillReturnRows(sqlmock.NewRows([]string{"order_status"}).AddRow(models.OrderStatusPending))
+	mock.ExpectExec("UPDATE orders SET order_status").WillReturnError(assert.AnError)
+	mock.ExpectRollback()
+
+	_, err := repo.BulkUpdateOrderStatus(context.Background(), []uuid.UUID{orderID}, models.OrderStatusPreparing)
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestGetPaymentMethodStatsAttributesSplitPayments tests that stats are aggregated per payment entry
+func TestGetPaymentMethodStatsAttributesSplitPayments(t *testing.T) {
+	repo, mock := setupTestRepository(t)
+
+	mock.ExpectQuery("FROM orders o LEFT JOIN order_payments p ON p.order_id = o.id").
+		WillReturnRows(sqlmock.NewRows([]string{"payment_method", "count", "total_amount", "percentage"}).
+			AddRow("cash", 3, 130.0, 60.0).
+			AddRow("card", 2, 76.5, 40.0))
+
+	stats, err := repo.GetPaymentMethodStats(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, []models.PaymentMethodStats{
+		{PaymentMethod: "cash", Count: 3, TotalAmount: 130.0, Percentage: 60.0},
+		{PaymentMethod: "card", Count: 2, TotalAmount: 76.5, Percentage: 40.0},
+	}, stats)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestPaymentMethodStatsQueryUsesPaymentEntries guards the split-payment attribution in the stats query
+func TestPaymentMethodStatsQueryUsesPaymentEntries(t *testing.T) {
+	queries, err := LoadQueries()
+	require.NoError(t, err)
+
+	query := queries.MustGet("get_payment_method_stats")
+	assert.Contains(t, query, "LEFT JOIN order_payments p ON p.order_id = o.id")
+	assert.Contains(t, query, "COALESCE(p.payment_method, o.payment_method)")
+	assert.Contains(t, query, "COALESCE(p.amount, o.final_amount)")
+}
+
+// TestStreamOrdersForExportGroupsItems tests that adjacent item rows are grouped under their order
+func TestStreamOrdersForExportGroupsItems(t *testing.T) {
+	repo, mock := setupTestRepository(t)
+
+	from := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+	firstID, secondID := uuid.New(), uuid.New()
+	now := time.Now()
+
+	columns := []string{
+		"id", "order_number", "customer_id", "order_date", "total_amount", "tax_amount",
+		"service_amount", "discount_amount", "final_amount", "payment_method", "order_status",
+		"notes", "cancelled_at", "cancellation_reason", "created_at", "updated_at",
+		"item_id", "recipe_id", "quantity", "unit_price", "total_price",
+		"special_instructions", "item_created_at",
+	}
+	mock.ExpectQuery("LEFT JOIN ordered_receipes").
+		WithArgs(from, to).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(firstID, "20240310-0001", nil, now, 50.0, 6.5, 5.0, 0.0, 61.5, "cash", "completed", nil, nil, nil, now, now,
+				uuid.New().String(), uuid.New().String(), 1, 20.0, 20.0, nil, now).
+			AddRow(firstID, "20240310-0001", nil, now, 50.0, 6.5, 5.0, 0.0, 61.5, "cash", "completed", nil, nil, nil, now, now,
+				uuid.New().String(), uuid.New().String(), 1, 30.0, 30.0, "no nuts", now).
+			AddRow(secondID, "20240310-0002", nil, now, 10.0, 1.3, 1.0, 0.0, 12.3, "card", "pending", nil, nil, nil, now, now,
+				nil, nil, nil, nil, nil, nil, nil))
+
+	var orderIDs []uuid.UUID
+	var itemCounts []int
+	err := repo.StreamOrdersForExport(context.Background(), from, to, true, func(order models.Order, items []models.OrderedRecipe) error {
+		orderIDs = append(orderIDs, order.ID)
+		itemCounts = append(itemCounts, len(items))
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{firstID, secondID}, orderIDs)
+	assert.Equal(t, []int{2, 0}, itemCounts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestListOrdersCancelledContext tests that cancelling the request context aborts a slow list query
+func TestListOrdersCancelledContext(t *testing.T) {
+	repo, mock := setupTestRepository(t)
+
+	mock.ExpectQuery("SELECT COUNT").
+		WillDelayFor(time.Second).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	defer cancel()
+
+	start := time.Now()
+	orders, total, err := repo.ListOrders(ctx, &models.OrderFilter{})
+
+	assert.Error(t, err)
+	assert.Nil(t, orders)
+	assert.Zero(t, total)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+}
@@ -1,4 +1,4 @@
--- Cancel an order (set status to cancelled)
+-- Cancel a pending or preparing order (set status to cancelled) recording why
 UPDATE orders 
-SET order_status = 'cancelled', updated_at = $1 
-WHERE id = $2 AND order_status != 'completed'; 
\ No newline at end of file
+SET order_status = 'cancelled', cancelled_at = $1, cancellation_reason = $3, updated_at = $1 
+WHERE id = $2 AND order_status IN ('pending', 'preparing');
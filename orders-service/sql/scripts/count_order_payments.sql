@@ -0,0 +1,2 @@
+-- Count an order's payment entries
+SELECT COUNT(*) FROM order_payments WHERE order_id = $1;
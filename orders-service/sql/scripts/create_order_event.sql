@@ -0,0 +1,3 @@
+-- Record a lifecycle event for an order's timeline, stamped with the order's current status
+INSERT INTO order_events (order_id, event_type, order_status, actor, notes) 
+SELECT id, $2, order_status, $3, $4 FROM orders WHERE id = $1; 
\ No newline at end of file
@@ -0,0 +1,6 @@
+-- Record a payment entry for an order
+INSERT INTO order_payments (
+    id, order_id, payment_method, amount, created_at
+) VALUES (
+    $1, $2, $3, $4, $5
+); 
\ No newline at end of file
@@ -0,0 +1,7 @@
+-- Record an order's whole final amount as its single payment entry
+INSERT INTO order_payments (
+    id, order_id, payment_method, amount, created_at
+)
+SELECT $1, id, $3, final_amount, $4
+FROM orders
+WHERE id = $2;
@@ -0,0 +1,6 @@
+-- Record a pending outbox message in the same transaction as the business change
+INSERT INTO outbox (
+    id, aggregate_id, event_type, payload, attempts, created_at, next_attempt_at
+) VALUES (
+    $1, $2, $3, $4, 0, $5, $5
+); 
\ No newline at end of file
@@ -0,0 +1,3 @@
+-- Remove an order's payment entries before they are replaced
+DELETE FROM order_payments 
+WHERE order_id = $1; 
\ No newline at end of file
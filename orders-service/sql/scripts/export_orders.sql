@@ -0,0 +1,7 @@
+-- Stream orders in a date range for accounting export
+SELECT id, order_number, customer_id, order_date, total_amount, tax_amount,
+       service_amount, discount_amount, final_amount, payment_method, order_status,
+       notes, cancelled_at, cancellation_reason, created_at, updated_at
+FROM orders
+WHERE order_date >= $1 AND order_date <= $2
+ORDER BY order_date, id; 
\ No newline at end of file
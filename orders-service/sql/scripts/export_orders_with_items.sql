@@ -0,0 +1,10 @@
+-- Stream orders in a date range with their line items for accounting export; rows of one order are adjacent
+SELECT o.id, o.order_number, o.customer_id, o.order_date, o.total_amount, o.tax_amount,
+       o.service_amount, o.discount_amount, o.final_amount, o.payment_method, o.order_status,
+       o.notes, o.cancelled_at, o.cancellation_reason, o.created_at, o.updated_at,
+       r.id, r.recipe_id, r.quantity, r.unit_price, r.total_price,
+       r.special_instructions, r.created_at
+FROM orders o
+LEFT JOIN ordered_receipes r ON r.order_id = o.id
+WHERE o.order_date >= $1 AND o.order_date <= $2
+ORDER BY o.order_date, o.id, r.created_at; 
\ No newline at end of file
@@ -0,0 +1,6 @@
+-- Count cancelled orders by reason; orders cancelled before reasons were recorded count as other
+SELECT COALESCE(cancellation_reason, 'other') AS reason, COUNT(*) AS count
+FROM orders
+WHERE order_status = 'cancelled'
+GROUP BY 1
+ORDER BY 1; 
\ No newline at end of file
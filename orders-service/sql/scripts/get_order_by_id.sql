@@ -1,6 +1,6 @@
 -- Get order by ID
-SELECT id, customer_id, order_date, total_amount, tax_amount,
-       discount_amount, final_amount, payment_method, order_status,
-       notes, created_at, updated_at
+SELECT id, order_number, customer_id, order_date, total_amount, tax_amount,
+       service_amount, discount_amount, final_amount, payment_method, order_status,
+       notes, cancelled_at, cancellation_reason, created_at, updated_at
 FROM orders 
 WHERE id = $1; 
\ No newline at end of file
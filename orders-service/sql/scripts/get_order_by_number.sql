@@ -0,0 +1,6 @@
+-- Get order by its human-readable number
+SELECT id, order_number, customer_id, order_date, total_amount, tax_amount,
+       service_amount, discount_amount, final_amount, payment_method, order_status,
+       notes, cancelled_at, cancellation_reason, created_at, updated_at
+FROM orders 
+WHERE order_number = $1; 
\ No newline at end of file
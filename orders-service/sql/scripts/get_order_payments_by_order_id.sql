@@ -0,0 +1,5 @@
+-- Get payment entries by order ID
+SELECT id, order_id, payment_method, amount, created_at
+FROM order_payments 
+WHERE order_id = $1
+ORDER BY created_at; 
\ No newline at end of file
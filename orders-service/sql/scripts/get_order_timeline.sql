@@ -0,0 +1,5 @@
+-- Get the lifecycle events of an order, oldest first
+SELECT id, order_id, event_type, order_status, actor, notes, created_at 
+FROM order_events 
+WHERE order_id = $1 
+ORDER BY created_at, id; 
\ No newline at end of file
@@ -1,10 +1,13 @@
 -- Get payment method statistics
+-- Split orders contribute each payment entry to its own method; orders without
+-- payment entries fall back to the order's payment method and final amount
 SELECT 
-    payment_method,
+    COALESCE(p.payment_method, o.payment_method) as payment_method,
     COUNT(*) as count,
-    COALESCE(SUM(final_amount), 0) as total_amount,
+    COALESCE(SUM(COALESCE(p.amount, o.final_amount)), 0) as total_amount,
     ROUND(COUNT(*) * 100.0 / SUM(COUNT(*)) OVER (), 2) as percentage
-FROM orders 
-WHERE order_status = 'completed'
-GROUP BY payment_method
+FROM orders o
+LEFT JOIN order_payments p ON p.order_id = o.id
+WHERE o.order_status = 'completed'
+GROUP BY COALESCE(p.payment_method, o.payment_method)
 ORDER BY count DESC; 
\ No newline at end of file
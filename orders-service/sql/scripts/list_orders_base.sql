@@ -1,5 +1,5 @@
 -- Base query for listing orders (filters will be added dynamically)
-SELECT id, customer_id, order_date, total_amount, tax_amount,
-       discount_amount, final_amount, payment_method, order_status,
-       notes, created_at, updated_at
+SELECT id, order_number, customer_id, order_date, total_amount, tax_amount,
+       service_amount, discount_amount, final_amount, payment_method, order_status,
+       notes, cancelled_at, cancellation_reason, created_at, updated_at
 FROM orders 
\ No newline at end of file
@@ -0,0 +1,6 @@
+-- Get unsent outbox messages that are due for a delivery attempt
+SELECT id, aggregate_id, event_type, payload, attempts, last_error, created_at, next_attempt_at, sent_at
+FROM outbox
+WHERE sent_at IS NULL AND next_attempt_at <= $1 AND attempts < $2
+ORDER BY created_at
+LIMIT $3; 
\ No newline at end of file
@@ -0,0 +1,2 @@
+-- Lock an order for a status transition
+SELECT order_status FROM orders WHERE id = $1 FOR UPDATE; 
\ No newline at end of file
@@ -0,0 +1,4 @@
+-- Record a failed delivery attempt and schedule the next one
+UPDATE outbox 
+SET attempts = attempts + 1, last_error = $1, next_attempt_at = $2 
+WHERE id = $3; 
\ No newline at end of file
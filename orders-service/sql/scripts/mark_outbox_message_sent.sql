@@ -0,0 +1,4 @@
+-- Mark an outbox message as delivered
+UPDATE outbox 
+SET sent_at = $1, last_error = NULL 
+WHERE id = $2; 
\ No newline at end of file
@@ -0,0 +1,5 @@
+-- Take the next order number of a scope; the upsert locks the scope's row so concurrent orders never share a number
+INSERT INTO order_number_counters (scope, last_number)
+VALUES ($1, 1)
+ON CONFLICT (scope) DO UPDATE SET last_number = order_number_counters.last_number + 1
+RETURNING last_number; 
\ No newline at end of file
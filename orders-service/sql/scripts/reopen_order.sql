@@ -0,0 +1,4 @@
+-- Reopen an order cancelled at or after the grace cutoff (set status back to pending)
+UPDATE orders 
+SET order_status = 'pending', cancelled_at = NULL, cancellation_reason = NULL, updated_at = $1 
+WHERE id = $2 AND order_status = 'cancelled' AND cancelled_at >= $3; 
\ No newline at end of file
@@ -0,0 +1,2 @@
+-- Move an order to a new status
+UPDATE orders SET order_status = $1, updated_at = $2 WHERE id = $3; 
\ No newline at end of file
@@ -0,0 +1,18 @@
+package utils
+
+import (
+	"net/http"
+
+	"orders-service/models"
+	"shared/httpx"
+)
+
+// ActorMiddleware stores the requesting user in the request context so order events record who made them
+func ActorMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if userID := r.Header.Get(httpx.UserIDHeader); userID != "" {
+			r = r.WithContext(models.WithActor(r.Context(), userID))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
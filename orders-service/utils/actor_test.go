@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"orders-service/models"
+	"shared/httpx"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestActorMiddleware tests that the gateway user ID reaches the request context
+func TestActorMiddleware(t *testing.T) {
+	testCases := map[string]struct {
+		userID        string
+		expectedActor *string
+	}{
+		"user ID is stored":     {userID: "7", expectedActor: stringPtr("7")},
+		"missing header is nil": {},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var actor *string
+			handler := ActorMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				actor = models.ActorFromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/orders", nil)
+			if tc.userID != "" {
+				req.Header.Set(httpx.UserIDHeader, tc.userID)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tc.expectedActor, actor)
+		})
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
@@ -6,6 +6,9 @@ import (
 	"time"
 
 	"session-service/models"
+	"shared/configcheck"
+
+	"golang.org/x/crypto/bcrypt"
 )
 
 // Config holds the configuration for the session service
@@ -18,18 +21,28 @@ type Config struct {
 	JWTSecret           string
 	JWTExpirationTime   time.Duration
 	JWTRefreshThreshold time.Duration
+	JWTMinSecretLength  int
 
 	// Session Management settings
 	SessionDefaultExpiration    time.Duration
 	SessionRememberMeExpiration time.Duration
 	SessionCleanupInterval      time.Duration
+	SessionIdleTimeout          time.Duration
 	SessionMaxConcurrent        int
+	SessionCacheSize            int
+	SessionCacheTTL             time.Duration
 
 	// Basic security settings
 	BcryptCost        int
 	MaxLoginAttempts  int
 	LoginCooldownTime time.Duration
 
+	// RequestTimeout bounds how long a request handler may run before the client gets a 503 (0 disables)
+	RequestTimeout time.Duration
+
+	// RequestLogSampleRate logs one in every N successful (2xx) requests; other responses are always logged
+	RequestLogSampleRate int
+
 	// Database settings
 	DatabaseHost     string
 	DatabasePort     int
@@ -40,6 +53,10 @@ type Config struct {
 
 	// Logging
 	LogLevel string
+
+	// Health check dependencies
+	HealthCheckDataService bool
+	DataServiceHealthURL   string
 }
 
 // LoadConfig loads configuration from environment variables with defaults
@@ -53,18 +70,26 @@ func LoadConfig() *Config {
 		JWTSecret:           getEnvString("JWT_SECRET", "your-super-secret-jwt-key-change-in-production"),
 		JWTExpirationTime:   getEnvDuration("JWT_EXPIRATION_TIME", "30m"),
 		JWTRefreshThreshold: getEnvDuration("JWT_REFRESH_THRESHOLD", "5m"),
+		JWTMinSecretLength:  getEnvInt("JWT_MIN_SECRET_LENGTH", 32),
 
 		// Session Management settings
 		SessionDefaultExpiration:    getEnvDuration("SESSION_DEFAULT_EXPIRATION", "30m"),
 		SessionRememberMeExpiration: getEnvDuration("SESSION_REMEMBER_ME_EXPIRATION", "168h"), // 7 days
 		SessionCleanupInterval:      getEnvDuration("SESSION_CLEANUP_INTERVAL", "10m"),
+		SessionIdleTimeout:          getEnvDuration("SESSION_IDLE_TIMEOUT", "2h"), // 0 disables
 		SessionMaxConcurrent:        getEnvInt("SESSION_MAX_CONCURRENT", 5),
+		SessionCacheSize:            getEnvInt("SESSION_CACHE_SIZE", 1000),
+		SessionCacheTTL:             getEnvDuration("SESSION_CACHE_TTL", "30s"),
 
 		// Basic security settings
 		BcryptCost:        getEnvInt("BCRYPT_COST", 12),
 		MaxLoginAttempts:  getEnvInt("MAX_LOGIN_ATTEMPTS", 5),
 		LoginCooldownTime: getEnvDuration("LOGIN_COOLDOWN_TIME", "15m"),
 
+		RequestTimeout: getEnvDuration("REQUEST_TIMEOUT", "10s"),
+
+		RequestLogSampleRate: getEnvInt("REQUEST_LOG_SAMPLE_RATE", 1), // log every request
+
 		// Database settings
 		DatabaseHost:     getEnvString("DB_HOST", "localhost"),
 		DatabasePort:     getEnvInt("DB_PORT", 5432),
@@ -75,6 +100,10 @@ func LoadConfig() *Config {
 
 		// Logging
 		LogLevel: getEnvString("LOG_LEVEL", "info"),
+
+		// Health check dependencies
+		HealthCheckDataService: getEnvBool("HEALTH_CHECK_DATA_SERVICE", true),
+		DataServiceHealthURL:   getEnvString("DATA_SERVICE_HEALTH_URL", "http://localhost:8086/health"),
 	}
 }
 
@@ -85,10 +114,80 @@ func (c *Config) ToSessionConfig() *models.SessionConfig {
 		RememberMeExpiration:  c.SessionRememberMeExpiration,
 		RefreshThreshold:      c.JWTRefreshThreshold,
 		CleanupInterval:       c.SessionCleanupInterval,
+		IdleTimeout:           c.SessionIdleTimeout,
 		MaxConcurrentSessions: c.SessionMaxConcurrent,
+		CacheSize:             c.SessionCacheSize,
+		CacheTTL:              c.SessionCacheTTL,
 	}
 }
 
+// Load reads the configuration like LoadConfig and validates it. The Config is returned
+// even when validation fails so the caller can set up logging before exiting
+func Load() (*Config, error) {
+	cfg := LoadConfig()
+	return cfg, cfg.Validate()
+}
+
+// Validate reports every invalid setting, including environment values LoadConfig
+// could not parse and replaced with defaults
+func (c *Config) Validate() error {
+	v := &configcheck.Validator{}
+
+	v.Port("SESSION_SERVER_PORT", c.ServerPort)
+	v.Port("DB_PORT", strconv.Itoa(c.DatabasePort))
+	v.Required("DB_HOST", c.DatabaseHost)
+	v.Required("DB_USER", c.DatabaseUser)
+	v.Required("DB_PASSWORD", c.DatabasePassword)
+	v.Required("DB_NAME", c.DatabaseName)
+	v.OneOf("DB_SSLMODE", c.DatabaseSSLMode, configcheck.SSLModes...)
+	v.Required("JWT_SECRET", c.JWTSecret)
+	// A short HMAC secret can be brute forced offline from any issued token
+	if c.JWTSecret != "" && len(c.JWTSecret) < c.JWTMinSecretLength {
+		v.Addf("JWT_SECRET must be at least %d characters, got %d", c.JWTMinSecretLength, len(c.JWTSecret))
+	}
+	if c.JWTMinSecretLength < 1 {
+		v.Addf("JWT_MIN_SECRET_LENGTH must be positive, got %d", c.JWTMinSecretLength)
+	}
+
+	for _, key := range []string{
+		"JWT_EXPIRATION_TIME", "JWT_REFRESH_THRESHOLD", "SESSION_DEFAULT_EXPIRATION",
+		"SESSION_REMEMBER_ME_EXPIRATION", "SESSION_CLEANUP_INTERVAL", "SESSION_IDLE_TIMEOUT",
+		"SESSION_CACHE_TTL", "LOGIN_COOLDOWN_TIME", "REQUEST_TIMEOUT",
+	} {
+		v.EnvDuration(key)
+	}
+	for _, key := range []string{"DB_PORT", "JWT_MIN_SECRET_LENGTH", "SESSION_MAX_CONCURRENT", "SESSION_CACHE_SIZE", "BCRYPT_COST", "MAX_LOGIN_ATTEMPTS", "REQUEST_LOG_SAMPLE_RATE"} {
+		v.EnvInt(key)
+	}
+	v.EnvBool("HEALTH_CHECK_DATA_SERVICE")
+
+	if c.JWTExpirationTime <= 0 {
+		v.Addf("JWT_EXPIRATION_TIME must be positive, got %s", c.JWTExpirationTime)
+	}
+	if c.RequestTimeout < 0 {
+		v.Addf("REQUEST_TIMEOUT must not be negative, got %s", c.RequestTimeout)
+	}
+	if c.RequestLogSampleRate < 1 {
+		v.Addf("REQUEST_LOG_SAMPLE_RATE must be at least 1, got %d", c.RequestLogSampleRate)
+	}
+	if c.SessionCleanupInterval <= 0 {
+		v.Addf("SESSION_CLEANUP_INTERVAL must be positive, got %s", c.SessionCleanupInterval)
+	}
+	// Activity is only persisted once the cached copy is older than the cache TTL, so a TTL at or
+	// above the idle timeout lets active sessions be expired as idle
+	if c.SessionIdleTimeout > 0 && c.SessionCacheTTL >= c.SessionIdleTimeout {
+		v.Addf("SESSION_CACHE_TTL must be less than SESSION_IDLE_TIMEOUT, got %s and %s", c.SessionCacheTTL, c.SessionIdleTimeout)
+	}
+	if c.BcryptCost < bcrypt.MinCost || c.BcryptCost > bcrypt.MaxCost {
+		v.Addf("BCRYPT_COST must be between %d and %d, got %d", bcrypt.MinCost, bcrypt.MaxCost, c.BcryptCost)
+	}
+	if c.HealthCheckDataService {
+		v.Required("DATA_SERVICE_HEALTH_URL", c.DataServiceHealthURL)
+	}
+
+	return v.Err()
+}
+
 // Helper functions for environment variable parsing
 func getEnvString(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -106,6 +205,15 @@ func getEnvInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
 func getEnvDuration(key string, defaultValue string) time.Duration {
 	value := getEnvString(key, defaultValue)
 	if duration, err := time.ParseDuration(value); err == nil {
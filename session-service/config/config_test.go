@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"session-service/models"
+	"shared/configcheck"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -23,18 +24,24 @@ func TestLoadConfig(t *testing.T) {
 	assert.Equal(t, "your-super-secret-jwt-key-change-in-production", config.JWTSecret)
 	assert.Equal(t, 30*time.Minute, config.JWTExpirationTime)
 	assert.Equal(t, 5*time.Minute, config.JWTRefreshThreshold)
+	assert.Equal(t, 32, config.JWTMinSecretLength)
 
 	// Session settings
 	assert.Equal(t, 30*time.Minute, config.SessionDefaultExpiration)
 	assert.Equal(t, 168*time.Hour, config.SessionRememberMeExpiration) // 7 days
 	assert.Equal(t, 10*time.Minute, config.SessionCleanupInterval)
+	assert.Equal(t, 2*time.Hour, config.SessionIdleTimeout)
 	assert.Equal(t, 5, config.SessionMaxConcurrent)
+	assert.Equal(t, 1000, config.SessionCacheSize)
+	assert.Equal(t, 30*time.Second, config.SessionCacheTTL)
 	// SessionStorageType removed - database storage is always used
 
 	// Security settings
 	assert.Equal(t, 12, config.BcryptCost)
 	assert.Equal(t, 5, config.MaxLoginAttempts)
 	assert.Equal(t, 15*time.Minute, config.LoginCooldownTime)
+	assert.Equal(t, 10*time.Second, config.RequestTimeout)
+	assert.Equal(t, 1, config.RequestLogSampleRate)
 
 	// Database settings
 	assert.Equal(t, "localhost", config.DatabaseHost)
@@ -46,6 +53,10 @@ func TestLoadConfig(t *testing.T) {
 
 	// Logging
 	assert.Equal(t, "info", config.LogLevel)
+
+	// Health check dependencies
+	assert.True(t, config.HealthCheckDataService)
+	assert.Equal(t, "http://localhost:8086/health", config.DataServiceHealthURL)
 }
 
 // TestLoadConfigWithEnvironmentVariables tests configuration loading with environment variables
@@ -60,7 +71,10 @@ func TestLoadConfigWithEnvironmentVariables(t *testing.T) {
 		"SESSION_DEFAULT_EXPIRATION":     "45m",
 		"SESSION_REMEMBER_ME_EXPIRATION": "240h", // 10 days
 		"SESSION_CLEANUP_INTERVAL":       "15m",
+		"SESSION_IDLE_TIMEOUT":           "45m",
 		"SESSION_MAX_CONCURRENT":         "10",
+		"SESSION_CACHE_SIZE":             "50",
+		"SESSION_CACHE_TTL":              "1m",
 		// "SESSION_STORAGE_TYPE" removed - database storage is always used
 		"BCRYPT_COST":         "14",
 		"MAX_LOGIN_ATTEMPTS":  "3",
@@ -72,6 +86,9 @@ func TestLoadConfigWithEnvironmentVariables(t *testing.T) {
 		"DB_NAME":             "testdb",
 		"DB_SSLMODE":          "require",
 		"LOG_LEVEL":           "debug",
+
+		"HEALTH_CHECK_DATA_SERVICE": "false",
+		"DATA_SERVICE_HEALTH_URL":   "http://data-service:8086/health",
 	}
 
 	// Set environment variables and defer cleanup
@@ -91,7 +108,10 @@ func TestLoadConfigWithEnvironmentVariables(t *testing.T) {
 	assert.Equal(t, 45*time.Minute, config.SessionDefaultExpiration)
 	assert.Equal(t, 240*time.Hour, config.SessionRememberMeExpiration)
 	assert.Equal(t, 15*time.Minute, config.SessionCleanupInterval)
+	assert.Equal(t, 45*time.Minute, config.SessionIdleTimeout)
 	assert.Equal(t, 10, config.SessionMaxConcurrent)
+	assert.Equal(t, 50, config.SessionCacheSize)
+	assert.Equal(t, time.Minute, config.SessionCacheTTL)
 	// SessionStorageType removed - database storage is always used
 	assert.Equal(t, 14, config.BcryptCost)
 	assert.Equal(t, 3, config.MaxLoginAttempts)
@@ -103,6 +123,8 @@ func TestLoadConfigWithEnvironmentVariables(t *testing.T) {
 	assert.Equal(t, "testdb", config.DatabaseName)
 	assert.Equal(t, "require", config.DatabaseSSLMode)
 	assert.Equal(t, "debug", config.LogLevel)
+	assert.False(t, config.HealthCheckDataService)
+	assert.Equal(t, "http://data-service:8086/health", config.DataServiceHealthURL)
 }
 
 // TestToSessionConfig tests the conversion to session-specific config
@@ -112,7 +134,10 @@ func TestToSessionConfig(t *testing.T) {
 		SessionRememberMeExpiration: 72 * time.Hour,
 		JWTRefreshThreshold:         10 * time.Minute,
 		SessionCleanupInterval:      20 * time.Minute,
+		SessionIdleTimeout:          90 * time.Minute,
 		SessionMaxConcurrent:        8,
+		SessionCacheSize:            200,
+		SessionCacheTTL:             45 * time.Second,
 		// SessionStorageType removed - database storage is always used
 	}
 
@@ -122,7 +147,10 @@ func TestToSessionConfig(t *testing.T) {
 	assert.Equal(t, 72*time.Hour, sessionConfig.RememberMeExpiration)
 	assert.Equal(t, 10*time.Minute, sessionConfig.RefreshThreshold)
 	assert.Equal(t, 20*time.Minute, sessionConfig.CleanupInterval)
+	assert.Equal(t, 90*time.Minute, sessionConfig.IdleTimeout)
 	assert.Equal(t, 8, sessionConfig.MaxConcurrentSessions)
+	assert.Equal(t, 200, sessionConfig.CacheSize)
+	assert.Equal(t, 45*time.Second, sessionConfig.CacheTTL)
 	// StorageType removed - database storage is always used
 
 	// Verify it implements the SessionConfig interface correctly
@@ -450,3 +478,84 @@ func TestEdgeCases(t *testing.T) {
 		assert.Equal(t, expected, result)
 	})
 }
+
+// TestValidate tests that defaults pass and that invalid or missing settings are all reported together
+func TestValidate(t *testing.T) {
+	t.Run("defaults are valid", func(t *testing.T) {
+		assert.NoError(t, LoadConfig().Validate())
+	})
+
+	t.Run("invalid values are aggregated", func(t *testing.T) {
+		t.Setenv("SESSION_SERVER_PORT", "-1")
+		t.Setenv("DB_SSLMODE", "sometimes")
+		t.Setenv("BCRYPT_COST", "64")
+
+		_, err := Load()
+
+		var validationErr *configcheck.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, []string{
+			`SESSION_SERVER_PORT must be a port between 1 and 65535, got "-1"`,
+			`DB_SSLMODE must be one of disable, allow, prefer, require, verify-ca, verify-full, got "sometimes"`,
+			"BCRYPT_COST must be between 4 and 31, got 64",
+		}, validationErr.Problems)
+	})
+
+	t.Run("sample rate below one", func(t *testing.T) {
+		t.Setenv("REQUEST_LOG_SAMPLE_RATE", "0")
+
+		_, err := Load()
+
+		var validationErr *configcheck.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, []string{"REQUEST_LOG_SAMPLE_RATE must be at least 1, got 0"}, validationErr.Problems)
+	})
+
+	t.Run("cache TTL not below idle timeout", func(t *testing.T) {
+		t.Setenv("SESSION_CACHE_TTL", "2h")
+
+		_, err := Load()
+
+		var validationErr *configcheck.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, []string{"SESSION_CACHE_TTL must be less than SESSION_IDLE_TIMEOUT, got 2h0m0s and 2h0m0s"}, validationErr.Problems)
+	})
+
+	t.Run("cache TTL is unbounded without an idle timeout", func(t *testing.T) {
+		t.Setenv("SESSION_CACHE_TTL", "2h")
+		t.Setenv("SESSION_IDLE_TIMEOUT", "0")
+
+		_, err := Load()
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("missing required secret", func(t *testing.T) {
+		cfg := LoadConfig()
+		cfg.JWTSecret = ""
+		cfg.DatabasePassword = ""
+
+		var validationErr *configcheck.ValidationError
+		require.ErrorAs(t, cfg.Validate(), &validationErr)
+		assert.Equal(t, []string{"DB_PASSWORD is required", "JWT_SECRET is required"}, validationErr.Problems)
+	})
+
+	t.Run("short secret is rejected", func(t *testing.T) {
+		t.Setenv("JWT_SECRET", "test-secret-key")
+
+		_, err := Load()
+
+		var validationErr *configcheck.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, []string{"JWT_SECRET must be at least 32 characters, got 15"}, validationErr.Problems)
+	})
+
+	t.Run("minimum secret length is configurable", func(t *testing.T) {
+		t.Setenv("JWT_SECRET", "test-secret-key")
+		t.Setenv("JWT_MIN_SECRET_LENGTH", "12")
+
+		_, err := Load()
+
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,61 @@
+package config
+
+// RedactedValue replaces secrets in the sanitized configuration
+const RedactedValue = "[REDACTED]"
+
+// Sanitized returns the effective configuration keyed by the environment variable that sets
+// each value, with secrets redacted so it can be shown to operators. An unset secret stays
+// empty rather than redacted, so a missing secret is still visible
+func (c *Config) Sanitized() map[string]interface{} {
+	return map[string]interface{}{
+		// Server settings
+		"SESSION_SERVER_PORT": c.ServerPort,
+		"SESSION_SERVER_HOST": c.ServerHost,
+
+		// JWT settings
+		"JWT_SECRET":            redact(c.JWTSecret),
+		"JWT_EXPIRATION_TIME":   c.JWTExpirationTime.String(),
+		"JWT_REFRESH_THRESHOLD": c.JWTRefreshThreshold.String(),
+		"JWT_MIN_SECRET_LENGTH": c.JWTMinSecretLength,
+
+		// Session Management settings
+		"SESSION_DEFAULT_EXPIRATION":     c.SessionDefaultExpiration.String(),
+		"SESSION_REMEMBER_ME_EXPIRATION": c.SessionRememberMeExpiration.String(),
+		"SESSION_CLEANUP_INTERVAL":       c.SessionCleanupInterval.String(),
+		"SESSION_IDLE_TIMEOUT":           c.SessionIdleTimeout.String(),
+		"SESSION_MAX_CONCURRENT":         c.SessionMaxConcurrent,
+		"SESSION_CACHE_SIZE":             c.SessionCacheSize,
+		"SESSION_CACHE_TTL":              c.SessionCacheTTL.String(),
+
+		// Basic security settings
+		"BCRYPT_COST":         c.BcryptCost,
+		"MAX_LOGIN_ATTEMPTS":  c.MaxLoginAttempts,
+		"LOGIN_COOLDOWN_TIME": c.LoginCooldownTime.String(),
+
+		"REQUEST_TIMEOUT":         c.RequestTimeout.String(),
+		"REQUEST_LOG_SAMPLE_RATE": c.RequestLogSampleRate,
+
+		// Database settings
+		"DB_HOST":     c.DatabaseHost,
+		"DB_PORT":     c.DatabasePort,
+		"DB_USER":     c.DatabaseUser,
+		"DB_PASSWORD": redact(c.DatabasePassword),
+		"DB_NAME":     c.DatabaseName,
+		"DB_SSLMODE":  c.DatabaseSSLMode,
+
+		// Logging
+		"LOG_LEVEL": c.LogLevel,
+
+		// Health check dependencies
+		"HEALTH_CHECK_DATA_SERVICE": c.HealthCheckDataService,
+		"DATA_SERVICE_HEALTH_URL":   c.DataServiceHealthURL,
+	}
+}
+
+// redact hides a secret's value while keeping whether it is set
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return RedactedValue
+}
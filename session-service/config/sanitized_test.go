@@ -0,0 +1,39 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSanitized tests that secrets are redacted and every other setting is reported as loaded
+func TestSanitized(t *testing.T) {
+	cfg := LoadConfig()
+	cfg.DatabasePassword = "db-password-value"
+	cfg.JWTSecret = "jwt-secret-value"
+
+	settings := cfg.Sanitized()
+
+	assert.Equal(t, RedactedValue, settings["DB_PASSWORD"])
+	assert.Equal(t, RedactedValue, settings["JWT_SECRET"])
+	assert.Equal(t, "localhost", settings["DB_HOST"])
+	assert.Equal(t, 5432, settings["DB_PORT"])
+	assert.Equal(t, "8081", settings["SESSION_SERVER_PORT"])
+	assert.Equal(t, "30m0s", settings["JWT_EXPIRATION_TIME"])
+	assert.Equal(t, 12, settings["BCRYPT_COST"])
+
+	encoded, err := json.Marshal(settings)
+	require.NoError(t, err)
+	assert.NotContains(t, string(encoded), "db-password-value")
+	assert.NotContains(t, string(encoded), "jwt-secret-value")
+}
+
+// TestSanitizedUnsetSecret tests that an unset secret is reported empty rather than redacted
+func TestSanitizedUnsetSecret(t *testing.T) {
+	cfg := LoadConfig()
+	cfg.JWTSecret = ""
+
+	assert.Equal(t, "", cfg.Sanitized()["JWT_SECRET"])
+}
@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"net/http"
+
+	"shared/httpx"
+
+	"github.com/sirupsen/logrus"
+)
+
+// GetConfig returns the configuration the service loaded, with secrets redacted, to admin roles
+func (api *SessionAPI) GetConfig(w http.ResponseWriter, r *http.Request) {
+	session, ok := api.authenticatedSession(w, r)
+	if !ok {
+		return
+	}
+
+	if httpx.IsAdminRole(session.RoleName) {
+		api.writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+			"service": "session-service",
+			"config":  api.settings,
+		})
+		return
+	}
+
+	api.logger.WithFields(logrus.Fields{
+		"user_id":  session.UserID,
+		"username": session.Username,
+		"role":     session.RoleName,
+	}).Warn("Access denied: configuration requires an admin role")
+	api.writeErrorResponse(w, http.StatusForbidden, "insufficient_role", "An admin role is required")
+}
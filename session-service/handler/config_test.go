@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"session-service/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetConfig tests that only admin roles read the configuration and that its secrets stay redacted
+func TestGetConfig(t *testing.T) {
+	testCases := map[string]struct {
+		role           string
+		noToken        bool
+		expectedStatus int
+		expectedCode   string
+	}{
+		"admin":         {role: "admin", expectedStatus: http.StatusOK},
+		"super admin":   {role: "super_admin", expectedStatus: http.StatusOK},
+		"cashier":       {role: "cashier", expectedStatus: http.StatusForbidden, expectedCode: "insufficient_role"},
+		"missing token": {role: "admin", noToken: true, expectedStatus: http.StatusUnauthorized, expectedCode: "missing_token"},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			api, _, storage, token := setupUserAdminAPI(t, nil)
+			storage.sessions["session-current"].RoleName = tc.role
+
+			cfg := config.LoadConfig()
+			cfg.JWTSecret = "jwt-secret-value"
+			cfg.DatabasePassword = "db-password-value"
+			api.SetConfig(cfg.Sanitized())
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/config", nil)
+			if !tc.noToken {
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+			rr := httptest.NewRecorder()
+
+			api.GetConfig(rr, req)
+
+			require.Equal(t, tc.expectedStatus, rr.Code)
+			assert.NotContains(t, rr.Body.String(), "jwt-secret-value")
+			assert.NotContains(t, rr.Body.String(), "db-password-value")
+			if tc.expectedCode != "" {
+				assert.Contains(t, rr.Body.String(), `"error":"`+tc.expectedCode+`"`)
+				return
+			}
+
+			var response struct {
+				Config map[string]interface{} `json:"config"`
+			}
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+			assert.Equal(t, config.RedactedValue, response.Config["JWT_SECRET"])
+			assert.Equal(t, config.RedactedValue, response.Config["DB_PASSWORD"])
+			assert.Equal(t, "localhost", response.Config["DB_HOST"])
+		})
+	}
+}
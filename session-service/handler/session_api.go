@@ -5,11 +5,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"session-service/models"
 	"session-service/utils"
+	"shared/httpx"
+	"shared/version"
 
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
@@ -22,6 +25,16 @@ type SessionAPI struct {
 	logger         *logrus.Logger
 	jwtManager     *utils.JWTManager
 	db             *sql.DB
+
+	// dataServiceHealthURL is checked by HealthCheck when set
+	dataServiceHealthURL string
+	httpClient           *http.Client
+
+	// passwordManager, when set, upgrades under-cost password hashes on login
+	passwordManager *utils.PasswordManager
+
+	// settings is the sanitized configuration served by GetConfig
+	settings map[string]interface{}
 }
 
 // NewSessionAPI creates a new session API handler
@@ -31,14 +44,30 @@ func NewSessionAPI(sessionManager *utils.SessionManager, jwtManager *utils.JWTMa
 		logger:         logger,
 		jwtManager:     jwtManager,
 		db:             db,
+		httpClient:     &http.Client{Timeout: 5 * time.Second},
 	}
 }
 
+// SetDataServiceHealthURL makes HealthCheck depend on the data-service health endpoint; an empty URL disables the check
+func (api *SessionAPI) SetDataServiceHealthURL(url string) {
+	api.dataServiceHealthURL = url
+}
+
+// SetPasswordManager enables rehashing passwords stored below the manager's bcrypt cost on successful login
+func (api *SessionAPI) SetPasswordManager(passwordManager *utils.PasswordManager) {
+	api.passwordManager = passwordManager
+}
+
+// SetConfig sets the configuration GetConfig serves; callers pass config.Config.Sanitized() so no secret is kept
+func (api *SessionAPI) SetConfig(settings map[string]interface{}) {
+	api.settings = settings
+}
+
 // CreateSession creates a new session (called by gateway during login)
 func (api *SessionAPI) CreateSession(w http.ResponseWriter, r *http.Request) {
 	var req models.SessionCreateRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		api.writeErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid request format")
+	if err := httpx.DecodeJSONBody(r, &req); err != nil {
+		api.writeErrorResponse(w, http.StatusBadRequest, "invalid_request", httpx.DecodeErrorMessage(err, "Invalid request format"))
 		return
 	}
 
@@ -80,8 +109,8 @@ func (api *SessionAPI) CreateSession(w http.ResponseWriter, r *http.Request) {
 // ValidateSession validates a session token
 func (api *SessionAPI) ValidateSession(w http.ResponseWriter, r *http.Request) {
 	var req models.SessionValidationRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		api.writeErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid request format")
+	if err := httpx.DecodeJSONBody(r, &req); err != nil {
+		api.writeErrorResponse(w, http.StatusBadRequest, "invalid_request", httpx.DecodeErrorMessage(err, "Invalid request format"))
 		return
 	}
 
@@ -128,7 +157,8 @@ func (api *SessionAPI) RefreshSession(w http.ResponseWriter, r *http.Request) {
 	api.sessionHandler.RefreshSession(w, r)
 }
 
-// GetUserSessions returns all sessions for a user
+// GetUserSessions returns a page of a user's sessions, newest first
+// Query parameters: limit (default 20, capped at 100), offset, and active_only (default true)
 func (api *SessionAPI) GetUserSessions(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	userID := vars["userID"]
@@ -138,10 +168,16 @@ func (api *SessionAPI) GetUserSessions(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	opts, err := parseSessionListOptions(r)
+	if err != nil {
+		api.writeErrorResponse(w, http.StatusBadRequest, "invalid_query", err.Error())
+		return
+	}
+
 	// Get current session ID from token if provided
 	currentSessionID := api.getCurrentSessionIDFromToken(r)
 
-	sessions, err := api.sessionHandler.sessionManager.GetUserSessions(userID, currentSessionID)
+	sessions, total, err := api.sessionHandler.sessionManager.ListUserSessions(userID, currentSessionID, opts)
 	if err != nil {
 		api.logger.WithError(err).Error("Failed to get user sessions")
 		api.writeErrorResponse(w, http.StatusInternalServerError, "fetch_error", "Failed to retrieve sessions")
@@ -149,15 +185,57 @@ func (api *SessionAPI) GetUserSessions(w http.ResponseWriter, r *http.Request) {
 	}
 
 	response := map[string]interface{}{
-		"success":  true,
-		"user_id":  userID,
-		"sessions": sessions,
-		"count":    len(sessions),
+		"success":     true,
+		"user_id":     userID,
+		"sessions":    sessions,
+		"count":       len(sessions),
+		"total":       total,
+		"limit":       opts.Limit,
+		"offset":      opts.Offset,
+		"active_only": opts.ActiveOnly,
 	}
 
 	api.writeJSONResponse(w, http.StatusOK, response)
 }
 
+// parseSessionListOptions reads the paging and filter parameters, clamping limit to MaxSessionListLimit
+func parseSessionListOptions(r *http.Request) (models.SessionListOptions, error) {
+	query := r.URL.Query()
+	opts := models.SessionListOptions{
+		Limit:      models.DefaultSessionListLimit,
+		ActiveOnly: true,
+	}
+
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			return opts, fmt.Errorf("limit must be a positive integer")
+		}
+		if limit > models.MaxSessionListLimit {
+			limit = models.MaxSessionListLimit
+		}
+		opts.Limit = limit
+	}
+
+	if raw := query.Get("offset"); raw != "" {
+		offset, err := strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			return opts, fmt.Errorf("offset must be a non-negative integer")
+		}
+		opts.Offset = offset
+	}
+
+	if raw := query.Get("active_only"); raw != "" {
+		activeOnly, err := strconv.ParseBool(raw)
+		if err != nil {
+			return opts, fmt.Errorf("active_only must be true or false")
+		}
+		opts.ActiveOnly = activeOnly
+	}
+
+	return opts, nil
+}
+
 // RevokeSession revokes a specific session
 func (api *SessionAPI) RevokeSession(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -252,8 +330,8 @@ func (api *SessionAPI) RevokeSessionByToken(w http.ResponseWriter, r *http.Reque
 		Token string `json:"token"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		api.writeErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid request format")
+	if err := httpx.DecodeJSONBody(r, &req); err != nil {
+		api.writeErrorResponse(w, http.StatusBadRequest, "invalid_request", httpx.DecodeErrorMessage(err, "Invalid request format"))
 		return
 	}
 
@@ -287,7 +365,12 @@ func (api *SessionAPI) RevokeSessionByToken(w http.ResponseWriter, r *http.Reque
 
 // GetSessionStats returns session statistics
 func (api *SessionAPI) GetSessionStats(w http.ResponseWriter, r *http.Request) {
-	stats := api.sessionHandler.sessionManager.GetSessionStats()
+	stats, err := api.sessionHandler.sessionManager.GetSessionStats()
+	if err != nil {
+		api.logger.WithError(err).Error("Failed to get session stats")
+		api.writeErrorResponse(w, http.StatusInternalServerError, "fetch_error", "Failed to retrieve session statistics")
+		return
+	}
 
 	response := map[string]interface{}{
 		"success": true,
@@ -297,50 +380,79 @@ func (api *SessionAPI) GetSessionStats(w http.ResponseWriter, r *http.Request) {
 	api.writeJSONResponse(w, http.StatusOK, response)
 }
 
+// GetMetrics returns JWT operation counters and the number of handler panics recovered
+func (api *SessionAPI) GetMetrics(w http.ResponseWriter, r *http.Request) {
+	response := map[string]interface{}{
+		"success": true,
+		"jwt":     api.jwtManager.Metrics(),
+		"panics":  httpx.PanicCount(),
+	}
+
+	api.writeJSONResponse(w, http.StatusOK, response)
+}
+
 // HealthCheck returns the health status of the session service
 func (api *SessionAPI) HealthCheck(w http.ResponseWriter, r *http.Request) {
-	// Check data-service health (which checks database connectivity)
-	client := &http.Client{
-		Timeout: 5 * time.Second,
-	}
+	checks := map[string]string{}
 
-	resp, err := client.Get("http://localhost:8086/health")
-	if err != nil {
-		api.logger.WithError(err).Error("Failed to connect to data-service during health check")
-		response := map[string]interface{}{
+	if err := api.db.Ping(); err != nil {
+		api.logger.WithError(err).Error("Database ping failed during health check")
+		checks["database"] = "unhealthy"
+		api.writeJSONResponse(w, http.StatusServiceUnavailable, map[string]interface{}{
 			"success": false,
 			"service": "session-service",
 			"status":  "unhealthy",
-			"message": "Data service connection failed",
+			"message": "Database connection failed",
 			"error":   err.Error(),
-		}
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusServiceUnavailable)
-		json.NewEncoder(w).Encode(response)
+			"checks":  checks,
+		})
 		return
 	}
-	defer resp.Body.Close()
+	checks["database"] = "healthy"
 
-	if resp.StatusCode != http.StatusOK {
-		api.logger.WithField("status_code", resp.StatusCode).Error("Data service health check failed")
-		response := map[string]interface{}{
+	if err := api.jwtManager.SelfCheck(); err != nil {
+		api.logger.WithError(err).Error("JWT self check failed during health check")
+		checks["jwt"] = "unhealthy"
+		api.writeJSONResponse(w, http.StatusServiceUnavailable, map[string]interface{}{
 			"success": false,
 			"service": "session-service",
 			"status":  "unhealthy",
-			"message": "Data service is unhealthy",
-			"error":   fmt.Sprintf("Data service returned status %d", resp.StatusCode),
-		}
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusServiceUnavailable)
-		json.NewEncoder(w).Encode(response)
+			"message": "JWT signing is not working",
+			"error":   err.Error(),
+			"checks":  checks,
+		})
 		return
 	}
+	checks["jwt"] = "healthy"
+
+	dependencies := map[string]string{}
+	if api.dataServiceHealthURL != "" {
+		if err := httpx.CheckHealth(api.httpClient, api.dataServiceHealthURL); err != nil {
+			api.logger.WithError(err).Error("Data service health check failed")
+			dependencies["data-service"] = "unhealthy"
+			api.writeJSONResponse(w, http.StatusServiceUnavailable, map[string]interface{}{
+				"success":      false,
+				"service":      "session-service",
+				"status":       "unhealthy",
+				"message":      "Data service is unhealthy",
+				"error":        err.Error(),
+				"checks":       checks,
+				"dependencies": dependencies,
+			})
+			return
+		}
+		dependencies["data-service"] = "healthy"
+	}
 
 	response := map[string]interface{}{
-		"success": true,
-		"service": "session-service",
-		"status":  "healthy",
-		"message": "Session service is operational",
+		"success":      true,
+		"service":      "session-service",
+		"status":       "healthy",
+		"message":      "Session service is operational",
+		"version":      version.Version,
+		"build":        version.Info(),
+		"checks":       checks,
+		"dependencies": dependencies,
 	}
 
 	api.writeJSONResponse(w, http.StatusOK, response)
@@ -433,6 +545,11 @@ func (api *SessionAPI) authenticateUser(username, password string) (*models.User
 		return nil, nil // Invalid password
 	}
 
+	// The plain password is only available here, so upgrade old hashes while we have it
+	if api.passwordManager != nil && api.passwordManager.NeedsRehash(passwordHash) {
+		api.upgradePasswordHash(user.ID, password)
+	}
+
 	// Get user permissions
 	permQuery := `
 		SELECT permission_name, description
@@ -463,6 +580,149 @@ func (api *SessionAPI) authenticateUser(username, password string) (*models.User
 	}, nil
 }
 
+// upgradePasswordHash rehashes a password at the configured cost; failures are logged and never block the login
+func (api *SessionAPI) upgradePasswordHash(userID, password string) {
+	newHash, err := api.passwordManager.HashPassword(password)
+	if err != nil {
+		api.logger.WithError(err).WithField("user_id", userID).Warn("Failed to rehash password")
+		return
+	}
+
+	query := `UPDATE users SET password_hash = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`
+	if _, err := api.db.Exec(query, newHash, userID); err != nil {
+		api.logger.WithError(err).WithField("user_id", userID).Warn("Failed to store rehashed password")
+		return
+	}
+
+	api.logger.WithField("user_id", userID).Info("Upgraded password hash to the configured bcrypt cost")
+}
+
+// passwords returns the configured password manager, falling back to bcrypt's default cost
+func (api *SessionAPI) passwords() *utils.PasswordManager {
+	if api.passwordManager != nil {
+		return api.passwordManager
+	}
+	return utils.NewPasswordManager(bcrypt.DefaultCost, api.logger)
+}
+
+// ChangePassword replaces the signed-in user's password and revokes their other sessions
+func (api *SessionAPI) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	session, ok := api.authenticatedSession(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.ChangePasswordRequest
+	if err := httpx.DecodeJSONBody(r, &req); err != nil {
+		api.writeErrorResponse(w, http.StatusBadRequest, "invalid_request", httpx.DecodeErrorMessage(err, "Invalid request format"))
+		return
+	}
+
+	if req.OldPassword == "" || req.NewPassword == "" {
+		api.writeErrorResponse(w, http.StatusBadRequest, "missing_fields", "Old and new passwords are required")
+		return
+	}
+
+	var passwordHash string
+	err := api.db.QueryRow(`SELECT password_hash FROM users WHERE id = $1 AND is_active = true`, session.UserID).Scan(&passwordHash)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			api.writeErrorResponse(w, http.StatusUnauthorized, "invalid_credentials", "Current password is incorrect")
+			return
+		}
+		api.logger.WithError(err).Error("Failed to load user for password change")
+		api.writeErrorResponse(w, http.StatusInternalServerError, "password_change_failed", "Failed to change password")
+		return
+	}
+
+	passwordManager := api.passwords()
+	if err := passwordManager.ValidatePassword(req.OldPassword, passwordHash); err != nil {
+		api.logger.WithField("user_id", session.UserID).Warn("Password change rejected: incorrect current password")
+		api.writeErrorResponse(w, http.StatusUnauthorized, "invalid_credentials", "Current password is incorrect")
+		return
+	}
+
+	if err := utils.ValidatePasswordStrength(req.NewPassword); err != nil {
+		api.writeErrorResponse(w, http.StatusUnprocessableEntity, "weak_password", err.Error())
+		return
+	}
+
+	if req.NewPassword == req.OldPassword {
+		api.writeErrorResponse(w, http.StatusUnprocessableEntity, "password_unchanged", "New password must differ from the current password")
+		return
+	}
+
+	newHash, err := passwordManager.HashPassword(req.NewPassword)
+	if err != nil {
+		api.writeErrorResponse(w, http.StatusInternalServerError, "password_change_failed", "Failed to change password")
+		return
+	}
+
+	query := `UPDATE users SET password_hash = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`
+	if _, err := api.db.Exec(query, newHash, session.UserID); err != nil {
+		api.logger.WithError(err).Error("Failed to store new password hash")
+		api.writeErrorResponse(w, http.StatusInternalServerError, "password_change_failed", "Failed to change password")
+		return
+	}
+
+	// Anyone holding another of the user's sessions may have known the old password
+	revokedCount := api.revokeOtherSessions(session.UserID, session.SessionID)
+
+	api.logger.WithFields(logrus.Fields{
+		"user_id":       session.UserID,
+		"revoked_count": revokedCount,
+	}).Info("Password changed via API")
+
+	api.writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"success":          true,
+		"message":          "Password changed successfully",
+		"revoked_sessions": revokedCount,
+	})
+}
+
+// authenticatedSession validates the request's bearer token and writes a 401 when it is missing or invalid
+func (api *SessionAPI) authenticatedSession(w http.ResponseWriter, r *http.Request) (*models.SessionData, bool) {
+	token := api.extractTokenFromHeader(r)
+	if token == "" {
+		api.writeErrorResponse(w, http.StatusUnauthorized, "missing_token", "Authorization token is required")
+		return nil, false
+	}
+
+	validation, err := api.sessionHandler.sessionManager.ValidateSession(&models.SessionValidationRequest{Token: token})
+	if err != nil || !validation.IsValid || validation.SessionData == nil {
+		api.writeErrorResponse(w, http.StatusUnauthorized, "invalid_session", "Session is not valid")
+		return nil, false
+	}
+
+	return validation.SessionData, true
+}
+
+// revokeOtherSessions revokes every active session of a user except keepSessionID and returns how many were revoked
+func (api *SessionAPI) revokeOtherSessions(userID, keepSessionID string) int {
+	sessions, err := api.sessionHandler.sessionManager.GetUserSessions(userID, keepSessionID)
+	if err != nil {
+		api.logger.WithError(err).Warn("Failed to get user sessions for revocation")
+		return 0
+	}
+
+	revokedCount := 0
+	for _, session := range sessions {
+		if session.SessionID == keepSessionID {
+			continue
+		}
+		err := api.sessionHandler.sessionManager.RevokeSession(&models.SessionRevokeRequest{
+			SessionID: session.SessionID,
+		})
+		if err != nil {
+			api.logger.WithError(err).Warn("Failed to revoke user session")
+			continue
+		}
+		revokedCount++
+	}
+
+	return revokedCount
+}
+
 // Login handles user authentication (database-backed implementation)
 func (api *SessionAPI) Login(w http.ResponseWriter, r *http.Request) {
 	var req struct {
@@ -470,8 +730,8 @@ func (api *SessionAPI) Login(w http.ResponseWriter, r *http.Request) {
 		Password string `json:"password"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		api.writeErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid request format")
+	if err := httpx.DecodeJSONBody(r, &req); err != nil {
+		api.writeErrorResponse(w, http.StatusBadRequest, "invalid_request", httpx.DecodeErrorMessage(err, "Invalid request format"))
 		return
 	}
 
@@ -0,0 +1,496 @@
+package handler
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"session-service/models"
+	"session-service/utils"
+	"shared/httpx"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// setupTestSessionAPI creates a session API backed by a ping-monitoring sqlmock
+func setupTestSessionAPI(t *testing.T) (*SessionAPI, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	require.NoError(t, err)
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	jwtManager := utils.NewJWTManager("test-secret", time.Hour, logger)
+	return NewSessionAPI(nil, jwtManager, db, logger), mock, func() { db.Close() }
+}
+
+// TestHealthCheck tests the database ping and the optional data-service dependency
+func TestHealthCheck(t *testing.T) {
+	healthyDataService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer healthyDataService.Close()
+
+	unreachableDataService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	unreachableURL := unreachableDataService.URL
+	unreachableDataService.Close()
+
+	testCases := map[string]struct {
+		dataServiceURL       string
+		pingErr              error
+		expectedStatus       int
+		expectedHealth       string
+		expectedDependencies map[string]interface{}
+		expectedChecks       map[string]interface{}
+	}{
+		"healthy without data-service check": {
+			expectedStatus:       http.StatusOK,
+			expectedHealth:       "healthy",
+			expectedDependencies: map[string]interface{}{},
+			expectedChecks:       map[string]interface{}{"database": "healthy", "jwt": "healthy"},
+		},
+		"healthy data-service": {
+			dataServiceURL:       healthyDataService.URL,
+			expectedStatus:       http.StatusOK,
+			expectedHealth:       "healthy",
+			expectedDependencies: map[string]interface{}{"data-service": "healthy"},
+		},
+		"unreachable data-service": {
+			dataServiceURL:       unreachableURL,
+			expectedStatus:       http.StatusServiceUnavailable,
+			expectedHealth:       "unhealthy",
+			expectedDependencies: map[string]interface{}{"data-service": "unhealthy"},
+		},
+		"database ping failure": {
+			pingErr:        errors.New("connection refused"),
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedHealth: "unhealthy",
+			expectedChecks: map[string]interface{}{"database": "unhealthy"},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			api, mock, cleanup := setupTestSessionAPI(t)
+			defer cleanup()
+
+			api.SetDataServiceHealthURL(tc.dataServiceURL)
+			mock.ExpectPing().WillReturnError(tc.pingErr)
+
+			rr := httptest.NewRecorder()
+			api.HealthCheck(rr, httptest.NewRequest(http.MethodGet, "/api/v1/sessions/health", nil))
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+
+			var body map[string]interface{}
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+			assert.Equal(t, tc.expectedHealth, body["status"])
+			if tc.expectedDependencies != nil {
+				assert.Equal(t, tc.expectedDependencies, body["dependencies"])
+			}
+			if tc.expectedChecks != nil {
+				assert.Equal(t, tc.expectedChecks, body["checks"])
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// TestHealthCheckBrokenJWTSigner tests that a manager unable to sign tokens makes the service unhealthy
+func TestHealthCheckBrokenJWTSigner(t *testing.T) {
+	api, mock, cleanup := setupTestSessionAPI(t)
+	defer cleanup()
+
+	api.jwtManager = utils.NewJWTManager("", time.Hour, api.logger)
+	mock.ExpectPing()
+
+	rr := httptest.NewRecorder()
+	api.HealthCheck(rr, httptest.NewRequest(http.MethodGet, "/api/v1/sessions/health", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	assert.Equal(t, "unhealthy", body["status"])
+	assert.Equal(t, map[string]interface{}{"database": "healthy", "jwt": "unhealthy"}, body["checks"])
+	assert.Equal(t, utils.ErrJWTSecretMissing.Error(), body["error"])
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// bcryptHashArg matches a bcrypt hash of password made at cost
+type bcryptHashArg struct {
+	password string
+	cost     int
+}
+
+func (a bcryptHashArg) Match(v driver.Value) bool {
+	hash, ok := v.(string)
+	if !ok {
+		return false
+	}
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err == nil && cost == a.cost && bcrypt.CompareHashAndPassword([]byte(hash), []byte(a.password)) == nil
+}
+
+// TestGetMetrics tests that the metrics response carries the JWT counters and the recovered panic count
+func TestGetMetrics(t *testing.T) {
+	api, _, cleanup := setupTestSessionAPI(t)
+	defer cleanup()
+
+	rr := httptest.NewRecorder()
+	api.GetMetrics(rr, httptest.NewRequest(http.MethodGet, "/api/v1/sessions/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	assert.Equal(t, true, body["success"])
+	assert.Contains(t, body, "jwt")
+	assert.Equal(t, float64(httpx.PanicCount()), body["panics"])
+}
+
+// TestAuthenticateUserUpgradesPasswordHash tests that a login rehashes passwords stored below the configured cost
+func TestAuthenticateUserUpgradesPasswordHash(t *testing.T) {
+	const configuredCost = bcrypt.MinCost + 1
+
+	testCases := map[string]struct {
+		storedCost   int
+		expectRehash bool
+	}{
+		"under-cost hash is rehashed and stored": {
+			storedCost:   bcrypt.MinCost,
+			expectRehash: true,
+		},
+		"hash at configured cost is left alone": {
+			storedCost: configuredCost,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			api, mock, cleanup := setupTestSessionAPI(t)
+			defer cleanup()
+			api.SetPasswordManager(utils.NewPasswordManager(configuredCost, api.logger))
+
+			storedHash, err := bcrypt.GenerateFromPassword([]byte("secret123"), tc.storedCost)
+			require.NoError(t, err)
+
+			mock.ExpectQuery("SELECT u.id, u.username, u.password_hash").
+				WithArgs("admin").
+				WillReturnRows(sqlmock.NewRows([]string{"id", "username", "password_hash", "full_name", "role_id", "is_active", "role_id", "role_name"}).
+					AddRow("user-1", "admin", string(storedHash), "Admin User", "role-1", true, "role-1", "admin"))
+
+			if tc.expectRehash {
+				mock.ExpectExec("UPDATE users SET password_hash").
+					WithArgs(bcryptHashArg{password: "secret123", cost: configuredCost}, "user-1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+
+			mock.ExpectQuery("SELECT permission_name, description").
+				WithArgs("role-1").
+				WillReturnRows(sqlmock.NewRows([]string{"permission_name", "description"}))
+
+			profile, err := api.authenticateUser("admin", "secret123")
+			require.NoError(t, err)
+			require.NotNil(t, profile)
+			assert.Equal(t, "user-1", profile.User.ID)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// memorySessionStorage is an in-memory SessionStorage for handler tests
+type memorySessionStorage struct {
+	mutex    sync.Mutex
+	sessions map[string]*models.SessionData
+}
+
+func (s *memorySessionStorage) Store(sessionID string, session *models.SessionData) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	copied := *session
+	s.sessions[sessionID] = &copied
+	return nil
+}
+
+func (s *memorySessionStorage) Get(sessionID string) (*models.SessionData, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	session, ok := s.sessions[sessionID]
+	if !ok {
+		return nil, fmt.Errorf("session not found")
+	}
+	copied := *session
+	return &copied, nil
+}
+
+func (s *memorySessionStorage) GetByTokenHash(tokenHash string) (*models.SessionData, error) {
+	return nil, fmt.Errorf("session not found")
+}
+
+func (s *memorySessionStorage) GetUserSessions(userID string) ([]*models.SessionData, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var sessions []*models.SessionData
+	for _, session := range s.sessions {
+		if session.UserID == userID {
+			copied := *session
+			sessions = append(sessions, &copied)
+		}
+	}
+	return sessions, nil
+}
+
+func (s *memorySessionStorage) Update(sessionID string, session *models.SessionData) error {
+	return s.Store(sessionID, session)
+}
+
+func (s *memorySessionStorage) Delete(sessionID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.sessions, sessionID)
+	return nil
+}
+
+func (s *memorySessionStorage) DeleteUserSessions(userID string) error {
+	return nil
+}
+
+func (s *memorySessionStorage) GetAllSessions() ([]*models.SessionData, error) {
+	return nil, nil
+}
+
+func (s *memorySessionStorage) Cleanup() error {
+	return nil
+}
+
+// setupChangePasswordAPI creates a session API whose user-1 is signed in on session-current and session-other
+func setupChangePasswordAPI(t *testing.T) (*SessionAPI, sqlmock.Sqlmock, *memorySessionStorage, string) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	config := models.DefaultSessionConfig()
+	config.CleanupInterval = time.Hour
+
+	storage := &memorySessionStorage{sessions: make(map[string]*models.SessionData)}
+	jwtManager := utils.NewJWTManager("test-secret-key", 30*time.Minute, logger)
+	sessionManager := utils.NewSessionManager(jwtManager, config, storage, logger)
+
+	profile := &models.UserProfile{
+		User: models.User{ID: "user-1", Username: "admin"},
+		Role: models.Role{ID: "role-1", RoleName: "admin"},
+	}
+	token, expiresAt, err := jwtManager.GenerateToken(profile, "session-current")
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+	for _, sessionID := range []string{"session-current", "session-other"} {
+		require.NoError(t, storage.Store(sessionID, &models.SessionData{
+			SessionID:    sessionID,
+			UserID:       "user-1",
+			Username:     "admin",
+			CreatedAt:    now,
+			ExpiresAt:    expiresAt,
+			LastActivity: now,
+			IsActive:     true,
+		}))
+	}
+
+	api := NewSessionAPI(sessionManager, jwtManager, db, logger)
+	api.SetPasswordManager(utils.NewPasswordManager(bcrypt.MinCost, logger))
+
+	return api, mock, storage, token
+}
+
+// TestChangePassword tests old password verification, the strength policy, and revocation of other sessions
+func TestChangePassword(t *testing.T) {
+	storedHash, err := bcrypt.GenerateFromPassword([]byte("oldPassw0rd"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	testCases := map[string]struct {
+		authorized     bool
+		body           string
+		expectUpdate   bool
+		expectedStatus int
+		expectedCode   string
+	}{
+		"success": {
+			authorized:     true,
+			body:           `{"old_password":"oldPassw0rd","new_password":"newPassw0rd"}`,
+			expectUpdate:   true,
+			expectedStatus: http.StatusOK,
+		},
+		"wrong old password": {
+			authorized:     true,
+			body:           `{"old_password":"notMyPassw0rd","new_password":"newPassw0rd"}`,
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "invalid_credentials",
+		},
+		"weak new password": {
+			authorized:     true,
+			body:           `{"old_password":"oldPassw0rd","new_password":"short"}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   "weak_password",
+		},
+		"missing token": {
+			body:           `{"old_password":"oldPassw0rd","new_password":"newPassw0rd"}`,
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "missing_token",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			api, mock, storage, token := setupChangePasswordAPI(t)
+
+			if tc.authorized {
+				mock.ExpectQuery("SELECT password_hash FROM users").
+					WithArgs("user-1").
+					WillReturnRows(sqlmock.NewRows([]string{"password_hash"}).AddRow(string(storedHash)))
+			}
+			if tc.expectUpdate {
+				mock.ExpectExec("UPDATE users SET password_hash").
+					WithArgs(bcryptHashArg{password: "newPassw0rd", cost: bcrypt.MinCost}, "user-1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/change-password", bytes.NewBufferString(tc.body))
+			if tc.authorized {
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+			rr := httptest.NewRecorder()
+
+			api.ChangePassword(rr, req)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+			assert.NoError(t, mock.ExpectationsWereMet())
+
+			if tc.expectedCode != "" {
+				var response models.ErrorResponse
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+				assert.Equal(t, tc.expectedCode, response.Error)
+			}
+
+			_, otherErr := storage.Get("session-other")
+			_, currentErr := storage.Get("session-current")
+			assert.NoError(t, currentErr)
+			if tc.expectUpdate {
+				assert.Error(t, otherErr, "other sessions should be revoked after a password change")
+			} else {
+				assert.NoError(t, otherErr)
+			}
+		})
+	}
+}
+
+// TestGetUserSessions tests the active_only filter, newest-first ordering, offset paging and limit clamping
+func TestGetUserSessions(t *testing.T) {
+	testCases := map[string]struct {
+		query          string
+		expectedStatus int
+		expectedCount  int
+		expectedTotal  int
+		expectedLimit  int
+		expectedFirst  string
+	}{
+		"defaults to active sessions": {
+			expectedStatus: http.StatusOK,
+			expectedCount:  models.DefaultSessionListLimit,
+			expectedTotal:  120,
+			expectedLimit:  models.DefaultSessionListLimit,
+			expectedFirst:  "session-119",
+		},
+		"includes inactive sessions": {
+			query:          "?active_only=false",
+			expectedStatus: http.StatusOK,
+			expectedCount:  models.DefaultSessionListLimit,
+			expectedTotal:  123,
+			expectedLimit:  models.DefaultSessionListLimit,
+			expectedFirst:  "revoked-2",
+		},
+		"clamps limit": {
+			query:          "?limit=500",
+			expectedStatus: http.StatusOK,
+			expectedCount:  models.MaxSessionListLimit,
+			expectedTotal:  120,
+			expectedLimit:  models.MaxSessionListLimit,
+			expectedFirst:  "session-119",
+		},
+		"offset past the first page": {
+			query:          "?limit=50&offset=110",
+			expectedStatus: http.StatusOK,
+			expectedCount:  10,
+			expectedTotal:  120,
+			expectedLimit:  50,
+			expectedFirst:  "session-9",
+		},
+		"invalid limit": {
+			query:          "?limit=abc",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			api, _, storage, _ := setupChangePasswordAPI(t)
+
+			base := time.Now().UTC().Add(-time.Hour)
+			for i := 0; i < 120; i++ {
+				require.NoError(t, storage.Store(fmt.Sprintf("session-%d", i), &models.SessionData{
+					SessionID: fmt.Sprintf("session-%d", i),
+					UserID:    "user-2",
+					CreatedAt: base.Add(time.Duration(i) * time.Second),
+					IsActive:  true,
+				}))
+			}
+			for i := 0; i < 3; i++ {
+				require.NoError(t, storage.Store(fmt.Sprintf("revoked-%d", i), &models.SessionData{
+					SessionID: fmt.Sprintf("revoked-%d", i),
+					UserID:    "user-2",
+					CreatedAt: base.Add(time.Duration(200+i) * time.Second),
+					IsActive:  false,
+				}))
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/sessions/user/user-2"+tc.query, nil)
+			req = mux.SetURLVars(req, map[string]string{"userID": "user-2"})
+			rr := httptest.NewRecorder()
+
+			api.GetUserSessions(rr, req)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+			if tc.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var response struct {
+				Sessions []models.SessionSummary `json:"sessions"`
+				Count    int                     `json:"count"`
+				Total    int                     `json:"total"`
+				Limit    int                     `json:"limit"`
+			}
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+			assert.Equal(t, tc.expectedCount, response.Count)
+			assert.Len(t, response.Sessions, tc.expectedCount)
+			assert.Equal(t, tc.expectedTotal, response.Total)
+			assert.Equal(t, tc.expectedLimit, response.Limit)
+			assert.Equal(t, tc.expectedFirst, response.Sessions[0].SessionID)
+		})
+	}
+}
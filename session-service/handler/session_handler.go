@@ -8,6 +8,7 @@ import (
 
 	"session-service/models"
 	"session-service/utils"
+	"shared/httpx"
 
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
@@ -32,8 +33,8 @@ func NewSessionHandler(sessionManager *utils.SessionManager, jwtManager *utils.J
 // ValidateSessionToken validates a token against the session store
 func (h *SessionHandler) ValidateSessionToken(w http.ResponseWriter, r *http.Request) {
 	var req models.SessionValidationRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid request format")
+	if err := httpx.DecodeJSONBody(r, &req); err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, "invalid_request", httpx.DecodeErrorMessage(err, "Invalid request format"))
 		return
 	}
 
@@ -74,8 +75,8 @@ func (h *SessionHandler) GetUserSessions(w http.ResponseWriter, r *http.Request)
 // RevokeSession revokes a specific session
 func (h *SessionHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
 	var req models.SessionRevokeRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid request format")
+	if err := httpx.DecodeJSONBody(r, &req); err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, "invalid_request", httpx.DecodeErrorMessage(err, "Invalid request format"))
 		return
 	}
 
@@ -133,7 +134,12 @@ func (h *SessionHandler) RevokeAllUserSessions(w http.ResponseWriter, r *http.Re
 
 // GetSessionStats returns session analytics
 func (h *SessionHandler) GetSessionStats(w http.ResponseWriter, r *http.Request) {
-	stats := h.sessionManager.GetSessionStats()
+	stats, err := h.sessionManager.GetSessionStats()
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get session stats")
+		h.writeErrorResponse(w, http.StatusInternalServerError, "fetch_error", "Failed to retrieve session statistics")
+		return
+	}
 	h.writeJSONResponse(w, http.StatusOK, stats)
 }
 
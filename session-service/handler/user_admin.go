@@ -0,0 +1,255 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"session-service/models"
+	"session-service/utils"
+	"shared/httpx"
+
+	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+// UserAdminPermission is the permission required to create and modify user accounts
+const UserAdminPermission = "auth-write"
+
+// uniqueViolation is the PostgreSQL error code for a unique constraint violation
+const uniqueViolation = "23505"
+
+// isUniqueViolation reports whether err was caused by a unique constraint
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == uniqueViolation
+}
+
+// CreateUser creates a user account with a hashed password and an assigned role
+func (api *SessionAPI) CreateUser(w http.ResponseWriter, r *http.Request) {
+	session, ok := api.requirePermission(w, r, UserAdminPermission)
+	if !ok {
+		return
+	}
+
+	var req models.CreateUserRequest
+	if err := httpx.DecodeJSONBody(r, &req); err != nil {
+		api.writeErrorResponse(w, http.StatusBadRequest, "invalid_request", httpx.DecodeErrorMessage(err, "Invalid request format"))
+		return
+	}
+
+	req.Username = strings.TrimSpace(req.Username)
+	req.FullName = strings.TrimSpace(req.FullName)
+	if req.Username == "" || req.Password == "" || req.FullName == "" || req.RoleID == "" {
+		api.writeErrorResponse(w, http.StatusBadRequest, "missing_fields", "Username, password, full name and role are required")
+		return
+	}
+
+	if len(req.Username) < 3 || len(req.Username) > 50 {
+		api.writeErrorResponse(w, http.StatusBadRequest, "invalid_username", "Username must be between 3 and 50 characters")
+		return
+	}
+
+	if err := utils.ValidatePasswordStrength(req.Password); err != nil {
+		api.writeErrorResponse(w, http.StatusUnprocessableEntity, "weak_password", err.Error())
+		return
+	}
+
+	if !api.checkRoleExists(w, req.RoleID) {
+		return
+	}
+
+	var taken bool
+	if err := api.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM users WHERE username = $1)`, req.Username).Scan(&taken); err != nil {
+		api.logger.WithError(err).Error("Failed to check username availability")
+		api.writeErrorResponse(w, http.StatusInternalServerError, "user_create_failed", "Failed to create user")
+		return
+	}
+	if taken {
+		api.writeErrorResponse(w, http.StatusConflict, "username_taken", fmt.Sprintf("Username '%s' is already in use", req.Username))
+		return
+	}
+
+	passwordHash, err := api.passwords().HashPassword(req.Password)
+	if err != nil {
+		api.writeErrorResponse(w, http.StatusInternalServerError, "user_create_failed", "Failed to create user")
+		return
+	}
+
+	user := models.User{
+		Username: req.Username,
+		FullName: req.FullName,
+		RoleID:   req.RoleID,
+		IsActive: true,
+	}
+
+	query := `
+		INSERT INTO users (username, password_hash, full_name, role_id, is_active)
+		VALUES ($1, $2, $3, $4, true)
+		RETURNING id, created_at, updated_at
+	`
+	err = api.db.QueryRow(query, user.Username, passwordHash, user.FullName, user.RoleID).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		// A concurrent request can claim the username between the check and the insert
+		if isUniqueViolation(err) {
+			api.writeErrorResponse(w, http.StatusConflict, "username_taken", fmt.Sprintf("Username '%s' is already in use", req.Username))
+			return
+		}
+		api.logger.WithError(err).Error("Failed to create user")
+		api.writeErrorResponse(w, http.StatusInternalServerError, "user_create_failed", "Failed to create user")
+		return
+	}
+
+	api.logger.WithFields(logrus.Fields{
+		"user_id":    user.ID,
+		"username":   user.Username,
+		"role_id":    user.RoleID,
+		"created_by": session.UserID,
+	}).Info("User created via API")
+
+	api.writeJSONResponse(w, http.StatusCreated, user)
+}
+
+// UpdateUser deactivates, reactivates or changes the role of a user account
+func (api *SessionAPI) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	session, ok := api.requirePermission(w, r, UserAdminPermission)
+	if !ok {
+		return
+	}
+
+	userID := mux.Vars(r)["id"]
+	if userID == "" {
+		api.writeErrorResponse(w, http.StatusBadRequest, "missing_user_id", "User ID is required")
+		return
+	}
+
+	var req models.UpdateUserRequest
+	if err := httpx.DecodeJSONBody(r, &req); err != nil {
+		api.writeErrorResponse(w, http.StatusBadRequest, "invalid_request", httpx.DecodeErrorMessage(err, "Invalid request format"))
+		return
+	}
+
+	if req.IsActive == nil && req.RoleID == nil {
+		api.writeErrorResponse(w, http.StatusBadRequest, "missing_fields", "At least one of is_active or role_id is required")
+		return
+	}
+
+	if req.IsActive != nil && !*req.IsActive && userID == session.UserID {
+		api.writeErrorResponse(w, http.StatusUnprocessableEntity, "self_deactivation", "You cannot deactivate your own account")
+		return
+	}
+
+	if req.RoleID != nil && !api.checkRoleExists(w, *req.RoleID) {
+		return
+	}
+
+	// The previous role is returned alongside the updated row to tell a downgrade from an upgrade
+	query := `
+		WITH previous AS (
+			SELECT id, role_id FROM users WHERE id = $3 FOR UPDATE
+		)
+		UPDATE users
+		SET is_active = COALESCE($1, users.is_active),
+		    role_id = COALESCE($2, users.role_id),
+		    updated_at = CURRENT_TIMESTAMP
+		FROM previous
+		WHERE users.id = previous.id
+		RETURNING users.id, users.username, users.full_name, users.role_id, users.is_active,
+		          users.last_login, users.created_at, users.updated_at, previous.role_id
+	`
+
+	var user models.User
+	var previousRoleID string
+	err := api.db.QueryRow(query, req.IsActive, req.RoleID, userID).Scan(
+		&user.ID, &user.Username, &user.FullName, &user.RoleID,
+		&user.IsActive, &user.LastLogin, &user.CreatedAt, &user.UpdatedAt, &previousRoleID,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			api.writeErrorResponse(w, http.StatusNotFound, "user_not_found", "User not found")
+			return
+		}
+		api.logger.WithError(err).Error("Failed to update user")
+		api.writeErrorResponse(w, http.StatusInternalServerError, "user_update_failed", "Failed to update user")
+		return
+	}
+
+	// Sessions carry the role's permissions, so a deactivated or downgraded user has to sign in again;
+	// reactivations and upgrades leave the user's sessions alone
+	revokedCount := 0
+	deactivated := req.IsActive != nil && !*req.IsActive
+	if deactivated || (user.RoleID != previousRoleID && api.isRoleDowngrade(previousRoleID, user.RoleID)) {
+		revokedCount = api.revokeOtherSessions(user.ID, "")
+	}
+
+	api.logger.WithFields(logrus.Fields{
+		"user_id":       user.ID,
+		"is_active":     user.IsActive,
+		"role_id":       user.RoleID,
+		"updated_by":    session.UserID,
+		"revoked_count": revokedCount,
+	}).Info("User updated via API")
+
+	api.writeJSONResponse(w, http.StatusOK, user)
+}
+
+// requirePermission authenticates the request and writes a 403 when its session lacks the permission
+func (api *SessionAPI) requirePermission(w http.ResponseWriter, r *http.Request, permission string) (*models.SessionData, bool) {
+	session, ok := api.authenticatedSession(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	for _, granted := range session.Permissions {
+		if granted == permission {
+			return session, true
+		}
+	}
+
+	api.logger.WithFields(logrus.Fields{
+		"user_id":             session.UserID,
+		"username":            session.Username,
+		"required_permission": permission,
+	}).Warn("Access denied: insufficient permissions")
+
+	api.writeErrorResponse(w, http.StatusForbidden, "insufficient_permissions",
+		fmt.Sprintf("Required permission '%s' not found", permission))
+	return nil, false
+}
+
+// isRoleDowngrade reports whether the old role grants a permission the new role lacks. When the
+// roles cannot be compared it reports a downgrade, so the user's sessions are revoked to be safe
+func (api *SessionAPI) isRoleDowngrade(oldRoleID, newRoleID string) bool {
+	query := `
+		SELECT EXISTS(
+			SELECT 1 FROM permissions
+			WHERE role_id = $1
+			  AND permission_name NOT IN (SELECT permission_name FROM permissions WHERE role_id = $2)
+		)
+	`
+
+	var downgrade bool
+	if err := api.db.QueryRow(query, oldRoleID, newRoleID).Scan(&downgrade); err != nil {
+		api.logger.WithError(err).Warn("Failed to compare role permissions, treating the role change as a downgrade")
+		return true
+	}
+	return downgrade
+}
+
+// checkRoleExists writes a 422 when the role is unknown
+func (api *SessionAPI) checkRoleExists(w http.ResponseWriter, roleID string) bool {
+	var exists bool
+	if err := api.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM roles WHERE id = $1)`, roleID).Scan(&exists); err != nil {
+		api.logger.WithError(err).Error("Failed to check role")
+		api.writeErrorResponse(w, http.StatusInternalServerError, "role_lookup_failed", "Failed to look up role")
+		return false
+	}
+	if !exists {
+		api.writeErrorResponse(w, http.StatusUnprocessableEntity, "invalid_role", fmt.Sprintf("Role '%s' does not exist", roleID))
+		return false
+	}
+	return true
+}
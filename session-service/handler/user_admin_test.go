@@ -0,0 +1,284 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"session-service/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// setupUserAdminAPI creates a session API whose signed-in user-1 holds the given permissions and whose user-2 has one active session
+func setupUserAdminAPI(t *testing.T, permissions []string) (*SessionAPI, sqlmock.Sqlmock, *memorySessionStorage, string) {
+	api, mock, storage, token := setupChangePasswordAPI(t)
+
+	storage.sessions["session-current"].Permissions = permissions
+
+	now := time.Now().UTC()
+	require.NoError(t, storage.Store("session-user-2", &models.SessionData{
+		SessionID:    "session-user-2",
+		UserID:       "user-2",
+		Username:     "cashier",
+		CreatedAt:    now,
+		ExpiresAt:    now.Add(30 * time.Minute),
+		LastActivity: now,
+		IsActive:     true,
+	}))
+
+	return api, mock, storage, token
+}
+
+// TestCreateUser tests the permission check, role and username validation, and password hashing
+func TestCreateUser(t *testing.T) {
+	now := time.Now()
+
+	testCases := map[string]struct {
+		permissions    []string
+		body           string
+		setupMock      func(mock sqlmock.Sqlmock)
+		expectedStatus int
+		expectedCode   string
+	}{
+		"success": {
+			permissions: []string{UserAdminPermission},
+			body:        `{"username":"newcashier","password":"Passw0rd1","full_name":"New Cashier","role_id":"role-cashier"}`,
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM roles").
+					WithArgs("role-cashier").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM users").
+					WithArgs("newcashier").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+				mock.ExpectQuery("INSERT INTO users").
+					WithArgs("newcashier", bcryptHashArg{password: "Passw0rd1", cost: bcrypt.MinCost}, "New Cashier", "role-cashier").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow("user-3", now, now))
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		"duplicate username": {
+			permissions: []string{UserAdminPermission},
+			body:        `{"username":"cashier","password":"Passw0rd1","full_name":"Cashier","role_id":"role-cashier"}`,
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM roles").
+					WithArgs("role-cashier").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM users").
+					WithArgs("cashier").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+			},
+			expectedStatus: http.StatusConflict,
+			expectedCode:   "username_taken",
+		},
+		"username claimed concurrently": {
+			permissions: []string{UserAdminPermission},
+			body:        `{"username":"newcashier","password":"Passw0rd1","full_name":"New Cashier","role_id":"role-cashier"}`,
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM roles").
+					WithArgs("role-cashier").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM users").
+					WithArgs("newcashier").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+				mock.ExpectQuery("INSERT INTO users").
+					WillReturnError(&pq.Error{Code: "23505", Constraint: "users_username_key"})
+			},
+			expectedStatus: http.StatusConflict,
+			expectedCode:   "username_taken",
+		},
+		"unknown role": {
+			permissions: []string{UserAdminPermission},
+			body:        `{"username":"newcashier","password":"Passw0rd1","full_name":"New Cashier","role_id":"role-missing"}`,
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM roles").
+					WithArgs("role-missing").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   "invalid_role",
+		},
+		"unknown field": {
+			permissions:    []string{UserAdminPermission},
+			body:           `{"username":"newcashier","pasword":"Passw0rd1","full_name":"New Cashier","role_id":"role-cashier"}`,
+			setupMock:      func(mock sqlmock.Sqlmock) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "invalid_request",
+		},
+		"missing permission": {
+			permissions:    []string{"auth-read"},
+			body:           `{"username":"newcashier","password":"Passw0rd1","full_name":"New Cashier","role_id":"role-cashier"}`,
+			setupMock:      func(mock sqlmock.Sqlmock) {},
+			expectedStatus: http.StatusForbidden,
+			expectedCode:   "insufficient_permissions",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			api, mock, _, token := setupUserAdminAPI(t, tc.permissions)
+			tc.setupMock(mock)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/users", bytes.NewBufferString(tc.body))
+			req.Header.Set("Authorization", "Bearer "+token)
+			rr := httptest.NewRecorder()
+
+			api.CreateUser(rr, req)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+			assert.NoError(t, mock.ExpectationsWereMet())
+
+			if tc.expectedCode != "" {
+				var response models.ErrorResponse
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+				assert.Equal(t, tc.expectedCode, response.Error)
+				return
+			}
+
+			var user models.User
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &user))
+			assert.Equal(t, "user-3", user.ID)
+			assert.True(t, user.IsActive)
+			assert.NotContains(t, rr.Body.String(), "password")
+		})
+	}
+}
+
+// updatedUserColumns are the columns returned by the user update: the updated row and the previous role
+var updatedUserColumns = []string{"id", "username", "full_name", "role_id", "is_active", "last_login", "created_at", "updated_at", "previous_role_id"}
+
+// TestUpdateUser tests that only deactivations and role downgrades revoke sessions, unknown users, and the self-deactivation guard
+func TestUpdateUser(t *testing.T) {
+	now := time.Now()
+
+	testCases := map[string]struct {
+		userID         string
+		body           string
+		setupMock      func(mock sqlmock.Sqlmock)
+		expectedStatus int
+		expectedCode   string
+		expectRevoked  bool
+	}{
+		"deactivate": {
+			userID: "user-2",
+			body:   `{"is_active":false}`,
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("UPDATE users").
+					WithArgs(false, nil, "user-2").
+					WillReturnRows(sqlmock.NewRows(updatedUserColumns).
+						AddRow("user-2", "cashier", "Cashier", "role-cashier", false, nil, now, now, "role-cashier"))
+			},
+			expectedStatus: http.StatusOK,
+			expectRevoked:  true,
+		},
+		"reactivate": {
+			userID: "user-2",
+			body:   `{"is_active":true}`,
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("UPDATE users").
+					WithArgs(true, nil, "user-2").
+					WillReturnRows(sqlmock.NewRows(updatedUserColumns).
+						AddRow("user-2", "cashier", "Cashier", "role-cashier", true, nil, now, now, "role-cashier"))
+			},
+			expectedStatus: http.StatusOK,
+		},
+		"role downgrade": {
+			userID: "user-2",
+			body:   `{"role_id":"role-cashier"}`,
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM roles").
+					WithArgs("role-cashier").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectQuery("UPDATE users").
+					WithArgs(nil, "role-cashier", "user-2").
+					WillReturnRows(sqlmock.NewRows(updatedUserColumns).
+						AddRow("user-2", "cashier", "Cashier", "role-cashier", true, nil, now, now, "role-manager"))
+				mock.ExpectQuery("SELECT 1 FROM permissions").
+					WithArgs("role-manager", "role-cashier").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+			},
+			expectedStatus: http.StatusOK,
+			expectRevoked:  true,
+		},
+		"role upgrade": {
+			userID: "user-2",
+			body:   `{"role_id":"role-manager"}`,
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM roles").
+					WithArgs("role-manager").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectQuery("UPDATE users").
+					WithArgs(nil, "role-manager", "user-2").
+					WillReturnRows(sqlmock.NewRows(updatedUserColumns).
+						AddRow("user-2", "cashier", "Cashier", "role-manager", true, nil, now, now, "role-cashier"))
+				mock.ExpectQuery("SELECT 1 FROM permissions").
+					WithArgs("role-cashier", "role-manager").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+			},
+			expectedStatus: http.StatusOK,
+		},
+		"user not found": {
+			userID: "user-9",
+			body:   `{"is_active":false}`,
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("UPDATE users").
+					WithArgs(false, nil, "user-9").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   "user_not_found",
+		},
+		"self deactivation": {
+			userID:         "user-1",
+			body:           `{"is_active":false}`,
+			setupMock:      func(mock sqlmock.Sqlmock) {},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   "self_deactivation",
+		},
+		"no fields": {
+			userID:         "user-2",
+			body:           `{}`,
+			setupMock:      func(mock sqlmock.Sqlmock) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "missing_fields",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			api, mock, storage, token := setupUserAdminAPI(t, []string{UserAdminPermission})
+			tc.setupMock(mock)
+
+			req := httptest.NewRequest(http.MethodPatch, "/api/v1/auth/users/"+tc.userID, bytes.NewBufferString(tc.body))
+			req.Header.Set("Authorization", "Bearer "+token)
+			req = mux.SetURLVars(req, map[string]string{"id": tc.userID})
+			rr := httptest.NewRecorder()
+
+			api.UpdateUser(rr, req)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+			assert.NoError(t, mock.ExpectationsWereMet())
+
+			if tc.expectedCode != "" {
+				var response models.ErrorResponse
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+				assert.Equal(t, tc.expectedCode, response.Error)
+			}
+
+			_, err := storage.Get("session-user-2")
+			if tc.expectRevoked {
+				assert.Error(t, err, "a deactivated or downgraded user's sessions should be revoked")
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
@@ -14,18 +15,32 @@ import (
 	"session-service/handler"
 	"session-service/middleware"
 	"session-service/utils"
+	"shared/httpx"
+	"shared/schema"
+	"shared/version"
 
 	"github.com/gorilla/mux"
 	_ "github.com/lib/pq" // PostgreSQL driver
 	"github.com/sirupsen/logrus"
 )
 
+// requiredTables are the tables the service queries; startup fails when any is missing
+var requiredTables = []string{
+	"roles",
+	"users",
+	"permissions",
+	"sessions",
+}
+
 func main() {
 	// Load configuration
-	cfg := config.LoadConfig()
+	cfg, cfgErr := config.Load()
 
 	// Setup logger
 	logger := setupLogger(cfg.LogLevel)
+	if cfgErr != nil {
+		logger.WithError(cfgErr).Fatal("Invalid configuration")
+	}
 	logger.Info("Starting Ice Cream Store Session Service")
 
 	// Connect to database
@@ -35,6 +50,15 @@ func main() {
 	}
 	defer db.Close()
 
+	// Fail fast with the missing table names instead of query errors on the first request
+	checkCtx, cancelCheck := context.WithTimeout(context.Background(), 10*time.Second)
+	err = schema.CheckRequiredTables(checkCtx, db, requiredTables)
+	cancelCheck()
+	if err != nil {
+		logger.WithError(err).Fatal("Database schema self-check failed")
+	}
+	logger.WithField("tables", len(requiredTables)).Info("Database schema self-check passed")
+
 	// Create JWT manager
 	jwtManager := utils.NewJWTManager(cfg.JWTSecret, cfg.JWTExpirationTime, logger)
 
@@ -51,9 +75,14 @@ func main() {
 	// Create handlers (auth handler now gets session manager for login integration)
 	sessionHandler := handler.NewSessionHandler(sessionManager, jwtManager, logger)
 	sessionAPI := handler.NewSessionAPI(sessionManager, jwtManager, db, logger)
+	if cfg.HealthCheckDataService {
+		sessionAPI.SetDataServiceHealthURL(cfg.DataServiceHealthURL)
+	}
+	sessionAPI.SetPasswordManager(utils.NewPasswordManager(cfg.BcryptCost, logger))
+	sessionAPI.SetConfig(cfg.Sanitized())
 
 	// Setup HTTP router
-	router := setupRouter(sessionHandler, sessionAPI, logger)
+	router := setupRouter(sessionHandler, sessionAPI, cfg.RequestTimeout, cfg.RequestLogSampleRate, logger)
 
 	// Start HTTP server
 	server := &http.Server{
@@ -131,20 +160,44 @@ func connectToDatabase(cfg *config.Config, logger *logrus.Logger) (*sql.DB, erro
 	return db, nil
 }
 
-func setupRouter(sessionHandler *handler.SessionHandler, sessionAPI *handler.SessionAPI, logger *logrus.Logger) *mux.Router {
+func setupRouter(sessionHandler *handler.SessionHandler, sessionAPI *handler.SessionAPI, requestTimeout time.Duration, requestLogSampleRate int, logger *logrus.Logger) *mux.Router {
 	router := mux.NewRouter()
 
 	// Add middleware
-	router.Use(loggingMiddleware(logger))
+	router.Use(loggingMiddleware(logger, httpx.NewRequestLogSampler(requestLogSampleRate)))
 
 	// Gateway validation middleware - block direct access
 	gatewayMiddleware := middleware.NewGatewayMiddleware(logger)
 	router.Use(gatewayMiddleware.ValidateGateway)
 
+	// Answer handler panics with a JSON 500 instead of dropping the connection
+	router.Use(httpx.RecoveryMiddleware(logger))
+
+	// Compress large responses for clients that accept gzip
+	router.Use(httpx.GzipMiddleware(httpx.GzipMinSize))
+
+	// Indent JSON responses for ?pretty=true (before gzip sees the body)
+	router.Use(httpx.PrettyJSONMiddleware)
+
+	// Bound handler run time so a slow query cannot hold a connection
+	router.Use(httpx.TimeoutMiddleware(requestTimeout, nil))
+
+	// Write endpoints only accept JSON bodies
+	router.Use(httpx.RequireJSONMiddleware)
+
 	// CORS removed - gateway handles all CORS headers
 
 	// ==== SESSION MANAGEMENT API ROUTES ====
 
+	// Account endpoints (authenticated by the bearer token's session)
+	authRouter := router.PathPrefix("/api/v1/auth").Subrouter()
+	authRouter.HandleFunc("/change-password", sessionAPI.ChangePassword).Methods("POST") // POST /api/v1/auth/change-password
+	authRouter.HandleFunc("/users", sessionAPI.CreateUser).Methods("POST")               // POST /api/v1/auth/users (requires auth-write)
+	authRouter.HandleFunc("/users/{id}", sessionAPI.UpdateUser).Methods("PATCH")         // PATCH /api/v1/auth/users/{id} (requires auth-write)
+
+	// Effective configuration with secrets redacted (requires an admin role)
+	router.HandleFunc("/api/v1/config", sessionAPI.GetConfig).Methods("GET") // GET /api/v1/config
+
 	// Single session router to avoid routing conflicts
 	sessionRouter := router.PathPrefix("/api/v1/sessions").Subrouter()
 
@@ -158,6 +211,7 @@ func setupRouter(sessionHandler *handler.SessionHandler, sessionAPI *handler.Ses
 	sessionRouter.HandleFunc("", sessionAPI.CreateSession).Methods("POST")          // POST /api/v1/sessions
 	sessionRouter.HandleFunc("/refresh", sessionAPI.RefreshSession).Methods("POST") // POST /api/v1/sessions/refresh
 	sessionRouter.HandleFunc("/stats", sessionAPI.GetSessionStats).Methods("GET")   // GET /api/v1/sessions/stats
+	sessionRouter.HandleFunc("/metrics", sessionAPI.GetMetrics).Methods("GET")      // GET /api/v1/sessions/metrics
 
 	// Protected endpoints (TODO: add auth middleware when available)
 	sessionRouter.HandleFunc("/user/{userID}", sessionAPI.GetUserSessions).Methods("GET")          // GET /api/v1/sessions/user/{userID}
@@ -174,22 +228,26 @@ func setupRouter(sessionHandler *handler.SessionHandler, sessionAPI *handler.Ses
 	router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
-		// Simple JSON response without encoding issues
-		fmt.Fprintf(w, `{
-			"service": "ice-cream-session-service",
-			"version": "1.0.0",
-			"status": "running",
-			"time": "%s",
-			"session_management": "enabled"
-		}`, time.Now().Format(time.RFC3339))
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"service":            "ice-cream-session-service",
+			"version":            version.Version,
+			"build":              version.Info(),
+			"status":             "running",
+			"time":               time.Now().Format(time.RFC3339),
+			"session_management": "enabled",
+		})
 	}).Methods("GET")
 
+	// Unmatched routes get JSON 404/405 responses instead of mux's plain text
+	httpx.SetRouteErrorHandlers(router)
+
 	logger.Info("HTTP routes configured successfully with session management API")
 	return router
 }
 
-// loggingMiddleware logs HTTP requests
-func loggingMiddleware(logger *logrus.Logger) mux.MiddlewareFunc {
+// loggingMiddleware logs HTTP requests, including the gateway-supplied user when there is one.
+// sampler keeps every failed request and thins out the successful ones
+func loggingMiddleware(logger *logrus.Logger, sampler *httpx.RequestLogSampler) mux.MiddlewareFunc {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
@@ -199,7 +257,10 @@ func loggingMiddleware(logger *logrus.Logger) mux.MiddlewareFunc {
 
 			next.ServeHTTP(wrapped, r)
 
-			logger.WithFields(logrus.Fields{
+			if !sampler.ShouldLog(wrapped.statusCode) {
+				return
+			}
+			logger.WithFields(httpx.RequestLogFields(r)).WithFields(logrus.Fields{
 				"method":     r.Method,
 				"url":        r.URL.Path,
 				"status":     wrapped.statusCode,
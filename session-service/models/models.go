@@ -75,6 +75,26 @@ type LogoutRequest struct {
 	Token string `json:"token" validate:"required"`
 }
 
+// ChangePasswordRequest represents a password change by the signed-in user
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,min=8"`
+}
+
+// CreateUserRequest represents an administrator creating a user account
+type CreateUserRequest struct {
+	Username string `json:"username" validate:"required,min=3,max=50"`
+	Password string `json:"password" validate:"required,min=8"`
+	FullName string `json:"full_name" validate:"required"`
+	RoleID   string `json:"role_id" validate:"required"`
+}
+
+// UpdateUserRequest represents an administrator changing a user's status or role; nil fields are left unchanged
+type UpdateUserRequest struct {
+	IsActive *bool   `json:"is_active,omitempty"`
+	RoleID   *string `json:"role_id,omitempty"`
+}
+
 // JWTClaims represents the JWT claims
 type JWTClaims struct {
 	UserID      string   `json:"user_id"`
@@ -122,6 +142,14 @@ type AuthStatus struct {
 	RefreshAt       time.Time `json:"refresh_at,omitempty"`
 }
 
+// JWTMetrics is a snapshot of JWT operation counters since service start
+type JWTMetrics struct {
+	TokensGenerated    int64            `json:"tokens_generated"`
+	TokensValidated    int64            `json:"tokens_validated"`
+	RefreshRotations   int64            `json:"refresh_rotations"`
+	ValidationFailures map[string]int64 `json:"validation_failures"` // keyed by failure reason
+}
+
 // TokenInfo represents token information for debugging/admin purposes
 type TokenInfo struct {
 	Valid       bool      `json:"valid"`
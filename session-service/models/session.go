@@ -34,11 +34,27 @@ type SessionSummary struct {
 	IsCurrent    bool      `json:"is_current"`
 }
 
+// Page size bounds for listing a user's sessions
+const (
+	DefaultSessionListLimit = 20
+	MaxSessionListLimit     = 100
+)
+
+// SessionListOptions controls which of a user's sessions are listed and which page is returned
+type SessionListOptions struct {
+	Limit      int  `json:"limit"`
+	Offset     int  `json:"offset"`
+	ActiveOnly bool `json:"active_only"`
+}
+
 // SessionStats provides basic analytics about user sessions
 type SessionStats struct {
-	TotalSessions   int `json:"total_sessions"`
-	ActiveSessions  int `json:"active_sessions"`
-	ExpiredSessions int `json:"expired_sessions"`
+	TotalSessions            int            `json:"total_sessions"`
+	ActiveSessions           int            `json:"active_sessions"`
+	RevokedSessions          int            `json:"revoked_sessions"`
+	ExpiredSessions          int            `json:"expired_sessions"`
+	SessionsByRole           map[string]int `json:"sessions_by_role"`            // active sessions per role
+	AverageSessionAgeSeconds float64        `json:"average_session_age_seconds"` // across active sessions
 }
 
 // SessionValidationRequest represents a token validation request
@@ -81,9 +97,14 @@ type SessionConfig struct {
 	RememberMeExpiration time.Duration `json:"remember_me_expiration"`
 	RefreshThreshold     time.Duration `json:"refresh_threshold"`
 	CleanupInterval      time.Duration `json:"cleanup_interval"`
+	IdleTimeout          time.Duration `json:"idle_timeout"` // zero disables the idle check
 
 	// Basic Security Configuration
 	MaxConcurrentSessions int `json:"max_concurrent_sessions"`
+
+	// Validation Cache Configuration (a zero size or TTL disables the cache)
+	CacheSize int           `json:"cache_size"`
+	CacheTTL  time.Duration `json:"cache_ttl"`
 }
 
 // Default configuration with simple settings
@@ -93,6 +114,9 @@ func DefaultSessionConfig() *SessionConfig {
 		RememberMeExpiration:  7 * 24 * time.Hour, // 7 days
 		RefreshThreshold:      15 * time.Minute,   // Increased from 5 minutes to 15 minutes
 		CleanupInterval:       30 * time.Minute,   // Increased from 10 minutes to 30 minutes
+		IdleTimeout:           2 * time.Hour,
 		MaxConcurrentSessions: 5,
+		CacheSize:             1000,
+		CacheTTL:              30 * time.Second,
 	}
 }
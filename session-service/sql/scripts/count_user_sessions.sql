@@ -0,0 +1,4 @@
+-- Count a user's sessions, restricted to active ones when $2 is true
+SELECT COUNT(*) as session_count
+FROM sessions 
+WHERE user_id = $1 AND ($2 = false OR is_active = true);
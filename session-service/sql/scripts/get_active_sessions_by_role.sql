@@ -0,0 +1,6 @@
+-- Count active sessions per role
+SELECT role_name, COUNT(*) AS active_count
+FROM sessions
+WHERE is_active = true AND expires_at > CURRENT_TIMESTAMP
+GROUP BY role_name
+ORDER BY role_name;
\ No newline at end of file
@@ -0,0 +1,10 @@
+-- Aggregate session counts and average age of active sessions
+-- Revoked sessions were deactivated before their expiry; expired ones are past expires_at
+SELECT
+    COUNT(*) AS total_sessions,
+    COUNT(*) FILTER (WHERE is_active = true AND expires_at > CURRENT_TIMESTAMP) AS active_sessions,
+    COUNT(*) FILTER (WHERE is_active = false AND expires_at > CURRENT_TIMESTAMP) AS revoked_sessions,
+    COUNT(*) FILTER (WHERE expires_at <= CURRENT_TIMESTAMP) AS expired_sessions,
+    COALESCE(AVG(EXTRACT(EPOCH FROM (CURRENT_TIMESTAMP - created_at)))
+        FILTER (WHERE is_active = true AND expires_at > CURRENT_TIMESTAMP), 0) AS average_session_age_seconds
+FROM sessions;
\ No newline at end of file
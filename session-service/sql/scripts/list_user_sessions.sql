@@ -0,0 +1,17 @@
+-- Get one page of a user's sessions, newest first
+-- $2 restricts the page to active sessions; a $3 of 0 returns every session from $4 on
+SELECT 
+    session_id,
+    user_id,
+    username,
+    role_name,
+    permissions,
+    token_hash,
+    created_at,
+    expires_at,
+    last_activity,
+    is_active
+FROM sessions 
+WHERE user_id = $1 AND ($2 = false OR is_active = true)
+ORDER BY created_at DESC
+LIMIT NULLIF($3, 0) OFFSET $4;
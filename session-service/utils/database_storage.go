@@ -198,6 +198,61 @@ func (s *DatabaseSessionStorage) GetUserSessions(userID string) ([]*models.Sessi
 	return sessions, nil
 }
 
+// ListUserSessions returns one page of a user's sessions, newest first, and the number of sessions matching the filter
+func (s *DatabaseSessionStorage) ListUserSessions(userID string, opts models.SessionListOptions) ([]*models.SessionData, int, error) {
+	countQuery, err := s.queries.Get("count_user_sessions")
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get count user sessions query: %w", err)
+	}
+
+	listQuery, err := s.queries.Get("list_user_sessions")
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get list user sessions query: %w", err)
+	}
+
+	var total int
+	if err := s.db.QueryRow(countQuery, userID, opts.ActiveOnly).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count user sessions: %w", err)
+	}
+
+	rows, err := s.db.Query(listQuery, userID, opts.ActiveOnly, opts.Limit, opts.Offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list user sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := make([]*models.SessionData, 0)
+	for rows.Next() {
+		session := &models.SessionData{}
+		var permissions pq.StringArray
+
+		err := rows.Scan(
+			&session.SessionID,
+			&session.UserID,
+			&session.Username,
+			&session.RoleName,
+			&permissions,
+			&session.TokenHash,
+			&session.CreatedAt,
+			&session.ExpiresAt,
+			&session.LastActivity,
+			&session.IsActive,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan session: %w", err)
+		}
+
+		session.Permissions = []string(permissions)
+		sessions = append(sessions, session)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating sessions: %w", err)
+	}
+
+	return sessions, total, nil
+}
+
 // Update updates a session in the database
 func (s *DatabaseSessionStorage) Update(sessionID string, session *models.SessionData) error {
 	query, err := s.queries.Get("update_session_activity")
@@ -315,6 +370,52 @@ func (s *DatabaseSessionStorage) CountUserActiveSessions(userID string) (int, er
 	return count, nil
 }
 
+// GetSessionStats aggregates session counts, per-role active sessions and average session age
+func (s *DatabaseSessionStorage) GetSessionStats() (*models.SessionStats, error) {
+	statsQuery, err := s.queries.Get("get_session_stats")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stats query: %w", err)
+	}
+
+	byRoleQuery, err := s.queries.Get("get_active_sessions_by_role")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sessions by role query: %w", err)
+	}
+
+	stats := &models.SessionStats{SessionsByRole: make(map[string]int)}
+	err = s.db.QueryRow(statsQuery).Scan(
+		&stats.TotalSessions,
+		&stats.ActiveSessions,
+		&stats.RevokedSessions,
+		&stats.ExpiredSessions,
+		&stats.AverageSessionAgeSeconds,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate session stats: %w", err)
+	}
+
+	rows, err := s.db.Query(byRoleQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count sessions by role: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var roleName string
+		var count int
+		if err := rows.Scan(&roleName, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan sessions by role: %w", err)
+		}
+		stats.SessionsByRole[roleName] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sessions by role: %w", err)
+	}
+
+	return stats, nil
+}
+
 // CleanupUserExpiredSessions removes expired sessions for a specific user
 func (s *DatabaseSessionStorage) CleanupUserExpiredSessions(userID string) error {
 	query, err := s.queries.Get("cleanup_user_expired_sessions")
@@ -0,0 +1,161 @@
+package utils
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"session-service/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupTestDatabaseStorage creates a database session storage backed by sqlmock
+func setupTestDatabaseStorage(t *testing.T) (*DatabaseSessionStorage, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+
+	storage, err := NewDatabaseSessionStorage(db, logger)
+	require.NoError(t, err)
+
+	return storage, mock, func() { db.Close() }
+}
+
+// TestGetSessionStats tests that aggregate rows are mapped onto the session stats
+func TestGetSessionStats(t *testing.T) {
+	storage, mock, cleanup := setupTestDatabaseStorage(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT (.+) AS total_sessions").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"total_sessions", "active_sessions", "revoked_sessions", "expired_sessions", "average_session_age_seconds",
+		}).AddRow(150, 45, 20, 85, 1843.5))
+	mock.ExpectQuery("SELECT role_name, COUNT\\(\\*\\) AS active_count").
+		WillReturnRows(sqlmock.NewRows([]string{"role_name", "active_count"}).
+			AddRow("admin", 5).
+			AddRow("cashier", 40))
+
+	stats, err := storage.GetSessionStats()
+	require.NoError(t, err)
+
+	assert.Equal(t, 150, stats.TotalSessions)
+	assert.Equal(t, 45, stats.ActiveSessions)
+	assert.Equal(t, 20, stats.RevokedSessions)
+	assert.Equal(t, 85, stats.ExpiredSessions)
+	assert.Equal(t, 1843.5, stats.AverageSessionAgeSeconds)
+	assert.Equal(t, map[string]int{"admin": 5, "cashier": 40}, stats.SessionsByRole)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestGetSessionStatsNoSessions tests the stats of an empty sessions table
+func TestGetSessionStatsNoSessions(t *testing.T) {
+	storage, mock, cleanup := setupTestDatabaseStorage(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT (.+) AS total_sessions").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"total_sessions", "active_sessions", "revoked_sessions", "expired_sessions", "average_session_age_seconds",
+		}).AddRow(0, 0, 0, 0, 0))
+	mock.ExpectQuery("SELECT role_name, COUNT\\(\\*\\) AS active_count").
+		WillReturnRows(sqlmock.NewRows([]string{"role_name", "active_count"}))
+
+	stats, err := storage.GetSessionStats()
+	require.NoError(t, err)
+
+	assert.Zero(t, stats.TotalSessions)
+	assert.Zero(t, stats.AverageSessionAgeSeconds)
+	assert.NotNil(t, stats.SessionsByRole)
+	assert.Empty(t, stats.SessionsByRole)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestGetSessionStatsError tests that aggregate query failures are returned
+func TestGetSessionStatsError(t *testing.T) {
+	storage, mock, cleanup := setupTestDatabaseStorage(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT (.+) AS total_sessions").
+		WillReturnError(sql.ErrConnDone)
+
+	stats, err := storage.GetSessionStats()
+	assert.Error(t, err)
+	assert.Nil(t, stats)
+	assert.Contains(t, err.Error(), "failed to aggregate session stats")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestListUserSessions tests that filtering, ordering and paging are passed to the database
+func TestListUserSessions(t *testing.T) {
+	columns := []string{
+		"session_id", "user_id", "username", "role_name", "permissions", "token_hash",
+		"created_at", "expires_at", "last_activity", "is_active",
+	}
+	now := time.Now().UTC()
+
+	testCases := map[string]struct {
+		opts          models.SessionListOptions
+		total         int
+		rows          *sqlmock.Rows
+		expectedCount int
+	}{
+		"active sessions page": {
+			opts:  models.SessionListOptions{Limit: 2, Offset: 4, ActiveOnly: true},
+			total: 6,
+			rows: sqlmock.NewRows(columns).
+				AddRow("session-2", "user-1", "admin", "admin", "{orders.read}", "hash-2", now.Add(-time.Minute), now.Add(time.Hour), now, true).
+				AddRow("session-1", "user-1", "admin", "admin", "{orders.read}", "hash-1", now.Add(-time.Hour), now.Add(time.Hour), now, true),
+			expectedCount: 2,
+		},
+		"offset past the last session": {
+			opts:          models.SessionListOptions{Limit: 50, Offset: 10},
+			total:         3,
+			rows:          sqlmock.NewRows(columns),
+			expectedCount: 0,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			storage, mock, cleanup := setupTestDatabaseStorage(t)
+			defer cleanup()
+
+			mock.ExpectQuery("SELECT COUNT\\(\\*\\) as session_count").
+				WithArgs("user-1", tc.opts.ActiveOnly).
+				WillReturnRows(sqlmock.NewRows([]string{"session_count"}).AddRow(tc.total))
+			mock.ExpectQuery("ORDER BY created_at DESC\\s+LIMIT NULLIF\\(\\$3, 0\\) OFFSET \\$4").
+				WithArgs("user-1", tc.opts.ActiveOnly, tc.opts.Limit, tc.opts.Offset).
+				WillReturnRows(tc.rows)
+
+			sessions, total, err := storage.ListUserSessions("user-1", tc.opts)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.total, total)
+			require.Len(t, sessions, tc.expectedCount)
+			if tc.expectedCount > 0 {
+				assert.Equal(t, "session-2", sessions[0].SessionID)
+				assert.Equal(t, []string{"orders.read"}, sessions[0].Permissions)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// TestListUserSessionsCountError tests that a failed count skips the page query
+func TestListUserSessionsCountError(t *testing.T) {
+	storage, mock, cleanup := setupTestDatabaseStorage(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) as session_count").
+		WillReturnError(sql.ErrConnDone)
+
+	_, _, err := storage.ListUserSessions("user-1", models.SessionListOptions{Limit: 50})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, sql.ErrConnDone)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
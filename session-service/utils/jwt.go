@@ -1,7 +1,9 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"session-service/models"
@@ -32,19 +34,35 @@ When storing times, always use UTC.
 When comparing times, always use UTC.
 */
 
+// Validation failure reasons reported in JWT metrics
+const (
+	JWTFailureExpired   = "expired"
+	JWTFailureSignature = "signature"
+	JWTFailureRevoked   = "revoked"
+	JWTFailureMalformed = "malformed"
+)
+
 // JWTManager handles JWT token operations
 type JWTManager struct {
 	secret     []byte
 	expiration time.Duration
 	logger     *logrus.Logger
+
+	// Operation counters
+	metricsMutex       sync.Mutex
+	tokensGenerated    int64
+	tokensValidated    int64
+	refreshRotations   int64
+	validationFailures map[string]int64
 }
 
 // NewJWTManager creates a new JWT manager instance
 func NewJWTManager(secret string, expiration time.Duration, logger *logrus.Logger) *JWTManager {
 	return &JWTManager{
-		secret:     []byte(secret),
-		expiration: expiration,
-		logger:     logger,
+		secret:             []byte(secret),
+		expiration:         expiration,
+		logger:             logger,
+		validationFailures: make(map[string]int64),
 	}
 }
 
@@ -84,6 +102,7 @@ func (j *JWTManager) GenerateToken(profile *models.UserProfile, sessionID string
 		j.logger.WithError(err).Error("Failed to sign JWT token")
 		return "", time.Time{}, fmt.Errorf("failed to generate token: %w", err)
 	}
+	j.updateMetrics(func() { j.tokensGenerated++ })
 
 	j.logger.WithFields(logrus.Fields{
 		"user_id":        profile.User.ID,
@@ -108,12 +127,14 @@ func (j *JWTManager) ValidateToken(tokenString string) (*models.JWTClaims, error
 
 	if err != nil {
 		j.logger.WithError(err).Warn("JWT token validation failed")
+		j.recordValidationFailure(validationFailureReason(err))
 		return nil, fmt.Errorf("invalid token: %w", err)
 	}
 
 	claims, ok := token.Claims.(*models.JWTClaims)
 	if !ok || !token.Valid {
 		j.logger.Warn("JWT token claims are invalid")
+		j.recordValidationFailure(JWTFailureMalformed)
 		return nil, fmt.Errorf("invalid token claims")
 	}
 
@@ -124,9 +145,12 @@ func (j *JWTManager) ValidateToken(tokenString string) (*models.JWTClaims, error
 			"expires_at":     claims.ExpiresAt.Time.Format("2006-01-02 15:04:05 MST"),
 			"expires_at_utc": claims.ExpiresAt.Time.UTC().Format("2006-01-02 15:04:05 UTC"),
 		}).Warn("JWT token has expired")
+		j.recordValidationFailure(JWTFailureExpired)
 		return nil, fmt.Errorf("token has expired")
 	}
 
+	j.updateMetrics(func() { j.tokensValidated++ })
+
 	j.logger.WithFields(logrus.Fields{
 		"user_id":  claims.UserID,
 		"username": claims.Username,
@@ -182,6 +206,7 @@ func (j *JWTManager) RefreshToken(tokenString string, refreshThreshold time.Dura
 		j.logger.WithError(err).Error("Failed to sign refreshed JWT token")
 		return "", time.Time{}, fmt.Errorf("failed to refresh token: %w", err)
 	}
+	j.recordRefreshRotation()
 
 	j.logger.WithFields(logrus.Fields{
 		"user_id":    claims.UserID,
@@ -235,3 +260,88 @@ func (j *JWTManager) GetTokenInfo(tokenString string) *models.TokenInfo {
 
 	return info
 }
+
+// ErrJWTSecretMissing is returned by SelfCheck when the manager has no signing secret
+var ErrJWTSecretMissing = errors.New("JWT secret is not configured")
+
+// SelfCheck signs and verifies a short-lived throwaway token, so a misconfigured secret surfaces in
+// health checks instead of at login; it is not counted in the operation metrics
+func (j *JWTManager) SelfCheck() error {
+	if len(j.secret) == 0 {
+		return ErrJWTSecretMissing
+	}
+
+	now := time.Now().UTC()
+	claims := &jwt.RegisteredClaims{
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+		Subject:   "health-check",
+		Issuer:    "icecream-session-service",
+	}
+
+	tokenString, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(j.secret)
+	if err != nil {
+		return fmt.Errorf("failed to sign health check token: %w", err)
+	}
+
+	parsed := &jwt.RegisteredClaims{}
+	_, err = jwt.ParseWithClaims(tokenString, parsed, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return j.secret, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to verify health check token: %w", err)
+	}
+	if parsed.Subject != claims.Subject {
+		return fmt.Errorf("health check token round-tripped with subject %q", parsed.Subject)
+	}
+	return nil
+}
+
+// Metrics returns a snapshot of the JWT operation counters
+func (j *JWTManager) Metrics() models.JWTMetrics {
+	j.metricsMutex.Lock()
+	defer j.metricsMutex.Unlock()
+
+	failures := make(map[string]int64, len(j.validationFailures))
+	for reason, count := range j.validationFailures {
+		failures[reason] = count
+	}
+
+	return models.JWTMetrics{
+		TokensGenerated:    j.tokensGenerated,
+		TokensValidated:    j.tokensValidated,
+		RefreshRotations:   j.refreshRotations,
+		ValidationFailures: failures,
+	}
+}
+
+// recordValidationFailure counts a failed validation under the given reason
+func (j *JWTManager) recordValidationFailure(reason string) {
+	j.updateMetrics(func() { j.validationFailures[reason]++ })
+}
+
+// recordRefreshRotation counts a token replaced by a refreshed one
+func (j *JWTManager) recordRefreshRotation() {
+	j.updateMetrics(func() { j.refreshRotations++ })
+}
+
+func (j *JWTManager) updateMetrics(fn func()) {
+	j.metricsMutex.Lock()
+	defer j.metricsMutex.Unlock()
+	fn()
+}
+
+// validationFailureReason maps a jwt parse error to a metrics failure reason
+func validationFailureReason(err error) string {
+	switch {
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return JWTFailureExpired
+	case errors.Is(err, jwt.ErrTokenSignatureInvalid):
+		return JWTFailureSignature
+	default:
+		return JWTFailureMalformed
+	}
+}
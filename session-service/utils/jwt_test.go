@@ -110,7 +110,7 @@ func TestGenerateToken(t *testing.T) {
 	jwtManager := setupTestJWTManager()
 	profile := createTestUserProfile()
 
-	token, expiresAt, err := jwtManager.GenerateToken(profile)
+	token, expiresAt, err := jwtManager.GenerateToken(profile, "test-session-id")
 
 	// Test successful generation
 	require.NoError(t, err)
@@ -133,7 +133,7 @@ func TestGenerateTokenWithNilProfile(t *testing.T) {
 
 	// This should panic or return an error
 	assert.Panics(t, func() {
-		jwtManager.GenerateToken(nil)
+		jwtManager.GenerateToken(nil, "test-session-id")
 	})
 }
 
@@ -143,7 +143,7 @@ func TestGenerateTokenWithEmptyPermissions(t *testing.T) {
 	profile := createTestUserProfile()
 	profile.Permissions = []models.Permission{} // Empty permissions
 
-	token, expiresAt, err := jwtManager.GenerateToken(profile)
+	token, expiresAt, err := jwtManager.GenerateToken(profile, "test-session-id")
 
 	require.NoError(t, err)
 	assert.NotEmpty(t, token)
@@ -162,7 +162,7 @@ func TestGenerateTokenWithNilPermissions(t *testing.T) {
 	profile := createTestUserProfile()
 	profile.Permissions = nil // Nil permissions
 
-	token, expiresAt, err := jwtManager.GenerateToken(profile)
+	token, expiresAt, err := jwtManager.GenerateToken(profile, "test-session-id")
 
 	require.NoError(t, err)
 	assert.NotEmpty(t, token)
@@ -181,7 +181,7 @@ func TestValidateToken(t *testing.T) {
 	profile := createTestUserProfile()
 
 	// Generate a valid token
-	token, _, err := jwtManager.GenerateToken(profile)
+	token, _, err := jwtManager.GenerateToken(profile, "test-session-id")
 	require.NoError(t, err)
 
 	// Test valid token
@@ -262,7 +262,7 @@ func TestValidateExpiredToken(t *testing.T) {
 	jwtManager := NewJWTManager("test-secret", 1*time.Millisecond, logger)
 
 	profile := createTestUserProfile()
-	token, _, err := jwtManager.GenerateToken(profile)
+	token, _, err := jwtManager.GenerateToken(profile, "test-session-id")
 	require.NoError(t, err)
 
 	// Wait for token to expire
@@ -280,7 +280,7 @@ func TestValidateTokenWithDifferentSecret(t *testing.T) {
 	// Generate token with one manager
 	jwtManager1 := NewJWTManager("secret1", 30*time.Minute, logrus.New())
 	profile := createTestUserProfile()
-	token, _, err := jwtManager1.GenerateToken(profile)
+	token, _, err := jwtManager1.GenerateToken(profile, "test-session-id")
 	require.NoError(t, err)
 
 	// Try to validate with different secret
@@ -292,6 +292,34 @@ func TestValidateTokenWithDifferentSecret(t *testing.T) {
 	assert.Contains(t, err.Error(), "invalid token")
 }
 
+// TestSelfCheck tests the throwaway token round trip used by the health check
+func TestSelfCheck(t *testing.T) {
+	testCases := map[string]struct {
+		secret      string
+		expectedErr error
+	}{
+		"configured secret": {secret: "test-secret-key"},
+		"missing secret":    {secret: "", expectedErr: ErrJWTSecretMissing},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			logger := logrus.New()
+			logger.SetLevel(logrus.ErrorLevel)
+			jwtManager := NewJWTManager(tc.secret, 30*time.Minute, logger)
+
+			err := jwtManager.SelfCheck()
+
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Zero(t, jwtManager.Metrics().TokensGenerated, "self checks are not counted as issued tokens")
+		})
+	}
+}
+
 // TestRefreshToken tests JWT token refresh functionality
 func TestRefreshToken(t *testing.T) {
 	// Create JWT manager with longer expiration for refresh testing
@@ -300,7 +328,7 @@ func TestRefreshToken(t *testing.T) {
 	jwtManager := NewJWTManager("test-secret", 10*time.Minute, logger)
 
 	profile := createTestUserProfile()
-	originalToken, originalExpiry, err := jwtManager.GenerateToken(profile)
+	originalToken, originalExpiry, err := jwtManager.GenerateToken(profile, "test-session-id")
 	require.NoError(t, err)
 
 	// Wait a bit to ensure new token has different issued time
@@ -327,7 +355,7 @@ func TestRefreshTokenNotNeeded(t *testing.T) {
 	jwtManager := setupTestJWTManager()
 	profile := createTestUserProfile()
 
-	token, _, err := jwtManager.GenerateToken(profile)
+	token, _, err := jwtManager.GenerateToken(profile, "test-session-id")
 	require.NoError(t, err)
 
 	// Try to refresh with short threshold (token doesn't need refresh yet)
@@ -363,7 +391,7 @@ func TestRefreshExpiredToken(t *testing.T) {
 	jwtManager := NewJWTManager("test-secret", 1*time.Millisecond, logger)
 
 	profile := createTestUserProfile()
-	token, _, err := jwtManager.GenerateToken(profile)
+	token, _, err := jwtManager.GenerateToken(profile, "test-session-id")
 	require.NoError(t, err)
 
 	// Wait for token to expire
@@ -384,7 +412,7 @@ func TestGetTokenInfo(t *testing.T) {
 	jwtManager := setupTestJWTManager()
 	profile := createTestUserProfile()
 
-	token, expiresAt, err := jwtManager.GenerateToken(profile)
+	token, expiresAt, err := jwtManager.GenerateToken(profile, "test-session-id")
 	require.NoError(t, err)
 
 	// Test valid token info
@@ -449,7 +477,7 @@ func TestGetTokenInfoExpired(t *testing.T) {
 	jwtManager := NewJWTManager("test-secret", 1*time.Millisecond, logger)
 
 	profile := createTestUserProfile()
-	token, _, err := jwtManager.GenerateToken(profile)
+	token, _, err := jwtManager.GenerateToken(profile, "test-session-id")
 	require.NoError(t, err)
 
 	// Wait for token to expire
@@ -482,7 +510,7 @@ func TestJWTManagerWithDifferentExpirations(t *testing.T) {
 		t.Run(fmt.Sprintf("expiration_%v", expiration), func(t *testing.T) {
 			jwtManager := NewJWTManager("test-secret", expiration, logger)
 
-			token, expiresAt, err := jwtManager.GenerateToken(profile)
+			token, expiresAt, err := jwtManager.GenerateToken(profile, "test-session-id")
 			require.NoError(t, err)
 			assert.NotEmpty(t, token)
 
@@ -516,7 +544,7 @@ func TestJWTManagerWithDifferentSecrets(t *testing.T) {
 		t.Run(fmt.Sprintf("secret_%d_chars", len(secret)), func(t *testing.T) {
 			jwtManager := NewJWTManager(secret, 30*time.Minute, logger)
 
-			token, _, err := jwtManager.GenerateToken(profile)
+			token, _, err := jwtManager.GenerateToken(profile, "test-session-id")
 			require.NoError(t, err)
 			assert.NotEmpty(t, token)
 
@@ -543,7 +571,7 @@ func TestTokenCrossCompatibility(t *testing.T) {
 	profile := createTestUserProfile()
 
 	// Generate token with first manager
-	token, _, err := jwtManager1.GenerateToken(profile)
+	token, _, err := jwtManager1.GenerateToken(profile, "test-session-id")
 	require.NoError(t, err)
 
 	// Validate with second manager
@@ -569,7 +597,7 @@ func BenchmarkGenerateToken(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, _, err := jwtManager.GenerateToken(profile)
+		_, _, err := jwtManager.GenerateToken(profile, "test-session-id")
 		if err != nil {
 			b.Fatal(err)
 		}
@@ -581,7 +609,7 @@ func BenchmarkValidateToken(b *testing.B) {
 	jwtManager := setupTestJWTManager()
 	profile := createTestUserProfile()
 
-	token, _, err := jwtManager.GenerateToken(profile)
+	token, _, err := jwtManager.GenerateToken(profile, "test-session-id")
 	if err != nil {
 		b.Fatal(err)
 	}
@@ -602,7 +630,7 @@ func BenchmarkRefreshToken(b *testing.B) {
 	jwtManager := NewJWTManager("test-secret", 10*time.Minute, logger)
 
 	profile := createTestUserProfile()
-	token, _, err := jwtManager.GenerateToken(profile)
+	token, _, err := jwtManager.GenerateToken(profile, "test-session-id")
 	if err != nil {
 		b.Fatal(err)
 	}
@@ -623,7 +651,7 @@ func BenchmarkGetTokenInfo(b *testing.B) {
 	jwtManager := setupTestJWTManager()
 	profile := createTestUserProfile()
 
-	token, _, err := jwtManager.GenerateToken(profile)
+	token, _, err := jwtManager.GenerateToken(profile, "test-session-id")
 	if err != nil {
 		b.Fatal(err)
 	}
@@ -636,3 +664,57 @@ func BenchmarkGetTokenInfo(b *testing.B) {
 		}
 	}
 }
+
+// TestJWTMetricsCountsFailureReasons tests that validation failures are counted by reason
+func TestJWTMetricsCountsFailureReasons(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+	jwtManager := NewJWTManager("test-secret", 1*time.Millisecond, logger)
+
+	token, _, err := jwtManager.GenerateToken(createTestUserProfile(), "test-session-id")
+	require.NoError(t, err)
+
+	// Wait for token to expire
+	time.Sleep(10 * time.Millisecond)
+
+	_, err = jwtManager.ValidateToken(token)
+	require.Error(t, err)
+
+	metrics := jwtManager.Metrics()
+	assert.Equal(t, int64(1), metrics.TokensGenerated)
+	assert.Equal(t, int64(0), metrics.TokensValidated)
+	assert.Equal(t, int64(1), metrics.ValidationFailures[JWTFailureExpired])
+	assert.Zero(t, metrics.ValidationFailures[JWTFailureSignature])
+
+	// A token signed with another secret fails on its signature
+	otherManager := NewJWTManager("other-secret", 30*time.Minute, logger)
+	otherToken, _, err := otherManager.GenerateToken(createTestUserProfile(), "test-session-id")
+	require.NoError(t, err)
+
+	_, err = jwtManager.ValidateToken(otherToken)
+	require.Error(t, err)
+
+	metrics = jwtManager.Metrics()
+	assert.Equal(t, int64(1), metrics.ValidationFailures[JWTFailureExpired])
+	assert.Equal(t, int64(1), metrics.ValidationFailures[JWTFailureSignature])
+}
+
+// TestJWTMetricsCountsValidations tests the success counters
+func TestJWTMetricsCountsValidations(t *testing.T) {
+	jwtManager := setupTestJWTManager()
+
+	token, _, err := jwtManager.GenerateToken(createTestUserProfile(), "test-session-id")
+	require.NoError(t, err)
+
+	_, err = jwtManager.ValidateToken(token)
+	require.NoError(t, err)
+
+	_, _, err = jwtManager.RefreshToken(token, 31*time.Minute)
+	require.NoError(t, err)
+
+	metrics := jwtManager.Metrics()
+	assert.Equal(t, int64(1), metrics.TokensGenerated)
+	assert.Equal(t, int64(2), metrics.TokensValidated)
+	assert.Equal(t, int64(1), metrics.RefreshRotations)
+	assert.Empty(t, metrics.ValidationFailures)
+}
@@ -2,11 +2,38 @@ package utils
 
 import (
 	"fmt"
+	"unicode"
 
 	"github.com/sirupsen/logrus"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// MinPasswordLength is the shortest password the strength policy accepts
+const MinPasswordLength = 8
+
+// ValidatePasswordStrength enforces the password policy: at least MinPasswordLength
+// characters with at least one letter and one digit
+func ValidatePasswordStrength(password string) error {
+	if len([]rune(password)) < MinPasswordLength {
+		return fmt.Errorf("password must be at least %d characters long", MinPasswordLength)
+	}
+
+	var hasLetter, hasDigit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		}
+	}
+	if !hasLetter || !hasDigit {
+		return fmt.Errorf("password must contain at least one letter and one digit")
+	}
+
+	return nil
+}
+
 // PasswordManager handles password hashing and validation
 type PasswordManager struct {
 	cost   int
@@ -52,3 +79,12 @@ func (p *PasswordManager) ValidatePassword(password, hashedPassword string) erro
 	p.logger.Debug("Password validation successful")
 	return nil
 }
+
+// NeedsRehash reports whether a hash was made with a lower cost than the configured one
+func (p *PasswordManager) NeedsRehash(hashedPassword string) bool {
+	cost, err := bcrypt.Cost([]byte(hashedPassword))
+	if err != nil {
+		return false
+	}
+	return cost < p.cost
+}
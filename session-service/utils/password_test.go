@@ -560,3 +560,70 @@ func TestConcurrentHashing(t *testing.T) {
 		}
 	}
 }
+
+// TestNeedsRehash tests detection of hashes below the configured cost
+func TestNeedsRehash(t *testing.T) {
+	logger := setupTestLogger()
+
+	lowCostHash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		cost     int
+		hash     string
+		expected bool
+	}{
+		{
+			name:     "hash below configured cost",
+			cost:     bcrypt.MinCost + 1,
+			hash:     string(lowCostHash),
+			expected: true,
+		},
+		{
+			name:     "hash at configured cost",
+			cost:     bcrypt.MinCost,
+			hash:     string(lowCostHash),
+			expected: false,
+		},
+		{
+			name:     "invalid hash",
+			cost:     bcrypt.MinCost + 1,
+			hash:     "not-a-bcrypt-hash",
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pm := NewPasswordManager(tt.cost, logger)
+			assert.Equal(t, tt.expected, pm.NeedsRehash(tt.hash))
+		})
+	}
+}
+
+// TestValidatePasswordStrength tests the password strength policy
+func TestValidatePasswordStrength(t *testing.T) {
+	tests := []struct {
+		name      string
+		password  string
+		expectErr bool
+	}{
+		{name: "letters and digits", password: "icecream42"},
+		{name: "too short", password: "abc123", expectErr: true},
+		{name: "letters only", password: "icecreamcone", expectErr: true},
+		{name: "digits only", password: "1234567890", expectErr: true},
+		{name: "unicode letters", password: "heladería9"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePasswordStrength(tt.password)
+			if tt.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package utils
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"session-service/models"
+)
+
+// SessionCache is a concurrency-safe LRU cache of validated sessions keyed by session ID.
+// Entries expire after the configured TTL so changes made by other instances are picked up.
+// A nil *SessionCache is valid and behaves as a disabled cache.
+type SessionCache struct {
+	maxEntries int
+	ttl        time.Duration
+
+	mutex   sync.Mutex
+	order   *list.List // front = most recently used
+	entries map[string]*list.Element
+
+	now func() time.Time
+}
+
+type sessionCacheEntry struct {
+	sessionID string
+	session   models.SessionData
+	expiresAt time.Time
+}
+
+// NewSessionCache creates a session cache, returning nil (cache disabled) when size or ttl is not positive
+func NewSessionCache(maxEntries int, ttl time.Duration) *SessionCache {
+	if maxEntries <= 0 || ttl <= 0 {
+		return nil
+	}
+
+	return &SessionCache{
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+		now:        time.Now,
+	}
+}
+
+// Get returns a copy of the cached session, or false if it is missing or stale
+func (c *SessionCache) Get(sessionID string) (*models.SessionData, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, ok := c.entries[sessionID]
+	if !ok {
+		return nil, false
+	}
+
+	entry := element.Value.(*sessionCacheEntry)
+	if c.now().After(entry.expiresAt) {
+		c.removeElement(element)
+		return nil, false
+	}
+
+	c.order.MoveToFront(element)
+	session := entry.session
+	return &session, true
+}
+
+// Set stores a copy of the session, evicting the least recently used entry when full.
+// Updating an entry that is already cached keeps its original expiry, so a session that is
+// validated constantly is still reloaded from storage once per TTL
+func (c *SessionCache) Set(session *models.SessionData) {
+	if c == nil || session == nil {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if element, ok := c.entries[session.SessionID]; ok {
+		element.Value.(*sessionCacheEntry).session = *session
+		c.order.MoveToFront(element)
+		return
+	}
+
+	element := c.order.PushFront(&sessionCacheEntry{
+		sessionID: session.SessionID,
+		session:   *session,
+		expiresAt: c.now().Add(c.ttl),
+	})
+	c.entries[session.SessionID] = element
+
+	for c.order.Len() > c.maxEntries {
+		c.removeElement(c.order.Back())
+	}
+}
+
+// Delete evicts a single session
+func (c *SessionCache) Delete(sessionID string) {
+	if c == nil {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if element, ok := c.entries[sessionID]; ok {
+		c.removeElement(element)
+	}
+}
+
+// DeleteUser evicts every cached session belonging to a user
+func (c *SessionCache) DeleteUser(userID string) {
+	if c == nil {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for _, element := range c.entries {
+		if element.Value.(*sessionCacheEntry).session.UserID == userID {
+			c.removeElement(element)
+		}
+	}
+}
+
+// Len returns the number of cached entries, including stale ones not yet evicted
+func (c *SessionCache) Len() int {
+	if c == nil {
+		return 0
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.order.Len()
+}
+
+func (c *SessionCache) removeElement(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*sessionCacheEntry).sessionID)
+}
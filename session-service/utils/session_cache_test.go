@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"session-service/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewSessionCacheDisabled tests that a non-positive size or TTL disables the cache
+func TestNewSessionCacheDisabled(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxEntries int
+		ttl        time.Duration
+	}{
+		{name: "zero size", maxEntries: 0, ttl: time.Minute},
+		{name: "zero ttl", maxEntries: 10, ttl: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := NewSessionCache(tt.maxEntries, tt.ttl)
+			assert.Nil(t, cache)
+
+			// A disabled cache is safe to use and never hits
+			cache.Set(&models.SessionData{SessionID: "session-1"})
+			_, ok := cache.Get("session-1")
+			assert.False(t, ok)
+			assert.Equal(t, 0, cache.Len())
+		})
+	}
+}
+
+// TestSessionCacheEvictsLeastRecentlyUsed tests LRU eviction when the cache is full
+func TestSessionCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewSessionCache(2, time.Minute)
+	require.NotNil(t, cache)
+
+	cache.Set(&models.SessionData{SessionID: "session-1"})
+	cache.Set(&models.SessionData{SessionID: "session-2"})
+
+	// Touch session-1 so session-2 becomes the least recently used
+	_, ok := cache.Get("session-1")
+	require.True(t, ok)
+
+	cache.Set(&models.SessionData{SessionID: "session-3"})
+
+	assert.Equal(t, 2, cache.Len())
+	_, ok = cache.Get("session-2")
+	assert.False(t, ok)
+	_, ok = cache.Get("session-1")
+	assert.True(t, ok)
+	_, ok = cache.Get("session-3")
+	assert.True(t, ok)
+}
+
+// TestSessionCacheExpiresEntries tests that entries are dropped after the TTL
+func TestSessionCacheExpiresEntries(t *testing.T) {
+	cache := NewSessionCache(10, time.Minute)
+	require.NotNil(t, cache)
+
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	cache.Set(&models.SessionData{SessionID: "session-1"})
+
+	_, ok := cache.Get("session-1")
+	assert.True(t, ok)
+
+	now = now.Add(time.Minute + time.Second)
+	_, ok = cache.Get("session-1")
+	assert.False(t, ok)
+	assert.Equal(t, 0, cache.Len())
+}
+
+// TestSessionCacheSetKeepsExpiry tests that refreshing a cached entry does not extend its TTL
+func TestSessionCacheSetKeepsExpiry(t *testing.T) {
+	cache := NewSessionCache(10, time.Minute)
+	require.NotNil(t, cache)
+
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	cache.Set(&models.SessionData{SessionID: "session-1", Username: "before"})
+
+	now = now.Add(40 * time.Second)
+	cache.Set(&models.SessionData{SessionID: "session-1", Username: "after"})
+	session, ok := cache.Get("session-1")
+	require.True(t, ok)
+	assert.Equal(t, "after", session.Username)
+
+	now = now.Add(40 * time.Second)
+	_, ok = cache.Get("session-1")
+	assert.False(t, ok)
+}
+
+// TestSessionCacheReturnsCopies tests that callers cannot mutate cached sessions in place
+func TestSessionCacheReturnsCopies(t *testing.T) {
+	cache := NewSessionCache(10, time.Minute)
+	require.NotNil(t, cache)
+
+	session := &models.SessionData{SessionID: "session-1", IsActive: true}
+	cache.Set(session)
+	session.IsActive = false
+
+	cached, ok := cache.Get("session-1")
+	require.True(t, ok)
+	assert.True(t, cached.IsActive)
+
+	cached.IsActive = false
+	cachedAgain, ok := cache.Get("session-1")
+	require.True(t, ok)
+	assert.True(t, cachedAgain.IsActive)
+}
+
+// TestSessionCacheDeleteUser tests evicting all sessions of one user
+func TestSessionCacheDeleteUser(t *testing.T) {
+	cache := NewSessionCache(10, time.Minute)
+	require.NotNil(t, cache)
+
+	cache.Set(&models.SessionData{SessionID: "session-1", UserID: "user-1"})
+	cache.Set(&models.SessionData{SessionID: "session-2", UserID: "user-1"})
+	cache.Set(&models.SessionData{SessionID: "session-3", UserID: "user-2"})
+
+	cache.DeleteUser("user-1")
+
+	assert.Equal(t, 1, cache.Len())
+	_, ok := cache.Get("session-3")
+	assert.True(t, ok)
+}
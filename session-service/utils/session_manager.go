@@ -5,6 +5,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -43,6 +44,7 @@ type SessionManager struct {
 
 	// Storage
 	storage SessionStorage
+	cache   *SessionCache // nil when caching is disabled
 
 	// Synchronization
 	mutex      sync.RWMutex
@@ -69,6 +71,8 @@ type SessionStorage interface {
 type ExtendedSessionStorage interface {
 	SessionStorage
 	CleanupUserExpiredSessions(userID string) error
+	GetSessionStats() (*models.SessionStats, error)
+	ListUserSessions(userID string, opts models.SessionListOptions) ([]*models.SessionData, int, error)
 }
 
 // SessionMetrics tracks basic session-related metrics
@@ -90,6 +94,7 @@ func NewSessionManager(jwtManager *JWTManager, config *models.SessionConfig, sto
 		logger:     logger,
 		config:     config,
 		storage:    storage,
+		cache:      NewSessionCache(config.CacheSize, config.CacheTTL),
 		metrics:    &SessionMetrics{},
 	}
 
@@ -99,6 +104,8 @@ func NewSessionManager(jwtManager *JWTManager, config *models.SessionConfig, sto
 	logger.WithFields(logrus.Fields{
 		"max_sessions":     config.MaxConcurrentSessions,
 		"cleanup_interval": config.CleanupInterval,
+		"cache_size":       config.CacheSize,
+		"cache_ttl":        config.CacheTTL,
 		"storage_type":     "database",
 	}).Info("Session manager initialized with database storage")
 
@@ -203,19 +210,23 @@ func (sm *SessionManager) ValidateSession(req *models.SessionValidationRequest)
 		}, nil
 	}
 
-	// Use session ID from JWT claims to retrieve session from database
-	session, err = sm.storage.Get(claims.SessionID)
-
-	if err != nil {
-		return &models.SessionValidationResponse{
-			IsValid:      false,
-			ErrorCode:    "session_not_found",
-			ErrorMessage: "Session not found",
-		}, nil
+	// Use session ID from JWT claims to retrieve session, from the cache when it is hot
+	session, cached := sm.cache.Get(claims.SessionID)
+	if !cached {
+		session, err = sm.storage.Get(claims.SessionID)
+		if err != nil {
+			sm.jwtManager.recordValidationFailure(JWTFailureRevoked)
+			return &models.SessionValidationResponse{
+				IsValid:      false,
+				ErrorCode:    "session_not_found",
+				ErrorMessage: "Session not found",
+			}, nil
+		}
 	}
 
 	// Check session validity
 	if !session.IsActive {
+		sm.jwtManager.recordValidationFailure(JWTFailureRevoked)
 		return &models.SessionValidationResponse{
 			IsValid:      false,
 			ErrorCode:    "session_inactive",
@@ -223,10 +234,35 @@ func (sm *SessionManager) ValidateSession(req *models.SessionValidationRequest)
 		}, nil
 	}
 
-	// Update session activity
+	// Sessions left untouched longer than the idle window expire even if the JWT has not
 	now := time.Now().UTC() // Use UTC to avoid timezone issues
+	if sm.config.IdleTimeout > 0 && now.Sub(session.LastActivity) > sm.config.IdleTimeout {
+		sm.expireSession(session.SessionID)
+		sm.jwtManager.recordValidationFailure(JWTFailureExpired)
+
+		sm.logger.WithFields(logrus.Fields{
+			"session_id":        session.SessionID,
+			"user_id":           session.UserID,
+			"last_activity_utc": session.LastActivity.UTC().Format("2006-01-02 15:04:05 UTC"),
+			"idle_timeout":      sm.config.IdleTimeout,
+		}).Info("Session expired due to inactivity")
+
+		return &models.SessionValidationResponse{
+			IsValid:      false,
+			ErrorCode:    "session_idle_timeout",
+			ErrorMessage: "Session expired due to inactivity",
+		}, nil
+	}
+
+	// Persist activity once the stored value is older than the cache TTL, whether the session
+	// came from the cache or from storage. The cached copy mirrors what storage holds, so a hot
+	// session is written at most once per TTL and never looks idle to other instances
+	stored := *session
+	if now.Sub(session.LastActivity) > sm.config.CacheTTL {
+		stored.LastActivity = now
+		sm.storage.Update(session.SessionID, &stored)
+	}
 	session.LastActivity = now
-	sm.storage.Update(session.SessionID, session)
 
 	// Check if token needs refresh
 	response := &models.SessionValidationResponse{
@@ -244,9 +280,12 @@ func (sm *SessionManager) ValidateSession(req *models.SessionValidationRequest)
 			response.NewToken = newToken
 			session.ExpiresAt = newExp
 			sm.storage.Update(session.SessionID, session)
+			stored = *session
 		}
 	}
 
+	sm.cache.Set(&stored)
+
 	return response, nil
 }
 
@@ -254,6 +293,7 @@ func (sm *SessionManager) ValidateSession(req *models.SessionValidationRequest)
 func (sm *SessionManager) RevokeSession(req *models.SessionRevokeRequest) error {
 	if req.RevokeAll && req.UserID != "" {
 		// Revoke all user sessions
+		sm.cache.DeleteUser(req.UserID)
 		return sm.storage.DeleteUserSessions(req.UserID)
 	}
 
@@ -273,6 +313,7 @@ func (sm *SessionManager) RevokeSession(req *models.SessionRevokeRequest) error
 		return fmt.Errorf("either session_id or token must be provided")
 	}
 
+	sm.cache.Delete(sessionID)
 	return sm.storage.Delete(sessionID)
 }
 
@@ -302,20 +343,86 @@ func (sm *SessionManager) GetUserSessions(userID string, currentSessionID string
 	return summaries, nil
 }
 
-// GetSessionStats returns basic analytics about sessions
-func (sm *SessionManager) GetSessionStats() *models.SessionStats {
+// ListUserSessions returns one page of a user's sessions, newest first, along with the total number matching the filter
+func (sm *SessionManager) ListUserSessions(userID string, currentSessionID string, opts models.SessionListOptions) ([]*models.SessionSummary, int, error) {
+	if extStorage, ok := sm.storage.(ExtendedSessionStorage); ok {
+		sessions, total, err := extStorage.ListUserSessions(userID, opts)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to list user sessions: %w", err)
+		}
+
+		summaries := make([]*models.SessionSummary, 0, len(sessions))
+		for _, session := range sessions {
+			summaries = append(summaries, sm.sessionSummary(session, currentSessionID))
+		}
+		return summaries, total, nil
+	}
+
+	// Fallback: filter and page in memory
+	sessions, err := sm.storage.GetUserSessions(userID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get user sessions: %w", err)
+	}
+
+	summaries := make([]*models.SessionSummary, 0, len(sessions))
+	for _, session := range sessions {
+		if opts.ActiveOnly && !session.IsActive {
+			continue
+		}
+
+		summaries = append(summaries, sm.sessionSummary(session, currentSessionID))
+	}
+
+	sort.SliceStable(summaries, func(i, j int) bool {
+		return summaries[i].CreatedAt.After(summaries[j].CreatedAt)
+	})
+
+	total := len(summaries)
+	if opts.Offset >= total {
+		return []*models.SessionSummary{}, total, nil
+	}
+
+	end := total
+	if opts.Limit > 0 && opts.Offset+opts.Limit < total {
+		end = opts.Offset + opts.Limit
+	}
+
+	return summaries[opts.Offset:end], total, nil
+}
+
+// GetSessionStats returns analytics about sessions, aggregated by the storage when it supports it
+func (sm *SessionManager) GetSessionStats() (*models.SessionStats, error) {
+	if extStorage, ok := sm.storage.(ExtendedSessionStorage); ok {
+		stats, err := extStorage.GetSessionStats()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get session stats: %w", err)
+		}
+		return stats, nil
+	}
+
+	// Fallback: in-process counters only
 	sm.metrics.mutex.RLock()
 	defer sm.metrics.mutex.RUnlock()
 
 	return &models.SessionStats{
-		TotalSessions:   int(sm.metrics.TotalSessions),
-		ActiveSessions:  int(sm.metrics.ActiveSessions),
-		ExpiredSessions: 0, // Can be calculated if needed
-	}
+		TotalSessions:  int(sm.metrics.TotalSessions),
+		ActiveSessions: int(sm.metrics.ActiveSessions),
+		SessionsByRole: map[string]int{},
+	}, nil
 }
 
 // Helper methods
 
+func (sm *SessionManager) sessionSummary(session *models.SessionData, currentSessionID string) *models.SessionSummary {
+	return &models.SessionSummary{
+		SessionID:    session.SessionID,
+		CreatedAt:    session.CreatedAt,
+		LastActivity: session.LastActivity,
+		IsActive:     session.IsActive,
+		IsCurrent:    session.SessionID == currentSessionID,
+	}
+}
+
 func (sm *SessionManager) generateSessionID() string {
 	bytes := make([]byte, 32)
 	rand.Read(bytes)
@@ -358,6 +465,7 @@ func (sm *SessionManager) checkConcurrentSessions(userID string) error {
 		}
 
 		if oldestSession != nil {
+			sm.cache.Delete(oldestSession.SessionID)
 			sm.storage.Delete(oldestSession.SessionID)
 		}
 	}
@@ -372,6 +480,7 @@ func (sm *SessionManager) expireSession(sessionID string) {
 	}
 
 	session.IsActive = false
+	sm.cache.Delete(sessionID)
 	sm.storage.Update(sessionID, session)
 
 	sm.updateMetrics(func(m *SessionMetrics) {
@@ -396,6 +505,7 @@ func (sm *SessionManager) refreshSessionToken(session *models.SessionData) (stri
 	if err != nil {
 		return "", time.Time{}, err
 	}
+	sm.jwtManager.recordRefreshRotation()
 
 	// Update session with new token
 	session.TokenHash = sm.hashToken(newToken)
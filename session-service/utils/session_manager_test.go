@@ -0,0 +1,318 @@
+package utils
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"session-service/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingSessionStorage is an in-memory SessionStorage that counts Get calls
+type countingSessionStorage struct {
+	mutex    sync.Mutex
+	sessions map[string]*models.SessionData
+	getCalls int
+}
+
+func newCountingSessionStorage() *countingSessionStorage {
+	return &countingSessionStorage{sessions: make(map[string]*models.SessionData)}
+}
+
+func (s *countingSessionStorage) Store(sessionID string, session *models.SessionData) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	copied := *session
+	s.sessions[sessionID] = &copied
+	return nil
+}
+
+func (s *countingSessionStorage) Get(sessionID string) (*models.SessionData, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.getCalls++
+	session, ok := s.sessions[sessionID]
+	if !ok {
+		return nil, fmt.Errorf("session not found")
+	}
+	copied := *session
+	return &copied, nil
+}
+
+func (s *countingSessionStorage) GetByTokenHash(tokenHash string) (*models.SessionData, error) {
+	return nil, fmt.Errorf("session not found")
+}
+
+func (s *countingSessionStorage) GetUserSessions(userID string) ([]*models.SessionData, error) {
+	return nil, nil
+}
+
+func (s *countingSessionStorage) Update(sessionID string, session *models.SessionData) error {
+	return s.Store(sessionID, session)
+}
+
+func (s *countingSessionStorage) Delete(sessionID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.sessions, sessionID)
+	return nil
+}
+
+func (s *countingSessionStorage) DeleteUserSessions(userID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for id, session := range s.sessions {
+		if session.UserID == userID {
+			delete(s.sessions, id)
+		}
+	}
+	return nil
+}
+
+func (s *countingSessionStorage) GetAllSessions() ([]*models.SessionData, error) {
+	return nil, nil
+}
+
+func (s *countingSessionStorage) Cleanup() error {
+	return nil
+}
+
+func (s *countingSessionStorage) gets() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.getCalls
+}
+
+// setupCachedSessionManager creates a session manager with caching enabled and one stored session
+func setupCachedSessionManager(t *testing.T) (*SessionManager, *countingSessionStorage, string) {
+	return setupSessionManagerWithActivity(t, time.Now().UTC())
+}
+
+// setupSessionManagerWithActivity creates a session manager whose stored session was last used at lastActivity
+func setupSessionManagerWithActivity(t *testing.T, lastActivity time.Time) (*SessionManager, *countingSessionStorage, string) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	config := models.DefaultSessionConfig()
+	config.CleanupInterval = time.Hour
+	config.CacheSize = 10
+	config.CacheTTL = time.Minute
+	config.IdleTimeout = time.Hour
+
+	storage := newCountingSessionStorage()
+	jwtManager := NewJWTManager("test-secret-key", 30*time.Minute, logger)
+	sm := NewSessionManager(jwtManager, config, storage, logger)
+
+	token, expiresAt, err := jwtManager.GenerateToken(createTestUserProfile(), "session-123")
+	require.NoError(t, err)
+	require.NoError(t, storage.Store("session-123", &models.SessionData{
+		SessionID:    "session-123",
+		UserID:       "user-123",
+		Username:     "testuser",
+		TokenHash:    sm.hashToken(token),
+		CreatedAt:    time.Now().UTC(),
+		ExpiresAt:    expiresAt,
+		LastActivity: lastActivity,
+		IsActive:     true,
+	}))
+
+	return sm, storage, token
+}
+
+// TestValidateSessionCacheHitSkipsStorage tests that a hot session validates without a storage lookup
+func TestValidateSessionCacheHitSkipsStorage(t *testing.T) {
+	sm, storage, token := setupCachedSessionManager(t)
+
+	response, err := sm.ValidateSession(&models.SessionValidationRequest{Token: token})
+	require.NoError(t, err)
+	require.True(t, response.IsValid)
+	assert.Equal(t, 1, storage.gets())
+
+	response, err = sm.ValidateSession(&models.SessionValidationRequest{Token: token})
+	require.NoError(t, err)
+	assert.True(t, response.IsValid)
+	assert.Equal(t, "session-123", response.SessionData.SessionID)
+	assert.Equal(t, 1, storage.gets(), "second validation should be served from the cache")
+}
+
+// TestValidateSessionHotEntryExpires tests that a session validated constantly is still reloaded once per cache TTL
+func TestValidateSessionHotEntryExpires(t *testing.T) {
+	sm, storage, token := setupCachedSessionManager(t)
+
+	now := time.Now()
+	sm.cache.now = func() time.Time { return now }
+
+	for _, step := range []struct {
+		advance      time.Duration
+		expectedGets int
+	}{
+		{advance: 0, expectedGets: 1},
+		{advance: 40 * time.Second, expectedGets: 1},
+		{advance: 40 * time.Second, expectedGets: 2},
+	} {
+		now = now.Add(step.advance)
+		response, err := sm.ValidateSession(&models.SessionValidationRequest{Token: token})
+		require.NoError(t, err)
+		require.True(t, response.IsValid)
+		assert.Equal(t, step.expectedGets, storage.gets())
+	}
+}
+
+// TestValidateSessionPersistsStaleActivity tests that last activity older than the cache TTL is written back on hits and misses
+func TestValidateSessionPersistsStaleActivity(t *testing.T) {
+	tests := []struct {
+		name           string
+		lastActivity   time.Duration
+		cached         bool
+		expectedUpdate bool
+	}{
+		{name: "miss with stale activity", lastActivity: 2 * time.Minute, expectedUpdate: true},
+		{name: "hit with stale activity", lastActivity: 2 * time.Minute, cached: true, expectedUpdate: true},
+		{name: "miss with recent activity", lastActivity: 10 * time.Second},
+		{name: "hit with recent activity", lastActivity: 10 * time.Second, cached: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lastActivity := time.Now().UTC().Add(-tt.lastActivity)
+			sm, storage, token := setupSessionManagerWithActivity(t, lastActivity)
+			if tt.cached {
+				session, err := storage.Get("session-123")
+				require.NoError(t, err)
+				sm.cache.Set(session)
+			}
+			gets := storage.gets()
+
+			response, err := sm.ValidateSession(&models.SessionValidationRequest{Token: token})
+			require.NoError(t, err)
+			require.True(t, response.IsValid)
+			if tt.cached {
+				assert.Equal(t, gets, storage.gets(), "validation should be served from the cache")
+			}
+
+			stored, err := storage.Get("session-123")
+			require.NoError(t, err)
+			if tt.expectedUpdate {
+				assert.WithinDuration(t, time.Now().UTC(), stored.LastActivity, 5*time.Second)
+			} else {
+				assert.True(t, lastActivity.Equal(stored.LastActivity))
+			}
+		})
+	}
+}
+
+// TestRevokeSessionEvictsCache tests that revocation removes the cached session
+func TestRevokeSessionEvictsCache(t *testing.T) {
+	tests := []struct {
+		name    string
+		request *models.SessionRevokeRequest
+	}{
+		{
+			name:    "revoke by session id",
+			request: &models.SessionRevokeRequest{SessionID: "session-123"},
+		},
+		{
+			name:    "revoke all user sessions",
+			request: &models.SessionRevokeRequest{UserID: "user-123", RevokeAll: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sm, storage, token := setupCachedSessionManager(t)
+
+			response, err := sm.ValidateSession(&models.SessionValidationRequest{Token: token})
+			require.NoError(t, err)
+			require.True(t, response.IsValid)
+			require.Equal(t, 1, sm.cache.Len())
+
+			require.NoError(t, sm.RevokeSession(tt.request))
+			assert.Equal(t, 0, sm.cache.Len())
+
+			response, err = sm.ValidateSession(&models.SessionValidationRequest{Token: token})
+			require.NoError(t, err)
+			assert.False(t, response.IsValid)
+			assert.Equal(t, "session_not_found", response.ErrorCode)
+			assert.Equal(t, 2, storage.gets())
+			assert.Equal(t, int64(1), sm.jwtManager.Metrics().ValidationFailures[JWTFailureRevoked])
+		})
+	}
+}
+
+// TestValidateSessionIdleTimeout tests that sessions untouched past the idle window fail validation
+func TestValidateSessionIdleTimeout(t *testing.T) {
+	tests := []struct {
+		name          string
+		lastActivity  time.Duration
+		expectedValid bool
+	}{
+		{name: "recently used", lastActivity: 10 * time.Minute, expectedValid: true},
+		{name: "idle past the window", lastActivity: 2 * time.Hour, expectedValid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lastActivity := time.Now().UTC().Add(-tt.lastActivity)
+			sm, storage, token := setupSessionManagerWithActivity(t, lastActivity)
+
+			response, err := sm.ValidateSession(&models.SessionValidationRequest{Token: token})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedValid, response.IsValid)
+
+			stored, err := storage.Get("session-123")
+			require.NoError(t, err)
+
+			if tt.expectedValid {
+				// A successful validation records the activity
+				assert.True(t, stored.LastActivity.After(lastActivity))
+				return
+			}
+
+			assert.Equal(t, "session_idle_timeout", response.ErrorCode)
+			assert.False(t, stored.IsActive)
+			assert.Equal(t, 0, sm.cache.Len())
+			assert.Equal(t, int64(1), sm.jwtManager.Metrics().ValidationFailures[JWTFailureExpired])
+
+			// The session stays expired on later attempts
+			response, err = sm.ValidateSession(&models.SessionValidationRequest{Token: token})
+			require.NoError(t, err)
+			assert.False(t, response.IsValid)
+		})
+	}
+}
+
+// TestListUserSessionsUsesStoragePaging tests that database storage pages the sessions instead of loading them all
+func TestListUserSessionsUsesStoragePaging(t *testing.T) {
+	storage, mock, cleanup := setupTestDatabaseStorage(t)
+	defer cleanup()
+
+	config := models.DefaultSessionConfig()
+	config.CleanupInterval = time.Hour
+	sm := NewSessionManager(NewJWTManager("test-secret-key", 30*time.Minute, storage.logger), config, storage, storage.logger)
+
+	now := time.Now().UTC()
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) as session_count").
+		WithArgs("user-1", true).
+		WillReturnRows(sqlmock.NewRows([]string{"session_count"}).AddRow(120))
+	mock.ExpectQuery("FROM sessions").
+		WithArgs("user-1", true, 1, 0).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"session_id", "user_id", "username", "role_name", "permissions", "token_hash",
+			"created_at", "expires_at", "last_activity", "is_active",
+		}).AddRow("session-current", "user-1", "admin", "admin", "{}", "hash", now, now.Add(time.Hour), now, true))
+
+	summaries, total, err := sm.ListUserSessions("user-1", "session-current", models.SessionListOptions{Limit: 1, ActiveOnly: true})
+	require.NoError(t, err)
+
+	assert.Equal(t, 120, total)
+	require.Len(t, summaries, 1)
+	assert.Equal(t, "session-current", summaries[0].SessionID)
+	assert.True(t, summaries[0].IsCurrent)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
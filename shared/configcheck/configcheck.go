@@ -0,0 +1,117 @@
+// Package configcheck validates service configuration, collecting every problem so
+// they are all reported at once instead of one per failed start
+package configcheck
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SSLModes are the PostgreSQL sslmode values a service accepts
+var SSLModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+// ValidationError lists every problem found in a configuration
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid configuration: %s", strings.Join(e.Problems, "; "))
+}
+
+// Validator collects configuration problems. The zero value is ready to use
+type Validator struct {
+	problems []string
+}
+
+// Addf records a problem
+func (v *Validator) Addf(format string, args ...interface{}) {
+	v.problems = append(v.problems, fmt.Sprintf(format, args...))
+}
+
+// Port requires value to be a TCP port number
+func (v *Validator) Port(key, value string) {
+	port, err := strconv.Atoi(value)
+	if err != nil || port < 1 || port > 65535 {
+		v.Addf("%s must be a port between 1 and 65535, got %q", key, value)
+	}
+}
+
+// Required requires value to be non-empty
+func (v *Validator) Required(key, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.Addf("%s is required", key)
+	}
+}
+
+// OneOf requires value to be one of allowed
+func (v *Validator) OneOf(key, value string, allowed ...string) {
+	for _, candidate := range allowed {
+		if value == candidate {
+			return
+		}
+	}
+	v.Addf("%s must be one of %s, got %q", key, strings.Join(allowed, ", "), value)
+}
+
+// NonNegative requires a number to be zero or greater
+func (v *Validator) NonNegative(key string, value float64) {
+	if value < 0 {
+		v.Addf("%s must not be negative, got %v", key, value)
+	}
+}
+
+// The Env* checks catch values the getEnv helpers could not parse and silently replaced with defaults
+
+// EnvInt requires the environment variable, when set, to be an integer
+func (v *Validator) EnvInt(key string) {
+	v.envParses(key, "an integer", func(value string) error {
+		_, err := strconv.Atoi(value)
+		return err
+	})
+}
+
+// EnvFloat requires the environment variable, when set, to be a number
+func (v *Validator) EnvFloat(key string) {
+	v.envParses(key, "a number", func(value string) error {
+		_, err := strconv.ParseFloat(value, 64)
+		return err
+	})
+}
+
+// EnvBool requires the environment variable, when set, to be a boolean
+func (v *Validator) EnvBool(key string) {
+	v.envParses(key, "true or false", func(value string) error {
+		_, err := strconv.ParseBool(value)
+		return err
+	})
+}
+
+// EnvDuration requires the environment variable, when set, to be a Go duration such as 30s
+func (v *Validator) EnvDuration(key string) {
+	v.envParses(key, "a duration such as 30s or 5m", func(value string) error {
+		_, err := time.ParseDuration(value)
+		return err
+	})
+}
+
+func (v *Validator) envParses(key, expected string, parse func(string) error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return
+	}
+	if err := parse(value); err != nil {
+		v.Addf("%s must be %s, got %q", key, expected, value)
+	}
+}
+
+// Err returns a ValidationError listing the problems found, or nil when there are none
+func (v *Validator) Err() error {
+	if len(v.problems) == 0 {
+		return nil
+	}
+	return &ValidationError{Problems: v.problems}
+}
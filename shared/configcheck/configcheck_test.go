@@ -0,0 +1,93 @@
+package configcheck
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestValidator tests each check and that problems are aggregated into one error
+func TestValidator(t *testing.T) {
+	testCases := map[string]struct {
+		check    func(v *Validator)
+		expected []string
+	}{
+		"valid values": {
+			check: func(v *Validator) {
+				v.Port("PORT", "8080")
+				v.Required("SECRET", "s3cret")
+				v.OneOf("SSL_MODE", "require", SSLModes...)
+				v.NonNegative("RATE", 0)
+			},
+		},
+		"port out of range": {
+			check:    func(v *Validator) { v.Port("PORT", "-1") },
+			expected: []string{`PORT must be a port between 1 and 65535, got "-1"`},
+		},
+		"port not a number": {
+			check:    func(v *Validator) { v.Port("PORT", "http") },
+			expected: []string{`PORT must be a port between 1 and 65535, got "http"`},
+		},
+		"missing required value": {
+			check:    func(v *Validator) { v.Required("SECRET", " ") },
+			expected: []string{"SECRET is required"},
+		},
+		"value outside the allowed set": {
+			check:    func(v *Validator) { v.OneOf("SSL_MODE", "sometimes", SSLModes...) },
+			expected: []string{`SSL_MODE must be one of disable, allow, prefer, require, verify-ca, verify-full, got "sometimes"`},
+		},
+		"problems are aggregated": {
+			check: func(v *Validator) {
+				v.Port("PORT", "70000")
+				v.Required("SECRET", "")
+				v.NonNegative("RATE", -1)
+			},
+			expected: []string{
+				`PORT must be a port between 1 and 65535, got "70000"`,
+				"SECRET is required",
+				"RATE must not be negative, got -1",
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			v := &Validator{}
+			tc.check(v)
+
+			err := v.Err()
+			if tc.expected == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			var validationErr *ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, tc.expected, validationErr.Problems)
+		})
+	}
+}
+
+// TestValidatorEnvParsing tests that unparsable environment values are reported instead of defaulted
+func TestValidatorEnvParsing(t *testing.T) {
+	t.Setenv("TEST_CONFIG_INT", "ten")
+	t.Setenv("TEST_CONFIG_FLOAT", "1.5")
+	t.Setenv("TEST_CONFIG_BOOL", "maybe")
+	t.Setenv("TEST_CONFIG_DURATION", "10")
+
+	v := &Validator{}
+	v.EnvInt("TEST_CONFIG_INT")
+	v.EnvFloat("TEST_CONFIG_FLOAT")
+	v.EnvBool("TEST_CONFIG_BOOL")
+	v.EnvDuration("TEST_CONFIG_DURATION")
+	v.EnvInt("TEST_CONFIG_UNSET")
+
+	var validationErr *ValidationError
+	require.ErrorAs(t, v.Err(), &validationErr)
+	assert.Equal(t, []string{
+		`TEST_CONFIG_INT must be an integer, got "ten"`,
+		`TEST_CONFIG_BOOL must be true or false, got "maybe"`,
+		`TEST_CONFIG_DURATION must be a duration such as 30s or 5m, got "10"`,
+	}, validationErr.Problems)
+}
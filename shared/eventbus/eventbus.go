@@ -0,0 +1,69 @@
+// Package eventbus is the in-process domain event bus the services publish through
+package eventbus
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Type identifies a kind of domain event
+type Type string
+
+// Event is a domain event published after a change has been committed
+type Event interface {
+	EventType() Type
+	OccurredAt() time.Time
+}
+
+// Handler reacts to a published event
+type Handler func(Event)
+
+// Bus delivers domain events to in-process subscribers. Publish returns
+// immediately; each handler runs in its own goroutine so a slow subscriber
+// never holds up the request that published the event.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[Type][]Handler
+	logger   *logrus.Logger
+}
+
+// New creates an empty event bus
+func New(logger *logrus.Logger) *Bus {
+	return &Bus{
+		handlers: make(map[Type][]Handler),
+		logger:   logger,
+	}
+}
+
+// Subscribe registers a handler for every event of the given type
+func (b *Bus) Subscribe(eventType Type, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[eventType] = append(b.handlers[eventType], handler)
+}
+
+// Publish dispatches an event to the handlers subscribed to its type
+func (b *Bus) Publish(event Event) {
+	b.mu.RLock()
+	handlers := b.handlers[event.EventType()]
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		go b.dispatch(handler, event)
+	}
+}
+
+// dispatch runs a single handler, keeping a panicking subscriber from taking down the service
+func (b *Bus) dispatch(handler Handler, event Event) {
+	defer func() {
+		if r := recover(); r != nil && b.logger != nil {
+			b.logger.WithFields(logrus.Fields{
+				"event_type": event.EventType(),
+				"panic":      r,
+			}).Error("Event handler panicked")
+		}
+	}()
+	handler(event)
+}
@@ -0,0 +1,129 @@
+package eventbus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testEvent is a minimal domain event for exercising the bus
+type testEvent struct {
+	eventType Type
+	id        int
+}
+
+func (e testEvent) EventType() Type       { return e.eventType }
+func (e testEvent) OccurredAt() time.Time { return time.Time{} }
+
+// newTestBus creates a bus with a silenced logger
+func newTestBus() *Bus {
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	return New(logger)
+}
+
+// TestEventBusDelivery tests that events reach only the handlers subscribed to their type
+func TestEventBusDelivery(t *testing.T) {
+	tests := map[string]struct {
+		subscriptions []Type
+		published     Type
+		expected      int
+	}{
+		"single subscriber": {
+			subscriptions: []Type{"thing.created"},
+			published:     "thing.created",
+			expected:      1,
+		},
+		"every subscriber of the type": {
+			subscriptions: []Type{"thing.created", "thing.created", "thing.deleted"},
+			published:     "thing.created",
+			expected:      2,
+		},
+		"no subscriber for the type": {
+			subscriptions: []Type{"thing.deleted"},
+			published:     "thing.created",
+			expected:      0,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			bus := newTestBus()
+			received := make(chan Event, len(tc.subscriptions))
+			for _, eventType := range tc.subscriptions {
+				bus.Subscribe(eventType, func(event Event) { received <- event })
+			}
+
+			bus.Publish(testEvent{eventType: tc.published, id: 1})
+
+			for i := 0; i < tc.expected; i++ {
+				select {
+				case event := <-received:
+					assert.Equal(t, testEvent{eventType: tc.published, id: 1}, event)
+				case <-time.After(time.Second):
+					t.Fatalf("expected %d deliveries, got %d", tc.expected, i)
+				}
+			}
+			select {
+			case event := <-received:
+				t.Fatalf("unexpected delivery: %v", event)
+			case <-time.After(20 * time.Millisecond):
+			}
+		})
+	}
+}
+
+// TestEventBusSlowSubscriberDoesNotBlock tests that publishing returns while a subscriber is still busy
+func TestEventBusSlowSubscriberDoesNotBlock(t *testing.T) {
+	bus := newTestBus()
+
+	release := make(chan struct{})
+	defer close(release)
+	bus.Subscribe("thing.created", func(Event) { <-release })
+
+	fast := make(chan Event, 10)
+	bus.Subscribe("thing.created", func(event Event) { fast <- event })
+
+	published := make(chan struct{})
+	go func() {
+		for i := 0; i < 10; i++ {
+			bus.Publish(testEvent{eventType: "thing.created", id: i})
+		}
+		close(published)
+	}()
+
+	select {
+	case <-published:
+	case <-time.After(time.Second):
+		t.Fatal("Publish blocked on a slow subscriber")
+	}
+
+	for i := 0; i < 10; i++ {
+		select {
+		case <-fast:
+		case <-time.After(time.Second):
+			t.Fatalf("fast subscriber received %d of 10 events", i)
+		}
+	}
+}
+
+// TestEventBusRecoversFromPanickingHandler tests that a panicking handler does not affect other handlers
+func TestEventBusRecoversFromPanickingHandler(t *testing.T) {
+	bus := newTestBus()
+
+	received := make(chan Event, 1)
+	bus.Subscribe("thing.created", func(Event) { panic("boom") })
+	bus.Subscribe("thing.created", func(event Event) { received <- event })
+
+	bus.Publish(testEvent{eventType: "thing.created"})
+
+	select {
+	case event := <-received:
+		require.Equal(t, Type("thing.created"), event.EventType())
+	case <-time.After(time.Second):
+		t.Fatal("healthy handler did not receive the event")
+	}
+}
@@ -0,0 +1,33 @@
+package httpx
+
+import (
+	"net/http"
+)
+
+// UserRoleHeader carries the role of the authenticated user; the gateway sets it after validating the session
+const UserRoleHeader = "X-User-Role"
+
+// AdminRoles are the roles allowed on administrative endpoints
+var AdminRoles = []string{"super_admin", "admin"}
+
+// IsAdminRole reports whether role is one of AdminRoles
+func IsAdminRole(role string) bool {
+	for _, admin := range AdminRoles {
+		if role == admin {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireAdminMiddleware answers requests whose user role is not an admin role with a JSON 403.
+// The role comes from the gateway, which handles authentication for every service
+func RequireAdminMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !IsAdminRole(r.Header.Get(UserRoleHeader)) {
+			WriteRouteError(w, http.StatusForbidden, "forbidden", r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
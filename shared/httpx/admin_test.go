@@ -0,0 +1,43 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRequireAdminMiddleware tests that only admin roles reach the wrapped handler
+func TestRequireAdminMiddleware(t *testing.T) {
+	handler := RequireAdminMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	testCases := map[string]struct {
+		role           string
+		expectedStatus int
+	}{
+		"super_admin passes": {role: "super_admin", expectedStatus: http.StatusNoContent},
+		"admin passes":       {role: "admin", expectedStatus: http.StatusNoContent},
+		"cashier is denied":  {role: "cashier", expectedStatus: http.StatusForbidden},
+		"no role is denied":  {expectedStatus: http.StatusForbidden},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/config", nil)
+			if tc.role != "" {
+				req.Header.Set(UserRoleHeader, tc.role)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			if tc.expectedStatus == http.StatusForbidden {
+				assert.Contains(t, rec.Body.String(), `"error":"forbidden"`)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package httpx
+
+import (
+	"mime"
+	"net/http"
+)
+
+// RequireJSONMiddleware rejects POST, PUT and PATCH requests whose body is not declared as
+// application/json with a JSON 415, so a form post fails clearly instead of in the JSON decoder.
+// Requests without a body pass through, since some write endpoints take no input
+func RequireJSONMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch:
+		default:
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if r.ContentLength == 0 || r.Body == nil || r.Body == http.NoBody {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil || mediaType != "application/json" {
+			WriteRouteError(w, http.StatusUnsupportedMediaType, "unsupported_media_type", r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,83 @@
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRequireJSONMiddleware tests that write requests must declare a JSON body
+func TestRequireJSONMiddleware(t *testing.T) {
+	router := mux.NewRouter()
+	router.HandleFunc("/items", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}).Methods("GET", "POST", "PUT", "PATCH")
+	router.Use(RequireJSONMiddleware)
+
+	testCases := map[string]struct {
+		method         string
+		contentType    string
+		body           string
+		expectedStatus int
+	}{
+		"JSON body passes": {
+			method:         http.MethodPost,
+			contentType:    "application/json",
+			body:           `{"name":"flour"}`,
+			expectedStatus: http.StatusNoContent,
+		},
+		"JSON with charset passes": {
+			method:         http.MethodPut,
+			contentType:    "application/json; charset=utf-8",
+			body:           `{"name":"flour"}`,
+			expectedStatus: http.StatusNoContent,
+		},
+		"form body is rejected": {
+			method:         http.MethodPost,
+			contentType:    "application/x-www-form-urlencoded",
+			body:           "name=flour",
+			expectedStatus: http.StatusUnsupportedMediaType,
+		},
+		"missing header is rejected": {
+			method:         http.MethodPatch,
+			body:           `{"name":"flour"}`,
+			expectedStatus: http.StatusUnsupportedMediaType,
+		},
+		"empty body passes without header": {
+			method:         http.MethodPost,
+			expectedStatus: http.StatusNoContent,
+		},
+		"GET is not checked": {
+			method:         http.MethodGet,
+			contentType:    "text/plain",
+			expectedStatus: http.StatusNoContent,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, "/items", strings.NewReader(tc.body))
+			if tc.contentType != "" {
+				req.Header.Set("Content-Type", tc.contentType)
+			}
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectedStatus, rr.Code)
+			if tc.expectedStatus == http.StatusUnsupportedMediaType {
+				assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+
+				var body RouteError
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+				assert.Equal(t, RouteError{Error: "unsupported_media_type", Path: "/items", Method: tc.method}, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package httpx
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/mux"
+)
+
+// GzipMinSize is the response size below which compressing costs more than it saves
+const GzipMinSize = 1024
+
+// precompressedTypes are content types whose bodies are already compressed
+var precompressedTypes = []string{
+	"image/", "video/", "audio/",
+	"application/gzip", "application/x-gzip", "application/zip", "application/zstd",
+	"application/octet-stream",
+}
+
+var gzipWriters = sync.Pool{
+	New: func() interface{} { return gzip.NewWriter(nil) },
+}
+
+// GzipMiddleware compresses responses of at least minSize bytes for clients that accept gzip.
+// The body is buffered until it reaches minSize, so small responses go out unchanged; already
+// compressed content types and WebSocket upgrades are never compressed
+func GzipMiddleware(minSize int) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Upgrade") != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Add("Vary", "Accept-Encoding")
+			if r.Method == http.MethodHead || !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			gw := &gzipResponseWriter{ResponseWriter: w, minSize: minSize}
+			defer gw.close()
+			next.ServeHTTP(gw, r)
+		})
+	}
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows gzip (a q of 0 refuses it)
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.EqualFold(strings.TrimSpace(coding), "gzip") {
+			continue
+		}
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if q, err := strconv.ParseFloat(value, 64); err == nil && q == 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// gzipResponseWriter holds back the status and body until minSize bytes have been written (or
+// the handler flushes or returns), then commits to a compressed or plain response
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	minSize   int
+	status    int
+	buf       []byte
+	committed bool
+	gz        *gzip.Writer
+}
+
+func (w *gzipResponseWriter) WriteHeader(statusCode int) {
+	if w.committed || w.status != 0 {
+		return
+	}
+	w.status = statusCode
+}
+
+func (w *gzipResponseWriter) Write(p []byte) (int, error) {
+	if w.committed {
+		if w.gz != nil {
+			return w.gz.Write(p)
+		}
+		return w.ResponseWriter.Write(p)
+	}
+
+	w.buf = append(w.buf, p...)
+	if len(w.buf) >= w.minSize {
+		if err := w.commit(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush sends what is buffered so far; streaming handlers commit the response early this way
+func (w *gzipResponseWriter) Flush() {
+	if !w.committed {
+		w.commit(len(w.buf) >= w.minSize)
+	}
+	if w.gz != nil {
+		w.gz.Flush()
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// commit writes the status and the buffered body, compressing them when allowed
+func (w *gzipResponseWriter) commit(compress bool) error {
+	w.committed = true
+
+	header := w.Header()
+	if header.Get("Content-Type") == "" && len(w.buf) > 0 {
+		header.Set("Content-Type", http.DetectContentType(w.buf))
+	}
+	if compress && header.Get("Content-Encoding") == "" && compressible(header.Get("Content-Type")) {
+		header.Del("Content-Length")
+		header.Set("Content-Encoding", "gzip")
+		w.gz = gzipWriters.Get().(*gzip.Writer)
+		w.gz.Reset(w.ResponseWriter)
+	}
+
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	if w.gz != nil {
+		_, err := w.gz.Write(buf)
+		return err
+	}
+	_, err := w.ResponseWriter.Write(buf)
+	return err
+}
+
+// close sends a response that never reached minSize as is and finishes the gzip stream
+func (w *gzipResponseWriter) close() {
+	if !w.committed {
+		w.commit(false)
+	}
+	if w.gz != nil {
+		w.gz.Close()
+		gzipWriters.Put(w.gz)
+		w.gz = nil
+	}
+}
+
+func compressible(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	for _, prefix := range precompressedTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return false
+		}
+	}
+	return true
+}
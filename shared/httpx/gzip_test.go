@@ -0,0 +1,122 @@
+package httpx
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGzipMiddleware tests that large responses are compressed only when the client accepts gzip
+func TestGzipMiddleware(t *testing.T) {
+	largeJSON := `[` + strings.Repeat(`{"name":"vanilla","price":1500},`, 100) + `{}]`
+	smallJSON := `{"status":"ok"}`
+
+	router := mux.NewRouter()
+	router.HandleFunc("/large", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		io.WriteString(w, largeJSON)
+	})
+	router.HandleFunc("/small", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, smallJSON)
+	})
+	router.HandleFunc("/archive", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/zip")
+		io.WriteString(w, largeJSON)
+	})
+	router.Use(GzipMiddleware(GzipMinSize))
+
+	testCases := map[string]struct {
+		path             string
+		acceptEncoding   string
+		expectedStatus   int
+		expectedEncoding string
+		expectedBody     string
+	}{
+		"large JSON is gzipped when requested": {
+			path:             "/large",
+			acceptEncoding:   "br, gzip",
+			expectedStatus:   http.StatusOK,
+			expectedEncoding: "gzip",
+			expectedBody:     largeJSON,
+		},
+		"large JSON is plain when not requested": {
+			path:           "/large",
+			expectedStatus: http.StatusOK,
+			expectedBody:   largeJSON,
+		},
+		"gzip refused with q=0": {
+			path:           "/large",
+			acceptEncoding: "gzip;q=0",
+			expectedStatus: http.StatusOK,
+			expectedBody:   largeJSON,
+		},
+		"small response stays plain": {
+			path:           "/small",
+			acceptEncoding: "gzip",
+			expectedStatus: http.StatusCreated,
+			expectedBody:   smallJSON,
+		},
+		"compressed content type stays plain": {
+			path:           "/archive",
+			acceptEncoding: "gzip",
+			expectedStatus: http.StatusOK,
+			expectedBody:   largeJSON,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			if tc.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tc.acceptEncoding)
+			}
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+			assert.Equal(t, tc.expectedEncoding, rr.Header().Get("Content-Encoding"))
+			assert.Equal(t, "Accept-Encoding", rr.Header().Get("Vary"))
+
+			body := rr.Body.Bytes()
+			if tc.expectedEncoding == "gzip" {
+				assert.Less(t, len(body), len(tc.expectedBody))
+				reader, err := gzip.NewReader(rr.Body)
+				require.NoError(t, err)
+				body, err = io.ReadAll(reader)
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedBody, string(body))
+		})
+	}
+}
+
+// TestGzipMiddlewareFlush tests that a streaming handler's flushes reach the client compressed
+func TestGzipMiddlewareFlush(t *testing.T) {
+	handler := GzipMiddleware(GzipMinSize)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/csv")
+		io.WriteString(w, "id,total\n")
+		w.(http.Flusher).Flush()
+		io.WriteString(w, "1,1500\n")
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/export", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	assert.True(t, rr.Flushed)
+	assert.Equal(t, "", rr.Header().Get("Content-Encoding"))
+	assert.Equal(t, "id,total\n1,1500\n", rr.Body.String())
+}
@@ -0,0 +1,20 @@
+package httpx
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// CheckHealth calls a dependency's health endpoint with client and returns an error unless it answers 200 OK
+func CheckHealth(client *http.Client, url string) error {
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("data service returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCheckHealth tests that only a 200 OK from the health endpoint counts as healthy
+func TestCheckHealth(t *testing.T) {
+	testCases := map[string]struct {
+		status        int
+		unreachable   bool
+		expectedError string
+	}{
+		"healthy":     {status: http.StatusOK},
+		"unhealthy":   {status: http.StatusServiceUnavailable, expectedError: "data service returned status 503"},
+		"unreachable": {unreachable: true},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+			}))
+			url := server.URL + "/health"
+			if tc.unreachable {
+				server.Close()
+			} else {
+				defer server.Close()
+			}
+
+			err := CheckHealth(&http.Client{Timeout: time.Second}, url)
+
+			switch {
+			case tc.unreachable:
+				assert.Error(t, err)
+			case tc.expectedError != "":
+				assert.EqualError(t, err, tc.expectedError)
+			default:
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
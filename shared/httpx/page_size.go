@@ -0,0 +1,20 @@
+package httpx
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// PageSizeWarningHeader is set on a list response whose requested limit was above the maximum page size
+const PageSizeWarningHeader = "X-Page-Size-Warning"
+
+// ClampPageSize caps a requested limit at maxPageSize and sets PageSizeWarningHeader when it had
+// to reduce it, so clients notice the smaller page instead of mistaking it for the end of the list.
+// A maxPageSize of 0 disables the cap
+func ClampPageSize(w http.ResponseWriter, limit, maxPageSize int) int {
+	if maxPageSize <= 0 || limit <= maxPageSize {
+		return limit
+	}
+	w.Header().Set(PageSizeWarningHeader, fmt.Sprintf("limit %d reduced to the maximum page size of %d", limit, maxPageSize))
+	return maxPageSize
+}
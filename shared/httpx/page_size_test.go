@@ -0,0 +1,51 @@
+package httpx
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestClampPageSize tests capping the requested limit and the warning header
+func TestClampPageSize(t *testing.T) {
+	testCases := map[string]struct {
+		limit           int
+		maxPageSize     int
+		expectedLimit   int
+		expectedWarning string
+	}{
+		"within limit": {
+			limit:         25,
+			maxPageSize:   100,
+			expectedLimit: 25,
+		},
+		"at limit": {
+			limit:         100,
+			maxPageSize:   100,
+			expectedLimit: 100,
+		},
+		"over limit is clamped": {
+			limit:           500,
+			maxPageSize:     100,
+			expectedLimit:   100,
+			expectedWarning: "limit 500 reduced to the maximum page size of 100",
+		},
+		"zero maximum disables the cap": {
+			limit:         500,
+			maxPageSize:   0,
+			expectedLimit: 500,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			limit := ClampPageSize(w, tc.limit, tc.maxPageSize)
+
+			assert.Equal(t, tc.expectedLimit, limit)
+			assert.Equal(t, tc.expectedWarning, w.Header().Get(PageSizeWarningHeader))
+		})
+	}
+}
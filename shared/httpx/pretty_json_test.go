@@ -0,0 +1,93 @@
+package httpx
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPrettyJSONMiddleware tests that JSON responses are indented only when ?pretty=true is requested
+func TestPrettyJSONMiddleware(t *testing.T) {
+	compactJSON := `{"success":true,"data":{"id":"1","flavors":["vanilla","chocolate"]}}`
+	indentedJSON := "{\n" +
+		"  \"success\": true,\n" +
+		"  \"data\": {\n" +
+		"    \"id\": \"1\",\n" +
+		"    \"flavors\": [\n" +
+		"      \"vanilla\",\n" +
+		"      \"chocolate\"\n" +
+		"    ]\n" +
+		"  }\n" +
+		"}\n"
+
+	router := mux.NewRouter()
+	router.HandleFunc("/json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, compactJSON+"\n")
+	})
+	router.HandleFunc("/text", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, compactJSON)
+	})
+	router.HandleFunc("/stream", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"a":1}`)
+		w.(http.Flusher).Flush()
+		io.WriteString(w, `{"b":2}`)
+	})
+	router.Use(PrettyJSONMiddleware)
+
+	testCases := map[string]struct {
+		path           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		"compact by default": {
+			path:           "/json",
+			expectedStatus: http.StatusCreated,
+			expectedBody:   compactJSON + "\n",
+		},
+		"indented when requested": {
+			path:           "/json?pretty=true",
+			expectedStatus: http.StatusCreated,
+			expectedBody:   indentedJSON,
+		},
+		"pretty=false stays compact": {
+			path:           "/json?pretty=false",
+			expectedStatus: http.StatusCreated,
+			expectedBody:   compactJSON + "\n",
+		},
+		"invalid pretty value stays compact": {
+			path:           "/json?pretty=yes-please",
+			expectedStatus: http.StatusCreated,
+			expectedBody:   compactJSON + "\n",
+		},
+		"non-JSON content is untouched": {
+			path:           "/text?pretty=true",
+			expectedStatus: http.StatusOK,
+			expectedBody:   compactJSON,
+		},
+		"flushed responses stream unchanged": {
+			path:           "/stream?pretty=1",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"a":1}{"b":2}`,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			assert.Equal(t, tc.expectedBody, rec.Body.String())
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package httpx
+
+import (
+	"net/http"
+	"runtime/debug"
+	"sync/atomic"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// panicCount counts handler panics recovered since the service started
+var panicCount atomic.Int64
+
+// PanicCount returns the number of handler panics recovered since the service started
+func PanicCount() int64 {
+	return panicCount.Load()
+}
+
+// RecoveryMiddleware turns a handler panic into a JSON 500 instead of a dropped connection,
+// logging the panic value and stack. http.ErrAbortHandler is re-panicked, since it is how a
+// handler deliberately aborts a response
+func RecoveryMiddleware(logger *logrus.Logger) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				recovered := recover()
+				if recovered == nil {
+					return
+				}
+				if recovered == http.ErrAbortHandler {
+					panic(recovered)
+				}
+
+				count := panicCount.Add(1)
+				logger.WithFields(logrus.Fields{
+					"method": r.Method,
+					"path":   r.URL.Path,
+					"panic":  recovered,
+					"panics": count,
+					"stack":  string(debug.Stack()),
+				}).Error("Recovered from handler panic")
+
+				WriteRouteError(w, http.StatusInternalServerError, "internal_error", r)
+			}()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRecoveryMiddleware tests that a panicking handler gets a JSON 500 and the server keeps serving
+func TestRecoveryMiddleware(t *testing.T) {
+	logger, hook := test.NewNullLogger()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("nil map write")
+	})
+	router.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	router.Use(RecoveryMiddleware(logger))
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	before := PanicCount()
+
+	resp, err := http.Get(server.URL + "/panic")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+	var body RouteError
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, RouteError{Error: "internal_error", Path: "/panic", Method: http.MethodGet}, body)
+
+	assert.Equal(t, before+1, PanicCount())
+	entry := hook.LastEntry()
+	require.NotNil(t, entry)
+	assert.Equal(t, logrus.ErrorLevel, entry.Level)
+	assert.Equal(t, "nil map write", entry.Data["panic"])
+	assert.Contains(t, entry.Data["stack"], "recovery_test.go")
+
+	// The server is still up after the panic
+	resp, err = http.Get(server.URL + "/ok")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
@@ -0,0 +1,45 @@
+package httpx
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// unknownFieldPrefix is the prefix encoding/json uses when DisallowUnknownFields rejects a field
+const unknownFieldPrefix = "json: unknown field "
+
+// UnknownFieldError reports a request body field that does not exist on the target struct
+type UnknownFieldError struct {
+	Field string
+}
+
+func (e *UnknownFieldError) Error() string {
+	return fmt.Sprintf("unknown field %q in request body", e.Field)
+}
+
+// DecodeJSONBody decodes the request body into dst, rejecting fields dst does not declare
+// so client typos surface as errors instead of being silently dropped
+func DecodeJSONBody(r *http.Request, dst interface{}) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(dst); err != nil {
+		if strings.HasPrefix(err.Error(), unknownFieldPrefix) {
+			return &UnknownFieldError{Field: strings.Trim(strings.TrimPrefix(err.Error(), unknownFieldPrefix), `"`)}
+		}
+		return err
+	}
+
+	return nil
+}
+
+// DecodeErrorMessage returns the client-facing message for a DecodeJSONBody error,
+// naming the offending field for unknown fields and using fallback otherwise
+func DecodeErrorMessage(err error, fallback string) string {
+	if unknownField, ok := err.(*UnknownFieldError); ok {
+		return unknownField.Error()
+	}
+	return fallback
+}
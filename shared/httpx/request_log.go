@@ -0,0 +1,45 @@
+package httpx
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+// UserIDHeader carries the ID of the authenticated user; the gateway sets it after validating the session
+const UserIDHeader = "X-User-ID"
+
+// RequestLogFields returns the log fields identifying who made the request. user_id is only
+// present when the request came through the gateway with an authenticated session
+func RequestLogFields(r *http.Request) logrus.Fields {
+	fields := logrus.Fields{}
+	if userID := r.Header.Get(UserIDHeader); userID != "" {
+		fields["user_id"] = userID
+	}
+	return fields
+}
+
+// RequestLogSampler decides which requests get a log line: every response outside 2xx, and one
+// in every N successful ones, so busy services do not pay for a log line per request
+type RequestLogSampler struct {
+	every     uint64
+	successes atomic.Uint64
+}
+
+// NewRequestLogSampler logs one in every n successful requests; n of 1 or less logs them all
+func NewRequestLogSampler(n int) *RequestLogSampler {
+	if n < 1 {
+		n = 1
+	}
+	return &RequestLogSampler{every: uint64(n)}
+}
+
+// ShouldLog reports whether the request answered with status is logged. The first success is
+// always logged, then every Nth after it
+func (s *RequestLogSampler) ShouldLog(status int) bool {
+	if status < 200 || status > 299 {
+		return true
+	}
+	return (s.successes.Add(1)-1)%s.every == 0
+}
@@ -0,0 +1,80 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRequestLogFields tests that user_id is included only when the gateway sent a user
+func TestRequestLogFields(t *testing.T) {
+	testCases := map[string]struct {
+		userID   string
+		expected logrus.Fields
+	}{
+		"header set": {
+			userID:   "user-123",
+			expected: logrus.Fields{"user_id": "user-123"},
+		},
+		"header absent": {
+			expected: logrus.Fields{},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/resource", nil)
+			if tc.userID != "" {
+				req.Header.Set(UserIDHeader, tc.userID)
+			}
+
+			assert.Equal(t, tc.expected, RequestLogFields(req))
+		})
+	}
+}
+
+// TestRequestLogSampler tests that non-2xx responses are always logged and successes one in N
+func TestRequestLogSampler(t *testing.T) {
+	testCases := map[string]struct {
+		rate     int
+		statuses []int
+		expected []bool
+	}{
+		"every request at rate 1": {
+			rate:     1,
+			statuses: []int{200, 201, 204},
+			expected: []bool{true, true, true},
+		},
+		"one in three successes": {
+			rate:     3,
+			statuses: []int{200, 200, 200, 200, 200},
+			expected: []bool{true, false, false, true, false},
+		},
+		"errors are always logged": {
+			rate:     3,
+			statuses: []int{200, 500, 404, 200, 301, 200, 200},
+			expected: []bool{true, true, true, false, true, false, true},
+		},
+		"rate below one logs everything": {
+			rate:     0,
+			statuses: []int{200, 200},
+			expected: []bool{true, true},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			sampler := NewRequestLogSampler(tc.rate)
+
+			logged := make([]bool, len(tc.statuses))
+			for i, status := range tc.statuses {
+				logged[i] = sampler.ShouldLog(status)
+			}
+
+			assert.Equal(t, tc.expected, logged)
+		})
+	}
+}
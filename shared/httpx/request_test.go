@@ -0,0 +1,68 @@
+package httpx
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type decodeTarget struct {
+	Name     string  `json:"name"`
+	Quantity float64 `json:"quantity"`
+}
+
+func TestDecodeJSONBody(t *testing.T) {
+	testCases := map[string]struct {
+		body            string
+		expectedTarget  decodeTarget
+		expectedField   string
+		expectedMessage string
+		expectError     bool
+	}{
+		"valid_body": {
+			body:           `{"name":"Milk","quantity":2}`,
+			expectedTarget: decodeTarget{Name: "Milk", Quantity: 2},
+		},
+		"unknown_field": {
+			body:            `{"name":"Milk","quantiy":2}`,
+			expectedField:   "quantiy",
+			expectedMessage: `unknown field "quantiy" in request body`,
+			expectError:     true,
+		},
+		"malformed_json": {
+			body:            `{"name":`,
+			expectedMessage: "Invalid JSON format",
+			expectError:     true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			request := httptest.NewRequest("POST", "/", strings.NewReader(tc.body))
+
+			var target decodeTarget
+			err := DecodeJSONBody(request, &target)
+
+			if !tc.expectError {
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectedTarget, target)
+				return
+			}
+
+			require.Error(t, err)
+			assert.Equal(t, tc.expectedMessage, DecodeErrorMessage(err, "Invalid JSON format"))
+
+			var unknownField *UnknownFieldError
+			if tc.expectedField != "" {
+				require.True(t, errors.As(err, &unknownField))
+				assert.Equal(t, tc.expectedField, unknownField.Field)
+			} else {
+				assert.False(t, errors.As(err, &unknownField))
+			}
+		})
+	}
+}
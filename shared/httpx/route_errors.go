@@ -0,0 +1,132 @@
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// routeMethods are the methods probed by default when building the Allow header of a 405 response
+var routeMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+	http.MethodPatch, http.MethodDelete, http.MethodOptions,
+}
+
+// RouteError is the JSON body returned for requests that no handler answered normally. Code and
+// Message are only set by WriteRouteErrorDetail, for failures a service classifies further
+type RouteError struct {
+	Error   string `json:"error"`
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+	Path    string `json:"path"`
+	Method  string `json:"method"`
+}
+
+// routeErrorOptions holds the settings RouteErrorOption values adjust
+type routeErrorOptions struct {
+	methods []string
+}
+
+// RouteErrorOption customizes the handlers built by SetRouteErrorHandlers, NotFoundHandler and
+// MethodNotAllowedHandler
+type RouteErrorOption func(*routeErrorOptions)
+
+// WithAllowMethods replaces the methods probed for the Allow header. A router whose catch-all
+// route answers a method on every path, like the gateway's CORS preflight, leaves that method out
+func WithAllowMethods(methods ...string) RouteErrorOption {
+	return func(o *routeErrorOptions) {
+		o.methods = methods
+	}
+}
+
+func newRouteErrorOptions(opts []RouteErrorOption) routeErrorOptions {
+	options := routeErrorOptions{methods: routeMethods}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// SetRouteErrorHandlers replaces mux's plain-text 404 and 405 responses with JSON ones
+func SetRouteErrorHandlers(router *mux.Router, opts ...RouteErrorOption) {
+	router.NotFoundHandler = NotFoundHandler(router, opts...)
+	router.MethodNotAllowedHandler = MethodNotAllowedHandler(router, opts...)
+}
+
+// NotFoundHandler answers requests for unknown paths with a JSON 404. mux reports a method
+// mismatch inside a subrouter as not found, so a path router serves with other methods gets a 405
+func NotFoundHandler(router *mux.Router, opts ...RouteErrorOption) http.Handler {
+	options := newRouteErrorOptions(opts)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if allowed := allowedMethods(router, r, options.methods); len(allowed) > 0 {
+			writeMethodNotAllowed(w, r, allowed)
+			return
+		}
+		WriteRouteError(w, http.StatusNotFound, "not_found", r)
+	})
+}
+
+// MethodNotAllowedHandler answers requests for a known path with an unsupported method with a
+// JSON 405, listing the methods router accepts for the path in the Allow header; a catch-all
+// method route can make mux report a mismatch for unknown paths, which still get a 404
+func MethodNotAllowedHandler(router *mux.Router, opts ...RouteErrorOption) http.Handler {
+	options := newRouteErrorOptions(opts)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		allowed := allowedMethods(router, r, options.methods)
+		if len(allowed) == 0 {
+			WriteRouteError(w, http.StatusNotFound, "not_found", r)
+			return
+		}
+		writeMethodNotAllowed(w, r, allowed)
+	})
+}
+
+// allowedMethods returns the methods, out of those probed, for which router has a route matching
+// the request's path
+func allowedMethods(router *mux.Router, r *http.Request, methods []string) []string {
+	var allowed []string
+	for _, method := range methods {
+		probe := r.Clone(r.Context())
+		probe.Method = method
+
+		var match mux.RouteMatch
+		if router.Match(probe, &match) && match.MatchErr == nil {
+			allowed = append(allowed, method)
+		}
+	}
+	return allowed
+}
+
+func writeMethodNotAllowed(w http.ResponseWriter, r *http.Request, allowed []string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	WriteRouteError(w, http.StatusMethodNotAllowed, "method_not_allowed", r)
+}
+
+// WriteRouteError answers with a RouteError carrying code and the request's method and path
+func WriteRouteError(w http.ResponseWriter, status int, code string, r *http.Request) {
+	writeRouteError(w, status, RouteError{
+		Error:  code,
+		Path:   r.URL.Path,
+		Method: r.Method,
+	})
+}
+
+// WriteRouteErrorDetail answers like WriteRouteError, adding a finer-grained detailCode and a
+// message that clients can react to
+func WriteRouteErrorDetail(w http.ResponseWriter, status int, code, detailCode, message string, r *http.Request) {
+	writeRouteError(w, status, RouteError{
+		Error:   code,
+		Code:    detailCode,
+		Message: message,
+		Path:    r.URL.Path,
+		Method:  r.Method,
+	})
+}
+
+func writeRouteError(w http.ResponseWriter, status int, body RouteError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
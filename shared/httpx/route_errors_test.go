@@ -0,0 +1,123 @@
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRouteErrorHandlers tests the JSON 404 for unknown paths and the JSON 405 with an Allow header
+func TestRouteErrorHandlers(t *testing.T) {
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+
+	router := mux.NewRouter()
+	api := router.PathPrefix("/api/v1").Subrouter()
+	api.HandleFunc("/items", ok).Methods("GET")
+	api.HandleFunc("/items", ok).Methods("POST")
+	api.HandleFunc("/items/{id}", ok).Methods("DELETE")
+	SetRouteErrorHandlers(router)
+
+	testCases := map[string]struct {
+		method         string
+		path           string
+		expectedStatus int
+		expectedError  string
+		expectedAllow  string
+	}{
+		"matched route": {
+			method:         http.MethodGet,
+			path:           "/api/v1/items",
+			expectedStatus: http.StatusOK,
+		},
+		"unknown path": {
+			method:         http.MethodGet,
+			path:           "/api/v1/unknown",
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "not_found",
+		},
+		"wrong method": {
+			method:         http.MethodPut,
+			path:           "/api/v1/items",
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedError:  "method_not_allowed",
+			expectedAllow:  "GET, POST",
+		},
+		"wrong method on a path with variables": {
+			method:         http.MethodGet,
+			path:           "/api/v1/items/42",
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedError:  "method_not_allowed",
+			expectedAllow:  "DELETE",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, httptest.NewRequest(tc.method, tc.path, nil))
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+			if tc.expectedError == "" {
+				return
+			}
+
+			assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+			assert.Equal(t, tc.expectedAllow, rr.Header().Get("Allow"))
+
+			var body RouteError
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+			assert.Equal(t, tc.expectedError, body.Error)
+			assert.Equal(t, tc.path, body.Path)
+			assert.Equal(t, tc.method, body.Method)
+		})
+	}
+}
+
+// TestRouteErrorHandlersWithAllowMethods tests that a method answered on every path by a catch-all
+// route is left out of the Allow header when it is not probed
+func TestRouteErrorHandlersWithAllowMethods(t *testing.T) {
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+
+	router := mux.NewRouter()
+	api := router.PathPrefix("/api/v1").Subrouter()
+	api.HandleFunc("/items", ok).Methods("GET")
+	api.HandleFunc("/items", ok).Methods("POST")
+	router.Methods("OPTIONS").HandlerFunc(ok) // like the gateway's CORS preflight route
+	SetRouteErrorHandlers(router, WithAllowMethods(http.MethodGet, http.MethodHead, http.MethodPost,
+		http.MethodPut, http.MethodPatch, http.MethodDelete))
+
+	testCases := map[string]struct {
+		path           string
+		expectedStatus int
+		expectedAllow  string
+	}{
+		"known path":   {path: "/api/v1/items", expectedStatus: http.StatusMethodNotAllowed, expectedAllow: "GET, POST"},
+		"unknown path": {path: "/api/v1/unknown", expectedStatus: http.StatusNotFound},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, httptest.NewRequest(http.MethodPut, tc.path, nil))
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+			assert.Equal(t, tc.expectedAllow, rr.Header().Get("Allow"))
+		})
+	}
+}
+
+// TestWriteRouteErrorDetail tests that the detail code and message are added to the route error
+func TestWriteRouteErrorDetail(t *testing.T) {
+	rr := httptest.NewRecorder()
+
+	WriteRouteErrorDetail(rr, http.StatusConflict, "database_error", "unique_violation", "duplicate key",
+		httptest.NewRequest(http.MethodPost, "/query", nil))
+
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.JSONEq(t, `{"error":"database_error","code":"unique_violation","message":"duplicate key","path":"/query","method":"POST"}`, rr.Body.String())
+}
@@ -0,0 +1,59 @@
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// TimeoutMiddleware bounds how long a route's handler may run. The request context carries the
+// deadline, so database calls made with it are cancelled, and the client gets a JSON 503 when the
+// handler has not responded in time.
+//
+// routeTimeouts overrides the timeout by route path template. A timeout of 0 disables the limit,
+// which streaming routes need because the response is buffered until the handler returns
+func TimeoutMiddleware(timeout time.Duration, routeTimeouts map[string]time.Duration) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			limit := routeTimeout(r, timeout, routeTimeouts)
+			if limit <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			body, _ := json.Marshal(RouteError{Error: "request_timeout", Path: r.URL.Path, Method: r.Method})
+			http.TimeoutHandler(next, limit, string(body)).ServeHTTP(&timeoutResponseWriter{ResponseWriter: w}, r)
+		})
+	}
+}
+
+// routeTimeout returns the timeout configured for the matched route, or fallback
+func routeTimeout(r *http.Request, fallback time.Duration, routeTimeouts map[string]time.Duration) time.Duration {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return fallback
+	}
+	template, err := route.GetPathTemplate()
+	if err != nil {
+		return fallback
+	}
+	if limit, ok := routeTimeouts[template]; ok {
+		return limit
+	}
+	return fallback
+}
+
+// timeoutResponseWriter labels the 503 written by http.TimeoutHandler as JSON; a handler that
+// finishes in time has its own headers copied over before WriteHeader
+type timeoutResponseWriter struct {
+	http.ResponseWriter
+}
+
+func (w *timeoutResponseWriter) WriteHeader(statusCode int) {
+	if statusCode == http.StatusServiceUnavailable && w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
@@ -0,0 +1,77 @@
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTimeoutMiddleware tests that a slow handler gets a JSON 503 and that fast and exempt routes are untouched
+func TestTimeoutMiddleware(t *testing.T) {
+	// slow waits for the request deadline the way a cancelled database call would
+	slow := func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+	fast := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusCreated)
+	}
+	streaming := func(w http.ResponseWriter, r *http.Request) {
+		_, deadline := r.Context().Deadline()
+		assert.False(t, deadline, "exempt routes should run without a deadline")
+		_, canFlush := w.(http.Flusher)
+		assert.True(t, canFlush, "exempt routes should keep the flushable writer")
+		w.WriteHeader(http.StatusOK)
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/slow", slow).Methods("GET")
+	router.HandleFunc("/fast", fast).Methods("GET")
+	router.HandleFunc("/stream", streaming).Methods("GET")
+	router.Use(TimeoutMiddleware(20*time.Millisecond, map[string]time.Duration{"/stream": 0}))
+
+	t.Run("slow handler times out", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/slow", nil)
+		rr := httptest.NewRecorder()
+
+		start := time.Now()
+		router.ServeHTTP(rr, req)
+
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+		require.Equal(t, http.StatusServiceUnavailable, rr.Code)
+		assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+
+		var body RouteError
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+		assert.Equal(t, RouteError{Error: "request_timeout", Path: "/slow", Method: http.MethodGet}, body)
+	})
+
+	t.Run("fast handler keeps its response", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/fast", nil)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		assert.Equal(t, "text/plain", rr.Header().Get("Content-Type"))
+	})
+
+	t.Run("exempt route runs without a limit", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/stream", nil)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+}
@@ -0,0 +1,82 @@
+package money
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Money is an amount held in integer minor units (cents) so sums of many prices stay exact
+type Money int64
+
+// FromFloat converts a decimal amount to Money, rounding half away from zero to the nearest cent
+func FromFloat(amount float64) Money {
+	return Money(math.Round(amount * 100))
+}
+
+// FromCents wraps an amount already expressed in minor units
+func FromCents(cents int64) Money {
+	return Money(cents)
+}
+
+// Cents returns the amount in minor units
+func (m Money) Cents() int64 {
+	return int64(m)
+}
+
+// Float64 returns the amount as a decimal, for fields still stored as floats
+func (m Money) Float64() float64 {
+	return float64(m) / 100
+}
+
+// Mul returns the amount multiplied by a whole quantity
+func (m Money) Mul(quantity int) Money {
+	return m * Money(quantity)
+}
+
+// Percent returns rate percent of the amount, rounded to the nearest cent
+func (m Money) Percent(rate float64) Money {
+	return Money(math.Round(float64(m) * rate / 100))
+}
+
+// Sum adds amounts together
+func Sum(amounts ...Money) Money {
+	var total Money
+	for _, amount := range amounts {
+		total += amount
+	}
+	return total
+}
+
+// String formats the amount with exactly two decimals
+func (m Money) String() string {
+	sign := ""
+	cents := int64(m)
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+// MarshalJSON encodes the amount as a JSON number with two decimals
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON accepts the amount as a JSON number or a numeric string
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.Trim(data, `"`)
+	if len(data) == 0 || string(data) == "null" {
+		*m = 0
+		return nil
+	}
+
+	amount, err := strconv.ParseFloat(string(data), 64)
+	if err != nil {
+		return fmt.Errorf("invalid money amount %q: %w", data, err)
+	}
+	*m = FromFloat(amount)
+	return nil
+}
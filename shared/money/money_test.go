@@ -0,0 +1,88 @@
+package money
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSumIsExact tests that summing prices in cents stays exact where float64 drifts
+func TestSumIsExact(t *testing.T) {
+	floatTotal := 0.0
+	var total Money
+	for i := 0; i < 10; i++ {
+		floatTotal += 0.1
+		total += FromFloat(0.1)
+	}
+
+	assert.NotEqual(t, 1.0, floatTotal, "float64 accumulates representation error")
+	assert.Equal(t, FromFloat(1.0), total)
+	assert.Equal(t, "1.00", total.String())
+
+	floatTotal = 0.0
+	total = 0
+	for i := 0; i < 1000; i++ {
+		floatTotal += 0.01
+		total += FromFloat(0.01)
+	}
+
+	assert.NotEqual(t, 10.0, floatTotal)
+	assert.Equal(t, int64(1000), total.Cents())
+}
+
+// TestArithmetic tests conversion, multiplication and percentages
+func TestArithmetic(t *testing.T) {
+	testCases := map[string]struct {
+		got      Money
+		expected Money
+	}{
+		"rounds half away from zero":  {got: FromFloat(0.125), expected: FromCents(13)},
+		"negative amounts round":      {got: FromFloat(-0.125), expected: FromCents(-13)},
+		"multiplies by quantity":      {got: FromFloat(19.99).Mul(3), expected: FromCents(5997)},
+		"percent rounds to the cent":  {got: FromFloat(10.05).Percent(13), expected: FromCents(131)},
+		"sums amounts":                {got: Sum(FromFloat(0.1), FromFloat(0.2)), expected: FromFloat(0.3)},
+		"sum of nothing is zero":      {got: Sum(), expected: 0},
+		"float round trip is precise": {got: FromFloat(FromCents(123456).Float64()), expected: FromCents(123456)},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.got)
+		})
+	}
+}
+
+// TestJSON tests that amounts marshal with two decimals and unmarshal from numbers or strings
+func TestJSON(t *testing.T) {
+	data, err := json.Marshal(map[string]Money{"total": FromCents(150000), "tax": FromCents(-5)})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"total": 1500.00, "tax": -0.05}`, string(data))
+	assert.Contains(t, string(data), `1500.00`)
+
+	testCases := map[string]struct {
+		input       string
+		expected    Money
+		expectError bool
+	}{
+		"number":         {input: `12.34`, expected: FromCents(1234)},
+		"string":         {input: `"12.34"`, expected: FromCents(1234)},
+		"whole number":   {input: `7`, expected: FromCents(700)},
+		"null":           {input: `null`, expected: 0},
+		"invalid string": {input: `"abc"`, expectError: true},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var m Money
+			err := json.Unmarshal([]byte(tc.input), &m)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, m)
+		})
+	}
+}
@@ -0,0 +1,30 @@
+// Package pricing holds the existence price calculation shared by the services that price stock:
+// margin and taxes applied per item, then a configurable rounding of the selling price.
+package pricing
+
+import "shared/money"
+
+// ExistencePrice holds the amounts added on top of an existence's cost, in cents
+type ExistencePrice struct {
+	IncomeMarginAmount money.Money
+	IvaAmount          money.Money
+	ServiceTaxAmount   money.Money
+	CalculatedPrice    money.Money
+}
+
+// CalculateExistencePrice applies the income margin to the cost, then IVA and service tax to the
+// cost plus margin. Each amount is rounded to the cent so the calculated price is their exact sum.
+func CalculateExistencePrice(costPerItem, incomeMarginPercentage, ivaPercentage, serviceTaxPercentage float64) ExistencePrice {
+	cost := money.FromFloat(costPerItem)
+	margin := cost.Percent(incomeMarginPercentage)
+	taxable := cost + margin
+	iva := taxable.Percent(ivaPercentage)
+	serviceTax := taxable.Percent(serviceTaxPercentage)
+
+	return ExistencePrice{
+		IncomeMarginAmount: margin,
+		IvaAmount:          iva,
+		ServiceTaxAmount:   serviceTax,
+		CalculatedPrice:    money.Sum(cost, margin, iva, serviceTax),
+	}
+}
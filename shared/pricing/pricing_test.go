@@ -0,0 +1,56 @@
+package pricing
+
+import (
+	"testing"
+
+	"shared/money"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCalculateExistencePrice tests margin and taxes on the cost per item
+func TestCalculateExistencePrice(t *testing.T) {
+	testCases := map[string]struct {
+		cost, margin, iva, serviceTax float64
+		expected                      ExistencePrice
+	}{
+		"default percentages": {
+			cost: 1000, margin: 30, iva: 13, serviceTax: 10,
+			expected: ExistencePrice{
+				IncomeMarginAmount: money.FromFloat(300),
+				IvaAmount:          money.FromFloat(169),
+				ServiceTaxAmount:   money.FromFloat(130),
+				CalculatedPrice:    money.FromFloat(1599),
+			},
+		},
+		"amounts rounded to the cent": {
+			cost: 10.05, margin: 0, iva: 13, serviceTax: 10,
+			expected: ExistencePrice{
+				IvaAmount:        money.FromFloat(1.31),
+				ServiceTaxAmount: money.FromFloat(1.01),
+				CalculatedPrice:  money.FromFloat(12.37),
+			},
+		},
+		"no percentages": {
+			cost:     1234,
+			expected: ExistencePrice{CalculatedPrice: money.FromFloat(1234)},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, CalculateExistencePrice(tc.cost, tc.margin, tc.iva, tc.serviceTax))
+		})
+	}
+}
+
+// TestCalculateExistencePriceSumsExactly tests that the calculated price is the exact sum of its parts
+func TestCalculateExistencePriceSumsExactly(t *testing.T) {
+	// 0.1 + 0.2 drifts in float64; in cents the calculated price is exactly the parts' sum
+	cost, margin := 0.1, 0.2
+	assert.NotEqual(t, 0.3, cost+margin)
+
+	price := CalculateExistencePrice(0.1, 200, 0, 0)
+	assert.Equal(t, 0.3, price.CalculatedPrice.Float64())
+	assert.Equal(t, "0.30", price.CalculatedPrice.String())
+}
@@ -0,0 +1,52 @@
+package pricing
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Rounding strategy names accepted in configuration
+const (
+	RoundingCeilTo100   = "ceil-to-100"
+	RoundingNearest10   = "round-to-nearest-10"
+	RoundingNone        = "none"
+	DefaultRoundingName = RoundingCeilTo100
+)
+
+// ErrUnknownRoundingStrategy is returned for rounding strategy names that are not supported
+var ErrUnknownRoundingStrategy = errors.New("unknown rounding strategy")
+
+// RoundingStrategy turns a calculated existence price into the final selling price
+type RoundingStrategy func(price float64) float64
+
+// CeilTo100 rounds the price up to the next multiple of 100
+func CeilTo100(price float64) float64 {
+	return math.Ceil(price/100) * 100
+}
+
+// RoundToNearest10 rounds the price to the nearest multiple of 10
+func RoundToNearest10(price float64) float64 {
+	return math.Round(price/10) * 10
+}
+
+// NoRounding leaves the price unchanged
+func NoRounding(price float64) float64 {
+	return price
+}
+
+// RoundingStrategyByName returns the strategy registered under name (case-insensitive)
+func RoundingStrategyByName(name string) (RoundingStrategy, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case RoundingCeilTo100:
+		return CeilTo100, nil
+	case RoundingNearest10:
+		return RoundToNearest10, nil
+	case RoundingNone:
+		return NoRounding, nil
+	default:
+		return nil, fmt.Errorf("%w %q (supported: %s, %s, %s)", ErrUnknownRoundingStrategy, name,
+			RoundingCeilTo100, RoundingNearest10, RoundingNone)
+	}
+}
@@ -0,0 +1,74 @@
+package pricing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRoundingStrategies tests each strategy on the same price
+func TestRoundingStrategies(t *testing.T) {
+	const price = 1234.5
+
+	testCases := map[string]struct {
+		name     string
+		expected float64
+	}{
+		"ceil to 100": {
+			name:     RoundingCeilTo100,
+			expected: 1300,
+		},
+		"round to nearest 10": {
+			name:     RoundingNearest10,
+			expected: 1230,
+		},
+		"no rounding": {
+			name:     RoundingNone,
+			expected: 1234.5,
+		},
+	}
+
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			strategy, err := RoundingStrategyByName(tc.name)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, strategy(price))
+		})
+	}
+}
+
+// TestRoundingStrategyByName tests name lookup, including unknown names
+func TestRoundingStrategyByName(t *testing.T) {
+	testCases := map[string]struct {
+		name          string
+		expectedError bool
+	}{
+		"names are case-insensitive": {
+			name: " Ceil-To-100 ",
+		},
+		"unknown name": {
+			name:          "floor-to-1000",
+			expectedError: true,
+		},
+		"empty name": {
+			name:          "",
+			expectedError: true,
+		},
+	}
+
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			strategy, err := RoundingStrategyByName(tc.name)
+
+			if tc.expectedError {
+				assert.ErrorIs(t, err, ErrUnknownRoundingStrategy)
+				assert.Nil(t, strategy)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.NotNil(t, strategy)
+		})
+	}
+}
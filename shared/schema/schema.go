@@ -0,0 +1,58 @@
+// Package schema checks at startup that the database has the tables a service queries
+package schema
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"fmt"
+	"strings"
+)
+
+// existingTablesQuery lists the tables of the schema the connection resolves unqualified names in
+//
+//go:embed scripts/existing_tables.sql
+var existingTablesQuery string
+
+// MissingTablesError reports the required tables the database does not have
+type MissingTablesError struct {
+	Tables []string
+}
+
+func (e *MissingTablesError) Error() string {
+	return fmt.Sprintf("database is missing required tables: %s (has the data-service init script run?)", strings.Join(e.Tables, ", "))
+}
+
+// CheckRequiredTables verifies at startup that every table the service queries exists, so a
+// missing schema fails fast with the table names instead of as query errors on the first request.
+// It returns a *MissingTablesError listing every missing table, in the order given
+func CheckRequiredTables(ctx context.Context, db *sql.DB, required []string) error {
+	rows, err := db.QueryContext(ctx, existingTablesQuery)
+	if err != nil {
+		return fmt.Errorf("failed to list database tables: %w", err)
+	}
+	defer rows.Close()
+
+	existing := map[string]bool{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return fmt.Errorf("failed to scan table name: %w", err)
+		}
+		existing[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to list database tables: %w", err)
+	}
+
+	var missing []string
+	for _, table := range required {
+		if !existing[table] {
+			missing = append(missing, table)
+		}
+	}
+	if len(missing) > 0 {
+		return &MissingTablesError{Tables: missing}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCheckRequiredTables tests that missing tables are listed and present ones pass
+func TestCheckRequiredTables(t *testing.T) {
+	required := []string{"customers", "orders", "payments"}
+
+	testCases := map[string]struct {
+		existing        []string
+		queryErr        error
+		expectedMissing []string
+		expectErr       bool
+	}{
+		"all tables present": {
+			existing: []string{"payments", "customers", "orders", "audit_logs"},
+		},
+		"missing tables are listed in order": {
+			existing:        []string{"customers"},
+			expectedMissing: []string{"orders", "payments"},
+			expectErr:       true,
+		},
+		"empty database": {
+			expectedMissing: required,
+			expectErr:       true,
+		},
+		"query failure": {
+			queryErr:  errors.New("permission denied"),
+			expectErr: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			expectation := mock.ExpectQuery("FROM information_schema.tables WHERE table_schema = current_schema\\(\\)")
+			if tc.queryErr != nil {
+				expectation.WillReturnError(tc.queryErr)
+			} else {
+				rows := sqlmock.NewRows([]string{"table_name"})
+				for _, table := range tc.existing {
+					rows.AddRow(table)
+				}
+				expectation.WillReturnRows(rows)
+			}
+
+			err = CheckRequiredTables(context.Background(), db, required)
+
+			if !tc.expectErr {
+				assert.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				var missingErr *MissingTablesError
+				if tc.expectedMissing == nil {
+					assert.False(t, errors.As(err, &missingErr))
+				} else {
+					require.ErrorAs(t, err, &missingErr)
+					assert.Equal(t, tc.expectedMissing, missingErr.Tables)
+					for _, table := range tc.expectedMissing {
+						assert.Contains(t, err.Error(), table)
+					}
+				}
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,2 @@
+-- List the tables of the schema the connection resolves unqualified names in
+SELECT table_name FROM information_schema.tables WHERE table_schema = current_schema()
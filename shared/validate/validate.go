@@ -0,0 +1,120 @@
+// Package validate collects every field violation in a request, so clients can fix them all in one round trip
+package validate
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ValidationError represents a single invalid field in a request
+type ValidationError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+	Index   *int   `json:"index,omitempty"`
+}
+
+func (e *ValidationError) Error() string {
+	if e.Index != nil {
+		return "validation error in " + e.Field + "[" + strconv.Itoa(*e.Index) + "]: " + e.Message
+	}
+	return "validation error in " + e.Field + ": " + e.Message
+}
+
+// Errors holds every violation found in a request, in the order its rules were checked
+type Errors []ValidationError
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i := range e {
+		messages[i] = e[i].Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap exposes each violation to errors.Is and errors.As
+func (e Errors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i := range e {
+		errs[i] = &e[i]
+	}
+	return errs
+}
+
+// Validator accumulates the violations reported while a request checks its field rules
+type Validator struct {
+	errs Errors
+}
+
+// Check records message against field when ok is false
+func (v *Validator) Check(ok bool, field, message string) {
+	if !ok {
+		v.errs = append(v.errs, ValidationError{Field: field, Message: message})
+	}
+}
+
+// CheckAt records message against the element at index of a list field when ok is false
+func (v *Validator) CheckAt(ok bool, field string, index int, message string) {
+	if !ok {
+		v.errs = append(v.errs, ValidationError{Field: field, Message: message, Index: &index})
+	}
+}
+
+// Add records violations produced elsewhere, such as by a nested request
+func (v *Validator) Add(violations ...ValidationError) {
+	v.errs = append(v.errs, violations...)
+}
+
+// Err returns the recorded violations as Errors, or nil when every rule passed
+func (v *Validator) Err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+// Validatable is implemented by requests that register their field rules with a Validator
+type Validatable interface {
+	Rules(v *Validator)
+}
+
+// Validate checks every rule of req and returns all violations as Errors, or nil if req is valid
+func Validate(req Validatable) error {
+	var v Validator
+	req.Rules(&v)
+	return v.Err()
+}
+
+// All returns the violations carried by err: every entry of an Errors, the single
+// ValidationError it wraps, or nil when err is not a validation failure
+func All(err error) []ValidationError {
+	var errs Errors
+	if errors.As(err, &errs) {
+		return errs
+	}
+	var single *ValidationError
+	if errors.As(err, &single) {
+		return []ValidationError{*single}
+	}
+	return nil
+}
+
+// ErrorResponse is the envelope a request failing validation is rejected with
+type ErrorResponse struct {
+	Success bool              `json:"success"`
+	Error   string            `json:"error"`
+	Errors  []ValidationError `json:"errors"`
+}
+
+// WriteErrors rejects a request with 400 Bad Request and every violation its validation reported
+func WriteErrors(w http.ResponseWriter, violations []ValidationError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(ErrorResponse{
+		Success: false,
+		Error:   "Validation failed",
+		Errors:  violations,
+	})
+}
@@ -0,0 +1,121 @@
+package validate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testRequest struct {
+	Name     string
+	Quantity int
+	Prices   []float64
+}
+
+func (r testRequest) Rules(v *Validator) {
+	v.Check(r.Name != "", "name", "name is required")
+	v.Check(r.Quantity > 0, "quantity", "quantity must be greater than 0")
+	for i, price := range r.Prices {
+		v.CheckAt(price >= 0, "prices", i, "price cannot be negative")
+	}
+}
+
+// TestValidate tests that every violated rule is reported, in registration order
+func TestValidate(t *testing.T) {
+	testCases := map[string]struct {
+		request  testRequest
+		expected []ValidationError
+	}{
+		"valid request": {
+			request: testRequest{Name: "Coffee", Quantity: 1, Prices: []float64{1.5}},
+		},
+		"single violation": {
+			request:  testRequest{Name: "Coffee", Quantity: 0},
+			expected: []ValidationError{{Field: "quantity", Message: "quantity must be greater than 0"}},
+		},
+		"multiple simultaneous violations": {
+			request: testRequest{Quantity: -1, Prices: []float64{2, -1, -3}},
+			expected: []ValidationError{
+				{Field: "name", Message: "name is required"},
+				{Field: "quantity", Message: "quantity must be greater than 0"},
+				{Field: "prices", Message: "price cannot be negative", Index: intPtr(1)},
+				{Field: "prices", Message: "price cannot be negative", Index: intPtr(2)},
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			err := Validate(tc.request)
+			if tc.expected == nil {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Equal(t, tc.expected, All(err))
+		})
+	}
+}
+
+// TestErrors tests the formatting and unwrapping of aggregated violations
+func TestErrors(t *testing.T) {
+	err := Validate(testRequest{Prices: []float64{-1}})
+	require.Error(t, err)
+
+	assert.Equal(t, "validation error in name: name is required; "+
+		"validation error in quantity: quantity must be greater than 0; "+
+		"validation error in prices[0]: price cannot be negative", err.Error())
+
+	var violation *ValidationError
+	require.True(t, errors.As(err, &violation))
+	assert.Equal(t, "name", violation.Field)
+}
+
+// TestAll tests extracting violations from wrapped and unrelated errors
+func TestAll(t *testing.T) {
+	single := &ValidationError{Field: "name", Message: "name is required"}
+
+	testCases := map[string]struct {
+		err      error
+		expected []ValidationError
+	}{
+		"nil error":           {err: nil},
+		"unrelated error":     {err: errors.New("boom")},
+		"single violation":    {err: single, expected: []ValidationError{*single}},
+		"wrapped aggregation": {err: fmt.Errorf("create: %w", Errors{*single}), expected: []ValidationError{*single}},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, All(tc.err))
+		})
+	}
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+// TestWriteErrors tests that violations are written in the validation error envelope
+func TestWriteErrors(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	WriteErrors(w, All(Validate(testRequest{Prices: []float64{1}})))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var response ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.False(t, response.Success)
+	assert.Equal(t, "Validation failed", response.Error)
+	require.Len(t, response.Errors, 2)
+	assert.Equal(t, "name", response.Errors[0].Field)
+	assert.Equal(t, "quantity", response.Errors[1].Field)
+}
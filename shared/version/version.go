@@ -0,0 +1,30 @@
+// Package version exposes build metadata injected at link time. Every service links
+// this package, so the same flags work for all of them.
+//
+// Values are set with -ldflags, for example:
+//
+//	go build -ldflags "-X shared/version.Version=1.2.0 -X shared/version.GitCommit=$(git rev-parse --short HEAD) -X shared/version.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+package version
+
+// Build metadata, overridden via -ldflags at build time
+var (
+	Version   = "1.0.0"
+	GitCommit = "unknown"
+	BuildTime = "unknown"
+)
+
+// BuildInfo describes the running binary
+type BuildInfo struct {
+	Version   string `json:"version"`
+	GitCommit string `json:"git_commit"`
+	BuildTime string `json:"build_time"`
+}
+
+// Info returns the build metadata of the running binary
+func Info() BuildInfo {
+	return BuildInfo{
+		Version:   Version,
+		GitCommit: GitCommit,
+		BuildTime: BuildTime,
+	}
+}
@@ -0,0 +1,28 @@
+package version
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestInfo tests that Info reflects the link-time variables and serializes with snake_case keys
+func TestInfo(t *testing.T) {
+	originalVersion, originalCommit, originalBuildTime := Version, GitCommit, BuildTime
+	defer func() {
+		Version, GitCommit, BuildTime = originalVersion, originalCommit, originalBuildTime
+	}()
+
+	Version = "1.2.3"
+	GitCommit = "abc1234"
+	BuildTime = "2024-01-01T12:00:00Z"
+
+	info := Info()
+	assert.Equal(t, BuildInfo{Version: "1.2.3", GitCommit: "abc1234", BuildTime: "2024-01-01T12:00:00Z"}, info)
+
+	data, err := json.Marshal(info)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"version":"1.2.3","git_commit":"abc1234","build_time":"2024-01-01T12:00:00Z"}`, string(data))
+}